@@ -0,0 +1,147 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/config"
+	"github.com/cpcloud/webcasa/internal/exports"
+)
+
+// remoteTimeout bounds a backup's upload or a restore's download.
+const remoteTimeout = 30 * time.Minute
+
+// runBackup pushes a compressed snapshot of the database to a directory or
+// an S3 bucket.
+func runBackup(args []string) {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	dbPath := fs.String("db", "", "SQLite database path (default: platform data dir)")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: webcasa backup [-db path] DIR|s3://bucket/prefix[/name.db.gz]")
+		os.Exit(2)
+	}
+	target, name := exports.SplitSnapshot(fs.Arg(0))
+	dest := snapshotDestination(target)
+
+	store := openExistingStore(*dbPath)
+	defer store.Close()
+	a, err := exports.Snapshot(store, time.Now())
+	if err != nil {
+		fail("snapshot database", err)
+	}
+	if name != "" {
+		a.FileName = name
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+	if err := dest.Deliver(ctx, a); err != nil {
+		fail("back up", err)
+	}
+	fmt.Fprintf(os.Stderr, "webcasa: backed up to %s/%s (%d bytes)\n", dest, a.FileName, len(a.Body))
+}
+
+// runRestore replaces the database with a snapshot fetched from a
+// directory or an S3 bucket, after checking it arrived intact and opens
+// cleanly. The database it replaces is kept beside it. webcasa mustn't be
+// running on the database meanwhile.
+func runRestore(args []string) {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	dbPath := fs.String("db", "", "SQLite database path (default: platform data dir)")
+	force := fs.Bool("force", false, "replace an existing database")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: webcasa restore [-db path] [-force] DIR|s3://bucket/prefix[/name.db.gz]")
+		fmt.Fprintln(os.Stderr, "without a file name, the newest snapshot there is restored")
+		os.Exit(2)
+	}
+	path, err := resolveDB(*dbPath, false)
+	if err != nil {
+		fail("resolve db path", err)
+	}
+	_, err = os.Stat(path)
+	exists := err == nil
+	if exists && !*force {
+		fail("restore", fmt.Errorf("%s exists; pass -force to replace it", path))
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		fail("restore", err)
+	}
+
+	target, name := exports.SplitSnapshot(fs.Arg(0))
+	dest := snapshotDestination(target)
+	src, ok := dest.(exports.Source)
+	if !ok {
+		fail("restore", fmt.Errorf("can't restore from %s", dest))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+	if name == "" {
+		if name, err = exports.LatestSnapshot(ctx, src); err != nil {
+			fail("find snapshot", err)
+		}
+	}
+	body, err := src.Fetch(ctx, name)
+	if err != nil {
+		fail("fetch snapshot", err)
+	}
+	db, err := exports.OpenSnapshot(body)
+	if err != nil {
+		fail("restore "+name, err)
+	}
+
+	tmp := path + ".restoring"
+	if err := os.WriteFile(tmp, db, 0o600); err != nil {
+		fail("restore", err)
+	}
+	if err := checkRestored(tmp); err != nil {
+		_ = os.Remove(tmp)
+		fail("restore "+name, err)
+	}
+	if exists {
+		// The old WAL goes with the old database, which it belongs to.
+		old := path + ".before-restore"
+		for _, suffix := range []string{"", "-wal", "-shm"} {
+			if err := os.Rename(path+suffix, old+suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+				_ = os.Remove(tmp)
+				fail("restore", err)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "webcasa: kept the old database as %s\n", old)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		fail("restore", err)
+	}
+	fmt.Fprintf(os.Stderr, "webcasa: restored %s from %s/%s\n", path, src, name)
+}
+
+// checkRestored opens a restored database and runs the integrity checks
+// on it, asking for its passphrase if it's encrypted.
+func checkRestored(path string) error {
+	store, err := openDatabase(path, true)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	return store.CheckIntegrity()
+}
+
+// snapshotDestination resolves where snapshots go with the configured
+// credentials.
+func snapshotDestination(target string) exports.Destination {
+	cfg, err := config.Load()
+	if err != nil {
+		fail("load config", err)
+	}
+	dest, err := cfg.Destination(target)
+	if err != nil {
+		fail("parse destination", err)
+	}
+	return dest
+}
@@ -0,0 +1,109 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// doctorShown caps how many records each check lists.
+const doctorShown = 10
+
+// runDoctor prints the retention checks and, when asked, applies their
+// cleanups. Each cleanup is undoable: trashed records can be restored and
+// changed ones reverted from their history.
+func runDoctor(args []string) {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	dbPath := fs.String("db", "", "SQLite database path (default: platform data dir)")
+	opts := data.DefaultRetentionOptions
+	fs.IntVar(&opts.DocumentYears, "document-years", opts.DocumentYears, "flag documents unopened for this many years")
+	fs.IntVar(&opts.PlannedYears, "planned-years", opts.PlannedYears, "flag projects planned for this many years")
+	fs.IntVar(&opts.VendorYears, "vendor-years", opts.VendorYears, "flag vendors with no work for this many years")
+	trashDocs := fs.Bool("trash-documents", false, "move unopened documents to the trash")
+	abandon := fs.Bool("abandon-projects", false, "mark stale planned projects abandoned")
+	trashVendors := fs.Bool("trash-vendors", false, "move idle vendors to the trash")
+	interval := fs.Int("set-interval", 0, "give maintenance without an interval this many months")
+	_ = fs.Parse(args)
+	if opts.DocumentYears < 1 || opts.PlannedYears < 1 || opts.VendorYears < 1 || *interval < 0 {
+		fmt.Fprintln(os.Stderr, "webcasa: doctor thresholds must be at least 1 year and -set-interval positive")
+		os.Exit(2)
+	}
+
+	store := openExistingStore(*dbPath)
+	defer store.Close()
+	r, err := store.RetentionReport(time.Now(), opts)
+	if err != nil {
+		fail("run checks", err)
+	}
+
+	check(fmt.Sprintf("Documents not opened in %d years", opts.DocumentYears),
+		r.UnopenedDocuments, "-trash-documents",
+		func(d data.Document) string { return fmt.Sprintf("#%d %s (%s)", d.ID, d.Title, d.SizeHuman) })
+	check(fmt.Sprintf("Projects planned for over %d years", opts.PlannedYears),
+		r.StalePlannedProjects, "-abandon-projects",
+		func(p data.Project) string {
+			return fmt.Sprintf("#%d %s, untouched since %s", p.ID, p.Title, p.UpdatedAt.Format(time.DateOnly))
+		})
+	check(fmt.Sprintf("Vendors with no work in %d years", opts.VendorYears),
+		r.IdleVendors, "-trash-vendors",
+		func(v data.Vendor) string { return fmt.Sprintf("#%d %s", v.ID, v.Name) })
+	check("Maintenance without an interval", r.UnscheduledMaintenance, "-set-interval MONTHS",
+		func(m data.MaintenanceItem) string { return fmt.Sprintf("#%d %s", m.ID, m.Name) })
+
+	if *trashDocs {
+		cleanUp("moved %d document(s) to the trash", r.UnopenedDocuments,
+			func(d data.Document) error { return store.DeleteDocument(d.ID) })
+	}
+	if *abandon {
+		cleanUp("marked %d project(s) abandoned", r.StalePlannedProjects, func(p data.Project) error {
+			p.Status = data.ProjectStatusAbandoned
+			return store.UpdateProject(p)
+		})
+	}
+	if *trashVendors {
+		cleanUp("moved %d vendor(s) to the trash", r.IdleVendors,
+			func(v data.Vendor) error { return store.DeleteVendor(v.ID) })
+	}
+	if *interval > 0 {
+		cleanUp(fmt.Sprintf("set a %d-month interval on %%d maintenance item(s)", *interval),
+			r.UnscheduledMaintenance, func(m data.MaintenanceItem) error {
+				m.IntervalMonths = *interval
+				return store.UpdateMaintenance(m)
+			})
+	}
+}
+
+// check prints one check's findings and the flag that cleans them up.
+func check[T any](title string, found []T, fix string, describe func(T) string) {
+	if len(found) == 0 {
+		fmt.Printf("ok    %s: none\n", title)
+		return
+	}
+	fmt.Printf("note  %s: %d (clean up with %s)\n", title, len(found), fix)
+	for _, rec := range found[:min(len(found), doctorShown)] {
+		fmt.Printf("        %s\n", describe(rec))
+	}
+	if len(found) > doctorShown {
+		fmt.Printf("        ... and %d more\n", len(found)-doctorShown)
+	}
+}
+
+// cleanUp applies fix to every record, reporting failures as it goes, then
+// how many it fixed.
+func cleanUp[T any](done string, found []T, fix func(T) error) {
+	n := 0
+	for _, rec := range found {
+		if err := fix(rec); err != nil {
+			fmt.Fprintf(os.Stderr, "webcasa: %v\n", err)
+			continue
+		}
+		n++
+	}
+	fmt.Fprintf(os.Stderr, "webcasa: "+done+"\n", n)
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/cpcloud/webcasa/internal/chat"
+	"github.com/cpcloud/webcasa/internal/config"
+)
+
+const embeddingsUsage = `usage: webcasa embeddings [command] [flags]
+
+commands:
+  status   say how many embeddings are cached for embedding_model. The
+           default command.
+  update   embed the document pages and notes that are new or changed
+  rebuild  forget every embedding and embed everything again, as after
+           changing embedding_model under [llm]
+`
+
+// runEmbeddings looks after the cache of embeddings chat matches questions
+// with when an embedding model is set. Chat embeds what a question needs
+// as it's asked; update does it all ahead of time.
+func runEmbeddings(args []string) {
+	cmd := "status"
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		cmd, args = args[0], args[1:]
+	}
+	fs := flag.NewFlagSet("embeddings "+cmd, flag.ExitOnError)
+	dbPath := fs.String("db", "", "SQLite database path (default: platform data dir)")
+	_ = fs.Parse(args)
+	if cmd != "status" && cmd != "update" && cmd != "rebuild" {
+		fmt.Fprint(os.Stderr, embeddingsUsage)
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fail("load config", err)
+	}
+	if cfg.LLM.EmbeddingModel == "" {
+		fail(cmd, errors.New("no embedding model is set; set embedding_model under [llm]"))
+	}
+	store := openExistingStore(*dbPath)
+	defer store.Close()
+
+	if cmd == "status" {
+		mine, others, err := store.CountEmbeddings(cfg.LLM.EmbeddingModel)
+		if err != nil {
+			fail("count embeddings", err)
+		}
+		fmt.Printf("%d embeddings by %s\n", mine, cfg.LLM.EmbeddingModel)
+		if others > 0 {
+			fmt.Printf("%d by other models; run webcasa embeddings rebuild to replace them\n", others)
+		}
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	model := newLLMClient(cfg.LLM)
+	if budget := newBudget(store, cfg.LLM.Budget); budget != nil {
+		model.Meter = budget
+	}
+	redact, err := newRedactor(cfg.LLM)
+	if err != nil {
+		fail("load config", err)
+	}
+	a := &chat.Assistant{Store: store, Model: model, Redact: redact}
+	n, err := a.UpdateEmbeddings(ctx, cmd == "rebuild")
+	if err != nil {
+		fail(cmd, err)
+	}
+	fmt.Fprintf(os.Stderr, "webcasa: embedded %d pages and notes with %s\n", n, cfg.LLM.EmbeddingModel)
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/cpcloud/webcasa/internal/config"
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// dbPassphraseEnv holds the passphrase of an encrypted database.
+const dbPassphraseEnv = "WEBCASA_DB_PASSPHRASE"
+
+// runEncrypt encrypts a plain database in place, so it can only be opened
+// with its passphrase from then on.
+func runEncrypt(args []string) {
+	fs := flag.NewFlagSet("encrypt", flag.ExitOnError)
+	dbPath := fs.String("db", "", "SQLite database path (default: platform data dir)")
+	_ = fs.Parse(args)
+
+	path, err := resolveDB(*dbPath, false)
+	if err != nil {
+		fail("resolve db path", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		fail("encrypt database", err)
+	}
+	passphrase, err := readSecret(dbPassphraseEnv, "passphrase", "New database passphrase", true)
+	if err != nil {
+		fail("read passphrase", err)
+	}
+	convertInPlace(path, func(tmp string) error { return data.EncryptDatabase(path, tmp, passphrase) })
+	fmt.Fprintf(os.Stderr, "webcasa: encrypted %s; keep the passphrase safe, it can't be recovered\n", path)
+}
+
+// runDecrypt turns an encrypted database back into a plain one in place.
+func runDecrypt(args []string) {
+	fs := flag.NewFlagSet("decrypt", flag.ExitOnError)
+	dbPath := fs.String("db", "", "SQLite database path (default: platform data dir)")
+	_ = fs.Parse(args)
+
+	path, err := resolveDB(*dbPath, false)
+	if err != nil {
+		fail("resolve db path", err)
+	}
+	if encrypted, err := data.IsEncrypted(path); err != nil {
+		fail("decrypt database", err)
+	} else if !encrypted {
+		fail("decrypt database", fmt.Errorf("%s isn't encrypted", path))
+	}
+	passphrase, err := dbPassphrase()
+	if err != nil {
+		fail("read passphrase", err)
+	}
+	convertInPlace(path, func(tmp string) error { return data.DecryptDatabase(path, tmp, passphrase) })
+	fmt.Fprintf(os.Stderr, "webcasa: decrypted %s\n", path)
+}
+
+// convertInPlace has write produce the converted database beside path, then
+// moves it over path. The old WAL and shared-memory files go with the old
+// database: left behind, they'd hold its pages in the clear, or be applied
+// to the new one. webcasa mustn't be running on the database meanwhile.
+func convertInPlace(path string, write func(tmp string) error) {
+	tmp := path + ".converting"
+	if err := write(tmp); err != nil {
+		_ = os.Remove(tmp)
+		fail("convert database", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		fail("convert database", err)
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(path + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			fail("convert database", err)
+		}
+	}
+}
+
+// openDatabase opens the database at path, asking for its passphrase if it
+// is encrypted.
+func openDatabase(path string, readOnly bool) (*data.Store, error) {
+	encrypted, err := data.IsEncrypted(path)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case encrypted && readOnly:
+		passphrase, err := dbPassphrase()
+		if err != nil {
+			return nil, err
+		}
+		return data.OpenEncryptedReadOnly(path, passphrase)
+	case encrypted:
+		passphrase, err := dbPassphrase()
+		if err != nil {
+			return nil, err
+		}
+		return data.OpenEncrypted(path, passphrase)
+	case readOnly:
+		return data.OpenReadOnly(path)
+	}
+	return data.Open(path)
+}
+
+// dbPassphrase takes an encrypted database's passphrase from the
+// environment, from database.passphrase_command, or from a prompt.
+func dbPassphrase() (string, error) {
+	if p := os.Getenv(dbPassphraseEnv); p != "" {
+		return p, nil
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return "", fmt.Errorf("load config: %w", err)
+	}
+	if argv := cfg.Database.PassphraseCommand; len(argv) > 0 {
+		var stderr bytes.Buffer
+		cmd := exec.Command(argv[0], argv[1:]...) //nolint:gosec // the user's own configured command
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("run database.passphrase_command: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+		}
+		return string(bytes.TrimRight(out, "\r\n")), nil
+	}
+	return readSecret(dbPassphraseEnv, "passphrase", "Database passphrase", false)
+}
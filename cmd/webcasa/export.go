@@ -0,0 +1,425 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/bundle"
+	"github.com/cpcloud/webcasa/internal/config"
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/exports"
+)
+
+// passphraseEnv lets scripts supply the bundle passphrase without a prompt.
+const passphraseEnv = exports.PassphraseEnv
+
+const exportUsage = `usage: webcasa export <command> [flags]
+
+commands:
+  emergency-bundle   write an encrypted zip of insurance documents, inventory,
+                     house profile, room photos, and contacts
+  decrypt-bundle     decrypt an emergency bundle back to a plain zip
+  handoff            write a zip of every record and file for a property
+                     manager or family member, with sensitive fields redacted
+  tables             write projects, vendors, quotes, appliances, maintenance,
+                     service logs and the house profile as CSV or JSON
+  service-history    write an appliance's service record, oldest first, as a
+                     PDF or CSV for a warranty claim or a buyer
+  inventory          write the house's valuables room by room, with their
+                     total replacement value, as a PDF or CSV for an insurer
+`
+
+func runExport(args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, exportUsage)
+		os.Exit(2)
+	}
+	switch args[0] {
+	case "emergency-bundle":
+		exportEmergencyBundle(args[1:])
+	case "decrypt-bundle":
+		decryptBundle(args[1:])
+	case "handoff":
+		exportHandoff(args[1:])
+	case "tables":
+		exportTables(args[1:])
+	case "service-history":
+		exportServiceHistory(args[1:])
+	case "inventory":
+		exportInventory(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "webcasa: unknown export command %q\n\n%s", args[0], exportUsage)
+		os.Exit(2)
+	}
+}
+
+func exportEmergencyBundle(args []string) {
+	fs := flag.NewFlagSet("export emergency-bundle", flag.ExitOnError)
+	dbPath := fs.String("db", "", "SQLite database path (default: platform data dir)")
+	out := fs.String("o", "", "output file (default: webcasa-emergency-<date>.zip.enc)")
+	_ = fs.Parse(args)
+
+	now := time.Now()
+	if *out == "" {
+		*out = fmt.Sprintf("webcasa-emergency-%s.zip.enc", now.Format(time.DateOnly))
+	}
+
+	store := openExistingStore(*dbPath)
+	defer store.Close()
+
+	var zipped bytes.Buffer
+	m, err := bundle.Emergency(store, &zipped, now)
+	if err != nil {
+		fail("build emergency bundle", err)
+	}
+	passphrase, err := readPassphrase(true)
+	if err != nil {
+		fail("read passphrase", err)
+	}
+	sealed, err := bundle.Seal(zipped.Bytes(), passphrase)
+	if err != nil {
+		fail("encrypt bundle", err)
+	}
+	if err := os.WriteFile(*out, sealed, 0o600); err != nil {
+		fail("write bundle", err)
+	}
+	fmt.Fprintf(os.Stderr,
+		"webcasa: wrote %s -- %d insurance document(s), %d inventory item(s), %d room photo(s), %d floor plan(s), %d contact(s)\n",
+		*out, m.InsuranceDocuments, m.InventoryItems, m.RoomPhotos, m.FloorPlans, m.Contacts)
+}
+
+func decryptBundle(args []string) {
+	fs := flag.NewFlagSet("export decrypt-bundle", flag.ExitOnError)
+	out := fs.String("o", "", "output zip (default: input name without .enc)")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: webcasa export decrypt-bundle [-o out.zip] BUNDLE")
+		os.Exit(2)
+	}
+	in := fs.Arg(0)
+	if *out == "" {
+		*out = strings.TrimSuffix(in, ".enc")
+		if *out == in {
+			*out = in + ".zip"
+		}
+	}
+
+	sealed, err := os.ReadFile(in)
+	if err != nil {
+		fail("read bundle", err)
+	}
+	passphrase, err := readPassphrase(false)
+	if err != nil {
+		fail("read passphrase", err)
+	}
+	plain, err := bundle.Open(sealed, passphrase)
+	if err != nil {
+		fail("decrypt bundle", err)
+	}
+	if err := os.WriteFile(*out, plain, 0o600); err != nil {
+		fail("write zip", err)
+	}
+	fmt.Fprintf(os.Stderr, "webcasa: wrote %s\n", *out)
+}
+
+func exportHandoff(args []string) {
+	fs := flag.NewFlagSet("export handoff", flag.ExitOnError)
+	dbPath := fs.String("db", "", "SQLite database path (default: platform data dir)")
+	out := fs.String("o", "", "output file (default: webcasa-records-<date>.zip)")
+	redactList := fs.String("redact", strings.Join(bundle.RedactionCategories(), ","),
+		"comma-separated categories ("+strings.Join(bundle.RedactionCategories(), ", ")+
+			"), field names, or table.Field to redact; empty for none")
+	encrypt := fs.Bool("encrypt", false, "encrypt the zip with a passphrase, like the emergency bundle")
+	_ = fs.Parse(args)
+
+	redact, err := bundle.ParseRedaction(strings.Split(*redactList, ","))
+	if err != nil {
+		fail("parse -redact", err)
+	}
+	now := time.Now()
+	if *out == "" {
+		*out = fmt.Sprintf("webcasa-records-%s.zip", now.Format(time.DateOnly))
+		if *encrypt {
+			*out += ".enc"
+		}
+	}
+
+	store := openExistingStore(*dbPath)
+	defer store.Close()
+
+	var zipped bytes.Buffer
+	m, err := bundle.Archive(store, &zipped, now, redact)
+	if err != nil {
+		fail("build archive", err)
+	}
+	body := zipped.Bytes()
+	if *encrypt {
+		passphrase, err := readPassphrase(true)
+		if err != nil {
+			fail("read passphrase", err)
+		}
+		if body, err = bundle.Seal(body, passphrase); err != nil {
+			fail("encrypt archive", err)
+		}
+	}
+	if err := os.WriteFile(*out, body, 0o600); err != nil {
+		fail("write archive", err)
+	}
+	fmt.Fprintf(os.Stderr,
+		"webcasa: wrote %s -- %d record(s), %d document(s), %d floor plan(s); %d value(s) redacted, %d insurance document(s) left out\n",
+		*out, m.Records, m.Documents, m.FloorPlans, m.RedactedValues, m.WithheldDocuments)
+}
+
+func exportTables(args []string) {
+	fs := flag.NewFlagSet("export tables", flag.ExitOnError)
+	dbPath := fs.String("db", "", "SQLite database path (default: platform data dir)")
+	format := fs.String("format", "csv", "csv or json")
+	out := fs.String("o", "", "output directory, or file with -bundle (default: webcasa-tables-<date>)")
+	single := fs.Bool("bundle", false, "write one file: a zip of CSVs, or one JSON object")
+	tableList := fs.String("tables", strings.Join(data.DumpTableNames(), ","), "comma-separated tables to write")
+	withDeleted := fs.Bool("include-deleted", false, "include rows in the trash")
+	since := fs.String("since", "", "only rows dated on or after YYYY-MM-DD (service date for service logs, creation date otherwise)")
+	until := fs.String("until", "", "only rows dated on or before YYYY-MM-DD")
+	_ = fs.Parse(args)
+	if *format != "csv" && *format != "json" {
+		fail("parse -format", fmt.Errorf("want csv or json, got %q", *format))
+	}
+
+	opts := data.DumpOptions{IncludeDeleted: *withDeleted}
+	var err error
+	if *since != "" {
+		if opts.Since, err = time.ParseInLocation(time.DateOnly, *since, time.Local); err != nil {
+			fail("parse -since", err)
+		}
+	}
+	if *until != "" {
+		if opts.Until, err = time.ParseInLocation(time.DateOnly, *until, time.Local); err != nil {
+			fail("parse -until", err)
+		}
+		opts.Until = opts.Until.AddDate(0, 0, 1)
+	}
+
+	now := time.Now()
+	if *out == "" {
+		*out = "webcasa-tables-" + now.Format(time.DateOnly)
+		switch {
+		case *single && *format == "json":
+			*out += ".json"
+		case *single:
+			*out += ".zip"
+		}
+	}
+
+	store := openExistingStore(*dbPath)
+	defer store.Close()
+	var tables []data.DumpedTable
+	rows := 0
+	for _, name := range strings.Split(*tableList, ",") {
+		t, err := store.DumpTable(strings.TrimSpace(name), opts)
+		if err != nil {
+			fail("export "+name, err)
+		}
+		tables = append(tables, t)
+		rows += len(t.Rows)
+	}
+
+	if *single {
+		var body []byte
+		if *format == "json" {
+			body, err = exports.TablesJSON(tables)
+		} else {
+			body, err = zipTables(tables, now)
+		}
+		if err != nil {
+			fail("write tables", err)
+		}
+		if err := os.WriteFile(*out, body, 0o600); err != nil {
+			fail("write tables", err)
+		}
+	} else {
+		if err := os.MkdirAll(*out, 0o700); err != nil {
+			fail("create output directory", err)
+		}
+		for _, t := range tables {
+			body, err := renderTable(t, *format)
+			if err != nil {
+				fail("write "+t.Name, err)
+			}
+			if err := os.WriteFile(filepath.Join(*out, t.Name+"."+*format), body, 0o600); err != nil {
+				fail("write "+t.Name, err)
+			}
+		}
+	}
+	fmt.Fprintf(os.Stderr, "webcasa: wrote %s -- %d table(s), %d row(s)\n", *out, len(tables), rows)
+}
+
+func exportServiceHistory(args []string) {
+	fs := flag.NewFlagSet("export service-history", flag.ExitOnError)
+	dbPath := fs.String("db", "", "SQLite database path (default: platform data dir)")
+	appliance := fs.Uint("appliance", 0, "ID of the appliance")
+	format := fs.String("format", exports.ServiceHistoryPDF, "pdf or csv")
+	out := fs.String("o", "", "output file (default: service-history-<appliance>-<date>.<format>)")
+	_ = fs.Parse(args)
+	if *appliance == 0 {
+		fmt.Fprintln(os.Stderr, "usage: webcasa export service-history -appliance ID [-format pdf|csv] [-o FILE]")
+		os.Exit(2)
+	}
+	if *format != exports.ServiceHistoryPDF && *format != exports.ServiceHistoryCSV {
+		fail("parse -format", fmt.Errorf("want pdf or csv, got %q", *format))
+	}
+
+	store := openExistingStore(*dbPath)
+	defer store.Close()
+	h, err := exports.ApplianceServiceHistory(store, *appliance, time.Now())
+	if err != nil {
+		fail(fmt.Sprintf("load appliance %d", *appliance), err)
+	}
+	artifact, err := h.Artifact(*format)
+	if err != nil {
+		fail("write service history", err)
+	}
+	if *out == "" {
+		*out = artifact.FileName
+	}
+	if err := os.WriteFile(*out, artifact.Body, 0o600); err != nil {
+		fail("write service history", err)
+	}
+	fmt.Fprintf(os.Stderr, "webcasa: wrote %s -- %d visit(s) to %s\n", *out, len(h.Visits), h.Appliance.Name)
+}
+
+func exportInventory(args []string) {
+	fs := flag.NewFlagSet("export inventory", flag.ExitOnError)
+	dbPath := fs.String("db", "", "SQLite database path (default: platform data dir)")
+	format := fs.String("format", exports.InventoryPDF, "pdf or csv")
+	out := fs.String("o", "", "output file (default: home-inventory-<date>.<format>)")
+	_ = fs.Parse(args)
+	if *format != exports.InventoryPDF && *format != exports.InventoryCSV {
+		fail("parse -format", fmt.Errorf("want pdf or csv, got %q", *format))
+	}
+
+	store := openExistingStore(*dbPath)
+	defer store.Close()
+	inv, err := exports.Inventory(store, time.Now())
+	if err != nil {
+		fail("load inventory", err)
+	}
+	artifact, err := inv.Artifact(*format)
+	if err != nil {
+		fail("write inventory", err)
+	}
+	if *out == "" {
+		*out = artifact.FileName
+	}
+	if err := os.WriteFile(*out, artifact.Body, 0o600); err != nil {
+		fail("write inventory", err)
+	}
+	fmt.Fprintf(os.Stderr, "webcasa: wrote %s -- %d item(s) worth %s to replace\n",
+		*out, inv.Items, data.FormatCents(inv.ReplacementCents))
+}
+
+func renderTable(t data.DumpedTable, format string) ([]byte, error) {
+	if format == "json" {
+		return exports.TableJSON(t)
+	}
+	return exports.TableCSV(t)
+}
+
+// zipTables puts one CSV per table in a zip.
+func zipTables(tables []data.DumpedTable, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, t := range tables {
+		body, err := exports.TableCSV(t)
+		if err != nil {
+			return nil, err
+		}
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: t.Name + ".csv", Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Write(body); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// openExistingStore opens the database for a one-shot command, bringing the
+// schema up to date first.
+func openExistingStore(path string) *data.Store {
+	resolved, err := resolveDB(path, false)
+	if err != nil {
+		fail("resolve db path", err)
+	}
+	store, err := openDatabase(resolved, false)
+	if err != nil {
+		fail("open database", err)
+	}
+	if err := store.AutoMigrate(); err != nil {
+		fail("migrate database", err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		fail("load config", err)
+	}
+	useDocumentStorage(store, resolved, cfg.Documents, false)
+	return store
+}
+
+// readPassphrase takes the passphrase from the environment, or prompts for
+// it on stderr and reads it from stdin. When confirm is set and stdin is a
+// terminal, the passphrase is asked for twice.
+func readPassphrase(confirm bool) (string, error) {
+	return readSecret(passphraseEnv, "passphrase", "Bundle passphrase", confirm)
+}
+
+// readSecret takes a secret from the environment variable env, or prompts
+// for it on stderr and reads it from stdin. When confirm is set and stdin
+// is a terminal, the secret is asked for twice. what names it in errors.
+func readSecret(env, what, label string, confirm bool) (string, error) {
+	if p := os.Getenv(env); p != "" {
+		return p, nil
+	}
+	interactive := false
+	if fi, err := os.Stdin.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+		interactive = true
+	}
+	in := bufio.NewReader(os.Stdin)
+	prompt := func(label string) (string, error) {
+		if interactive {
+			fmt.Fprint(os.Stderr, label)
+		}
+		line, err := in.ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("no %s given (set %s or pipe it on stdin)", what, env)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+	p, err := prompt(label + ": ")
+	if err != nil {
+		return "", err
+	}
+	if confirm && interactive {
+		again, err := prompt("Repeat " + what + ": ")
+		if err != nil {
+			return "", err
+		}
+		if again != p {
+			return "", fmt.Errorf("%ss don't match", what)
+		}
+	}
+	return p, nil
+}
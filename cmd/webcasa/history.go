@@ -0,0 +1,84 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+const historyUsage = `usage: webcasa history [command] [flags]
+
+commands:
+  list  show the recent edits, deletions and restorations, newest first;
+        -n how many (default 20). The default command.
+  undo  take back the newest step
+  redo  put back the step undone last
+`
+
+// runHistory shows the undo log, or steps through it the way Undo and
+// Redo do in the web interface.
+func runHistory(args []string) {
+	cmd := "list"
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		cmd, args = args[0], args[1:]
+	}
+	fs := flag.NewFlagSet("history "+cmd, flag.ExitOnError)
+	dbPath := fs.String("db", "", "SQLite database path (default: platform data dir)")
+	limit := fs.Int("n", 20, "how many steps to list")
+	_ = fs.Parse(args)
+
+	var step func(*data.Store) (data.UndoEntry, error)
+	switch cmd {
+	case "list":
+	case "undo":
+		step = (*data.Store).Undo
+	case "redo":
+		step = (*data.Store).Redo
+	default:
+		fmt.Fprint(os.Stderr, historyUsage)
+		os.Exit(2)
+	}
+
+	store := openExistingStore(*dbPath)
+	defer store.Close()
+	if step != nil {
+		entry, err := step(store)
+		if err != nil {
+			fail(cmd, err)
+		}
+		fmt.Fprintf(os.Stderr, "webcasa: %s %s of %s\n", cmd, entry.Action, describeStep(entry))
+		return
+	}
+
+	entries, err := store.UndoLog(*limit)
+	if err != nil {
+		fail("load history", err)
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(os.Stderr, "webcasa: no history yet")
+		return
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "WHEN\tACTION\tRECORD\tFIELDS\t")
+	for _, e := range entries {
+		action := e.Action
+		if e.UndoneAt != nil {
+			action += " (undone)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t\n",
+			e.At.Local().Format(time.DateTime), action, describeStep(e), e.Fields)
+	}
+	_ = tw.Flush()
+}
+
+// describeStep names the row a step changed, as in "appliances #3".
+func describeStep(e data.UndoEntry) string {
+	return fmt.Sprintf("%s #%d", e.Entity, e.TargetID)
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"gorm.io/gorm"
+)
+
+const houseUsage = `usage: webcasa house <command> [flags]
+
+commands:
+  list             show the active houses; -archived includes archived ones
+  switch ID        make a house the current one, the one its records show for
+  archive ID       put a house away, e.g. once it's sold, keeping its records
+  reactivate ID    bring an archived house back
+`
+
+func runHouse(args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, houseUsage)
+		os.Exit(2)
+	}
+	fs := flag.NewFlagSet("house "+args[0], flag.ExitOnError)
+	dbPath := fs.String("db", "", "SQLite database path (default: platform data dir)")
+	archived := fs.Bool("archived", false, "include archived houses")
+	_ = fs.Parse(args[1:])
+
+	switch args[0] {
+	case "list":
+		store := openExistingStore(*dbPath)
+		defer store.Close()
+		listHouses(store, *archived)
+	case "switch", "archive", "reactivate":
+		if fs.NArg() != 1 {
+			fmt.Fprintf(os.Stderr, "usage: webcasa house %s [-db path] ID\n", args[0])
+			os.Exit(2)
+		}
+		id, err := strconv.ParseUint(fs.Arg(0), 10, 64)
+		if err != nil || id == 0 {
+			fail(args[0]+" house", fmt.Errorf("invalid id %q", fs.Arg(0)))
+		}
+		store := openExistingStore(*dbPath)
+		defer store.Close()
+		switch args[0] {
+		case "switch":
+			err = store.SwitchHouse(uint(id))
+		case "archive":
+			err = store.ArchiveHouse(uint(id))
+		default:
+			err = store.ReactivateHouse(uint(id))
+		}
+		if err != nil {
+			fail(args[0]+" house", err)
+		}
+		if args[0] == "switch" {
+			fmt.Fprintf(os.Stderr, "webcasa: switched to house %d\n", id)
+		} else {
+			fmt.Fprintf(os.Stderr, "webcasa: %sd house %d\n", args[0], id)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "webcasa: unknown house command %q\n\n%s", args[0], houseUsage)
+		os.Exit(2)
+	}
+}
+
+func listHouses(store *data.Store, includeArchived bool) {
+	houses, err := store.ListHouses(includeArchived)
+	if err != nil {
+		fail("list houses", err)
+	}
+	if len(houses) == 0 {
+		fmt.Fprintln(os.Stderr, "webcasa: no houses")
+		return
+	}
+	current, err := store.HouseProfile()
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		fail("list houses", err)
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNICKNAME\tADDRESS\tSTATUS")
+	for _, h := range houses {
+		status := "active"
+		if h.ID == current.ID {
+			status = "current"
+		} else if h.ArchivedAt != nil {
+			status = "archived " + h.ArchivedAt.Local().Format(time.DateOnly)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", h.ID, h.Nickname, h.AddressLine1, status)
+	}
+	_ = tw.Flush()
+}
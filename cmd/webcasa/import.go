@@ -0,0 +1,160 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"archive/zip"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/exports"
+)
+
+const importUsage = `usage: webcasa import [flags] <path>
+
+Loads tables written by "webcasa export tables" into the database, keeping
+their IDs. <path> is an export directory, a -bundle zip of CSVs, or a
+-bundle JSON file. Rows with a new ID are added; -on-conflict says what to
+do with the rest:
+
+  skip        keep the row already in the database (default)
+  overwrite   replace it with the imported row
+  merge       replace it, except where the imported cell is empty
+
+Every project, vendor, maintenance item and appliance a row refers to must
+be in the import or the database, or nothing is loaded.
+
+flags:
+`
+
+func runImport(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	dbPath := fs.String("db", "", "SQLite database path, created if missing (default: platform data dir)")
+	onConflict := fs.String("on-conflict", string(data.ImportSkip), "skip, overwrite or merge")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, importUsage)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	tables, err := readTables(fs.Arg(0))
+	if err != nil {
+		fail("read "+fs.Arg(0), err)
+	}
+
+	store := openExistingStore(*dbPath)
+	defer store.Close()
+	if err := store.SeedDefaults(); err != nil {
+		fail("seed defaults", err)
+	}
+	results, err := store.ImportTables(tables, data.ImportStrategy(*onConflict))
+	var importErr *data.ImportError
+	if errors.As(err, &importErr) {
+		fmt.Fprintln(os.Stderr, "webcasa: nothing was imported; these rows refer to records that don't exist:")
+		for _, p := range importErr.Problems {
+			fmt.Fprintf(os.Stderr, "  %s\n", p)
+		}
+		os.Exit(1)
+	}
+	if err != nil {
+		fail("import", err)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TABLE\tADDED\tUPDATED\tUNCHANGED")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", r.Name, r.Added, r.Updated, r.Unchanged)
+	}
+	_ = tw.Flush()
+}
+
+// readTables reads an export directory, zip of CSVs, or JSON bundle.
+func readTables(path string) ([]data.DumpedTable, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case info.IsDir():
+		return readTableDir(path)
+	case strings.EqualFold(filepath.Ext(path), ".zip"):
+		return readTableZip(path)
+	default:
+		body, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return exports.ReadTablesJSON(body)
+	}
+}
+
+// readTableDir reads the <table>.csv or <table>.json files in dir.
+func readTableDir(dir string) ([]data.DumpedTable, error) {
+	var tables []data.DumpedTable
+	for _, name := range data.DumpTableNames() {
+		base := filepath.Join(dir, name)
+		if f, err := os.Open(base + ".csv"); err == nil {
+			t, err := exports.ReadTableCSV(name, f)
+			_ = f.Close()
+			if err != nil {
+				return nil, err
+			}
+			tables = append(tables, t)
+			continue
+		}
+		body, err := os.ReadFile(base + ".json")
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		t, err := exports.ReadTableJSON(name, body)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("no table files (%s.csv, ...)", data.DumpTableNames()[0])
+	}
+	return tables, nil
+}
+
+// readTableZip reads the <table>.csv files in a zip.
+func readTableZip(path string) ([]data.DumpedTable, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	var tables []data.DumpedTable
+	for _, f := range zr.File {
+		name, ok := strings.CutSuffix(f.Name, ".csv")
+		if !ok {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		t, err := exports.ReadTableCSV(name, r)
+		_ = r.Close()
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+	return tables, nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// verifyDatabase checks the database for damage and, if there is any,
+// exits with instructions for restoring it instead of starting.
+func verifyDatabase(store *data.Store, dbPath, backupDir string) {
+	fmt.Fprintf(os.Stderr, "webcasa: checking database integrity...\n")
+	err := store.CheckIntegrity()
+	var damaged *data.IntegrityError
+	if !errors.As(err, &damaged) {
+		if err != nil {
+			fail("check database", err)
+		}
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "webcasa: %s is damaged; not starting.\n", dbPath)
+	for _, p := range damaged.Problems {
+		fmt.Fprintf(os.Stderr, "  %s\n", p)
+	}
+	fmt.Fprintf(os.Stderr, "\nTo restore it, keep a copy of the damaged file, then ")
+	if backup := latestBackup(backupDir); backup != "" {
+		fmt.Fprintf(os.Stderr, "copy the latest backup over it:\n\n  cp %q %q\n  rm -f %q %q\n  cp %q %q\n\nChanges made since it was taken are lost.\n",
+			dbPath, dbPath+".damaged", dbPath+"-wal", dbPath+"-shm", backup, dbPath)
+	} else {
+		fmt.Fprintf(os.Stderr, "copy your most recent backup over it. None was found in %s.\n", backupDir)
+	}
+	fmt.Fprintf(os.Stderr, "\nWithout a backup, sqlite3 can salvage what is readable:\n\n  sqlite3 %q .recover | sqlite3 %q\n",
+		dbPath, dbPath+".recovered")
+	fmt.Fprintf(os.Stderr, "\nSet database.verify_on_open = false to start anyway.\n")
+	os.Exit(1)
+}
+
+// latestBackup returns the newest "backup now" file in dir, or "" if there
+// is none. Their names sort by when they were taken.
+func latestBackup(dir string) string {
+	backups, _ := filepath.Glob(filepath.Join(dir, "webcasa-*.db"))
+	if len(backups) == 0 {
+		return ""
+	}
+	return slices.Max(backups)
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/config"
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/sched"
+)
+
+const jobsUsage = `usage: webcasa jobs <command> [flags]
+
+commands:
+  list         show background jobs with their schedule, last run, and next run
+  run NAME     run a job now, regardless of its schedule
+`
+
+// newScheduler registers every background job the config asks for.
+func newScheduler(store *data.Store, cfg config.Config) (*sched.Scheduler, error) {
+	s := sched.New(store)
+	exportJobs, err := cfg.ExportJobs()
+	if err != nil {
+		return nil, err
+	}
+	for _, j := range exportJobs {
+		if err := s.Add(j.SchedJob(store)); err != nil {
+			return nil, err
+		}
+	}
+	reminders, err := cfg.ReminderJob(os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+	if reminders != nil {
+		if err := s.Add(reminders.SchedJob(store)); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func runJobs(args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, jobsUsage)
+		os.Exit(2)
+	}
+	fs := flag.NewFlagSet("jobs "+args[0], flag.ExitOnError)
+	dbPath := fs.String("db", "", "SQLite database path (default: platform data dir)")
+	_ = fs.Parse(args[1:])
+
+	switch args[0] {
+	case "list":
+		scheduler := openScheduler(*dbPath)
+		listJobs(scheduler)
+	case "run":
+		if fs.NArg() != 1 {
+			fmt.Fprintln(os.Stderr, "usage: webcasa jobs run [-db path] NAME")
+			os.Exit(2)
+		}
+		scheduler := openScheduler(*dbPath)
+		scheduler.Log = os.Stderr
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		if err := scheduler.RunNow(ctx, fs.Arg(0)); err != nil {
+			fail("run "+fs.Arg(0), err)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "webcasa: unknown jobs command %q\n\n%s", args[0], jobsUsage)
+		os.Exit(2)
+	}
+}
+
+func openScheduler(dbPath string) *sched.Scheduler {
+	cfg, err := config.Load()
+	if err != nil {
+		fail("load config", err)
+	}
+	store := openExistingStore(dbPath)
+	scheduler, err := newScheduler(store, cfg)
+	if err != nil {
+		fail("set up jobs", err)
+	}
+	return scheduler
+}
+
+func listJobs(scheduler *sched.Scheduler) {
+	statuses, err := scheduler.Status()
+	if err != nil {
+		fail("list jobs", err)
+	}
+	if len(statuses) == 0 {
+		fmt.Fprintln(os.Stderr, "webcasa: no background jobs configured")
+		return
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSCHEDULE\tLAST RUN\tRESULT\tNEXT RUN")
+	for _, st := range statuses {
+		lastRun, result := "never", "-"
+		if st.LastRun != nil {
+			lastRun = st.LastRun.LastRunAt.Local().Format(time.DateTime)
+			result = "ok"
+			if st.LastRun.LastError != "" {
+				result = "error: " + st.LastRun.LastError
+			}
+		}
+		next := "-"
+		if !st.NextRunAt.IsZero() {
+			next = st.NextRunAt.Local().Format(time.DateTime)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", st.Name, st.Schedule, lastRun, result, next)
+	}
+	_ = tw.Flush()
+}
@@ -14,32 +14,144 @@ import (
 	"time"
 
 	"github.com/cpcloud/webcasa/internal/api"
+	"github.com/cpcloud/webcasa/internal/chat"
+	"github.com/cpcloud/webcasa/internal/config"
 	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/hooks"
+	"github.com/cpcloud/webcasa/internal/llm"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "backup":
+			runBackup(os.Args[2:])
+			return
+		case "decrypt":
+			runDecrypt(os.Args[2:])
+			return
+		case "doctor":
+			runDoctor(os.Args[2:])
+			return
+		case "embeddings":
+			runEmbeddings(os.Args[2:])
+			return
+		case "encrypt":
+			runEncrypt(os.Args[2:])
+			return
+		case "export":
+			runExport(os.Args[2:])
+			return
+		case "history":
+			runHistory(os.Args[2:])
+			return
+		case "house":
+			runHouse(os.Args[2:])
+			return
+		case "import":
+			runImport(os.Args[2:])
+			return
+		case "jobs":
+			runJobs(os.Args[2:])
+			return
+		case "remind":
+			runRemind(os.Args[2:])
+			return
+		case "report":
+			runReport(os.Args[2:])
+			return
+		case "restore":
+			runRestore(os.Args[2:])
+			return
+		case "repair":
+			runRepair(os.Args[2:])
+			return
+		case "stats":
+			runStats(os.Args[2:])
+			return
+		case "tokens":
+			runTokens(os.Args[2:])
+			return
+		case "user":
+			runUser(os.Args[2:])
+			return
+		}
+	}
+
 	addr := flag.String("addr", ":8080", "listen address (host:port)")
 	dbPath := flag.String("db", "", "SQLite database path (default: platform data dir)")
 	demo := flag.Bool("demo", false, "seed demo data into an in-memory database")
 	webDir := flag.String("web-dir", "web", "path to web/ directory for static files")
+	readOnly := flag.Bool("force-read-only", false, "open the database read-only, e.g. one from a newer webcasa")
+	basePath := flag.String("base-path", "", "URL prefix to serve under, e.g. /casa (default: server.base_path)")
+	var tf tlsFlags
+	flag.StringVar(&tf.cert, "tls-cert", "", "serve HTTPS with this certificate file (PEM, with any intermediates)")
+	flag.StringVar(&tf.key, "tls-key", "", "private key file for -tls-cert (PEM)")
+	flag.StringVar(&tf.acmeDomains, "acme-domain", "", "serve HTTPS with certificates from Let's Encrypt for these comma-separated domains; -addr defaults to :443")
+	flag.StringVar(&tf.acmeEmail, "acme-email", "", "contact address given to Let's Encrypt for expiry notices (optional)")
+	flag.StringVar(&tf.acmeCache, "acme-cache", data.CertCacheDir(), "directory to keep Let's Encrypt certificates in")
+	flag.StringVar(&tf.acmeHTTP, "acme-http", ":80", "with -acme-domain, answer HTTP challenges and redirect to HTTPS here; empty to turn off")
 	flag.Parse()
+	if err := tf.validate(); err != nil {
+		fail("parse flags", err)
+	}
+	if tf.acmeDomains != "" && !flagSet("addr") {
+		*addr = ":443"
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fail("load config", err)
+	}
+	if *basePath != "" {
+		if cfg.Server.BasePath, err = config.CleanBasePath(*basePath); err != nil {
+			fail("parse --base-path", err)
+		}
+	}
+	proxies, err := cfg.Server.Proxies()
+	if err != nil {
+		fail("load config", err)
+	}
 
 	resolvedDB, err := resolveDB(*dbPath, *demo)
 	if err != nil {
 		fail("resolve db path", err)
 	}
 
-	store, err := data.Open(resolvedDB)
+	if *readOnly && *demo {
+		fail("open database", fmt.Errorf("--force-read-only can't be combined with --demo"))
+	}
+	store, err := openDatabase(resolvedDB, *readOnly)
 	if err != nil {
 		fail("open database", err)
 	}
 	defer store.Close()
+	store.SetHealthLog(os.Stderr)
+	if cfg.Database.VerifyOnOpen && !*demo {
+		verifyDatabase(store, resolvedDB, cfg.Admin.BackupDir)
+	}
 
-	if err := store.AutoMigrate(); err != nil {
-		fail("migrate database", err)
+	if *readOnly {
+		version, err := store.SchemaVersion()
+		if err != nil {
+			fail("open database", err)
+		}
+		fmt.Fprintf(os.Stderr, "webcasa: database opened read-only (schema version %d, this build writes %d)\n",
+			version, data.SchemaVersion)
+	} else {
+		if err := store.AutoMigrate(); err != nil {
+			fail("migrate database", err)
+		}
+		if err := store.SeedDefaults(); err != nil {
+			fail("seed defaults", err)
+		}
+		if err := store.SetUndoDepth(cfg.Database.UndoDepth); err != nil {
+			fail("trim undo log", err)
+		}
 	}
-	if err := store.SeedDefaults(); err != nil {
-		fail("seed defaults", err)
+	useDocumentStorage(store, resolvedDB, cfg.Documents, *readOnly)
+	if !*readOnly {
+		tidyDocumentFiles(store, cfg.Documents.Storage == config.StorageFiles)
 	}
 	if *demo {
 		if err := store.SeedDemoData(); err != nil {
@@ -48,40 +160,214 @@ func main() {
 		fmt.Fprintf(os.Stderr, "webcasa: demo data seeded\n")
 	}
 
+	scheduler, err := newScheduler(store, cfg)
+	if err != nil {
+		fail("set up jobs", err)
+	}
+	scheduler.Log = os.Stderr
+	configTOML, err := cfg.Redacted().TOML()
+	if err != nil {
+		fail("render config", err)
+	}
+
+	hookList, err := cfg.HookList()
+	if err != nil {
+		fail("load config", err)
+	}
+	dispatcher := hooks.NewDispatcher(hookList, os.Stderr)
+	defer dispatcher.Close(10 * time.Second)
+
+	model := newLLMClient(cfg.LLM)
+	budget := newBudget(store, cfg.LLM.Budget)
+	if budget != nil {
+		model.Meter = budget
+	}
+	redact, err := newRedactor(cfg.LLM)
+	if err != nil {
+		fail("load config", err)
+	}
+	handler := api.NewServer(store, *webDir,
+		api.WithWaterLimits(cfg.Water.Limits()),
+		api.WithImageCompression(cfg.Documents.ImageOptions()),
+		api.WithLLM(model),
+		api.WithChatModels(newStageClient(model, cfg.LLM.SQL), newStageClient(model, cfg.LLM.Summary)),
+		api.WithLLMBudget(budget),
+		api.WithChatRedactor(redact),
+		api.WithHooks(dispatcher),
+		api.WithAdmin(api.AdminOptions{
+			Password:   cfg.Admin.Password,
+			BackupDir:  cfg.Admin.BackupDir,
+			Scheduler:  scheduler,
+			ConfigTOML: configTOML,
+		}),
+		api.WithBasePath(cfg.Server.BasePath),
+		api.WithCORSOrigins(cfg.Server.CORSOrigins),
+		api.WithTrustedProxies(proxies),
+		api.WithGraphQL(cfg.Server.GraphQL),
+		api.WithDisabledModules(cfg.Modules.Disabled()),
+		api.WithCrashReports(data.CrashReportDir()),
+	)
 	srv := &http.Server{
 		Addr:         *addr,
-		Handler:      api.NewServer(store, *webDir),
+		Handler:      handler,
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 30 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	srv.RegisterOnShutdown(handler.CloseStreams)
+	var challenges *http.Server
+	if tf.enabled() {
+		if challenges, err = tf.configure(srv); err != nil {
+			fail("set up TLS", err)
+		}
+	}
 
 	// Graceful shutdown on SIGINT/SIGTERM.
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	if n := len(scheduler.Jobs()); n > 0 && *readOnly {
+		fmt.Fprintf(os.Stderr, "webcasa: %d background job(s) not scheduled while read-only\n", n)
+	} else if n > 0 {
+		go scheduler.Start(ctx)
+		fmt.Fprintf(os.Stderr, "webcasa: %d background job(s) scheduled\n", n)
+	}
+
 	go func() {
-		fmt.Fprintf(os.Stderr, "webcasa: listening on %s\n", *addr)
+		if tf.enabled() {
+			fmt.Fprintf(os.Stderr, "webcasa: listening on %s (%s)\n", *addr, tf.httpsURL(*addr))
+		} else {
+			fmt.Fprintf(os.Stderr, "webcasa: listening on %s\n", *addr)
+		}
+		if cfg.Server.BasePath != "" {
+			fmt.Fprintf(os.Stderr, "webcasa: serving under %s/\n", cfg.Server.BasePath)
+		}
 		if resolvedDB == ":memory:" {
 			fmt.Fprintf(os.Stderr, "webcasa: using in-memory database (demo mode)\n")
 		} else {
 			fmt.Fprintf(os.Stderr, "webcasa: database at %s\n", resolvedDB)
 		}
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		serve := srv.ListenAndServe
+		if tf.enabled() {
+			serve = func() error { return srv.ListenAndServeTLS("", "") }
+		}
+		if err := serve(); err != nil && err != http.ErrServerClosed {
 			fail("listen", err)
 		}
 	}()
+	if challenges != nil {
+		go func() {
+			fmt.Fprintf(os.Stderr, "webcasa: answering ACME challenges and redirecting to HTTPS on %s\n", challenges.Addr)
+			if err := challenges.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				fail("listen for ACME challenges", err)
+			}
+		}()
+	}
 
 	<-ctx.Done()
 	fmt.Fprintf(os.Stderr, "\nwebcasa: shutting down...\n")
 
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
+	if challenges != nil {
+		_ = challenges.Shutdown(shutdownCtx)
+	}
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		fail("shutdown", err)
 	}
 }
 
+// newLLMClient returns a client for the model configured under [llm].
+func newLLMClient(c config.LLM) *llm.Client {
+	client := &llm.Client{
+		BaseURL: c.BaseURL, Model: c.Model, ExtraContext: c.ExtraContext,
+		Window: c.ContextWindow, ProbeTimeout: c.TimeoutDuration(), EmbeddingModel: c.EmbeddingModel,
+		RequestTimeout: c.RequestTimeoutDuration(), Retries: c.Retries,
+	}
+	if c.BreakerFailures > 0 {
+		client.Breaker = &llm.Breaker{Failures: c.BreakerFailures, Cooldown: c.BreakerCooldownDuration()}
+	}
+	return client
+}
+
+// newBudget returns the budget configured under [llm.budget], or nil when
+// no cap is set.
+func newBudget(store *data.Store, c config.LLMBudget) *chat.Budget {
+	if !c.Enabled() {
+		return nil
+	}
+	return &chat.Budget{
+		Store: store, Daily: c.DailyTokens, Monthly: c.MonthlyTokens,
+		LocalOnly: c.WhenSpent == config.BudgetLocal,
+	}
+}
+
+// newRedactor returns the redactor configured under [llm.redact], or nil
+// when nothing is to be masked for the configured model.
+func newRedactor(c config.LLM) (*chat.Redactor, error) {
+	if !c.Redacting() {
+		return nil, nil
+	}
+	return chat.NewRedactor(c.Redact.Columns, c.Redact.Patterns)
+}
+
+// useDocumentStorage keeps document files where [documents] says. A
+// read-only database only reads them.
+func useDocumentStorage(store *data.Store, dbPath string, c config.Documents, readOnly bool) {
+	dir := c.FilesDirFor(dbPath)
+	inFiles := c.Storage == config.StorageFiles && !readOnly
+	if inFiles && dir == "" {
+		fmt.Fprintln(os.Stderr, "webcasa: keeping document files in the database, since it isn't a file; set documents.files_dir to keep them as files")
+		inFiles = false
+	}
+	if err := store.SetDocumentStorage(dir, inFiles); err != nil {
+		fail("set document storage", err)
+	}
+}
+
+// tidyDocumentFiles moves document files kept in the other place to where
+// they're kept now, compacting the database when they left it, and removes
+// files no document uses any more.
+func tidyDocumentFiles(store *data.Store, inFiles bool) {
+	moved, err := store.MoveDocumentFiles()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "webcasa: move document files: %v\n", err)
+	}
+	if moved > 0 && inFiles {
+		fmt.Fprintf(os.Stderr, "webcasa: moved %d document files out of the database; compacting it\n", moved)
+		if err := store.Compact(); err != nil {
+			fmt.Fprintf(os.Stderr, "webcasa: %v\n", err)
+		}
+	} else if moved > 0 {
+		fmt.Fprintf(os.Stderr, "webcasa: moved %d document files into the database\n", moved)
+	}
+	removed, freed, err := store.CollectDocumentFiles()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "webcasa: remove unused document files: %v\n", err)
+	} else if removed > 0 {
+		fmt.Fprintf(os.Stderr, "webcasa: removed %d document files no document uses, %d bytes\n", removed, freed)
+	}
+}
+
+// newStageClient returns a client for the model configured for a stage of
+// chat, such as [llm.sql], or nil when none is. It shares base's server
+// settings, breaker included.
+func newStageClient(base *llm.Client, stage config.LLMStage) *llm.Client {
+	if stage.Model == "" {
+		return nil
+	}
+	client := *base
+	client.Model, client.Window = stage.Model, stage.ContextWindow
+	return &client
+}
+
+// flagSet reports whether the named flag was given on the command line.
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) { set = set || f.Name == name })
+	return set
+}
+
 func resolveDB(path string, demo bool) (string, error) {
 	if path != "" {
 		return path, nil
@@ -0,0 +1,52 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/config"
+	"github.com/cpcloud/webcasa/internal/remind"
+)
+
+// runRemind sends what is coming due once, to the channels in [reminders]
+// or those given with -to.
+func runRemind(args []string) {
+	fs := flag.NewFlagSet("remind", flag.ExitOnError)
+	dbPath := fs.String("db", "", "SQLite database path (default: platform data dir)")
+	days := fs.Int("days", 0, "how many days ahead to look (default: reminders.days)")
+	to := fs.String("to", "", "comma-separated channels: stdout, desktop, mailto:ADDR, ntfy:URL, slack:URL, discord:URL, or a webhook URL (default: reminders.to and reminders.channel)")
+	_ = fs.Parse(args)
+
+	cfg, err := config.Load()
+	if err != nil {
+		fail("load config", err)
+	}
+	if *to != "" {
+		cfg.Reminders.To = strings.Split(*to, ",")
+		cfg.Reminders.Channels = nil
+	}
+	if *days > 0 {
+		cfg.Reminders.Days = *days
+	}
+	channels, err := cfg.ReminderChannels(os.Stdout)
+	if err != nil {
+		fail("remind", err)
+	}
+	store := openExistingStore(*dbPath)
+	defer store.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	job := remind.Job{Days: cfg.Reminders.Days, Channels: channels, URL: strings.TrimRight(cfg.Server.URL, "/")}
+	if err := job.Send(ctx, store, time.Now()); err != nil {
+		fail("remind", err)
+	}
+}
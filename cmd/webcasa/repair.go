@@ -0,0 +1,150 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+const repairUsage = `usage: webcasa repair [-db path] [-ref table.column] [-fix detach|purge | -relink ID | -i]
+
+Finds rows pointing at rows that no longer exist and lists them. With no
+fix it changes nothing.
+
+  -fix detach   clear each optional reference (documents become unattached)
+  -fix purge    delete each row holding a dangling reference, for good
+  -relink ID    point each reference at row ID instead; needs -ref
+  -i            ask what to do with each one
+`
+
+func runRepair(args []string) {
+	fs := flag.NewFlagSet("repair", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, repairUsage) }
+	dbPath := fs.String("db", "", "SQLite database path (default: platform data dir)")
+	only := fs.String("ref", "", "only repair references held in table.column")
+	fix := fs.String("fix", "", "detach or purge")
+	relink := fs.Uint("relink", 0, "row ID to point the references at")
+	interactive := fs.Bool("i", false, "ask about each reference")
+	_ = fs.Parse(args)
+
+	modes := 0
+	for _, set := range []bool{*fix != "", *relink != 0, *interactive} {
+		if set {
+			modes++
+		}
+	}
+	if modes > 1 || (*fix != "" && *fix != "detach" && *fix != "purge") || (*relink != 0 && *only == "") {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	store := openExistingStore(*dbPath)
+	defer store.Close()
+	refs, err := store.DanglingRefs()
+	if err != nil {
+		fail("find dangling references", err)
+	}
+	if *only != "" {
+		kept := refs[:0]
+		for _, r := range refs {
+			if r.Table+"."+r.Column == *only {
+				kept = append(kept, r)
+			}
+		}
+		refs = kept
+	}
+	if len(refs) == 0 {
+		fmt.Println("ok    no dangling references")
+		return
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	fixed := 0
+	for _, r := range refs {
+		fmt.Printf("note  %s\n", r)
+		var err error
+		switch {
+		case *fix == "detach":
+			err = store.DetachRef(r)
+		case *fix == "purge":
+			err = store.PurgeRef(r)
+		case *relink != 0:
+			err = store.RelinkRef(r, *relink)
+		case *interactive:
+			err = askRepair(store, in, r)
+		default:
+			continue
+		}
+		if errors.Is(err, errRepairSkipped) {
+			continue
+		}
+		if errors.Is(err, errRepairQuit) {
+			break
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "webcasa: %v\n", err)
+			continue
+		}
+		fixed++
+	}
+	if modes == 0 {
+		fmt.Fprintf(os.Stderr, "webcasa: found %d dangling reference(s); see webcasa repair -h for fixes\n", len(refs))
+		return
+	}
+	fmt.Fprintf(os.Stderr, "webcasa: repaired %d of %d dangling reference(s)\n", fixed, len(refs))
+}
+
+var (
+	errRepairSkipped = errors.New("skipped")
+	errRepairQuit    = errors.New("quit")
+)
+
+// askRepair prompts for what to do with one reference until it gets an
+// answer it can act on.
+func askRepair(store *data.Store, in *bufio.Reader, r data.DanglingRef) error {
+	options := "[r]elink, [p]urge, [s]kip, [q]uit"
+	if r.Nullable {
+		options = "[r]elink, [d]etach, [p]urge, [s]kip, [q]uit"
+	}
+	for {
+		fmt.Printf("      %s? ", options)
+		line, err := in.ReadString('\n')
+		if err != nil && line == "" {
+			return errRepairQuit
+		}
+		switch answer := strings.TrimSpace(strings.ToLower(line)); answer {
+		case "r":
+			fmt.Printf("      %s ID to point at: ", r.Target)
+			line, _ := in.ReadString('\n')
+			id, err := strconv.ParseUint(strings.TrimSpace(line), 10, 64)
+			if err != nil || id == 0 {
+				fmt.Println("      not an ID")
+				continue
+			}
+			if err := store.RelinkRef(r, uint(id)); err != nil {
+				fmt.Printf("      %v\n", err)
+				continue
+			}
+			return nil
+		case "d":
+			if r.Nullable {
+				return store.DetachRef(r)
+			}
+		case "p":
+			return store.PurgeRef(r)
+		case "s", "":
+			return errRepairSkipped
+		case "q":
+			return errRepairQuit
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/exports"
+)
+
+// runReport writes a report on the current house -- the profile, projects
+// against their budgets, the service history, appliances and spending --
+// for a year or a range of dates, as Markdown or as HTML to print.
+func runReport(args []string) {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	dbPath := fs.String("db", "", "SQLite database path (default: platform data dir)")
+	format := fs.String("format", "", "markdown or html (default: from -o's extension, else markdown)")
+	out := fs.String("o", "", "output file (default: standard output)")
+	year := fs.Int("year", 0, "report on this calendar year (default: this year, unless -since or -until)")
+	since := fs.String("since", "", "report from YYYY-MM-DD")
+	until := fs.String("until", "", "report through YYYY-MM-DD (default: today)")
+	_ = fs.Parse(args)
+
+	if *format == "" {
+		switch strings.ToLower(filepath.Ext(*out)) {
+		case ".html", ".htm":
+			*format = "html"
+		default:
+			*format = "markdown"
+		}
+	}
+	if *format != "markdown" && *format != "html" {
+		fail("parse -format", fmt.Errorf("want markdown or html, got %q", *format))
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	var from, to time.Time
+	var err error
+	switch {
+	case *year != 0 && (*since != "" || *until != ""):
+		fail("parse flags", fmt.Errorf("-year can't be combined with -since or -until"))
+	case *until != "" && *since == "":
+		fail("parse flags", fmt.Errorf("-until needs -since"))
+	case *since != "":
+		if from, err = time.ParseInLocation(time.DateOnly, *since, time.Local); err != nil {
+			fail("parse -since", err)
+		}
+		to = today.AddDate(0, 0, 1)
+		if *until != "" {
+			if to, err = time.ParseInLocation(time.DateOnly, *until, time.Local); err != nil {
+				fail("parse -until", err)
+			}
+			to = to.AddDate(0, 0, 1)
+		}
+		if !from.Before(to) {
+			fail("parse flags", fmt.Errorf("-since must come before -until"))
+		}
+	default:
+		if *year == 0 {
+			*year = now.Year()
+		}
+		from = time.Date(*year, time.January, 1, 0, 0, 0, 0, time.Local)
+		to = from.AddDate(1, 0, 0)
+	}
+
+	store := openExistingStore(*dbPath)
+	defer store.Close()
+	report, err := exports.HomeReport(store, from, to, now)
+	if err != nil {
+		fail("build report", err)
+	}
+	body := []byte(report.Markdown())
+	if *format == "html" {
+		if body, err = report.HTML(); err != nil {
+			fail("render report", err)
+		}
+	}
+
+	if *out == "" {
+		if _, err := os.Stdout.Write(body); err != nil {
+			fail("write report", err)
+		}
+		return
+	}
+	if err := os.WriteFile(*out, body, 0o600); err != nil {
+		fail("write report", err)
+	}
+	fmt.Fprintf(os.Stderr, "webcasa: wrote %s\n", *out)
+}
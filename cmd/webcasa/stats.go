@@ -0,0 +1,105 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// usageHeadings titles each kind of counter.
+var usageHeadings = map[string]string{
+	data.UsageFeature: "Features (changes made, by API route)",
+	data.UsageTab:     "Pages visited",
+	data.UsageForm:    "Forms saved, by page",
+}
+
+// runStats shows the local usage counters, or wipes them. They are never
+// sent anywhere; -json prints them for sharing by hand.
+func runStats(args []string) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	dbPath := fs.String("db", "", "SQLite database path (default: platform data dir)")
+	asJSON := fs.Bool("json", false, "print the counters as JSON")
+	wipe := fs.Bool("wipe", false, "delete every counter")
+	_ = fs.Parse(args)
+
+	store := openExistingStore(*dbPath)
+	defer store.Close()
+	if *wipe {
+		n, err := store.WipeUsage()
+		if err != nil {
+			fail("wipe usage counters", err)
+		}
+		fmt.Fprintf(os.Stderr, "webcasa: deleted %d usage counter(s)\n", n)
+		return
+	}
+
+	counters, err := store.UsageCounters()
+	if err != nil {
+		fail("load usage counters", err)
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(usageJSON(counters)); err != nil {
+			fail("write usage counters", err)
+		}
+		return
+	}
+	if len(counters) == 0 {
+		fmt.Fprintln(os.Stderr, "webcasa: no usage recorded yet")
+		return
+	}
+	for _, kind := range data.UsageKinds() {
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		shown := false
+		for _, c := range counters {
+			if c.Kind != kind {
+				continue
+			}
+			if !shown {
+				fmt.Printf("%s\n", usageHeadings[kind])
+				shown = true
+			}
+			fmt.Fprintf(tw, "  %s\t%d\t", c.Name, c.Count)
+			if kind == data.UsageForm {
+				fmt.Fprintf(tw, "avg %s\t", c.AverageDuration().Round(time.Second))
+			}
+			fmt.Fprintf(tw, "last %s\n", c.LastAt.Local().Format(time.DateOnly))
+		}
+		_ = tw.Flush()
+		if shown {
+			fmt.Println()
+		}
+	}
+	fmt.Fprintln(os.Stderr, "webcasa: these stay on this machine; wipe them with webcasa stats -wipe")
+}
+
+// usageCount is a counter as -json prints it.
+type usageCount struct {
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name"`
+	Count     int64     `json:"count"`
+	AverageMS int64     `json:"average_ms,omitempty"`
+	FirstAt   time.Time `json:"first_at"`
+	LastAt    time.Time `json:"last_at"`
+}
+
+func usageJSON(counters []data.UsageCounter) []usageCount {
+	out := make([]usageCount, 0, len(counters))
+	for _, c := range counters {
+		out = append(out, usageCount{
+			Kind: c.Kind, Name: c.Name, Count: c.Count,
+			AverageMS: c.AverageDuration().Milliseconds(),
+			FirstAt:   c.FirstAt, LastAt: c.LastAt,
+		})
+	}
+	return out
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// tlsFlags are the flags for serving HTTPS directly, with a certificate
+// from files or from Let's Encrypt.
+type tlsFlags struct {
+	cert, key   string
+	acmeDomains string
+	acmeEmail   string
+	acmeCache   string
+	acmeHTTP    string
+}
+
+// enabled reports whether HTTPS was asked for.
+func (f tlsFlags) enabled() bool {
+	return f.cert != "" || f.key != "" || f.acmeDomains != ""
+}
+
+// validate checks that the flags make sense together.
+func (f tlsFlags) validate() error {
+	switch {
+	case f.acmeDomains != "" && (f.cert != "" || f.key != ""):
+		return fmt.Errorf("-acme-domain can't be combined with -tls-cert or -tls-key")
+	case (f.cert == "") != (f.key == ""):
+		return fmt.Errorf("-tls-cert and -tls-key go together")
+	case f.acmeDomains != "" && len(f.domains()) == 0:
+		return fmt.Errorf("-acme-domain needs at least one domain name")
+	}
+	return nil
+}
+
+func (f tlsFlags) domains() []string {
+	var domains []string
+	for d := range strings.SplitSeq(f.acmeDomains, ",") {
+		if d = strings.TrimSpace(d); d != "" {
+			domains = append(domains, d)
+		}
+	}
+	return domains
+}
+
+// configure sets srv up to serve HTTPS. With -acme-domain it also returns
+// a plain HTTP server, for -acme-http, that answers Let's Encrypt's
+// challenges and redirects everything else to HTTPS; it is nil when
+// -acme-http is empty.
+func (f tlsFlags) configure(srv *http.Server) (*http.Server, error) {
+	if f.cert != "" {
+		pair, err := tls.LoadX509KeyPair(f.cert, f.key)
+		if err != nil {
+			return nil, fmt.Errorf("load certificate: %w", err)
+		}
+		srv.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12, Certificates: []tls.Certificate{pair}}
+		return nil, nil
+	}
+	m := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(f.domains()...),
+		Cache:      autocert.DirCache(f.acmeCache),
+		Email:      f.acmeEmail,
+	}
+	srv.TLSConfig = m.TLSConfig()
+	srv.TLSConfig.MinVersion = tls.VersionTLS12
+	if f.acmeHTTP == "" {
+		return nil, nil
+	}
+	return &http.Server{
+		Addr:              f.acmeHTTP,
+		Handler:           m.HTTPHandler(nil),
+		ReadHeaderTimeout: 10 * time.Second,
+		IdleTimeout:       60 * time.Second,
+	}, nil
+}
+
+// httpsURL describes where the server can be reached, for the startup
+// log.
+func (f tlsFlags) httpsURL(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	if domains := f.domains(); len(domains) > 0 {
+		host = domains[0]
+	} else if host == "" {
+		host = "localhost"
+	}
+	if port == "443" {
+		return "https://" + host + "/"
+	}
+	return "https://" + net.JoinHostPort(host, port) + "/"
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+var tokensUsage = `usage: webcasa tokens <command> [flags]
+
+commands:
+  create NAME  issue an API token; -scope ` + strings.Join(data.TokenScopes(), "|") + ` (default read),
+               -rate requests per minute (default ` + strconv.Itoa(data.DefaultTokenRateLimit) + `)
+  list         show tokens with their scope, rate limit, and last use
+  revoke ID    stop a token from working
+`
+
+func runTokens(args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, tokensUsage)
+		os.Exit(2)
+	}
+	fs := flag.NewFlagSet("tokens "+args[0], flag.ExitOnError)
+	dbPath := fs.String("db", "", "SQLite database path (default: platform data dir)")
+	scope := fs.String("scope", data.ScopeRead, "token scope: "+strings.Join(data.TokenScopes(), ", "))
+	rate := fs.Int("rate", data.DefaultTokenRateLimit, "requests per minute")
+	_ = fs.Parse(args[1:])
+
+	switch args[0] {
+	case "create":
+		if fs.NArg() != 1 {
+			fmt.Fprintln(os.Stderr, "usage: webcasa tokens create [-db path] [-scope scope] [-rate n] NAME")
+			os.Exit(2)
+		}
+		store := openExistingStore(*dbPath)
+		defer store.Close()
+		tok, secret, err := store.CreateAPIToken(fs.Arg(0), *scope, *rate)
+		if err != nil {
+			fail("create token", err)
+		}
+		fmt.Fprintf(os.Stderr, "webcasa: created %s token %q (id %d); it won't be shown again\n",
+			tok.Scope, tok.Name, tok.ID)
+		fmt.Println(secret)
+	case "list":
+		store := openExistingStore(*dbPath)
+		defer store.Close()
+		listTokens(store)
+	case "revoke":
+		if fs.NArg() != 1 {
+			fmt.Fprintln(os.Stderr, "usage: webcasa tokens revoke [-db path] ID")
+			os.Exit(2)
+		}
+		id, err := strconv.ParseUint(fs.Arg(0), 10, 64)
+		if err != nil || id == 0 {
+			fail("revoke token", fmt.Errorf("invalid id %q", fs.Arg(0)))
+		}
+		store := openExistingStore(*dbPath)
+		defer store.Close()
+		if err := store.RevokeAPIToken(uint(id)); err != nil {
+			fail("revoke token", err)
+		}
+		fmt.Fprintf(os.Stderr, "webcasa: revoked token %d\n", id)
+	default:
+		fmt.Fprintf(os.Stderr, "webcasa: unknown tokens command %q\n\n%s", args[0], tokensUsage)
+		os.Exit(2)
+	}
+}
+
+func listTokens(store *data.Store) {
+	toks, err := store.ListAPITokens()
+	if err != nil {
+		fail("list tokens", err)
+	}
+	if len(toks) == 0 {
+		fmt.Fprintln(os.Stderr, "webcasa: no API tokens")
+		return
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tTOKEN\tSCOPE\tRATE/MIN\tLAST USED\tSTATUS")
+	for _, t := range toks {
+		lastUsed := "never"
+		if t.LastUsedAt != nil {
+			lastUsed = t.LastUsedAt.Local().Format(time.DateTime)
+		}
+		status := "active"
+		if t.Revoked() {
+			status = "revoked " + t.RevokedAt.Local().Format(time.DateOnly)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s...\t%s\t%d\t%s\t%s\n",
+			t.ID, t.Name, t.Hint, t.Scope, t.RateLimit, lastUsed, status)
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,110 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// passwordEnv lets scripts supply an account password without a prompt.
+const passwordEnv = "WEBCASA_PASSWORD"
+
+var userUsage = `usage: webcasa user <command> [flags]
+
+commands:
+  add NAME      create an account; the password (at least ` + strconv.Itoa(data.MinPasswordLength) + ` characters)
+                is read from ` + passwordEnv + ` or stdin. The first account turns
+                on sign-in for the web app and API
+  passwd NAME   set an account's password and sign it out everywhere
+  list          show accounts and when they last signed in
+  remove NAME   delete an account; removing the last turns sign-in off
+`
+
+func runUser(args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, userUsage)
+		os.Exit(2)
+	}
+	fs := flag.NewFlagSet("user "+args[0], flag.ExitOnError)
+	dbPath := fs.String("db", "", "SQLite database path (default: platform data dir)")
+	_ = fs.Parse(args[1:])
+
+	needName := func() string {
+		if fs.NArg() != 1 {
+			fmt.Fprintf(os.Stderr, "usage: webcasa user %s [-db path] NAME\n", args[0])
+			os.Exit(2)
+		}
+		return fs.Arg(0)
+	}
+	switch args[0] {
+	case "add":
+		name := needName()
+		store := openExistingStore(*dbPath)
+		defer store.Close()
+		password, err := readSecret(passwordEnv, "password", "Password for "+name, true)
+		if err != nil {
+			fail("add user", err)
+		}
+		u, err := store.CreateUser(name, password)
+		if err != nil {
+			fail("add user", err)
+		}
+		fmt.Fprintf(os.Stderr, "webcasa: added user %q (id %d)\n", u.Username, u.ID)
+	case "passwd":
+		name := needName()
+		store := openExistingStore(*dbPath)
+		defer store.Close()
+		password, err := readSecret(passwordEnv, "password", "New password for "+name, true)
+		if err != nil {
+			fail("set password", err)
+		}
+		if err := store.SetUserPassword(name, password); err != nil {
+			fail("set password", err)
+		}
+		fmt.Fprintf(os.Stderr, "webcasa: changed the password for %q and ended its sessions\n", name)
+	case "list":
+		store := openExistingStore(*dbPath)
+		defer store.Close()
+		listUsers(store)
+	case "remove":
+		name := needName()
+		store := openExistingStore(*dbPath)
+		defer store.Close()
+		if err := store.RemoveUser(name); err != nil {
+			fail("remove user", err)
+		}
+		fmt.Fprintf(os.Stderr, "webcasa: removed user %q\n", name)
+	default:
+		fmt.Fprintf(os.Stderr, "webcasa: unknown user command %q\n\n%s", args[0], userUsage)
+		os.Exit(2)
+	}
+}
+
+func listUsers(store *data.Store) {
+	users, err := store.ListUsers()
+	if err != nil {
+		fail("list users", err)
+	}
+	if len(users) == 0 {
+		fmt.Fprintln(os.Stderr, "webcasa: no accounts; anyone who can reach webcasa can use it")
+		return
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tUSERNAME\tCREATED\tLAST SIGN-IN")
+	for _, u := range users {
+		last := "never"
+		if u.LastLoginAt != nil {
+			last = u.LastLoginAt.Local().Format(time.DateTime)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", u.ID, u.Username, u.CreatedAt.Local().Format(time.DateOnly), last)
+	}
+	_ = tw.Flush()
+}
@@ -9,7 +9,9 @@ require (
 	github.com/dustin/go-humanize v1.0.1
 	github.com/iancoleman/strcase v0.3.0
 	github.com/stretchr/testify v1.11.1
+	golang.org/x/crypto v0.48.0
 	gorm.io/gorm v1.31.1
+	modernc.org/libc v1.67.6
 	modernc.org/sqlite v1.45.0
 )
 
@@ -23,10 +25,10 @@ require (
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
 	golang.org/x/exp v0.0.0-20251023183803-a4bb9ffd2546 // indirect
+	golang.org/x/net v0.49.0 // indirect
 	golang.org/x/sys v0.41.0 // indirect
-	golang.org/x/text v0.20.0 // indirect
+	golang.org/x/text v0.34.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
-	modernc.org/libc v1.67.6 // indirect
 	modernc.org/mathutil v1.7.1 // indirect
 	modernc.org/memory v1.11.0 // indirect
 )
@@ -0,0 +1,274 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"gorm.io/gorm"
+)
+
+// sessionCookie carries a sign-in session from the web app.
+const sessionCookie = "webcasa_session"
+
+// userKey holds the signed-in data.User in a request's context.
+type userKey struct{}
+
+// tokenKey marks a request that withTokens let through on an API token.
+type tokenKey struct{}
+
+// withUsers asks for a signed-in account on every API request once an
+// account exists, taking the session from the cookie set by Login or from
+// an "Authorization: Bearer wcu_..." header. Requests let through on an
+// API token don't need one, nor does the admin panel, which has its own
+// password. Until the first account is made with "webcasa user add",
+// nobody is asked to sign in.
+func withUsers(next http.Handler, store *data.Store, guard *authGuard) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !needsSignIn(r) || r.Context().Value(tokenKey{}) != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		has, err := store.HasUsers()
+		if err != nil {
+			jsonError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !has {
+			next.ServeHTTP(w, r)
+			return
+		}
+		token := sessionToken(r)
+		if token != "" {
+			if guard.blocked(w, r) {
+				return
+			}
+			u, err := store.UserBySession(token, time.Now())
+			if err == nil {
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey{}, u)))
+				return
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				jsonError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			guard.fail(r)
+		}
+		if !strings.HasPrefix(r.URL.Path, "/api/") {
+			http.Error(w, "sign in required", http.StatusUnauthorized)
+			return
+		}
+		jsonError(w, http.StatusUnauthorized, "sign in required")
+	})
+}
+
+// needsSignIn reports whether the path is behind sign-in: the API, apart
+// from health checks, signing in and out, the admin panel and reminder
+// links, which are signed, and the server-rendered dashboard. The web
+// app's static files and the kiosk, which takes a display token, are not.
+func needsSignIn(r *http.Request) bool {
+	switch p := r.URL.Path; {
+	case p == "/dashboard":
+		return true
+	case !strings.HasPrefix(p, "/api/"),
+		p == "/api/health", p == "/api/auth/login", p == "/api/auth/logout", p == "/api/auth/me",
+		strings.HasPrefix(p, "/api/admin/"), strings.HasPrefix(p, "/api/reminders/"):
+		return false
+	}
+	return true
+}
+
+// sessionToken returns the request's sign-in session token, from the
+// Authorization header or the session cookie, or "".
+func sessionToken(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok &&
+		strings.HasPrefix(token, data.SessionPrefix) {
+		return token
+	}
+	if c, err := r.Cookie(sessionCookie); err == nil && strings.HasPrefix(c.Value, data.SessionPrefix) {
+		return c.Value
+	}
+	return ""
+}
+
+// ── Accounts ───────────────────────────────────────
+
+type loginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// Code is a current authenticator code or a recovery code, required
+	// once the account has two-factor authentication on.
+	Code string `json:"code"`
+}
+
+type loginResponse struct {
+	Username  string    `json:"username"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Login trades a username and password, plus a two-factor code when the
+// account has one, for a session, set as an HttpOnly cookie for the web
+// app and returned as a token for other clients.
+func (a *API) Login(w http.ResponseWriter, r *http.Request) {
+	if a.guard.blocked(w, r) {
+		return
+	}
+	body, err := decodeBody[loginRequest](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	u, token, sess, err := a.store.SignIn(body.Username, body.Password, body.Code, time.Now())
+	if errors.Is(err, data.ErrBadCredentials) {
+		a.guard.fail(r)
+		jsonError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if errors.Is(err, data.ErrSecondFactorRequired) || errors.Is(err, data.ErrBadSecondFactor) {
+		if errors.Is(err, data.ErrBadSecondFactor) {
+			a.guard.fail(r)
+		}
+		writeJSON(w, http.StatusUnauthorized, map[string]any{"error": err.Error(), "code_required": true})
+		return
+	}
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	http.SetCookie(w, a.sessionCookie(r, token, sess.ExpiresAt))
+	jsonOK(w, loginResponse{Username: u.Username, Token: token, ExpiresAt: sess.ExpiresAt})
+}
+
+// Logout ends the request's session and clears the cookie.
+func (a *API) Logout(w http.ResponseWriter, r *http.Request) {
+	if token := sessionToken(r); token != "" {
+		if err := a.store.SignOut(token); err != nil {
+			jsonError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	c := a.sessionCookie(r, "", time.Time{})
+	c.MaxAge = -1
+	http.SetCookie(w, c)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Verify takes a two-factor code for the request's session, which may
+// then restore deleted records for data.FreshFactorWindow.
+func (a *API) Verify(w http.ResponseWriter, r *http.Request) {
+	if a.guard.blocked(w, r) {
+		return
+	}
+	token := sessionToken(r)
+	if token == "" {
+		jsonError(w, http.StatusForbidden, "sign in to an account to use two-factor authentication")
+		return
+	}
+	body, err := decodeBody[twoFactorCode](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	err = a.store.VerifySession(token, body.Code, time.Now())
+	if errors.Is(err, data.ErrBadSecondFactor) {
+		a.guard.fail(r)
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		jsonError(w, http.StatusUnauthorized, "sign in required")
+		return
+	}
+	if err != nil {
+		jsonError(w, http.StatusConflict, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// requireFreshCode guards restoring deleted records. A signed-in account
+// with two-factor authentication on must have given a code within
+// data.FreshFactorWindow, signing in or to Verify; otherwise it's refused
+// with 403 and code_required.
+func (a *API) requireFreshCode(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Value(userKey{}).(data.User); !ok {
+			next(w, r)
+			return
+		}
+		fresh, err := a.store.FreshlyVerified(sessionToken(r), time.Now())
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			jsonError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !fresh {
+			writeJSON(w, http.StatusForbidden, map[string]any{
+				"error": "enter a two-factor code to restore records", "code_required": true,
+			})
+			return
+		}
+		next(w, r)
+	}
+}
+
+type meResponse struct {
+	// Auth is whether any account exists, and so whether signing in is
+	// needed at all.
+	Auth     bool   `json:"auth"`
+	Username string `json:"username,omitempty"`
+}
+
+// Me says who is signed in, answering 401 when accounts exist and nobody
+// is.
+func (a *API) Me(w http.ResponseWriter, r *http.Request) {
+	has, err := a.store.HasUsers()
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !has {
+		jsonOK(w, meResponse{})
+		return
+	}
+	token := sessionToken(r)
+	if token == "" {
+		jsonError(w, http.StatusUnauthorized, "sign in required")
+		return
+	}
+	u, err := a.store.UserBySession(token, time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		jsonError(w, http.StatusUnauthorized, "sign in required")
+		return
+	}
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, meResponse{Auth: true, Username: u.Username})
+}
+
+// sessionCookie makes the session cookie, scoped to the base path and
+// marked Secure when the request came over HTTPS, directly or through a
+// trusted proxy.
+func (a *API) sessionCookie(r *http.Request, token string, expires time.Time) *http.Cookie {
+	path := a.basePath
+	if path == "" {
+		path = "/"
+	}
+	return &http.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     path,
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   overHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	}
+}
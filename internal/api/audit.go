@@ -0,0 +1,84 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// actorOf names who is making a request, for the audit log.
+func actorOf(r *http.Request) string {
+	if u, ok := r.Context().Value(userKey{}).(data.User); ok {
+		return "user:" + u.Username
+	}
+	if id, ok := r.Context().Value(tokenKey{}).(uint); ok {
+		return "token:" + strconv.FormatUint(uint64(id), 10)
+	}
+	if strings.HasPrefix(r.URL.Path, "/api/admin/") {
+		return "admin"
+	}
+	return "web"
+}
+
+// apiKey holds the API a request is served by when it isn't the server's
+// own: one whose store records who made the request's changes, or runs
+// them in a batch's transaction.
+type apiKey struct{}
+
+// bind serves a route with the API in the request's context, or with a
+// when there is none.
+func (a *API) bind(h func(*API, http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if b, ok := r.Context().Value(apiKey{}).(*API); ok {
+			h(b, w, r)
+			return
+		}
+		h(a, w, r)
+	}
+}
+
+// withActor serves API requests that change things with a store that
+// records who made them in the audit log, passing it to the routes in the
+// request's context.
+func (a *API) withActor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions ||
+			!strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		b := *a
+		b.store = a.store.As(actorOf(r))
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiKey{}, &b)))
+	})
+}
+
+// ListAuditTrail returns every recorded change to one row, oldest first:
+// its creation, edits, deletions and restorations, with who made each.
+// {entity} is the table name, e.g. "projects".
+func (a *API) ListAuditTrail(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(r.PathValue("eid"), 10, 64)
+	if err != nil || id == 0 {
+		jsonError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	entries, err := a.store.AuditTrail(r.PathValue("entity"), uint(id))
+	if errors.Is(err, data.ErrUnknownEntity) {
+		jsonError(w, http.StatusNotFound, err.Error())
+		return
+	} else if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if entries == nil {
+		entries = []data.AuditEntry{}
+	}
+	jsonOK(w, entries)
+}
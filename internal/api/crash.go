@@ -0,0 +1,103 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// crashRecent is how many requests a crash report lists.
+const crashRecent = 20
+
+// WithCrashReports writes a report to dir whenever a handler panics, for
+// attaching to a bug report. Without it, panics are only logged.
+func WithCrashReports(dir string) Option {
+	return func(a *API) { a.crashes = &crashReporter{dir: dir} }
+}
+
+// crashReporter remembers the last few requests and writes them, with the
+// panic, to a report. Reports hold no row data: requests are recorded by
+// method and path only, since query strings can carry tokens.
+type crashReporter struct {
+	dir string
+
+	mu     sync.Mutex
+	recent [crashRecent]string
+	next   int
+}
+
+func (c *crashReporter) record(r *http.Request) {
+	if c == nil {
+		return
+	}
+	line := time.Now().Format(time.RFC3339) + " " + r.Method + " " + r.URL.Path
+	c.mu.Lock()
+	c.recent[c.next%crashRecent] = line
+	c.next++
+	c.mu.Unlock()
+}
+
+// requests returns the recorded requests, oldest first.
+func (c *crashReporter) requests() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []string
+	for i := max(0, c.next-crashRecent); i < c.next; i++ {
+		out = append(out, c.recent[i%crashRecent])
+	}
+	return out
+}
+
+// write saves a report of the panic and returns its path.
+func (c *crashReporter) write(store *data.Store, r *http.Request, panicked any, stack []byte) (string, error) {
+	now := time.Now()
+	var b strings.Builder
+	fmt.Fprintf(&b, "webcasa crash report, %s\n\n", now.Format(time.RFC3339))
+	fmt.Fprintf(&b, "panic: %v\n", panicked)
+	fmt.Fprintf(&b, "request: %s %s\n\n", r.Method, r.URL.Path)
+
+	version := "(devel)"
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		version = info.Main.Version
+	}
+	mode := "read-write"
+	if store.ReadOnly() {
+		mode = "read-only"
+	}
+	schema, err := store.SchemaVersion()
+	schemaText := fmt.Sprintf("%d (this build writes %d)", schema, data.SchemaVersion)
+	if err != nil {
+		schemaText = "unknown: " + err.Error()
+	}
+	fmt.Fprintf(&b, "version: %s\n", version)
+	fmt.Fprintf(&b, "go: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "mode: %s\n", mode)
+	fmt.Fprintf(&b, "schema: %s\n", schemaText)
+	fmt.Fprintf(&b, "database healthy: %t\n\n", store.Health().OK)
+
+	fmt.Fprintf(&b, "recent requests:\n")
+	for _, line := range c.requests() {
+		fmt.Fprintf(&b, "  %s\n", line)
+	}
+	fmt.Fprintf(&b, "\n%s", stack)
+
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(c.dir, "crash-"+now.Format("20060102-150405.000")+".txt")
+	if err := os.WriteFile(path, []byte(b.String()), 0o600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
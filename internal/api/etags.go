@@ -0,0 +1,223 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ── ETags ──────────────────────────────────────────
+
+// withETags tags JSON GET responses under /api/ and answers 304 when the
+// client already has the current copy. A record's tag starts with its
+// version ("v4-1a2b..."), and a PUT or PATCH sent with If-Match is refused
+// with 412 unless the record still has that tag; the version is then
+// passed on as the body's Version, so a save racing the check still fails
+// with 409 instead of overwriting. A route with no GET to tag what it
+// changes refuses If-Match with 412, since there's nothing to check it
+// against.
+func withETags(next http.Handler, mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") || r.URL.Path == "/api/events" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			serveTagged(next, w, r)
+		case http.MethodPut, http.MethodPatch:
+			if r.Header.Get("If-Match") != "" && !checkIfMatch(next, mux, w, r) {
+				return
+			}
+			next.ServeHTTP(w, r)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+func serveTagged(next http.Handler, w http.ResponseWriter, r *http.Request) {
+	ew := &etagWriter{ResponseWriter: w}
+	next.ServeHTTP(ew, r)
+	if !ew.buffered() {
+		return
+	}
+	tag := etagFor(ew.buf.Bytes())
+	w.Header().Set("ETag", tag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), tag, true) {
+		w.Header().Del("Content-Length")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(ew.buf.Bytes())
+}
+
+// checkIfMatch compares If-Match with the tag a GET of the same path
+// would return now. It writes the error response and returns false when
+// the request shouldn't go ahead.
+func checkIfMatch(next http.Handler, mux *http.ServeMux, w http.ResponseWriter, r *http.Request) bool {
+	get := r.Clone(r.Context())
+	get.Method = http.MethodGet
+	get.Body = http.NoBody
+	get.ContentLength = 0
+	get.Header.Del("If-None-Match")
+	if _, pattern := mux.Handler(get); pattern == "" {
+		jsonError(w, http.StatusPreconditionFailed, "this has no version to match -- send it without If-Match")
+		return false
+	}
+	cw := &captureWriter{header: make(http.Header)}
+	next.ServeHTTP(cw, get)
+	if cw.status != http.StatusOK {
+		// Let the handler report the missing record.
+		return true
+	}
+	tag := etagFor(cw.buf.Bytes())
+	if !etagMatches(r.Header.Get("If-Match"), tag, false) {
+		w.Header().Set("ETag", tag)
+		jsonError(w, http.StatusPreconditionFailed, "the record has changed since it was fetched")
+		return false
+	}
+	if version, ok := etagVersion(tag); ok {
+		if err := setBodyVersion(r, version); err != nil {
+			jsonError(w, http.StatusBadRequest, err.Error())
+			return false
+		}
+	}
+	return true
+}
+
+// etagFor tags a JSON body by its content, prefixed with the record's
+// version when it is a single versioned record.
+func etagFor(body []byte) string {
+	sum := sha256.Sum256(body)
+	hash := hex.EncodeToString(sum[:8])
+	var rec struct{ Version int }
+	if bytes.HasPrefix(bytes.TrimSpace(body), []byte("{")) &&
+		json.Unmarshal(body, &rec) == nil && rec.Version > 0 {
+		return `"v` + strconv.Itoa(rec.Version) + "-" + hash + `"`
+	}
+	return `"` + hash + `"`
+}
+
+// etagVersion pulls the record version out of a tag made by etagFor.
+func etagVersion(tag string) (int, bool) {
+	rest, ok := strings.CutPrefix(tag, `"v`)
+	if !ok {
+		return 0, false
+	}
+	n, _, _ := strings.Cut(rest, "-")
+	v, err := strconv.Atoi(n)
+	return v, err == nil
+}
+
+// etagMatches reports whether a comma-separated If-Match or If-None-Match
+// list names tag. Weak tags only count for If-None-Match.
+func etagMatches(header, tag string, weak bool) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" {
+			return true
+		}
+		if after, ok := strings.CutPrefix(t, "W/"); ok {
+			if !weak {
+				continue
+			}
+			t = after
+		}
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// setBodyVersion sets Version in a JSON object request body.
+func setBodyVersion(r *http.Request, version int) error {
+	raw, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	if err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(raw, &fields) != nil || fields == nil {
+		// Not an object; the handler will complain about it.
+		r.Body = io.NopCloser(bytes.NewReader(raw))
+		return nil
+	}
+	fields["Version"] = json.RawMessage(strconv.Itoa(version))
+	body, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	r.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return nil
+}
+
+// etagWriter holds back a successful JSON response so it can be tagged.
+// Anything else, such as file downloads or a response the handler tagged
+// itself, goes straight through.
+type etagWriter struct {
+	http.ResponseWriter
+	buf         bytes.Buffer
+	wroteHeader bool
+	passthrough bool
+}
+
+func (ew *etagWriter) WriteHeader(code int) {
+	if ew.wroteHeader {
+		return
+	}
+	ew.wroteHeader = true
+	ct := ew.Header().Get("Content-Type")
+	if code != http.StatusOK || !strings.HasPrefix(ct, "application/json") || ew.Header().Get("ETag") != "" {
+		ew.passthrough = true
+		ew.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (ew *etagWriter) Write(p []byte) (int, error) {
+	if !ew.wroteHeader {
+		ew.WriteHeader(http.StatusOK)
+	}
+	if ew.passthrough {
+		return ew.ResponseWriter.Write(p)
+	}
+	return ew.buf.Write(p)
+}
+
+func (ew *etagWriter) Unwrap() http.ResponseWriter { return ew.ResponseWriter }
+
+func (ew *etagWriter) buffered() bool { return ew.wroteHeader && !ew.passthrough }
+
+// captureWriter records a response without sending it anywhere.
+type captureWriter struct {
+	header http.Header
+	status int
+	buf    bytes.Buffer
+}
+
+func (cw *captureWriter) Header() http.Header { return cw.header }
+
+func (cw *captureWriter) WriteHeader(code int) {
+	if cw.status == 0 {
+		cw.status = code
+	}
+}
+
+func (cw *captureWriter) Write(p []byte) (int, error) {
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	return cw.buf.Write(p)
+}
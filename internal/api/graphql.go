@@ -0,0 +1,279 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"time"
+	"unicode"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/graphql"
+	"gorm.io/gorm"
+)
+
+// ── GraphQL ────────────────────────────────────────
+
+// WithGraphQL serves read-only GraphQL queries at /api/graphql.
+func WithGraphQL(enabled bool) Option {
+	return func(a *API) {
+		if enabled {
+			a.graphql = graphSchema(a)
+		} else {
+			a.graphql = nil
+		}
+	}
+}
+
+// GraphQL runs a query against projects, quotes, vendors and their
+// documents. GET takes the request in the query string, POST as JSON.
+func (a *API) GraphQL(w http.ResponseWriter, r *http.Request) {
+	var req graphql.Request
+	if r.Method == http.MethodGet {
+		q := r.URL.Query()
+		req.Query = q.Get("query")
+		req.OperationName = q.Get("operationName")
+		if vars := q.Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				jsonError(w, http.StatusBadRequest, "variables: "+err.Error())
+				return
+			}
+		}
+	} else {
+		var err error
+		if req, err = decodeBody[graphql.Request](r); err != nil {
+			jsonError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if req.Query == "" {
+		jsonError(w, http.StatusBadRequest, "query is required")
+		return
+	}
+	jsonOK(w, graphql.Execute(r.Context(), a.graphql, req))
+}
+
+// graphSchema describes the entity graph. Scalar fields come from the
+// models' columns, named in camel case (BudgetCents is budgetCents).
+func graphSchema(a *API) *graphql.Schema {
+	project := graphObject("Project", data.Project{})
+	projectType := graphObject("ProjectType", data.ProjectType{})
+	quote := graphObject("Quote", data.Quote{})
+	vendor := graphObject("Vendor", data.Vendor{})
+	document := graphObject("Document", data.Document{})
+
+	project.Fields["projectType"] = &graphql.Field{Type: projectType, Resolve: belongsTo(
+		func(p data.Project) uint { return p.ProjectTypeID },
+		func([]uint) ([]data.ProjectType, error) { return a.store.ProjectTypes() },
+		func(t data.ProjectType) uint { return t.ID },
+	)}
+	project.Fields["quotes"] = &graphql.Field{Type: quote, List: true, Resolve: hasMany(
+		func(p data.Project) uint { return p.ID },
+		a.store.QuotesByProjects,
+		func(q data.Quote) uint { return q.ProjectID },
+	)}
+	project.Fields["documents"] = documentsOf(a, document, data.DocumentEntityProject,
+		func(p data.Project) uint { return p.ID })
+
+	quote.Fields["project"] = &graphql.Field{Type: project, Resolve: belongsTo(
+		func(q data.Quote) uint { return q.ProjectID },
+		a.store.ProjectsByID,
+		func(p data.Project) uint { return p.ID },
+	)}
+	quote.Fields["vendor"] = &graphql.Field{Type: vendor, Resolve: belongsTo(
+		func(q data.Quote) uint { return q.VendorID },
+		a.store.VendorsByID,
+		func(v data.Vendor) uint { return v.ID },
+	)}
+	quote.Fields["documents"] = documentsOf(a, document, data.DocumentEntityQuote,
+		func(q data.Quote) uint { return q.ID })
+
+	vendor.Fields["quotes"] = &graphql.Field{Type: quote, List: true, Resolve: hasMany(
+		func(v data.Vendor) uint { return v.ID },
+		a.store.QuotesByVendors,
+		func(q data.Quote) uint { return q.VendorID },
+	)}
+	vendor.Fields["documents"] = documentsOf(a, document, data.DocumentEntityVendor,
+		func(v data.Vendor) uint { return v.ID })
+
+	query := &graphql.Object{Name: "Query", Fields: map[string]*graphql.Field{
+		"projects":  rootList(project, func(del bool) ([]data.Project, error) { return a.store.ListProjects(del) }),
+		"project":   rootByID(project, func(id uint) (data.Project, error) { return a.store.GetProject(id) }),
+		"quotes":    rootList(quote, func(del bool) ([]data.Quote, error) { return a.store.ListQuotes(del) }),
+		"quote":     rootByID(quote, func(id uint) (data.Quote, error) { return a.store.GetQuote(id) }),
+		"vendors":   rootList(vendor, func(del bool) ([]data.Vendor, error) { return a.store.ListVendors(del) }),
+		"vendor":    rootByID(vendor, func(id uint) (data.Vendor, error) { return a.store.GetVendor(id) }),
+		"documents": rootList(document, func(del bool) ([]data.Document, error) { return a.store.ListDocuments(del) }),
+		"document":  rootByID(document, func(id uint) (data.Document, error) { return a.store.GetDocumentMetadata(id) }),
+	}}
+	return &graphql.Schema{Query: query}
+}
+
+// graphObject makes an object type whose fields are model's scalar
+// columns. Blobs and associations are left for explicit fields.
+func graphObject(name string, model any) *graphql.Object {
+	obj := &graphql.Object{Name: name, Fields: make(map[string]*graphql.Field)}
+	t := reflect.TypeOf(model)
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() || !graphScalar(f.Type) {
+			continue
+		}
+		index := f.Index
+		obj.Fields[graphName(f.Name)] = &graphql.Field{
+			Resolve: func(_ context.Context, parents []any, _ map[string]any) ([]any, error) {
+				out := make([]any, len(parents))
+				for i, p := range parents {
+					out[i] = reflect.ValueOf(p).FieldByIndex(index).Interface()
+				}
+				return out, nil
+			},
+		}
+	}
+	return obj
+}
+
+var (
+	timeType      = reflect.TypeFor[time.Time]()
+	deletedAtType = reflect.TypeFor[gorm.DeletedAt]()
+)
+
+func graphScalar(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return t == timeType || t == deletedAtType
+}
+
+// graphName turns a Go field name into a GraphQL one: ID is id,
+// MIMEType is mimeType, ProjectTypeID is projectTypeID.
+func graphName(goName string) string {
+	r := []rune(goName)
+	n := 0
+	for n < len(r) && unicode.IsUpper(r[n]) {
+		n++
+	}
+	if n > 1 && n < len(r) {
+		n-- // the last capital starts the next word
+	}
+	for i := range max(n, 1) {
+		r[i] = unicode.ToLower(r[i])
+	}
+	return string(r)
+}
+
+// hasMany resolves a list of children for every parent with one load,
+// grouping the children by the parent they point at.
+func hasMany[P, C any](parentID func(P) uint, load func([]uint) ([]C, error), childParent func(C) uint) graphql.Resolver {
+	return func(_ context.Context, parents []any, _ map[string]any) ([]any, error) {
+		ids := make([]uint, len(parents))
+		for i, p := range parents {
+			ids[i] = parentID(p.(P))
+		}
+		children, err := load(ids)
+		if err != nil {
+			return nil, err
+		}
+		byParent := make(map[uint][]any)
+		for _, c := range children {
+			byParent[childParent(c)] = append(byParent[childParent(c)], c)
+		}
+		out := make([]any, len(parents))
+		for i, id := range ids {
+			out[i] = append([]any{}, byParent[id]...)
+		}
+		return out, nil
+	}
+}
+
+// documentsOf resolves the documents attached to parents of one kind.
+func documentsOf[P any](a *API, document *graphql.Object, kind string, id func(P) uint) *graphql.Field {
+	return &graphql.Field{Type: document, List: true, Resolve: hasMany(
+		id,
+		func(ids []uint) ([]data.Document, error) { return a.store.DocumentsByEntities(kind, ids) },
+		func(d data.Document) uint { return d.EntityID },
+	)}
+}
+
+// belongsTo resolves the record each parent points at with one load. A
+// parent pointing nowhere, or at a missing record, gets null.
+func belongsTo[P, C any](ref func(P) uint, load func([]uint) ([]C, error), id func(C) uint) graphql.Resolver {
+	return func(_ context.Context, parents []any, _ map[string]any) ([]any, error) {
+		ids := make([]uint, 0, len(parents))
+		for _, p := range parents {
+			if r := ref(p.(P)); r != 0 {
+				ids = append(ids, r)
+			}
+		}
+		out := make([]any, len(parents))
+		if len(ids) == 0 {
+			return out, nil
+		}
+		records, err := load(ids)
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[uint]C, len(records))
+		for _, rec := range records {
+			byID[id(rec)] = rec
+		}
+		for i, p := range parents {
+			if rec, ok := byID[ref(p.(P))]; ok {
+				out[i] = rec
+			}
+		}
+		return out, nil
+	}
+}
+
+func rootList[T any](typ *graphql.Object, list func(includeDeleted bool) ([]T, error)) *graphql.Field {
+	return &graphql.Field{Type: typ, List: true, Args: []string{"includeDeleted"},
+		Resolve: func(_ context.Context, _ []any, args map[string]any) ([]any, error) {
+			includeDeleted, _, err := graphql.BoolArg(args, "includeDeleted")
+			if err != nil {
+				return nil, err
+			}
+			rows, err := list(includeDeleted)
+			if err != nil {
+				return nil, err
+			}
+			out := make([]any, len(rows))
+			for i, row := range rows {
+				out[i] = row
+			}
+			return []any{out}, nil
+		}}
+}
+
+func rootByID[T any](typ *graphql.Object, get func(uint) (T, error)) *graphql.Field {
+	return &graphql.Field{Type: typ, Args: []string{"id"},
+		Resolve: func(_ context.Context, _ []any, args map[string]any) ([]any, error) {
+			id, ok, err := graphql.IntArg(args, "id")
+			if err != nil {
+				return nil, err
+			}
+			if !ok || id <= 0 {
+				return nil, errors.New("id must be a positive integer")
+			}
+			row, err := get(uint(id))
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return []any{nil}, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			return []any{row}, nil
+		}}
+}
@@ -6,15 +6,41 @@ package api
 import (
 	"errors"
 	"net/http"
+	"net/netip"
 	"strings"
 
+	"github.com/cpcloud/webcasa/internal/chat"
 	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/graphql"
+	"github.com/cpcloud/webcasa/internal/hooks"
+	"github.com/cpcloud/webcasa/internal/llm"
+	"github.com/cpcloud/webcasa/internal/photo"
 	"gorm.io/gorm"
 )
 
-// API holds the store reference for all handlers.
+// API holds the store reference and settings shared by all handlers.
 type API struct {
-	store *data.Store
+	store        *data.Store
+	waterLimits  data.WaterLimits
+	hooks        *hooks.Dispatcher
+	admin        AdminOptions
+	images       *photo.Options
+	model        *llm.Client
+	sqlModel     *llm.Client
+	summaryModel *llm.Client
+	budget       *chat.Budget
+	redact       *chat.Redactor
+
+	basePath       string
+	corsOrigins    []string
+	trustedProxies []netip.Prefix
+	guard          *authGuard
+	sessions       *sessionStore
+	live           *liveHub
+	graphql        *graphql.Schema
+	crashes        *crashReporter
+
+	disabledModules []string
 }
 
 // ── House Profile ──────────────────────────────────
@@ -50,7 +76,7 @@ func (a *API) UpdateHouse(w http.ResponseWriter, r *http.Request) {
 		return
 	} else {
 		if err := a.store.UpdateHouseProfile(body); err != nil {
-			jsonError(w, http.StatusInternalServerError, err.Error())
+			handleUpdateError(w, err)
 			return
 		}
 	}
@@ -86,6 +112,9 @@ func (a *API) ListMaintenanceCategories(w http.ResponseWriter, _ *http.Request)
 
 func (a *API) ListProjects(w http.ResponseWriter, r *http.Request) {
 	items, err := a.store.ListProjects(boolQuery(r, "include_deleted"))
+	if err == nil {
+		items, err = withTags(a, r, data.DocumentEntityProject, items, func(p *data.Project) (uint, *[]string) { return p.ID, &p.Tags })
+	}
 	if err != nil {
 		jsonError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -114,7 +143,7 @@ func (a *API) CreateProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := a.store.CreateProject(&body); err != nil {
-		jsonError(w, http.StatusInternalServerError, err.Error())
+		handleCreateError(w, err)
 		return
 	}
 	jsonCreated(w, body)
@@ -133,7 +162,7 @@ func (a *API) UpdateProject(w http.ResponseWriter, r *http.Request) {
 	}
 	body.ID = id
 	if err := a.store.UpdateProject(body); err != nil {
-		jsonError(w, http.StatusInternalServerError, err.Error())
+		handleUpdateError(w, err)
 		return
 	}
 	updated, err := a.store.GetProject(id)
@@ -164,7 +193,7 @@ func (a *API) RestoreProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := a.store.RestoreProject(id); err != nil {
-		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		handleRestoreError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -209,7 +238,7 @@ func (a *API) CreateQuote(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := a.store.CreateQuote(&body.Quote, body.Vendor); err != nil {
-		jsonError(w, http.StatusInternalServerError, err.Error())
+		handleCreateError(w, err)
 		return
 	}
 	created, _ := a.store.GetQuote(body.Quote.ID)
@@ -229,7 +258,7 @@ func (a *API) UpdateQuote(w http.ResponseWriter, r *http.Request) {
 	}
 	body.Quote.ID = id
 	if err := a.store.UpdateQuote(body.Quote, body.Vendor); err != nil {
-		jsonError(w, http.StatusInternalServerError, err.Error())
+		handleUpdateError(w, err)
 		return
 	}
 	updated, _ := a.store.GetQuote(id)
@@ -256,7 +285,7 @@ func (a *API) RestoreQuote(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := a.store.RestoreQuote(id); err != nil {
-		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		handleRestoreError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -276,6 +305,25 @@ func (a *API) ListQuotesByProject(w http.ResponseWriter, r *http.Request) {
 	jsonOK(w, items)
 }
 
+// CompareQuotes lines up a project's quotes line item by line item.
+func (a *API) CompareQuotes(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := a.store.GetProject(id); err != nil {
+		handleGetError(w, err, "project")
+		return
+	}
+	cmp, err := a.store.CompareQuotes(id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, cmp)
+}
+
 func (a *API) ListQuotesByVendor(w http.ResponseWriter, r *http.Request) {
 	id, err := parseID(r)
 	if err != nil {
@@ -294,6 +342,9 @@ func (a *API) ListQuotesByVendor(w http.ResponseWriter, r *http.Request) {
 
 func (a *API) ListVendors(w http.ResponseWriter, r *http.Request) {
 	items, err := a.store.ListVendors(boolQuery(r, "include_deleted"))
+	if err == nil {
+		items, err = withTags(a, r, data.DocumentEntityVendor, items, func(p *data.Vendor) (uint, *[]string) { return p.ID, &p.Tags })
+	}
 	if err != nil {
 		jsonError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -322,7 +373,7 @@ func (a *API) CreateVendor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := a.store.CreateVendor(&body); err != nil {
-		jsonError(w, http.StatusInternalServerError, err.Error())
+		handleCreateError(w, err)
 		return
 	}
 	jsonCreated(w, body)
@@ -341,7 +392,7 @@ func (a *API) UpdateVendor(w http.ResponseWriter, r *http.Request) {
 	}
 	body.ID = id
 	if err := a.store.UpdateVendor(body); err != nil {
-		jsonError(w, http.StatusInternalServerError, err.Error())
+		handleUpdateError(w, err)
 		return
 	}
 	updated, err := a.store.GetVendor(id)
@@ -372,7 +423,7 @@ func (a *API) RestoreVendor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := a.store.RestoreVendor(id); err != nil {
-		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		handleRestoreError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -396,6 +447,9 @@ func (a *API) ListServiceLogsByVendor(w http.ResponseWriter, r *http.Request) {
 
 func (a *API) ListMaintenance(w http.ResponseWriter, r *http.Request) {
 	items, err := a.store.ListMaintenance(boolQuery(r, "include_deleted"))
+	if err == nil {
+		items, err = withTags(a, r, data.DocumentEntityMaintenance, items, func(p *data.MaintenanceItem) (uint, *[]string) { return p.ID, &p.Tags })
+	}
 	if err != nil {
 		jsonError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -424,7 +478,7 @@ func (a *API) CreateMaintenance(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := a.store.CreateMaintenance(&body); err != nil {
-		jsonError(w, http.StatusInternalServerError, err.Error())
+		handleCreateError(w, err)
 		return
 	}
 	jsonCreated(w, body)
@@ -443,7 +497,7 @@ func (a *API) UpdateMaintenance(w http.ResponseWriter, r *http.Request) {
 	}
 	body.ID = id
 	if err := a.store.UpdateMaintenance(body); err != nil {
-		jsonError(w, http.StatusInternalServerError, err.Error())
+		handleUpdateError(w, err)
 		return
 	}
 	updated, err := a.store.GetMaintenance(id)
@@ -474,7 +528,7 @@ func (a *API) RestoreMaintenance(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := a.store.RestoreMaintenance(id); err != nil {
-		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		handleRestoreError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -502,6 +556,17 @@ type serviceLogRequest struct {
 	Vendor data.Vendor `json:"Vendor"`
 }
 
+// ListAllServiceLogs returns the service history of every maintenance
+// item, most recent first.
+func (a *API) ListAllServiceLogs(w http.ResponseWriter, r *http.Request) {
+	items, err := a.store.ListServiceLogs(boolQuery(r, "include_deleted"))
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, items)
+}
+
 func (a *API) ListServiceLogs(w http.ResponseWriter, r *http.Request) {
 	id, err := parseID(r)
 	if err != nil {
@@ -543,7 +608,7 @@ func (a *API) CreateServiceLog(w http.ResponseWriter, r *http.Request) {
 	}
 	body.ServiceLogEntry.MaintenanceItemID = maintID
 	if err := a.store.CreateServiceLog(&body.ServiceLogEntry, body.Vendor); err != nil {
-		jsonError(w, http.StatusInternalServerError, err.Error())
+		handleCreateError(w, err)
 		return
 	}
 	created, _ := a.store.GetServiceLog(body.ServiceLogEntry.ID)
@@ -563,7 +628,7 @@ func (a *API) UpdateServiceLog(w http.ResponseWriter, r *http.Request) {
 	}
 	body.ServiceLogEntry.ID = id
 	if err := a.store.UpdateServiceLog(body.ServiceLogEntry, body.Vendor); err != nil {
-		jsonError(w, http.StatusInternalServerError, err.Error())
+		handleUpdateError(w, err)
 		return
 	}
 	updated, _ := a.store.GetServiceLog(id)
@@ -590,7 +655,7 @@ func (a *API) RestoreServiceLog(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := a.store.RestoreServiceLog(id); err != nil {
-		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		handleRestoreError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -600,6 +665,9 @@ func (a *API) RestoreServiceLog(w http.ResponseWriter, r *http.Request) {
 
 func (a *API) ListAppliances(w http.ResponseWriter, r *http.Request) {
 	items, err := a.store.ListAppliances(boolQuery(r, "include_deleted"))
+	if err == nil {
+		items, err = withTags(a, r, data.DocumentEntityAppliance, items, func(p *data.Appliance) (uint, *[]string) { return p.ID, &p.Tags })
+	}
 	if err != nil {
 		jsonError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -628,7 +696,7 @@ func (a *API) CreateAppliance(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := a.store.CreateAppliance(&body); err != nil {
-		jsonError(w, http.StatusInternalServerError, err.Error())
+		handleCreateError(w, err)
 		return
 	}
 	jsonCreated(w, body)
@@ -647,7 +715,7 @@ func (a *API) UpdateAppliance(w http.ResponseWriter, r *http.Request) {
 	}
 	body.ID = id
 	if err := a.store.UpdateAppliance(body); err != nil {
-		jsonError(w, http.StatusInternalServerError, err.Error())
+		handleUpdateError(w, err)
 		return
 	}
 	updated, err := a.store.GetAppliance(id)
@@ -678,7 +746,7 @@ func (a *API) RestoreAppliance(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := a.store.RestoreAppliance(id); err != nil {
-		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		handleRestoreError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -716,7 +784,7 @@ func (a *API) CreateIncident(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := a.store.CreateIncident(&body); err != nil {
-		jsonError(w, http.StatusInternalServerError, err.Error())
+		handleCreateError(w, err)
 		return
 	}
 	jsonCreated(w, body)
@@ -735,7 +803,7 @@ func (a *API) UpdateIncident(w http.ResponseWriter, r *http.Request) {
 	}
 	body.ID = id
 	if err := a.store.UpdateIncident(body); err != nil {
-		jsonError(w, http.StatusInternalServerError, err.Error())
+		handleUpdateError(w, err)
 		return
 	}
 	updated, err := a.store.GetIncident(id)
@@ -766,7 +834,7 @@ func (a *API) RestoreIncident(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := a.store.RestoreIncident(id); err != nil {
-		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		handleRestoreError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -782,6 +850,47 @@ func handleGetError(w http.ResponseWriter, err error, entity string) {
 	jsonError(w, http.StatusInternalServerError, err.Error())
 }
 
+// handleUpdateError answers 409 when the client saved over a newer
+// version of the record, so it can show what changed.
+func handleUpdateError(w http.ResponseWriter, err error) {
+	if errors.Is(err, data.ErrVersionConflict) {
+		jsonError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	handleCreateError(w, err)
+}
+
+// handleCreateError answers 422 when the record refers to one that doesn't
+// exist, like a quote for a missing project, its quote lines don't add up,
+// a project's status reason isn't known, or a project would be completed
+// with open tasks.
+func handleCreateError(w http.ResponseWriter, err error) {
+	if errors.Is(err, data.ErrQuoteLines) || errors.Is(err, data.ErrCustomField) ||
+		errors.Is(err, data.ErrStatusReason) || errors.Is(err, data.ErrOpenTasks) {
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
+		jsonError(w, http.StatusUnprocessableEntity, "refers to a record that doesn't exist")
+		return
+	}
+	jsonError(w, http.StatusInternalServerError, err.Error())
+}
+
+// handleRestoreError answers 422 when the record can't come back yet, e.g.
+// because its parent is in the trash.
+func handleRestoreError(w http.ResponseWriter, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	jsonError(w, http.StatusUnprocessableEntity, err.Error())
+}
+
 func handleDeleteError(w http.ResponseWriter, err error) {
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		jsonError(w, http.StatusNotFound, "not found")
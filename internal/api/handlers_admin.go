@@ -0,0 +1,643 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/sched"
+	"github.com/cpcloud/webcasa/internal/totp"
+)
+
+// AdminOptions configures the admin panel. The panel is disabled while
+// Password is empty.
+type AdminOptions struct {
+	Password  string
+	BackupDir string
+	Scheduler *sched.Scheduler
+	// ConfigTOML is the running configuration with secrets redacted.
+	ConfigTOML string
+}
+
+// WithAdmin enables the admin endpoints under /api/admin/.
+func WithAdmin(opts AdminOptions) Option {
+	return func(a *API) { a.admin = opts }
+}
+
+// backupPrefix and backupSuffix frame the names of "backup now" files.
+const (
+	backupPrefix = "webcasa-"
+	backupSuffix = ".db"
+)
+
+// ── Admin ──────────────────────────────────────────
+
+// requireAdmin guards an admin handler. The bearer token is either a
+// session from AdminLogin or, while two-factor authentication is off, the
+// admin password itself.
+func (a *API) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if a.admin.Password == "" {
+			jsonError(w, http.StatusForbidden,
+				"admin panel is disabled -- set admin.password or WEBCASA_ADMIN_PASSWORD")
+			return
+		}
+		if a.guard.blocked(w, r) {
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if ok && a.sessions.valid(token, time.Now()) {
+			next(w, r)
+			return
+		}
+		if ok && a.checkAdminPassword(token) {
+			factor, err := a.store.SecondFactor(adminAccount)
+			if err != nil {
+				jsonError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			if factor == nil || !factor.Confirmed() {
+				next(w, r)
+				return
+			}
+			jsonError(w, http.StatusUnauthorized, "two-factor authentication is on -- sign in with a code")
+			return
+		}
+		if ok {
+			a.guard.fail(r)
+		}
+		w.Header().Set("WWW-Authenticate", `Bearer realm="webcasa admin"`)
+		jsonError(w, http.StatusUnauthorized, "admin password required")
+	}
+}
+
+func (a *API) checkAdminPassword(pw string) bool {
+	return subtle.ConstantTimeCompare([]byte(pw), []byte(a.admin.Password)) == 1
+}
+
+// adminAccount names the admin panel's login where an account is needed,
+// such as for its two-factor enrollment.
+const adminAccount = "admin"
+
+// adminSessionTTL is how long an admin sign-in lasts.
+const adminSessionTTL = 12 * time.Hour
+
+// sessionPrefix starts admin session tokens.
+const sessionPrefix = "wcs_"
+
+// sessionStore holds admin sign-ins in memory; a restart signs everyone
+// out.
+type sessionStore struct {
+	mu      sync.Mutex
+	expires map[string]time.Time
+}
+
+func newSessionStore() *sessionStore {
+	return &sessionStore{expires: make(map[string]time.Time)}
+}
+
+func (s *sessionStore) create(now time.Time) (string, time.Time, error) {
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return "", time.Time{}, err
+	}
+	token := sessionPrefix + hex.EncodeToString(raw)
+	exp := now.Add(adminSessionTTL)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for t, e := range s.expires {
+		if !now.Before(e) {
+			delete(s.expires, t)
+		}
+	}
+	s.expires[token] = exp
+	return token, exp, nil
+}
+
+func (s *sessionStore) valid(token string, now time.Time) bool {
+	if !strings.HasPrefix(token, sessionPrefix) {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	exp, ok := s.expires[token]
+	return ok && now.Before(exp)
+}
+
+func (s *sessionStore) revoke(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.expires, token)
+}
+
+// revokeAll signs everyone out, e.g. when two-factor authentication is
+// turned off.
+func (s *sessionStore) revokeAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.expires)
+}
+
+type adminLoginRequest struct {
+	Password string `json:"password"`
+	// Code is a current authenticator code or a recovery code, required
+	// once two-factor authentication is on.
+	Code string `json:"code"`
+}
+
+type adminSession struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AdminLogin trades the admin password, plus a two-factor code when
+// enrolled, for a session token.
+func (a *API) AdminLogin(w http.ResponseWriter, r *http.Request) {
+	if a.admin.Password == "" {
+		jsonError(w, http.StatusForbidden,
+			"admin panel is disabled -- set admin.password or WEBCASA_ADMIN_PASSWORD")
+		return
+	}
+	if a.guard.blocked(w, r) {
+		return
+	}
+	body, err := decodeBody[adminLoginRequest](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !a.checkAdminPassword(body.Password) {
+		a.guard.fail(r)
+		jsonError(w, http.StatusUnauthorized, "wrong admin password")
+		return
+	}
+	factor, err := a.store.SecondFactor(adminAccount)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if factor != nil && factor.Confirmed() {
+		if strings.TrimSpace(body.Code) == "" {
+			writeJSON(w, http.StatusUnauthorized, map[string]any{
+				"error": "two-factor code required", "code_required": true,
+			})
+			return
+		}
+		if err := a.store.CheckSecondFactor(adminAccount, body.Code, time.Now()); err != nil {
+			if errors.Is(err, data.ErrBadSecondFactor) {
+				a.guard.fail(r)
+				writeJSON(w, http.StatusUnauthorized, map[string]any{
+					"error": err.Error(), "code_required": true,
+				})
+				return
+			}
+			jsonError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	token, exp, err := a.sessions.create(time.Now())
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, adminSession{Token: token, ExpiresAt: exp})
+}
+
+func (a *API) AdminLogout(w http.ResponseWriter, r *http.Request) {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		a.sessions.revoke(token)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type adminJob struct {
+	Name       string     `json:"name"`
+	Schedule   string     `json:"schedule"`
+	LastRunAt  *time.Time `json:"last_run_at"`
+	LastError  string     `json:"last_error"`
+	DurationMS int64      `json:"duration_ms"`
+	NextRunAt  *time.Time `json:"next_run_at"`
+}
+
+type adminBackup struct {
+	Name      string    `json:"name"`
+	Bytes     int64     `json:"bytes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type adminOverview struct {
+	Storage   data.StorageStats `json:"storage"`
+	Jobs      []adminJob        `json:"jobs"`
+	Backups   []adminBackup     `json:"backups"`
+	BackupDir string            `json:"backup_dir"`
+	Config    string            `json:"config"`
+}
+
+func (a *API) AdminOverview(w http.ResponseWriter, _ *http.Request) {
+	stats, err := a.store.StorageStats()
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jobs, err := a.adminJobs()
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	backups, err := a.listBackups()
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, adminOverview{
+		Storage:   stats,
+		Jobs:      jobs,
+		Backups:   backups,
+		BackupDir: a.admin.BackupDir,
+		Config:    a.admin.ConfigTOML,
+	})
+}
+
+func (a *API) adminJobs() ([]adminJob, error) {
+	out := []adminJob{}
+	if a.admin.Scheduler == nil {
+		return out, nil
+	}
+	statuses, err := a.admin.Scheduler.Status()
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range statuses {
+		j := adminJob{Name: st.Name, Schedule: st.Schedule}
+		if st.LastRun != nil {
+			j.LastRunAt = &st.LastRun.LastRunAt
+			j.LastError = st.LastRun.LastError
+			j.DurationMS = st.LastRun.DurationMS
+		}
+		if !st.NextRunAt.IsZero() {
+			j.NextRunAt = &st.NextRunAt
+		}
+		out = append(out, j)
+	}
+	return out, nil
+}
+
+func (a *API) listBackups() ([]adminBackup, error) {
+	out := []adminBackup{}
+	if a.admin.BackupDir == "" {
+		return out, nil
+	}
+	entries, err := os.ReadDir(a.admin.BackupDir)
+	if os.IsNotExist(err) {
+		return out, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, backupSuffix) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		out = append(out, adminBackup{Name: name, Bytes: info.Size(), CreatedAt: info.ModTime()})
+	}
+	slices.SortFunc(out, func(x, y adminBackup) int { return y.CreatedAt.Compare(x.CreatedAt) })
+	return out, nil
+}
+
+func (a *API) AdminBackup(w http.ResponseWriter, _ *http.Request) {
+	if a.admin.BackupDir == "" {
+		jsonError(w, http.StatusConflict, "no backup directory configured")
+		return
+	}
+	if err := os.MkdirAll(a.admin.BackupDir, 0o700); err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	name := backupPrefix + time.Now().Format("20060102-150405") + backupSuffix
+	path := filepath.Join(a.admin.BackupDir, name)
+	if err := a.store.Backup(path); err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonCreated(w, adminBackup{Name: name, Bytes: info.Size(), CreatedAt: info.ModTime()})
+}
+
+// AdminRunJob starts a job in the background; its outcome shows up in the
+// job list.
+func (a *API) AdminRunJob(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if a.admin.Scheduler == nil || !slices.ContainsFunc(a.admin.Scheduler.Jobs(), func(j sched.Job) bool {
+		return j.Name == name
+	}) {
+		jsonError(w, http.StatusNotFound, "no job named "+strconv.Quote(name))
+		return
+	}
+	go func() { _ = a.admin.Scheduler.RunNow(context.Background(), name) }()
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "started"})
+}
+
+func (a *API) AdminDeletions(w http.ResponseWriter, r *http.Request) {
+	limit := 100
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			jsonError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = min(n, 1000)
+	}
+	records, err := a.store.ListDeletions(limit)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, records)
+}
+
+// ── Users ──────────────────────────────────────────
+
+type userRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// The user endpoints sit behind the admin password, like "webcasa user"
+// on the command line.
+
+func (a *API) ListUsers(w http.ResponseWriter, _ *http.Request) {
+	users, err := a.store.ListUsers()
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, users)
+}
+
+func (a *API) CreateUser(w http.ResponseWriter, r *http.Request) {
+	body, err := decodeBody[userRequest](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	u, err := a.store.CreateUser(body.Username, body.Password)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	jsonCreated(w, u)
+}
+
+// RemoveUser deletes an account, signing it out everywhere.
+func (a *API) RemoveUser(w http.ResponseWriter, r *http.Request) {
+	if err := a.store.RemoveUser(r.PathValue("name")); err != nil {
+		handleGetError(w, err, "user")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ── Audit Log ──────────────────────────────────────
+
+// AdminAuditLog pages through the audit log, newest first. entity and
+// actor narrow it, limit sets the page size (default 100) and before, from
+// the previous page's X-Next-Cursor header, picks up where it left off.
+func (a *API) AdminAuditLog(w http.ResponseWriter, r *http.Request) {
+	q := data.AuditQuery{Entity: r.URL.Query().Get("entity"), Actor: r.URL.Query().Get("actor"), Limit: 100}
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > data.MaxAuditPage {
+			jsonError(w, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(data.MaxAuditPage))
+			return
+		}
+		q.Limit = n
+	}
+	if raw := r.URL.Query().Get("before"); raw != "" {
+		n, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			jsonError(w, http.StatusBadRequest, "before must be an entry ID")
+			return
+		}
+		q.Before = uint(n)
+	}
+	entries, before, err := a.store.ListAuditLog(q)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if before != 0 {
+		w.Header().Set(nextCursorHeader, strconv.FormatUint(uint64(before), 10))
+	}
+	jsonOK(w, entries)
+}
+
+// ── API Tokens ─────────────────────────────────────
+
+type tokenRequest struct {
+	Name      string `json:"name"`
+	Scope     string `json:"scope"`
+	RateLimit int    `json:"rate_limit"`
+}
+
+type createdToken struct {
+	Token data.APIToken `json:"token"`
+	// Secret is shown once, at creation.
+	Secret string `json:"secret"`
+}
+
+// The token endpoints sit behind the admin password.
+
+func (a *API) ListAPITokens(w http.ResponseWriter, _ *http.Request) {
+	toks, err := a.store.ListAPITokens()
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, toks)
+}
+
+func (a *API) CreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	body, err := decodeBody[tokenRequest](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	tok, secret, err := a.store.CreateAPIToken(body.Name, body.Scope, body.RateLimit)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	jsonCreated(w, createdToken{Token: tok, Secret: secret})
+}
+
+func (a *API) RevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RevokeAPIToken(id); err != nil {
+		handleGetError(w, err, "API token")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ── Two-Factor Authentication ──────────────────────
+
+type twoFactorStatus struct {
+	Enabled       bool `json:"enabled"`
+	Pending       bool `json:"pending"`
+	RecoveryCodes int  `json:"recovery_codes_left"`
+}
+
+type twoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type twoFactorCode struct {
+	Code string `json:"code"`
+}
+
+// factorKey holds the account a request's two-factor routes work on, when
+// it isn't the admin panel's.
+type factorKey struct{}
+
+// forSignedIn serves the two-factor routes for the signed-in account. It
+// answers 403 when there is none, as before the first account is made or
+// for a request with an API token.
+func forSignedIn(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u, ok := r.Context().Value(userKey{}).(data.User)
+		if !ok {
+			jsonError(w, http.StatusForbidden, "sign in to an account to use two-factor authentication")
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), factorKey{}, data.UserAccount(u.Username))))
+	}
+}
+
+// factorAccount is the account the request's two-factor routes work on:
+// the signed-in account's under forSignedIn, the admin panel's otherwise.
+func factorAccount(r *http.Request) string {
+	if account, ok := r.Context().Value(factorKey{}).(string); ok {
+		return account
+	}
+	return adminAccount
+}
+
+func (a *API) TwoFactorStatus(w http.ResponseWriter, r *http.Request) {
+	f, err := a.store.SecondFactor(factorAccount(r))
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var st twoFactorStatus
+	if f != nil {
+		st = twoFactorStatus{Enabled: f.Confirmed(), Pending: !f.Confirmed(), RecoveryCodes: f.RecoveryCodesLeft()}
+	}
+	jsonOK(w, st)
+}
+
+// StartTwoFactor issues a new secret for the authenticator app. It takes
+// effect once ConfirmTwoFactor sees a code from it.
+func (a *API) StartTwoFactor(w http.ResponseWriter, r *http.Request) {
+	account := factorAccount(r)
+	f, err := a.store.StartSecondFactor(account)
+	if err != nil {
+		jsonError(w, http.StatusConflict, err.Error())
+		return
+	}
+	jsonCreated(w, twoFactorEnrollment{
+		Secret: f.Secret,
+		URI:    totp.URI(data.AppName, strings.TrimPrefix(account, data.UserAccount("")), f.Secret),
+	})
+}
+
+// ConfirmTwoFactor turns two-factor authentication on and returns the
+// recovery codes, shown only this once. Existing sessions stay signed in.
+func (a *API) ConfirmTwoFactor(w http.ResponseWriter, r *http.Request) {
+	if a.guard.blocked(w, r) {
+		return
+	}
+	body, err := decodeBody[twoFactorCode](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	codes, err := a.store.ConfirmSecondFactor(factorAccount(r), body.Code, time.Now())
+	if errors.Is(err, data.ErrBadSecondFactor) {
+		a.guard.fail(r)
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if err != nil {
+		jsonError(w, http.StatusConflict, err.Error())
+		return
+	}
+	jsonOK(w, map[string][]string{"recovery_codes": codes})
+}
+
+// DisableTwoFactor turns two-factor authentication off. It takes a current
+// code, so a stolen session alone can't remove the second factor, and
+// wrong codes count against the client like failed sign-ins. Turning
+// it off for the admin panel signs out every admin session.
+func (a *API) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	if a.guard.blocked(w, r) {
+		return
+	}
+	body, err := decodeBody[twoFactorCode](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	account := factorAccount(r)
+	f, err := a.store.SecondFactor(account)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if f != nil && f.Confirmed() {
+		if err := a.store.CheckSecondFactor(account, body.Code, time.Now()); err != nil {
+			if errors.Is(err, data.ErrBadSecondFactor) {
+				a.guard.fail(r)
+				jsonError(w, http.StatusUnprocessableEntity, err.Error())
+				return
+			}
+			jsonError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	if err := a.store.DisableSecondFactor(account); err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if account == adminAccount {
+		a.sessions.revokeAll()
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
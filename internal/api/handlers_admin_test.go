@@ -0,0 +1,98 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTwoFactorCodeGuessesLockOut(t *testing.T) {
+	store, err := data.Open(filepath.Join(t.TempDir(), "api.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	require.NoError(t, store.AutoMigrate())
+	require.NoError(t, store.SeedDefaults())
+	srv := NewServer(store, "")
+	now := time.Now()
+
+	// enroll signs a user in and starts their enrollment, returning the
+	// session token and the secret.
+	enroll := func(name string) (string, string) {
+		t.Helper()
+		_, err := store.CreateUser(name, "correct horse battery")
+		require.NoError(t, err)
+		_, token, _, err := store.SignIn(name, "correct horse battery", "", now)
+		require.NoError(t, err)
+		f, err := store.StartSecondFactor(data.UserAccount(name))
+		require.NoError(t, err)
+		return token, f.Secret
+	}
+	// wrong is a six-digit code outside every window a check accepts.
+	wrong := func(secret string) string {
+		t.Helper()
+		for _, guess := range []string{"000000", "111111", "222222", "333333"} {
+			ok := true
+			for step := totp.Step(now) - 2; step <= totp.Step(now)+2; step++ {
+				code, err := totp.Code(secret, step)
+				require.NoError(t, err)
+				ok = ok && code != guess
+			}
+			if ok {
+				return guess
+			}
+		}
+		t.Fatal("no wrong code to guess")
+		return ""
+	}
+	post := func(client, token, path, code string) int {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"code":"`+code+`"}`))
+		req.RemoteAddr = client + ":4711"
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// A stolen session guessing at the code to turn the second factor off
+	// runs out of guesses, and then even the right code is turned away.
+	token, secret := enroll("sam")
+	code, err := totp.Code(secret, totp.Step(now))
+	require.NoError(t, err)
+	_, err = store.ConfirmSecondFactor(data.UserAccount("sam"), code, now)
+	require.NoError(t, err)
+	for range authFailuresPerMinute {
+		assert.Equal(t, http.StatusUnprocessableEntity, post("192.0.2.1", token, "/api/auth/2fa/disable", wrong(secret)))
+	}
+	next, err := totp.Code(secret, totp.Step(now)+1)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, post("192.0.2.1", token, "/api/auth/2fa/disable", next))
+	f, err := store.SecondFactor(data.UserAccount("sam"))
+	require.NoError(t, err)
+	require.NotNil(t, f)
+	assert.True(t, f.Confirmed())
+
+	// Confirming an enrollment counts wrong codes the same way.
+	token, secret = enroll("kim")
+	for range authFailuresPerMinute {
+		assert.Equal(t, http.StatusUnprocessableEntity, post("192.0.2.2", token, "/api/auth/2fa/confirm", wrong(secret)))
+	}
+	code, err = totp.Code(secret, totp.Step(now))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, post("192.0.2.2", token, "/api/auth/2fa/confirm", code))
+	f, err = store.SecondFactor(data.UserAccount("kim"))
+	require.NoError(t, err)
+	require.NotNil(t, f)
+	assert.False(t, f.Confirmed())
+}
@@ -0,0 +1,153 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// ── Air Filters ────────────────────────────────────
+
+func (a *API) ListAirFilterSpecs(w http.ResponseWriter, r *http.Request) {
+	items, err := a.store.ListAirFilterSpecs(boolQuery(r, "include_deleted"))
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, items)
+}
+
+func (a *API) GetAirFilterSpec(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	item, err := a.store.GetAirFilterSpec(id)
+	if err != nil {
+		handleGetError(w, err, "air filter")
+		return
+	}
+	jsonOK(w, item)
+}
+
+// ListAirFilterSuggestions returns the filters to swap (and buy, when out of
+// stock) over the next two weeks, including overdue ones.
+func (a *API) ListAirFilterSuggestions(w http.ResponseWriter, _ *http.Request) {
+	items, err := a.store.ListAirFilterSuggestions(time.Now(), 14*24*time.Hour)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if items == nil {
+		items = []data.AirFilterSuggestion{}
+	}
+	jsonOK(w, items)
+}
+
+func (a *API) CreateAirFilterSpec(w http.ResponseWriter, r *http.Request) {
+	body, err := decodeBody[data.AirFilterSpec](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.CreateAirFilterSpec(&body); err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	created, err := a.store.GetAirFilterSpec(body.ID)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonCreated(w, created)
+}
+
+func (a *API) UpdateAirFilterSpec(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[data.AirFilterSpec](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.ID = id
+	if err := a.store.UpdateAirFilterSpec(body); err != nil {
+		handleUpdateError(w, err)
+		return
+	}
+	updated, err := a.store.GetAirFilterSpec(id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, updated)
+}
+
+func (a *API) DeleteAirFilterSpec(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.DeleteAirFilterSpec(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) RestoreAirFilterSpec(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RestoreAirFilterSpec(id); err != nil {
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// airFilterChangeRequest is the optional body for POST .../change. An absent
+// or zero ChangedAt means "now".
+type airFilterChangeRequest struct {
+	ChangedAt time.Time `json:"changedAt"`
+}
+
+func (a *API) RecordAirFilterChange(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var body airFilterChangeRequest
+	if r.ContentLength != 0 {
+		body, err = decodeBody[airFilterChangeRequest](r)
+		if err != nil {
+			jsonError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if body.ChangedAt.IsZero() {
+		body.ChangedAt = time.Now()
+	}
+	if err := a.store.RecordAirFilterChange(id, body.ChangedAt); err != nil {
+		handleGetError(w, err, "air filter")
+		return
+	}
+	updated, err := a.store.GetAirFilterSpec(id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, updated)
+}
@@ -0,0 +1,56 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// ── Vendor analytics ───────────────────────────────
+
+// VendorAnalytics ranks the vendors by what the house has spent with them,
+// with how their jobs compare on cost, how quickly they quote and how
+// often they're hired again.
+func (a *API) VendorAnalytics(w http.ResponseWriter, _ *http.Request) {
+	stats, err := a.store.VendorAnalytics()
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, stats)
+}
+
+// ── Cycle times ────────────────────────────────────
+
+// CycleTimes reports how long the house's projects take from asking for
+// quotes to choosing one, and how their actual durations compare with
+// the planned ones.
+func (a *API) CycleTimes(w http.ResponseWriter, _ *http.Request) {
+	times, err := a.store.CycleTimes()
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, times)
+}
+
+// ── Slip reasons ───────────────────────────────────
+
+// SlipReasons reports why the house's projects were delayed or abandoned.
+func (a *API) SlipReasons(w http.ResponseWriter, _ *http.Request) {
+	report, err := a.store.SlipReasons()
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if report.Reasons == nil {
+		report.Reasons = []data.StatusReasonCount{}
+	}
+	if report.Changes == nil {
+		report.Changes = []data.ProjectStatusChange{}
+	}
+	jsonOK(w, report)
+}
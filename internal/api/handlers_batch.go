@@ -0,0 +1,279 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/hooks"
+)
+
+// ── Batches ────────────────────────────────────────
+
+const maxBatchOperations = 100
+
+// batchOperation is one API call in a batch, e.g.
+// {"method":"PUT","path":"/api/projects/3","body":{...}}. A path segment
+// or body string of the form "$N" stands for the ID of the record saved by
+// operation N, so one batch can create a vendor and quote it.
+type batchOperation struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+type batchRequest struct {
+	Operations []batchOperation `json:"operations"`
+}
+
+type batchResult struct {
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+type batchResponse struct {
+	Results []batchResult `json:"results"`
+	Failed  *int          `json:"failed,omitempty"`
+	Error   string        `json:"error,omitempty"`
+}
+
+var errBatchFailed = errors.New("batch operation failed")
+
+// Batch runs a list of creates, updates and deletes in one transaction.
+// Either all of them are saved or, as soon as one fails, none are; the
+// response has each operation's status and body either way.
+func (a *API) Batch(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeBody[batchRequest](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(req.Operations) == 0 || len(req.Operations) > maxBatchOperations {
+		jsonError(w, http.StatusBadRequest,
+			fmt.Sprintf("a batch takes 1 to %d operations", maxBatchOperations))
+		return
+	}
+	for i, op := range req.Operations {
+		if err := checkBatchOperation(op); err != nil {
+			jsonError(w, http.StatusBadRequest, fmt.Sprintf("operation %d: %s", i, err))
+			return
+		}
+		if m := pathModule(op.Path); m != "" && slices.Contains(a.disabledModules, m) {
+			jsonError(w, http.StatusForbidden, fmt.Sprintf("operation %d: the %s module is turned off", i, m))
+			return
+		}
+	}
+
+	// Hooks and live events wait for the commit, so nothing hears about
+	// changes that get rolled back.
+	deferred := &deferredHooks{}
+	ctx := context.WithValue(r.Context(), deferredHooksKey{}, deferred)
+	results := make([]batchResult, 0, len(req.Operations))
+	err = a.store.InTransaction(func(tx *data.Store) error {
+		b := *a
+		b.store = tx
+		mux := http.NewServeMux()
+		b.routes(mux)
+		handler := withHooks(mux, a.hooks)
+		ctx := context.WithValue(ctx, apiKey{}, &b)
+		for _, op := range req.Operations {
+			res := runBatchOperation(ctx, handler, r, op, results)
+			results = append(results, res)
+			if res.Status/100 != 2 {
+				return errBatchFailed
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errBatchFailed) {
+		failed := len(results) - 1
+		writeJSON(w, results[failed].Status, batchResponse{
+			Results: results,
+			Failed:  &failed,
+			Error:   fmt.Sprintf("operation %d failed; nothing was saved", failed),
+		})
+		return
+	}
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	for _, ev := range deferred.events {
+		a.hooks.Notify(ev)
+	}
+	for i, op := range req.Operations {
+		path, _ := resolveBatchPath(op.Path, results[:i])
+		if res, ok := changedResource(path); ok {
+			a.live.publish(liveEvent{Type: "changed", Resource: res, Method: op.Method})
+		}
+	}
+	jsonOK(w, batchResponse{Results: results})
+}
+
+// checkBatchOperation allows changes to records only: no reads, admin
+// calls, uploads or nested batches.
+func checkBatchOperation(op batchOperation) error {
+	switch op.Method {
+	case http.MethodPost, http.MethodPut, http.MethodDelete:
+	default:
+		return fmt.Errorf("method must be POST, PUT or DELETE, not %q", op.Method)
+	}
+	rest, ok := strings.CutPrefix(op.Path, "/api/")
+	if !ok || strings.ContainsAny(op.Path, "?#") {
+		return fmt.Errorf("path %q isn't an API path", op.Path)
+	}
+	if strings.HasPrefix(rest, "admin/") || rest == "batch" || rest == "presence" || rest == "graphql" ||
+		(op.Method == http.MethodPost && rest == "documents") ||
+		(op.Method == http.MethodPost && rest == "floor-plans") {
+		return fmt.Errorf("%s %s can't be batched", op.Method, op.Path)
+	}
+	return nil
+}
+
+func runBatchOperation(
+	ctx context.Context, handler http.Handler, r *http.Request, op batchOperation, done []batchResult,
+) batchResult {
+	path, err := resolveBatchPath(op.Path, done)
+	if err != nil {
+		return batchError(http.StatusBadRequest, err)
+	}
+	body, err := resolveBatchBody(op.Body, done)
+	if err != nil {
+		return batchError(http.StatusBadRequest, err)
+	}
+	sub, err := http.NewRequestWithContext(ctx, op.Method, path, bytes.NewReader(body))
+	if err != nil {
+		return batchError(http.StatusBadRequest, err)
+	}
+	sub.Header.Set("Content-Type", "application/json")
+	// The session goes along for routes that look at it, like restoring.
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		sub.Header.Set("Authorization", auth)
+	}
+	if c, err := r.Cookie(sessionCookie); err == nil {
+		sub.AddCookie(c)
+	}
+	sub.RemoteAddr = r.RemoteAddr
+	cw := &captureWriter{header: make(http.Header)}
+	handler.ServeHTTP(cw, sub)
+	res := batchResult{Status: cw.status}
+	if res.Status == 0 {
+		res.Status = http.StatusOK
+	}
+	if out := bytes.TrimSpace(cw.buf.Bytes()); len(out) > 0 && json.Valid(out) {
+		res.Body = json.RawMessage(out)
+	}
+	return res
+}
+
+func batchError(status int, err error) batchResult {
+	body, _ := json.Marshal(map[string]string{"error": err.Error()})
+	return batchResult{Status: status, Body: body}
+}
+
+// batchRef returns the ID saved by the operation a "$N" reference names.
+func batchRef(ref string, done []batchResult) (uint, bool, error) {
+	n, ok := strings.CutPrefix(ref, "$")
+	if !ok {
+		return 0, false, nil
+	}
+	i, err := strconv.Atoi(n)
+	if err != nil {
+		return 0, false, nil
+	}
+	if i < 0 || i >= len(done) {
+		return 0, true, fmt.Errorf("%s refers to an operation that hasn't run yet", ref)
+	}
+	var saved struct{ ID uint }
+	if json.Unmarshal(done[i].Body, &saved) != nil || saved.ID == 0 {
+		return 0, true, fmt.Errorf("operation %d didn't return a record ID", i)
+	}
+	return saved.ID, true, nil
+}
+
+func resolveBatchPath(path string, done []batchResult) (string, error) {
+	segs := strings.Split(path, "/")
+	for i, seg := range segs {
+		id, isRef, err := batchRef(seg, done)
+		if err != nil {
+			return "", err
+		}
+		if isRef {
+			segs[i] = strconv.FormatUint(uint64(id), 10)
+		}
+	}
+	return strings.Join(segs, "/"), nil
+}
+
+func resolveBatchBody(body json.RawMessage, done []batchResult) ([]byte, error) {
+	if len(body) == 0 || !bytes.Contains(body, []byte(`"$`)) {
+		return body, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	var resolve func(v any) (any, error)
+	resolve = func(v any) (any, error) {
+		switch v := v.(type) {
+		case string:
+			id, isRef, err := batchRef(v, done)
+			if err != nil || !isRef {
+				return v, err
+			}
+			return id, nil
+		case map[string]any:
+			for k, x := range v {
+				x, err := resolve(x)
+				if err != nil {
+					return nil, err
+				}
+				v[k] = x
+			}
+		case []any:
+			for i, x := range v {
+				x, err := resolve(x)
+				if err != nil {
+					return nil, err
+				}
+				v[i] = x
+			}
+		}
+		return v, nil
+	}
+	v, err := resolve(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// deferredHooks collects notify events from a batch until it commits.
+type deferredHooks struct {
+	events []hooks.Event
+}
+
+type deferredHooksKey struct{}
+
+// notifyHook sends ev to the notify hooks, or holds it back when the
+// request is part of a batch.
+func notifyHook(ctx context.Context, d *hooks.Dispatcher, ev hooks.Event) {
+	if deferred, ok := ctx.Value(deferredHooksKey{}).(*deferredHooks); ok {
+		deferred.events = append(deferred.events, ev)
+		return
+	}
+	d.Notify(ev)
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/exports"
+	"gorm.io/gorm"
+)
+
+// ── Bid requests ───────────────────────────────────
+
+// BidRequestDocument writes the project's request for bids. ?format= is
+// markdown (the default), text for an email body, or html for a page to
+// print.
+func (a *API) BidRequestDocument(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = exports.BidFormatMarkdown
+	}
+	body, err := exports.BidRequest(a.store, id, format, time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		jsonError(w, http.StatusNotFound, "project not found")
+		return
+	} else if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	switch format {
+	case exports.BidFormatHTML:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	case exports.BidFormatMarkdown:
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	default:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	_, _ = w.Write(body)
+}
+
+// ListBidRequests lists the vendors the project's bid request went to.
+func (a *API) ListBidRequests(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	bids, err := a.store.ListBidRequests(id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, bids)
+}
+
+// RecordBidRequests notes that the bid request was sent. Body:
+// {"vendorIds": [1, 2], "followUpDays": 7}; followUpDays of 0 means no
+// reminder.
+func (a *API) RecordBidRequests(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[struct {
+		VendorIDs    []uint `json:"vendorIds"`
+		FollowUpDays int    `json:"followUpDays"`
+	}](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RecordBidRequests(id, body.VendorIDs, time.Now(), body.FollowUpDays); err != nil {
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	bids, err := a.store.ListBidRequests(id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonCreated(w, bids)
+}
+
+// CloseBidRequest stops follow-up reminders with {"closed": true}, or
+// starts them again with false.
+func (a *API) CloseBidRequest(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[struct {
+		Closed bool `json:"closed"`
+	}](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.CloseBidRequest(id, body.Closed, time.Now()); err != nil {
+		handleGetError(w, err, "bid request")
+		return
+	}
+	bid, err := a.store.GetBidRequest(id)
+	if err != nil {
+		handleGetError(w, err, "bid request")
+		return
+	}
+	jsonOK(w, bid)
+}
+
+func (a *API) RemoveBidRequest(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RemoveBidRequest(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
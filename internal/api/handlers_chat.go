@@ -0,0 +1,132 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/chat"
+	"github.com/cpcloud/webcasa/internal/llm"
+	"gorm.io/gorm"
+)
+
+// WithLLMBudget reports how much of the models' token budget is spent in
+// the chat status. The clients given WithLLM and WithChatModels should
+// carry it as their Meter.
+func WithLLMBudget(b *chat.Budget) Option {
+	return func(a *API) { a.budget = b }
+}
+
+// chatTimeout bounds how long answering a question may take, across all
+// of its calls to the model.
+const chatTimeout = 3 * time.Minute
+
+// WithChatModels has chat write its queries with sql and answer from their
+// results with summary. Either may be nil, leaving that stage to the model
+// given WithLLM.
+func WithChatModels(sql, summary *llm.Client) Option {
+	return func(a *API) { a.sqlModel, a.summaryModel = sql, summary }
+}
+
+// WithChatRedactor has chat mask values with r in what it sends the
+// models.
+func WithChatRedactor(r *chat.Redactor) Option {
+	return func(a *API) { a.redact = r }
+}
+
+// ── Chat ───────────────────────────────────────────
+
+// Ask answers a question about the house with the language model. Body:
+// {"question": "..."}; a question starting "@project kitchen-remodel" or
+// "@appliance 7" is answered from that record, its related rows and its
+// documents, citing their pages.
+func (a *API) Ask(w http.ResponseWriter, r *http.Request) {
+	body, err := decodeBody[struct {
+		Question string `json:"question"`
+	}](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if strings.TrimSpace(body.Question) == "" {
+		jsonError(w, http.StatusBadRequest, "question is required")
+		return
+	}
+	scope, question, err := chat.ParseScope(body.Question)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if a.model == nil {
+		jsonError(w, http.StatusServiceUnavailable, "no language model is configured")
+		return
+	}
+	// History is a convenience; failing to keep it doesn't fail the question.
+	_ = a.store.AppendChatInput(strings.TrimSpace(body.Question))
+
+	ctx, cancel := context.WithTimeout(r.Context(), chatTimeout)
+	defer cancel()
+	assistant := &chat.Assistant{
+		Store: a.store, Model: a.model, SQLModel: a.sqlModel, SummaryModel: a.summaryModel, Redact: a.redact,
+	}
+	answer, err := assistant.Ask(ctx, scope, question)
+	switch {
+	case errors.Is(err, chat.ErrScope):
+		jsonError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		jsonError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, llm.ErrUnavailable), errors.Is(err, chat.ErrOverBudget):
+		jsonError(w, http.StatusServiceUnavailable, err.Error())
+	case err != nil:
+		jsonError(w, http.StatusBadGateway, err.Error())
+	default:
+		jsonOK(w, answer)
+	}
+}
+
+type chatStatus struct {
+	Configured bool `json:"configured"`
+	// UnavailableUntil is set while the model is left alone after failing
+	// repeatedly.
+	UnavailableUntil *time.Time `json:"unavailableUntil,omitempty"`
+	// Budget is how much of the token budget is spent, when there is one.
+	Budget *chat.BudgetStatus `json:"budget,omitempty"`
+}
+
+// ChatStatus says whether a model is configured, until when it's left
+// alone if it has been failing, and how much of its budget is spent.
+func (a *API) ChatStatus(w http.ResponseWriter, _ *http.Request) {
+	status := chatStatus{Configured: a.model != nil}
+	if a.model != nil && a.model.Breaker != nil {
+		if until := a.model.Breaker.OpenUntil(); !until.IsZero() {
+			status.UnavailableUntil = &until
+		}
+	}
+	if a.budget != nil {
+		budget, err := a.budget.Status()
+		if err != nil {
+			jsonError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		status.Budget = &budget
+	}
+	jsonOK(w, status)
+}
+
+// ChatHistory lists the questions asked before, oldest first.
+func (a *API) ChatHistory(w http.ResponseWriter, _ *http.Request) {
+	history, err := a.store.LoadChatHistory()
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if history == nil {
+		history = []string{}
+	}
+	jsonOK(w, history)
+}
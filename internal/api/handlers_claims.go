@@ -0,0 +1,205 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/exports"
+	"gorm.io/gorm"
+)
+
+// ── Warranty claims ────────────────────────────────
+
+func (a *API) ListWarrantyClaims(w http.ResponseWriter, r *http.Request) {
+	items, err := a.store.ListWarrantyClaims(0, boolQuery(r, "include_deleted"))
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, items)
+}
+
+// ListApplianceWarrantyClaims lists the claims made on one appliance.
+func (a *API) ListApplianceWarrantyClaims(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	items, err := a.store.ListWarrantyClaims(id, boolQuery(r, "include_deleted"))
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, items)
+}
+
+func (a *API) GetWarrantyClaim(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	item, err := a.store.GetWarrantyClaim(id)
+	if err != nil {
+		handleGetError(w, err, "warranty claim")
+		return
+	}
+	jsonOK(w, item)
+}
+
+// CreateWarrantyClaim opens a claim and answers with the receipt and
+// warranty documents it picked.
+func (a *API) CreateWarrantyClaim(w http.ResponseWriter, r *http.Request) {
+	body, err := decodeBody[data.WarrantyClaim](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.CreateWarrantyClaim(&body, time.Now()); err != nil {
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	created, err := a.store.GetWarrantyClaim(body.ID)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonCreated(w, created)
+}
+
+func (a *API) UpdateWarrantyClaim(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[data.WarrantyClaim](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.ID = id
+	if err := a.store.UpdateWarrantyClaim(body, time.Now()); err != nil {
+		handleUpdateError(w, err)
+		return
+	}
+	updated, err := a.store.GetWarrantyClaim(id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, updated)
+}
+
+func (a *API) DeleteWarrantyClaim(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.DeleteWarrantyClaim(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) RestoreWarrantyClaim(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RestoreWarrantyClaim(id); err != nil {
+		handleRestoreError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddClaimCorrespondence logs a letter, email or call about a claim.
+func (a *API) AddClaimCorrespondence(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[data.ClaimCorrespondence](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.ID = 0
+	body.WarrantyClaimID = id
+	if err := a.store.AddClaimCorrespondence(&body, time.Now()); err != nil {
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	jsonCreated(w, body)
+}
+
+func (a *API) RemoveClaimCorrespondence(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RemoveClaimCorrespondence(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ClaimLetter serves the claim's letter to the manufacturer as plain text,
+// prefilled from the appliance, to edit and send.
+func (a *API) ClaimLetter(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := exports.ClaimLetter(a.store, id, time.Now())
+	if err != nil {
+		handleGetError(w, err, "warranty claim")
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write(body)
+}
+
+// ClaimPacket downloads a zip of the claim's letter, receipt, warranty and
+// the appliance's service history.
+func (a *API) ClaimPacket(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	claim, err := a.store.GetWarrantyClaim(id)
+	if err != nil {
+		handleGetError(w, err, "warranty claim")
+		return
+	}
+	now := time.Now()
+	var buf bytes.Buffer
+	if err := exports.ClaimPacket(a.store, id, &buf, now); errors.Is(err, gorm.ErrRecordNotFound) {
+		jsonError(w, http.StatusNotFound, "warranty claim not found")
+		return
+	} else if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=%q", exports.ClaimPacketName(claim, now)))
+	_, _ = w.Write(buf.Bytes())
+}
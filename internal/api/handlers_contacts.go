@@ -0,0 +1,107 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// ── Contacts ───────────────────────────────────────
+
+func (a *API) ListContacts(w http.ResponseWriter, r *http.Request) {
+	items, err := a.store.ListContacts(boolQuery(r, "include_deleted"))
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, items)
+}
+
+func (a *API) ListContactRoles(w http.ResponseWriter, _ *http.Request) {
+	jsonOK(w, data.ContactRoles())
+}
+
+func (a *API) GetContact(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	item, err := a.store.GetContact(id)
+	if err != nil {
+		handleGetError(w, err, "contact")
+		return
+	}
+	jsonOK(w, item)
+}
+
+func (a *API) CreateContact(w http.ResponseWriter, r *http.Request) {
+	body, err := decodeBody[data.Contact](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.CreateContact(&body); err != nil {
+		handleExpenseError(w, err)
+		return
+	}
+	created, err := a.store.GetContact(body.ID)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonCreated(w, created)
+}
+
+func (a *API) UpdateContact(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[data.Contact](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.ID = id
+	if err := a.store.UpdateContact(body); err != nil {
+		handleExpenseError(w, err)
+		return
+	}
+	updated, err := a.store.GetContact(id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, updated)
+}
+
+func (a *API) DeleteContact(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.DeleteContact(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) RestoreContact(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RestoreContact(id); err != nil {
+		handleRestoreError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
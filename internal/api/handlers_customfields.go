@@ -0,0 +1,70 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// ── Custom Fields ──────────────────────────────────
+
+// ListCustomFields returns the custom fields of ?kind= (appliance or
+// project), or of both.
+func (a *API) ListCustomFields(w http.ResponseWriter, r *http.Request) {
+	fields, err := a.store.ListCustomFields(r.URL.Query().Get("kind"))
+	if err != nil {
+		handleCreateError(w, err)
+		return
+	}
+	jsonOK(w, fields)
+}
+
+func (a *API) CreateCustomField(w http.ResponseWriter, r *http.Request) {
+	body, err := decodeBody[data.CustomField](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.CreateCustomField(&body); err != nil {
+		handleCreateError(w, err)
+		return
+	}
+	jsonCreated(w, body)
+}
+
+// UpdateCustomField relabels and moves a custom field. Its key and type
+// can't change.
+func (a *API) UpdateCustomField(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[data.CustomField](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	field, err := a.store.UpdateCustomField(id, body.Label, body.Position)
+	if err != nil {
+		handleUpdateError(w, err)
+		return
+	}
+	jsonOK(w, field)
+}
+
+func (a *API) DeleteCustomField(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.DeleteCustomField(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -4,7 +4,15 @@
 package api
 
 import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"math"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cpcloud/webcasa/internal/data"
@@ -12,41 +20,102 @@ import (
 
 // dashboardResponse is the aggregated JSON returned by GET /api/dashboard.
 type dashboardResponse struct {
-	Incidents          []data.Incident        `json:"incidents"`
-	Maintenance        []data.MaintenanceItem `json:"maintenance"`
-	ActiveProjects     []data.Project         `json:"activeProjects"`
-	ExpiringWarranties []data.Appliance       `json:"expiringWarranties"`
-	House              *data.HouseProfile     `json:"house,omitempty"`
-	RecentServiceLogs  []data.ServiceLogEntry `json:"recentServiceLogs"`
-	YTDServiceSpend    int64                  `json:"ytdServiceSpendCents"`
-	TotalProjectSpend  int64                  `json:"totalProjectSpendCents"`
+	Incidents          []data.Incident            `json:"incidents"`
+	Maintenance        []data.MaintenanceItem     `json:"maintenance"`
+	ActiveProjects     []data.Project             `json:"activeProjects"`
+	ExpiringWarranties []data.Warranty            `json:"expiringWarranties"`
+	PestRetreatments   []data.PestTreatment       `json:"pestRetreatments"`
+	WaterAlerts        []data.WaterAlert          `json:"waterAlerts"`
+	AirFilters         []data.AirFilterSuggestion `json:"airFilters"`
+	ChecklistTasks     []data.HouseEventTask      `json:"checklistTasks"`
+	BidFollowUps       []data.BidRequest          `json:"bidFollowUps"`
+	RebatesDue         []data.Rebate              `json:"rebatesDue"`
+	StaleProjects      []data.StaleProject        `json:"staleProjects"`
+	House              *data.HouseProfile         `json:"house,omitempty"`
+	RecentServiceLogs  []data.ServiceLogEntry     `json:"recentServiceLogs"`
+	YTDServiceSpend    int64                      `json:"ytdServiceSpendCents"`
+	TotalProjectSpend  int64                      `json:"totalProjectSpendCents"`
+	Charts             dashboardCharts            `json:"charts"`
 }
 
-func (a *API) Dashboard(w http.ResponseWriter, _ *http.Request) {
-	now := time.Now()
+// dashboardCharts is what the dashboard's spending charts draw, also
+// returned by GET /api/dashboard/charts: spend in each of the last
+// dashboardChartMonths months, oldest first, project spend by project
+// type, maintenance cost by category over the same months, and each
+// utility's usage over them against the year before.
+type dashboardCharts struct {
+	Months                []data.SpendBucket  `json:"months"`
+	ProjectTypes          []data.SpendBucket  `json:"projectTypes"`
+	MaintenanceCategories []data.SpendBucket  `json:"maintenanceCategories"`
+	Utilities             []data.UtilityTrend `json:"utilities"`
+}
 
-	incidents, err := a.store.ListOpenIncidents()
+const dashboardChartMonths = 12
+
+func (a *API) Dashboard(w http.ResponseWriter, _ *http.Request) {
+	d, err := a.dashboard(time.Now())
 	if err != nil {
 		jsonError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	jsonOK(w, d)
+}
+
+// dashboard gathers what the dashboard shows as of now.
+func (a *API) dashboard(now time.Time) (dashboardResponse, error) {
+	incidents, err := a.store.ListOpenIncidents()
+	if err != nil {
+		return dashboardResponse{}, err
+	}
 
 	maintenance, err := a.store.ListMaintenanceWithSchedule()
 	if err != nil {
-		jsonError(w, http.StatusInternalServerError, err.Error())
-		return
+		return dashboardResponse{}, err
 	}
 
 	projects, err := a.store.ListActiveProjects()
 	if err != nil {
-		jsonError(w, http.StatusInternalServerError, err.Error())
-		return
+		return dashboardResponse{}, err
 	}
 
 	warranties, err := a.store.ListExpiringWarranties(now, 30*24*time.Hour, 90*24*time.Hour)
 	if err != nil {
-		jsonError(w, http.StatusInternalServerError, err.Error())
-		return
+		return dashboardResponse{}, err
+	}
+
+	pests, err := a.store.ListPestRetreatmentsDue(now, 30*24*time.Hour)
+	if err != nil {
+		return dashboardResponse{}, err
+	}
+
+	waterAlerts, err := a.store.ListWaterAlerts(a.waterLimits)
+	if err != nil {
+		return dashboardResponse{}, err
+	}
+
+	airFilters, err := a.store.ListAirFilterSuggestions(now, 14*24*time.Hour)
+	if err != nil {
+		return dashboardResponse{}, err
+	}
+
+	checklist, err := a.store.ListHouseEventTasksDue(now, 14*24*time.Hour)
+	if err != nil {
+		return dashboardResponse{}, err
+	}
+
+	bidFollowUps, err := a.store.ListBidFollowUpsDue(now, 7*24*time.Hour)
+	if err != nil {
+		return dashboardResponse{}, err
+	}
+
+	rebates, err := a.store.ListRebatesDue(now, 30*24*time.Hour)
+	if err != nil {
+		return dashboardResponse{}, err
+	}
+
+	stale, err := a.store.ListStaleProjects(now, staleProjectDays*24*time.Hour)
+	if err != nil {
+		return dashboardResponse{}, err
 	}
 
 	var house *data.HouseProfile
@@ -57,21 +126,41 @@ func (a *API) Dashboard(w http.ResponseWriter, _ *http.Request) {
 
 	recentLogs, err := a.store.ListRecentServiceLogs(5)
 	if err != nil {
-		jsonError(w, http.StatusInternalServerError, err.Error())
-		return
+		return dashboardResponse{}, err
 	}
 
 	yearStart := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
 	ytdSpend, err := a.store.YTDServiceSpendCents(yearStart)
 	if err != nil {
-		jsonError(w, http.StatusInternalServerError, err.Error())
-		return
+		return dashboardResponse{}, err
 	}
 
 	projectSpend, err := a.store.TotalProjectSpendCents()
 	if err != nil {
-		jsonError(w, http.StatusInternalServerError, err.Error())
-		return
+		return dashboardResponse{}, err
+	}
+
+	charts, err := a.dashboardCharts(now)
+	if err != nil {
+		return dashboardResponse{}, err
+	}
+
+	// Turned-off modules have nothing to say.
+	off := func(m string) bool { return slices.Contains(a.disabledModules, m) }
+	if off("incidents") {
+		incidents = nil
+	}
+	if off("appliances") {
+		warranties = nil
+	}
+	if off("pests") {
+		pests = nil
+	}
+	if off("water") {
+		waterAlerts = nil
+	}
+	if off("air_filters") {
+		airFilters = nil
 	}
 
 	// Ensure non-nil slices for clean JSON output.
@@ -85,20 +174,455 @@ func (a *API) Dashboard(w http.ResponseWriter, _ *http.Request) {
 		projects = []data.Project{}
 	}
 	if warranties == nil {
-		warranties = []data.Appliance{}
+		warranties = []data.Warranty{}
+	}
+	if pests == nil {
+		pests = []data.PestTreatment{}
+	}
+	if waterAlerts == nil {
+		waterAlerts = []data.WaterAlert{}
+	}
+	if airFilters == nil {
+		airFilters = []data.AirFilterSuggestion{}
+	}
+	if checklist == nil {
+		checklist = []data.HouseEventTask{}
+	}
+	if bidFollowUps == nil {
+		bidFollowUps = []data.BidRequest{}
+	}
+	if rebates == nil {
+		rebates = []data.Rebate{}
+	}
+	if stale == nil {
+		stale = []data.StaleProject{}
 	}
 	if recentLogs == nil {
 		recentLogs = []data.ServiceLogEntry{}
 	}
 
-	jsonOK(w, dashboardResponse{
+	return dashboardResponse{
 		Incidents:          incidents,
 		Maintenance:        maintenance,
 		ActiveProjects:     projects,
 		ExpiringWarranties: warranties,
+		PestRetreatments:   pests,
+		WaterAlerts:        waterAlerts,
+		AirFilters:         airFilters,
+		ChecklistTasks:     checklist,
+		BidFollowUps:       bidFollowUps,
+		RebatesDue:         rebates,
+		StaleProjects:      stale,
 		House:              house,
 		RecentServiceLogs:  recentLogs,
 		YTDServiceSpend:    ytdSpend,
 		TotalProjectSpend:  projectSpend,
-	})
+		Charts:             charts,
+	}, nil
+}
+
+func (a *API) DashboardCharts(w http.ResponseWriter, _ *http.Request) {
+	c, err := a.dashboardCharts(time.Now())
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, c)
+}
+
+// dashboardCharts gathers the spending charts as of now.
+func (a *API) dashboardCharts(now time.Time) (dashboardCharts, error) {
+	months, err := a.store.SpendByMonth(now, dashboardChartMonths)
+	if err != nil {
+		return dashboardCharts{}, err
+	}
+	types, err := a.store.SpendByProjectType()
+	if err != nil {
+		return dashboardCharts{}, err
+	}
+	since := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, 1-dashboardChartMonths, 0)
+	categories, err := a.store.MaintenanceCostByCategory(since)
+	if err != nil {
+		return dashboardCharts{}, err
+	}
+	if types == nil {
+		types = []data.SpendBucket{}
+	}
+	utilities, err := a.store.UtilityTrends(now, dashboardChartMonths)
+	if err != nil {
+		return dashboardCharts{}, err
+	}
+	if categories == nil {
+		categories = []data.SpendBucket{}
+	}
+	if utilities == nil {
+		utilities = []data.UtilityTrend{}
+	}
+	return dashboardCharts{
+		Months: months, ProjectTypes: types, MaintenanceCategories: categories, Utilities: utilities,
+	}, nil
+}
+
+// ── Dashboard page ─────────────────────────────────
+
+//go:embed dashboard.html
+var dashboardHTML string
+
+var dashboardTemplate = template.Must(template.New("dashboard").Parse(dashboardHTML))
+
+// The page reloads itself every defaultDashboardRefresh unless ?refresh=
+// says otherwise, in seconds; 0 turns reloading off.
+const (
+	defaultDashboardRefresh = 5 * time.Minute
+	minDashboardRefresh     = 30 * time.Second
+	dashboardUpcomingDays   = 30
+)
+
+type dashboardPage struct {
+	Title    string
+	Updated  time.Time
+	Refresh  int
+	Stats    []dashboardStat
+	Sections []dashboardSection
+	Charts   []dashboardChart
+}
+
+type dashboardStat struct {
+	Label, Value string
+}
+
+type dashboardSection struct {
+	Title, Empty string
+	Rows         []dashboardRow
+}
+
+type dashboardRow struct {
+	Label, Detail string
+	Alert         bool
+	due           time.Time
+}
+
+// dashboardChart is a chart drawn in text: a sparkline from the first
+// month to the last, or one bar per row.
+type dashboardChart struct {
+	Title, Empty     string
+	Spark            string
+	First, Last, Sum string
+	Bars             []dashboardBar
+}
+
+type dashboardBar struct {
+	Label, Bar, Value string
+}
+
+// dashboardBarWidth is how many cells the longest bar fills.
+const dashboardBarWidth = 16
+
+// DashboardPage renders the dashboard as a plain HTML page, with no
+// scripts, for a kiosk browser or an e-ink display.
+func (a *API) DashboardPage(w http.ResponseWriter, r *http.Request) {
+	refresh := defaultDashboardRefresh
+	if raw := r.URL.Query().Get("refresh"); raw != "" {
+		secs, err := strconv.Atoi(raw)
+		if err != nil || secs < 0 {
+			http.Error(w, "refresh must be a number of seconds", http.StatusBadRequest)
+			return
+		}
+		refresh = max(time.Duration(secs)*time.Second, minDashboardRefresh)
+		if secs == 0 {
+			refresh = 0
+		}
+	}
+
+	now := time.Now()
+	d, err := a.dashboard(now)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	page := buildDashboardPage(d, now)
+	page.Refresh = int(refresh.Seconds())
+
+	var buf bytes.Buffer
+	if err := dashboardTemplate.Execute(&buf, page); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = buf.WriteTo(w)
+}
+
+// buildDashboardPage sorts the aggregates into the page's sections, with
+// the same overdue and upcoming rules as the web app's dashboard.
+func buildDashboardPage(d dashboardResponse, now time.Time) dashboardPage {
+	page := dashboardPage{Title: "Home", Updated: now}
+	if d.House != nil && d.House.Nickname != "" {
+		page.Title = d.House.Nickname
+	}
+
+	var overdue, upcoming []dashboardRow
+	for _, m := range d.Maintenance {
+		next := m.NextDueAt
+		if next == nil {
+			continue
+		}
+		days := daysBetween(now, *next)
+		row := dashboardRow{Label: m.Name, Detail: relativeDays(days), due: *next}
+		switch {
+		case days < 0:
+			row.Alert = true
+			overdue = append(overdue, row)
+		case days <= dashboardUpcomingDays:
+			upcoming = append(upcoming, row)
+		}
+	}
+
+	incidents := make([]dashboardRow, 0, len(d.Incidents))
+	for _, i := range d.Incidents {
+		incidents = append(incidents, dashboardRow{
+			Label: i.Title, Detail: i.Severity, Alert: i.Severity == data.IncidentSeverityUrgent,
+		})
+	}
+
+	var renewals []dashboardRow
+	for _, w := range d.ExpiringWarranties {
+		days := daysBetween(now, w.ExpiresOn)
+		renewals = append(renewals, dashboardRow{
+			Label: w.Title(), Detail: relativeDays(days), Alert: days < 0, due: w.ExpiresOn,
+		})
+	}
+	for _, p := range d.PestRetreatments {
+		next := data.ComputeNextDue(&p.TreatedAt, p.RetreatIntervalMonths)
+		if next == nil {
+			continue
+		}
+		days := daysBetween(now, *next)
+		renewals = append(renewals, dashboardRow{
+			Label: p.TargetPest + " re-treatment", Detail: relativeDays(days), Alert: days < 0, due: *next,
+		})
+	}
+	for _, f := range d.AirFilters {
+		days := daysBetween(now, f.DueAt)
+		renewals = append(renewals, dashboardRow{
+			Label: f.Title, Detail: relativeDays(days), Alert: days < 0, due: f.DueAt,
+		})
+	}
+	for _, b := range d.BidFollowUps {
+		days := daysBetween(now, *b.FollowUpOn)
+		upcoming = append(upcoming, dashboardRow{
+			Label:  "Follow up with " + b.Vendor.Name + " on " + b.Project.Title,
+			Detail: relativeDays(days), Alert: days < 0, due: *b.FollowUpOn,
+		})
+	}
+	for _, rb := range d.RebatesDue {
+		due := *rb.DueOn()
+		days := daysBetween(now, due)
+		upcoming = append(upcoming, dashboardRow{Label: rb.NextStep(), Detail: relativeDays(days), Alert: days < 0, due: due})
+	}
+	for _, rows := range [][]dashboardRow{overdue, upcoming, renewals} {
+		slices.SortStableFunc(rows, func(a, b dashboardRow) int { return a.due.Compare(b.due) })
+	}
+
+	checklist := make([]dashboardRow, 0, len(d.ChecklistTasks))
+	for _, t := range d.ChecklistTasks {
+		days := daysBetween(now, *t.DueAt)
+		checklist = append(checklist, dashboardRow{Label: t.Title, Detail: relativeDays(days), Alert: days < 0})
+	}
+
+	water := make([]dashboardRow, 0, len(d.WaterAlerts))
+	for _, alert := range d.WaterAlerts {
+		metrics := make([]string, 0, len(alert.Exceedances))
+		for _, e := range alert.Exceedances {
+			metrics = append(metrics, e.Metric)
+		}
+		water = append(water, dashboardRow{
+			Label:  alert.Test.Source + " " + alert.Test.TestedAt.Format("Jan 2"),
+			Detail: strings.Join(metrics, ", "),
+			Alert:  true,
+		})
+	}
+
+	projects := make([]dashboardRow, 0, len(d.ActiveProjects))
+	for _, p := range d.ActiveProjects {
+		projects = append(projects, dashboardRow{
+			Label: p.Title, Detail: p.Status, Alert: p.Status == data.ProjectStatusDelayed,
+		})
+	}
+
+	stale := make([]dashboardRow, 0, len(d.StaleProjects))
+	for _, sp := range d.StaleProjects {
+		stale = append(stale, dashboardRow{
+			Label: sp.Project.Title, Detail: fmt.Sprintf("%s, idle %d days", sp.Project.Status, sp.IdleDays),
+		})
+	}
+
+	page.Stats = []dashboardStat{
+		{"Overdue", strconv.Itoa(len(overdue))},
+		{"Due in 30 days", strconv.Itoa(len(upcoming))},
+		{"Open incidents", strconv.Itoa(len(d.Incidents))},
+		{"Service spend this year", data.FormatCents(d.YTDServiceSpend)},
+		{"Project spend", data.FormatCents(d.TotalProjectSpend)},
+	}
+	page.Sections = []dashboardSection{
+		{Title: "Overdue maintenance", Empty: "Nothing overdue.", Rows: overdue},
+		{Title: "Coming up", Empty: "Nothing due in the next 30 days.", Rows: upcoming},
+		{Title: "Incidents", Empty: "No open incidents.", Rows: incidents},
+		{Title: "Renewals", Empty: "No warranties, filters or treatments due.", Rows: renewals},
+	}
+	if len(checklist) > 0 {
+		page.Sections = append(page.Sections,
+			dashboardSection{Title: "Moving checklist", Rows: checklist})
+	}
+	if len(water) > 0 {
+		page.Sections = append(page.Sections,
+			dashboardSection{Title: "Water quality", Rows: water})
+	}
+	page.Sections = append(page.Sections,
+		dashboardSection{Title: "Active projects", Empty: "No projects underway.", Rows: projects})
+	if len(stale) > 0 {
+		page.Sections = append(page.Sections,
+			dashboardSection{Title: "Stale projects", Rows: stale})
+	}
+
+	page.Charts = []dashboardChart{
+		monthChart(d.Charts.Months),
+		barChart("Projects by type", "No project costs yet.", d.Charts.ProjectTypes),
+		barChart("Maintenance by category", "No maintenance costs in the last year.", d.Charts.MaintenanceCategories),
+	}
+	for _, u := range d.Charts.Utilities {
+		page.Charts = append(page.Charts, utilityChart(u))
+	}
+	return page
+}
+
+// monthChart draws monthly spend as a sparkline.
+func monthChart(months []data.SpendBucket) dashboardChart {
+	c := dashboardChart{Title: "Spending by month", Empty: "Nothing spent lately."}
+	values := make([]int64, len(months))
+	var sum int64
+	for i, m := range months {
+		values[i] = m.AmountCents
+		sum += m.AmountCents
+	}
+	if sum == 0 {
+		return c
+	}
+	c.Spark = sparkline(values)
+	c.First = monthLabel(months[0].Label)
+	c.Last = monthLabel(months[len(months)-1].Label)
+	c.Sum = data.FormatCents(sum)
+	return c
+}
+
+// utilityChart draws a utility's monthly usage as a sparkline, with the
+// total against the same months a year before.
+func utilityChart(u data.UtilityTrend) dashboardChart {
+	c := dashboardChart{Title: strings.ToUpper(u.Type[:1]) + u.Type[1:] + " use", Empty: "No usage on the bills lately."}
+	values := make([]int64, len(u.Months))
+	var sum, lastYear float64
+	for i, m := range u.Months {
+		if m.Usage != nil {
+			values[i] = int64(math.Round(*m.Usage))
+			sum += *m.Usage
+		}
+		if m.LastYear != nil {
+			lastYear += *m.LastYear
+		}
+	}
+	if sum == 0 {
+		return c
+	}
+	c.Spark = sparkline(values)
+	c.First = monthLabel(u.Months[0].Label)
+	c.Last = monthLabel(u.Months[len(u.Months)-1].Label)
+	c.Sum = fmt.Sprintf("%.0f %s", sum, u.Unit)
+	if lastYear > 0 {
+		c.Sum += fmt.Sprintf(" (%+.0f%% on last year)", (sum/lastYear-1)*100)
+	}
+	return c
+}
+
+// barChart draws one bar per bucket, scaled to the largest.
+func barChart(title, empty string, buckets []data.SpendBucket) dashboardChart {
+	c := dashboardChart{Title: title, Empty: empty}
+	var top int64
+	for _, b := range buckets {
+		top = max(top, b.AmountCents)
+	}
+	for _, b := range buckets {
+		c.Bars = append(c.Bars, dashboardBar{
+			Label: b.Label,
+			Bar:   blockBar(b.AmountCents, top, dashboardBarWidth),
+			Value: data.FormatCents(b.AmountCents),
+		})
+	}
+	return c
+}
+
+// sparkline draws each value as one of eight block heights, scaled to the
+// largest. Zero is the lowest block.
+func sparkline(values []int64) string {
+	const blocks = "▁▂▃▄▅▆▇█"
+	levels := []rune(blocks)
+	var top int64
+	for _, v := range values {
+		top = max(top, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if top > 0 && v > 0 {
+			i = int((v*int64(len(levels)-1) + top - 1) / top)
+		}
+		b.WriteRune(levels[i])
+	}
+	return b.String()
+}
+
+// blockBar draws v as a bar of full blocks, ending in a partial block to
+// the eighth, width cells long at top. Anything above zero shows.
+func blockBar(v, top int64, width int) string {
+	const partials = " ▏▎▍▌▋▊▉"
+	if top <= 0 || v <= 0 {
+		return ""
+	}
+	eighths := max(int(v*int64(width)*8/top), 1)
+	bar := strings.Repeat("█", eighths/8)
+	if rest := eighths % 8; rest > 0 {
+		bar += string([]rune(partials)[rest])
+	}
+	return bar
+}
+
+// monthLabel turns "2026-03" into "Mar 2026".
+func monthLabel(period string) string {
+	t, err := time.Parse("2006-01", period)
+	if err != nil {
+		return period
+	}
+	return t.Format("Jan 2006")
+}
+
+// daysBetween counts calendar days from now to t, negative when t has
+// passed.
+func daysBetween(now, t time.Time) int {
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	t = t.In(now.Location())
+	to := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from).Hours() / 24)
+}
+
+func relativeDays(days int) string {
+	switch {
+	case days == 0:
+		return "today"
+	case days == 1:
+		return "tomorrow"
+	case days == -1:
+		return "yesterday"
+	case days > 1:
+		return fmt.Sprintf("in %d days", days)
+	}
+	return fmt.Sprintf("%d days ago", -days)
 }
@@ -0,0 +1,172 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/netscan"
+)
+
+// ── Smart Devices ──────────────────────────────────
+
+func (a *API) ListSmartDevices(w http.ResponseWriter, r *http.Request) {
+	items, err := a.store.ListSmartDevices(boolQuery(r, "include_deleted"))
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, items)
+}
+
+func (a *API) GetSmartDevice(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	item, err := a.store.GetSmartDevice(id)
+	if err != nil {
+		handleGetError(w, err, "device")
+		return
+	}
+	jsonOK(w, item)
+}
+
+func (a *API) CreateSmartDevice(w http.ResponseWriter, r *http.Request) {
+	body, err := decodeBody[data.SmartDevice](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.CreateSmartDevice(&body); err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	created, err := a.store.GetSmartDevice(body.ID)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonCreated(w, created)
+}
+
+func (a *API) UpdateSmartDevice(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[data.SmartDevice](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.ID = id
+	if err := a.store.UpdateSmartDevice(body); err != nil {
+		handleUpdateError(w, err)
+		return
+	}
+	updated, err := a.store.GetSmartDevice(id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, updated)
+}
+
+func (a *API) DeleteSmartDevice(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.DeleteSmartDevice(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) RestoreSmartDevice(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RestoreSmartDevice(id); err != nil {
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// batteryChangeRequest is the optional body for POST .../battery. An absent
+// or zero ChangedAt means "now".
+type batteryChangeRequest struct {
+	ChangedAt time.Time `json:"changedAt"`
+}
+
+func (a *API) RecordBatteryChange(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var body batteryChangeRequest
+	if r.ContentLength != 0 {
+		body, err = decodeBody[batteryChangeRequest](r)
+		if err != nil {
+			jsonError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if body.ChangedAt.IsZero() {
+		body.ChangedAt = time.Now()
+	}
+	if err := a.store.RecordBatteryChange(id, body.ChangedAt); err != nil {
+		handleGetError(w, err, "device")
+		return
+	}
+	updated, err := a.store.GetSmartDevice(id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, updated)
+}
+
+// discoveredDevice is a network neighbor annotated with whether it is
+// already in the device inventory.
+type discoveredDevice struct {
+	netscan.Neighbor
+	Known bool `json:"known"`
+}
+
+// DiscoverSmartDevices lists hosts from the server's neighbor table to help
+// populate the inventory. Hosts already recorded (by MAC) are flagged.
+func (a *API) DiscoverSmartDevices(w http.ResponseWriter, _ *http.Request) {
+	neighbors, err := netscan.Neighbors()
+	if errors.Is(err, netscan.ErrUnsupported) {
+		jsonError(w, http.StatusNotImplemented, err.Error())
+		return
+	}
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	known, err := a.store.KnownMACAddresses()
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	out := make([]discoveredDevice, len(neighbors))
+	for i, n := range neighbors {
+		out[i] = discoveredDevice{Neighbor: n, Known: known[n.MACAddress]}
+	}
+	jsonOK(w, out)
+}
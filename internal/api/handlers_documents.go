@@ -4,20 +4,55 @@
 package api
 
 import (
+	"bytes"
 	"crypto/sha256"
+	"errors"
 	"fmt"
+	"image"
+	_ "image/jpeg" // decoders for fingerprintPhoto
+	_ "image/png"
 	"io"
 	"net/http"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/imagedup"
+	"github.com/cpcloud/webcasa/internal/photo"
+	"gorm.io/gorm"
 )
 
 // ── Documents ──────────────────────────────────────
 
+// WithImageCompression recompresses uploaded photos with opts (see
+// config.Documents.ImageOptions). nil stores uploads as they are.
+func WithImageCompression(opts *photo.Options) Option {
+	return func(a *API) { a.images = opts }
+}
+
+// nextCursorHeader carries the cursor for the next page of a paged list.
+const nextCursorHeader = "X-Next-Cursor"
+
+// ListDocuments returns every document, or one page of them when limit or
+// after is given.
 func (a *API) ListDocuments(w http.ResponseWriter, r *http.Request) {
+	if q, ok, err := documentPageQuery(r); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	} else if ok {
+		if r.URL.Query().Has("tag") {
+			jsonError(w, http.StatusBadRequest, "documents can't be paged by tag")
+			return
+		}
+		a.listDocumentPage(w, r, q)
+		return
+	}
 	items, err := a.store.ListDocuments(boolQuery(r, "include_deleted"))
+	if err == nil {
+		items, err = withTags(a, r, data.DeletionEntityDocument, items, documentTags)
+	}
 	if err != nil {
 		jsonError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -25,6 +60,50 @@ func (a *API) ListDocuments(w http.ResponseWriter, r *http.Request) {
 	jsonOK(w, items)
 }
 
+// documentPageQuery reads the keyset paging parameters: limit (default 100)
+// and after, the cursor from the previous page's X-Next-Cursor header. ok
+// is false when the request doesn't ask for paging.
+func documentPageQuery(r *http.Request) (q data.DocumentPageQuery, ok bool, err error) {
+	rawLimit, rawAfter := r.URL.Query().Get("limit"), r.URL.Query().Get("after")
+	if rawLimit == "" && rawAfter == "" {
+		return q, false, nil
+	}
+	q.IncludeDeleted = boolQuery(r, "include_deleted")
+	q.Limit = 100
+	if rawLimit != "" {
+		n, err := strconv.Atoi(rawLimit)
+		if err != nil || n <= 0 || n > data.MaxDocumentPage {
+			return q, false, fmt.Errorf("limit must be between 1 and %d", data.MaxDocumentPage)
+		}
+		q.Limit = n
+	}
+	if rawAfter != "" {
+		c, err := data.ParseDocumentCursor(rawAfter)
+		if err != nil {
+			return q, false, err
+		}
+		q.After = &c
+	}
+	return q, true, nil
+}
+
+func (a *API) listDocumentPage(w http.ResponseWriter, r *http.Request, q data.DocumentPageQuery) {
+	items, next, err := a.store.ListDocumentPage(q)
+	if err == nil {
+		items, err = withTags(a, r, data.DeletionEntityDocument, items, documentTags)
+	}
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if next != nil {
+		w.Header().Set(nextCursorHeader, next.String())
+	}
+	jsonOK(w, items)
+}
+
+func documentTags(d *data.Document) (uint, *[]string) { return d.ID, &d.Tags }
+
 func (a *API) ListDocumentsByEntity(w http.ResponseWriter, r *http.Request) {
 	entityKind := r.PathValue("kind")
 	idStr := r.PathValue("eid")
@@ -33,6 +112,14 @@ func (a *API) ListDocumentsByEntity(w http.ResponseWriter, r *http.Request) {
 		jsonError(w, http.StatusBadRequest, fmt.Sprintf("invalid entity id %q", idStr))
 		return
 	}
+	if q, ok, err := documentPageQuery(r); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	} else if ok {
+		q.EntityKind, q.EntityID = entityKind, uint(eid)
+		a.listDocumentPage(w, r, q)
+		return
+	}
 	items, err := a.store.ListDocumentsByEntity(entityKind, uint(eid), boolQuery(r, "include_deleted"))
 	if err != nil {
 		jsonError(w, http.StatusInternalServerError, err.Error())
@@ -57,25 +144,82 @@ func (a *API) GetDocument(w http.ResponseWriter, r *http.Request) {
 
 // DownloadDocument streams the document BLOB with appropriate content headers.
 func (a *API) DownloadDocument(w http.ResponseWriter, r *http.Request) {
+	a.serveDocument(w, r, "attachment")
+}
+
+// DocumentContent serves the document for viewing in the browser, e.g. a
+// PDF preview.
+func (a *API) DocumentContent(w http.ResponseWriter, r *http.Request) {
+	a.serveDocument(w, r, "inline")
+}
+
+// serveDocument streams the document BLOB without holding it in memory. A
+// Range request gets just the bytes asked for, so a viewer can jump around
+// a large PDF or video, and the ETag is the content's checksum, so a copy
+// the client already has isn't sent again.
+func (a *API) serveDocument(w http.ResponseWriter, r *http.Request, disposition string) {
 	id, err := parseID(r)
 	if err != nil {
 		jsonError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	doc, err := a.store.GetDocument(id)
+	doc, err := a.store.GetDocumentMetadata(id)
 	if err != nil {
 		handleGetError(w, err, "document")
 		return
 	}
-	if len(doc.Data) == 0 {
-		jsonError(w, http.StatusNotFound, "document has no content")
+	content, err := a.store.OpenDocument(id)
+	if errors.Is(err, data.ErrNoContent) {
+		jsonError(w, http.StatusNotFound, err.Error())
+		return
+	} else if err != nil {
+		handleGetError(w, err, "document")
 		return
 	}
+	defer content.Close()
+	if r.Header.Get("Range") == "" {
+		// Count each open once, not every piece a viewer asks for.
+		_ = a.store.TouchDocument(id, time.Now())
+	}
 	w.Header().Set("Content-Type", doc.MIMEType)
-	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, doc.FileName))
-	w.Header().Set("Content-Length", strconv.FormatInt(doc.SizeBytes, 10))
-	w.WriteHeader(http.StatusOK)
-	w.Write(doc.Data) //nolint:errcheck
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`%s; filename="%s"`, disposition, doc.FileName))
+	if doc.ChecksumSHA256 != "" {
+		w.Header().Set("ETag", `"`+doc.ChecksumSHA256+`"`)
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	http.ServeContent(w, r, doc.FileName, time.Time{}, content)
+}
+
+// DocumentThumbnail serves a small JPEG of a photo document, for showing
+// it in lists. Other documents have none.
+func (a *API) DocumentThumbnail(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	doc, err := a.store.GetDocumentMetadata(id)
+	if err != nil {
+		handleGetError(w, err, "document")
+		return
+	}
+	etag := `"thumb-` + doc.ChecksumSHA256 + `"`
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	thumb, err := a.store.DocumentThumbnail(id)
+	if errors.Is(err, data.ErrNoThumbnail) {
+		jsonError(w, http.StatusNotFound, err.Error())
+		return
+	} else if err != nil {
+		handleGetError(w, err, "document")
+		return
+	}
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write(thumb)
 }
 
 // UploadDocument handles multipart form uploads. Fields:
@@ -85,11 +229,18 @@ func (a *API) DownloadDocument(w http.ResponseWriter, r *http.Request) {
 //	entityKind - entity type to link to (optional)
 //	entityId   - entity ID to link to (optional)
 //	notes      - optional notes
+//
+// Files other than photos are streamed into the database: the form spills
+// them to a temporary file rather than memory, and they're copied from
+// there in chunks.
 func (a *API) UploadDocument(w http.ResponseWriter, r *http.Request) {
-	const maxUpload = 50 << 20 // 50 MiB
+	const (
+		maxUpload    = 50 << 20 // 50 MiB
+		uploadMemory = 1 << 20  // larger files wait on disk
+	)
 	r.Body = http.MaxBytesReader(w, r.Body, maxUpload+1024)
 
-	if err := r.ParseMultipartForm(maxUpload); err != nil {
+	if err := r.ParseMultipartForm(uploadMemory); err != nil {
 		jsonError(w, http.StatusBadRequest, fmt.Sprintf("parse form: %v -- max upload size is 50 MiB", err))
 		return
 	}
@@ -101,11 +252,14 @@ func (a *API) UploadDocument(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
-	fileData, err := io.ReadAll(file)
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
 	if err != nil {
 		jsonError(w, http.StatusInternalServerError, fmt.Sprintf("read uploaded file: %v", err))
 		return
 	}
+	sniff := make([]byte, 512)
+	n, _ := file.ReadAt(sniff, 0)
 
 	title := r.FormValue("title")
 	if title == "" {
@@ -114,19 +268,16 @@ func (a *API) UploadDocument(w http.ResponseWriter, r *http.Request) {
 
 	mime := header.Header.Get("Content-Type")
 	if mime == "" || mime == "application/octet-stream" {
-		mime = detectMIME(fileData, header.Filename)
+		mime = detectMIME(sniff[:n], header.Filename)
 	}
 
-	checksum := fmt.Sprintf("%x", sha256.Sum256(fileData))
-
 	doc := data.Document{
 		Title:          title,
 		FileName:       filepath.Base(header.Filename),
 		EntityKind:     r.FormValue("entityKind"),
 		MIMEType:       mime,
-		SizeBytes:      int64(len(fileData)),
-		ChecksumSHA256: checksum,
-		Data:           fileData,
+		SizeBytes:      size,
+		ChecksumSHA256: fmt.Sprintf("%x", hash.Sum(nil)),
 		Notes:          r.FormValue("notes"),
 	}
 
@@ -139,7 +290,28 @@ func (a *API) UploadDocument(w http.ResponseWriter, r *http.Request) {
 		doc.EntityID = uint(eid)
 	}
 
-	if err := a.store.CreateDocument(&doc); err != nil {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		jsonError(w, http.StatusInternalServerError, fmt.Sprintf("read uploaded file: %v", err))
+		return
+	}
+	if strings.HasPrefix(mime, "image/") {
+		// Photos are recompressed and fingerprinted, which needs them whole.
+		if doc.Data, err = io.ReadAll(file); err != nil {
+			jsonError(w, http.StatusInternalServerError, fmt.Sprintf("read uploaded file: %v", err))
+			return
+		}
+		if a.images != nil && r.FormValue("keepOriginal") != "true" {
+			a.shrinkPhoto(&doc)
+		}
+		fingerprintPhoto(&doc)
+		if err = a.store.CreateDocument(&doc); err == nil {
+			// The thumbnail is made again when it's asked for if this fails.
+			_, _ = a.store.DocumentThumbnail(doc.ID)
+		}
+	} else {
+		err = a.store.CreateDocumentFrom(&doc, file)
+	}
+	if err != nil {
 		jsonError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -149,6 +321,110 @@ func (a *API) UploadDocument(w http.ResponseWriter, r *http.Request) {
 	jsonCreated(w, doc)
 }
 
+// shrinkPhoto swaps an uploaded photo for a recompressed copy when that
+// comes out smaller, keeping the original's checksum and size on record.
+// Images that can't be decoded are stored as they are.
+func (a *API) shrinkPhoto(doc *data.Document) {
+	if !strings.HasPrefix(doc.MIMEType, "image/") {
+		return
+	}
+	out, ok, err := photo.Shrink(doc.Data, *a.images)
+	if err != nil || !ok {
+		return
+	}
+	doc.OriginalChecksumSHA256, doc.OriginalSizeBytes = doc.ChecksumSHA256, doc.SizeBytes
+	doc.Data = out
+	doc.SizeBytes = int64(len(out))
+	doc.ChecksumSHA256 = fmt.Sprintf("%x", sha256.Sum256(out))
+}
+
+// fingerprintPhoto records the perceptual hash and sharpness of a JPEG or
+// PNG upload, so bursts of near-identical shots can be found later.
+func fingerprintPhoto(doc *data.Document) {
+	if doc.MIMEType != "image/jpeg" && doc.MIMEType != "image/png" {
+		return
+	}
+	img, _, err := image.Decode(bytes.NewReader(doc.Data))
+	if err != nil {
+		return
+	}
+	doc.ImageHash = imagedup.HashImage(img).String()
+	doc.Sharpness = imagedup.Sharpness(img)
+}
+
+// ListPhotoBursts returns the runs of near-identical photos attached to an
+// entity, each with the sharpest shot suggested as the one to keep.
+func (a *API) ListPhotoBursts(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("eid")
+	eid, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, fmt.Sprintf("invalid entity id %q", idStr))
+		return
+	}
+	bursts, err := a.store.PhotoBursts(r.PathValue("kind"), uint(eid))
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if bursts == nil {
+		bursts = []data.PhotoBurst{}
+	}
+	jsonOK(w, bursts)
+}
+
+// ListDuplicateDocuments lists the files attached as more than one
+// document.
+func (a *API) ListDuplicateDocuments(w http.ResponseWriter, r *http.Request) {
+	sets, err := a.store.DuplicateDocuments()
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, sets)
+}
+
+type mergeDuplicatesResponse struct {
+	Dropped    int   `json:"dropped"`
+	FreedBytes int64 `json:"freedBytes"`
+}
+
+// MergeDuplicateDocuments stores each duplicated file once.
+func (a *API) MergeDuplicateDocuments(w http.ResponseWriter, r *http.Request) {
+	dropped, freed, err := a.store.MergeDuplicateDocuments()
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, mergeDuplicatesResponse{Dropped: dropped, FreedBytes: freed})
+}
+
+type keepBestShotRequest struct {
+	Discard []uint `json:"discard"`
+}
+
+// KeepBestShot keeps the photo in the path and deletes the other shots of
+// its burst listed in the body.
+func (a *API) KeepBestShot(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[keepBestShotRequest](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.KeepBestShot(id, body.Discard); errors.Is(err, gorm.ErrRecordNotFound) {
+		jsonError(w, http.StatusNotFound, "document not found")
+		return
+	} else if err != nil {
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (a *API) UpdateDocument(w http.ResponseWriter, r *http.Request) {
 	id, err := parseID(r)
 	if err != nil {
@@ -164,7 +440,7 @@ func (a *API) UpdateDocument(w http.ResponseWriter, r *http.Request) {
 	}
 	body.ID = id
 	if err := a.store.UpdateDocument(body); err != nil {
-		jsonError(w, http.StatusInternalServerError, err.Error())
+		handleUpdateError(w, err)
 		return
 	}
 
@@ -197,7 +473,7 @@ func (a *API) RestoreDocument(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := a.store.RestoreDocument(id); err != nil {
-		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		handleRestoreError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -0,0 +1,190 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"gorm.io/gorm"
+)
+
+// ── Expenses ───────────────────────────────────────
+
+func (a *API) ListExpenses(w http.ResponseWriter, r *http.Request) {
+	items, err := a.store.ListExpenses(boolQuery(r, "include_deleted"))
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, items)
+}
+
+func (a *API) GetExpense(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	item, err := a.store.GetExpense(id)
+	if err != nil {
+		handleGetError(w, err, "expense")
+		return
+	}
+	jsonOK(w, item)
+}
+
+// ListExpenseCategories lists the categories in use, for suggestions.
+func (a *API) ListExpenseCategories(w http.ResponseWriter, _ *http.Request) {
+	cats, err := a.store.ExpenseCategories()
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if cats == nil {
+		cats = []string{}
+	}
+	jsonOK(w, cats)
+}
+
+func (a *API) CreateExpense(w http.ResponseWriter, r *http.Request) {
+	body, err := decodeBody[data.Expense](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.CreateExpense(&body); err != nil {
+		handleExpenseError(w, err)
+		return
+	}
+	created, err := a.store.GetExpense(body.ID)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonCreated(w, created)
+}
+
+func (a *API) UpdateExpense(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[data.Expense](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.ID = id
+	if err := a.store.UpdateExpense(body); err != nil {
+		handleExpenseError(w, err)
+		return
+	}
+	updated, err := a.store.GetExpense(id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, updated)
+}
+
+func (a *API) DeleteExpense(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.DeleteExpense(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) RestoreExpense(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RestoreExpense(id); err != nil {
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleExpenseError answers 404 or 409 as for any update, and 422 for an
+// expense, warranty, inventory item, rebate, contact or utility bill that
+// is missing something or links to a record that doesn't exist.
+func handleExpenseError(w http.ResponseWriter, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, data.ErrVersionConflict) {
+		handleUpdateError(w, err)
+		return
+	}
+	if strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
+		jsonError(w, http.StatusUnprocessableEntity, "refers to a record that doesn't exist")
+		return
+	}
+	jsonError(w, http.StatusUnprocessableEntity, err.Error())
+}
+
+// ── Spending rollups ───────────────────────────────
+
+type spendingResponse struct {
+	Year int `json:"year"`
+	// Months totals the year's spending by month and category, and Years
+	// every year's by category.
+	Months []data.SpendTotal `json:"months"`
+	Years  []data.SpendTotal `json:"years"`
+	// Ownership is what each appliance cost over the year.
+	Ownership []data.OwnershipCost `json:"ownership"`
+}
+
+// Spending rolls up every recorded cost, expenses and the costs kept on
+// other records alike, for ?year= (default: this year) and for every
+// year.
+func (a *API) Spending(w http.ResponseWriter, r *http.Request) {
+	year := time.Now().Year()
+	if raw := r.URL.Query().Get("year"); raw != "" {
+		y, err := strconv.Atoi(raw)
+		if err != nil || y < 1 {
+			jsonError(w, http.StatusBadRequest, "year must be a year, like 2026")
+			return
+		}
+		year = y
+	}
+	since := time.Date(year, 1, 1, 0, 0, 0, 0, time.Local)
+	until := since.AddDate(1, 0, 0)
+	all, err := a.store.ListSpending(time.Time{}, time.Now().AddDate(100, 0, 0))
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var inYear []data.SpendEntry
+	for _, e := range all {
+		if !e.Date.Before(since) && e.Date.Before(until) {
+			inYear = append(inYear, e)
+		}
+	}
+	ownership, err := a.store.CostOfOwnership(since, until)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if ownership == nil {
+		ownership = []data.OwnershipCost{}
+	}
+	jsonOK(w, spendingResponse{
+		Year:      year,
+		Months:    data.RollUpSpending(inYear, true),
+		Years:     data.RollUpSpending(all, false),
+		Ownership: ownership,
+	})
+}
@@ -0,0 +1,189 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"gorm.io/gorm"
+)
+
+// ── Change orders and invoices ─────────────────────
+
+// ProjectFinancials sums up a project's budget, change orders, invoices
+// and retainage.
+func (a *API) ProjectFinancials(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	f, err := a.store.ProjectFinancials(id)
+	if err != nil {
+		handleGetError(w, err, "project")
+		return
+	}
+	jsonOK(w, f)
+}
+
+// BudgetRevisions lists the changes to a project's budget and actual cost,
+// oldest first.
+func (a *API) BudgetRevisions(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	revs, err := a.store.BudgetRevisions(id)
+	if err != nil {
+		handleGetError(w, err, "project")
+		return
+	}
+	jsonOK(w, revs)
+}
+
+func (a *API) ListChangeOrders(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	items, err := a.store.ListChangeOrders(id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, items)
+}
+
+func (a *API) CreateChangeOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[data.ChangeOrder](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.ProjectID = id
+	if err := a.store.CreateChangeOrder(&body); err != nil {
+		handleFinancialsError(w, err)
+		return
+	}
+	jsonCreated(w, body)
+}
+
+func (a *API) UpdateChangeOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[data.ChangeOrder](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.ID = id
+	if err := a.store.UpdateChangeOrder(body); err != nil {
+		handleFinancialsError(w, err)
+		return
+	}
+	updated, _ := a.store.GetChangeOrder(id)
+	jsonOK(w, updated)
+}
+
+func (a *API) RemoveChangeOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RemoveChangeOrder(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) ListInvoices(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	items, err := a.store.ListInvoices(id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, items)
+}
+
+func (a *API) CreateInvoice(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[data.Invoice](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.ProjectID = id
+	if err := a.store.CreateInvoice(&body); err != nil {
+		handleFinancialsError(w, err)
+		return
+	}
+	jsonCreated(w, body)
+}
+
+func (a *API) UpdateInvoice(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[data.Invoice](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.ID = id
+	if err := a.store.UpdateInvoice(body); err != nil {
+		handleFinancialsError(w, err)
+		return
+	}
+	updated, _ := a.store.GetInvoice(id)
+	jsonOK(w, updated)
+}
+
+func (a *API) RemoveInvoice(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RemoveInvoice(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleFinancialsError answers 404 or 409 as for any update, and 422 for
+// a change order or invoice that doesn't add up, or a vendor that doesn't
+// exist.
+func handleFinancialsError(w http.ResponseWriter, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, data.ErrVersionConflict) {
+		handleUpdateError(w, err)
+		return
+	}
+	jsonError(w, http.StatusUnprocessableEntity, err.Error())
+}
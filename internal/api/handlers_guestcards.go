@@ -0,0 +1,95 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/exports"
+	"github.com/cpcloud/webcasa/internal/llm"
+	"gorm.io/gorm"
+)
+
+// draftTimeout bounds how long a model may take to draft guest
+// instructions; local models on modest hardware are slow.
+const draftTimeout = 2 * time.Minute
+
+// WithLLM lets features that can use a language model, such as drafting
+// guest instructions, call this one. Without it they do without.
+func WithLLM(c *llm.Client) Option {
+	return func(a *API) { a.model = c }
+}
+
+// ── Guest cards ────────────────────────────────────
+
+// GuestCard writes an appliance's "how to use" card for guests. ?format=
+// is html (the default), a page to print or save as a PDF, or markdown.
+func (a *API) GuestCard(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = exports.GuestCardHTML
+	}
+	body, err := exports.GuestCard(a.store, id, format)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		jsonError(w, http.StatusNotFound, "appliance not found")
+		return
+	} else if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if format == exports.GuestCardHTML {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	}
+	_, _ = w.Write(body)
+}
+
+// DraftGuestInstructions suggests guest instructions for an appliance,
+// without saving them. Body: {"useModel": true} has the language model
+// write them from the notes and attached text documents; otherwise they
+// come from the notes.
+func (a *API) DraftGuestInstructions(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[struct {
+		UseModel bool `json:"useModel"`
+	}](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var model *llm.Client
+	if body.UseModel {
+		if a.model == nil {
+			jsonError(w, http.StatusServiceUnavailable, "no language model is configured")
+			return
+		}
+		model = a.model
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), draftTimeout)
+	defer cancel()
+	draft, err := exports.DraftGuestInstructions(ctx, a.store, id, model)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		jsonError(w, http.StatusNotFound, "appliance not found")
+	case err != nil && model != nil:
+		jsonError(w, http.StatusBadGateway, err.Error())
+	case err != nil:
+		jsonError(w, http.StatusInternalServerError, err.Error())
+	default:
+		jsonOK(w, draft)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"gorm.io/gorm"
+)
+
+// ── Field History ──────────────────────────────────
+
+// ListFieldHistory returns the recorded field changes to one row, newest
+// first. {entity} is the table name, e.g. "projects".
+func (a *API) ListFieldHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(r.PathValue("eid"), 10, 64)
+	if err != nil || id == 0 {
+		jsonError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	changes, err := a.store.FieldHistory(r.PathValue("entity"), uint(id))
+	if errors.Is(err, data.ErrUnknownEntity) {
+		jsonError(w, http.StatusNotFound, err.Error())
+		return
+	} else if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if changes == nil {
+		changes = []data.FieldChange{}
+	}
+	jsonOK(w, changes)
+}
+
+// RevertField sets a field back to the value it had before change {id}.
+func (a *API) RevertField(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RevertField(id); errors.Is(err, gorm.ErrRecordNotFound) {
+		jsonError(w, http.StatusNotFound, "change or record not found")
+		return
+	} else if err != nil {
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ── Undo Log ───────────────────────────────────────
+
+// ListUndoLog returns the newest steps of the undo log, newest first;
+// ?limit= caps how many (default 50).
+func (a *API) ListUndoLog(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			jsonError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = n
+	}
+	entries, err := a.store.UndoLog(limit)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if entries == nil {
+		entries = []data.UndoEntry{}
+	}
+	jsonOK(w, entries)
+}
+
+// Undo takes back the newest step in the undo log and returns it.
+func (a *API) Undo(w http.ResponseWriter, _ *http.Request) {
+	a.stepUndoLog(w, a.store.Undo, data.ErrNothingToUndo)
+}
+
+// Redo puts back the step undone last and returns it.
+func (a *API) Redo(w http.ResponseWriter, _ *http.Request) {
+	a.stepUndoLog(w, a.store.Redo, data.ErrNothingToRedo)
+}
+
+func (a *API) stepUndoLog(w http.ResponseWriter, step func() (data.UndoEntry, error), nothing error) {
+	entry, err := step()
+	if errors.Is(err, nothing) {
+		jsonError(w, http.StatusConflict, err.Error())
+		return
+	} else if err != nil {
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	jsonOK(w, entry)
+}
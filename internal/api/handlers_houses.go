@@ -0,0 +1,103 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"gorm.io/gorm"
+)
+
+// ── Houses ─────────────────────────────────────────
+
+// withHouse serves API reads asking for ?house=ID with that house's
+// records in place of the current house's, archived or not. Changes are
+// made to the current house, so other requests can't ask for one.
+func (a *API) withHouse(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query().Get("house")
+		if q == "" || !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			jsonError(w, http.StatusBadRequest, "house only picks the records to show -- switch to it to make changes")
+			return
+		}
+		id, err := strconv.ParseUint(q, 10, 64)
+		if err != nil || id == 0 {
+			jsonError(w, http.StatusBadRequest, "invalid house")
+			return
+		}
+		view, err := a.store.ViewHouse(uint(id))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			jsonError(w, http.StatusNotFound, "house not found")
+			return
+		} else if err != nil {
+			jsonError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		b := *a
+		b.store = view
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiKey{}, &b)))
+	})
+}
+
+func (a *API) ListHouses(w http.ResponseWriter, r *http.Request) {
+	houses, err := a.store.ListHouses(boolQuery(r, "archived"))
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, houses)
+}
+
+// AddHouse sets up another house and switches to it.
+func (a *API) AddHouse(w http.ResponseWriter, r *http.Request) {
+	body, err := decodeBody[data.HouseProfile](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.AddHouse(&body); err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonCreated(w, body)
+}
+
+// SwitchHouse makes {"id": n} the current house, the one projects,
+// appliances, maintenance and documents are shown for, and answers with its
+// profile.
+func (a *API) SwitchHouse(w http.ResponseWriter, r *http.Request) {
+	body, err := decodeBody[struct {
+		ID uint `json:"id"`
+	}](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	switch err := a.store.SwitchHouse(body.ID); {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		jsonError(w, http.StatusNotFound, "house not found")
+		return
+	case errors.Is(err, data.ErrHouseArchived):
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	case err != nil:
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	profile, err := a.store.HouseProfile()
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, profile)
+}
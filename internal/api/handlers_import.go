@@ -0,0 +1,59 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// maxSheetRows bounds how many rows one spreadsheet import takes.
+const maxSheetRows = 5000
+
+// ── Spreadsheet Import ─────────────────────────────
+
+// ListSheetFields returns the fields a spreadsheet's columns can be
+// matched to for {kind}: appliances, vendors, maintenance or utilities.
+func (a *API) ListSheetFields(w http.ResponseWriter, r *http.Request) {
+	fields, err := data.SheetFields(r.PathValue("kind"))
+	if err != nil {
+		jsonError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	jsonOK(w, fields)
+}
+
+type sheetImportRequest struct {
+	// Rows map field keys to cells, one per row after the header.
+	Rows []map[string]string `json:"rows"`
+	// DryRun checks the rows without creating anything.
+	DryRun bool `json:"dryRun"`
+}
+
+// ImportSheet creates a record of {kind} from each row of a spreadsheet
+// whose columns have been matched to fields, reporting the rows that don't
+// validate, which are skipped.
+func (a *API) ImportSheet(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeBody[sheetImportRequest](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(req.Rows) == 0 || len(req.Rows) > maxSheetRows {
+		jsonError(w, http.StatusBadRequest, fmt.Sprintf("an import takes 1 to %d rows", maxSheetRows))
+		return
+	}
+	result, err := a.store.ImportSheet(r.PathValue("kind"), req.Rows, req.DryRun)
+	if errors.Is(err, data.ErrUnknownSheetKind) {
+		jsonError(w, http.StatusNotFound, err.Error())
+		return
+	} else if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, result)
+}
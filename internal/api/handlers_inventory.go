@@ -0,0 +1,142 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/exports"
+)
+
+// ── Inventory ──────────────────────────────────────
+
+func (a *API) ListInventory(w http.ResponseWriter, r *http.Request) {
+	items, err := a.store.ListInventory(boolQuery(r, "include_deleted"))
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, items)
+}
+
+// InventoryByRoom lists the inventory grouped by room, each room with the
+// replacement value of what's in it.
+func (a *API) InventoryByRoom(w http.ResponseWriter, _ *http.Request) {
+	rooms, err := a.store.InventoryByRoom()
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if rooms == nil {
+		rooms = []data.InventoryRoom{}
+	}
+	jsonOK(w, rooms)
+}
+
+// InventoryReport downloads the inventory by room with its total
+// replacement value, for an insurer. ?format= is pdf (the default) or csv.
+func (a *API) InventoryReport(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = exports.InventoryPDF
+	}
+	inv, err := exports.Inventory(a.store, time.Now())
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	out, err := inv.Artifact(format)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", out.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", out.FileName))
+	_, _ = w.Write(out.Body)
+}
+
+func (a *API) GetInventoryItem(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	item, err := a.store.GetInventoryItem(id)
+	if err != nil {
+		handleGetError(w, err, "inventory item")
+		return
+	}
+	jsonOK(w, item)
+}
+
+func (a *API) CreateInventoryItem(w http.ResponseWriter, r *http.Request) {
+	body, err := decodeBody[data.InventoryItem](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.CreateInventoryItem(&body); err != nil {
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	created, err := a.store.GetInventoryItem(body.ID)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonCreated(w, created)
+}
+
+func (a *API) UpdateInventoryItem(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[data.InventoryItem](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.ID = id
+	if err := a.store.UpdateInventoryItem(body); err != nil {
+		handleExpenseError(w, err)
+		return
+	}
+	updated, err := a.store.GetInventoryItem(id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, updated)
+}
+
+func (a *API) DeleteInventoryItem(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.DeleteInventoryItem(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) RestoreInventoryItem(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RestoreInventoryItem(id); err != nil {
+		handleRestoreError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
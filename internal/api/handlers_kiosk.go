@@ -0,0 +1,214 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"gorm.io/gorm"
+)
+
+// ── Kiosk ──────────────────────────────────────────
+
+//go:embed kiosk.html
+var kioskHTML string
+
+var kioskTemplate = template.Must(template.New("kiosk").Parse(kioskHTML))
+
+// The kiosk moves on to its next panel every defaultKioskRotate unless
+// ?rotate= says otherwise, in seconds.
+const (
+	defaultKioskRotate = 20 * time.Second
+	minKioskRotate     = 5 * time.Second
+	kioskNextUpRows    = 5
+	kioskWeekDays      = 7
+)
+
+type kioskPage struct {
+	Title   string
+	Updated time.Time
+	Rotate  int
+	// Next is the relative URL of the following panel.
+	Next   string
+	Panel  dashboardSection
+	Panels []kioskDot
+}
+
+type kioskDot struct{ Current bool }
+
+// KioskPage shows one full-screen panel at a time, reloading into the next
+// one, with nothing to tap. It needs a display token in ?token=, so the
+// tablet on the wall can't be used for anything else.
+func (a *API) KioskPage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if status, msg := a.kioskDenies(r, q.Get("token")); status != 0 {
+		http.Error(w, msg, status)
+		return
+	}
+	rotate := defaultKioskRotate
+	if raw := q.Get("rotate"); raw != "" {
+		secs, err := strconv.Atoi(raw)
+		if err != nil || secs <= 0 {
+			http.Error(w, "rotate must be a positive number of seconds", http.StatusBadRequest)
+			return
+		}
+		rotate = max(time.Duration(secs)*time.Second, minKioskRotate)
+	}
+	panel, _ := strconv.Atoi(q.Get("panel"))
+
+	now := time.Now()
+	d, err := a.dashboard(now)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	panels := buildKioskPanels(d, now)
+	panel = ((panel % len(panels)) + len(panels)) % len(panels)
+
+	next := url.Values{}
+	next.Set("token", q.Get("token"))
+	next.Set("panel", strconv.Itoa((panel+1)%len(panels)))
+	if q.Has("rotate") {
+		next.Set("rotate", q.Get("rotate"))
+	}
+	page := kioskPage{
+		Title:   "Home",
+		Updated: now,
+		Rotate:  int(rotate.Seconds()),
+		Next:    "kiosk?" + next.Encode(),
+		Panel:   panels[panel],
+		Panels:  make([]kioskDot, len(panels)),
+	}
+	if d.House != nil && d.House.Nickname != "" {
+		page.Title = d.House.Nickname
+	}
+	page.Panels[panel].Current = true
+
+	var buf bytes.Buffer
+	if err := kioskTemplate.Execute(&buf, page); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	_, _ = buf.WriteTo(w)
+}
+
+// kioskDenies checks the kiosk's token, returning the status and message
+// to refuse with, or 0 if it may see the page. Display tokens are meant
+// for this, but read and full tokens can see as much anyway.
+func (a *API) kioskDenies(r *http.Request, secret string) (int, string) {
+	if secret == "" {
+		return http.StatusUnauthorized, "a display token is required, as ?token="
+	}
+	if a.guard.failures.exhausted(clientHost(r), authFailuresPerMinute, time.Now()) {
+		return http.StatusTooManyRequests, "too many failed sign-in attempts, try again later"
+	}
+	tok, err := a.store.APITokenBySecret(secret)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		a.guard.fail(r)
+		return http.StatusUnauthorized, "unknown or revoked token"
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err.Error()
+	}
+	switch tok.Scope {
+	case data.ScopeDisplay, data.ScopeRead, data.ScopeFull:
+	default:
+		return http.StatusForbidden, "this token can't show the kiosk page"
+	}
+	_ = a.store.TouchAPIToken(tok.ID, time.Now())
+	return 0, ""
+}
+
+// buildKioskPanels makes the kiosk's panels: the next maintenance due,
+// everything dated this week, checklist tasks included, and advisories.
+// There's no weather feed, so advisories are the house's own: urgent
+// incidents, water tests over their limits and delayed projects.
+func buildKioskPanels(d dashboardResponse, now time.Time) []dashboardSection {
+	var nextUp []dashboardRow
+	for _, m := range d.Maintenance {
+		next := m.NextDueAt
+		if next == nil {
+			continue
+		}
+		days := daysBetween(now, *next)
+		nextUp = append(nextUp, dashboardRow{
+			Label: m.Name, Detail: relativeDays(days), Alert: days < 0, due: *next,
+		})
+	}
+	slices.SortStableFunc(nextUp, func(a, b dashboardRow) int { return a.due.Compare(b.due) })
+
+	var week []dashboardRow
+	inWeek := func(label string, t time.Time) {
+		if days := daysBetween(now, t); days >= 0 && days < kioskWeekDays {
+			week = append(week, dashboardRow{Label: label, Detail: t.Format("Mon Jan 2"), due: t})
+		}
+	}
+	for _, row := range nextUp {
+		inWeek(row.Label, row.due)
+	}
+	for _, w := range d.ExpiringWarranties {
+		inWeek(w.Title()+" ends", w.ExpiresOn)
+	}
+	for _, p := range d.PestRetreatments {
+		if next := data.ComputeNextDue(&p.TreatedAt, p.RetreatIntervalMonths); next != nil {
+			inWeek(p.TargetPest+" re-treatment", *next)
+		}
+	}
+	for _, f := range d.AirFilters {
+		inWeek(f.Title, f.DueAt)
+	}
+	for _, t := range d.ChecklistTasks {
+		inWeek(t.Title, *t.DueAt)
+	}
+	for _, b := range d.BidFollowUps {
+		inWeek("Follow up with "+b.Vendor.Name, *b.FollowUpOn)
+	}
+	for _, rb := range d.RebatesDue {
+		inWeek(rb.Program+" rebate", *rb.DueOn())
+	}
+	for _, p := range d.ActiveProjects {
+		if p.StartDate != nil {
+			inWeek(p.Title+" starts", *p.StartDate)
+		}
+		if p.EndDate != nil {
+			inWeek(p.Title+" wraps up", *p.EndDate)
+		}
+	}
+	slices.SortStableFunc(week, func(a, b dashboardRow) int { return a.due.Compare(b.due) })
+
+	var advisories []dashboardRow
+	for _, i := range d.Incidents {
+		if i.Severity == data.IncidentSeverityUrgent {
+			advisories = append(advisories, dashboardRow{Label: i.Title, Detail: "urgent", Alert: true})
+		}
+	}
+	for _, alert := range d.WaterAlerts {
+		advisories = append(advisories, dashboardRow{
+			Label: alert.Test.Source + " water", Detail: "over limits", Alert: true,
+		})
+	}
+	for _, p := range d.ActiveProjects {
+		if p.Status == data.ProjectStatusDelayed {
+			advisories = append(advisories, dashboardRow{Label: p.Title, Detail: "delayed"})
+		}
+	}
+
+	return []dashboardSection{
+		{Title: "Next up", Empty: "No maintenance scheduled.", Rows: nextUp[:min(len(nextUp), kioskNextUpRows)]},
+		{Title: "This week", Empty: "Nothing on the calendar this week.", Rows: week},
+		{Title: "Advisories", Empty: "All clear.", Rows: advisories},
+	}
+}
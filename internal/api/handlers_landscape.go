@@ -0,0 +1,146 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// ── Landscape ──────────────────────────────────────
+
+func (a *API) ListLandscapeKinds(w http.ResponseWriter, _ *http.Request) {
+	jsonOK(w, data.LandscapeKinds())
+}
+
+func (a *API) ListLandscapeAssets(w http.ResponseWriter, r *http.Request) {
+	items, err := a.store.ListLandscapeAssets(boolQuery(r, "include_deleted"))
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, items)
+}
+
+func (a *API) GetLandscapeAsset(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	item, err := a.store.GetLandscapeAsset(id)
+	if err != nil {
+		handleGetError(w, err, "landscape asset")
+		return
+	}
+	jsonOK(w, item)
+}
+
+func (a *API) CreateLandscapeAsset(w http.ResponseWriter, r *http.Request) {
+	body, err := decodeBody[data.LandscapeAsset](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.CreateLandscapeAsset(&body); err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonCreated(w, body)
+}
+
+func (a *API) UpdateLandscapeAsset(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[data.LandscapeAsset](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.ID = id
+	if err := a.store.UpdateLandscapeAsset(body); err != nil {
+		handleUpdateError(w, err)
+		return
+	}
+	updated, err := a.store.GetLandscapeAsset(id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, updated)
+}
+
+func (a *API) DeleteLandscapeAsset(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.DeleteLandscapeAsset(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) RestoreLandscapeAsset(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RestoreLandscapeAsset(id); err != nil {
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) ListMaintenanceByLandscapeAsset(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	items, err := a.store.ListMaintenanceByLandscapeAsset(id, boolQuery(r, "include_deleted"))
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, items)
+}
+
+// landscapeCareRequest carries the task verb ("Fertilize", "Winterize")
+// alongside the maintenance fields for a new care task.
+type landscapeCareRequest struct {
+	data.MaintenanceItem
+	Task string `json:"Task"`
+}
+
+func (a *API) CreateLandscapeCare(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[landscapeCareRequest](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.AddLandscapeCare(id, body.Task, &body.MaintenanceItem); err != nil {
+		handleGetError(w, err, "landscape asset")
+		return
+	}
+	created, err := a.store.GetMaintenance(body.MaintenanceItem.ID)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonCreated(w, created)
+}
@@ -0,0 +1,158 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// ── House events ───────────────────────────────────
+
+func (a *API) ListHouseEvents(w http.ResponseWriter, r *http.Request) {
+	items, err := a.store.ListHouseEvents(boolQuery(r, "include_deleted"))
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, items)
+}
+
+func (a *API) GetHouseEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	item, err := a.store.GetHouseEvent(id)
+	if err != nil {
+		handleGetError(w, err, "house event")
+		return
+	}
+	jsonOK(w, item)
+}
+
+// CreateHouseEvent records a purchase, move or sale and answers with the
+// checklist generated for it.
+func (a *API) CreateHouseEvent(w http.ResponseWriter, r *http.Request) {
+	body, err := decodeBody[data.HouseEvent](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.CreateHouseEvent(&body); err != nil {
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	jsonCreated(w, body)
+}
+
+func (a *API) UpdateHouseEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[data.HouseEvent](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.ID = id
+	if err := a.store.UpdateHouseEvent(body); err != nil {
+		handleUpdateError(w, err)
+		return
+	}
+	updated, err := a.store.GetHouseEvent(id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, updated)
+}
+
+func (a *API) DeleteHouseEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.DeleteHouseEvent(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) RestoreHouseEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RestoreHouseEvent(id); err != nil {
+		handleRestoreError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddHouseEventTask adds a task of the user's own to the event's checklist.
+func (a *API) AddHouseEventTask(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[data.HouseEventTask](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.ID = 0
+	body.HouseEventID = id
+	if err := a.store.AddHouseEventTask(&body); err != nil {
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	jsonCreated(w, body)
+}
+
+// SetHouseEventTaskDone ticks a checklist task off with {"done": true}, or
+// back on with false.
+func (a *API) SetHouseEventTaskDone(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[struct {
+		Done bool `json:"done"`
+	}](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	task, err := a.store.SetHouseEventTaskDone(id, body.Done, time.Now())
+	if err != nil {
+		handleGetError(w, err, "task")
+		return
+	}
+	jsonOK(w, task)
+}
+
+func (a *API) RemoveHouseEventTask(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RemoveHouseEventTask(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
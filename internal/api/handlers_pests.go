@@ -0,0 +1,135 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// ── Pest Treatments ────────────────────────────────
+
+func (a *API) ListPestTreatments(w http.ResponseWriter, r *http.Request) {
+	items, err := a.store.ListPestTreatments(boolQuery(r, "include_deleted"))
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, items)
+}
+
+func (a *API) GetPestTreatment(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	item, err := a.store.GetPestTreatment(id)
+	if err != nil {
+		handleGetError(w, err, "pest treatment")
+		return
+	}
+	jsonOK(w, item)
+}
+
+// LastPestTreatment answers "when was the last termite inspection?" style
+// lookups via ?pest=termite.
+func (a *API) LastPestTreatment(w http.ResponseWriter, r *http.Request) {
+	pest := strings.TrimSpace(r.URL.Query().Get("pest"))
+	if pest == "" {
+		jsonError(w, http.StatusBadRequest, "pest query parameter is required")
+		return
+	}
+	item, err := a.store.LastPestTreatment(pest)
+	if err != nil {
+		handleGetError(w, err, "pest treatment")
+		return
+	}
+	jsonOK(w, item)
+}
+
+// ListPestRetreatmentsDue returns pests due for re-treatment within the
+// next 30 days, including overdue ones.
+func (a *API) ListPestRetreatmentsDue(w http.ResponseWriter, _ *http.Request) {
+	items, err := a.store.ListPestRetreatmentsDue(time.Now(), 30*24*time.Hour)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if items == nil {
+		items = []data.PestTreatment{}
+	}
+	jsonOK(w, items)
+}
+
+func (a *API) CreatePestTreatment(w http.ResponseWriter, r *http.Request) {
+	body, err := decodeBody[data.PestTreatment](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.CreatePestTreatment(&body); err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	created, err := a.store.GetPestTreatment(body.ID)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonCreated(w, created)
+}
+
+func (a *API) UpdatePestTreatment(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[data.PestTreatment](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.ID = id
+	if err := a.store.UpdatePestTreatment(body); err != nil {
+		handleUpdateError(w, err)
+		return
+	}
+	updated, err := a.store.GetPestTreatment(id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, updated)
+}
+
+func (a *API) DeletePestTreatment(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.DeletePestTreatment(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) RestorePestTreatment(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RestorePestTreatment(id); err != nil {
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// ── Project tasks ──────────────────────────────────
+
+// ListProjectTasks lists a project's checklist in order.
+func (a *API) ListProjectTasks(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	tasks, err := a.store.ListProjectTasks(id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if tasks == nil {
+		tasks = []data.ProjectTask{}
+	}
+	jsonOK(w, tasks)
+}
+
+// CreateProjectTask adds a task to the end of a project's checklist.
+func (a *API) CreateProjectTask(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[data.ProjectTask](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.ID = 0
+	body.ProjectID = id
+	if err := a.store.CreateProjectTask(&body, time.Now()); err != nil {
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	jsonCreated(w, body)
+}
+
+// GetProjectTask returns one task, tagged with its version for If-Match.
+func (a *API) GetProjectTask(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	task, err := a.store.GetProjectTask(id)
+	if err != nil {
+		handleGetError(w, err, "task")
+		return
+	}
+	jsonOK(w, task)
+}
+
+func (a *API) UpdateProjectTask(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[data.ProjectTask](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.ID = id
+	if err := a.store.UpdateProjectTask(body, time.Now()); err != nil {
+		handleExpenseError(w, err)
+		return
+	}
+	task, err := a.store.GetProjectTask(id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, task)
+}
+
+func (a *API) DeleteProjectTask(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.DeleteProjectTask(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ReorderProjectTasks puts a project's checklist in the order of {"ids"},
+// which lists every task on it.
+func (a *API) ReorderProjectTasks(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[struct {
+		IDs []uint `json:"ids"`
+	}](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.ReorderProjectTasks(id, body.IDs); err != nil {
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
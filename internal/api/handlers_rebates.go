@@ -0,0 +1,128 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// ── Rebates ────────────────────────────────────────
+
+func (a *API) ListRebates(w http.ResponseWriter, r *http.Request) {
+	items, err := a.store.ListRebates(boolQuery(r, "include_deleted"))
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, items)
+}
+
+// ListRebatesDue lists the rebates to apply for or follow up on in the
+// next ?days= (default 30), overdue ones included.
+func (a *API) ListRebatesDue(w http.ResponseWriter, r *http.Request) {
+	days := 30
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			jsonError(w, http.StatusBadRequest, "days must be a non-negative integer")
+			return
+		}
+		days = n
+	}
+	items, err := a.store.ListRebatesDue(time.Now(), time.Duration(days)*24*time.Hour)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if items == nil {
+		items = []data.Rebate{}
+	}
+	jsonOK(w, items)
+}
+
+func (a *API) GetRebate(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	item, err := a.store.GetRebate(id)
+	if err != nil {
+		handleGetError(w, err, "rebate")
+		return
+	}
+	jsonOK(w, item)
+}
+
+func (a *API) CreateRebate(w http.ResponseWriter, r *http.Request) {
+	body, err := decodeBody[data.Rebate](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.CreateRebate(&body, time.Now()); err != nil {
+		handleExpenseError(w, err)
+		return
+	}
+	created, err := a.store.GetRebate(body.ID)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonCreated(w, created)
+}
+
+func (a *API) UpdateRebate(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[data.Rebate](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.ID = id
+	if err := a.store.UpdateRebate(body, time.Now()); err != nil {
+		handleExpenseError(w, err)
+		return
+	}
+	updated, err := a.store.GetRebate(id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, updated)
+}
+
+func (a *API) DeleteRebate(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.DeleteRebate(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) RestoreRebate(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RestoreRebate(id); err != nil {
+		handleRestoreError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
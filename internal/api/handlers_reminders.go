@@ -0,0 +1,168 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/remind"
+	"gorm.io/gorm"
+)
+
+// ── Reminder links ─────────────────────────────────
+
+//go:embed reminder_link.html
+var reminderLinkHTML string
+
+var reminderLinkTemplate = template.Must(template.New("reminder_link").Parse(reminderLinkHTML))
+
+// reminderLinkHome is the web app, relative to a reminder link's path.
+const reminderLinkHome = "../../../../"
+
+type reminderLinkPage struct {
+	Heading string
+	Message string
+	Error   string
+	Confirm string
+	Home    string
+}
+
+// ReminderLink shows what an action link from a reminder will do, with a
+// button to do it. Link previews in chat apps and mail scanners fetch
+// links, so following one changes nothing by itself. The link's
+// signature stands in for signing in.
+func (a *API) ReminderLink(w http.ResponseWriter, r *http.Request) {
+	action, kind, id, status, msg := a.reminderLink(r)
+	if status != 0 {
+		writeReminderLinkPage(w, status, reminderLinkPage{Heading: "Can't do that", Error: msg, Home: reminderLinkHome})
+		return
+	}
+	page := reminderLinkPage{Heading: "Snooze this reminder?", Confirm: "Snooze a week"}
+	if action == remind.ActionDone {
+		page = reminderLinkPage{Heading: "Mark this done?", Confirm: "Mark done"}
+		if kind == remind.KindApproval {
+			page = reminderLinkPage{Heading: "Approve this change order?", Confirm: "Approve"}
+		}
+	}
+	page.Message = a.reminderLinkSubject(kind, id)
+	writeReminderLinkPage(w, http.StatusOK, page)
+}
+
+// ActOnReminderLink does what a reminder's action link is for: logs
+// maintenance as done, approves a change order, or snoozes the reminder
+// for remind.SnoozeFor.
+func (a *API) ActOnReminderLink(w http.ResponseWriter, r *http.Request) {
+	action, kind, id, status, msg := a.reminderLink(r)
+	if status != 0 {
+		writeReminderLinkPage(w, status, reminderLinkPage{Heading: "Can't do that", Error: msg, Home: reminderLinkHome})
+		return
+	}
+	done, err := remind.Act(a.store, action, kind, id, time.Now())
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		writeReminderLinkPage(w, http.StatusNotFound,
+			reminderLinkPage{Heading: "Can't do that", Error: "It was deleted.", Home: reminderLinkHome})
+		return
+	case err != nil:
+		writeReminderLinkPage(w, http.StatusInternalServerError,
+			reminderLinkPage{Heading: "Something went wrong", Error: err.Error(), Home: reminderLinkHome})
+		return
+	}
+	if res, ok := reminderLinkResource(action, kind, id); ok {
+		a.live.publish(liveEvent{Type: "changed", Resource: res, Method: http.MethodPut})
+	}
+	writeReminderLinkPage(w, http.StatusOK, reminderLinkPage{Heading: "Done", Message: done, Home: reminderLinkHome})
+}
+
+// reminderLink checks an action link, returning what it is for, or the
+// status and message to refuse it with.
+func (a *API) reminderLink(r *http.Request) (action, kind string, id uint, status int, msg string) {
+	action, kind = r.PathValue("action"), r.PathValue("kind")
+	id, err := parseID(r)
+	if err != nil {
+		return "", "", 0, http.StatusBadRequest, err.Error()
+	}
+	if a.guard.failures.exhausted(clientHost(r), authFailuresPerMinute, time.Now()) {
+		return "", "", 0, http.StatusTooManyRequests, "Too many bad links; try again later."
+	}
+	key, err := remind.LinkKey(a.store)
+	if err != nil {
+		return "", "", 0, http.StatusInternalServerError, err.Error()
+	}
+	if err := remind.VerifyLink(key, action, kind, id, r.URL.Query(), time.Now()); err != nil {
+		a.guard.fail(r)
+		return "", "", 0, http.StatusForbidden, "This link is invalid or has expired."
+	}
+	return action, kind, id, 0, ""
+}
+
+// reminderLinkSubject names the record a link is about, or "" if it
+// can't.
+func (a *API) reminderLinkSubject(kind string, id uint) string {
+	switch kind {
+	case remind.KindMaintenance:
+		if item, err := a.store.GetMaintenance(id); err == nil {
+			return item.Name
+		}
+	case remind.KindApproval:
+		if co, err := a.store.GetChangeOrder(id); err == nil {
+			return co.Reason
+		}
+	case remind.KindWarranty:
+		if app, err := a.store.GetAppliance(id); err == nil {
+			return app.Name + " warranty"
+		}
+	case remind.KindPolicy:
+		if w, err := a.store.GetWarranty(id); err == nil {
+			return w.Title()
+		}
+	case remind.KindRebate:
+		if rb, err := a.store.GetRebate(id); err == nil {
+			return rb.Title()
+		}
+	case remind.KindProjectTask:
+		if task, err := a.store.GetProjectTask(id); err == nil {
+			return task.Title
+		}
+	}
+	return ""
+}
+
+// reminderLinkResource is the record an action changed, for browsers
+// showing it. Snoozing changes no record.
+func reminderLinkResource(action, kind string, id uint) (string, bool) {
+	if action != remind.ActionDone {
+		return "", false
+	}
+	switch kind {
+	case remind.KindMaintenance:
+		return "maintenance/" + strconv.FormatUint(uint64(id), 10), true
+	case remind.KindApproval:
+		return "change-orders/" + strconv.FormatUint(uint64(id), 10), true
+	case remind.KindProjectTask:
+		return "project-tasks/" + strconv.FormatUint(uint64(id), 10), true
+	case remind.KindChecklist:
+		return "house-event-tasks/" + strconv.FormatUint(uint64(id), 10), true
+	}
+	return "", false
+}
+
+func writeReminderLinkPage(w http.ResponseWriter, status int, page reminderLinkPage) {
+	var buf bytes.Buffer
+	if err := reminderLinkTemplate.Execute(&buf, page); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.WriteHeader(status)
+	_, _ = buf.WriteTo(w)
+}
@@ -0,0 +1,465 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// ── Rooms ──────────────────────────────────────────
+
+func (a *API) ListRooms(w http.ResponseWriter, r *http.Request) {
+	items, err := a.store.ListRooms(boolQuery(r, "include_deleted"))
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, items)
+}
+
+func (a *API) GetRoom(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	item, err := a.store.GetRoom(id)
+	if err != nil {
+		handleGetError(w, err, "room")
+		return
+	}
+	jsonOK(w, item)
+}
+
+func (a *API) CreateRoom(w http.ResponseWriter, r *http.Request) {
+	body, err := decodeBody[data.Room](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.CreateRoom(&body); err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonCreated(w, body)
+}
+
+func (a *API) UpdateRoom(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[data.Room](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.ID = id
+	if err := a.store.UpdateRoom(body); err != nil {
+		handleUpdateError(w, err)
+		return
+	}
+	updated, err := a.store.GetRoom(id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, updated)
+}
+
+func (a *API) DeleteRoom(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.DeleteRoom(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetRoomDrilldown returns a room with its appliances, projects, finishes,
+// and saved estimates.
+func (a *API) GetRoomDrilldown(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	d, err := a.store.RoomDrilldown(id)
+	if err != nil {
+		handleGetError(w, err, "room")
+		return
+	}
+	jsonOK(w, d)
+}
+
+func (a *API) RestoreRoom(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RestoreRoom(id); err != nil {
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ── Room Finishes ──────────────────────────────────
+
+func (a *API) ListRoomFinishes(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	items, err := a.store.ListRoomFinishes(id, boolQuery(r, "include_deleted"))
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, items)
+}
+
+func (a *API) CreateRoomFinish(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[data.RoomFinish](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.RoomID = id
+	if err := a.store.CreateRoomFinish(&body); err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonCreated(w, body)
+}
+
+func (a *API) UpdateRoomFinish(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	existing, err := a.store.GetRoomFinish(id)
+	if err != nil {
+		handleGetError(w, err, "finish")
+		return
+	}
+	body, err := decodeBody[data.RoomFinish](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.ID = id
+	body.RoomID = existing.RoomID
+	if err := a.store.UpdateRoomFinish(body); err != nil {
+		handleUpdateError(w, err)
+		return
+	}
+	updated, err := a.store.GetRoomFinish(id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, updated)
+}
+
+func (a *API) DeleteRoomFinish(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.DeleteRoomFinish(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) RestoreRoomFinish(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RestoreRoomFinish(id); err != nil {
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ── Floor Plans ────────────────────────────────────
+
+func (a *API) ListFloorPlans(w http.ResponseWriter, r *http.Request) {
+	items, err := a.store.ListFloorPlans(boolQuery(r, "include_deleted"))
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, items)
+}
+
+func (a *API) GetFloorPlan(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	item, err := a.store.GetFloorPlan(id)
+	if err != nil {
+		handleGetError(w, err, "floor plan")
+		return
+	}
+	jsonOK(w, item)
+}
+
+// FloorPlanImage serves the floor plan image inline.
+func (a *API) FloorPlanImage(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	item, err := a.store.GetFloorPlan(id)
+	if err != nil {
+		handleGetError(w, err, "floor plan")
+		return
+	}
+	w.Header().Set("Content-Type", item.ImageMIMEType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(item.ImageData)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(item.ImageData) //nolint:errcheck
+}
+
+// UploadFloorPlan handles multipart form uploads. Fields:
+//
+//	file  - the image (required)
+//	name  - plan name (defaults to the file name)
+//	level - optional floor or level
+//	notes - optional notes
+func (a *API) UploadFloorPlan(w http.ResponseWriter, r *http.Request) {
+	maxUpload := a.store.MaxDocumentSize()
+	r.Body = http.MaxBytesReader(w, r.Body, maxUpload+1024)
+
+	if err := r.ParseMultipartForm(maxUpload); err != nil {
+		jsonError(w, http.StatusBadRequest, fmt.Sprintf("parse form: %v", err))
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, "missing 'file' field in multipart form")
+		return
+	}
+	defer file.Close()
+
+	fileData, err := io.ReadAll(file)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, fmt.Sprintf("read uploaded file: %v", err))
+		return
+	}
+	name := r.FormValue("name")
+	if name == "" {
+		name = data.TitleFromFilename(header.Filename)
+	}
+	mime := header.Header.Get("Content-Type")
+	if mime == "" || mime == "application/octet-stream" {
+		mime = detectMIME(fileData, header.Filename)
+	}
+
+	plan := data.FloorPlan{
+		Name:          name,
+		Level:         r.FormValue("level"),
+		FileName:      filepath.Base(header.Filename),
+		ImageMIMEType: mime,
+		SizeBytes:     int64(len(fileData)),
+		ImageData:     fileData,
+		Notes:         r.FormValue("notes"),
+	}
+	if err := a.store.CreateFloorPlan(&plan); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	jsonCreated(w, plan)
+}
+
+func (a *API) UpdateFloorPlan(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[data.FloorPlan](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.ID = id
+	if err := a.store.UpdateFloorPlan(body); err != nil {
+		handleUpdateError(w, err)
+		return
+	}
+	updated, err := a.store.GetFloorPlan(id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, updated)
+}
+
+// SetFloorPlanHotspots replaces the plan's room regions with the posted list.
+func (a *API) SetFloorPlanHotspots(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[[]data.RoomHotspot](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.SetFloorPlanHotspots(id, body); err != nil {
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	updated, err := a.store.GetFloorPlan(id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, updated)
+}
+
+func (a *API) DeleteFloorPlan(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.DeleteFloorPlan(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) RestoreFloorPlan(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RestoreFloorPlan(id); err != nil {
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ── Material Estimates ─────────────────────────────
+
+// estimateRequest names the room to estimate and the optional inputs.
+type estimateRequest struct {
+	data.EstimateInputs
+	RoomID uint   `json:"RoomID"`
+	Notes  string `json:"Notes"`
+}
+
+// PreviewMaterialEstimate computes an estimate without saving it.
+func (a *API) PreviewMaterialEstimate(w http.ResponseWriter, r *http.Request) {
+	body, err := decodeBody[estimateRequest](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	room, err := a.store.GetRoom(body.RoomID)
+	if err != nil {
+		handleGetError(w, err, "room")
+		return
+	}
+	est, err := data.EstimateMaterials(room, body.EstimateInputs)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	jsonOK(w, est)
+}
+
+func (a *API) ListMaterialEstimates(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	items, err := a.store.ListMaterialEstimates(id, boolQuery(r, "include_deleted"))
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, items)
+}
+
+func (a *API) CreateMaterialEstimate(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[estimateRequest](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	est, err := a.store.SaveMaterialEstimate(id, body.RoomID, body.EstimateInputs, body.Notes)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonCreated(w, est)
+}
+
+func (a *API) DeleteMaterialEstimate(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.DeleteMaterialEstimate(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) RestoreMaterialEstimate(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RestoreMaterialEstimate(id); err != nil {
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
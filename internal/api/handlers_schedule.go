@@ -0,0 +1,68 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/exports"
+)
+
+// ── Maintenance Schedule ───────────────────────────
+
+// defaultDueDays is how far ahead ListMaintenanceDue looks by default.
+const defaultDueDays = 30
+
+// ListMaintenanceDue returns maintenance due within ?days= (default 30),
+// overdue items included, soonest first.
+func (a *API) ListMaintenanceDue(w http.ResponseWriter, r *http.Request) {
+	days := defaultDueDays
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			jsonError(w, http.StatusBadRequest, "days must be a non-negative integer")
+			return
+		}
+		days = n
+	}
+	items, err := a.store.ListMaintenanceDue(time.Now(), time.Duration(days)*24*time.Hour)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if items == nil {
+		items = []data.MaintenanceItem{}
+	}
+	jsonOK(w, items)
+}
+
+// ListMaintenanceOverdue returns maintenance past its due date, most
+// overdue first.
+func (a *API) ListMaintenanceOverdue(w http.ResponseWriter, _ *http.Request) {
+	items, err := a.store.OverdueMaintenance(time.Now())
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if items == nil {
+		items = []data.MaintenanceItem{}
+	}
+	jsonOK(w, items)
+}
+
+// Calendar serves the house's dates as an iCalendar feed to subscribe to
+// from a calendar app.
+func (a *API) Calendar(w http.ResponseWriter, _ *http.Request) {
+	body, err := exports.Calendar(a.store, time.Now())
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="webcasa.ics"`)
+	_, _ = w.Write(body)
+}
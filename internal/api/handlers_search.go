@@ -0,0 +1,31 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// ── Search ─────────────────────────────────────────
+
+// Search finds records of the current house matching ?q=, best first.
+func (a *API) Search(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		jsonError(w, http.StatusBadRequest, "q is required")
+		return
+	}
+	hits, err := a.store.Search(q)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if hits == nil {
+		hits = []data.SearchHit{}
+	}
+	jsonOK(w, hits)
+}
@@ -0,0 +1,46 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/exports"
+	"gorm.io/gorm"
+)
+
+// ── Service history ────────────────────────────────
+
+// ServiceHistory downloads an appliance's service history, oldest first.
+// ?format= is pdf (the default) or csv.
+func (a *API) ServiceHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = exports.ServiceHistoryPDF
+	}
+	h, err := exports.ApplianceServiceHistory(a.store, id, time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		jsonError(w, http.StatusNotFound, "appliance not found")
+		return
+	} else if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	out, err := h.Artifact(format)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", out.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", out.FileName))
+	_, _ = w.Write(out.Body)
+}
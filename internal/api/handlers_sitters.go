@@ -0,0 +1,260 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"gorm.io/gorm"
+)
+
+// ── House sitters ──────────────────────────────────
+
+type createdSitterStay struct {
+	Stay data.SitterStay `json:"stay"`
+	// Secret is shown once, at creation. Link is the sitter's page,
+	// relative to the web app.
+	Secret string `json:"secret"`
+	Link   string `json:"link"`
+}
+
+func (a *API) ListSitterStays(w http.ResponseWriter, _ *http.Request) {
+	stays, err := a.store.ListSitterStays()
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, stays)
+}
+
+// CreateSitterStay sets up a stay and answers with its share link, which
+// can't be shown again.
+func (a *API) CreateSitterStay(w http.ResponseWriter, r *http.Request) {
+	body, err := decodeBody[data.SitterStay](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.ID = 0
+	secret, err := a.store.CreateSitterStay(&body, time.Now())
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	jsonCreated(w, createdSitterStay{Stay: body, Secret: secret, Link: "sitter/" + secret})
+}
+
+// RevokeSitterStay stops a stay's link from working before the stay ends.
+func (a *API) RevokeSitterStay(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RevokeSitterStay(id, time.Now()); err != nil {
+		handleGetError(w, err, "sitter stay")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) DeleteSitterStay(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.DeleteSitterStay(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ── Sitter pages ───────────────────────────────────
+
+//go:embed sitter.html
+var sitterHTML string
+
+var sitterTemplate = template.Must(template.New("sitter").Parse(sitterHTML))
+
+type sitterPage struct {
+	Title   string
+	Error   string
+	Secret  string
+	Address string
+	Stay    data.SitterStay
+	Guide   data.SitterGuide
+	// Left is set after the sitter leaves a note.
+	Left bool
+}
+
+// SitterPage is what a house-sitter's share link opens: emergency info,
+// appliance guides, what's scheduled during the stay, and a form for
+// leaving notes and photos. The link's secret stands in for signing in.
+func (a *API) SitterPage(w http.ResponseWriter, r *http.Request) {
+	stay, status, msg := a.sitterStay(r)
+	if status != 0 {
+		writeSitterPage(w, status, sitterPage{Title: "Can't open this link", Error: msg})
+		return
+	}
+	guide, err := a.store.SitterGuide(stay)
+	if err != nil {
+		writeSitterPage(w, http.StatusInternalServerError, sitterPage{Title: "Something went wrong", Error: err.Error()})
+		return
+	}
+	page := sitterPage{
+		Title:   "House notes",
+		Secret:  r.PathValue("secret"),
+		Address: houseAddress(guide.House),
+		Stay:    stay,
+		Guide:   guide,
+		Left:    r.URL.Query().Has("left"),
+	}
+	if guide.House.Nickname != "" {
+		page.Title = guide.House.Nickname
+	}
+	writeSitterPage(w, http.StatusOK, page)
+}
+
+// LeaveSitterNote saves a note and photo from the sitter's page form,
+// fields note and photo, and goes back to the page.
+func (a *API) LeaveSitterNote(w http.ResponseWriter, r *http.Request) {
+	stay, status, msg := a.sitterStay(r)
+	if status != 0 {
+		writeSitterPage(w, status, sitterPage{Title: "Can't open this link", Error: msg})
+		return
+	}
+	fail := func(status int, msg string) {
+		writeSitterPage(w, status, sitterPage{Title: "Couldn't leave that", Error: msg})
+	}
+	maxUpload := a.store.MaxDocumentSize()
+	r.Body = http.MaxBytesReader(w, r.Body, maxUpload+1024)
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		fail(http.StatusBadRequest, fmt.Sprintf("parse form: %v", err))
+		return
+	}
+
+	var photo *data.Document
+	file, header, err := r.FormFile("photo")
+	switch {
+	case errors.Is(err, http.ErrMissingFile):
+	case err != nil:
+		fail(http.StatusBadRequest, err.Error())
+		return
+	default:
+		defer file.Close()
+		fileData, err := io.ReadAll(file)
+		if err != nil {
+			fail(http.StatusInternalServerError, fmt.Sprintf("read uploaded file: %v", err))
+			return
+		}
+		mime := header.Header.Get("Content-Type")
+		if mime == "" || mime == "application/octet-stream" {
+			mime = detectMIME(fileData, header.Filename)
+		}
+		if !strings.HasPrefix(mime, "image/") {
+			fail(http.StatusUnsupportedMediaType, "Only photos can be left here.")
+			return
+		}
+		photo = &data.Document{
+			Title:          fmt.Sprintf("From %s, %s", stay.Sitter, time.Now().Format("Jan 2")),
+			FileName:       filepath.Base(header.Filename),
+			MIMEType:       mime,
+			SizeBytes:      int64(len(fileData)),
+			ChecksumSHA256: fmt.Sprintf("%x", sha256.Sum256(fileData)),
+			Data:           fileData,
+		}
+		if a.images != nil {
+			a.shrinkPhoto(photo)
+		}
+		fingerprintPhoto(photo)
+	}
+
+	if _, err := a.store.As("sitter:"+stay.Sitter).AddSitterNote(stay.ID, r.FormValue("note"), photo); err != nil {
+		fail(http.StatusBadRequest, err.Error())
+		return
+	}
+	a.live.publish(liveEvent{
+		Type: "changed", Resource: "sitter-stays/" + strconv.FormatUint(uint64(stay.ID), 10), Method: http.MethodPost,
+	})
+	http.Redirect(w, r, "../"+r.PathValue("secret")+"?left", http.StatusSeeOther)
+}
+
+// SitterPhoto serves a photo left during the stay to its sitter.
+func (a *API) SitterPhoto(w http.ResponseWriter, r *http.Request) {
+	stay, status, msg := a.sitterStay(r)
+	if status != 0 {
+		http.Error(w, msg, status)
+		return
+	}
+	id, err := parseID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	doc, err := a.store.SitterPhoto(stay.ID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "no such photo", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", doc.MIMEType)
+	w.Header().Set("Cache-Control", "no-store")
+	http.ServeContent(w, r, doc.FileName, time.Time{}, bytes.NewReader(doc.Data))
+}
+
+// sitterStay finds the stay a sitter link is for, or the status and
+// message to refuse it with.
+func (a *API) sitterStay(r *http.Request) (data.SitterStay, int, string) {
+	if a.guard.failures.exhausted(clientHost(r), authFailuresPerMinute, time.Now()) {
+		return data.SitterStay{}, http.StatusTooManyRequests, "Too many bad links; try again later."
+	}
+	stay, err := a.store.SitterStayBySecret(r.PathValue("secret"), time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		a.guard.fail(r)
+		return data.SitterStay{}, http.StatusNotFound, "This link is invalid or has expired."
+	} else if err != nil {
+		return data.SitterStay{}, http.StatusInternalServerError, err.Error()
+	}
+	return stay, 0, ""
+}
+
+// houseAddress is the house's address on one line.
+func houseAddress(h data.HouseProfile) string {
+	var parts []string
+	for _, p := range []string{h.AddressLine1, h.AddressLine2, h.City, strings.TrimSpace(h.State + " " + h.PostalCode)} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func writeSitterPage(w http.ResponseWriter, status int, page sitterPage) {
+	var buf bytes.Buffer
+	if err := sitterTemplate.Execute(&buf, page); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.WriteHeader(status)
+	_, _ = buf.WriteTo(w)
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// ── Stale projects ─────────────────────────────────
+
+// staleProjectDays is how long an active project goes without activity
+// before the dashboard nudges about it.
+const staleProjectDays = 30
+
+// ListStaleProjects lists the active projects with no activity in ?days=
+// days, 30 by default, the longest idle first.
+func (a *API) ListStaleProjects(w http.ResponseWriter, r *http.Request) {
+	days := staleProjectDays
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			jsonError(w, http.StatusBadRequest, "days must be a non-negative integer")
+			return
+		}
+		days = n
+	}
+	stale, err := a.store.ListStaleProjects(time.Now(), time.Duration(days)*24*time.Hour)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if stale == nil {
+		stale = []data.StaleProject{}
+	}
+	jsonOK(w, stale)
+}
+
+// BumpProject marks a project as looked at, so it's no longer stale.
+func (a *API) BumpProject(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.BumpProject(id, time.Now()); err != nil {
+		handleGetError(w, err, "project")
+		return
+	}
+	a.writeProject(w, id)
+}
+
+// SetProjectStatus moves a project to {"status"}, delayed or abandoned for
+// {"reason"}, adding {"note"} to its description when given.
+func (a *API) SetProjectStatus(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[struct {
+		Status string `json:"status"`
+		Reason string `json:"reason"`
+		Note   string `json:"note"`
+	}](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.SetProjectStatus(id, body.Status, body.Reason, body.Note, time.Now()); err != nil {
+		handleExpenseError(w, err)
+		return
+	}
+	a.writeProject(w, id)
+}
+
+// ListStatusReasons lists the reasons a project can be delayed or
+// abandoned for.
+func (a *API) ListStatusReasons(w http.ResponseWriter, _ *http.Request) {
+	jsonOK(w, data.StatusReasons())
+}
+
+func (a *API) writeProject(w http.ResponseWriter, id uint) {
+	project, err := a.store.GetProject(id)
+	if err != nil {
+		handleGetError(w, err, "project")
+		return
+	}
+	jsonOK(w, project)
+}
@@ -0,0 +1,145 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// ── Tags ───────────────────────────────────────────
+
+func (a *API) ListTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := a.store.ListTags()
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, tags)
+}
+
+type tagRequest struct {
+	Name string `json:"name"`
+}
+
+// CreateTag adds a tag, answering with the existing one when a tag is
+// already named the same.
+func (a *API) CreateTag(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeBody[tagRequest](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	tag, err := a.store.CreateTag(req.Name)
+	if err != nil {
+		handleTagError(w, err)
+		return
+	}
+	jsonCreated(w, tag)
+}
+
+// RenameTag renames a tag on every record that carries it.
+func (a *API) RenameTag(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	req, err := decodeBody[tagRequest](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	tag, err := a.store.RenameTag(id, req.Name)
+	if err != nil {
+		handleTagError(w, err)
+		return
+	}
+	jsonOK(w, tag)
+}
+
+func (a *API) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.DeleteTag(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type setTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// setTagsOf handles replacing the tags of a record of kind, answering with
+// the tags it carries afterwards.
+func (a *API) setTagsOf(kind string) http.HandlerFunc {
+	return a.bind(func(a *API, w http.ResponseWriter, r *http.Request) {
+		id, err := parseID(r)
+		if err != nil {
+			jsonError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		req, err := decodeBody[setTagsRequest](r)
+		if err != nil {
+			jsonError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		tags, err := a.store.SetTags(kind, id, req.Tags)
+		if err != nil {
+			handleTagError(w, err)
+			return
+		}
+		jsonOK(w, setTagsRequest{Tags: append([]string{}, tags...)})
+	})
+}
+
+func handleTagError(w http.ResponseWriter, err error) {
+	if errors.Is(err, data.ErrTagName) {
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	handleGetError(w, err, "record")
+}
+
+// withTags fills in the tags of listed records of kind and, when the
+// request has ?tag=, keeps only the records carrying that tag.
+func withTags[T any](a *API, r *http.Request, kind string, items []T, fields func(*T) (uint, *[]string)) ([]T, error) {
+	ids := make([]uint, len(items))
+	for i := range items {
+		ids[i], _ = fields(&items[i])
+	}
+	tags, err := a.store.TagsOf(kind, ids)
+	if err != nil {
+		return nil, err
+	}
+	keep := func(uint) bool { return true }
+	if name := r.URL.Query().Get("tag"); name != "" {
+		tagged, err := a.store.TaggedIDs(kind, name)
+		if err != nil {
+			return nil, err
+		}
+		set := make(map[uint]bool, len(tagged))
+		for _, id := range tagged {
+			set[id] = true
+		}
+		keep = func(id uint) bool { return set[id] }
+	}
+	out := items[:0]
+	for i := range items {
+		id, itemTags := fields(&items[i])
+		if !keep(id) {
+			continue
+		}
+		*itemTags = append([]string{}, tags[id]...)
+		out = append(out, items[i])
+	}
+	return out, nil
+}
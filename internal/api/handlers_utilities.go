@@ -0,0 +1,132 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// ── Utility bills ──────────────────────────────────
+
+func (a *API) ListUtilityBills(w http.ResponseWriter, r *http.Request) {
+	items, err := a.store.ListUtilityBills(boolQuery(r, "include_deleted"))
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, items)
+}
+
+func (a *API) ListUtilityTypes(w http.ResponseWriter, _ *http.Request) {
+	jsonOK(w, data.UtilityTypes())
+}
+
+func (a *API) GetUtilityBill(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	item, err := a.store.GetUtilityBill(id)
+	if err != nil {
+		handleGetError(w, err, "utility bill")
+		return
+	}
+	jsonOK(w, item)
+}
+
+func (a *API) CreateUtilityBill(w http.ResponseWriter, r *http.Request) {
+	body, err := decodeBody[data.UtilityBill](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.CreateUtilityBill(&body); err != nil {
+		handleExpenseError(w, err)
+		return
+	}
+	created, err := a.store.GetUtilityBill(body.ID)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonCreated(w, created)
+}
+
+func (a *API) UpdateUtilityBill(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[data.UtilityBill](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.ID = id
+	if err := a.store.UpdateUtilityBill(body); err != nil {
+		handleExpenseError(w, err)
+		return
+	}
+	updated, err := a.store.GetUtilityBill(id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, updated)
+}
+
+func (a *API) DeleteUtilityBill(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.DeleteUtilityBill(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) RestoreUtilityBill(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RestoreUtilityBill(id); err != nil {
+		handleRestoreError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UtilityTrends returns each utility's usage and cost over the last
+// ?months= months (default 12), next to the same months a year before.
+func (a *API) UtilityTrends(w http.ResponseWriter, r *http.Request) {
+	months := dashboardChartMonths
+	if raw := r.URL.Query().Get("months"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > 120 {
+			jsonError(w, http.StatusBadRequest, "months must be a number from 1 to 120")
+			return
+		}
+		months = n
+	}
+	trends, err := a.store.UtilityTrends(time.Now(), months)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if trends == nil {
+		trends = []data.UtilityTrend{}
+	}
+	jsonOK(w, trends)
+}
@@ -0,0 +1,164 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// ── Walkthroughs ───────────────────────────────────
+
+func (a *API) ListWalkthroughs(w http.ResponseWriter, r *http.Request) {
+	items, err := a.store.ListWalkthroughs(boolQuery(r, "include_deleted"))
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, items)
+}
+
+func (a *API) GetWalkthrough(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	item, err := a.store.GetWalkthrough(id)
+	if err != nil {
+		handleGetError(w, err, "walkthrough")
+		return
+	}
+	jsonOK(w, item)
+}
+
+func (a *API) CreateWalkthrough(w http.ResponseWriter, r *http.Request) {
+	body, err := decodeBody[data.Walkthrough](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.CreateWalkthrough(&body); err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonCreated(w, body)
+}
+
+func (a *API) UpdateWalkthrough(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[data.Walkthrough](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.ID = id
+	if err := a.store.UpdateWalkthrough(body); err != nil {
+		handleUpdateError(w, err)
+		return
+	}
+	updated, err := a.store.GetWalkthrough(id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, updated)
+}
+
+func (a *API) DeleteWalkthrough(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.DeleteWalkthrough(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) RestoreWalkthrough(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RestoreWalkthrough(id); err != nil {
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddWalkthroughItem tags an existing photo or video document as part of
+// the walkthrough.
+func (a *API) AddWalkthroughItem(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[data.WalkthroughItem](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.ID = 0
+	body.WalkthroughID = id
+	if err := a.store.AddWalkthroughItem(&body); err != nil {
+		jsonError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	jsonCreated(w, body)
+}
+
+func (a *API) RemoveWalkthroughItem(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RemoveWalkthroughItem(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CompareWalkthroughs lines up walkthrough shots by area for the years in
+// the comma-separated ?years= parameter.
+func (a *API) CompareWalkthroughs(w http.ResponseWriter, r *http.Request) {
+	var years []int
+	for _, part := range strings.Split(r.URL.Query().Get("years"), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		y, err := strconv.Atoi(part)
+		if err != nil {
+			jsonError(w, http.StatusBadRequest, fmt.Sprintf("invalid year %q", part))
+			return
+		}
+		years = append(years, y)
+	}
+	if len(years) == 0 {
+		jsonError(w, http.StatusBadRequest, "years is required, e.g. ?years=2024,2025")
+		return
+	}
+	rows, err := a.store.CompareWalkthroughs(years)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, map[string]any{"years": years, "areas": rows})
+}
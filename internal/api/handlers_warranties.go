@@ -0,0 +1,128 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// ── Warranties ─────────────────────────────────────
+
+func (a *API) ListWarranties(w http.ResponseWriter, r *http.Request) {
+	items, err := a.store.ListWarranties(boolQuery(r, "include_deleted"))
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, items)
+}
+
+// ListExpiringWarranties lists the warranties ending in the next ?days=
+// (default 90), appliances' own warranty dates included.
+func (a *API) ListExpiringWarranties(w http.ResponseWriter, r *http.Request) {
+	days := 90
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			jsonError(w, http.StatusBadRequest, "days must be a non-negative integer")
+			return
+		}
+		days = n
+	}
+	items, err := a.store.ListExpiringWarranties(time.Now(), 0, time.Duration(days)*24*time.Hour)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if items == nil {
+		items = []data.Warranty{}
+	}
+	jsonOK(w, items)
+}
+
+func (a *API) GetWarranty(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	item, err := a.store.GetWarranty(id)
+	if err != nil {
+		handleGetError(w, err, "warranty")
+		return
+	}
+	jsonOK(w, item)
+}
+
+func (a *API) CreateWarranty(w http.ResponseWriter, r *http.Request) {
+	body, err := decodeBody[data.Warranty](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.CreateWarranty(&body); err != nil {
+		handleExpenseError(w, err)
+		return
+	}
+	created, err := a.store.GetWarranty(body.ID)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonCreated(w, created)
+}
+
+func (a *API) UpdateWarranty(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := decodeBody[data.Warranty](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body.ID = id
+	if err := a.store.UpdateWarranty(body); err != nil {
+		handleExpenseError(w, err)
+		return
+	}
+	updated, err := a.store.GetWarranty(id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, updated)
+}
+
+func (a *API) DeleteWarranty(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.DeleteWarranty(id); err != nil {
+		handleDeleteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) RestoreWarranty(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.store.RestoreWarranty(id); err != nil {
+		handleRestoreError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
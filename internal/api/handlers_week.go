@@ -0,0 +1,95 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/remind"
+)
+
+// ── This week ──────────────────────────────────────
+
+// Week lists everything needing attention in the coming week, overdue
+// things first: maintenance, tasks with due dates, appointments and the
+// other reminders.
+func (a *API) Week(w http.ResponseWriter, r *http.Request) {
+	items, err := remind.Week(a.store, time.Now())
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if items == nil {
+		items = []remind.WeekItem{}
+	}
+	jsonOK(w, items)
+}
+
+// ActOnWeekItem marks a thing on the week's list done, snoozes it for
+// remind.SnoozeFor, or reschedules it to {"due"}, answering with what was
+// done.
+func (a *API) ActOnWeekItem(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	kind, action, now := r.PathValue("kind"), r.PathValue("action"), time.Now()
+	var msg string
+	switch action {
+	case remind.ActionDone, remind.ActionSnooze:
+		msg, err = remind.Act(a.store, action, kind, id, now)
+		if errors.Is(err, remind.ErrBadLink) {
+			jsonError(w, http.StatusUnprocessableEntity, "this can't be marked done -- snooze it instead")
+			return
+		}
+	case "reschedule":
+		body, err := decodeBody[struct {
+			Due time.Time `json:"due"`
+		}](r)
+		if err != nil {
+			jsonError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if body.Due.IsZero() {
+			jsonError(w, http.StatusBadRequest, "due is required")
+			return
+		}
+		msg, err = remind.Reschedule(a.store, kind, id, body.Due, now)
+		if errors.Is(err, remind.ErrCantReschedule) {
+			jsonError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+	default:
+		jsonError(w, http.StatusBadRequest, "action must be done, snooze or reschedule")
+		return
+	}
+	if err != nil {
+		handleUpdateError(w, err)
+		return
+	}
+	if res, ok := weekResource(action, kind, id); ok {
+		a.live.publish(liveEvent{Type: "changed", Resource: res, Method: http.MethodPut})
+	}
+	jsonOK(w, map[string]string{"message": msg})
+}
+
+// weekResource is the record an action on the week's list changed, for
+// browsers showing it. Snoozing changes no record.
+func weekResource(action, kind string, id uint) (string, bool) {
+	if action == remind.ActionSnooze {
+		return "", false
+	}
+	switch kind {
+	case remind.KindProjectTask, remind.KindChecklist:
+		// Rescheduled or done, the task is the record that changed.
+		return reminderLinkResource(remind.ActionDone, kind, id)
+	case remind.KindProjectStart, remind.KindProjectEnd:
+		return "projects/" + strconv.FormatUint(uint64(id), 10), true
+	}
+	return reminderLinkResource(action, kind, id)
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// retryAfter is what clients are told to wait while the database is
+// unavailable.
+const retryAfter = 5 * time.Second
+
+// withHealth answers API requests with 503 and Retry-After while the
+// database is unavailable, rather than letting each fail with a 500. A
+// request that fails because the database went away mid-flight gets the
+// same treatment. The health endpoint and static files are always served.
+func withHealth(next http.Handler, store *data.Store) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") || r.URL.Path == "/api/health" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !store.Health().OK {
+			unavailable(w)
+			return
+		}
+		next.ServeHTTP(&healthWriter{ResponseWriter: w, store: store}, r)
+	})
+}
+
+func unavailable(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter/time.Second)))
+	jsonError(w, http.StatusServiceUnavailable, "database temporarily unavailable, try again shortly")
+}
+
+// healthWriter turns a 500 into a 503 when the database has become
+// unavailable by the time the handler gives up.
+type healthWriter struct {
+	http.ResponseWriter
+	store *data.Store
+}
+
+func (hw *healthWriter) WriteHeader(code int) {
+	if code == http.StatusInternalServerError && !hw.store.Health().OK {
+		hw.Header().Set("Retry-After", strconv.Itoa(int(retryAfter/time.Second)))
+		code = http.StatusServiceUnavailable
+	}
+	hw.ResponseWriter.WriteHeader(code)
+}
+
+func (hw *healthWriter) Unwrap() http.ResponseWriter { return hw.ResponseWriter }
+
+type healthResponse struct {
+	Status        string    `json:"status"`
+	Since         time.Time `json:"since"`
+	Error         string    `json:"error,omitempty"`
+	ReadOnly      bool      `json:"read_only"`
+	SchemaVersion int       `json:"schema_version"`
+}
+
+// Health checks the database and reports whether it is reachable, for
+// uptime monitors and container health checks. An encrypted database that
+// can't be saved to disk is reported as "unsaved".
+func (a *API) Health(w http.ResponseWriter, r *http.Request) {
+	_ = a.store.Ping(r.Context())
+	st := a.store.Health()
+	if !st.OK {
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter/time.Second)))
+		writeJSON(w, http.StatusServiceUnavailable, healthResponse{
+			Status: "unavailable", Since: st.Since, Error: st.Error, ReadOnly: a.store.ReadOnly(),
+		})
+		return
+	}
+	if st.Unsaved != "" {
+		// Reachable, but changes are only in memory: not ready.
+		writeJSON(w, http.StatusServiceUnavailable, healthResponse{
+			Status: "unsaved", Since: st.Since, Error: st.Unsaved, ReadOnly: a.store.ReadOnly(),
+		})
+		return
+	}
+	version, err := a.store.SchemaVersion()
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jsonOK(w, healthResponse{
+		Status: "ok", Since: st.Since, ReadOnly: a.store.ReadOnly(), SchemaVersion: version,
+	})
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/hooks"
+)
+
+// WithHooks runs the dispatcher's hooks around every change made through
+// the API.
+func WithHooks(d *hooks.Dispatcher) Option {
+	return func(a *API) { a.hooks = d }
+}
+
+// withHooks turns mutating requests into hook events: validate hooks run
+// before the handler and can reject the request, notify hooks get the saved
+// record afterwards.
+func withHooks(mux *http.ServeMux, d *hooks.Dispatcher) http.Handler {
+	if d == nil {
+		return mux
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost && r.Method != http.MethodPut && r.Method != http.MethodDelete {
+			mux.ServeHTTP(w, r)
+			return
+		}
+		_, pattern := mux.Handler(r)
+		entity, action, nested, ok := hooks.EventForRoute(pattern)
+		if !ok {
+			mux.ServeHTTP(w, r)
+			return
+		}
+		ev := hooks.Event{
+			Name:   entity + "." + action,
+			Entity: entity,
+			Action: action,
+			At:     time.Now(),
+		}
+		validate := d.Wants(ev.Name, hooks.ModeValidate)
+		notify := d.Wants(ev.Name, hooks.ModeNotify)
+		if !validate && !notify {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		if id := routeID(pattern, r.URL.Path); nested {
+			ev.ParentID = id
+		} else {
+			ev.ID = id
+		}
+		if body, ok := peekJSONBody(r); ok {
+			ev.Request = body
+		}
+
+		if validate {
+			if err := d.Validate(r.Context(), ev); err != nil {
+				var rej *hooks.RejectedError
+				if errors.As(err, &rej) {
+					jsonError(w, http.StatusUnprocessableEntity, rej.Error())
+					return
+				}
+				jsonError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+		if !notify {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &bodyRecorder{ResponseWriter: w, status: http.StatusOK}
+		mux.ServeHTTP(rec, r)
+		if rec.status/100 != 2 {
+			return
+		}
+		if json.Valid(rec.body.Bytes()) && !rec.truncated {
+			ev.Record = json.RawMessage(bytes.Clone(rec.body.Bytes()))
+			if ev.ID == 0 {
+				var saved struct{ ID uint }
+				_ = json.Unmarshal(ev.Record, &saved)
+				ev.ID = saved.ID
+			}
+		}
+		notifyHook(r.Context(), d, ev)
+	})
+}
+
+// routeID pulls the {id} path value out of a request path by its position
+// in the route pattern. The mux only fills in path values for the handler
+// it dispatches to, so the middleware does it by hand.
+func routeID(pattern, path string) uint {
+	_, route, _ := strings.Cut(pattern, " ")
+	want := strings.Split(route, "/")
+	got := strings.Split(path, "/")
+	for i, seg := range want {
+		if seg == "{id}" && i < len(got) {
+			n, err := strconv.ParseUint(got[i], 10, 64)
+			if err != nil {
+				return 0
+			}
+			return uint(n)
+		}
+	}
+	return 0
+}
+
+// peekJSONBody reads a JSON request body for the hook payload and puts it
+// back for the handler. Uploads and oversized bodies are left alone.
+func peekJSONBody(r *http.Request) (json.RawMessage, bool) {
+	if r.Body == nil || r.ContentLength == 0 {
+		return nil, false
+	}
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt != "application/json" && mt != "" {
+		return nil, false
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+	if err != nil || len(body) > maxBodySize || !json.Valid(body) {
+		return nil, false
+	}
+	return body, true
+}
+
+// bodyRecorder keeps a copy of the response for notify hooks.
+type bodyRecorder struct {
+	http.ResponseWriter
+	status    int
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (br *bodyRecorder) WriteHeader(code int) {
+	br.status = code
+	br.ResponseWriter.WriteHeader(code)
+}
+
+func (br *bodyRecorder) Write(p []byte) (int, error) {
+	if br.body.Len()+len(p) <= maxBodySize {
+		br.body.Write(p)
+	} else {
+		br.truncated = true
+	}
+	return br.ResponseWriter.Write(p)
+}
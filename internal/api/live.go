@@ -0,0 +1,271 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ── Live Events ────────────────────────────────────
+
+// Browsers refresh their presence while an edit form is open; an editor
+// that stops is dropped after presenceTTL. Streams get a comment line
+// every streamKeepalive so proxies don't close them as idle.
+const (
+	presenceTTL     = 45 * time.Second
+	streamKeepalive = 25 * time.Second
+)
+
+// liveEvent is one server-sent event. Resource is an API path below
+// /api/, like "projects/3".
+type liveEvent struct {
+	Type     string   `json:"type"` // "presence" or "changed"
+	Resource string   `json:"resource"`
+	Editors  []string `json:"editors,omitempty"`
+	Method   string   `json:"method,omitempty"`
+}
+
+// liveHub tracks who is editing what and fans events out to the browsers
+// listening on /api/events.
+type liveHub struct {
+	mu      sync.Mutex
+	subs    map[chan liveEvent]struct{}
+	editing map[string]map[string]time.Time // resource → session → last seen
+	closed  bool
+}
+
+func newLiveHub() *liveHub {
+	return &liveHub{
+		subs:    make(map[chan liveEvent]struct{}),
+		editing: make(map[string]map[string]time.Time),
+	}
+}
+
+func (h *liveHub) subscribe() (chan liveEvent, []liveEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ch := make(chan liveEvent, 16)
+	if h.closed {
+		close(ch)
+		return ch, nil
+	}
+	h.subs[ch] = struct{}{}
+	h.expireLocked(time.Now())
+	snapshot := make([]liveEvent, 0, len(h.editing))
+	for res := range h.editing {
+		snapshot = append(snapshot, h.presenceLocked(res))
+	}
+	return ch, snapshot
+}
+
+func (h *liveHub) unsubscribe(ch chan liveEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[ch]; ok {
+		delete(h.subs, ch)
+		close(ch)
+	}
+}
+
+// close ends every stream, so server shutdown isn't held up by them.
+func (h *liveHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for ch := range h.subs {
+		delete(h.subs, ch)
+		close(ch)
+	}
+}
+
+// publishLocked sends ev to every listener. A listener too slow to keep
+// up misses events rather than holding up everyone else.
+func (h *liveHub) publishLocked(ev liveEvent) {
+	for ch := range h.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+func (h *liveHub) publish(ev liveEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.publishLocked(ev)
+}
+
+// setEditing records whether session has resource open for editing, and
+// announces the resource's editors when they change.
+func (h *liveHub) setEditing(resource, session string, editing bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := time.Now()
+	h.expireLocked(now)
+	sessions := h.editing[resource]
+	_, was := sessions[session]
+	if editing {
+		if sessions == nil {
+			sessions = make(map[string]time.Time)
+			h.editing[resource] = sessions
+		}
+		sessions[session] = now
+	} else {
+		delete(sessions, session)
+		if len(sessions) == 0 {
+			delete(h.editing, resource)
+		}
+	}
+	if was != editing {
+		h.publishLocked(h.presenceLocked(resource))
+	}
+}
+
+// expireLocked drops editors that have stopped refreshing.
+func (h *liveHub) expireLocked(now time.Time) {
+	for res, sessions := range h.editing {
+		changed := false
+		for s, seen := range sessions {
+			if now.Sub(seen) > presenceTTL {
+				delete(sessions, s)
+				changed = true
+			}
+		}
+		if len(sessions) == 0 {
+			delete(h.editing, res)
+		}
+		if changed {
+			h.publishLocked(h.presenceLocked(res))
+		}
+	}
+}
+
+func (h *liveHub) presenceLocked(resource string) liveEvent {
+	editors := make([]string, 0, len(h.editing[resource]))
+	for s := range h.editing[resource] {
+		editors = append(editors, s)
+	}
+	slices.Sort(editors)
+	return liveEvent{Type: "presence", Resource: resource, Editors: editors}
+}
+
+// Events streams presence and change events as server-sent events.
+func (a *API) Events(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	// The stream outlives the server's write timeout.
+	_ = rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	ch, snapshot := a.live.subscribe()
+	defer a.live.unsubscribe(ch)
+	send := func(ev liveEvent) error {
+		b, err := json.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", b); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+	for _, ev := range snapshot {
+		if send(ev) != nil {
+			return
+		}
+	}
+
+	ping := time.NewTicker(streamKeepalive)
+	defer ping.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev, ok := <-ch:
+			if !ok || send(ev) != nil {
+				return
+			}
+		case <-ping.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil || rc.Flush() != nil {
+				return
+			}
+		}
+	}
+}
+
+type presenceRequest struct {
+	Resource string `json:"resource"`
+	Session  string `json:"session"`
+	Editing  bool   `json:"editing"`
+}
+
+var (
+	liveResource = regexp.MustCompile(`^[a-z-]+/[0-9]+$|^house$`)
+	liveSession  = regexp.MustCompile(`^[A-Za-z0-9-]{8,64}$`)
+)
+
+// SetPresence records that a browser session opened or closed an edit
+// form. Sessions are random IDs made up by each browser tab.
+func (a *API) SetPresence(w http.ResponseWriter, r *http.Request) {
+	body, err := decodeBody[presenceRequest](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !liveResource.MatchString(body.Resource) || !liveSession.MatchString(body.Session) {
+		jsonError(w, http.StatusBadRequest, "invalid resource or session")
+		return
+	}
+	a.live.setEditing(body.Resource, body.Session, body.Editing)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// withLive announces successful changes to records, so open edit forms
+// can tell their copy is stale.
+func withLive(next http.Handler, hub *liveHub) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost && r.Method != http.MethodPut && r.Method != http.MethodDelete {
+			next.ServeHTTP(w, r)
+			return
+		}
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		if rec.status/100 != 2 {
+			return
+		}
+		if res, ok := changedResource(r.URL.Path); ok {
+			hub.publish(liveEvent{Type: "changed", Resource: res, Method: r.Method})
+		}
+	})
+}
+
+// changedResource turns a request path like /api/projects/3/restore into
+// the resource it changed, "projects/3".
+func changedResource(path string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, "/api/")
+	if !ok || strings.HasPrefix(rest, "admin/") || rest == "presence" {
+		return "", false
+	}
+	if rest == "house" {
+		return rest, true
+	}
+	parts := strings.SplitN(rest, "/", 3)
+	if len(parts) < 2 {
+		return "", false
+	}
+	res := parts[0] + "/" + parts[1]
+	return res, liveResource.MatchString(res)
+}
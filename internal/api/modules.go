@@ -0,0 +1,77 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// moduleResources maps each optional module to the first path segments,
+// after /api/, of the routes that change its records.
+var moduleResources = map[string][]string{
+	"appliances":   {"appliances"},
+	"incidents":    {"incidents"},
+	"devices":      {"devices"},
+	"landscape":    {"landscape"},
+	"pests":        {"pest-treatments"},
+	"water":        {"water-tests", "water-filters"},
+	"air_filters":  {"air-filters"},
+	"rooms":        {"rooms", "room-finishes"},
+	"floor_plans":  {"floor-plans"},
+	"walkthroughs": {"walkthroughs", "walkthrough-items"},
+	"inventory":    {"inventory"},
+}
+
+// WithDisabledModules turns off optional modules by name, e.g. "water". The
+// web app hides their pages and the API refuses changes to their records.
+// The records stay readable, since other pages show them.
+func WithDisabledModules(names []string) Option {
+	return func(a *API) { a.disabledModules = names }
+}
+
+// withModules refuses changes to records of a disabled module.
+func withModules(next http.Handler, disabled []string) http.Handler {
+	if len(disabled) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			if m := pathModule(r.URL.Path); m != "" && slices.Contains(disabled, m) {
+				jsonError(w, http.StatusForbidden, "the "+m+" module is turned off")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// pathModule names the optional module an API path belongs to, or "" for
+// the core ones.
+func pathModule(path string) string {
+	rest, ok := strings.CutPrefix(path, "/api/")
+	if !ok {
+		return ""
+	}
+	first, _, _ := strings.Cut(rest, "/")
+	for m, resources := range moduleResources {
+		if slices.Contains(resources, first) {
+			return m
+		}
+	}
+	return ""
+}
+
+// ── Modules ────────────────────────────────────────
+
+type modulesResponse struct {
+	Disabled []string `json:"disabled"`
+}
+
+// ListModules reports which optional modules are turned off, so the web app
+// can hide their pages.
+func (a *API) ListModules(w http.ResponseWriter, r *http.Request) {
+	jsonOK(w, modulesResponse{Disabled: append([]string{}, a.disabledModules...)})
+}
@@ -4,10 +4,16 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"net/netip"
 	"os"
+	"runtime/debug"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/cpcloud/webcasa/internal/data"
@@ -17,104 +23,492 @@ import (
 type Server struct {
 	handler http.Handler
 	store   *data.Store
+	live    *liveHub
+}
+
+// Option configures optional server behavior.
+type Option func(*API)
+
+// WithWaterLimits sets the thresholds water test results are checked
+// against. Defaults to data.DefaultWaterLimits.
+func WithWaterLimits(limits data.WaterLimits) Option {
+	return func(a *API) { a.waterLimits = limits }
+}
+
+// WithBasePath serves everything under the given URL prefix, e.g. "/casa",
+// which must already be cleaned (see config.CleanBasePath).
+func WithBasePath(prefix string) Option {
+	return func(a *API) { a.basePath = prefix }
+}
+
+// WithCORSOrigins limits the browser origins allowed to call the API. With
+// none, or "*", any origin may.
+func WithCORSOrigins(origins []string) Option {
+	return func(a *API) { a.corsOrigins = origins }
+}
+
+// WithTrustedProxies believes X-Forwarded-For from these proxies when
+// finding a client's address for logs and rate limiting, and
+// X-Forwarded-Proto when marking the session cookie Secure.
+func WithTrustedProxies(proxies []netip.Prefix) Option {
+	return func(a *API) { a.trustedProxies = proxies }
 }
 
 // NewServer creates a configured HTTP handler with all API routes and static
 // file serving. webDir is the path to the web/ directory containing
 // index.html; when empty, static serving is disabled.
-func NewServer(store *data.Store, webDir string) *Server {
+func NewServer(store *data.Store, webDir string, opts ...Option) *Server {
 	mux := http.NewServeMux()
-	a := &API{store: store}
+	a := &API{
+		store:       store,
+		waterLimits: data.DefaultWaterLimits(),
+		guard:       newAuthGuard(),
+		sessions:    newSessionStore(),
+		live:        newLiveHub(),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	a.routes(mux)
+
+	// Server-rendered pages for wall displays
+	mux.HandleFunc("GET /dashboard", a.DashboardPage)
+	mux.HandleFunc("GET /kiosk", a.KioskPage)
+
+	// House-sitter pages, opened with a stay's share link
+	mux.HandleFunc("GET /sitter/{secret}", a.SitterPage)
+	mux.HandleFunc("POST /sitter/{secret}/notes", a.LeaveSitterNote)
+	mux.HandleFunc("GET /sitter/{secret}/photos/{id}", a.SitterPhoto)
+
+	// Static files — serve web/ directory at root
+	if webDir != "" {
+		fs := http.FileServer(http.Dir(webDir))
+		mux.Handle("/", fs)
+	}
+
+	handler := withTokens(withUsers(withETags(withLive(withUsage(withModules(a.withActor(a.withHouse(withHooks(mux, a.hooks))), a.disabledModules), mux, store), a.live), mux), store, a.guard), store, a.guard)
+	handler = withHealth(handler, store)
+	if store.ReadOnly() {
+		handler = withReadOnly(handler)
+	}
+	handler = withBasePath(handler, a.basePath)
+	handler = withMiddleware(handler, a.corsOrigins, a.trustedProxies, store, a.crashes)
+	return &Server{handler: handler, store: store, live: a.live}
+}
+
+// routes registers the API's handlers on mux.
+func (a *API) routes(mux *http.ServeMux) {
+	// Health
+	mux.HandleFunc("GET /api/health", a.bind((*API).Health))
+
+	// Accounts
+	mux.HandleFunc("POST /api/auth/login", a.bind((*API).Login))
+	mux.HandleFunc("POST /api/auth/logout", a.bind((*API).Logout))
+	mux.HandleFunc("GET /api/auth/me", a.bind((*API).Me))
+	mux.HandleFunc("POST /api/auth/verify", a.bind((*API).Verify))
+	mux.HandleFunc("GET /api/auth/2fa", forSignedIn(a.bind((*API).TwoFactorStatus)))
+	mux.HandleFunc("POST /api/auth/2fa", forSignedIn(a.bind((*API).StartTwoFactor)))
+	mux.HandleFunc("POST /api/auth/2fa/confirm", forSignedIn(a.bind((*API).ConfirmTwoFactor)))
+	mux.HandleFunc("POST /api/auth/2fa/disable", forSignedIn(a.bind((*API).DisableTwoFactor)))
 
-	// House profile (singleton)
-	mux.HandleFunc("GET /api/house", a.GetHouse)
-	mux.HandleFunc("PUT /api/house", a.UpdateHouse)
+	// House profile (the current house)
+	mux.HandleFunc("GET /api/house", a.bind((*API).GetHouse))
+	mux.HandleFunc("PUT /api/house", a.bind((*API).UpdateHouse))
+
+	// Houses
+	mux.HandleFunc("GET /api/houses", a.bind((*API).ListHouses))
+	mux.HandleFunc("POST /api/houses", a.bind((*API).AddHouse))
+	mux.HandleFunc("PUT /api/houses/current", a.bind((*API).SwitchHouse))
 
 	// Dashboard
-	mux.HandleFunc("GET /api/dashboard", a.Dashboard)
+	mux.HandleFunc("GET /api/dashboard", a.bind((*API).Dashboard))
+	mux.HandleFunc("GET /api/dashboard/charts", a.bind((*API).DashboardCharts))
+
+	// Search
+	mux.HandleFunc("GET /api/search", a.bind((*API).Search))
+
+	// Chat
+	mux.HandleFunc("POST /api/chat", a.bind((*API).Ask))
+	mux.HandleFunc("GET /api/chat/history", a.bind((*API).ChatHistory))
+	mux.HandleFunc("GET /api/chat/status", a.bind((*API).ChatStatus))
+
+	// Reminder links, signed instead of signed in
+	mux.HandleFunc("GET /api/reminders/{kind}/{id}/{action}", a.bind((*API).ReminderLink))
+	mux.HandleFunc("POST /api/reminders/{kind}/{id}/{action}", a.bind((*API).ActOnReminderLink))
+
+	// This week
+	mux.HandleFunc("GET /api/week", a.bind((*API).Week))
+	mux.HandleFunc("POST /api/week/{kind}/{id}/{action}", a.bind((*API).ActOnWeekItem))
 
 	// Reference data
-	mux.HandleFunc("GET /api/project-types", a.ListProjectTypes)
-	mux.HandleFunc("GET /api/maintenance-categories", a.ListMaintenanceCategories)
+	mux.HandleFunc("GET /api/project-types", a.bind((*API).ListProjectTypes))
+	mux.HandleFunc("GET /api/maintenance-categories", a.bind((*API).ListMaintenanceCategories))
 
 	// Projects
-	mux.HandleFunc("GET /api/projects", a.ListProjects)
-	mux.HandleFunc("GET /api/projects/{id}", a.GetProject)
-	mux.HandleFunc("POST /api/projects", a.CreateProject)
-	mux.HandleFunc("PUT /api/projects/{id}", a.UpdateProject)
-	mux.HandleFunc("DELETE /api/projects/{id}", a.DeleteProject)
-	mux.HandleFunc("POST /api/projects/{id}/restore", a.RestoreProject)
-	mux.HandleFunc("GET /api/projects/{id}/quotes", a.ListQuotesByProject)
+	mux.HandleFunc("GET /api/projects", a.bind((*API).ListProjects))
+	mux.HandleFunc("GET /api/projects/stale", a.bind((*API).ListStaleProjects))
+	mux.HandleFunc("GET /api/projects/{id}", a.bind((*API).GetProject))
+	mux.HandleFunc("POST /api/projects", a.bind((*API).CreateProject))
+	mux.HandleFunc("PUT /api/projects/{id}", a.bind((*API).UpdateProject))
+	mux.HandleFunc("DELETE /api/projects/{id}", a.bind((*API).DeleteProject))
+	mux.HandleFunc("POST /api/projects/{id}/restore", a.requireFreshCode(a.bind((*API).RestoreProject)))
+	mux.HandleFunc("POST /api/projects/{id}/bump", a.bind((*API).BumpProject))
+	mux.HandleFunc("POST /api/projects/{id}/status", a.bind((*API).SetProjectStatus))
+	mux.HandleFunc("GET /api/status-reasons", a.bind((*API).ListStatusReasons))
+	mux.HandleFunc("GET /api/projects/{id}/tasks", a.bind((*API).ListProjectTasks))
+	mux.HandleFunc("POST /api/projects/{id}/tasks", a.bind((*API).CreateProjectTask))
+	mux.HandleFunc("PUT /api/projects/{id}/tasks/order", a.bind((*API).ReorderProjectTasks))
+	mux.HandleFunc("GET /api/project-tasks/{id}", a.bind((*API).GetProjectTask))
+	mux.HandleFunc("PUT /api/project-tasks/{id}", a.bind((*API).UpdateProjectTask))
+	mux.HandleFunc("DELETE /api/project-tasks/{id}", a.bind((*API).DeleteProjectTask))
+	mux.HandleFunc("GET /api/projects/{id}/quotes", a.bind((*API).ListQuotesByProject))
+	mux.HandleFunc("GET /api/projects/{id}/quote-comparison", a.bind((*API).CompareQuotes))
+	mux.HandleFunc("GET /api/projects/{id}/estimates", a.bind((*API).ListMaterialEstimates))
+	mux.HandleFunc("POST /api/projects/{id}/estimates", a.bind((*API).CreateMaterialEstimate))
+	mux.HandleFunc("GET /api/projects/{id}/bid-request", a.bind((*API).BidRequestDocument))
+	mux.HandleFunc("GET /api/projects/{id}/bid-requests", a.bind((*API).ListBidRequests))
+	mux.HandleFunc("POST /api/projects/{id}/bid-requests", a.bind((*API).RecordBidRequests))
+	mux.HandleFunc("PUT /api/bid-requests/{id}", a.bind((*API).CloseBidRequest))
+	mux.HandleFunc("DELETE /api/bid-requests/{id}", a.bind((*API).RemoveBidRequest))
+	mux.HandleFunc("GET /api/projects/{id}/financials", a.bind((*API).ProjectFinancials))
+	mux.HandleFunc("GET /api/projects/{id}/budget-revisions", a.bind((*API).BudgetRevisions))
+	mux.HandleFunc("GET /api/projects/{id}/change-orders", a.bind((*API).ListChangeOrders))
+	mux.HandleFunc("POST /api/projects/{id}/change-orders", a.bind((*API).CreateChangeOrder))
+	mux.HandleFunc("PUT /api/change-orders/{id}", a.bind((*API).UpdateChangeOrder))
+	mux.HandleFunc("DELETE /api/change-orders/{id}", a.bind((*API).RemoveChangeOrder))
+	mux.HandleFunc("GET /api/projects/{id}/invoices", a.bind((*API).ListInvoices))
+	mux.HandleFunc("POST /api/projects/{id}/invoices", a.bind((*API).CreateInvoice))
+	mux.HandleFunc("PUT /api/invoices/{id}", a.bind((*API).UpdateInvoice))
+	mux.HandleFunc("DELETE /api/invoices/{id}", a.bind((*API).RemoveInvoice))
+
+	// Expenses
+	mux.HandleFunc("GET /api/expenses", a.bind((*API).ListExpenses))
+	mux.HandleFunc("GET /api/expenses/categories", a.bind((*API).ListExpenseCategories))
+	mux.HandleFunc("GET /api/expenses/{id}", a.bind((*API).GetExpense))
+	mux.HandleFunc("POST /api/expenses", a.bind((*API).CreateExpense))
+	mux.HandleFunc("PUT /api/expenses/{id}", a.bind((*API).UpdateExpense))
+	mux.HandleFunc("DELETE /api/expenses/{id}", a.bind((*API).DeleteExpense))
+	mux.HandleFunc("POST /api/expenses/{id}/restore", a.requireFreshCode(a.bind((*API).RestoreExpense)))
+	mux.HandleFunc("GET /api/spending", a.bind((*API).Spending))
+
+	// Warranties
+	mux.HandleFunc("GET /api/warranties", a.bind((*API).ListWarranties))
+	mux.HandleFunc("GET /api/warranties/expiring", a.bind((*API).ListExpiringWarranties))
+	mux.HandleFunc("GET /api/warranties/{id}", a.bind((*API).GetWarranty))
+	mux.HandleFunc("POST /api/warranties", a.bind((*API).CreateWarranty))
+	mux.HandleFunc("PUT /api/warranties/{id}", a.bind((*API).UpdateWarranty))
+	mux.HandleFunc("DELETE /api/warranties/{id}", a.bind((*API).DeleteWarranty))
+	mux.HandleFunc("POST /api/warranties/{id}/restore", a.requireFreshCode(a.bind((*API).RestoreWarranty)))
+
+	// Inventory
+	mux.HandleFunc("GET /api/inventory", a.bind((*API).ListInventory))
+	mux.HandleFunc("GET /api/inventory/rooms", a.bind((*API).InventoryByRoom))
+	mux.HandleFunc("GET /api/inventory/report", a.bind((*API).InventoryReport))
+	mux.HandleFunc("GET /api/inventory/{id}", a.bind((*API).GetInventoryItem))
+	mux.HandleFunc("POST /api/inventory", a.bind((*API).CreateInventoryItem))
+	mux.HandleFunc("PUT /api/inventory/{id}", a.bind((*API).UpdateInventoryItem))
+	mux.HandleFunc("DELETE /api/inventory/{id}", a.bind((*API).DeleteInventoryItem))
+	mux.HandleFunc("POST /api/inventory/{id}/restore", a.requireFreshCode(a.bind((*API).RestoreInventoryItem)))
+
+	// Contacts
+	mux.HandleFunc("GET /api/contacts", a.bind((*API).ListContacts))
+	mux.HandleFunc("GET /api/contact-roles", a.bind((*API).ListContactRoles))
+	mux.HandleFunc("GET /api/contacts/{id}", a.bind((*API).GetContact))
+	mux.HandleFunc("POST /api/contacts", a.bind((*API).CreateContact))
+	mux.HandleFunc("PUT /api/contacts/{id}", a.bind((*API).UpdateContact))
+	mux.HandleFunc("DELETE /api/contacts/{id}", a.bind((*API).DeleteContact))
+	mux.HandleFunc("POST /api/contacts/{id}/restore", a.requireFreshCode(a.bind((*API).RestoreContact)))
+
+	// Utility bills
+	mux.HandleFunc("GET /api/utilities", a.bind((*API).ListUtilityBills))
+	mux.HandleFunc("GET /api/utilities/trends", a.bind((*API).UtilityTrends))
+	mux.HandleFunc("GET /api/utility-types", a.bind((*API).ListUtilityTypes))
+	mux.HandleFunc("GET /api/utilities/{id}", a.bind((*API).GetUtilityBill))
+	mux.HandleFunc("POST /api/utilities", a.bind((*API).CreateUtilityBill))
+	mux.HandleFunc("PUT /api/utilities/{id}", a.bind((*API).UpdateUtilityBill))
+	mux.HandleFunc("DELETE /api/utilities/{id}", a.bind((*API).DeleteUtilityBill))
+	mux.HandleFunc("POST /api/utilities/{id}/restore", a.requireFreshCode(a.bind((*API).RestoreUtilityBill)))
+
+	// Rebates
+	mux.HandleFunc("GET /api/rebates", a.bind((*API).ListRebates))
+	mux.HandleFunc("GET /api/rebates/due", a.bind((*API).ListRebatesDue))
+	mux.HandleFunc("GET /api/rebates/{id}", a.bind((*API).GetRebate))
+	mux.HandleFunc("POST /api/rebates", a.bind((*API).CreateRebate))
+	mux.HandleFunc("PUT /api/rebates/{id}", a.bind((*API).UpdateRebate))
+	mux.HandleFunc("DELETE /api/rebates/{id}", a.bind((*API).DeleteRebate))
+	mux.HandleFunc("POST /api/rebates/{id}/restore", a.requireFreshCode(a.bind((*API).RestoreRebate)))
+
+	// House sitters
+	mux.HandleFunc("GET /api/sitter-stays", a.bind((*API).ListSitterStays))
+	mux.HandleFunc("POST /api/sitter-stays", a.bind((*API).CreateSitterStay))
+	mux.HandleFunc("POST /api/sitter-stays/{id}/revoke", a.bind((*API).RevokeSitterStay))
+	mux.HandleFunc("DELETE /api/sitter-stays/{id}", a.bind((*API).DeleteSitterStay))
 
 	// Quotes
-	mux.HandleFunc("GET /api/quotes", a.ListQuotes)
-	mux.HandleFunc("GET /api/quotes/{id}", a.GetQuote)
-	mux.HandleFunc("POST /api/quotes", a.CreateQuote)
-	mux.HandleFunc("PUT /api/quotes/{id}", a.UpdateQuote)
-	mux.HandleFunc("DELETE /api/quotes/{id}", a.DeleteQuote)
-	mux.HandleFunc("POST /api/quotes/{id}/restore", a.RestoreQuote)
+	mux.HandleFunc("GET /api/quotes", a.bind((*API).ListQuotes))
+	mux.HandleFunc("GET /api/quotes/{id}", a.bind((*API).GetQuote))
+	mux.HandleFunc("POST /api/quotes", a.bind((*API).CreateQuote))
+	mux.HandleFunc("PUT /api/quotes/{id}", a.bind((*API).UpdateQuote))
+	mux.HandleFunc("DELETE /api/quotes/{id}", a.bind((*API).DeleteQuote))
+	mux.HandleFunc("POST /api/quotes/{id}/restore", a.requireFreshCode(a.bind((*API).RestoreQuote)))
+
+	// Reports
+	mux.HandleFunc("GET /api/reports/cycle-times", a.bind((*API).CycleTimes))
+	mux.HandleFunc("GET /api/reports/slip-reasons", a.bind((*API).SlipReasons))
 
 	// Vendors
-	mux.HandleFunc("GET /api/vendors", a.ListVendors)
-	mux.HandleFunc("GET /api/vendors/{id}", a.GetVendor)
-	mux.HandleFunc("POST /api/vendors", a.CreateVendor)
-	mux.HandleFunc("PUT /api/vendors/{id}", a.UpdateVendor)
-	mux.HandleFunc("DELETE /api/vendors/{id}", a.DeleteVendor)
-	mux.HandleFunc("POST /api/vendors/{id}/restore", a.RestoreVendor)
-	mux.HandleFunc("GET /api/vendors/{id}/quotes", a.ListQuotesByVendor)
-	mux.HandleFunc("GET /api/vendors/{id}/service-logs", a.ListServiceLogsByVendor)
+	mux.HandleFunc("GET /api/vendors", a.bind((*API).ListVendors))
+	mux.HandleFunc("GET /api/vendors/analytics", a.bind((*API).VendorAnalytics))
+	mux.HandleFunc("GET /api/vendors/{id}", a.bind((*API).GetVendor))
+	mux.HandleFunc("POST /api/vendors", a.bind((*API).CreateVendor))
+	mux.HandleFunc("PUT /api/vendors/{id}", a.bind((*API).UpdateVendor))
+	mux.HandleFunc("DELETE /api/vendors/{id}", a.bind((*API).DeleteVendor))
+	mux.HandleFunc("POST /api/vendors/{id}/restore", a.requireFreshCode(a.bind((*API).RestoreVendor)))
+	mux.HandleFunc("GET /api/vendors/{id}/quotes", a.bind((*API).ListQuotesByVendor))
+	mux.HandleFunc("GET /api/vendors/{id}/service-logs", a.bind((*API).ListServiceLogsByVendor))
 
 	// Maintenance
-	mux.HandleFunc("GET /api/maintenance", a.ListMaintenance)
-	mux.HandleFunc("GET /api/maintenance/{id}", a.GetMaintenance)
-	mux.HandleFunc("POST /api/maintenance", a.CreateMaintenance)
-	mux.HandleFunc("PUT /api/maintenance/{id}", a.UpdateMaintenance)
-	mux.HandleFunc("DELETE /api/maintenance/{id}", a.DeleteMaintenance)
-	mux.HandleFunc("POST /api/maintenance/{id}/restore", a.RestoreMaintenance)
-	mux.HandleFunc("GET /api/maintenance/{id}/service-logs", a.ListServiceLogs)
-	mux.HandleFunc("POST /api/maintenance/{id}/service-logs", a.CreateServiceLog)
+	mux.HandleFunc("GET /api/maintenance", a.bind((*API).ListMaintenance))
+	mux.HandleFunc("GET /api/maintenance/due", a.bind((*API).ListMaintenanceDue))
+	mux.HandleFunc("GET /api/calendar.ics", a.bind((*API).Calendar))
+	mux.HandleFunc("GET /api/maintenance/overdue", a.bind((*API).ListMaintenanceOverdue))
+	mux.HandleFunc("GET /api/maintenance/{id}", a.bind((*API).GetMaintenance))
+	mux.HandleFunc("POST /api/maintenance", a.bind((*API).CreateMaintenance))
+	mux.HandleFunc("PUT /api/maintenance/{id}", a.bind((*API).UpdateMaintenance))
+	mux.HandleFunc("DELETE /api/maintenance/{id}", a.bind((*API).DeleteMaintenance))
+	mux.HandleFunc("POST /api/maintenance/{id}/restore", a.requireFreshCode(a.bind((*API).RestoreMaintenance)))
+	mux.HandleFunc("GET /api/maintenance/{id}/service-logs", a.bind((*API).ListServiceLogs))
+	mux.HandleFunc("POST /api/maintenance/{id}/service-logs", a.bind((*API).CreateServiceLog))
 
 	// Service logs
-	mux.HandleFunc("GET /api/service-logs/{id}", a.GetServiceLog)
-	mux.HandleFunc("PUT /api/service-logs/{id}", a.UpdateServiceLog)
-	mux.HandleFunc("DELETE /api/service-logs/{id}", a.DeleteServiceLog)
-	mux.HandleFunc("POST /api/service-logs/{id}/restore", a.RestoreServiceLog)
+	mux.HandleFunc("GET /api/service-logs", a.bind((*API).ListAllServiceLogs))
+	mux.HandleFunc("GET /api/service-logs/{id}", a.bind((*API).GetServiceLog))
+	mux.HandleFunc("PUT /api/service-logs/{id}", a.bind((*API).UpdateServiceLog))
+	mux.HandleFunc("DELETE /api/service-logs/{id}", a.bind((*API).DeleteServiceLog))
+	mux.HandleFunc("POST /api/service-logs/{id}/restore", a.requireFreshCode(a.bind((*API).RestoreServiceLog)))
 
 	// Appliances
-	mux.HandleFunc("GET /api/appliances", a.ListAppliances)
-	mux.HandleFunc("GET /api/appliances/{id}", a.GetAppliance)
-	mux.HandleFunc("POST /api/appliances", a.CreateAppliance)
-	mux.HandleFunc("PUT /api/appliances/{id}", a.UpdateAppliance)
-	mux.HandleFunc("DELETE /api/appliances/{id}", a.DeleteAppliance)
-	mux.HandleFunc("POST /api/appliances/{id}/restore", a.RestoreAppliance)
-	mux.HandleFunc("GET /api/appliances/{id}/maintenance", a.ListMaintenanceByAppliance)
+	mux.HandleFunc("GET /api/appliances", a.bind((*API).ListAppliances))
+	mux.HandleFunc("GET /api/appliances/{id}", a.bind((*API).GetAppliance))
+	mux.HandleFunc("POST /api/appliances", a.bind((*API).CreateAppliance))
+	mux.HandleFunc("PUT /api/appliances/{id}", a.bind((*API).UpdateAppliance))
+	mux.HandleFunc("DELETE /api/appliances/{id}", a.bind((*API).DeleteAppliance))
+	mux.HandleFunc("POST /api/appliances/{id}/restore", a.requireFreshCode(a.bind((*API).RestoreAppliance)))
+	mux.HandleFunc("GET /api/appliances/{id}/maintenance", a.bind((*API).ListMaintenanceByAppliance))
+	mux.HandleFunc("GET /api/appliances/{id}/guest-card", a.bind((*API).GuestCard))
+	mux.HandleFunc("POST /api/appliances/{id}/guest-card/draft", a.bind((*API).DraftGuestInstructions))
+	mux.HandleFunc("GET /api/appliances/{id}/service-history", a.bind((*API).ServiceHistory))
+	mux.HandleFunc("GET /api/appliances/{id}/warranty-claims", a.bind((*API).ListApplianceWarrantyClaims))
 
 	// Incidents
-	mux.HandleFunc("GET /api/incidents", a.ListIncidents)
-	mux.HandleFunc("GET /api/incidents/{id}", a.GetIncident)
-	mux.HandleFunc("POST /api/incidents", a.CreateIncident)
-	mux.HandleFunc("PUT /api/incidents/{id}", a.UpdateIncident)
-	mux.HandleFunc("DELETE /api/incidents/{id}", a.DeleteIncident)
-	mux.HandleFunc("POST /api/incidents/{id}/restore", a.RestoreIncident)
+	mux.HandleFunc("GET /api/incidents", a.bind((*API).ListIncidents))
+	mux.HandleFunc("GET /api/incidents/{id}", a.bind((*API).GetIncident))
+	mux.HandleFunc("POST /api/incidents", a.bind((*API).CreateIncident))
+	mux.HandleFunc("PUT /api/incidents/{id}", a.bind((*API).UpdateIncident))
+	mux.HandleFunc("DELETE /api/incidents/{id}", a.bind((*API).DeleteIncident))
+	mux.HandleFunc("POST /api/incidents/{id}/restore", a.requireFreshCode(a.bind((*API).RestoreIncident)))
 
 	// Documents
-	mux.HandleFunc("GET /api/documents", a.ListDocuments)
-	mux.HandleFunc("GET /api/documents/{id}", a.GetDocument)
-	mux.HandleFunc("GET /api/documents/{id}/download", a.DownloadDocument)
-	mux.HandleFunc("POST /api/documents", a.UploadDocument)
-	mux.HandleFunc("PUT /api/documents/{id}", a.UpdateDocument)
-	mux.HandleFunc("DELETE /api/documents/{id}", a.DeleteDocument)
-	mux.HandleFunc("POST /api/documents/{id}/restore", a.RestoreDocument)
-	mux.HandleFunc("GET /api/documents/by/{kind}/{eid}", a.ListDocumentsByEntity)
+	mux.HandleFunc("GET /api/documents", a.bind((*API).ListDocuments))
+	mux.HandleFunc("GET /api/documents/duplicates", a.bind((*API).ListDuplicateDocuments))
+	mux.HandleFunc("POST /api/documents/duplicates/merge", a.bind((*API).MergeDuplicateDocuments))
+	mux.HandleFunc("GET /api/documents/{id}", a.bind((*API).GetDocument))
+	mux.HandleFunc("GET /api/documents/{id}/download", a.bind((*API).DownloadDocument))
+	mux.HandleFunc("GET /api/documents/{id}/content", a.bind((*API).DocumentContent))
+	mux.HandleFunc("GET /api/documents/{id}/thumbnail", a.bind((*API).DocumentThumbnail))
+	mux.HandleFunc("POST /api/documents", a.bind((*API).UploadDocument))
+	mux.HandleFunc("PUT /api/documents/{id}", a.bind((*API).UpdateDocument))
+	mux.HandleFunc("DELETE /api/documents/{id}", a.bind((*API).DeleteDocument))
+	mux.HandleFunc("POST /api/documents/{id}/restore", a.requireFreshCode(a.bind((*API).RestoreDocument)))
+	mux.HandleFunc("POST /api/documents/{id}/keep", a.bind((*API).KeepBestShot))
+	mux.HandleFunc("GET /api/documents/by/{kind}/{eid}", a.bind((*API).ListDocumentsByEntity))
+	mux.HandleFunc("GET /api/documents/by/{kind}/{eid}/bursts", a.bind((*API).ListPhotoBursts))
 
-	// Static files — serve web/ directory at root
-	if webDir != "" {
-		fs := http.FileServer(http.Dir(webDir))
-		mux.Handle("/", fs)
+	// Smart devices
+	mux.HandleFunc("GET /api/devices", a.bind((*API).ListSmartDevices))
+	mux.HandleFunc("GET /api/devices/discover", a.bind((*API).DiscoverSmartDevices))
+	mux.HandleFunc("GET /api/devices/{id}", a.bind((*API).GetSmartDevice))
+	mux.HandleFunc("POST /api/devices", a.bind((*API).CreateSmartDevice))
+	mux.HandleFunc("PUT /api/devices/{id}", a.bind((*API).UpdateSmartDevice))
+	mux.HandleFunc("DELETE /api/devices/{id}", a.bind((*API).DeleteSmartDevice))
+	mux.HandleFunc("POST /api/devices/{id}/restore", a.requireFreshCode(a.bind((*API).RestoreSmartDevice)))
+	mux.HandleFunc("POST /api/devices/{id}/battery", a.bind((*API).RecordBatteryChange))
+
+	// Landscape assets
+	mux.HandleFunc("GET /api/landscape-kinds", a.bind((*API).ListLandscapeKinds))
+	mux.HandleFunc("GET /api/landscape", a.bind((*API).ListLandscapeAssets))
+	mux.HandleFunc("GET /api/landscape/{id}", a.bind((*API).GetLandscapeAsset))
+	mux.HandleFunc("POST /api/landscape", a.bind((*API).CreateLandscapeAsset))
+	mux.HandleFunc("PUT /api/landscape/{id}", a.bind((*API).UpdateLandscapeAsset))
+	mux.HandleFunc("DELETE /api/landscape/{id}", a.bind((*API).DeleteLandscapeAsset))
+	mux.HandleFunc("POST /api/landscape/{id}/restore", a.requireFreshCode(a.bind((*API).RestoreLandscapeAsset)))
+	mux.HandleFunc("GET /api/landscape/{id}/maintenance", a.bind((*API).ListMaintenanceByLandscapeAsset))
+	mux.HandleFunc("POST /api/landscape/{id}/maintenance", a.bind((*API).CreateLandscapeCare))
+
+	// Pest treatments
+	mux.HandleFunc("GET /api/pest-treatments", a.bind((*API).ListPestTreatments))
+	mux.HandleFunc("GET /api/pest-treatments/last", a.bind((*API).LastPestTreatment))
+	mux.HandleFunc("GET /api/pest-treatments/due", a.bind((*API).ListPestRetreatmentsDue))
+	mux.HandleFunc("GET /api/pest-treatments/{id}", a.bind((*API).GetPestTreatment))
+	mux.HandleFunc("POST /api/pest-treatments", a.bind((*API).CreatePestTreatment))
+	mux.HandleFunc("PUT /api/pest-treatments/{id}", a.bind((*API).UpdatePestTreatment))
+	mux.HandleFunc("DELETE /api/pest-treatments/{id}", a.bind((*API).DeletePestTreatment))
+	mux.HandleFunc("POST /api/pest-treatments/{id}/restore", a.requireFreshCode(a.bind((*API).RestorePestTreatment)))
+
+	// Water quality
+	mux.HandleFunc("GET /api/water-tests", a.bind((*API).ListWaterTests))
+	mux.HandleFunc("GET /api/water-tests/alerts", a.bind((*API).ListWaterAlerts))
+	mux.HandleFunc("GET /api/water-tests/{id}", a.bind((*API).GetWaterTest))
+	mux.HandleFunc("POST /api/water-tests", a.bind((*API).CreateWaterTest))
+	mux.HandleFunc("PUT /api/water-tests/{id}", a.bind((*API).UpdateWaterTest))
+	mux.HandleFunc("DELETE /api/water-tests/{id}", a.bind((*API).DeleteWaterTest))
+	mux.HandleFunc("POST /api/water-tests/{id}/restore", a.requireFreshCode(a.bind((*API).RestoreWaterTest)))
+	mux.HandleFunc("GET /api/water-filters", a.bind((*API).ListWaterFilterChanges))
+	mux.HandleFunc("GET /api/water-filters/{id}", a.bind((*API).GetWaterFilterChange))
+	mux.HandleFunc("POST /api/water-filters", a.bind((*API).CreateWaterFilterChange))
+	mux.HandleFunc("PUT /api/water-filters/{id}", a.bind((*API).UpdateWaterFilterChange))
+	mux.HandleFunc("DELETE /api/water-filters/{id}", a.bind((*API).DeleteWaterFilterChange))
+	mux.HandleFunc("POST /api/water-filters/{id}/restore", a.requireFreshCode(a.bind((*API).RestoreWaterFilterChange)))
+	mux.HandleFunc("GET /api/appliances/{id}/water-filters", a.bind((*API).ListWaterFilterChangesByAppliance))
+
+	// Air filters
+	mux.HandleFunc("GET /api/air-filters", a.bind((*API).ListAirFilterSpecs))
+	mux.HandleFunc("GET /api/air-filters/suggestions", a.bind((*API).ListAirFilterSuggestions))
+	mux.HandleFunc("GET /api/air-filters/{id}", a.bind((*API).GetAirFilterSpec))
+	mux.HandleFunc("POST /api/air-filters", a.bind((*API).CreateAirFilterSpec))
+	mux.HandleFunc("PUT /api/air-filters/{id}", a.bind((*API).UpdateAirFilterSpec))
+	mux.HandleFunc("DELETE /api/air-filters/{id}", a.bind((*API).DeleteAirFilterSpec))
+	mux.HandleFunc("POST /api/air-filters/{id}/restore", a.requireFreshCode(a.bind((*API).RestoreAirFilterSpec)))
+	mux.HandleFunc("POST /api/air-filters/{id}/change", a.bind((*API).RecordAirFilterChange))
+
+	// Rooms and material estimates
+	mux.HandleFunc("GET /api/rooms", a.bind((*API).ListRooms))
+	mux.HandleFunc("GET /api/rooms/{id}", a.bind((*API).GetRoom))
+	mux.HandleFunc("POST /api/rooms", a.bind((*API).CreateRoom))
+	mux.HandleFunc("PUT /api/rooms/{id}", a.bind((*API).UpdateRoom))
+	mux.HandleFunc("DELETE /api/rooms/{id}", a.bind((*API).DeleteRoom))
+	mux.HandleFunc("POST /api/rooms/{id}/restore", a.requireFreshCode(a.bind((*API).RestoreRoom)))
+	mux.HandleFunc("GET /api/rooms/{id}/drilldown", a.bind((*API).GetRoomDrilldown))
+	mux.HandleFunc("GET /api/rooms/{id}/finishes", a.bind((*API).ListRoomFinishes))
+	mux.HandleFunc("POST /api/rooms/{id}/finishes", a.bind((*API).CreateRoomFinish))
+	mux.HandleFunc("PUT /api/room-finishes/{id}", a.bind((*API).UpdateRoomFinish))
+	mux.HandleFunc("DELETE /api/room-finishes/{id}", a.bind((*API).DeleteRoomFinish))
+	mux.HandleFunc("POST /api/room-finishes/{id}/restore", a.requireFreshCode(a.bind((*API).RestoreRoomFinish)))
+	mux.HandleFunc("POST /api/estimates/preview", a.bind((*API).PreviewMaterialEstimate))
+	mux.HandleFunc("DELETE /api/estimates/{id}", a.bind((*API).DeleteMaterialEstimate))
+	mux.HandleFunc("POST /api/estimates/{id}/restore", a.requireFreshCode(a.bind((*API).RestoreMaterialEstimate)))
+
+	// Floor plans
+	mux.HandleFunc("GET /api/floor-plans", a.bind((*API).ListFloorPlans))
+	mux.HandleFunc("GET /api/floor-plans/{id}", a.bind((*API).GetFloorPlan))
+	mux.HandleFunc("GET /api/floor-plans/{id}/image", a.bind((*API).FloorPlanImage))
+	mux.HandleFunc("POST /api/floor-plans", a.bind((*API).UploadFloorPlan))
+	mux.HandleFunc("PUT /api/floor-plans/{id}", a.bind((*API).UpdateFloorPlan))
+	mux.HandleFunc("PUT /api/floor-plans/{id}/hotspots", a.bind((*API).SetFloorPlanHotspots))
+	mux.HandleFunc("DELETE /api/floor-plans/{id}", a.bind((*API).DeleteFloorPlan))
+	mux.HandleFunc("POST /api/floor-plans/{id}/restore", a.requireFreshCode(a.bind((*API).RestoreFloorPlan)))
+
+	// Walkthroughs
+	mux.HandleFunc("GET /api/walkthroughs", a.bind((*API).ListWalkthroughs))
+	mux.HandleFunc("GET /api/walkthroughs/compare", a.bind((*API).CompareWalkthroughs))
+	mux.HandleFunc("GET /api/walkthroughs/{id}", a.bind((*API).GetWalkthrough))
+	mux.HandleFunc("POST /api/walkthroughs", a.bind((*API).CreateWalkthrough))
+	mux.HandleFunc("PUT /api/walkthroughs/{id}", a.bind((*API).UpdateWalkthrough))
+	mux.HandleFunc("DELETE /api/walkthroughs/{id}", a.bind((*API).DeleteWalkthrough))
+	mux.HandleFunc("POST /api/walkthroughs/{id}/restore", a.requireFreshCode(a.bind((*API).RestoreWalkthrough)))
+	mux.HandleFunc("POST /api/walkthroughs/{id}/items", a.bind((*API).AddWalkthroughItem))
+	mux.HandleFunc("DELETE /api/walkthrough-items/{id}", a.bind((*API).RemoveWalkthroughItem))
+
+	// House events and their checklists
+	mux.HandleFunc("GET /api/house-events", a.bind((*API).ListHouseEvents))
+	mux.HandleFunc("GET /api/house-events/{id}", a.bind((*API).GetHouseEvent))
+	mux.HandleFunc("POST /api/house-events", a.bind((*API).CreateHouseEvent))
+	mux.HandleFunc("PUT /api/house-events/{id}", a.bind((*API).UpdateHouseEvent))
+	mux.HandleFunc("DELETE /api/house-events/{id}", a.bind((*API).DeleteHouseEvent))
+	mux.HandleFunc("POST /api/house-events/{id}/restore", a.requireFreshCode(a.bind((*API).RestoreHouseEvent)))
+	mux.HandleFunc("POST /api/house-events/{id}/tasks", a.bind((*API).AddHouseEventTask))
+	mux.HandleFunc("PUT /api/house-event-tasks/{id}", a.bind((*API).SetHouseEventTaskDone))
+	mux.HandleFunc("DELETE /api/house-event-tasks/{id}", a.bind((*API).RemoveHouseEventTask))
+
+	// Warranty claims and their correspondence
+	mux.HandleFunc("GET /api/warranty-claims", a.bind((*API).ListWarrantyClaims))
+	mux.HandleFunc("GET /api/warranty-claims/{id}", a.bind((*API).GetWarrantyClaim))
+	mux.HandleFunc("POST /api/warranty-claims", a.bind((*API).CreateWarrantyClaim))
+	mux.HandleFunc("PUT /api/warranty-claims/{id}", a.bind((*API).UpdateWarrantyClaim))
+	mux.HandleFunc("DELETE /api/warranty-claims/{id}", a.bind((*API).DeleteWarrantyClaim))
+	mux.HandleFunc("POST /api/warranty-claims/{id}/restore", a.requireFreshCode(a.bind((*API).RestoreWarrantyClaim)))
+	mux.HandleFunc("GET /api/warranty-claims/{id}/letter", a.bind((*API).ClaimLetter))
+	mux.HandleFunc("GET /api/warranty-claims/{id}/packet", a.bind((*API).ClaimPacket))
+	mux.HandleFunc("POST /api/warranty-claims/{id}/correspondence", a.bind((*API).AddClaimCorrespondence))
+	mux.HandleFunc("DELETE /api/claim-correspondence/{id}", a.bind((*API).RemoveClaimCorrespondence))
+
+	// Live events
+	mux.HandleFunc("GET /api/events", a.bind((*API).Events))
+	mux.HandleFunc("POST /api/presence", a.bind((*API).SetPresence))
+
+	// Usage counters
+	mux.HandleFunc("POST /api/usage", a.bind((*API).RecordUsage))
+
+	// Optional modules
+	mux.HandleFunc("GET /api/modules", a.bind((*API).ListModules))
+
+	// Field history
+	mux.HandleFunc("GET /api/history/{entity}/{eid}", a.bind((*API).ListFieldHistory))
+	mux.HandleFunc("POST /api/history/{id}/revert", a.bind((*API).RevertField))
+	mux.HandleFunc("GET /api/audit/{entity}/{eid}", a.bind((*API).ListAuditTrail))
+	mux.HandleFunc("GET /api/undo-log", a.bind((*API).ListUndoLog))
+	mux.HandleFunc("POST /api/undo", a.bind((*API).Undo))
+	mux.HandleFunc("POST /api/redo", a.bind((*API).Redo))
+
+	// Batches
+	mux.HandleFunc("POST /api/batch", a.bind((*API).Batch))
+
+	// Spreadsheet import
+	mux.HandleFunc("GET /api/import/{kind}", a.bind((*API).ListSheetFields))
+	mux.HandleFunc("POST /api/import/{kind}", a.bind((*API).ImportSheet))
+
+	// Custom fields
+	mux.HandleFunc("GET /api/custom-fields", a.bind((*API).ListCustomFields))
+	mux.HandleFunc("POST /api/custom-fields", a.bind((*API).CreateCustomField))
+	mux.HandleFunc("PUT /api/custom-fields/{id}", a.bind((*API).UpdateCustomField))
+	mux.HandleFunc("DELETE /api/custom-fields/{id}", a.bind((*API).DeleteCustomField))
+
+	// Tags
+	mux.HandleFunc("GET /api/tags", a.bind((*API).ListTags))
+	mux.HandleFunc("POST /api/tags", a.bind((*API).CreateTag))
+	mux.HandleFunc("PUT /api/tags/{id}", a.bind((*API).RenameTag))
+	mux.HandleFunc("DELETE /api/tags/{id}", a.bind((*API).DeleteTag))
+	mux.HandleFunc("PUT /api/projects/{id}/tags", a.setTagsOf(data.DocumentEntityProject))
+	mux.HandleFunc("PUT /api/vendors/{id}/tags", a.setTagsOf(data.DocumentEntityVendor))
+	mux.HandleFunc("PUT /api/maintenance/{id}/tags", a.setTagsOf(data.DocumentEntityMaintenance))
+	mux.HandleFunc("PUT /api/appliances/{id}/tags", a.setTagsOf(data.DocumentEntityAppliance))
+	mux.HandleFunc("PUT /api/documents/{id}/tags", a.setTagsOf(data.DeletionEntityDocument))
+
+	// GraphQL, when enabled
+	if a.graphql != nil {
+		mux.HandleFunc("GET /api/graphql", a.bind((*API).GraphQL))
+		mux.HandleFunc("POST /api/graphql", a.bind((*API).GraphQL))
 	}
 
-	handler := withMiddleware(mux)
-	return &Server{handler: handler, store: store}
+	// Admin
+	mux.HandleFunc("POST /api/admin/login", a.bind((*API).AdminLogin))
+	mux.HandleFunc("POST /api/admin/logout", a.bind((*API).AdminLogout))
+	mux.HandleFunc("GET /api/admin/overview", a.requireAdmin(a.bind((*API).AdminOverview)))
+	mux.HandleFunc("POST /api/admin/backup", a.requireAdmin(a.bind((*API).AdminBackup)))
+	mux.HandleFunc("POST /api/admin/jobs/{name}/run", a.requireAdmin(a.bind((*API).AdminRunJob)))
+	mux.HandleFunc("GET /api/admin/deletions", a.requireAdmin(a.bind((*API).AdminDeletions)))
+	mux.HandleFunc("GET /api/admin/users", a.requireAdmin(a.bind((*API).ListUsers)))
+	mux.HandleFunc("POST /api/admin/users", a.requireAdmin(a.bind((*API).CreateUser)))
+	mux.HandleFunc("DELETE /api/admin/users/{name}", a.requireAdmin(a.bind((*API).RemoveUser)))
+	mux.HandleFunc("GET /api/admin/audit", a.requireAdmin(a.bind((*API).AdminAuditLog)))
+	mux.HandleFunc("GET /api/admin/tokens", a.requireAdmin(a.bind((*API).ListAPITokens)))
+	mux.HandleFunc("POST /api/admin/tokens", a.requireAdmin(a.bind((*API).CreateAPIToken)))
+	mux.HandleFunc("DELETE /api/admin/tokens/{id}", a.requireAdmin(a.bind((*API).RevokeAPIToken)))
+	mux.HandleFunc("GET /api/admin/2fa", a.requireAdmin(a.bind((*API).TwoFactorStatus)))
+	mux.HandleFunc("POST /api/admin/2fa", a.requireAdmin(a.bind((*API).StartTwoFactor)))
+	mux.HandleFunc("POST /api/admin/2fa/confirm", a.requireAdmin(a.bind((*API).ConfirmTwoFactor)))
+	mux.HandleFunc("POST /api/admin/2fa/disable", a.requireAdmin(a.bind((*API).DisableTwoFactor)))
 }
 
 // ServeHTTP implements http.Handler.
@@ -122,16 +516,73 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.handler.ServeHTTP(w, r)
 }
 
-// withMiddleware wraps the mux with recovery, CORS, and logging.
-func withMiddleware(h http.Handler) http.Handler {
-	return withRecovery(withLogging(withCORS(h)))
+// CloseStreams ends the open /api/events streams, which would otherwise
+// hold up a graceful shutdown. Register it with
+// http.Server.RegisterOnShutdown.
+func (s *Server) CloseStreams() {
+	s.live.close()
+}
+
+// withMiddleware wraps the handler with recovery, client address
+// resolution, logging, and CORS.
+func withMiddleware(h http.Handler, origins []string, proxies []netip.Prefix, store *data.Store, crashes *crashReporter) http.Handler {
+	return withRecovery(withRealIP(withLogging(withCORS(h, origins)), proxies), store, crashes)
 }
 
-func withCORS(next http.Handler) http.Handler {
+// withBasePath strips prefix from request paths, and sends the bare prefix
+// to the prefix with a trailing slash so the frontend's relative URLs
+// resolve. Paths outside the prefix are not found.
+func withBasePath(next http.Handler, prefix string) http.Handler {
+	if prefix == "" {
+		return next
+	}
+	mux := http.NewServeMux()
+	mux.Handle(prefix+"/", http.StripPrefix(prefix, next))
+	mux.HandleFunc(prefix, func(w http.ResponseWriter, r *http.Request) {
+		target := prefix + "/"
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+	})
+	return mux
+}
+
+// withReadOnly turns away API requests that would change data, for a
+// database opened read-only. Signing in, to the app or the admin panel,
+// is still allowed.
+func withReadOnly(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
+		switch {
+		case r.Method == http.MethodGet, r.Method == http.MethodHead, r.Method == http.MethodOptions,
+			!strings.HasPrefix(r.URL.Path, "/api/"),
+			r.URL.Path == "/api/auth/login", r.URL.Path == "/api/auth/logout",
+			r.URL.Path == "/api/admin/login", r.URL.Path == "/api/admin/logout",
+			r.URL.Path == "/api/graphql":
+			next.ServeHTTP(w, r)
+		default:
+			jsonError(w, http.StatusForbidden, "the database is open read-only")
+		}
+	})
+}
+
+func withCORS(next http.Handler, origins []string) http.Handler {
+	anyOrigin := len(origins) == 0 || slices.Contains(origins, "*")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if anyOrigin {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Add("Vary", "Origin")
+			origin := r.Header.Get("Origin")
+			if origin == "" || !slices.Contains(origins, origin) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-Match, If-None-Match")
+		w.Header().Set("Access-Control-Expose-Headers", nextCursorHeader+", ETag")
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusNoContent)
 			return
@@ -150,23 +601,101 @@ func (sr *statusRecorder) WriteHeader(code int) {
 	sr.ResponseWriter.WriteHeader(code)
 }
 
+func (sr *statusRecorder) Unwrap() http.ResponseWriter { return sr.ResponseWriter }
+
 func withLogging(next http.Handler) http.Handler {
 	logger := log.New(os.Stderr, "", log.LstdFlags)
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
 		next.ServeHTTP(rec, r)
-		logger.Printf("%s %s %d %s", r.Method, r.URL.Path, rec.status, time.Since(start).Round(time.Millisecond))
+		logger.Printf("%s %s %s %d %s", clientHost(r), r.Method, r.URL.Path, rec.status,
+			time.Since(start).Round(time.Millisecond))
+	})
+}
+
+// viaHTTPSKey marks a request a trusted proxy says came to it over HTTPS.
+type viaHTTPSKey struct{}
+
+// overHTTPS reports whether the client reached the server over HTTPS,
+// directly or through a trusted proxy.
+func overHTTPS(r *http.Request) bool {
+	return r.TLS != nil || r.Context().Value(viaHTTPSKey{}) != nil
+}
+
+// withRealIP replaces the request's remote address with the client's when
+// the request comes through a trusted proxy. X-Forwarded-For is read from
+// the right, skipping trusted hops, since anything left of the last
+// untrusted entry could have been made up by the client. A trusted proxy's
+// X-Forwarded-Proto is believed too; anyone else's is ignored.
+func withRealIP(next http.Handler, proxies []netip.Prefix) http.Handler {
+	if len(proxies) == 0 {
+		return next
+	}
+	trusted := func(addr netip.Addr) bool {
+		return slices.ContainsFunc(proxies, func(p netip.Prefix) bool { return p.Contains(addr) })
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peer, err := netip.ParseAddr(clientHost(r))
+		if err != nil || !trusted(peer.Unmap()) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https") {
+			r = r.WithContext(context.WithValue(r.Context(), viaHTTPSKey{}, true))
+		}
+		var hops []string
+		for _, v := range r.Header.Values("X-Forwarded-For") {
+			hops = append(hops, strings.Split(v, ",")...)
+		}
+		client := peer
+		for i := len(hops) - 1; i >= 0; i-- {
+			addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				break
+			}
+			client = addr.Unmap()
+			if !trusted(client) {
+				break
+			}
+		}
+		r.RemoteAddr = netip.AddrPortFrom(client, 0).String()
+		next.ServeHTTP(w, r)
 	})
 }
 
-func withRecovery(next http.Handler) http.Handler {
+// clientHost returns the host part of the request's remote address.
+func clientHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// withRecovery turns a panicking handler into a 500 and, when crashes is
+// set, saves a crash report.
+func withRecovery(next http.Handler, store *data.Store, crashes *crashReporter) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		crashes.record(r)
 		defer func() {
-			if err := recover(); err != nil {
-				fmt.Fprintf(os.Stderr, "panic: %v\n", err)
-				jsonError(w, http.StatusInternalServerError, "internal server error")
+			err := recover()
+			if err == nil {
+				return
+			}
+			if err == http.ErrAbortHandler {
+				panic(err)
+			}
+			fmt.Fprintf(os.Stderr, "panic: %v\n", err)
+			if crashes != nil {
+				path, werr := crashes.write(store, r, err, debug.Stack())
+				if werr != nil {
+					fmt.Fprintf(os.Stderr, "webcasa: write crash report: %v\n", werr)
+				} else {
+					fmt.Fprintf(os.Stderr, "webcasa: crash report saved to %s; please attach it to a bug report\n", path)
+				}
 			}
+			jsonError(w, http.StatusInternalServerError, "internal server error")
 		}()
 		next.ServeHTTP(w, r)
 	})
@@ -0,0 +1,168 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"gorm.io/gorm"
+)
+
+// withTokens checks requests carrying an API token against the token's
+// scope and rate limit. Requests without one pass through untouched. The
+// calendar feed may take its token as ?token=, since calendar apps can't
+// send headers.
+func withTokens(next http.Handler, store *data.Store, guard *authGuard) http.Handler {
+	limiter := newRateLimiter[uint]()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok && r.URL.Path == "/api/calendar.ics" {
+			secret = r.URL.Query().Get("token")
+		}
+		if !strings.HasPrefix(secret, data.TokenPrefix) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if guard.blocked(w, r) {
+			return
+		}
+		tok, err := store.APITokenBySecret(secret)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			guard.fail(r)
+			jsonError(w, http.StatusUnauthorized, "unknown or revoked API token")
+			return
+		}
+		if err != nil {
+			jsonError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if msg := scopeDenies(tok.Scope, r); msg != "" {
+			jsonError(w, http.StatusForbidden, msg)
+			return
+		}
+		now := time.Now()
+		if wait, ok := limiter.allow(tok.ID, tok.RateLimit, now); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			jsonError(w, http.StatusTooManyRequests,
+				fmt.Sprintf("rate limit of %d requests per minute exceeded", tok.RateLimit))
+			return
+		}
+		_ = store.TouchAPIToken(tok.ID, now)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tokenKey{}, tok.ID)))
+	})
+}
+
+// scopeDenies explains why a token with the given scope may not make the
+// request, or returns "" if it may.
+func scopeDenies(scope string, r *http.Request) string {
+	if strings.HasPrefix(r.URL.Path, "/api/admin/") {
+		return "API tokens can't use the admin panel"
+	}
+	switch scope {
+	case data.ScopeFull:
+		return ""
+	case data.ScopeRead:
+		// GraphQL only reads, whichever method carries the query.
+		if r.Method == http.MethodGet || r.Method == http.MethodHead || r.URL.Path == "/api/graphql" {
+			return ""
+		}
+		return "this token is read-only"
+	case data.ScopeUpload:
+		if r.Method == http.MethodPost && r.URL.Path == "/api/documents" {
+			return ""
+		}
+		return "this token can only upload documents"
+	case data.ScopeDisplay:
+		return "this token can only show the kiosk page"
+	}
+	return "unknown token scope " + strconv.Quote(scope)
+}
+
+// rateLimiter is a token bucket per key, refilled continuously at a
+// per-minute rate and holding at most one minute's worth.
+type rateLimiter[K comparable] struct {
+	mu      sync.Mutex
+	buckets map[K]*bucket
+}
+
+type bucket struct {
+	level float64
+	at    time.Time
+}
+
+func newRateLimiter[K comparable]() *rateLimiter[K] {
+	return &rateLimiter[K]{buckets: make(map[K]*bucket)}
+}
+
+// fill refills the key's bucket up to now. The caller holds l.mu.
+func (l *rateLimiter[K]) fill(key K, perMinute int, now time.Time) *bucket {
+	capacity := float64(perMinute)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{level: capacity, at: now}
+		l.buckets[key] = b
+	}
+	b.level = min(capacity, b.level+now.Sub(b.at).Minutes()*capacity)
+	b.at = now
+	return b
+}
+
+// allow takes one request from the key's bucket. When the bucket is empty
+// it returns how long until the next request would be allowed.
+func (l *rateLimiter[K]) allow(key K, perMinute int, now time.Time) (time.Duration, bool) {
+	if perMinute <= 0 {
+		perMinute = data.DefaultTokenRateLimit
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.fill(key, perMinute, now)
+	if b.level < 1 {
+		return time.Duration((1 - b.level) / float64(perMinute) * float64(time.Minute)), false
+	}
+	b.level--
+	return 0, true
+}
+
+// exhausted reports whether the key's bucket is empty, without taking from
+// it.
+func (l *rateLimiter[K]) exhausted(key K, perMinute int, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.fill(key, perMinute, now).level < 1
+}
+
+// authFailuresPerMinute is how many wrong admin passwords or unknown API
+// tokens one client address may send per minute before being turned away.
+const authFailuresPerMinute = 10
+
+// authGuard slows down guessing of the admin password and API tokens by
+// client address.
+type authGuard struct{ failures *rateLimiter[string] }
+
+func newAuthGuard() *authGuard { return &authGuard{failures: newRateLimiter[string]()} }
+
+// blocked reports whether the client has run out of attempts, writing a
+// 429 if so.
+func (g *authGuard) blocked(w http.ResponseWriter, r *http.Request) bool {
+	if !g.failures.exhausted(clientHost(r), authFailuresPerMinute, time.Now()) {
+		return false
+	}
+	w.Header().Set("Retry-After", "60")
+	jsonError(w, http.StatusTooManyRequests, "too many failed sign-in attempts, try again later")
+	return true
+}
+
+// fail counts a failed attempt by the client.
+func (g *authGuard) fail(r *http.Request) {
+	g.failures.allow(clientHost(r), authFailuresPerMinute, time.Now())
+}
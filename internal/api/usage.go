@@ -0,0 +1,68 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// untrackedRoutes are changes too routine to count as using a feature.
+var untrackedRoutes = map[string]bool{
+	"POST /api/presence": true,
+	"POST /api/usage":    true,
+}
+
+// withUsage counts each successful change by its route pattern, like
+// "PUT /api/projects/{id}", so the counters say which features are used
+// without recording what was changed.
+func withUsage(next http.Handler, mux *http.ServeMux, store *data.Store) http.Handler {
+	if store.ReadOnly() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost && r.Method != http.MethodPut && r.Method != http.MethodDelete {
+			next.ServeHTTP(w, r)
+			return
+		}
+		_, pattern := mux.Handler(r)
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		if pattern == "" || untrackedRoutes[pattern] || rec.status/100 != 2 {
+			return
+		}
+		_ = store.RecordUsage(data.UsageFeature, pattern, 0, time.Now())
+	})
+}
+
+// ── Usage ──────────────────────────────────────────
+
+// usageEvent is what the web app reports: a page visited, or a form saved
+// after being open for MS milliseconds.
+type usageEvent struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	MS   int64  `json:"ms"`
+}
+
+// RecordUsage counts a page visit or form save reported by the web app.
+func (a *API) RecordUsage(w http.ResponseWriter, r *http.Request) {
+	ev, err := decodeBody[usageEvent](r)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if ev.Kind != data.UsageTab && ev.Kind != data.UsageForm {
+		jsonError(w, http.StatusBadRequest, "kind must be tab or form")
+		return
+	}
+	took := time.Duration(ev.MS) * time.Millisecond
+	if err := a.store.RecordUsage(ev.Kind, ev.Name, took, time.Now()); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,221 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// Redaction categories for the records archive.
+const (
+	// RedactPolicy blanks insurance policy numbers and leaves out insurance
+	// paperwork.
+	RedactPolicy = "policy"
+	// RedactSerials blanks serial numbers and MAC addresses.
+	RedactSerials = "serials"
+	// RedactCosts blanks every amount of money.
+	RedactCosts = "costs"
+)
+
+// RedactionCategories lists the named groups of sensitive fields.
+func RedactionCategories() []string {
+	return []string{RedactPolicy, RedactSerials, RedactCosts}
+}
+
+// Redacted replaces the value of every redacted field.
+const Redacted = "[redacted]"
+
+var categoryFields = map[string][]string{
+	RedactPolicy:  {"InsurancePolicy"},
+	RedactSerials: {"SerialNumber", "MACAddress"},
+}
+
+// fieldSpec matches a field name, optionally qualified by its table.
+var fieldSpec = regexp.MustCompile(`^([a-z_]+\.)?[A-Za-z][A-Za-z0-9]*$`)
+
+// Redaction says what to hold back from a records archive.
+type Redaction struct {
+	specs []string
+	// fields holds bare field names and table.Field pairs.
+	fields        map[string]bool
+	costs         bool
+	insuranceDocs bool
+}
+
+// ParseRedaction reads redaction specs: categories, field names as they
+// appear in the archive's records/*.json, or table.Field for one table's
+// field only (vendors.Phone).
+func ParseRedaction(specs []string) (Redaction, error) {
+	r := Redaction{fields: make(map[string]bool)}
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		switch {
+		case spec == "":
+			continue
+		case spec == RedactCosts:
+			r.costs = true
+		case spec == RedactPolicy:
+			r.insuranceDocs = true
+		case spec == RedactSerials:
+		case !fieldSpec.MatchString(spec):
+			return Redaction{}, fmt.Errorf(
+				"can't redact %q -- use %s, a field name, or table.Field",
+				spec, strings.Join(RedactionCategories(), ", "))
+		}
+		for _, f := range categoryFields[spec] {
+			r.fields[f] = true
+		}
+		if !slices.Contains(RedactionCategories(), spec) {
+			r.fields[spec] = true
+		}
+		r.specs = append(r.specs, spec)
+	}
+	return r, nil
+}
+
+// Specs returns what the redaction holds back, as given.
+func (r Redaction) Specs() []string { return r.specs }
+
+func (r Redaction) redacts(table, field string) bool {
+	return r.fields[field] || r.fields[table+"."+field] ||
+		(r.costs && strings.HasSuffix(field, "Cents"))
+}
+
+// apply blanks the redacted fields of a decoded record, and of any
+// records nested in it, returning how many values it blanked. Empty values
+// are left alone.
+func (r Redaction) apply(table string, v any) int {
+	n := 0
+	switch v := v.(type) {
+	case map[string]any:
+		for k, x := range v {
+			if x != nil && x != "" && r.redacts(table, k) {
+				v[k] = Redacted
+				n++
+				continue
+			}
+			n += r.apply("", x)
+		}
+	case []any:
+		for _, x := range v {
+			n += r.apply(table, x)
+		}
+	}
+	return n
+}
+
+// dropUnloaded removes associations that weren't loaded, which encode as
+// records with ID 0; the foreign keys next to them say what they point at.
+func dropUnloaded(rows []any) {
+	for _, row := range rows {
+		row, _ := row.(map[string]any)
+		for k, v := range row {
+			if nested, ok := v.(map[string]any); ok && nested["ID"] == json.Number("0") {
+				delete(row, k)
+			}
+		}
+	}
+}
+
+// ArchiveManifest summarizes what went into a records archive.
+type ArchiveManifest struct {
+	Records           int
+	Documents         int
+	WithheldDocuments int
+	FloorPlans        int
+	RedactedValues    int
+}
+
+// Archive writes every record as records/<table>.json, with document
+// files and floor plans alongside, as a plain zip to w. Each record passes
+// through redact on its way in.
+func Archive(store *data.Store, w io.Writer, now time.Time, redact Redaction) (ArchiveManifest, error) {
+	var m ArchiveManifest
+	zw := zip.NewWriter(w)
+
+	tables, err := store.ArchiveTables()
+	if err != nil {
+		return m, err
+	}
+	for _, t := range tables {
+		raw, err := json.Marshal(t.Rows)
+		if err != nil {
+			return m, fmt.Errorf("encode %s: %w", t.Table, err)
+		}
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		var rows []any
+		if err := dec.Decode(&rows); err != nil {
+			return m, fmt.Errorf("decode %s: %w", t.Table, err)
+		}
+		m.Records += len(rows)
+		dropUnloaded(rows)
+		m.RedactedValues += redact.apply(t.Table, rows)
+		body, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return m, fmt.Errorf("encode %s: %w", t.Table, err)
+		}
+		if err := writeFile(zw, "records/"+t.Table+".json", body, now); err != nil {
+			return m, err
+		}
+	}
+
+	docs, err := store.ListDocuments(false)
+	if err != nil {
+		return m, fmt.Errorf("list documents: %w", err)
+	}
+	for _, meta := range docs {
+		if redact.insuranceDocs && IsInsuranceDocument(meta) {
+			m.WithheldDocuments++
+			continue
+		}
+		doc, err := store.GetDocument(meta.ID)
+		if err != nil {
+			return m, fmt.Errorf("load document %d: %w", meta.ID, err)
+		}
+		name := fmt.Sprintf("documents/%d-%s", doc.ID, safeName(doc.FileName, doc.Title))
+		if err := writeFile(zw, name, doc.Data, doc.UpdatedAt); err != nil {
+			return m, err
+		}
+		m.Documents++
+	}
+
+	var plans Manifest
+	if err := writeFloorPlans(store, zw, &plans); err != nil {
+		return m, err
+	}
+	m.FloorPlans = plans.FloorPlans
+
+	if err := writeArchiveReadme(zw, redact, m, now); err != nil {
+		return m, err
+	}
+	return m, zw.Close()
+}
+
+func writeArchiveReadme(zw *zip.Writer, redact Redaction, m ArchiveManifest, now time.Time) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Home records\nGenerated %s\n\n", now.Format("January 2, 2006 15:04 MST"))
+	fmt.Fprintf(&b, "records/      %d records, one JSON file per kind\n", m.Records)
+	fmt.Fprintf(&b, "documents/    %d attached files, named <id>-<file name>\n", m.Documents)
+	fmt.Fprintf(&b, "floor-plans/  %d floor plan images\n", m.FloorPlans)
+	if specs := redact.Specs(); len(specs) > 0 {
+		fmt.Fprintf(&b, "\nREDACTED\n  %s\n", strings.Join(specs, ", "))
+		fmt.Fprintf(&b, "  %d values read %q.\n", m.RedactedValues, Redacted)
+		if m.WithheldDocuments > 0 {
+			fmt.Fprintf(&b, "  %d insurance documents were left out; their details are still in records/documents.json.\n",
+				m.WithheldDocuments)
+		}
+	}
+	return writeFile(zw, "README.txt", []byte(b.String()), now)
+}
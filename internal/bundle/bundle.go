@@ -0,0 +1,383 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+// Package bundle builds the emergency bundle: a single zip with what you'd
+// need after a disaster -- insurance documents, the inventory with values,
+// the house profile, recent photos of each room, and key contacts -- sealed
+// with a passphrase so it can be parked in cloud storage. It also builds
+// the records archive: everything, with sensitive fields optionally
+// redacted, for handing the house's records to someone else.
+package bundle
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"gorm.io/gorm"
+)
+
+// insuranceWords pick out insurance paperwork among the documents by title,
+// file name, or notes.
+var insuranceWords = regexp.MustCompile(`(?i)insurance|policy|declarations|coverage`)
+
+// unsafeName matches runs of characters we keep out of zip entry names.
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Manifest summarizes what went into a bundle.
+type Manifest struct {
+	InsuranceDocuments int
+	InventoryItems     int
+	InventoryCents     int64
+	RoomPhotos         int
+	FloorPlans         int
+	Contacts           int
+}
+
+// Emergency writes the emergency bundle as a plain zip to w. Encrypt the
+// result before it leaves the machine.
+func Emergency(store *data.Store, w io.Writer, now time.Time) (Manifest, error) {
+	var m Manifest
+	zw := zip.NewWriter(w)
+
+	profile, err := store.HouseProfile()
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return m, fmt.Errorf("load house profile: %w", err)
+	}
+	vendors, err := store.ListVendors(false)
+	if err != nil {
+		return m, fmt.Errorf("list vendors: %w", err)
+	}
+	contacts, err := store.ListContacts(false)
+	if err != nil {
+		return m, fmt.Errorf("list contacts: %w", err)
+	}
+
+	houseJSON, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		return m, fmt.Errorf("encode house profile: %w", err)
+	}
+	if err := writeFile(zw, "house.json", houseJSON, now); err != nil {
+		return m, err
+	}
+	if err := writeInsurance(store, zw, &m); err != nil {
+		return m, err
+	}
+	if err := writeInventory(store, zw, &m, now); err != nil {
+		return m, err
+	}
+	if err := writeContacts(zw, contacts, vendors, &m, now); err != nil {
+		return m, err
+	}
+	if err := writeRoomPhotos(store, zw, &m); err != nil {
+		return m, err
+	}
+	if err := writeFloorPlans(store, zw, &m); err != nil {
+		return m, err
+	}
+	if err := writeSummary(zw, profile, contacts, vendors, m, now); err != nil {
+		return m, err
+	}
+	return m, zw.Close()
+}
+
+// IsInsuranceDocument reports whether a document looks like insurance
+// paperwork.
+func IsInsuranceDocument(doc data.Document) bool {
+	return insuranceWords.MatchString(doc.Title) ||
+		insuranceWords.MatchString(doc.FileName) ||
+		insuranceWords.MatchString(doc.Notes)
+}
+
+func writeInsurance(store *data.Store, zw *zip.Writer, m *Manifest) error {
+	docs, err := store.ListDocuments(false)
+	if err != nil {
+		return fmt.Errorf("list documents: %w", err)
+	}
+	for _, meta := range docs {
+		if !IsInsuranceDocument(meta) {
+			continue
+		}
+		doc, err := store.GetDocument(meta.ID)
+		if err != nil {
+			return fmt.Errorf("load document %d: %w", meta.ID, err)
+		}
+		name := fmt.Sprintf("insurance/%d-%s", doc.ID, safeName(doc.FileName, doc.Title))
+		if err := writeFile(zw, name, doc.Data, doc.UpdatedAt); err != nil {
+			return err
+		}
+		m.InsuranceDocuments++
+	}
+	return nil
+}
+
+func writeInventory(store *data.Store, zw *zip.Writer, m *Manifest, now time.Time) error {
+	appliances, err := store.ListAppliances(false)
+	if err != nil {
+		return fmt.Errorf("list appliances: %w", err)
+	}
+	rooms, err := store.ListRooms(true)
+	if err != nil {
+		return fmt.Errorf("list rooms: %w", err)
+	}
+	roomNames := make(map[uint]string, len(rooms))
+	for _, r := range rooms {
+		roomNames[r.ID] = r.Name
+	}
+
+	rows := [][]string{{
+		"Name", "Brand", "Model", "Serial", "Location",
+		"Purchased", "Cost", "Warranty Expires", "Notes",
+	}}
+	for _, a := range appliances {
+		location := a.Location
+		if a.RoomID != nil && roomNames[*a.RoomID] != "" {
+			location = roomNames[*a.RoomID]
+		}
+		cost := ""
+		if a.CostCents != nil {
+			cost = formatCents(*a.CostCents)
+			m.InventoryCents += *a.CostCents
+		}
+		rows = append(rows, []string{
+			a.Name, a.Brand, a.ModelNumber, a.SerialNumber, location,
+			formatDate(a.PurchaseDate), cost, formatDate(a.WarrantyExpiry), a.Notes,
+		})
+		m.InventoryItems++
+	}
+	return writeCSV(zw, "inventory.csv", rows, now)
+}
+
+// writeContacts lists the house's contacts, emergency ones first, and
+// then the vendors. A contact who works for a company is listed under the
+// company, as vendors are.
+func writeContacts(zw *zip.Writer, contacts []data.Contact, vendors []data.Vendor, m *Manifest, now time.Time) error {
+	rows := [][]string{{"Name", "Role", "Contact", "Phone", "Email", "Website", "Account", "Emergency", "Notes"}}
+	for _, c := range contacts {
+		name, person := c.Name, ""
+		if c.Company != "" {
+			name, person = c.Company, c.Name
+		}
+		emergency := ""
+		if c.Emergency {
+			emergency = "yes"
+		}
+		rows = append(rows, []string{
+			name, c.Role, person, c.Phone, c.Email, c.Website, c.AccountNumber, emergency, c.Notes,
+		})
+		m.Contacts++
+	}
+	for _, v := range vendors {
+		rows = append(rows, []string{v.Name, "vendor", v.ContactName, v.Phone, v.Email, v.Website, "", "", v.Notes})
+		m.Contacts++
+	}
+	return writeCSV(zw, "contacts.csv", rows, now)
+}
+
+// writeRoomPhotos adds the most recent walkthrough shots of each room or
+// area.
+func writeRoomPhotos(store *data.Store, zw *zip.Writer, m *Manifest) error {
+	walks, err := store.ListWalkthroughs(false)
+	if err != nil {
+		return fmt.Errorf("list walkthroughs: %w", err)
+	}
+	// Walkthroughs come newest year first; the first year that has an
+	// area wins it.
+	claimed := make(map[string]int)
+	for _, w := range walks {
+		for _, it := range w.Items {
+			area := strings.ToLower(it.AreaLabel())
+			if year, ok := claimed[area]; ok && year != w.Year {
+				continue
+			}
+			claimed[area] = w.Year
+			doc, err := store.GetDocument(it.DocumentID)
+			if err != nil {
+				return fmt.Errorf("load photo %d: %w", it.DocumentID, err)
+			}
+			name := fmt.Sprintf("photos/%s/%d-%d-%s",
+				safeName(it.AreaLabel(), "area"), w.Year, doc.ID, safeName(doc.FileName, doc.Title))
+			if err := writeFile(zw, name, doc.Data, doc.UpdatedAt); err != nil {
+				return err
+			}
+			m.RoomPhotos++
+		}
+	}
+	return nil
+}
+
+func writeFloorPlans(store *data.Store, zw *zip.Writer, m *Manifest) error {
+	plans, err := store.ListFloorPlans(false)
+	if err != nil {
+		return fmt.Errorf("list floor plans: %w", err)
+	}
+	for _, meta := range plans {
+		plan, err := store.GetFloorPlan(meta.ID)
+		if err != nil {
+			return fmt.Errorf("load floor plan %d: %w", meta.ID, err)
+		}
+		name := safeName(plan.FileName, plan.Name)
+		if path.Ext(name) == "" {
+			if exts, _ := mime.ExtensionsByType(plan.ImageMIMEType); len(exts) > 0 {
+				name += exts[0]
+			}
+		}
+		if err := writeFile(zw, fmt.Sprintf("floor-plans/%d-%s", plan.ID, name), plan.ImageData, plan.UpdatedAt); err != nil {
+			return err
+		}
+		m.FloorPlans++
+	}
+	return nil
+}
+
+// writeSummary writes README.txt: the house profile, insurance details, and
+// key contacts in plain text, readable on any device.
+func writeSummary(
+	zw *zip.Writer,
+	p data.HouseProfile,
+	contacts []data.Contact,
+	vendors []data.Vendor,
+	m Manifest,
+	now time.Time,
+) error {
+	var b strings.Builder
+	title := "Emergency bundle"
+	if p.Nickname != "" {
+		title += " -- " + p.Nickname
+	}
+	fmt.Fprintf(&b, "%s\nGenerated %s\n\n", title, now.Format("January 2, 2006 15:04 MST"))
+
+	b.WriteString("HOUSE\n")
+	line := func(label, value string) {
+		if strings.TrimSpace(value) != "" {
+			fmt.Fprintf(&b, "  %-18s %s\n", label+":", value)
+		}
+	}
+	address := strings.Join(nonEmpty(p.AddressLine1, p.AddressLine2), ", ")
+	cityLine := strings.TrimSpace(strings.Join(nonEmpty(p.City, p.State), ", ") + " " + p.PostalCode)
+	line("Address", strings.Join(nonEmpty(address, cityLine), ", "))
+	if p.YearBuilt > 0 {
+		line("Year built", strconv.Itoa(p.YearBuilt))
+	}
+	if p.SquareFeet > 0 {
+		line("Square feet", strconv.Itoa(p.SquareFeet))
+	}
+	line("Construction", strings.Join(nonEmpty(p.FoundationType, p.ExteriorType, p.RoofType), " / "))
+	line("Utilities", strings.Join(nonEmpty(p.HeatingType, p.CoolingType, p.WaterSource, p.SewerType), " / "))
+
+	b.WriteString("\nINSURANCE\n")
+	line("Carrier", p.InsuranceCarrier)
+	line("Policy", p.InsurancePolicy)
+	line("Renews", formatDate(p.InsuranceRenewal))
+	fmt.Fprintf(&b, "  %-18s %d (see insurance/)\n", "Documents:", m.InsuranceDocuments)
+
+	b.WriteString("\nINVENTORY\n")
+	fmt.Fprintf(&b, "  %d items, %s recorded value (see inventory.csv)\n",
+		m.InventoryItems, formatCents(m.InventoryCents))
+
+	b.WriteString("\nKEY CONTACTS (full list in contacts.csv)\n")
+	for _, c := range contacts {
+		if c.Phone == "" && c.Email == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "  %s", c.Name)
+		if c.Company != "" {
+			fmt.Fprintf(&b, " (%s)", c.Company)
+		}
+		fmt.Fprintf(&b, ": %s", strings.Join(nonEmpty(c.Phone, c.Email), ", "))
+		if c.AccountNumber != "" {
+			fmt.Fprintf(&b, ", account %s", c.AccountNumber)
+		}
+		if c.Emergency {
+			b.WriteString(" [emergency]")
+		}
+		b.WriteString("\n")
+	}
+	for _, v := range vendors {
+		if v.Phone == "" && v.Email == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "  %s", v.Name)
+		if v.ContactName != "" {
+			fmt.Fprintf(&b, " (%s)", v.ContactName)
+		}
+		fmt.Fprintf(&b, ": %s\n", strings.Join(nonEmpty(v.Phone, v.Email), ", "))
+	}
+
+	fmt.Fprintf(&b, "\nPHOTOS\n  %d room photos (photos/), %d floor plans (floor-plans/)\n",
+		m.RoomPhotos, m.FloorPlans)
+	return writeFile(zw, "README.txt", []byte(b.String()), now)
+}
+
+func writeFile(zw *zip.Writer, name string, body []byte, modified time.Time) error {
+	f, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modified,
+	})
+	if err != nil {
+		return fmt.Errorf("add %s: %w", name, err)
+	}
+	if _, err := f.Write(body); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return nil
+}
+
+func writeCSV(zw *zip.Writer, name string, rows [][]string, now time.Time) error {
+	var b strings.Builder
+	cw := csv.NewWriter(&b)
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return writeFile(zw, name, []byte(b.String()), now)
+}
+
+// safeName turns a file name (or, failing that, a fallback label) into a
+// single path segment safe for a zip entry.
+func safeName(name, fallback string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	if name == "." || name == "/" || name == "" {
+		name = fallback
+	}
+	name = strings.Trim(unsafeName.ReplaceAllString(name, "_"), "_.")
+	if name == "" {
+		return "file"
+	}
+	return name
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.DateOnly)
+}
+
+func formatCents(c int64) string {
+	sign := ""
+	if c < 0 {
+		sign, c = "-", -c
+	}
+	return fmt.Sprintf("%s$%d.%02d", sign, c/100, c%100)
+}
+
+func nonEmpty(parts ...string) []string {
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if s := strings.TrimSpace(p); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
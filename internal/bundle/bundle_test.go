@@ -0,0 +1,182 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStore(t *testing.T) *data.Store {
+	t.Helper()
+	store, err := data.Open(filepath.Join(t.TempDir(), "bundle.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	require.NoError(t, store.AutoMigrate())
+	require.NoError(t, store.SeedDefaults())
+	return store
+}
+
+func TestSealRoundTrip(t *testing.T) {
+	sealed, err := Seal([]byte("hello bundle"), "correct horse")
+	require.NoError(t, err)
+	assert.NotContains(t, string(sealed), "hello bundle")
+
+	plain, err := Open(sealed, "correct horse")
+	require.NoError(t, err)
+	assert.Equal(t, "hello bundle", string(plain))
+
+	_, err = Open(sealed, "wrong horse!")
+	require.ErrorIs(t, err, ErrBadPassphrase)
+
+	sealed[len(sealed)-1] ^= 0xff
+	_, err = Open(sealed, "correct horse")
+	require.ErrorIs(t, err, ErrBadPassphrase)
+
+	_, err = Seal([]byte("x"), "short")
+	assert.Error(t, err)
+	_, err = Open([]byte("PK\x03\x04"), "correct horse")
+	assert.ErrorContains(t, err, "not an encrypted")
+}
+
+func TestEmergencyBundleContents(t *testing.T) {
+	store := newStore(t)
+	require.NoError(t, store.CreateHouseProfile(data.HouseProfile{
+		Nickname: "Elm St", AddressLine1: "1 Elm St", City: "Springfield",
+		InsuranceCarrier: "Acme Mutual", InsurancePolicy: "HO-123",
+	}))
+	cost := int64(129_900)
+	require.NoError(t, store.CreateAppliance(&data.Appliance{Name: "Fridge", CostCents: &cost}))
+	require.NoError(t, store.CreateVendor(&data.Vendor{Name: "Bob's Plumbing", Phone: "555-0100"}))
+	require.NoError(t, store.CreateContact(&data.Contact{
+		Name: "Springfield Water", Role: data.ContactRoleUtility, Phone: "555-0199",
+		AccountNumber: "W-42", Emergency: true,
+	}))
+
+	policy := data.Document{Title: "Homeowners Policy", FileName: "policy.pdf", MIMEType: "application/pdf", Data: []byte("%PDF")}
+	require.NoError(t, store.CreateDocument(&policy))
+	manual := data.Document{Title: "Fridge manual", FileName: "manual.pdf", MIMEType: "application/pdf", Data: []byte("%PDF")}
+	require.NoError(t, store.CreateDocument(&manual))
+
+	for _, year := range []int{2024, 2025} {
+		w := data.Walkthrough{Year: year}
+		require.NoError(t, store.CreateWalkthrough(&w))
+		photo := data.Document{Title: "kitchen", FileName: "kitchen.jpg", MIMEType: "image/jpeg", Data: []byte{0xff, 0xd8}}
+		require.NoError(t, store.CreateDocument(&photo))
+		require.NoError(t, store.AddWalkthroughItem(&data.WalkthroughItem{
+			WalkthroughID: w.ID, DocumentID: photo.ID, Area: "Kitchen",
+		}))
+	}
+
+	var buf bytes.Buffer
+	m, err := Emergency(store, &buf, time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, Manifest{
+		InsuranceDocuments: 1, InventoryItems: 1, InventoryCents: cost,
+		RoomPhotos: 1, Contacts: 2,
+	}, m)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		b, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		_ = rc.Close()
+		files[f.Name] = string(b)
+	}
+
+	assert.Contains(t, files, "house.json")
+	assert.Contains(t, files, fmt.Sprintf("insurance/%d-policy.pdf", policy.ID))
+	assert.Contains(t, files["inventory.csv"], "Fridge")
+	assert.Contains(t, files["inventory.csv"], "$1299.00")
+	assert.Contains(t, files["contacts.csv"], "555-0100")
+	assert.Contains(t, files["contacts.csv"], "Springfield Water,utility,,555-0199,,,W-42,yes,")
+
+	var photos []string
+	for name := range files {
+		if strings.HasPrefix(name, "photos/") {
+			photos = append(photos, name)
+		}
+	}
+	require.Len(t, photos, 1, "only the latest year's shot of each area")
+	assert.Contains(t, photos[0], "photos/Kitchen/2025-")
+
+	readme := files["README.txt"]
+	assert.Contains(t, readme, "Acme Mutual")
+	assert.Contains(t, readme, "HO-123")
+	assert.Contains(t, readme, "Bob's Plumbing")
+	assert.Contains(t, readme, "Springfield Water: 555-0199, account W-42 [emergency]")
+}
+
+func TestSafeName(t *testing.T) {
+	assert.Equal(t, "passwd", safeName("../../etc/passwd", "x"))
+	assert.Equal(t, "my_scan_1_.pdf", safeName(`C:\Users\me\my scan (1).pdf`, "x"))
+	assert.Equal(t, "Main_floor", safeName("", "Main floor"))
+	assert.Equal(t, "file", safeName("", "???"))
+}
+
+func TestArchiveRedacts(t *testing.T) {
+	store := newStore(t)
+	require.NoError(t, store.CreateHouseProfile(data.HouseProfile{
+		Nickname: "Elm St", InsuranceCarrier: "Acme Mutual", InsurancePolicy: "HO-123",
+	}))
+	cost := int64(129_900)
+	require.NoError(t, store.CreateAppliance(&data.Appliance{
+		Name: "Fridge", SerialNumber: "SN-42", CostCents: &cost,
+	}))
+	require.NoError(t, store.CreateVendor(&data.Vendor{Name: "Bob's Plumbing", Phone: "555-0100"}))
+	policy := data.Document{Title: "Homeowners Policy", FileName: "policy.pdf", Data: []byte("%PDF")}
+	require.NoError(t, store.CreateDocument(&policy))
+	manual := data.Document{Title: "Fridge manual", FileName: "manual.pdf", Data: []byte("%PDF")}
+	require.NoError(t, store.CreateDocument(&manual))
+
+	redact, err := ParseRedaction([]string{RedactPolicy, RedactSerials, RedactCosts, "vendors.Phone"})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	m, err := Archive(store, &buf, time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC), redact)
+	require.NoError(t, err)
+	assert.Equal(t, 1, m.Documents)
+	assert.Equal(t, 1, m.WithheldDocuments)
+	assert.Equal(t, 4, m.RedactedValues)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		b, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		_ = rc.Close()
+		files[f.Name] = string(b)
+	}
+	assert.Contains(t, files, fmt.Sprintf("documents/%d-manual.pdf", manual.ID))
+	assert.NotContains(t, files, fmt.Sprintf("documents/%d-policy.pdf", policy.ID))
+	assert.NotContains(t, files, "records/api_tokens.json")
+
+	house := files["records/house_profiles.json"]
+	assert.Contains(t, house, "Acme Mutual")
+	assert.NotContains(t, house, "HO-123")
+	assert.NotContains(t, files["records/appliances.json"], "SN-42")
+	assert.NotContains(t, files["records/appliances.json"], "129900")
+	assert.NotContains(t, files["records/vendors.json"], "555-0100")
+	assert.Contains(t, files["records/vendors.json"], "Bob's Plumbing")
+	assert.Contains(t, files["README.txt"], "policy, serials, costs, vendors.Phone")
+
+	_, err = ParseRedaction([]string{"drop table"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package bundle
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// Sealed bundles are laid out as
+//
+//	magic (8) | salt (16) | nonce (12) | AES-256-GCM ciphertext
+//
+// with the key derived from the passphrase by PBKDF2-SHA256. The header is
+// authenticated as additional data, so tampering with it fails decryption.
+const (
+	magic          = "WCBUNDL1"
+	saltSize       = 16
+	kdfIterations  = 600_000
+	keySize        = 32
+	minPassphrase  = 8
+	sealedOverhead = len(magic) + saltSize
+)
+
+// ErrBadPassphrase is returned when a bundle can't be opened, either because
+// the passphrase is wrong or the file was modified.
+var ErrBadPassphrase = errors.New("wrong passphrase or corrupted bundle")
+
+// Seal encrypts plaintext with a key derived from passphrase.
+func Seal(plaintext []byte, passphrase string) ([]byte, error) {
+	if len(passphrase) < minPassphrase {
+		return nil, fmt.Errorf("passphrase must be at least %d characters", minPassphrase)
+	}
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generate salt: %w", err)
+	}
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	header := make([]byte, 0, sealedOverhead+len(nonce))
+	header = append(header, magic...)
+	header = append(header, salt...)
+	header = append(header, nonce...)
+	return aead.Seal(header, nonce, plaintext, header), nil
+}
+
+// Open decrypts a bundle produced by Seal.
+func Open(sealed []byte, passphrase string) ([]byte, error) {
+	if !bytes.HasPrefix(sealed, []byte(magic)) {
+		return nil, fmt.Errorf("not an encrypted webcasa bundle")
+	}
+	if len(sealed) < sealedOverhead {
+		return nil, ErrBadPassphrase
+	}
+	salt := sealed[len(magic):sealedOverhead]
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	headerLen := sealedOverhead + aead.NonceSize()
+	if len(sealed) < headerLen+aead.Overhead() {
+		return nil, ErrBadPassphrase
+	}
+	header := sealed[:headerLen]
+	nonce := sealed[sealedOverhead:headerLen]
+	plaintext, err := aead.Open(nil, nonce, sealed[headerLen:], header)
+	if err != nil {
+		return nil, ErrBadPassphrase
+	}
+	return plaintext, nil
+}
+
+func newAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, kdfIterations, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
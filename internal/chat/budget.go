@@ -0,0 +1,92 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package chat
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/llm"
+)
+
+// ErrOverBudget is wrapped by the errors for requests to a model turned
+// away because the day's or month's token budget is spent.
+var ErrOverBudget = errors.New("the language model's token budget is spent")
+
+// ErrLocalOnly is wrapped along with ErrOverBudget when questions that can
+// be answered from the records still are.
+var ErrLocalOnly = errors.New("only questions that can be answered from the records are answered")
+
+// Budget caps the tokens language models may take in a day and in a
+// month, so hooking up a paid API can't run up a bill unnoticed. It counts
+// them in the store, as the server reports them or as guessed from the
+// text. Give it to each client as its llm.Meter.
+type Budget struct {
+	Store *data.Store
+	// Daily and Monthly cap the tokens taken today and this month. Zero
+	// leaves either uncapped.
+	Daily, Monthly int64
+	// LocalOnly keeps answering the questions that can be answered from
+	// the records while the budget is spent. Otherwise chat is off until
+	// the day or month is over.
+	LocalOnly bool
+}
+
+var _ llm.Meter = (*Budget)(nil)
+
+// BudgetStatus is how much of a budget is spent.
+type BudgetStatus struct {
+	TodayTokens int64 `json:"todayTokens"`
+	MonthTokens int64 `json:"monthTokens"`
+	Daily       int64 `json:"daily,omitempty"`
+	Monthly     int64 `json:"monthly,omitempty"`
+	// Spent is set while requests are turned away.
+	Spent     bool `json:"spent"`
+	LocalOnly bool `json:"localOnly"`
+}
+
+// Status returns how much of the budget is spent, as of now.
+func (b *Budget) Status() (BudgetStatus, error) {
+	now := time.Now()
+	today, err := b.Store.LLMTokensSince(now)
+	if err != nil {
+		return BudgetStatus{}, err
+	}
+	month, err := b.Store.LLMTokensSince(now.AddDate(0, 0, 1-now.Day()))
+	if err != nil {
+		return BudgetStatus{}, err
+	}
+	return BudgetStatus{
+		TodayTokens: today, MonthTokens: month, Daily: b.Daily, Monthly: b.Monthly,
+		Spent:     (b.Daily > 0 && today >= b.Daily) || (b.Monthly > 0 && month >= b.Monthly),
+		LocalOnly: b.LocalOnly,
+	}, nil
+}
+
+// Allow turns requests away while the budget is spent.
+func (b *Budget) Allow() error {
+	s, err := b.Status()
+	if err != nil {
+		return fmt.Errorf("check the token budget: %w", err)
+	}
+	if !s.Spent {
+		return nil
+	}
+	limit := fmt.Sprintf("the daily cap of %d tokens", b.Daily)
+	if b.Daily == 0 || s.TodayTokens < b.Daily {
+		limit = fmt.Sprintf("the monthly cap of %d tokens", b.Monthly)
+	}
+	if b.LocalOnly {
+		return fmt.Errorf("%w: %s is reached; %w", ErrOverBudget, limit, ErrLocalOnly)
+	}
+	return fmt.Errorf("%w: %s is reached", ErrOverBudget, limit)
+}
+
+// Record counts the tokens a request took. Failing to count them doesn't
+// fail the request.
+func (b *Budget) Record(model string, u llm.Usage) {
+	_ = b.Store.RecordLLMUsage(time.Now(), model, u.PromptTokens, u.CompletionTokens, u.Estimated)
+}
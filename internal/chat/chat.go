@@ -0,0 +1,409 @@
+// Package chat answers questions about the house with a language model.
+// A question is answered in two stages: the model writes a query, and then
+// answers from its results. A question scoped to a record, as in
+// "@project kitchen-remodel how far over budget are we?", has the query
+// written over just that record's tables, and is answered from the record
+// itself and the pages of its documents that match the question too,
+// citing them.
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/llm"
+)
+
+// Answer is the reply to a question.
+type Answer struct {
+	Text string `json:"answer"`
+	// SQL is the query the answer was read from, if it came from one.
+	SQL string `json:"sql,omitempty"`
+	// Sources are the document pages the model was given; its [n]
+	// citations number them from 1.
+	Sources []Source `json:"sources,omitempty"`
+	// Local is set when the model couldn't be reached, or its budget is
+	// spent, and the answer was computed from the records instead.
+	Local bool `json:"local,omitempty"`
+	// Warnings say what was left out of what the model was given to fit
+	// its context window.
+	Warnings []string `json:"warnings,omitempty"`
+	// Attempts are the queries the model wrote, in order, when its first
+	// failed or found nothing and it was asked to correct it.
+	Attempts []Attempt `json:"attempts,omitempty"`
+	// Redacted is how many values were masked in what the model was sent.
+	Redacted int `json:"redacted,omitempty"`
+}
+
+// Attempt is a query the model wrote and what came of it.
+type Attempt struct {
+	SQL string `json:"sql"`
+	// Error is why the query failed, if it did.
+	Error string `json:"error,omitempty"`
+	Rows  int    `json:"rows"`
+}
+
+// Source is a page of a document.
+type Source struct {
+	DocumentID uint   `json:"documentId"`
+	Title      string `json:"title"`
+	Page       int    `json:"page"`
+}
+
+// Scope is the record a question is about: a kind, such as project, and
+// its ID or name. The zero Scope is the whole house.
+type Scope struct {
+	Kind string
+	Ref  string
+}
+
+// ErrScope is wrapped by the errors for scopes that name no one record.
+var ErrScope = errors.New("can't scope the question")
+
+var scopePrefix = regexp.MustCompile(`^@(\w+)(?:\s+(\S+))?\s*`)
+
+// ParseScope splits a leading "@kind ref" off a question, where ref is an
+// ID or a name written as a data.Slug.
+func ParseScope(question string) (Scope, string, error) {
+	q := strings.TrimSpace(question)
+	m := scopePrefix.FindStringSubmatch(q)
+	if m == nil {
+		return Scope{}, q, nil
+	}
+	kind := strings.ToLower(m[1])
+	kinds := data.ScopeKinds()
+	if !slices.Contains(kinds, kind) {
+		return Scope{}, "", fmt.Errorf("%w: @%s isn't a scope -- use @%s", ErrScope, m[1], strings.Join(kinds, ", @"))
+	}
+	if m[2] == "" {
+		return Scope{}, "", fmt.Errorf("%w: @%s needs an ID or a name, as in @%s 7", ErrScope, kind, kind)
+	}
+	rest := strings.TrimSpace(q[len(m[0]):])
+	if rest == "" {
+		return Scope{}, "", fmt.Errorf("%w: nothing to ask after @%s %s", ErrScope, kind, m[2])
+	}
+	return Scope{Kind: kind, Ref: m[2]}, rest, nil
+}
+
+// Assistant answers questions from the store's records.
+type Assistant struct {
+	Store *data.Store
+	Model *llm.Client
+	// SQLModel, if set, writes the queries instead of Model, as a small
+	// fast model can.
+	SQLModel *llm.Client
+	// SummaryModel, if set, answers from what the query found instead of
+	// Model, as a larger model does better.
+	SummaryModel *llm.Client
+	// Redact, if set, masks values in what the models are sent.
+	Redact *Redactor
+}
+
+// sqlModel is the model that writes queries.
+func (a *Assistant) sqlModel() *llm.Client {
+	if a.SQLModel != nil {
+		return a.SQLModel
+	}
+	return a.Model
+}
+
+// summaryModel is the model that answers from what's found.
+func (a *Assistant) summaryModel() *llm.Client {
+	if a.SummaryModel != nil {
+		return a.SummaryModel
+	}
+	return a.Model
+}
+
+// Ask answers a question about scope. When the model can't be reached, or
+// its budget is spent and a Budget allows it, a common question about the
+// whole house is still answered from the records, marked Local.
+func (a *Assistant) Ask(ctx context.Context, scope Scope, question string) (Answer, error) {
+	if scope.Kind != "" {
+		return a.askScoped(ctx, scope, question)
+	}
+	answer, err := a.askRecords(ctx, question)
+	if errors.Is(err, llm.ErrUnreachable) || errors.Is(err, ErrLocalOnly) {
+		if local, ok, localErr := a.answerLocally(question); localErr != nil {
+			return Answer{}, localErr
+		} else if ok {
+			if errors.Is(err, ErrOverBudget) {
+				local.Warnings = append(local.Warnings, "The language model's token budget is spent, so this was answered from the records.")
+			}
+			return local, nil
+		}
+	}
+	return answer, err
+}
+
+// ── Scoped questions ──────────────────────────────
+
+// docPages and docBudget bound how many pages of a record's documents,
+// and how much of their text, the model is given.
+const (
+	docPages  = 6
+	docBudget = 12_000
+)
+
+const scopeFocus = `
+The question is about the %s %q: the row of %s whose id is %d.%s Only look at rows for it.`
+
+const scopedPrompt = `You answer questions about one record of a home's records, such as a project or an appliance.
+You're given its fields, the results of a database query about it when there are any, and excerpts of the documents attached to it, such as manuals, each starting with a number in square brackets, like [2].
+Answer in a few sentences, in plain words, using only what you're given, and cite the excerpt each fact comes from with its number, like [2].
+Columns ending in _cents in the query results hold amounts of money in cents; give them in dollars.
+If what you're given doesn't answer the question, say so plainly instead of guessing.`
+
+func (a *Assistant) askScoped(ctx context.Context, scope Scope, question string) (Answer, error) {
+	rec, err := a.Store.FindScoped(scope.Kind, scope.Ref)
+	if errors.Is(err, data.ErrAmbiguousScope) {
+		return Answer{}, fmt.Errorf("%w: %w", ErrScope, err)
+	} else if err != nil {
+		return Answer{}, err
+	}
+	pages, matched, err := a.searchPages(ctx, rec, question)
+	if err != nil {
+		return Answer{}, err
+	}
+
+	// The query is written over the record's table and the tables that
+	// refer to it.
+	tables := []string{rec.Table}
+	var keys []string
+	for table, fk := range rec.Related {
+		tables = append(tables, table)
+		keys = append(keys, table+"."+fk)
+	}
+	slices.Sort(tables[1:])
+	slices.Sort(keys)
+	var related string
+	if len(keys) > 0 {
+		related = fmt.Sprintf(" Rows of other tables refer to it with %s = %d.", strings.Join(keys, ", "), rec.ID)
+	}
+	focus := fmt.Sprintf(scopeFocus, rec.Kind, rec.Name, rec.Table, rec.ID, related)
+	size := a.sizeFor(ctx, a.sqlModel())
+	today := time.Now().Format(time.DateOnly)
+	schema, err := a.schema(size, question, size.room(fmt.Sprintf(sqlPrompt, today, ""), focus, question), tables...)
+	if err != nil {
+		return Answer{}, err
+	}
+	system := fmt.Sprintf(sqlPrompt, today, schema) + focus
+	reply, err := a.sqlModel().Complete(ctx, system, question)
+	if err != nil {
+		return Answer{}, fmt.Errorf("ask the model: %w", err)
+	}
+
+	var out Answer
+	size = a.answerSize(ctx, size)
+	mask := a.redaction()
+	if a.Redact != nil {
+		if rec.Fields, err = a.Store.RecordFields(rec.Table, rec.ID, mask.column); err != nil {
+			return Answer{}, err
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "The %s %q:\n%s", rec.Kind, rec.Name, rec.Fields)
+	query := extractSQL(reply)
+	if columns, rows, err := a.Store.ReadOnlyQuery(query); err == nil && len(rows) > 0 {
+		out.SQL = query
+		b.WriteString("\nQuery results:\n")
+		// Results get half of what's left, and the documents the rest.
+		size.fitRows(&b, columns, mask.rows(columns, rows), size.room(scopedPrompt, b.String(), question)/2)
+	}
+	room := size.room(scopedPrompt, b.String(), question)
+	budget := min(docBudget, room)
+	cut := func() {
+		if room < docBudget {
+			size.warn("less of the record's documents was given to it")
+		}
+	}
+	for _, p := range pages {
+		if budget <= 0 {
+			cut()
+			break
+		}
+		text := mask.text(p.Text)
+		if len(text) > budget {
+			text = strings.ToValidUTF8(text[:budget], "")
+			cut()
+		}
+		budget -= len(text)
+		out.Sources = append(out.Sources, Source{DocumentID: p.DocumentID, Title: p.Title, Page: p.Page})
+		fmt.Fprintf(&b, "\n[%d] %s, page %d:\n%s\n", len(out.Sources), p.Title, p.Page, text)
+	}
+	fmt.Fprintf(&b, "\nQuestion: %s", question)
+
+	if out.Text, err = a.summaryModel().Complete(ctx, scopedPrompt, b.String()); err != nil {
+		return Answer{}, fmt.Errorf("ask the model: %w", err)
+	}
+	out.Warnings = append(size.warnings, matched...)
+	out.Redacted = mask.n
+	return out, nil
+}
+
+// ── Records ───────────────────────────────────────
+
+const sqlPrompt = `You write SQLite queries for a home maintenance database.
+Write one SELECT statement that answers the user's question. Reply with only the SQL: no explanation and no semicolon.
+Leave out rows whose deleted_at is set. Columns ending in _cents hold amounts of money in cents.
+Today is %s.
+
+Tables:
+%s`
+
+const resultsPrompt = `You answer questions about a home from the results of a database query run to answer them.
+Answer in a sentence or two, in plain words, using only the results. Don't mention the query or SQL.
+Columns ending in _cents hold amounts of money in cents; give them in dollars.`
+
+const notesPrompt = `
+Notes written on records that may bear on the question follow the results; use them only if they do.`
+
+const dumpPrompt = `You answer questions about a home from its records, listed table by table.
+Answer in a sentence or two, in plain words, using only the records. If they don't answer the question, say so.`
+
+// Everything the model is given is sized to fit its context window; what
+// doesn't fit is cut and the answer warns about it.
+func (a *Assistant) askRecords(ctx context.Context, question string) (Answer, error) {
+	size := a.sizeFor(ctx, a.sqlModel())
+	today := time.Now().Format(time.DateOnly)
+	schema, err := a.schema(size, question, size.room(fmt.Sprintf(sqlPrompt, today, ""), question))
+	if err != nil {
+		return Answer{}, err
+	}
+	system := fmt.Sprintf(sqlPrompt, today, schema)
+	turns := []llm.Message{{Role: "user", Content: question}}
+	var attempts []Attempt
+	var columns []string
+	var rows [][]string
+	// A query that fails or finds nothing is sent back to the model once
+	// to be corrected, before falling back to the data dump.
+	for try := range 2 {
+		reply, err := a.sqlModel().Converse(ctx, system, turns)
+		if err != nil {
+			return Answer{}, fmt.Errorf("ask the model: %w", err)
+		}
+		attempt := Attempt{SQL: extractSQL(reply)}
+		columns, rows, err = a.Store.ReadOnlyQuery(attempt.SQL)
+		attempt.Rows = len(rows)
+		if err != nil {
+			attempt.Error = err.Error()
+		}
+		attempts = append(attempts, attempt)
+		if err == nil && len(rows) > 0 {
+			break
+		}
+		if try == 0 {
+			turns = append(turns, llm.Message{Role: "assistant", Content: attempt.SQL},
+				llm.Message{Role: "user", Content: correction(attempt)})
+		}
+	}
+	last := attempts[len(attempts)-1]
+	if len(attempts) == 1 {
+		attempts = nil
+	}
+	size = a.answerSize(ctx, size)
+	mask := a.redaction()
+	if last.Error != "" || last.Rows == 0 {
+		answer, err := a.askDump(ctx, size, mask, question)
+		answer.Attempts = attempts
+		return answer, err
+	}
+	query := last.SQL
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Question: %s\n\nResults:\n", question)
+	prompt := resultsPrompt
+	notes := a.relatedNotes(ctx, question)
+	if len(notes) > 0 {
+		prompt += notesPrompt
+	}
+	// With notes, results get most of the room, and the notes what's left.
+	room := size.room(prompt, b.String())
+	if len(notes) > 0 {
+		room = room * 3 / 4
+	}
+	size.fitRows(&b, columns, mask.rows(columns, rows), room)
+	if len(notes) > 0 {
+		room = size.room(prompt, b.String())
+		var n strings.Builder
+		n.WriteString("\nNotes:\n")
+		for _, note := range notes {
+			line := fmt.Sprintf("- %s %q: %s\n", note.Source, mask.text(note.Label), strings.ReplaceAll(mask.text(note.Text), "\n", " "))
+			if n.Len()+len(line) > room {
+				break
+			}
+			n.WriteString(line)
+		}
+		b.WriteString(n.String())
+	}
+	answer, err := a.summaryModel().Complete(ctx, prompt, b.String())
+	if err != nil {
+		return Answer{}, fmt.Errorf("ask the model: %w", err)
+	}
+	return Answer{Text: answer, SQL: query, Warnings: size.warnings, Attempts: attempts, Redacted: mask.n}, nil
+}
+
+// correction asks the model to fix a query that failed or found nothing.
+func correction(failed Attempt) string {
+	if failed.Error != "" {
+		return fmt.Sprintf("That query failed: %s\nWrite a corrected query. Reply with only the SQL.", failed.Error)
+	}
+	return "That query found no rows. If the answer could be in rows it missed, as with a name spelled " +
+		"or capitalized differently, or a condition that's too narrow, write a broader query. Reply with only the SQL."
+}
+
+// askDump answers from every record, for when a query can't.
+func (a *Assistant) askDump(ctx context.Context, size *sizing, mask *redaction, question string) (Answer, error) {
+	question = "\nQuestion: " + question
+	dump := size.fitDump(a.Store.MaskedDump(mask.column), size.room(dumpPrompt, question))
+	answer, err := a.summaryModel().Complete(ctx, dumpPrompt, dump+question)
+	if err != nil {
+		return Answer{}, fmt.Errorf("ask the model: %w", err)
+	}
+	return Answer{Text: answer, Warnings: size.warnings, Redacted: mask.n}, nil
+}
+
+// schema describes the named tables, or all of them, for the model in room
+// characters, a line each, followed by the values some columns take.
+func (a *Assistant) schema(size *sizing, question string, room int, names ...string) (string, error) {
+	if len(names) == 0 {
+		var err error
+		if names, err = a.Store.TableNames(); err != nil {
+			return "", fmt.Errorf("list tables: %w", err)
+		}
+	}
+	tables := make([]tableDesc, 0, len(names))
+	for _, name := range names {
+		cols, err := a.Store.TableColumns(name)
+		if err != nil {
+			return "", fmt.Errorf("describe %s: %w", name, err)
+		}
+		t := tableDesc{name: name}
+		for _, c := range cols {
+			// Blobs and the edit counter behind version checks never
+			// answer a question, so they aren't worth the room.
+			if c.Name != "data" && c.Name != "version" {
+				t.columns = append(t.columns, c.Name+" "+c.Type)
+			}
+		}
+		tables = append(tables, t)
+	}
+	return size.fitSchema(tables, a.Store.ColumnHints(), question, room), nil
+}
+
+var sqlFence = regexp.MustCompile("(?s)```(?:sql|sqlite)?\\s*(.*?)```")
+
+// extractSQL takes the query out of the model's reply, which may wrap it
+// in a code fence or end it with a semicolon.
+func extractSQL(reply string) string {
+	if m := sqlFence.FindStringSubmatch(reply); m != nil {
+		reply = m[1]
+	}
+	return strings.TrimRight(strings.TrimSpace(reply), "; \n")
+}
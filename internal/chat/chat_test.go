@@ -0,0 +1,429 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func newStore(t *testing.T) *data.Store {
+	t.Helper()
+	store, err := data.Open(filepath.Join(t.TempDir(), "chat.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	require.NoError(t, store.AutoMigrate())
+	require.NoError(t, store.SeedDefaults())
+	return store
+}
+
+// fakeModel answers each request with the next of replies, and records
+// the prompts it was sent. It doesn't say how big its context window is.
+// It embeds text about scale or crust one way, and anything else another.
+func fakeModel(t *testing.T, replies ...string) (*llm.Client, *[]string) {
+	t.Helper()
+	var prompts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/embeddings" {
+			var req struct{ Input []string }
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			var out []map[string]any
+			for i, text := range req.Input {
+				v := []float32{0, 1}
+				if strings.Contains(text, "scale") || strings.Contains(text, "crust") {
+					v = []float32{1, 0}
+				}
+				out = append(out, map[string]any{"index": i, "embedding": v})
+			}
+			prompts = append(prompts, fmt.Sprintf("embed %d", len(req.Input)))
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": out})
+			return
+		}
+		if r.URL.Path != "/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		var req struct{ Messages []llm.Message }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		var prompt []string
+		for _, m := range req.Messages {
+			prompt = append(prompt, m.Content)
+		}
+		prompts = append(prompts, strings.Join(prompt, "\n"))
+		require.NotEmpty(t, replies, "unexpected request")
+		reply := replies[0]
+		replies = replies[1:]
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []any{map[string]any{"message": llm.Message{Role: "assistant", Content: reply}}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return &llm.Client{BaseURL: srv.URL}, &prompts
+}
+
+func TestParseScope(t *testing.T) {
+	scope, q, err := ParseScope("  how old is the roof? ")
+	require.NoError(t, err)
+	assert.Equal(t, Scope{}, scope)
+	assert.Equal(t, "how old is the roof?", q)
+
+	scope, q, err = ParseScope("@Appliance 7 how do I descale it?")
+	require.NoError(t, err)
+	assert.Equal(t, Scope{Kind: "appliance", Ref: "7"}, scope)
+	assert.Equal(t, "how do I descale it?", q)
+
+	scope, q, err = ParseScope("@project kitchen-remodel how much over budget are we?")
+	require.NoError(t, err)
+	assert.Equal(t, Scope{Kind: "project", Ref: "kitchen-remodel"}, scope)
+	assert.Equal(t, "how much over budget are we?", q)
+
+	for in, want := range map[string]string{
+		"@room 3 who?": "use @appliance, @incident, @maintenance, @project, @vendor",
+		"@appliance":   "needs an ID or a name",
+		"@appliance 7": "nothing to ask",
+	} {
+		_, _, err := ParseScope(in)
+		require.ErrorIs(t, err, ErrScope, in)
+		assert.ErrorContains(t, err, want, in)
+	}
+}
+
+func TestAskScopedToManual(t *testing.T) {
+	store := newStore(t)
+	kettle := data.Appliance{Name: "Kettle", Brand: "Fellow"}
+	require.NoError(t, store.CreateAppliance(&kettle))
+	text := "Filling: up to the MAX line.\fDescaling: boil a cup of vinegar, then rinse twice."
+	manual := data.Document{
+		Title: "Kettle manual", FileName: "kettle.txt", MIMEType: "text/plain", Data: []byte(text),
+		SizeBytes: int64(len(text)), EntityKind: data.DocumentEntityAppliance, EntityID: kettle.ID,
+	}
+	require.NoError(t, store.CreateDocument(&manual))
+
+	model, prompts := fakeModel(t, "SELECT 1 WHERE 0", "Boil vinegar, then rinse twice [1].")
+	a := &Assistant{Store: store, Model: model}
+	answer, err := a.Ask(context.Background(), Scope{Kind: "appliance", Ref: "kettle"}, "how do I descale it?")
+	require.NoError(t, err)
+	assert.Equal(t, Answer{
+		Text:    "Boil vinegar, then rinse twice [1].",
+		Sources: []Source{{DocumentID: manual.ID, Title: "Kettle manual", Page: 2}},
+	}, answer)
+	assert.Contains(t, (*prompts)[0], `the appliance "Kettle": the row of appliances whose id is 1`)
+	assert.Contains(t, (*prompts)[1], "brand: Fellow")
+	assert.Contains(t, (*prompts)[1], "[1] Kettle manual, page 2:\nDescaling: boil a cup of vinegar")
+	assert.NotContains(t, (*prompts)[1], "MAX line")
+
+	_, err = a.Ask(context.Background(), Scope{Kind: "appliance", Ref: "toaster"}, "how?")
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestAskScopedToProject(t *testing.T) {
+	store := newStore(t)
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	for _, title := range []string{"Kitchen Remodel", "Kitchen Lighting"} {
+		require.NoError(t, store.CreateProject(&data.Project{Title: title, ProjectTypeID: types[0].ID, Status: data.ProjectStatusPlanned}))
+	}
+	project, err := store.FindScoped("project", "kitchen-remodel")
+	require.NoError(t, err)
+	quote := data.Quote{ProjectID: project.ID, TotalCents: 1_250_000}
+	require.NoError(t, store.CreateQuote(&quote, data.Vendor{Name: "Cabinet Co"}))
+
+	query := fmt.Sprintf("SELECT total_cents FROM quotes WHERE project_id = %d", project.ID)
+	model, prompts := fakeModel(t, query, "The quote is $12,500.")
+	a := &Assistant{Store: store, Model: model}
+	answer, err := a.Ask(context.Background(), Scope{Kind: "project", Ref: "kitchen-remodel"}, "what did the quote come to?")
+	require.NoError(t, err)
+	assert.Equal(t, Answer{Text: "The quote is $12,500.", SQL: query}, answer)
+	assert.Contains(t, (*prompts)[0], "quotes.project_id, rebates.project_id, warranties.project_id = 1")
+	assert.NotContains(t, (*prompts)[0], "appliances(")
+	assert.Contains(t, (*prompts)[1], "Query results:\ntotal_cents\n1250000\n")
+
+	_, err = a.Ask(context.Background(), Scope{Kind: "project", Ref: "kitchen"}, "what's left?")
+	require.ErrorIs(t, err, ErrScope)
+	assert.ErrorContains(t, err, "kitchen could be Kitchen Remodel, Kitchen Lighting")
+}
+
+func TestAskRecords(t *testing.T) {
+	store := newStore(t)
+	require.NoError(t, store.CreateAppliance(&data.Appliance{Name: "Furnace"}))
+
+	model, prompts := fakeModel(t,
+		"```sql\nSELECT name FROM appliances WHERE deleted_at IS NULL;\n```",
+		"You have a furnace.",
+	)
+	a := &Assistant{Store: store, Model: model}
+	answer, err := a.Ask(context.Background(), Scope{}, "what appliances do I have?")
+	require.NoError(t, err)
+	assert.Equal(t, Answer{Text: "You have a furnace.", SQL: "SELECT name FROM appliances WHERE deleted_at IS NULL"}, answer)
+	assert.Contains(t, (*prompts)[1], "name\nFurnace\n")
+
+	// A query that fails is sent back to be corrected once.
+	model, prompts = fakeModel(t, "SELECT * FROM users", "SELECT name FROM appliances WHERE name LIKE 'furnace'", "A furnace.")
+	a.Model = model
+	answer, err = a.Ask(context.Background(), Scope{}, "do I have a furnace?")
+	require.NoError(t, err)
+	assert.Contains(t, (*prompts)[1], "SELECT * FROM users\nThat query failed: ")
+	assert.Equal(t, "A furnace.", answer.Text)
+	assert.Equal(t, "SELECT name FROM appliances WHERE name LIKE 'furnace'", answer.SQL)
+	require.Len(t, answer.Attempts, 2)
+	assert.Equal(t, Attempt{SQL: answer.SQL, Rows: 1}, answer.Attempts[1])
+
+	// A query that fails or finds nothing twice falls back to the data dump.
+	model, prompts = fakeModel(t, "SELECT * FROM users", "SELECT name FROM appliances WHERE 0", "Just a furnace.")
+	a.Model = model
+	answer, err = a.Ask(context.Background(), Scope{}, "what appliances do I have?")
+	require.NoError(t, err)
+	assert.Equal(t, Answer{Text: "Just a furnace.", Attempts: []Attempt{
+		{SQL: "SELECT * FROM users", Error: answer.Attempts[0].Error},
+		{SQL: "SELECT name FROM appliances WHERE 0"},
+	}}, answer)
+	assert.Contains(t, answer.Attempts[0].Error, "users")
+	assert.Contains(t, (*prompts)[2], "### appliances")
+}
+
+func TestAskRedacts(t *testing.T) {
+	store := newStore(t)
+	furnace := data.Appliance{Name: "Furnace", SerialNumber: "FX-1234", Notes: "Warranty claim WC884213 filed."}
+	require.NoError(t, store.CreateAppliance(&furnace))
+	redact, err := NewRedactor([]string{"serial_number"}, []string{`WC\d+`})
+	require.NoError(t, err)
+
+	model, prompts := fakeModel(t, "SELECT name, serial_number, notes FROM appliances", "It's FX-something.")
+	a := &Assistant{Store: store, Model: model, Redact: redact}
+	answer, err := a.Ask(context.Background(), Scope{}, "what's the furnace's serial number?")
+	require.NoError(t, err)
+	assert.Equal(t, 2, answer.Redacted)
+	assert.Contains(t, (*prompts)[1], "Furnace\t[redacted]\tWarranty claim [redacted] filed.")
+	assert.NotContains(t, (*prompts)[1], "FX-1234")
+
+	model, prompts = fakeModel(t, "SELECT 1 WHERE 0", "It's FX-something.")
+	a.Model = model
+	answer, err = a.Ask(context.Background(), Scope{Kind: "appliance", Ref: "furnace"}, "what's its serial?")
+	require.NoError(t, err)
+	assert.Equal(t, 2, answer.Redacted)
+	assert.Contains(t, (*prompts)[1], "serial_number: [redacted]")
+	assert.NotContains(t, (*prompts)[1], "WC884213")
+
+	_, err = NewRedactor(nil, []string{"("})
+	assert.Error(t, err)
+}
+
+func TestAskUsesStageModels(t *testing.T) {
+	store := newStore(t)
+	require.NoError(t, store.CreateAppliance(&data.Appliance{Name: "Furnace"}))
+
+	main, mainPrompts := fakeModel(t)
+	sql, sqlPrompts := fakeModel(t, "SELECT name FROM appliances")
+	summary, summaryPrompts := fakeModel(t, "You have a furnace.")
+	a := &Assistant{Store: store, Model: main, SQLModel: sql, SummaryModel: summary}
+	answer, err := a.Ask(context.Background(), Scope{}, "what appliances do I have?")
+	require.NoError(t, err)
+	assert.Equal(t, "You have a furnace.", answer.Text)
+	assert.Empty(t, *mainPrompts)
+	require.Len(t, *sqlPrompts, 1)
+	assert.Contains(t, (*sqlPrompts)[0], "You write SQLite queries")
+	require.Len(t, *summaryPrompts, 1)
+	assert.Contains(t, (*summaryPrompts)[0], "name\nFurnace\n")
+}
+
+func TestAskOffline(t *testing.T) {
+	store := newStore(t)
+	categories, err := store.MaintenanceCategories()
+	require.NoError(t, err)
+	now := time.Now()
+	for name, serviced := range map[string]time.Time{
+		"Clean gutters":   now.AddDate(0, -7, 0),
+		"Replace filter":  now.AddDate(0, -5, -15),
+		"Flush the water": now.AddDate(0, -1, 0),
+	} {
+		require.NoError(t, store.CreateMaintenance(&data.MaintenanceItem{
+			Name: name, CategoryID: categories[0].ID, LastServicedAt: &serviced, IntervalMonths: 6,
+		}))
+	}
+	require.NoError(t, store.CreateExpense(&data.Expense{SpentOn: now, AmountCents: 12_345, Category: "plumbing", Description: "Valve"}))
+
+	down, _ := fakeModel(t)
+	down.BaseURL = "http://127.0.0.1:1"
+	a := &Assistant{Store: store, Model: down}
+
+	answer, err := a.Ask(context.Background(), Scope{}, "What's overdue?")
+	require.NoError(t, err)
+	assert.True(t, answer.Local)
+	assert.Contains(t, answer.Text, "1 maintenance item is overdue:\n- Clean gutters: due ")
+
+	answer, err = a.Ask(context.Background(), Scope{}, "what's due next")
+	require.NoError(t, err)
+	assert.Regexp(t, `^Maintenance due next:\n- Replace filter: due .*, in 1[45] days\n- Flush the water: `, answer.Text)
+
+	answer, err = a.Ask(context.Background(), Scope{}, "How much have I spent?")
+	require.NoError(t, err)
+	assert.Equal(t, Answer{Text: "Spent this year: $123.45\n- plumbing: $123.45\n", Local: true}, answer)
+
+	// Anything else still needs the model.
+	_, err = a.Ask(context.Background(), Scope{}, "how old is the roof?")
+	require.ErrorIs(t, err, llm.ErrUnreachable)
+	_, err = a.Ask(context.Background(), Scope{Kind: "appliance", Ref: "1"}, "what's overdue?")
+	require.Error(t, err)
+}
+
+func TestAskWithinBudget(t *testing.T) {
+	store := newStore(t)
+	require.NoError(t, store.CreateAppliance(&data.Appliance{Name: "Furnace"}))
+	model, prompts := fakeModel(t, "SELECT name FROM appliances", "You have a furnace.")
+	budget := &Budget{Store: store, Daily: 1_000_000, LocalOnly: true}
+	model.Meter = budget
+	a := &Assistant{Store: store, Model: model}
+
+	_, err := a.Ask(context.Background(), Scope{}, "what appliances do I have?")
+	require.NoError(t, err)
+	status, err := budget.Status()
+	require.NoError(t, err)
+	assert.Positive(t, status.TodayTokens)
+	assert.Equal(t, status.TodayTokens, status.MonthTokens)
+	assert.False(t, status.Spent)
+	usage, err := store.ListLLMUsage(time.Now())
+	require.NoError(t, err)
+	require.Len(t, usage, 1)
+	assert.Equal(t, int64(2), usage[0].Requests)
+	assert.Equal(t, int64(2), usage[0].EstimatedRequests, "the fake server doesn't report usage")
+
+	// Spent, common questions are still answered from the records.
+	budget.Daily = status.TodayTokens
+	answer, err := a.Ask(context.Background(), Scope{}, "What's overdue?")
+	require.NoError(t, err)
+	assert.True(t, answer.Local)
+	assert.Len(t, answer.Warnings, 1)
+	_, err = a.Ask(context.Background(), Scope{}, "how old is the roof?")
+	require.ErrorIs(t, err, ErrOverBudget)
+	assert.ErrorContains(t, err, "daily cap")
+
+	budget.LocalOnly = false
+	_, err = a.Ask(context.Background(), Scope{}, "What's overdue?")
+	require.ErrorIs(t, err, ErrOverBudget)
+	assert.Len(t, *prompts, 2, "nothing more reaches the model")
+}
+
+func TestAskFitsSmallWindow(t *testing.T) {
+	store := newStore(t)
+	for i := range 200 {
+		require.NoError(t, store.CreateAppliance(&data.Appliance{
+			Name: fmt.Sprintf("Appliance %03d", i), Notes: strings.Repeat("Serviced every spring. ", 3),
+		}))
+	}
+
+	model, prompts := fakeModel(t, "SELECT name, notes FROM appliances", "Lots of appliances.")
+	model.Window = 1500
+	a := &Assistant{Store: store, Model: model}
+	answer, err := a.Ask(context.Background(), Scope{}, "what appliances do I have?")
+	require.NoError(t, err)
+	chars, _, _ := model.PromptBudget(context.Background())
+	for _, p := range *prompts {
+		assert.LessOrEqual(t, len(p), chars)
+	}
+	assert.Contains(t, (*prompts)[0], "appliances(id, ")
+	assert.NotContains(t, (*prompts)[0], "INTEGER", "types go before tables")
+	assert.Regexp(t, `…and \d+ more rows\n$`, strings.SplitN((*prompts)[1], "Results:\n", 2)[1])
+	require.Len(t, answer.Warnings, 4)
+	assert.Contains(t, answer.Warnings[0], "reads about 1500 tokens at once, so the values some columns take were left out.")
+	assert.Contains(t, answer.Warnings[1], "the column types were left out")
+	assert.Regexp(t, `only \d+ of the \d+ tables were described`, answer.Warnings[2])
+	assert.Regexp(t, `given \d+ of the 200 rows the query found`, answer.Warnings[3])
+
+	// Falling back to the dump gives every table a share.
+	model, prompts = fakeModel(t, "SELECT nothing", "SELECT still nothing", "Lots of appliances.")
+	model.Window = 1500
+	a.Model = model
+	answer, err = a.Ask(context.Background(), Scope{}, "what appliances do I have?")
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len((*prompts)[2]), chars)
+	assert.Contains(t, (*prompts)[2], "### appliances (200 rows)")
+	assert.Contains(t, (*prompts)[2], "### project_types (12 rows)")
+	assert.Contains(t, answer.Warnings[len(answer.Warnings)-1], "some records were left out")
+}
+
+// The whole schema, with its types and known values, has to fit the
+// window assumed of a model that won't say how big its is, or every
+// question starts with a warning that something was cut.
+func TestSchemaFitsDefaultWindow(t *testing.T) {
+	store := newStore(t)
+	model, prompts := fakeModel(t, "SELECT name FROM project_types", "Plenty.")
+	a := &Assistant{Store: store, Model: model}
+	answer, err := a.Ask(context.Background(), Scope{}, "what kinds of projects are there?")
+	require.NoError(t, err)
+	assert.Empty(t, answer.Warnings)
+
+	chars, window, probed := model.PromptBudget(context.Background())
+	assert.Equal(t, llm.DefaultContextWindow, window)
+	assert.False(t, probed)
+	assert.LessOrEqual(t, len((*prompts)[0]), chars)
+	tables, err := store.TableNames()
+	require.NoError(t, err)
+	for _, name := range tables {
+		assert.Contains(t, (*prompts)[0], "\n"+name+"(", "every table is described")
+	}
+	assert.Contains(t, (*prompts)[0], "project_tasks(id INTEGER", "with its columns' types")
+	assert.Contains(t, (*prompts)[0], "Known values:")
+	assert.NotContains(t, (*prompts)[0], "version INTEGER", "the edit counter is left out")
+}
+
+func TestAskMatchesByMeaning(t *testing.T) {
+	store := newStore(t)
+	kettle := data.Appliance{Name: "Kettle", Notes: "Hard water here; watch for limescale."}
+	require.NoError(t, store.CreateAppliance(&kettle))
+	text := "Filling: up to the MAX line.\fLimescale: boil a cup of vinegar, then rinse twice."
+	require.NoError(t, store.CreateDocument(&data.Document{
+		Title: "Kettle manual", FileName: "kettle.txt", MIMEType: "text/plain", Data: []byte(text),
+		SizeBytes: int64(len(text)), EntityKind: data.DocumentEntityAppliance, EntityID: kettle.ID,
+	}))
+
+	ask := func(replies ...string) (Answer, []string) {
+		model, prompts := fakeModel(t, replies...)
+		model.EmbeddingModel = "nomic-embed-text"
+		a := &Assistant{Store: store, Model: model}
+		answer, err := a.Ask(context.Background(), Scope{Kind: "appliance", Ref: "kettle"}, "how do I get the white crust off?")
+		require.NoError(t, err)
+		return answer, *prompts
+	}
+	// No word of the question is in the manual, but the page on limescale
+	// is nearest in meaning.
+	answer, prompts := ask("SELECT 1 WHERE 0", "Boil vinegar [1].")
+	require.NotEmpty(t, answer.Sources)
+	assert.Equal(t, 2, answer.Sources[0].Page)
+	assert.Equal(t, []string{"embed 2", "embed 1"}, prompts[:2], "both pages, then the question")
+
+	// Pages already embedded aren't embedded again.
+	_, prompts = ask("SELECT 1 WHERE 0", "Boil vinegar [1].")
+	assert.Equal(t, "embed 1", prompts[0])
+
+	// Notes that bear on a question about the whole house come with the
+	// query results.
+	model, recorded := fakeModel(t, "SELECT name FROM appliances", "The kettle.")
+	model.EmbeddingModel = "nomic-embed-text"
+	a := &Assistant{Store: store, Model: model}
+	_, err := a.Ask(context.Background(), Scope{}, "what gets crusty with scale?")
+	require.NoError(t, err)
+	last := (*recorded)[len(*recorded)-1]
+	assert.Contains(t, last, "Notes written on records")
+	assert.Contains(t, last, `- appliances "Kettle": Hard water here; watch for limescale.`)
+
+	n, err := a.UpdateEmbeddings(context.Background(), false)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	n, err = a.UpdateEmbeddings(context.Background(), true)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+}
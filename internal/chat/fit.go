@@ -0,0 +1,215 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package chat
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cpcloud/webcasa/internal/llm"
+)
+
+// sizing is how much the model can be sent at once, in characters, and
+// what had to be cut to fit.
+type sizing struct {
+	chars  int
+	window int
+	// probed is false when the window is a guess.
+	probed   bool
+	warnings []string
+}
+
+// sizeFor asks model how much it reads at once.
+func (a *Assistant) sizeFor(ctx context.Context, model *llm.Client) *sizing {
+	chars, window, probed := model.PromptBudget(ctx)
+	// The extra context rides along with every system prompt.
+	chars -= len(model.ExtraContext)
+	return &sizing{chars: chars, window: window, probed: probed}
+}
+
+// answerSize sizes the prompts of the stage that answers, after size has
+// sized the query's: size itself when one model does both, else the
+// summary model's size, keeping what the query's stage had to cut.
+func (a *Assistant) answerSize(ctx context.Context, size *sizing) *sizing {
+	if a.summaryModel() == a.sqlModel() {
+		return size
+	}
+	next := a.sizeFor(ctx, a.summaryModel())
+	next.warnings = size.warnings
+	return next
+}
+
+// room is how many characters are left once the fixed parts of a prompt
+// are in, with a break after each, never less than a little.
+func (s *sizing) room(fixed ...string) int {
+	n := s.chars
+	for _, f := range fixed {
+		n -= len(f) + 1
+	}
+	return max(n, 200)
+}
+
+// warn notes what was cut to fit the model's window.
+func (s *sizing) warn(what string) {
+	msg := fmt.Sprintf("The model reads about %d tokens at once, so %s.", s.window, what)
+	if !s.probed {
+		msg += " The server didn't say how much it reads, so that's a guess; set context_window under [llm] if the model can take more."
+	}
+	if !slices.Contains(s.warnings, msg) {
+		s.warnings = append(s.warnings, msg)
+	}
+}
+
+// tableDesc is one table as the model is told about it.
+type tableDesc struct {
+	name    string
+	columns []string // each "name TYPE"
+}
+
+// fitSchema describes tables in room characters, dropping what matters
+// least first: the known values of columns, then the columns' types, then
+// the tables the question doesn't name.
+func (s *sizing) fitSchema(tables []tableDesc, hints, question string, room int) string {
+	render := func(tables []tableDesc, types bool) string {
+		var b strings.Builder
+		for _, t := range tables {
+			cols := t.columns
+			if !types {
+				cols = make([]string, len(t.columns))
+				for i, c := range t.columns {
+					cols[i], _, _ = strings.Cut(c, " ")
+				}
+			}
+			fmt.Fprintf(&b, "%s(%s)\n", t.name, strings.Join(cols, ", "))
+		}
+		return b.String()
+	}
+
+	full := render(tables, true)
+	if hints != "" {
+		if withHints := full + "\nKnown values:\n" + hints; len(withHints) <= room {
+			return withHints
+		}
+		s.warn("the values some columns take were left out")
+	}
+	if len(full) <= room {
+		return full
+	}
+	s.warn("the column types were left out")
+	bare := render(tables, false)
+	if len(bare) <= room {
+		return bare
+	}
+
+	// Tables the question names go first; the rest follow as they fit.
+	q := strings.ToLower(question)
+	named := func(t tableDesc) bool {
+		for _, word := range strings.Split(t.name, "_") {
+			if len(word) > 3 && strings.Contains(q, strings.TrimSuffix(word, "s")) {
+				return true
+			}
+		}
+		return false
+	}
+	ranked := slices.Clone(tables)
+	slices.SortStableFunc(ranked, func(x, y tableDesc) int {
+		switch nx, ny := named(x), named(y); {
+		case nx && !ny:
+			return -1
+		case ny && !nx:
+			return 1
+		}
+		return 0
+	})
+	var kept []tableDesc
+	used := 0
+	for _, t := range ranked {
+		line := render([]tableDesc{t}, false)
+		if used+len(line) > room {
+			continue
+		}
+		kept = append(kept, t)
+		used += len(line)
+	}
+	s.warn(fmt.Sprintf("only %d of the %d tables were described to it", len(kept), len(tables)))
+	return render(kept, false)
+}
+
+// fitRows writes as many query results as fit in room characters, saying
+// how many more there were.
+func (s *sizing) fitRows(b *strings.Builder, columns []string, rows [][]string, room int) {
+	used := 0
+	header := strings.Join(columns, "\t") + "\n"
+	b.WriteString(header)
+	used += len(header)
+	for i, row := range rows {
+		line := strings.Join(row, "\t") + "\n"
+		more := fmt.Sprintf("…and %d more rows\n", len(rows)-i)
+		need := len(line)
+		if i < len(rows)-1 {
+			// Leave room to say how many rows after it were cut.
+			need += len(more)
+		}
+		if used+need > room {
+			b.WriteString(more)
+			s.warn(fmt.Sprintf("it was given %d of the %d rows the query found", i, len(rows)))
+			return
+		}
+		b.WriteString(line)
+		used += len(line)
+	}
+}
+
+// fitDump cuts the data dump to room characters, giving each table an
+// even share, so a big table doesn't crowd the others out. Whatever a
+// small table leaves of its share goes to the bigger ones.
+func (s *sizing) fitDump(dump string, room int) string {
+	if len(dump) <= room {
+		return dump
+	}
+	var sections []string
+	for section := range strings.SplitSeq(dump, "\n### ") {
+		if len(sections) > 0 {
+			section = "### " + section
+		}
+		sections = append(sections, strings.TrimRight(section, "\n")+"\n\n")
+	}
+	order := make([]int, len(sections))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(x, y int) int { return len(sections[x]) - len(sections[y]) })
+
+	left := room
+	for n, i := range order {
+		share := left / (len(order) - n)
+		if len(sections[i]) > share {
+			sections[i] = cutLines(sections[i], share)
+		}
+		left -= len(sections[i])
+	}
+	s.warn("some records were left out of what it was given")
+	return strings.Join(sections, "")
+}
+
+// cutLines cuts a table of the dump to at most n characters at a line
+// break, noting how many rows were cut.
+func cutLines(text string, n int) string {
+	lines := strings.SplitAfter(strings.TrimRight(text, "\n"), "\n")
+	var b strings.Builder
+	for i, line := range lines {
+		more := fmt.Sprintf("…and %d more rows\n\n", len(lines)-i)
+		if b.Len()+len(line)+len(more) > n {
+			if i == 0 {
+				return ""
+			}
+			b.WriteString(more)
+			return b.String()
+		}
+		b.WriteString(line)
+	}
+	return b.String() + "\n\n"
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package chat
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// localList bounds how many items a local answer lists.
+const localList = 10
+
+// localAnswers are the common questions answered straight from the records
+// when the model can't be reached, tried in order.
+var localAnswers = []struct {
+	match  *regexp.Regexp
+	answer func(a *Assistant, question string, now time.Time) (string, error)
+}{
+	{regexp.MustCompile(`\b(overdue|past due|late)\b`), (*Assistant).localOverdue},
+	{regexp.MustCompile(`\b(spen[dt]|spending|expenses?)\b`), (*Assistant).localSpend},
+	{regexp.MustCompile(`\b(due|upcoming|coming up)\b`), (*Assistant).localNextDue},
+}
+
+// answerLocally answers a common question -- what's overdue, what's due
+// next, how much was spent -- without the model. ok is false for any
+// other question.
+func (a *Assistant) answerLocally(question string) (answer Answer, ok bool, err error) {
+	q := strings.ToLower(question)
+	for _, l := range localAnswers {
+		if !l.match.MatchString(q) {
+			continue
+		}
+		text, err := l.answer(a, q, time.Now())
+		if err != nil {
+			return Answer{}, false, err
+		}
+		return Answer{Text: text, Local: true}, true, nil
+	}
+	return Answer{}, false, nil
+}
+
+func (a *Assistant) localOverdue(_ string, now time.Time) (string, error) {
+	items, err := a.Store.OverdueMaintenance(now)
+	if err != nil {
+		return "", fmt.Errorf("list overdue maintenance: %w", err)
+	}
+	if len(items) == 0 {
+		return "Nothing is overdue.", nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s overdue:\n", countItems(len(items)))
+	writeMaintenance(&b, items, now)
+	return b.String(), nil
+}
+
+func (a *Assistant) localNextDue(_ string, now time.Time) (string, error) {
+	items, err := a.Store.ListMaintenanceDue(now, 365*24*time.Hour)
+	if err != nil {
+		return "", fmt.Errorf("list maintenance due: %w", err)
+	}
+	items = slices.DeleteFunc(items, func(m data.MaintenanceItem) bool { return m.NextDueAt.Before(now) })
+	if len(items) == 0 {
+		return "No maintenance is due in the next year.", nil
+	}
+	var b strings.Builder
+	b.WriteString("Maintenance due next:\n")
+	writeMaintenance(&b, items, now)
+	return b.String(), nil
+}
+
+// writeMaintenance lists maintenance items with their due dates, up to
+// localList of them.
+func writeMaintenance(b *strings.Builder, items []data.MaintenanceItem, now time.Time) {
+	for i, m := range items {
+		if i == localList {
+			fmt.Fprintf(b, "…and %d more\n", len(items)-i)
+			break
+		}
+		name := m.Name
+		if m.Appliance.Name != "" {
+			name += " (" + m.Appliance.Name + ")"
+		}
+		fmt.Fprintf(b, "- %s: due %s, %s\n", name, m.NextDueAt.Local().Format(time.DateOnly), relativeDays(*m.NextDueAt, now))
+	}
+}
+
+// relativeDays says how far t is from now in days.
+func relativeDays(t, now time.Time) string {
+	days := int(t.Sub(now).Hours() / 24)
+	switch {
+	case days == 0:
+		return "today"
+	case days == 1:
+		return "tomorrow"
+	case days == -1:
+		return "1 day ago"
+	case days < 0:
+		return fmt.Sprintf("%d days ago", -days)
+	}
+	return fmt.Sprintf("in %d days", days)
+}
+
+func countItems(n int) string {
+	if n == 1 {
+		return "1 maintenance item is"
+	}
+	return fmt.Sprintf("%d maintenance items are", n)
+}
+
+var allTime = regexp.MustCompile(`\b(all time|ever)\b`)
+
+// localSpend totals spending for this year, or this month, last year or
+// all time when the question asks for those, by category.
+func (a *Assistant) localSpend(question string, now time.Time) (string, error) {
+	year := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location())
+	since, until, period := year, now.AddDate(0, 0, 1), "this year"
+	switch {
+	case strings.Contains(question, "month"):
+		since, period = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()), "this month"
+	case strings.Contains(question, "last year"):
+		since, until, period = year.AddDate(-1, 0, 0), year, "last year"
+	case allTime.MatchString(question):
+		since, period = time.Time{}, "in all"
+	}
+	entries, err := a.Store.ListSpending(since, until)
+	if err != nil {
+		return "", fmt.Errorf("list spending: %w", err)
+	}
+	var total int64
+	byCategory := map[string]int64{}
+	for _, e := range entries {
+		total += e.AmountCents
+		byCategory[e.Category] += e.AmountCents
+	}
+	if total == 0 {
+		return fmt.Sprintf("Nothing was spent %s.", period), nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Spent %s: %s\n", period, data.FormatCents(total))
+	categories := slices.SortedFunc(maps.Keys(byCategory), func(x, y string) int {
+		return cmp.Or(cmp.Compare(byCategory[y], byCategory[x]), cmp.Compare(x, y))
+	})
+	for _, c := range categories {
+		fmt.Fprintf(&b, "- %s: %s\n", strings.ReplaceAll(c, "_", " "), data.FormatCents(byCategory[c]))
+	}
+	return b.String(), nil
+}
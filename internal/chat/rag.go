@@ -0,0 +1,143 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// When an embedding model is set, document pages and the notes on records
+// are matched to questions by meaning rather than by their words. Their
+// embeddings are cached, and only text that is new or changed since is
+// embedded again.
+
+const (
+	// embedBatch is how many texts are embedded in one request.
+	embedBatch = 32
+	// embedChars bounds how much of a text is embedded, since embedding
+	// models read less at once than chat models.
+	embedChars = 8000
+	// noteMatches is how many notes on records an answer from query
+	// results is given.
+	noteMatches = 5
+)
+
+// rag reports whether questions are matched to text by meaning.
+func (a *Assistant) rag() bool { return a.Model.EmbeddingModel != "" }
+
+// embed brings the cached embeddings of chunks up to date.
+func (a *Assistant) embed(ctx context.Context, chunks []data.EmbedChunk) error {
+	for start := 0; start < len(chunks); start += embedBatch {
+		batch := chunks[start:min(start+embedBatch, len(chunks))]
+		texts := make([]string, len(batch))
+		mask := a.redaction()
+		for i, c := range batch {
+			texts[i] = embedText(mask.text(c.Text))
+		}
+		vectors, err := a.Model.Embed(ctx, texts)
+		if err != nil {
+			return fmt.Errorf("embed: %w", err)
+		}
+		for i, c := range batch {
+			if err := a.Store.SaveEmbedding(a.Model.EmbeddingModel, c, vectors[i]); err != nil {
+				return fmt.Errorf("save embedding: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func embedText(text string) string {
+	if len(text) > embedChars {
+		text = strings.ToValidUTF8(text[:embedChars], "")
+	}
+	return text
+}
+
+// UpdateEmbeddings embeds every document page and note on a record that
+// hasn't been embedded as it is now, returning how many it embedded. With
+// rebuild, everything is embedded again, as after changing the embedding
+// model.
+func (a *Assistant) UpdateEmbeddings(ctx context.Context, rebuild bool) (int, error) {
+	if rebuild {
+		if _, err := a.Store.ClearEmbeddings(); err != nil {
+			return 0, fmt.Errorf("clear embeddings: %w", err)
+		}
+	}
+	pages, err := a.Store.StalePages(a.Model.EmbeddingModel, "", 0)
+	if err != nil {
+		return 0, err
+	}
+	notes, err := a.Store.StaleNotes(a.Model.EmbeddingModel)
+	if err != nil {
+		return 0, err
+	}
+	chunks := append(pages, notes...)
+	return len(chunks), a.embed(ctx, chunks)
+}
+
+// searchPages returns the pages of a record's documents that best match
+// question: by meaning when an embedding model is set, else, or when it
+// can't be used, by their words, saying so.
+func (a *Assistant) searchPages(ctx context.Context, rec data.ScopedRecord, question string) (pages []data.TextPage, warnings []string, err error) {
+	if err := a.Store.IndexDocumentText(rec.Kind, rec.ID); err != nil {
+		return nil, nil, err
+	}
+	if a.rag() {
+		pages, err := a.nearestPages(ctx, rec, question)
+		if err == nil {
+			return pages, nil, nil
+		}
+		warnings = append(warnings, fmt.Sprintf(
+			"The documents were matched to the question by their words, since the embedding model couldn't be used: %v.", err))
+	}
+	pages, err = a.Store.SearchDocumentText(rec.Kind, rec.ID, question, docPages)
+	if err != nil {
+		return nil, nil, fmt.Errorf("search documents: %w", err)
+	}
+	return pages, warnings, nil
+}
+
+func (a *Assistant) nearestPages(ctx context.Context, rec data.ScopedRecord, question string) ([]data.TextPage, error) {
+	stale, err := a.Store.StalePages(a.Model.EmbeddingModel, rec.Kind, rec.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.embed(ctx, stale); err != nil {
+		return nil, err
+	}
+	vectors, err := a.Model.Embed(ctx, []string{embedText(question)})
+	if err != nil {
+		return nil, fmt.Errorf("embed the question: %w", err)
+	}
+	return a.Store.NearestPages(a.Model.EmbeddingModel, rec.Kind, rec.ID, vectors[0], docPages)
+}
+
+// relatedNotes returns the notes on records nearest in meaning to
+// question, or none when no embedding model is set or it can't be used.
+func (a *Assistant) relatedNotes(ctx context.Context, question string) []data.EmbedChunk {
+	if !a.rag() {
+		return nil
+	}
+	stale, err := a.Store.StaleNotes(a.Model.EmbeddingModel)
+	if err != nil {
+		return nil
+	}
+	if err := a.embed(ctx, stale); err != nil {
+		return nil
+	}
+	vectors, err := a.Model.Embed(ctx, []string{embedText(question)})
+	if err != nil {
+		return nil
+	}
+	notes, err := a.Store.NearestNotes(a.Model.EmbeddingModel, vectors[0], noteMatches)
+	if err != nil {
+		return nil
+	}
+	return notes
+}
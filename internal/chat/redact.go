@@ -0,0 +1,90 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package chat
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Masked takes the place of each value that's redacted.
+const Masked = "[redacted]"
+
+// Redactor masks values, such as serial and policy numbers, in the
+// records, notes and document pages sent to a model. The question is sent
+// as typed.
+type Redactor struct {
+	// Columns are the columns whose values are masked, in any table.
+	// Query results are matched by their column names, so a query that
+	// renames a column gets past this; Patterns still apply.
+	Columns []string
+	// Patterns match text that's masked anywhere it's sent.
+	Patterns []*regexp.Regexp
+}
+
+// NewRedactor masks the values of columns and the text matching patterns.
+func NewRedactor(columns, patterns []string) (*Redactor, error) {
+	r := &Redactor{Columns: columns}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("redact %q: %w", p, err)
+		}
+		r.Patterns = append(r.Patterns, re)
+	}
+	return r, nil
+}
+
+// redaction masks what's sent for one question, counting the values it
+// masked. With no Redactor it masks nothing.
+type redaction struct {
+	r *Redactor
+	n int
+}
+
+func (a *Assistant) redaction() *redaction { return &redaction{r: a.Redact} }
+
+// column masks a value of column col.
+func (m *redaction) column(col, value string) string {
+	if m.r == nil {
+		return value
+	}
+	for _, c := range m.r.Columns {
+		if strings.EqualFold(c, col) {
+			m.n++
+			return Masked
+		}
+	}
+	return m.text(value)
+}
+
+// text masks what matches the patterns in s.
+func (m *redaction) text(s string) string {
+	if m.r == nil {
+		return s
+	}
+	for _, p := range m.r.Patterns {
+		s = p.ReplaceAllStringFunc(s, func(string) string {
+			m.n++
+			return Masked
+		})
+	}
+	return s
+}
+
+// rows returns query results with their values masked.
+func (m *redaction) rows(columns []string, rows [][]string) [][]string {
+	if m.r == nil {
+		return rows
+	}
+	out := make([][]string, len(rows))
+	for i, row := range rows {
+		out[i] = make([]string, len(row))
+		for j, v := range row {
+			out[i][j] = m.column(columns[j], v)
+		}
+	}
+	return out
+}
@@ -5,8 +5,14 @@ package config
 
 import (
 	"fmt"
+	"io"
+	"net/netip"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -15,12 +21,27 @@ import (
 	"github.com/adrg/xdg"
 
 	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/exports"
+	"github.com/cpcloud/webcasa/internal/hooks"
+	"github.com/cpcloud/webcasa/internal/photo"
+	"github.com/cpcloud/webcasa/internal/remind"
+	"github.com/cpcloud/webcasa/internal/sched"
 )
 
 // Config is the top-level application configuration, loaded from a TOML file.
 type Config struct {
 	LLM       LLM       `toml:"llm"`
 	Documents Documents `toml:"documents"`
+	Water     Water     `toml:"water"`
+	S3        S3        `toml:"s3"`
+	SMTP      SMTP      `toml:"smtp"`
+	Exports   []Export  `toml:"exports"`
+	Reminders Reminders `toml:"reminders"`
+	Hooks     []Hook    `toml:"hooks"`
+	Admin     Admin     `toml:"admin"`
+	Server    Server    `toml:"server"`
+	Database  Database  `toml:"database"`
+	Modules   Modules   `toml:"modules"`
 }
 
 // LLM holds settings for the local LLM inference backend.
@@ -43,6 +64,150 @@ type LLM struct {
 	// (ping, model listing, auto-detect). Go duration string, e.g. "5s",
 	// "10s", "500ms". Default: "5s".
 	Timeout string `toml:"timeout"`
+
+	// RequestTimeout bounds each request for an answer or embeddings, each
+	// retry getting its own. Go duration string. Default: "2m".
+	RequestTimeout string `toml:"request_timeout"`
+
+	// Retries is how many more times a request that got no answer, or an
+	// answer that the server is overloaded, is tried, waiting 1s, 2s, 4s
+	// and so on between tries. Default: 2; 0 turns retrying off.
+	Retries int `toml:"retries"`
+
+	// BreakerFailures is how many requests in a row must fail before the
+	// model is left alone for BreakerCooldown, questions failing at once
+	// meanwhile. Default: 3; 0 turns this off.
+	BreakerFailures int `toml:"breaker_failures"`
+
+	// BreakerCooldown is how long the model is left alone after
+	// BreakerFailures failures. Go duration string. Default: "5m".
+	BreakerCooldown string `toml:"breaker_cooldown"`
+
+	// ContextWindow is how many tokens the model reads at once, prompt and
+	// answer together. Chat prompts are cut to fit it. Optional; when zero
+	// it's asked of the server, or assumed to be 4096 if the server won't say.
+	ContextWindow int `toml:"context_window"`
+
+	// EmbeddingModel, when set, embeds document pages and the notes on
+	// records so chat questions are matched to them by meaning, e.g.
+	// "nomic-embed-text". Optional; empty matches them by their words.
+	EmbeddingModel string `toml:"embedding_model"`
+
+	// SQL, when its model is set, is the model that writes chat's database
+	// queries, where a small fast model does well. Optional; empty uses
+	// Model.
+	SQL LLMStage `toml:"sql"`
+
+	// Summary, when its model is set, is the model that answers chat
+	// questions from query results, where a larger model does better.
+	// Optional; empty uses Model.
+	Summary LLMStage `toml:"summary"`
+
+	// Budget caps the tokens the models may take. Optional; no caps by
+	// default.
+	Budget LLMBudget `toml:"budget"`
+
+	// Redact masks values in what chat sends a model that isn't on this
+	// machine. Optional; nothing is masked by default.
+	Redact LLMRedact `toml:"redact"`
+}
+
+// What happens once an LLM budget is spent.
+const (
+	// BudgetLocal answers the questions chat can answer from the records.
+	BudgetLocal = "local"
+	// BudgetOff turns chat off.
+	BudgetOff = "off"
+)
+
+// LLMBudget caps the tokens language models may take, counted as the
+// server reports them or, when it doesn't, as guessed from the text.
+type LLMBudget struct {
+	// DailyTokens and MonthlyTokens cap the tokens taken in a day and in a
+	// calendar month. Zero leaves either uncapped.
+	DailyTokens   int64 `toml:"daily_tokens"`
+	MonthlyTokens int64 `toml:"monthly_tokens"`
+
+	// WhenSpent is what chat does once a cap is reached: BudgetLocal (the
+	// default) or BudgetOff.
+	WhenSpent string `toml:"when_spent"`
+}
+
+// Enabled reports whether either cap is set.
+func (b LLMBudget) Enabled() bool { return b.DailyTokens > 0 || b.MonthlyTokens > 0 }
+
+// RequestTimeoutDuration returns the parsed per-request timeout, falling
+// back to DefaultLLMRequestTimeout if the value is empty or unparseable.
+func (l LLM) RequestTimeoutDuration() time.Duration {
+	return parseDurationOr(l.RequestTimeout, DefaultLLMRequestTimeout)
+}
+
+// BreakerCooldownDuration returns the parsed breaker cooldown, falling
+// back to DefaultLLMBreakerCooldown if the value is empty or unparseable.
+func (l LLM) BreakerCooldownDuration() time.Duration {
+	return parseDurationOr(l.BreakerCooldown, DefaultLLMBreakerCooldown)
+}
+
+func parseDurationOr(s string, fallback time.Duration) time.Duration {
+	if s == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return fallback
+	}
+	return d
+}
+
+// LLMRedact masks values, such as serial and policy numbers, in the
+// records, notes and document pages chat sends a language model, each
+// replaced with "[redacted]".
+type LLMRedact struct {
+	// Columns are the columns whose values are masked in any table, e.g.
+	// "serial_number".
+	Columns []string `toml:"columns"`
+
+	// Patterns are regular expressions whose matches are masked in any
+	// text sent.
+	Patterns []string `toml:"patterns"`
+
+	// Local masks values for a model served from this machine too.
+	Local bool `toml:"local"`
+}
+
+// Redacting reports whether values are masked for the configured model:
+// some are listed, and the model isn't served from this machine or Local
+// is set.
+func (l LLM) Redacting() bool {
+	if len(l.Redact.Columns) == 0 && len(l.Redact.Patterns) == 0 {
+		return false
+	}
+	return l.Redact.Local || !loopback(l.BaseURL)
+}
+
+// loopback reports whether a URL's host is this machine.
+func loopback(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	addr, err := netip.ParseAddr(host)
+	return err == nil && addr.IsLoopback()
+}
+
+// LLMStage picks the model for one stage of answering a chat question. It
+// is served from the same BaseURL as the main model.
+type LLMStage struct {
+	// Model is the model identifier passed in the stage's chat requests.
+	Model string `toml:"model"`
+
+	// ContextWindow is how many tokens the stage's model reads at once.
+	// Optional; when zero it's asked of the server, as for the main model.
+	ContextWindow int `toml:"context_window"`
 }
 
 // TimeoutDuration returns the parsed LLM timeout, falling back to
@@ -68,14 +233,660 @@ type Documents struct {
 	// is kept before being evicted on the next startup. Set to 0 to disable
 	// eviction. Default: 30.
 	CacheTTLDays int `toml:"cache_ttl_days"`
+
+	// CompressImages scales down and re-encodes JPEG and PNG uploads.
+	// Default: false.
+	CompressImages bool `toml:"compress_images"`
+
+	// ImageMaxDimension caps the longer side of a compressed image, in
+	// pixels. Default: 2048.
+	ImageMaxDimension int `toml:"image_max_dimension"`
+
+	// ImageQuality is the JPEG quality (1-100) compressed photos are saved
+	// at. Default: 82.
+	ImageQuality int `toml:"image_quality"`
+
+	// Storage is where document files are kept: StorageDatabase, in the
+	// database, or StorageFiles, as files in FilesDir named by their
+	// SHA-256, which keeps the database small and its backups quick.
+	// Files are moved to wherever this says when the server starts.
+	// Default: "database".
+	Storage string `toml:"storage"`
+
+	// FilesDir is the directory document files are kept in, with Storage
+	// "files". Don't share it between databases. Default: the database
+	// file's name with -files, beside it.
+	FilesDir string `toml:"files_dir"`
+}
+
+// Where document files are kept.
+const (
+	StorageDatabase = "database"
+	StorageFiles    = "files"
+)
+
+// FilesDirFor returns the directory document files are kept in for the
+// database at dbPath, or "" when FilesDir isn't set and the database
+// isn't a file.
+func (d Documents) FilesDirFor(dbPath string) string {
+	if d.FilesDir != "" {
+		return d.FilesDir
+	}
+	if dbPath == "" || dbPath == ":memory:" {
+		return ""
+	}
+	return strings.TrimSuffix(dbPath, filepath.Ext(dbPath)) + "-files"
+}
+
+// ImageOptions returns the recompression settings, or nil when uploads are
+// stored as they are.
+func (d Documents) ImageOptions() *photo.Options {
+	if !d.CompressImages {
+		return nil
+	}
+	return &photo.Options{MaxDimension: d.ImageMaxDimension, Quality: d.ImageQuality}
+}
+
+// Water holds the thresholds water test results are checked against. Set a
+// limit to 0 to disable that check.
+type Water struct {
+	// MaxHardnessGPG is the hardness, in grains per gallon, above which a
+	// test is flagged. Default: 7.
+	MaxHardnessGPG float64 `toml:"max_hardness_gpg"`
+
+	// MaxLeadPPB is the lead level, in parts per billion, above which a
+	// test is flagged. Default: 15 (the EPA action level).
+	MaxLeadPPB float64 `toml:"max_lead_ppb"`
+
+	// MinPH and MaxPH bound the acceptable pH range. Default: 6.5-8.5.
+	MinPH float64 `toml:"min_ph"`
+	MaxPH float64 `toml:"max_ph"`
+}
+
+// Limits converts the config section to the thresholds used by the store.
+func (w Water) Limits() data.WaterLimits {
+	return data.WaterLimits{
+		MaxHardnessGPG: w.MaxHardnessGPG,
+		MaxLeadPPB:     w.MaxLeadPPB,
+		MinPH:          w.MinPH,
+		MaxPH:          w.MaxPH,
+	}
+}
+
+// S3 holds credentials for s3:// export and backup destinations.
+type S3 struct {
+	// Endpoint is the base URL of an S3-compatible service. Leave empty
+	// for AWS.
+	Endpoint string `toml:"endpoint"`
+
+	// Region is the bucket's region. Default: us-east-1.
+	Region string `toml:"region"`
+
+	// AccessKeyID and SecretAccessKey authenticate uploads. Prefer the
+	// AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables.
+	AccessKeyID     string `toml:"access_key_id"`
+	SecretAccessKey string `toml:"secret_access_key"`
+}
+
+// SMTP holds the mail server used for mailto: export destinations.
+type SMTP struct {
+	Host string `toml:"host"`
+
+	// Port defaults to 587 (submission with STARTTLS).
+	Port int `toml:"port"`
+
+	Username string `toml:"username"`
+
+	// Password is better set through WEBCASA_SMTP_PASSWORD.
+	Password string `toml:"password"`
+
+	// From is the sender address. Defaults to Username.
+	From string `toml:"from"`
+}
+
+// Export is one scheduled export.
+type Export struct {
+	// Name identifies the export in logs and in "webcasa jobs".
+	Name string `toml:"name"`
+
+	// Kind is house_manual, emergency_bundle, spend_csv, weekly_summary,
+	// or backup.
+	Kind string `toml:"kind"`
+
+	// Every is a cron expression ("0 6 * * 1"), a shorthand (daily,
+	// weekly, monthly, quarterly, yearly), or an interval ("36h").
+	Every string `toml:"every"`
+
+	// Jitter delays the export by up to this Go duration so several
+	// exports on the same schedule don't all start at once. Optional.
+	Jitter string `toml:"jitter"`
+
+	// To is a directory path, s3://bucket/prefix, or mailto:address.
+	To string `toml:"to"`
+
+	// Budget is the monthly budget, in dollars, that a weekly_summary
+	// compares this month's spending against. Optional.
+	Budget int64 `toml:"budget"`
+}
+
+// smtpSettings are the [smtp] settings for sending mail.
+func (c Config) smtpSettings() exports.SMTPSettings {
+	return exports.SMTPSettings{
+		Host:     c.SMTP.Host,
+		Port:     c.SMTP.Port,
+		Username: c.SMTP.Username,
+		Password: c.SMTP.Password,
+		From:     c.SMTP.From,
+	}
+}
+
+// s3Settings are the [s3] credentials for s3:// destinations.
+func (c Config) s3Settings() exports.S3Settings {
+	return exports.S3Settings{
+		Endpoint:        c.S3.Endpoint,
+		Region:          c.S3.Region,
+		AccessKeyID:     c.S3.AccessKeyID,
+		SecretAccessKey: c.S3.SecretAccessKey,
+	}
+}
+
+// Destination resolves a directory path, s3://bucket/prefix or
+// mailto:address with the configured credentials.
+func (c Config) Destination(to string) (exports.Destination, error) {
+	return exports.ParseDestination(to, c.s3Settings(), c.smtpSettings())
+}
+
+// ExportJobs resolves the configured exports into runnable jobs.
+func (c Config) ExportJobs() ([]exports.Job, error) {
+	s3 := c.s3Settings()
+	mail := c.smtpSettings()
+	jobs := make([]exports.Job, 0, len(c.Exports))
+	seen := make(map[string]bool, len(c.Exports))
+	for i, e := range c.Exports {
+		if e.Name == "" {
+			return nil, fmt.Errorf("exports[%d]: name is required", i)
+		}
+		if seen[e.Name] {
+			return nil, fmt.Errorf("exports[%d]: duplicate name %q", i, e.Name)
+		}
+		seen[e.Name] = true
+		if !slices.Contains(exports.Kinds(), e.Kind) {
+			return nil, fmt.Errorf(
+				"exports[%d]: unknown kind %q -- use one of %s",
+				i, e.Kind, strings.Join(exports.Kinds(), ", "),
+			)
+		}
+		schedule, err := sched.Parse(e.Every)
+		if err != nil {
+			return nil, fmt.Errorf("exports[%d]: %w", i, err)
+		}
+		var jitter time.Duration
+		if e.Jitter != "" {
+			if jitter, err = time.ParseDuration(e.Jitter); err != nil || jitter < 0 {
+				return nil, fmt.Errorf("exports[%d]: invalid jitter %q", i, e.Jitter)
+			}
+		}
+		switch {
+		case e.Budget < 0:
+			return nil, fmt.Errorf("exports[%d]: budget can't be negative", i)
+		case e.Budget > 0 && e.Kind != exports.KindWeeklySummary:
+			return nil, fmt.Errorf("exports[%d]: budget is only for %s", i, exports.KindWeeklySummary)
+		}
+		to, err := exports.ParseDestination(e.To, s3, mail)
+		if err != nil {
+			return nil, fmt.Errorf("exports[%d]: %w", i, err)
+		}
+		jobs = append(jobs, exports.Job{
+			Name: e.Name, Kind: e.Kind, Schedule: schedule, Jitter: jitter, To: to,
+			BudgetCents: e.Budget * 100,
+		})
+	}
+	return jobs, nil
+}
+
+// Reminders sends what is coming due -- maintenance, warranty ends, the
+// insurance renewal, approvals, projects over budget -- to the configured
+// channels.
+type Reminders struct {
+	// Days is how far ahead to look. Default: 14.
+	Days int `toml:"days"`
+
+	// Every schedules reminders in the server, like an export's every.
+	// Optional; "webcasa remind" sends them on demand either way.
+	Every string `toml:"every"`
+
+	// To lists the channels: "stdout", "desktop", mailto:address,
+	// ntfy:https://ntfy.sh/topic, slack:<webhook URL>,
+	// discord:<webhook URL>, or an http(s) URL to POST JSON to.
+	// Default: stdout, unless Channels are given.
+	To []string `toml:"to"`
+
+	// Channels are more channels, each with a filter on what it gets.
+	Channels []ReminderChannel `toml:"channel"`
+
+	// Rules say how scheduled reminders nag.
+	Rules ReminderRules `toml:"rules"`
+}
+
+// ReminderRules hold off and escalate scheduled reminders. "webcasa
+// remind" ignores them.
+type ReminderRules struct {
+	// QuietHours is when nothing is sent, e.g. "22:00-07:00", in local
+	// time. What would have been sent goes out on the first run after.
+	QuietHours string `toml:"quiet_hours"`
+
+	// RenotifyDays sends a reminder again only after this many days,
+	// unless it has gone overdue since. Default: every run.
+	RenotifyDays int `toml:"renotify_days"`
+
+	// EscalateAfter sends an overdue reminder to EscalateTo, instead of
+	// the usual channels, once it has been sent this many times.
+	EscalateAfter int `toml:"escalate_after"`
+
+	// EscalateTo are the channels for escalated reminders, as in
+	// Reminders.To.
+	EscalateTo []string `toml:"escalate_to"`
+}
+
+// ReminderChannel is a reminder channel that gets only some reminders.
+type ReminderChannel struct {
+	// To is the channel, as in Reminders.To.
+	To string `toml:"to"`
+
+	// OverdueOnly sends only what is past due.
+	OverdueOnly bool `toml:"overdue_only"`
+
+	// Kinds sends only these kinds: maintenance, warranty,
+	// warranty_policy, insurance, approval, budget, rebate. Default: all.
+	Kinds []string `toml:"kinds"`
+
+	// MinAmount sends only reminders with an amount, i.e. change orders
+	// awaiting approval, projects over budget and rebates, of at least
+	// this many dollars.
+	MinAmount int64 `toml:"min_amount"`
+
+	// Digest is "daily" or "weekly" to send one message grouping
+	// everything, at most once a day or week. Default: every run.
+	Digest string `toml:"digest"`
+
+	// QuietHours replaces reminders.rules.quiet_hours for this channel.
+	QuietHours string `toml:"quiet_hours"`
+}
+
+// ReminderChannels resolves the configured reminder channels. stdout
+// writes to out.
+func (c Config) ReminderChannels(out io.Writer) ([]remind.Channel, error) {
+	to := c.Reminders.To
+	if len(to) == 0 && len(c.Reminders.Channels) == 0 {
+		to = []string{"stdout"}
+	}
+	mail := c.smtpSettings()
+	channels := make([]remind.Channel, 0, len(to))
+	for i, t := range to {
+		ch, err := remind.ParseChannel(strings.TrimSpace(t), mail, out)
+		if err != nil {
+			return nil, fmt.Errorf("reminders.to[%d]: %w", i, err)
+		}
+		channels = append(channels, ch)
+	}
+	for i, rc := range c.Reminders.Channels {
+		ch, err := remind.ParseChannel(strings.TrimSpace(rc.To), mail, out)
+		if err != nil {
+			return nil, fmt.Errorf("reminders.channel[%d]: %w", i, err)
+		}
+		for _, k := range rc.Kinds {
+			if !slices.Contains(remind.Kinds(), k) {
+				return nil, fmt.Errorf("reminders.channel[%d]: unknown kind %q -- use %s",
+					i, k, strings.Join(remind.Kinds(), ", "))
+			}
+		}
+		if rc.MinAmount < 0 {
+			return nil, fmt.Errorf("reminders.channel[%d]: min_amount can't be negative", i)
+		}
+		switch rc.Digest {
+		case "", remind.DigestDaily, remind.DigestWeekly:
+		default:
+			return nil, fmt.Errorf("reminders.channel[%d]: digest must be %q or %q, got %q",
+				i, remind.DigestDaily, remind.DigestWeekly, rc.Digest)
+		}
+		quiet, err := remind.ParseQuietHours(rc.QuietHours)
+		if err != nil {
+			return nil, fmt.Errorf("reminders.channel[%d]: %w", i, err)
+		}
+		channels = append(channels, &remind.Filtered{
+			Channel: ch,
+			Filter: remind.Filter{
+				OverdueOnly: rc.OverdueOnly, Kinds: rc.Kinds, MinAmountCents: rc.MinAmount * 100,
+			},
+			Digest:     rc.Digest,
+			ID:         strings.TrimSpace(rc.To),
+			QuietHours: quiet,
+		})
+	}
+	return channels, nil
+}
+
+// ReminderJob resolves the scheduled reminders, or nil when reminders.every
+// is unset.
+func (c Config) ReminderJob(out io.Writer) (*remind.Job, error) {
+	if c.Reminders.Every == "" {
+		return nil, nil
+	}
+	schedule, err := sched.Parse(c.Reminders.Every)
+	if err != nil {
+		return nil, fmt.Errorf("reminders: %w", err)
+	}
+	channels, err := c.ReminderChannels(out)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := c.ReminderRules(out)
+	if err != nil {
+		return nil, err
+	}
+	return &remind.Job{
+		Schedule: schedule, Days: c.Reminders.Days, Channels: channels, Rules: rules,
+		URL: strings.TrimRight(c.Server.URL, "/"),
+	}, nil
+}
+
+// ReminderRules resolves reminders.rules. stdout writes to out.
+func (c Config) ReminderRules(out io.Writer) (remind.Rules, error) {
+	r := c.Reminders.Rules
+	quiet, err := remind.ParseQuietHours(r.QuietHours)
+	if err != nil {
+		return remind.Rules{}, fmt.Errorf("reminders.rules: %w", err)
+	}
+	if r.RenotifyDays < 0 || r.EscalateAfter < 0 {
+		return remind.Rules{}, fmt.Errorf("reminders.rules: renotify_days and escalate_after can't be negative")
+	}
+	if r.EscalateAfter > 0 && len(r.EscalateTo) == 0 {
+		return remind.Rules{}, fmt.Errorf("reminders.rules: escalate_after needs escalate_to")
+	}
+	rules := remind.Rules{QuietHours: quiet, RenotifyDays: r.RenotifyDays, EscalateAfter: r.EscalateAfter}
+	for i, t := range r.EscalateTo {
+		ch, err := remind.ParseChannel(strings.TrimSpace(t), c.smtpSettings(), out)
+		if err != nil {
+			return remind.Rules{}, fmt.Errorf("reminders.rules.escalate_to[%d]: %w", i, err)
+		}
+		rules.EscalateTo = append(rules.EscalateTo, ch)
+	}
+	return rules, nil
+}
+
+// Admin holds settings for the admin panel.
+type Admin struct {
+	// Password unlocks the admin panel. The panel is disabled while it is
+	// empty. Better set through WEBCASA_ADMIN_PASSWORD.
+	Password string `toml:"password"`
+
+	// BackupDir is where "backup now" writes database copies. Default:
+	// a backups directory next to the default database location.
+	BackupDir string `toml:"backup_dir"`
+}
+
+// Server holds settings for running behind a reverse proxy or serving
+// other origins.
+type Server struct {
+	// BasePath is the URL prefix webcasa is served under, e.g. "/casa"
+	// behind a proxy that forwards /casa/ unchanged. The --base-path flag
+	// overrides it. Default: served at the root.
+	BasePath string `toml:"base_path"`
+
+	// CORSOrigins are the origins allowed to call the API from a browser,
+	// e.g. "https://home.example.com". Default: any origin ("*").
+	CORSOrigins []string `toml:"cors_origins"`
+
+	// TrustedProxies are addresses or CIDR ranges of reverse proxies whose
+	// X-Forwarded-For header is believed when finding a client's address,
+	// and whose X-Forwarded-Proto says whether the client used HTTPS.
+	// Default: none, so the connecting address and connection are used.
+	TrustedProxies []string `toml:"trusted_proxies"`
+
+	// GraphQL serves read-only GraphQL queries at /api/graphql alongside
+	// the REST API. Default: off.
+	GraphQL bool `toml:"graphql"`
+
+	// URL is the address webcasa is reached at, including any base path,
+	// e.g. "https://casa.example.com". Reminders link to it to mark things
+	// done, snooze them or open them. Default: reminders carry no links.
+	URL string `toml:"url"`
+}
+
+// Database holds checks run on the database file itself.
+type Database struct {
+	// VerifyOnOpen runs SQLite's quick_check and compares every document
+	// with its stored checksum before starting, refusing to start on a
+	// damaged database. It reads the whole file, so it slows startup on a
+	// large one. Default: off.
+	VerifyOnOpen bool `toml:"verify_on_open"`
+
+	// PassphraseCommand prints the passphrase of an encrypted database,
+	// e.g. ["secret-tool", "lookup", "service", "webcasa"] to read it from
+	// the system keyring. Run when WEBCASA_DB_PASSPHRASE isn't set.
+	// Default: prompt for it.
+	PassphraseCommand []string `toml:"passphrase_command"`
+
+	// UndoDepth is how many edits, deletions and restorations the undo log
+	// keeps, oldest dropped first. The log survives restarts. Default: 200.
+	UndoDepth int `toml:"undo_depth"`
+}
+
+// Modules turns the optional parts of webcasa on or off. A module that is
+// off has its page hidden and its records can't be changed through the API,
+// but nothing is deleted. Projects, maintenance, vendors, quotes, documents
+// and the house profile are always on. Default: all on.
+type Modules struct {
+	Appliances   bool `toml:"appliances"`
+	Incidents    bool `toml:"incidents"`
+	Devices      bool `toml:"devices"`
+	Landscape    bool `toml:"landscape"`
+	Pests        bool `toml:"pests"`
+	Water        bool `toml:"water"`
+	AirFilters   bool `toml:"air_filters"`
+	Rooms        bool `toml:"rooms"`
+	FloorPlans   bool `toml:"floor_plans"`
+	Walkthroughs bool `toml:"walkthroughs"`
+	Inventory    bool `toml:"inventory"`
+}
+
+func allModules() Modules {
+	return Modules{
+		Appliances: true, Incidents: true, Devices: true, Landscape: true, Pests: true,
+		Water: true, AirFilters: true, Rooms: true, FloorPlans: true, Walkthroughs: true,
+		Inventory: true,
+	}
+}
+
+// Disabled names the modules that are off, by their config keys.
+func (m Modules) Disabled() []string {
+	var off []string
+	for _, mod := range []struct {
+		name string
+		on   bool
+	}{
+		{"appliances", m.Appliances},
+		{"incidents", m.Incidents},
+		{"devices", m.Devices},
+		{"landscape", m.Landscape},
+		{"pests", m.Pests},
+		{"water", m.Water},
+		{"air_filters", m.AirFilters},
+		{"rooms", m.Rooms},
+		{"floor_plans", m.FloorPlans},
+		{"walkthroughs", m.Walkthroughs},
+		{"inventory", m.Inventory},
+	} {
+		if !mod.on {
+			off = append(off, mod.name)
+		}
+	}
+	return off
+}
+
+// Proxies parses the trusted proxy list. A bare address trusts just that
+// host.
+func (s Server) Proxies() ([]netip.Prefix, error) {
+	out := make([]netip.Prefix, 0, len(s.TrustedProxies))
+	for _, raw := range s.TrustedProxies {
+		raw = strings.TrimSpace(raw)
+		if p, err := netip.ParsePrefix(raw); err == nil {
+			out = append(out, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(raw)
+		if err != nil {
+			return nil, fmt.Errorf("server.trusted_proxies: %q is not an address or CIDR range", raw)
+		}
+		out = append(out, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return out, nil
+}
+
+// CleanBasePath normalizes a URL prefix to "/segment[/segment...]" without
+// a trailing slash. The root ("" or "/") becomes "".
+func CleanBasePath(p string) (string, error) {
+	p = strings.TrimSpace(p)
+	if p == "" || p == "/" {
+		return "", nil
+	}
+	if !strings.HasPrefix(p, "/") {
+		return "", fmt.Errorf("base path %q must start with /", p)
+	}
+	cleaned := path.Clean(p)
+	if cleaned != strings.TrimRight(p, "/") || strings.ContainsAny(p, "?#%") {
+		return "", fmt.Errorf("base path %q must be a plain path like /casa", p)
+	}
+	return cleaned, nil
+}
+
+// redactedMark replaces secrets in Redacted output.
+const redactedMark = "********"
+
+// Redacted returns a copy of the config with passwords and keys masked,
+// safe to show in the admin panel.
+func (c Config) Redacted() Config {
+	mask := func(s *string) {
+		if *s != "" {
+			*s = redactedMark
+		}
+	}
+	mask(&c.Admin.Password)
+	mask(&c.S3.AccessKeyID)
+	mask(&c.S3.SecretAccessKey)
+	mask(&c.SMTP.Password)
+	// A Slack or Discord webhook's URL is its secret.
+	chat := func(to string) string {
+		if kind, _, ok := strings.Cut(to, ":"); ok && (kind == "slack" || kind == "discord") {
+			return kind + ":" + redactedMark
+		}
+		return to
+	}
+	chats := func(list []string) []string {
+		out := make([]string, len(list))
+		for i, t := range list {
+			out[i] = chat(t)
+		}
+		return out
+	}
+	c.Reminders.To = chats(c.Reminders.To)
+	c.Reminders.Rules.EscalateTo = chats(c.Reminders.Rules.EscalateTo)
+	channels := slices.Clone(c.Reminders.Channels)
+	for i := range channels {
+		channels[i].To = chat(channels[i].To)
+	}
+	c.Reminders.Channels = channels
+	return c
+}
+
+// TOML renders the config as a TOML document.
+func (c Config) TOML() (string, error) {
+	var b strings.Builder
+	if err := toml.NewEncoder(&b).Encode(c); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Hook runs an external program when records change. The program gets the
+// event as JSON on stdin.
+type Hook struct {
+	// Name identifies the hook in logs and error messages.
+	Name string `toml:"name"`
+
+	// Command is the program followed by its arguments. It is run
+	// directly, not through a shell.
+	Command []string `toml:"command"`
+
+	// Events lists the event names to run on, as glob patterns:
+	// "project.created", "*.deleted", "appliance.*", "*".
+	Events []string `toml:"events"`
+
+	// Mode is "notify" (default): run in the background after the change
+	// is saved, or "validate": run before it and reject the change on a
+	// non-zero exit.
+	Mode string `toml:"mode"`
+
+	// Timeout is a Go duration. Default: 30s for notify, 5s for validate.
+	Timeout string `toml:"timeout"`
+}
+
+// HookList resolves the configured hooks.
+func (c Config) HookList() ([]hooks.Hook, error) {
+	out := make([]hooks.Hook, 0, len(c.Hooks))
+	seen := make(map[string]bool, len(c.Hooks))
+	for i, h := range c.Hooks {
+		if h.Name == "" {
+			return nil, fmt.Errorf("hooks[%d]: name is required", i)
+		}
+		if seen[h.Name] {
+			return nil, fmt.Errorf("hooks[%d]: duplicate name %q", i, h.Name)
+		}
+		seen[h.Name] = true
+		if len(h.Command) == 0 || h.Command[0] == "" {
+			return nil, fmt.Errorf("hooks[%d]: command is required", i)
+		}
+		if len(h.Events) == 0 {
+			return nil, fmt.Errorf("hooks[%d]: events is required -- use \"*\" for all", i)
+		}
+		for _, e := range h.Events {
+			if _, err := path.Match(e, ""); err != nil {
+				return nil, fmt.Errorf("hooks[%d]: bad event pattern %q", i, e)
+			}
+		}
+		mode := h.Mode
+		if mode == "" {
+			mode = hooks.ModeNotify
+		}
+		if mode != hooks.ModeNotify && mode != hooks.ModeValidate {
+			return nil, fmt.Errorf("hooks[%d]: mode must be notify or validate, got %q", i, h.Mode)
+		}
+		var timeout time.Duration
+		if h.Timeout != "" {
+			d, err := time.ParseDuration(h.Timeout)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("hooks[%d]: invalid timeout %q", i, h.Timeout)
+			}
+			timeout = d
+		}
+		out = append(out, hooks.Hook{
+			Name: h.Name, Command: h.Command, Events: h.Events, Mode: mode, Timeout: timeout,
+		})
+	}
+	return out, nil
 }
 
 const (
 	DefaultBaseURL      = "http://localhost:11434/v1"
 	DefaultModel        = "qwen3"
 	DefaultLLMTimeout   = 5 * time.Second
+	DefaultLLMRetries   = 2
+	DefaultLLMFailures  = 3
 	DefaultCacheTTLDays = 30
 	configRelPath       = "webcasa/config.toml"
+
+	DefaultLLMRequestTimeout  = 2 * time.Minute
+	DefaultLLMBreakerCooldown = 5 * time.Minute
 )
 
 // defaults returns a Config with all default values populated.
@@ -85,14 +896,38 @@ func defaults() Config {
 			BaseURL: DefaultBaseURL,
 			Model:   DefaultModel,
 			Timeout: DefaultLLMTimeout.String(),
+
+			RequestTimeout:  DefaultLLMRequestTimeout.String(),
+			Retries:         DefaultLLMRetries,
+			BreakerFailures: DefaultLLMFailures,
+			BreakerCooldown: DefaultLLMBreakerCooldown.String(),
 		},
 		Documents: Documents{
-			MaxFileSize:  data.MaxDocumentSize,
-			CacheTTLDays: DefaultCacheTTLDays,
+			MaxFileSize:       data.MaxDocumentSize,
+			CacheTTLDays:      DefaultCacheTTLDays,
+			ImageMaxDimension: photo.DefaultMaxDimension,
+			ImageQuality:      photo.DefaultQuality,
+		},
+		Water:     defaultWater(),
+		Reminders: Reminders{Days: remind.DefaultDays},
+		Modules:   allModules(),
+		Database:  Database{UndoDepth: data.DefaultUndoDepth},
+		Admin: Admin{
+			BackupDir: filepath.Join(xdg.DataHome, data.AppName, "backups"),
 		},
 	}
 }
 
+func defaultWater() Water {
+	l := data.DefaultWaterLimits()
+	return Water{
+		MaxHardnessGPG: l.MaxHardnessGPG,
+		MaxLeadPPB:     l.MaxLeadPPB,
+		MinPH:          l.MinPH,
+		MaxPH:          l.MaxPH,
+	}
+}
+
 // Path returns the expected config file path (XDG_CONFIG_HOME/webcasa/config.toml).
 func Path() string {
 	return filepath.Join(xdg.ConfigHome, configRelPath)
@@ -134,6 +969,52 @@ func LoadFromPath(path string) (Config, error) {
 			return cfg, fmt.Errorf("llm.timeout must be positive, got %s", cfg.LLM.Timeout)
 		}
 	}
+	for _, f := range [][2]string{
+		{"llm.request_timeout", cfg.LLM.RequestTimeout},
+		{"llm.breaker_cooldown", cfg.LLM.BreakerCooldown},
+	} {
+		if f[1] == "" {
+			continue
+		}
+		d, err := time.ParseDuration(f[1])
+		if err != nil {
+			return cfg, fmt.Errorf("%s: invalid duration %q -- use Go syntax like \"90s\" or \"5m\"", f[0], f[1])
+		}
+		if d <= 0 {
+			return cfg, fmt.Errorf("%s must be positive, got %s", f[0], f[1])
+		}
+	}
+	if cfg.LLM.Retries < 0 {
+		return cfg, fmt.Errorf("llm.retries must not be negative, got %d", cfg.LLM.Retries)
+	}
+	if cfg.LLM.BreakerFailures < 0 {
+		return cfg, fmt.Errorf("llm.breaker_failures must not be negative, got %d", cfg.LLM.BreakerFailures)
+	}
+	if cfg.LLM.Budget.DailyTokens < 0 || cfg.LLM.Budget.MonthlyTokens < 0 {
+		return cfg, fmt.Errorf("llm.budget token caps must not be negative")
+	}
+	switch cfg.LLM.Budget.WhenSpent {
+	case "":
+		cfg.LLM.Budget.WhenSpent = BudgetLocal
+	case BudgetLocal, BudgetOff:
+	default:
+		return cfg, fmt.Errorf("llm.budget.when_spent must be %q or %q, got %q",
+			BudgetLocal, BudgetOff, cfg.LLM.Budget.WhenSpent)
+	}
+	for i, p := range cfg.LLM.Redact.Patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return cfg, fmt.Errorf("llm.redact.patterns[%d]: %w", i, err)
+		}
+	}
+	if cfg.LLM.ContextWindow < 0 {
+		return cfg, fmt.Errorf("llm.context_window must not be negative, got %d", cfg.LLM.ContextWindow)
+	}
+	if cfg.LLM.SQL.ContextWindow < 0 {
+		return cfg, fmt.Errorf("llm.sql.context_window must not be negative, got %d", cfg.LLM.SQL.ContextWindow)
+	}
+	if cfg.LLM.Summary.ContextWindow < 0 {
+		return cfg, fmt.Errorf("llm.summary.context_window must not be negative, got %d", cfg.LLM.Summary.ContextWindow)
+	}
 
 	if cfg.Documents.MaxFileSize <= 0 {
 		return cfg, fmt.Errorf(
@@ -149,12 +1030,91 @@ func LoadFromPath(path string) (Config, error) {
 		)
 	}
 
+	if cfg.Documents.ImageMaxDimension <= 0 {
+		return cfg, fmt.Errorf(
+			"documents.image_max_dimension must be positive, got %d",
+			cfg.Documents.ImageMaxDimension,
+		)
+	}
+
+	if q := cfg.Documents.ImageQuality; q < 1 || q > 100 {
+		return cfg, fmt.Errorf("documents.image_quality must be between 1 and 100, got %d", q)
+	}
+
+	switch cfg.Documents.Storage {
+	case "":
+		cfg.Documents.Storage = StorageDatabase
+	case StorageDatabase, StorageFiles:
+	default:
+		return cfg, fmt.Errorf("documents.storage must be %q or %q, got %q",
+			StorageDatabase, StorageFiles, cfg.Documents.Storage)
+	}
+
+	w := cfg.Water
+	if w.MaxHardnessGPG < 0 || w.MaxLeadPPB < 0 || w.MinPH < 0 || w.MaxPH < 0 {
+		return cfg, fmt.Errorf("water limits must be non-negative")
+	}
+	if w.MinPH > 0 && w.MaxPH > 0 && w.MinPH > w.MaxPH {
+		return cfg, fmt.Errorf(
+			"water.min_ph (%g) must not exceed water.max_ph (%g)",
+			w.MinPH, w.MaxPH,
+		)
+	}
+
+	if cfg.Database.UndoDepth <= 0 {
+		return cfg, fmt.Errorf("database.undo_depth must be positive, got %d", cfg.Database.UndoDepth)
+	}
+
+	if _, err := cfg.ExportJobs(); err != nil {
+		return cfg, err
+	}
+	if _, err := cfg.HookList(); err != nil {
+		return cfg, err
+	}
+	if cfg.Reminders.Days <= 0 {
+		return cfg, fmt.Errorf("reminders.days must be positive, got %d", cfg.Reminders.Days)
+	}
+	if _, err := cfg.ReminderChannels(io.Discard); err != nil {
+		return cfg, err
+	}
+	if _, err := cfg.ReminderRules(io.Discard); err != nil {
+		return cfg, err
+	}
+	if _, err := cfg.ReminderJob(io.Discard); err != nil {
+		return cfg, err
+	}
+
+	base, err := CleanBasePath(cfg.Server.BasePath)
+	if err != nil {
+		return cfg, fmt.Errorf("server.base_path: %w", err)
+	}
+	cfg.Server.BasePath = base
+	if _, err := cfg.Server.Proxies(); err != nil {
+		return cfg, err
+	}
+	for _, origin := range cfg.Server.CORSOrigins {
+		if origin != "*" && !strings.Contains(origin, "://") {
+			return cfg, fmt.Errorf(
+				"server.cors_origins: %q should look like https://host[:port]", origin)
+		}
+	}
+	if u := cfg.Server.URL; u != "" {
+		parsed, err := url.Parse(u)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return cfg, fmt.Errorf("server.url: %q should look like https://host[:port][/path]", u)
+		}
+	}
+
 	return cfg, nil
 }
 
 // applyEnvOverrides lets environment variables override config-file values.
 // OLLAMA_HOST sets the base URL (with /v1 appended if missing).
-// WEBCASA_LLM_MODEL sets the model.
+// WEBCASA_LLM_MODEL sets the model, WEBCASA_EMBEDDING_MODEL the
+// embedding model, and WEBCASA_SQL_MODEL and WEBCASA_SUMMARY_MODEL the
+// models of chat's two stages. The standard AWS_* variables and
+// WEBCASA_SMTP_PASSWORD supply export delivery credentials, and
+// WEBCASA_ADMIN_PASSWORD unlocks the admin panel.
 func applyEnvOverrides(cfg *Config) {
 	if host := os.Getenv("OLLAMA_HOST"); host != "" {
 		host = strings.TrimRight(host, "/")
@@ -166,9 +1126,21 @@ func applyEnvOverrides(cfg *Config) {
 	if model := os.Getenv("WEBCASA_LLM_MODEL"); model != "" {
 		cfg.LLM.Model = model
 	}
+	if model := os.Getenv("WEBCASA_EMBEDDING_MODEL"); model != "" {
+		cfg.LLM.EmbeddingModel = model
+	}
+	if model := os.Getenv("WEBCASA_SQL_MODEL"); model != "" {
+		cfg.LLM.SQL.Model = model
+	}
+	if model := os.Getenv("WEBCASA_SUMMARY_MODEL"); model != "" {
+		cfg.LLM.Summary.Model = model
+	}
 	if timeout := os.Getenv("WEBCASA_LLM_TIMEOUT"); timeout != "" {
 		cfg.LLM.Timeout = timeout
 	}
+	if timeout := os.Getenv("WEBCASA_LLM_REQUEST_TIMEOUT"); timeout != "" {
+		cfg.LLM.RequestTimeout = timeout
+	}
 	if maxSize := os.Getenv("WEBCASA_MAX_DOCUMENT_SIZE"); maxSize != "" {
 		if n, err := strconv.ParseInt(maxSize, 10, 64); err == nil {
 			cfg.Documents.MaxFileSize = n
@@ -179,6 +1151,21 @@ func applyEnvOverrides(cfg *Config) {
 			cfg.Documents.CacheTTLDays = n
 		}
 	}
+	if id := os.Getenv("AWS_ACCESS_KEY_ID"); id != "" {
+		cfg.S3.AccessKeyID = id
+	}
+	if secret := os.Getenv("AWS_SECRET_ACCESS_KEY"); secret != "" {
+		cfg.S3.SecretAccessKey = secret
+	}
+	if region := os.Getenv("AWS_REGION"); region != "" && cfg.S3.Region == "" {
+		cfg.S3.Region = region
+	}
+	if pw := os.Getenv("WEBCASA_SMTP_PASSWORD"); pw != "" {
+		cfg.SMTP.Password = pw
+	}
+	if pw := os.Getenv("WEBCASA_ADMIN_PASSWORD"); pw != "" {
+		cfg.Admin.Password = pw
+	}
 }
 
 // ExampleTOML returns a commented config file suitable for writing as a
@@ -206,6 +1193,61 @@ model = "` + DefaultModel + `"
 # Increase if your LLM server is slow to respond.
 # timeout = "5s"
 
+# Timeout for each request for an answer, separate from the one above.
+# Default: "2m". Local models on modest hardware can be slow.
+# request_timeout = "2m"
+
+# How many more times a request is tried when the server doesn't answer
+# or says it's overloaded, waiting 1s, 2s, 4s... between tries. Default: 2.
+# retries = 2
+
+# After this many requests in a row fail, the model is left alone for
+# breaker_cooldown and questions fail at once; the Ask page says until
+# when. Default: 3 and "5m". Set breaker_failures = 0 to turn this off.
+# breaker_failures = 3
+# breaker_cooldown = "5m"
+
+# How many tokens the model reads at once. Chat prompts are cut to fit.
+# Default: asked of the server (Ollama, llama.cpp, vLLM, LM Studio), or 4096
+# if it won't say. Set it if answers warn that the size was a guess.
+# context_window = 8192
+
+# Optional: a model that embeds text, so chat matches questions to document
+# pages and notes by meaning rather than by their words. Embeddings are
+# cached; after changing the model run "webcasa embeddings rebuild".
+# embedding_model = "nomic-embed-text"
+
+# Optional: chat answers a question in two stages, writing a database query
+# and then answering from its results. Each can have its own model from the
+# same server: a small fast one for the queries, a larger one for answers.
+# Both default to the model above.
+# [llm.sql]
+# model = "qwen3:1.7b"
+# context_window = 8192
+#
+# [llm.summary]
+# model = "qwen3:14b"
+
+# Optional: caps on the tokens the models may take, so a paid API can't run
+# up a bill unnoticed. Tokens are counted as the server reports them, or
+# guessed from the text when it doesn't. Once a cap is reached, when_spent
+# = "local" still answers what chat can work out from the records (overdue
+# maintenance, what's due, spending); "off" turns chat off until the day or
+# month is over.
+# [llm.budget]
+# daily_tokens = 200000
+# monthly_tokens = 3000000
+# when_spent = "local"
+
+# Optional: values masked, each replaced with "[redacted]", in the records,
+# notes and document pages chat sends a model that isn't on this machine
+# (set local = true to mask them for one that is too). columns masks a
+# column's values in any table; patterns are regular expressions masked in
+# any text. Questions are sent as typed.
+# [llm.redact]
+# columns = ["serial_number", "insurance_policy", "address_line1", "address_line2"]
+# patterns = ['\b[A-Z]{2}\d{6,}\b']
+
 [documents]
 # Maximum file size (in bytes) for document imports. Default: 50 MiB.
 # max_file_size = 52428800
@@ -213,5 +1255,184 @@ model = "` + DefaultModel + `"
 # Days to keep extracted document cache entries before evicting on startup.
 # Set to 0 to disable eviction. Default: 30.
 # cache_ttl_days = 30
+
+# Scale down and re-encode JPEG and PNG uploads, e.g. 12 MP phone photos,
+# to keep the database small. The longer side is capped at
+# image_max_dimension pixels; JPEGs are saved at image_quality (1-100).
+# compress_images = false
+# image_max_dimension = 2048
+# image_quality = 82
+
+# Keep document files as files, named by their SHA-256, rather than in the
+# database, so large scans don't make it huge and backups slow. Files are
+# moved when the server starts, either way; back up files_dir along with
+# the database. files_dir defaults to the database's name with -files,
+# beside it.
+# storage = "files"
+# files_dir = "/srv/webcasa/files"
+
+[water]
+# Thresholds for water test alerts. Set a limit to 0 to disable it.
+# max_hardness_gpg = 7
+# max_lead_ppb = 15
+# min_ph = 6.5
+# max_ph = 8.5
+
+# Scheduled exports. "every" is a cron expression ("0 6 * * 1"), a shorthand
+# (daily, weekly, monthly, quarterly, yearly), or an interval like "36h";
+# "jitter" optionally delays each run by up to the given duration. Exports
+# are delivered to a directory, an S3 bucket, or an email address.
+# Emergency bundles are encrypted with the passphrase in
+# WEBCASA_BUNDLE_PASSPHRASE. A weekly_summary is an HTML email of what got
+# done, what's due next week, this month's spending against an optional
+# monthly "budget" in dollars, and what's about to expire. A backup is a
+# compressed snapshot of the database that "webcasa restore" can fetch
+# back. See "webcasa jobs list" for run history.
+#
+# [[exports]]
+# name = "manual"
+# kind = "house_manual"
+# every = "monthly"
+# to = "/srv/backups/webcasa"
+#
+# [[exports]]
+# name = "bundle"
+# kind = "emergency_bundle"
+# every = "quarterly"
+# to = "s3://my-bucket/webcasa"
+#
+# [[exports]]
+# name = "nightly"
+# kind = "backup"
+# every = "daily"
+# to = "s3://my-bucket/webcasa/db"
+#
+# [[exports]]
+# name = "spend"
+# kind = "spend_csv"
+# every = "0 7 * * 1"      # Mondays at 07:00
+# jitter = "10m"
+# to = "mailto:me@example.com"
+#
+# [[exports]]
+# name = "week"
+# kind = "weekly_summary"
+# every = "0 8 * * 0"      # Sundays at 08:00
+# budget = 1500
+# to = "mailto:me@example.com"
+
+# Reminders list the maintenance due, warranties ending and the insurance
+# renewal in the next "days" days. "webcasa remind" sends them once; set
+# "every" to have the server send them on a schedule. Channels are stdout,
+# desktop (notify-send, or osascript on macOS), mailto: (uses [smtp]),
+# ntfy:<topic URL>, slack:<incoming webhook URL>, discord:<webhook URL>, or
+# any http(s) URL, which gets the reminders as JSON. Change orders awaiting
+# approval, active projects over budget and rebates to apply for or chase
+# are reminded of too. A [[reminders.channel]] gets only the reminders its
+# filter keeps: overdue_only, kinds (maintenance, warranty,
+# warranty_policy, insurance, approval, budget, rebate) and min_amount in
+# dollars. With digest = "daily" or "weekly" it gets
+# everything grouped under headings, at most once a day or week.
+# [reminders.rules] make scheduled reminders nag in proportion: nothing in
+# quiet hours, a repeat only every renotify_days, and overdue items sent
+# escalate_after times moved to the escalate_to channels.
+#
+# [reminders]
+# days = 14
+# every = "0 8 * * *"      # every morning at 08:00
+# to = ["mailto:me@example.com", "ntfy:https://ntfy.sh/my-house"]
+#
+# [[reminders.channel]]
+# to = "discord:https://discord.com/api/webhooks/..."
+# overdue_only = true
+#
+# [[reminders.channel]]
+# to = "mailto:me@example.com"
+# digest = "weekly"
+#
+# [reminders.rules]
+# quiet_hours = "22:00-07:00"   # hold off overnight; a channel may set its own
+# renotify_days = 3             # repeat a reminder every 3 days, not every run
+# escalate_after = 3            # after 3 sends, overdue items go to escalate_to
+# escalate_to = ["ntfy:https://ntfy.sh/my-house-urgent"]
+#
+# [[reminders.channel]]
+# to = "slack:https://hooks.slack.com/services/..."
+# kinds = ["approval"]
+# min_amount = 1000
+
+# Hooks run a program whenever records change, with the event as JSON on
+# stdin (event, phase, entity, action, id, parent_id, at, request, record).
+# Events are named <entity>.<action> -- actions are created, updated,
+# deleted, and restored -- and matched as glob patterns. "notify" hooks run
+# in the background after the change; "validate" hooks run before it and
+# reject the change by exiting non-zero, with their output as the message.
+#
+# [[hooks]]
+# name = "tag-appliances"
+# command = ["/usr/local/bin/webcasa-tagger", "--verbose"]
+# events = ["appliance.created", "appliance.updated"]
+#
+# [[hooks]]
+# name = "require-vendor"
+# command = ["/usr/local/bin/check-quote"]
+# events = ["quote.*"]
+# mode = "validate"
+# timeout = "2s"
+
+# [server]
+# Behind a reverse proxy that forwards /casa/ without stripping it:
+# base_path = "/casa"
+# Origins allowed to call the API from a browser (default: any).
+# cors_origins = ["https://home.example.com"]
+# Proxies whose X-Forwarded-For and X-Forwarded-Proto headers are trusted.
+# trusted_proxies = ["127.0.0.1", "10.0.0.0/8"]
+# Serve read-only GraphQL queries at /api/graphql.
+# graphql = true
+# Where webcasa is reached, so reminders can link to mark done, snooze
+# and open what they're about.
+# url = "https://casa.example.com"
+
+# [database]
+# Check the database for damage before starting. Reads the whole file.
+# verify_on_open = true
+# Print the passphrase of an encrypted database, e.g. from the keyring.
+# passphrase_command = ["secret-tool", "lookup", "service", "webcasa"]
+# How many steps undo can go back, kept across restarts.
+# undo_depth = 200
+
+# [modules]
+# Turn off the parts of webcasa you don't use. Their pages are hidden and
+# their records can't be changed, but nothing is deleted. All are on by
+# default; projects, maintenance, vendors, quotes and documents always are.
+# appliances = true
+# incidents = true
+# devices = true
+# landscape = true
+# pests = true
+# water = true
+# air_filters = true
+# rooms = true
+# floor_plans = true
+# walkthroughs = true
+# inventory = true
+
+# [admin]
+# Unlocks the admin panel (backups, storage, jobs, config). Prefer
+# WEBCASA_ADMIN_PASSWORD. The panel is disabled while no password is set.
+# password = ""
+# backup_dir = "/srv/backups/webcasa"
+
+# [s3]
+# endpoint = ""            # empty for AWS, or e.g. "https://minio.local:9000"
+# region = "us-east-1"
+# access_key_id and secret_access_key: prefer AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY
+
+# [smtp]
+# host = "smtp.example.com"
+# port = 587
+# username = "me@example.com"
+# from = "webcasa <me@example.com>"
+# password: prefer WEBCASA_SMTP_PASSWORD
 `
 }
@@ -4,12 +4,15 @@
 package config
 
 import (
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/photo"
+	"github.com/cpcloud/webcasa/internal/remind"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -174,6 +177,147 @@ func TestCacheTTLDaysRejectsNegative(t *testing.T) {
 	assert.Contains(t, err.Error(), "must be non-negative")
 }
 
+func TestImageOptions(t *testing.T) {
+	cfg, err := LoadFromPath(filepath.Join(t.TempDir(), "nope.toml"))
+	require.NoError(t, err)
+	assert.Nil(t, cfg.Documents.ImageOptions(), "off by default")
+
+	path := writeConfig(t, "[documents]\ncompress_images = true\nimage_quality = 70\n")
+	cfg, err = LoadFromPath(path)
+	require.NoError(t, err)
+	opts := cfg.Documents.ImageOptions()
+	require.NotNil(t, opts)
+	assert.Equal(t, photo.DefaultMaxDimension, opts.MaxDimension)
+	assert.Equal(t, 70, opts.Quality)
+
+	_, err = LoadFromPath(writeConfig(t, "[documents]\nimage_quality = 101\n"))
+	assert.ErrorContains(t, err, "image_quality")
+	_, err = LoadFromPath(writeConfig(t, "[documents]\nimage_max_dimension = 0\n"))
+	assert.ErrorContains(t, err, "image_max_dimension")
+}
+
+func TestDocumentStorage(t *testing.T) {
+	cfg, err := LoadFromPath(filepath.Join(t.TempDir(), "nope.toml"))
+	require.NoError(t, err)
+	assert.Equal(t, StorageDatabase, cfg.Documents.Storage)
+	assert.Equal(t, "/data/webcasa-files", cfg.Documents.FilesDirFor("/data/webcasa.db"))
+	assert.Empty(t, cfg.Documents.FilesDirFor(":memory:"))
+
+	cfg, err = LoadFromPath(writeConfig(t, "[documents]\nstorage = \"files\"\nfiles_dir = \"/srv/files\"\n"))
+	require.NoError(t, err)
+	assert.Equal(t, StorageFiles, cfg.Documents.Storage)
+	assert.Equal(t, "/srv/files", cfg.Documents.FilesDirFor("/data/webcasa.db"))
+
+	_, err = LoadFromPath(writeConfig(t, "[documents]\nstorage = \"s3\"\n"))
+	assert.ErrorContains(t, err, "documents.storage")
+}
+
+func TestLLMContextWindow(t *testing.T) {
+	cfg, err := LoadFromPath(writeConfig(t, "[llm]\ncontext_window = 32768\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 32768, cfg.LLM.ContextWindow)
+
+	_, err = LoadFromPath(writeConfig(t, "[llm]\ncontext_window = -1\n"))
+	assert.ErrorContains(t, err, "context_window")
+}
+
+func TestEmbeddingModel(t *testing.T) {
+	cfg, err := LoadFromPath(writeConfig(t, "[llm]\nembedding_model = \"nomic-embed-text\"\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "nomic-embed-text", cfg.LLM.EmbeddingModel)
+
+	t.Setenv("WEBCASA_EMBEDDING_MODEL", "mxbai-embed-large")
+	cfg, err = LoadFromPath(writeConfig(t, "[llm]\nembedding_model = \"nomic-embed-text\"\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "mxbai-embed-large", cfg.LLM.EmbeddingModel)
+}
+
+func TestLLMStages(t *testing.T) {
+	cfg, err := LoadFromPath(writeConfig(t,
+		"[llm.sql]\nmodel = \"qwen3:1.7b\"\ncontext_window = 8192\n\n[llm.summary]\nmodel = \"qwen3:14b\"\n"))
+	require.NoError(t, err)
+	assert.Equal(t, LLMStage{Model: "qwen3:1.7b", ContextWindow: 8192}, cfg.LLM.SQL)
+	assert.Equal(t, LLMStage{Model: "qwen3:14b"}, cfg.LLM.Summary)
+
+	_, err = LoadFromPath(writeConfig(t, "[llm.summary]\ncontext_window = -1\n"))
+	assert.ErrorContains(t, err, "llm.summary.context_window")
+
+	t.Setenv("WEBCASA_SQL_MODEL", "phi4-mini")
+	t.Setenv("WEBCASA_SUMMARY_MODEL", "gemma3:12b")
+	cfg, err = LoadFromPath(writeConfig(t, ""))
+	require.NoError(t, err)
+	assert.Equal(t, "phi4-mini", cfg.LLM.SQL.Model)
+	assert.Equal(t, "gemma3:12b", cfg.LLM.Summary.Model)
+}
+
+func TestLLMRetryPolicy(t *testing.T) {
+	cfg, err := LoadFromPath(filepath.Join(t.TempDir(), "nope.toml"))
+	require.NoError(t, err)
+	assert.Equal(t, DefaultLLMRequestTimeout, cfg.LLM.RequestTimeoutDuration())
+	assert.Equal(t, DefaultLLMRetries, cfg.LLM.Retries)
+	assert.Equal(t, DefaultLLMFailures, cfg.LLM.BreakerFailures)
+	assert.Equal(t, DefaultLLMBreakerCooldown, cfg.LLM.BreakerCooldownDuration())
+
+	cfg, err = LoadFromPath(writeConfig(t,
+		"[llm]\nrequest_timeout = \"45s\"\nretries = 0\nbreaker_failures = 5\nbreaker_cooldown = \"1m\"\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 45*time.Second, cfg.LLM.RequestTimeoutDuration())
+	assert.Zero(t, cfg.LLM.Retries)
+	assert.Equal(t, 5, cfg.LLM.BreakerFailures)
+	assert.Equal(t, time.Minute, cfg.LLM.BreakerCooldownDuration())
+
+	_, err = LoadFromPath(writeConfig(t, "[llm]\nbreaker_cooldown = \"soon\"\n"))
+	assert.ErrorContains(t, err, "llm.breaker_cooldown: invalid duration")
+	_, err = LoadFromPath(writeConfig(t, "[llm]\nretries = -1\n"))
+	assert.ErrorContains(t, err, "llm.retries")
+
+	t.Setenv("WEBCASA_LLM_REQUEST_TIMEOUT", "3m")
+	cfg, err = LoadFromPath(filepath.Join(t.TempDir(), "nope.toml"))
+	require.NoError(t, err)
+	assert.Equal(t, 3*time.Minute, cfg.LLM.RequestTimeoutDuration())
+}
+
+func TestLLMBudget(t *testing.T) {
+	cfg, err := LoadFromPath(filepath.Join(t.TempDir(), "nope.toml"))
+	require.NoError(t, err)
+	assert.False(t, cfg.LLM.Budget.Enabled())
+	assert.Equal(t, BudgetLocal, cfg.LLM.Budget.WhenSpent)
+
+	cfg, err = LoadFromPath(writeConfig(t, "[llm.budget]\nmonthly_tokens = 3000000\nwhen_spent = \"off\"\n"))
+	require.NoError(t, err)
+	assert.True(t, cfg.LLM.Budget.Enabled())
+	assert.Equal(t, LLMBudget{MonthlyTokens: 3_000_000, WhenSpent: BudgetOff}, cfg.LLM.Budget)
+
+	_, err = LoadFromPath(writeConfig(t, "[llm.budget]\nwhen_spent = \"panic\"\n"))
+	assert.ErrorContains(t, err, "llm.budget.when_spent")
+	_, err = LoadFromPath(writeConfig(t, "[llm.budget]\ndaily_tokens = -5\n"))
+	assert.ErrorContains(t, err, "llm.budget")
+}
+
+func TestLLMRedact(t *testing.T) {
+	cfg, err := LoadFromPath(filepath.Join(t.TempDir(), "nope.toml"))
+	require.NoError(t, err)
+	assert.False(t, cfg.LLM.Redacting())
+
+	cfg, err = LoadFromPath(writeConfig(t, "[llm.redact]\ncolumns = [\"serial_number\"]\n"))
+	require.NoError(t, err)
+	assert.False(t, cfg.LLM.Redacting(), "the default model is on this machine")
+	cfg.LLM.Redact.Local = true
+	assert.True(t, cfg.LLM.Redacting())
+
+	cfg, err = LoadFromPath(writeConfig(t,
+		"[llm]\nbase_url = \"https://api.example.com/v1\"\n[llm.redact]\npatterns = ['\\d{9}']\n"))
+	require.NoError(t, err)
+	assert.True(t, cfg.LLM.Redacting())
+	assert.Equal(t, []string{`\d{9}`}, cfg.LLM.Redact.Patterns)
+
+	cfg.LLM.BaseURL = "http://127.0.0.1:8080/v1"
+	assert.False(t, cfg.LLM.Redacting())
+
+	_, err = LoadFromPath(writeConfig(t, "[llm.redact]\npatterns = ['(']\n"))
+	assert.ErrorContains(t, err, "llm.redact.patterns[0]")
+}
+
 func TestLLMTimeout(t *testing.T) {
 	t.Run("default", func(t *testing.T) {
 		cfg, err := LoadFromPath(filepath.Join(t.TempDir(), "nope.toml"))
@@ -216,3 +360,343 @@ func TestLLMTimeout(t *testing.T) {
 		assert.Contains(t, err.Error(), "must be positive")
 	})
 }
+
+func TestWaterLimitsDefault(t *testing.T) {
+	cfg, err := LoadFromPath(filepath.Join(t.TempDir(), "nope.toml"))
+	require.NoError(t, err)
+	assert.Equal(t, data.DefaultWaterLimits(), cfg.Water.Limits())
+}
+
+func TestWaterLimitsFromFile(t *testing.T) {
+	path := writeConfig(t, "[water]\nmax_lead_ppb = 5\nmax_hardness_gpg = 0\n")
+	cfg, err := LoadFromPath(path)
+	require.NoError(t, err)
+	assert.InDelta(t, 5.0, cfg.Water.MaxLeadPPB, 1e-9)
+	assert.Zero(t, cfg.Water.MaxHardnessGPG)
+	assert.InDelta(t, 8.5, cfg.Water.MaxPH, 1e-9)
+}
+
+func TestWaterLimitsRejectsInvertedPHRange(t *testing.T) {
+	path := writeConfig(t, "[water]\nmin_ph = 9\nmax_ph = 7\n")
+	_, err := LoadFromPath(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "min_ph")
+}
+
+func TestExportsFromFile(t *testing.T) {
+	path := writeConfig(t, `[smtp]
+host = "smtp.example.com"
+from = "house@example.com"
+
+[[exports]]
+name = "manual"
+kind = "house_manual"
+every = "monthly"
+to = "/tmp/exports"
+
+[[exports]]
+name = "spend"
+kind = "spend_csv"
+every = "0 7 * * 1"
+jitter = "10m"
+to = "mailto:me@example.com"
+
+[[exports]]
+name = "week"
+kind = "weekly_summary"
+every = "weekly"
+budget = 1500
+to = "mailto:me@example.com"
+`)
+	t.Setenv("WEBCASA_SMTP_PASSWORD", "hunter22")
+	cfg, err := LoadFromPath(path)
+	require.NoError(t, err)
+	assert.Equal(t, "hunter22", cfg.SMTP.Password)
+
+	jobs, err := cfg.ExportJobs()
+	require.NoError(t, err)
+	require.Len(t, jobs, 3)
+	assert.Equal(t, "monthly", jobs[0].Schedule.String())
+	assert.Equal(t, "/tmp/exports", jobs[0].To.String())
+	assert.Equal(t, "0 7 * * 1", jobs[1].Schedule.String())
+	assert.Equal(t, 10*time.Minute, jobs[1].Jitter)
+	assert.Equal(t, "mailto:me@example.com", jobs[1].To.String())
+	assert.Equal(t, int64(150_000), jobs[2].BudgetCents)
+}
+
+func TestRemindersFromFile(t *testing.T) {
+	cfg, err := LoadFromPath(writeConfig(t, ""))
+	require.NoError(t, err)
+	assert.Equal(t, 14, cfg.Reminders.Days)
+	job, err := cfg.ReminderJob(io.Discard)
+	require.NoError(t, err)
+	assert.Nil(t, job, "reminders aren't scheduled unless asked")
+	channels, err := cfg.ReminderChannels(io.Discard)
+	require.NoError(t, err)
+	require.Len(t, channels, 1)
+	assert.Equal(t, "stdout", channels[0].String())
+
+	cfg, err = LoadFromPath(writeConfig(t, `[reminders]
+days = 30
+every = "daily"
+to = ["desktop", "ntfy:https://ntfy.sh/elm-street"]
+`))
+	require.NoError(t, err)
+	job, err = cfg.ReminderJob(io.Discard)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, 30, job.Days)
+	require.Len(t, job.Channels, 2)
+	assert.Equal(t, "ntfy:https://ntfy.sh/elm-street", job.Channels[1].String())
+
+	_, err = LoadFromPath(writeConfig(t, "[reminders]\nto = [\"mailto:me@example.com\"]\n"))
+	assert.ErrorContains(t, err, "[smtp] host")
+	_, err = LoadFromPath(writeConfig(t, "[reminders]\nto = [\"pager\"]\n"))
+	assert.ErrorContains(t, err, "unknown channel")
+	_, err = LoadFromPath(writeConfig(t, "[reminders]\ndays = 0\n"))
+	assert.ErrorContains(t, err, "reminders.days")
+
+	cfg, err = LoadFromPath(writeConfig(t, `[reminders]
+[[reminders.channel]]
+to = "discord:https://discord.com/api/webhooks/1/hunter2"
+overdue_only = true
+
+[[reminders.channel]]
+to = "slack:https://hooks.slack.com/services/T0/B0/hunter2"
+kinds = ["approval", "budget"]
+min_amount = 1000
+digest = "weekly"
+`))
+	require.NoError(t, err)
+	channels, err = cfg.ReminderChannels(io.Discard)
+	require.NoError(t, err)
+	require.Len(t, channels, 2, "no stdout default when filtered channels are given")
+	f, ok := channels[1].(*remind.Filtered)
+	require.True(t, ok)
+	assert.Equal(t, []string{remind.KindApproval, remind.KindBudget}, f.Filter.Kinds)
+	assert.Equal(t, int64(100_000), f.Filter.MinAmountCents)
+	assert.Equal(t, remind.DigestWeekly, f.Digest)
+	_, err = LoadFromPath(writeConfig(t, "[[reminders.channel]]\nto = \"stdout\"\ndigest = \"hourly\"\n"))
+	assert.ErrorContains(t, err, "digest must be")
+	redacted, err := cfg.Redacted().TOML()
+	require.NoError(t, err)
+	assert.NotContains(t, redacted, "hunter2")
+	assert.Contains(t, cfg.Reminders.Channels[0].To, "hunter2", "redacting leaves the config alone")
+
+	cfg, err = LoadFromPath(writeConfig(t, `[reminders]
+every = "hourly"
+
+[[reminders.channel]]
+to = "desktop"
+quiet_hours = "21:00-08:00"
+
+[reminders.rules]
+quiet_hours = "22:00-07:00"
+renotify_days = 3
+escalate_after = 2
+escalate_to = ["discord:https://discord.com/api/webhooks/1/hunter2"]
+`))
+	require.NoError(t, err)
+	job, err = cfg.ReminderJob(io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, "22:00-07:00", job.Rules.QuietHours.String())
+	assert.Equal(t, 3, job.Rules.RenotifyDays)
+	require.Len(t, job.Rules.EscalateTo, 1)
+	assert.Equal(t, "21:00-08:00", job.Channels[0].(*remind.Filtered).QuietHours.String())
+	redacted, err = cfg.Redacted().TOML()
+	require.NoError(t, err)
+	assert.NotContains(t, redacted, "hunter2")
+	_, err = LoadFromPath(writeConfig(t, "[reminders.rules]\nescalate_after = 2\n"))
+	assert.ErrorContains(t, err, "needs escalate_to")
+	_, err = LoadFromPath(writeConfig(t, "[reminders.rules]\nquiet_hours = \"late\"\n"))
+	assert.ErrorContains(t, err, "quiet hours")
+
+	_, err = LoadFromPath(writeConfig(t, "[[reminders.channel]]\nto = \"stdout\"\nkinds = [\"bills\"]\n"))
+	assert.ErrorContains(t, err, "unknown kind")
+}
+
+func TestExportsRejectInvalid(t *testing.T) {
+	tests := []struct {
+		name, body, want string
+	}{
+		{"unknown kind", `kind = "pdf"` + "\nevery = \"weekly\"\nto = \"/tmp\"", "unknown kind"},
+		{"bad interval", `kind = "spend_csv"` + "\nevery = \"fortnightly\"\nto = \"/tmp\"", "invalid schedule"},
+		{"no destination", `kind = "spend_csv"` + "\nevery = \"weekly\"", "empty destination"},
+		{"mail without smtp", `kind = "spend_csv"` + "\nevery = \"weekly\"\nto = \"mailto:a@b.c\"", "[smtp] host"},
+		{"budget on csv", `kind = "spend_csv"` + "\nevery = \"weekly\"\nto = \"/tmp\"\nbudget = 10", "only for weekly_summary"},
+		{"negative budget", `kind = "weekly_summary"` + "\nevery = \"weekly\"\nto = \"/tmp\"\nbudget = -1", "negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, "[[exports]]\nname = \"x\"\n"+tt.body+"\n")
+			_, err := LoadFromPath(path)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+
+	path := writeConfig(t, `[[exports]]
+name = "a"
+kind = "spend_csv"
+every = "weekly"
+to = "/tmp"
+
+[[exports]]
+name = "a"
+kind = "house_manual"
+every = "monthly"
+to = "/tmp"
+`)
+	_, err := LoadFromPath(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "duplicate name")
+}
+
+func TestHooksFromFile(t *testing.T) {
+	path := writeConfig(t, `[[hooks]]
+name = "tagger"
+command = ["/usr/local/bin/tagger", "-v"]
+events = ["appliance.*"]
+
+[[hooks]]
+name = "check"
+command = ["/usr/local/bin/check"]
+events = ["quote.created"]
+mode = "validate"
+timeout = "2s"
+`)
+	cfg, err := LoadFromPath(path)
+	require.NoError(t, err)
+	list, err := cfg.HookList()
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "notify", list[0].Mode)
+	assert.Equal(t, []string{"/usr/local/bin/tagger", "-v"}, list[0].Command)
+	assert.Equal(t, "validate", list[1].Mode)
+	assert.Equal(t, 2*time.Second, list[1].Timeout)
+}
+
+func TestHooksRejectInvalid(t *testing.T) {
+	tests := []struct {
+		name, body, want string
+	}{
+		{"no command", `events = ["*"]`, "command is required"},
+		{"no events", `command = ["x"]`, "events is required"},
+		{"bad mode", "command = [\"x\"]\nevents = [\"*\"]\nmode = \"before\"", "mode must be"},
+		{"bad pattern", "command = [\"x\"]\nevents = [\"[\"]", "bad event pattern"},
+		{"bad timeout", "command = [\"x\"]\nevents = [\"*\"]\ntimeout = \"soon\"", "invalid timeout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, "[[hooks]]\nname = \"h\"\n"+tt.body+"\n")
+			_, err := LoadFromPath(path)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	path := writeConfig(t, "[smtp]\nhost = \"smtp.example.com\"\npassword = \"hunter22\"\n")
+	t.Setenv("WEBCASA_ADMIN_PASSWORD", "sesame")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "shh")
+	cfg, err := LoadFromPath(path)
+	require.NoError(t, err)
+	assert.Equal(t, "sesame", cfg.Admin.Password)
+
+	out, err := cfg.Redacted().TOML()
+	require.NoError(t, err)
+	assert.Contains(t, out, "smtp.example.com")
+	for _, secret := range []string{"hunter22", "sesame", "shh"} {
+		assert.NotContains(t, out, secret)
+	}
+	assert.Equal(t, "sesame", cfg.Admin.Password, "the original is untouched")
+}
+
+func TestServerFromFile(t *testing.T) {
+	path := writeConfig(t, `[server]
+base_path = "/casa/"
+cors_origins = ["https://home.example.com"]
+trusted_proxies = ["127.0.0.1", "10.1.2.3/8", "::1"]
+graphql = true
+url = "https://home.example.com/casa"
+`)
+	cfg, err := LoadFromPath(path)
+	require.NoError(t, err)
+	assert.Equal(t, "/casa", cfg.Server.BasePath)
+	assert.True(t, cfg.Server.GraphQL)
+	assert.Equal(t, "https://home.example.com/casa", cfg.Server.URL)
+	_, err = LoadFromPath(writeConfig(t, "[server]\nurl = \"casa.example.com\"\n"))
+	require.ErrorContains(t, err, "server.url")
+	assert.Equal(t, []string{"https://home.example.com"}, cfg.Server.CORSOrigins)
+	proxies, err := cfg.Server.Proxies()
+	require.NoError(t, err)
+	require.Len(t, proxies, 3)
+	assert.Equal(t, "127.0.0.1/32", proxies[0].String())
+	assert.Equal(t, "10.0.0.0/8", proxies[1].String())
+	assert.Equal(t, "::1/128", proxies[2].String())
+}
+
+func TestDatabaseFromFile(t *testing.T) {
+	cfg, err := LoadFromPath(writeConfig(t, "[llm]\n"))
+	require.NoError(t, err)
+	assert.False(t, cfg.Database.VerifyOnOpen)
+	assert.Empty(t, cfg.Database.PassphraseCommand)
+
+	cfg, err = LoadFromPath(writeConfig(t,
+		"[database]\nverify_on_open = true\npassphrase_command = [\"pass\", \"show\", \"webcasa\"]\n"))
+	require.NoError(t, err)
+	assert.True(t, cfg.Database.VerifyOnOpen)
+	assert.Equal(t, []string{"pass", "show", "webcasa"}, cfg.Database.PassphraseCommand)
+}
+
+func TestModulesFromFile(t *testing.T) {
+	cfg, err := LoadFromPath(writeConfig(t, "[llm]\n"))
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Modules.Disabled())
+
+	cfg, err = LoadFromPath(writeConfig(t, "[modules]\nwater = false\nfloor_plans = false\n"))
+	require.NoError(t, err)
+	assert.True(t, cfg.Modules.Appliances)
+	assert.Equal(t, []string{"water", "floor_plans"}, cfg.Modules.Disabled())
+}
+
+func TestServerRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name, body, want string
+	}{
+		{"relative base path", `base_path = "casa"`, "must start with /"},
+		{"dotted base path", `base_path = "/casa/../x"`, "plain path"},
+		{"bad proxy", `trusted_proxies = ["nginx"]`, "not an address"},
+		{"bare origin", `cors_origins = ["home.example.com"]`, "https://host"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, "[server]\n"+tt.body+"\n")
+			_, err := LoadFromPath(path)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestCleanBasePath(t *testing.T) {
+	for in, want := range map[string]string{"": "", "/": "", "/casa": "/casa", "/casa/": "/casa", "/a/b/": "/a/b"} {
+		got, err := CleanBasePath(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+}
+
+func TestUndoDepthFromFile(t *testing.T) {
+	cfg, err := LoadFromPath(writeConfig(t, ""))
+	require.NoError(t, err)
+	assert.Equal(t, data.DefaultUndoDepth, cfg.Database.UndoDepth)
+
+	cfg, err = LoadFromPath(writeConfig(t, "[database]\nundo_depth = 50\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 50, cfg.Database.UndoDepth)
+
+	_, err = LoadFromPath(writeConfig(t, "[database]\nundo_depth = -1\n"))
+	assert.ErrorContains(t, err, "database.undo_depth")
+}
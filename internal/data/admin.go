@@ -0,0 +1,120 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gorm.io/gorm"
+)
+
+// TableStats counts the rows of one table. Deleted counts soft-deleted rows
+// still kept for restore.
+type TableStats struct {
+	Table   string
+	Rows    int64
+	Deleted int64
+}
+
+// StorageStats summarizes what the database holds and how much room it
+// takes.
+type StorageStats struct {
+	DatabaseBytes int64
+	FreeBytes     int64
+	DocumentCount int64
+	DocumentBytes int64
+	// StoredFileBytes is the room taken by document files kept as files,
+	// outside the database.
+	StoredFileBytes int64
+	FloorPlanCount  int64
+	FloorPlanBytes  int64
+	Tables          []TableStats
+}
+
+// StorageStats measures the database file, the attachment blobs, and the
+// row count of every table.
+func (s *Store) StorageStats() (StorageStats, error) {
+	var st StorageStats
+	var pageSize, pageCount, freePages int64
+	if err := s.db.Raw("PRAGMA page_size").Scan(&pageSize).Error; err != nil {
+		return st, fmt.Errorf("read page size: %w", err)
+	}
+	if err := s.db.Raw("PRAGMA page_count").Scan(&pageCount).Error; err != nil {
+		return st, fmt.Errorf("read page count: %w", err)
+	}
+	if err := s.db.Raw("PRAGMA freelist_count").Scan(&freePages).Error; err != nil {
+		return st, fmt.Errorf("read free pages: %w", err)
+	}
+	st.DatabaseBytes = pageSize * pageCount
+	st.FreeBytes = pageSize * freePages
+
+	var docs struct{ N, Bytes int64 }
+	if err := s.db.Model(&Document{}).
+		Select("COUNT(*) AS n, COALESCE(SUM(" + ColSizeBytes + "), 0) AS bytes").
+		Scan(&docs).Error; err != nil {
+		return st, fmt.Errorf("measure documents: %w", err)
+	}
+	st.DocumentCount, st.DocumentBytes = docs.N, docs.Bytes
+	if err := s.db.Raw("SELECT COALESCE(SUM(" + ColSizeBytes + "), 0) FROM (SELECT DISTINCT " + ColStoredFile + ", " +
+		ColSizeBytes + " FROM documents WHERE " + ColStoredFile + " <> '')").
+		Scan(&st.StoredFileBytes).Error; err != nil {
+		return st, fmt.Errorf("measure document files: %w", err)
+	}
+
+	var plans struct{ N, Bytes int64 }
+	if err := s.db.Model(&FloorPlan{}).
+		Select("COUNT(*) AS n, COALESCE(SUM(LENGTH(" + ColImageData + ")), 0) AS bytes").
+		Scan(&plans).Error; err != nil {
+		return st, fmt.Errorf("measure floor plans: %w", err)
+	}
+	st.FloorPlanCount, st.FloorPlanBytes = plans.N, plans.Bytes
+
+	for _, model := range allModels() {
+		stmt := &gorm.Statement{DB: s.db}
+		if err := stmt.Parse(model); err != nil {
+			return st, fmt.Errorf("parse model: %w", err)
+		}
+		var total, live int64
+		if err := s.db.Unscoped().Model(model).Count(&total).Error; err != nil {
+			return st, fmt.Errorf("count %s: %w", stmt.Schema.Table, err)
+		}
+		if err := s.db.Model(model).Count(&live).Error; err != nil {
+			return st, fmt.Errorf("count %s: %w", stmt.Schema.Table, err)
+		}
+		st.Tables = append(st.Tables, TableStats{
+			Table: stmt.Schema.Table, Rows: live, Deleted: total - live,
+		})
+	}
+	return st, nil
+}
+
+// Backup writes a consistent, compacted copy of the database to path. It
+// refuses to overwrite an existing file.
+func (s *Store) Backup(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("backup %s already exists", path)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if s.sealed != nil {
+		// A backup of an encrypted database stays encrypted.
+		if err := s.sealed.backup(s, path); err != nil {
+			return fmt.Errorf("back up database: %w", err)
+		}
+		return nil
+	}
+	if err := s.db.Exec("VACUUM INTO ?", path).Error; err != nil {
+		return fmt.Errorf("back up database: %w", err)
+	}
+	return nil
+}
+
+// ListDeletions returns the most recent deletion records, newest first.
+func (s *Store) ListDeletions(limit int) ([]DeletionRecord, error) {
+	var records []DeletionRecord
+	err := s.db.Order(ColDeletedAt + " desc, " + ColID + " desc").Limit(limit).Find(&records).Error
+	return records, err
+}
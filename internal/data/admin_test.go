@@ -0,0 +1,55 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageStats(t *testing.T) {
+	store := newTestStore(t)
+	doc := Document{Title: "manual", FileName: "m.pdf", MIMEType: "application/pdf", Data: []byte("12345"), SizeBytes: 5}
+	require.NoError(t, store.CreateDocument(&doc))
+	v := Vendor{Name: "Acme"}
+	require.NoError(t, store.CreateVendor(&v))
+	require.NoError(t, store.DeleteVendor(v.ID))
+
+	st, err := store.StorageStats()
+	require.NoError(t, err)
+	assert.Positive(t, st.DatabaseBytes)
+	assert.Equal(t, int64(1), st.DocumentCount)
+	assert.Equal(t, int64(5), st.DocumentBytes)
+
+	tables := map[string]TableStats{}
+	for _, tb := range st.Tables {
+		tables[tb.Table] = tb
+	}
+	assert.Equal(t, TableStats{Table: "vendors", Rows: 0, Deleted: 1}, tables["vendors"])
+	assert.Equal(t, int64(1), tables["deletion_records"].Rows)
+
+	deletions, err := store.ListDeletions(10)
+	require.NoError(t, err)
+	require.Len(t, deletions, 1)
+	assert.Equal(t, DeletionEntityVendor, deletions[0].Entity)
+}
+
+func TestBackup(t *testing.T) {
+	store := newTestStore(t)
+	require.NoError(t, store.CreateVendor(&Vendor{Name: "Acme"}))
+	path := filepath.Join(t.TempDir(), "backup.db")
+	require.NoError(t, store.Backup(path))
+	require.ErrorContains(t, store.Backup(path), "already exists")
+
+	copied, err := Open(path)
+	require.NoError(t, err)
+	defer copied.Close()
+	vendors, err := copied.ListVendors(false)
+	require.NoError(t, err)
+	require.Len(t, vendors, 1)
+	assert.Equal(t, "Acme", vendors[0].Name)
+}
@@ -0,0 +1,311 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultAirFilterIntervalMonths is used when a filter spec has no
+	// explicit replacement interval.
+	defaultAirFilterIntervalMonths = 3
+
+	// airFilterMaintenanceCategory is the seeded category that auto-created
+	// filter replacement items are filed under.
+	airFilterMaintenanceCategory = "HVAC"
+)
+
+// AirFilterSpec records the filter an HVAC appliance takes, so nobody has to
+// pull the old one out to read the size. Each spec keeps a count of spare
+// filters on hand and is linked to an auto-created maintenance item whose
+// name carries the size and MERV rating.
+type AirFilterSpec struct {
+	ID                uint      `gorm:"primaryKey"`
+	ApplianceID       uint      `gorm:"index"`
+	Appliance         Appliance `gorm:"constraint:OnDelete:CASCADE;"`
+	Location          string
+	WidthIn           float64
+	HeightIn          float64
+	DepthIn           float64
+	MERV              int
+	Brand             string
+	PartNumber        string
+	StockOnHand       int
+	IntervalMonths    int
+	MaintenanceItemID *uint           `gorm:"index"`
+	MaintenanceItem   MaintenanceItem `gorm:"constraint:OnDelete:SET NULL;"`
+	Notes             string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	Version           int            `gorm:"not null;default:1"`
+	DeletedAt         gorm.DeletedAt `gorm:"index"`
+}
+
+// SizeLabel formats the nominal size as W x H x D, e.g. "16x25x1".
+func (f AirFilterSpec) SizeLabel() string {
+	dim := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	return dim(f.WidthIn) + "x" + dim(f.HeightIn) + "x" + dim(f.DepthIn)
+}
+
+// ProductLabel is the shopping-list name for the filter, e.g.
+// "16x25x1 MERV 11 air filter".
+func (f AirFilterSpec) ProductLabel() string {
+	label := f.SizeLabel()
+	if f.MERV > 0 {
+		label += fmt.Sprintf(" MERV %d", f.MERV)
+	}
+	return label + " air filter"
+}
+
+// AirFilterSuggestion is a replacement filter that should be swapped in (and
+// bought, if none are in stock) because its maintenance item is coming due.
+type AirFilterSuggestion struct {
+	Spec    AirFilterSpec
+	Title   string
+	DueAt   time.Time
+	InStock bool
+}
+
+func (s *Store) ListAirFilterSpecs(includeDeleted bool) ([]AirFilterSpec, error) {
+	var items []AirFilterSpec
+	db := s.db.
+		Preload("Appliance", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("MaintenanceItem", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Order(ColApplianceID + ", " + ColID)
+	if includeDeleted {
+		db = db.Unscoped()
+	}
+	return items, db.Find(&items).Error
+}
+
+func (s *Store) GetAirFilterSpec(id uint) (AirFilterSpec, error) {
+	var item AirFilterSpec
+	err := s.db.
+		Preload("Appliance").
+		Preload("MaintenanceItem", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		First(&item, id).Error
+	return item, err
+}
+
+func (s *Store) CreateAirFilterSpec(item *AirFilterSpec) error {
+	if err := normalizeAirFilterSpec(item); err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var appliance Appliance
+		if err := tx.First(&appliance, item.ApplianceID).Error; err != nil {
+			return fmt.Errorf("appliance not found or deleted")
+		}
+		if err := syncAirFilterMaintenance(tx, item, appliance); err != nil {
+			return err
+		}
+		return tx.Create(item).Error
+	})
+}
+
+// UpdateAirFilterSpec saves the spec and renames the linked maintenance item
+// so a size or MERV change shows up in the task list.
+func (s *Store) UpdateAirFilterSpec(item AirFilterSpec) error {
+	if err := normalizeAirFilterSpec(&item); err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var existing AirFilterSpec
+		if err := tx.First(&existing, item.ID).Error; err != nil {
+			return err
+		}
+		item.MaintenanceItemID = existing.MaintenanceItemID
+		var appliance Appliance
+		if err := tx.First(&appliance, item.ApplianceID).Error; err != nil {
+			return fmt.Errorf("appliance not found or deleted")
+		}
+		if err := syncAirFilterMaintenance(tx, &item, appliance); err != nil {
+			return err
+		}
+		return updateByIDWith(tx, &AirFilterSpec{}, item.ID, item)
+	})
+}
+
+// DeleteAirFilterSpec soft-deletes a spec. The linked maintenance item is
+// left in place so its service history stays visible.
+func (s *Store) DeleteAirFilterSpec(id uint) error {
+	return s.softDelete(&AirFilterSpec{}, DeletionEntityAirFilter, id)
+}
+
+func (s *Store) RestoreAirFilterSpec(id uint) error {
+	var item AirFilterSpec
+	if err := s.db.Unscoped().First(&item, id).Error; err != nil {
+		return err
+	}
+	if err := s.requireParentAlive(&Appliance{}, item.ApplianceID); err != nil {
+		return parentRestoreError("appliance", err)
+	}
+	return s.restoreEntity(&AirFilterSpec{}, DeletionEntityAirFilter, id)
+}
+
+// RecordAirFilterChange marks the filter as swapped at the given time: one
+// spare is taken from stock (never below zero) and a service entry is logged
+// against the linked maintenance item.
+func (s *Store) RecordAirFilterChange(id uint, changedAt time.Time) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var spec AirFilterSpec
+		if err := tx.First(&spec, id).Error; err != nil {
+			return err
+		}
+		if spec.StockOnHand > 0 {
+			if err := tx.Model(&spec).
+				Update(ColStockOnHand, spec.StockOnHand-1).Error; err != nil {
+				return err
+			}
+		}
+		if spec.MaintenanceItemID == nil {
+			return nil
+		}
+		entry := ServiceLogEntry{
+			MaintenanceItemID: *spec.MaintenanceItemID,
+			ServicedAt:        changedAt,
+			Notes:             "Installed " + spec.ProductLabel(),
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+		return tx.Model(&MaintenanceItem{}).
+			Where(ColID+" = ?", *spec.MaintenanceItemID).
+			Update(ColLastServicedAt, changedAt).Error
+	})
+}
+
+// ListAirFilterSuggestions returns a replacement suggestion for every filter
+// whose maintenance item is due on or before now + horizon, soonest first.
+// Filters never serviced are treated as due now.
+func (s *Store) ListAirFilterSuggestions(
+	now time.Time,
+	horizon time.Duration,
+) ([]AirFilterSuggestion, error) {
+	var specs []AirFilterSpec
+	err := s.db.
+		Preload("Appliance", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("MaintenanceItem").
+		Where(ColMaintenanceItemID + " IS NOT NULL").
+		Find(&specs).Error
+	if err != nil {
+		return nil, err
+	}
+	cutoff := now.Add(horizon)
+	var out []AirFilterSuggestion
+	for _, spec := range specs {
+		item := spec.MaintenanceItem
+		if item.ID == 0 || item.IntervalMonths <= 0 {
+			continue
+		}
+		due := now
+		if item.NextDueAt != nil {
+			due = *item.NextDueAt
+		}
+		if due.After(cutoff) {
+			continue
+		}
+		out = append(out, AirFilterSuggestion{
+			Spec:    spec,
+			Title:   fmt.Sprintf("%s for %s", spec.ProductLabel(), airFilterPlace(spec)),
+			DueAt:   due,
+			InStock: spec.StockOnHand > 0,
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].DueAt.Before(out[j].DueAt) })
+	return out, nil
+}
+
+func normalizeAirFilterSpec(item *AirFilterSpec) error {
+	if item.ApplianceID == 0 {
+		return fmt.Errorf("appliance is required")
+	}
+	if item.WidthIn <= 0 || item.HeightIn <= 0 || item.DepthIn <= 0 {
+		return fmt.Errorf("filter width, height, and depth must all be positive")
+	}
+	if item.MERV < 0 || item.MERV > 16 {
+		return fmt.Errorf("MERV rating must be between 1 and 16 (or 0 if unknown), got %d", item.MERV)
+	}
+	if item.StockOnHand < 0 {
+		return fmt.Errorf("stock on hand must be non-negative, got %d", item.StockOnHand)
+	}
+	if item.IntervalMonths < 0 {
+		return fmt.Errorf(
+			"replacement interval must be non-negative, got %d",
+			item.IntervalMonths,
+		)
+	}
+	if item.IntervalMonths == 0 {
+		item.IntervalMonths = defaultAirFilterIntervalMonths
+	}
+	item.Location = strings.TrimSpace(item.Location)
+	return nil
+}
+
+// airFilterPlace names where the filter goes: the appliance, plus the slot
+// location when an appliance takes more than one filter.
+func airFilterPlace(spec AirFilterSpec) string {
+	if spec.Location == "" {
+		return spec.Appliance.Name
+	}
+	return fmt.Sprintf("%s, %s", spec.Appliance.Name, spec.Location)
+}
+
+// airFilterMaintenanceName is the title given to auto-created filter items.
+func airFilterMaintenanceName(spec AirFilterSpec) string {
+	label := spec.SizeLabel()
+	if spec.MERV > 0 {
+		label += fmt.Sprintf(" MERV %d", spec.MERV)
+	}
+	return fmt.Sprintf("Replace %s filter (%s)", airFilterPlace(spec), label)
+}
+
+// syncAirFilterMaintenance creates or refreshes the maintenance item for a
+// filter spec.
+func syncAirFilterMaintenance(tx *gorm.DB, spec *AirFilterSpec, appliance Appliance) error {
+	named := *spec
+	named.Appliance = appliance
+	name := airFilterMaintenanceName(named)
+	if spec.MaintenanceItemID != nil {
+		return tx.Model(&MaintenanceItem{}).
+			Where(ColID+" = ?", *spec.MaintenanceItemID).
+			Updates(map[string]any{
+				ColName:           name,
+				ColApplianceID:    appliance.ID,
+				ColIntervalMonths: spec.IntervalMonths,
+			}).Error
+	}
+	var cat MaintenanceCategory
+	err := tx.Where(ColName+" = ?", airFilterMaintenanceCategory).First(&cat).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf(
+			"maintenance category %q not found -- run SeedDefaults first",
+			airFilterMaintenanceCategory,
+		)
+	}
+	if err != nil {
+		return err
+	}
+	applianceID := appliance.ID
+	item := MaintenanceItem{
+		Name:           name,
+		CategoryID:     cat.ID,
+		ApplianceID:    &applianceID,
+		IntervalMonths: spec.IntervalMonths,
+		Notes:          "Created automatically from an air filter spec.",
+	}
+	if err := tx.Create(&item).Error; err != nil {
+		return fmt.Errorf("create filter maintenance: %w", err)
+	}
+	spec.MaintenanceItemID = &item.ID
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFurnace(t *testing.T, store *Store) Appliance {
+	t.Helper()
+	furnace := Appliance{Name: "Furnace"}
+	require.NoError(t, store.CreateAppliance(&furnace))
+	return furnace
+}
+
+func TestAirFilterSpecLabels(t *testing.T) {
+	spec := AirFilterSpec{WidthIn: 16, HeightIn: 25, DepthIn: 1, MERV: 11}
+	assert.Equal(t, "16x25x1", spec.SizeLabel())
+	assert.Equal(t, "16x25x1 MERV 11 air filter", spec.ProductLabel())
+
+	odd := AirFilterSpec{WidthIn: 19.5, HeightIn: 21.5, DepthIn: 4.5}
+	assert.Equal(t, "19.5x21.5x4.5 air filter", odd.ProductLabel())
+}
+
+func TestCreateAirFilterSpecCreatesMaintenanceItem(t *testing.T) {
+	store := newTestStore(t)
+	furnace := newFurnace(t, store)
+	spec := AirFilterSpec{
+		ApplianceID: furnace.ID, Location: "Return grille",
+		WidthIn: 20, HeightIn: 25, DepthIn: 1, MERV: 8,
+	}
+	require.NoError(t, store.CreateAirFilterSpec(&spec))
+	require.NotNil(t, spec.MaintenanceItemID)
+
+	item, err := store.GetMaintenance(*spec.MaintenanceItemID)
+	require.NoError(t, err)
+	assert.Equal(t, "Replace Furnace, Return grille filter (20x25x1 MERV 8)", item.Name)
+	assert.Equal(t, defaultAirFilterIntervalMonths, item.IntervalMonths)
+	assert.Equal(t, airFilterMaintenanceCategory, item.Category.Name)
+	require.NotNil(t, item.ApplianceID)
+	assert.Equal(t, furnace.ID, *item.ApplianceID)
+
+	// Changing the size renames the task.
+	spec.MERV = 13
+	require.NoError(t, store.UpdateAirFilterSpec(spec))
+	item, err = store.GetMaintenance(*spec.MaintenanceItemID)
+	require.NoError(t, err)
+	assert.Equal(t, "Replace Furnace, Return grille filter (20x25x1 MERV 13)", item.Name)
+}
+
+func TestCreateAirFilterSpecValidation(t *testing.T) {
+	store := newTestStore(t)
+	furnace := newFurnace(t, store)
+	assert.Error(t, store.CreateAirFilterSpec(&AirFilterSpec{WidthIn: 1, HeightIn: 1, DepthIn: 1}))
+	assert.Error(t, store.CreateAirFilterSpec(&AirFilterSpec{ApplianceID: furnace.ID}))
+	assert.Error(t, store.CreateAirFilterSpec(&AirFilterSpec{
+		ApplianceID: furnace.ID, WidthIn: 16, HeightIn: 25, DepthIn: 1, MERV: 20,
+	}))
+	assert.Error(t, store.CreateAirFilterSpec(&AirFilterSpec{
+		ApplianceID: 9999, WidthIn: 16, HeightIn: 25, DepthIn: 1,
+	}))
+}
+
+func TestAirFilterSuggestionsAndStock(t *testing.T) {
+	store := newTestStore(t)
+	furnace := newFurnace(t, store)
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	spec := AirFilterSpec{
+		ApplianceID: furnace.ID, WidthIn: 16, HeightIn: 25, DepthIn: 1,
+		MERV: 11, StockOnHand: 1,
+	}
+	require.NoError(t, store.CreateAirFilterSpec(&spec))
+
+	// Never serviced: due now.
+	suggestions, err := store.ListAirFilterSuggestions(now, 14*24*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, suggestions, 1)
+	assert.Equal(t, "16x25x1 MERV 11 air filter for Furnace", suggestions[0].Title)
+	assert.True(t, suggestions[0].InStock)
+
+	require.NoError(t, store.RecordAirFilterChange(spec.ID, now))
+	got, err := store.GetAirFilterSpec(spec.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, got.StockOnHand)
+	logs, err := store.ListServiceLog(*spec.MaintenanceItemID, false)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, "Installed 16x25x1 MERV 11 air filter", logs[0].Notes)
+
+	// Freshly changed: nothing due in the next two weeks...
+	suggestions, err = store.ListAirFilterSuggestions(now, 14*24*time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, suggestions)
+
+	// ...but three months on it is, and there are no spares left.
+	suggestions, err = store.ListAirFilterSuggestions(now.AddDate(0, 3, 0), 0)
+	require.NoError(t, err)
+	require.Len(t, suggestions, 1)
+	assert.False(t, suggestions[0].InStock)
+
+	// Stock never goes negative.
+	require.NoError(t, store.RecordAirFilterChange(spec.ID, now.AddDate(0, 3, 0)))
+	got, err = store.GetAirFilterSpec(spec.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, got.StockOnHand)
+}
+
+func TestAirFilterSpecBlocksApplianceDelete(t *testing.T) {
+	store := newTestStore(t)
+	furnace := newFurnace(t, store)
+	spec := AirFilterSpec{ApplianceID: furnace.ID, WidthIn: 16, HeightIn: 20, DepthIn: 1}
+	require.NoError(t, store.CreateAirFilterSpec(&spec))
+
+	require.NoError(t, store.DeleteMaintenance(*spec.MaintenanceItemID))
+	require.ErrorContains(t, store.DeleteAppliance(furnace.ID), "air filter spec")
+	require.NoError(t, store.DeleteAirFilterSpec(spec.ID))
+	require.NoError(t, store.DeleteAppliance(furnace.ID))
+	require.ErrorContains(t, store.RestoreAirFilterSpec(spec.ID), "appliance is deleted")
+}
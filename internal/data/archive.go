@@ -0,0 +1,99 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// ArchiveTable is every live row of one table.
+type ArchiveTable struct {
+	// Table is the table's name, e.g. "projects".
+	Table string
+	// Rows is a slice of the table's model.
+	Rows any
+}
+
+// archiveModels lists the tables that hold the household's records, as
+// opposed to the app's own bookkeeping: settings, history, deletions, chat
+// inputs, job runs, usage counters and credentials.
+func archiveModels() []any {
+	return []any{
+		&HouseProfile{},
+		&ProjectType{},
+		&Vendor{},
+		&Project{},
+		&Quote{},
+		&QuoteLine{},
+		&ChangeOrder{},
+		&Invoice{},
+		&MaintenanceCategory{},
+		&Appliance{},
+		&MaintenanceItem{},
+		&ServiceLogEntry{},
+		&Incident{},
+		&Document{},
+		&SmartDevice{},
+		&LandscapeAsset{},
+		&PestTreatment{},
+		&WaterTest{},
+		&WaterFilterChange{},
+		&AirFilterSpec{},
+		&Room{},
+		&MaterialEstimate{},
+		&RoomFinish{},
+		&FloorPlan{},
+		&RoomHotspot{},
+		&Walkthrough{},
+		&WalkthroughItem{},
+		&HouseEvent{},
+		&HouseEventTask{},
+		&BidRequest{},
+		&Expense{},
+		&WarrantyClaim{},
+		&Warranty{},
+		&InventoryItem{},
+		&Rebate{},
+		&Contact{},
+		&UtilityBill{},
+		&ProjectStatusChange{},
+		&ProjectTask{},
+		&ClaimCorrespondence{},
+	}
+}
+
+// ArchiveTables loads every live record of every household table, oldest
+// first. File contents are left out; fetch them with GetDocument and
+// GetFloorPlan.
+func (s *Store) ArchiveTables() ([]ArchiveTable, error) {
+	tables := make([]ArchiveTable, 0, len(archiveModels()))
+	for _, model := range archiveModels() {
+		stmt := &gorm.Statement{DB: s.db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("parse model: %w", err)
+		}
+		var blobs []string
+		for _, f := range stmt.Schema.Fields {
+			if f.DBName != "" && f.FieldType == reflect.TypeOf([]byte(nil)) {
+				blobs = append(blobs, f.DBName)
+			}
+		}
+		rows := reflect.New(reflect.SliceOf(reflect.TypeOf(model).Elem()))
+		q := s.db.Model(model)
+		if _, ok := model.(*Document); ok {
+			// Documents have a computed size column to select too.
+			q = q.Select(listDocumentColumns)
+		} else if len(blobs) > 0 {
+			q = q.Omit(blobs...)
+		}
+		if err := q.Order(ColID).Find(rows.Interface()).Error; err != nil {
+			return nil, fmt.Errorf("load %s: %w", stmt.Schema.Table, err)
+		}
+		tables = append(tables, ArchiveTable{Table: stmt.Schema.Table, Rows: rows.Elem().Interface()})
+	}
+	return tables, nil
+}
@@ -0,0 +1,39 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveTables(t *testing.T) {
+	store := newTestStore(t)
+	require.NoError(t, store.SeedDemoData())
+	_, _, err := store.CreateAPIToken("ci", ScopeRead, 0)
+	require.NoError(t, err)
+
+	tables, err := store.ArchiveTables()
+	require.NoError(t, err)
+	byName := make(map[string]any, len(tables))
+	for _, tbl := range tables {
+		byName[tbl.Table] = tbl.Rows
+	}
+	assert.NotContains(t, byName, "api_tokens")
+	assert.NotContains(t, byName, "settings")
+
+	docs, ok := byName["documents"].([]Document)
+	require.True(t, ok)
+	require.NotEmpty(t, docs)
+	for _, d := range docs {
+		assert.Nil(t, d.Data, "file contents are left out")
+		assert.NotEmpty(t, d.FileName)
+	}
+	projects, ok := byName["projects"].([]Project)
+	require.True(t, ok)
+	require.NotEmpty(t, projects)
+	assert.Less(t, projects[0].ID, projects[len(projects)-1].ID)
+}
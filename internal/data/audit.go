@@ -0,0 +1,273 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Audit log actions.
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+	AuditPurge   = "purge"
+)
+
+// ActorLocal is the actor of changes made without one: from the command
+// line, by scheduled jobs, or by code that didn't say.
+const ActorLocal = "local"
+
+// tableAuditLog is AuditEntry's table.
+const tableAuditLog = "audit_log"
+
+// AuditEntry is one change to a record: its creation, an edit, its
+// deletion, restoration or purging. Entity is the table name. The log is
+// only ever appended to.
+type AuditEntry struct {
+	ID       uint `gorm:"primaryKey"`
+	At       time.Time
+	Action   string
+	Entity   string `gorm:"index:idx_audit_row,priority:1"`
+	TargetID uint   `gorm:"index:idx_audit_row,priority:2"`
+	// Changes holds each field a creation or edit set, as JSON mapping the
+	// column to its old and new values: {"name": ["Kettle", "Toaster"]}.
+	// A creation's old values are null.
+	Changes string
+	// Actor is who made the change: "user:NAME" for a signed-in account,
+	// "token:ID" for an API token, "admin" for the admin panel,
+	// "sitter:NAME" for a house sitter's page, "web" for the web app when
+	// nobody has to sign in, or ActorLocal.
+	Actor string
+}
+
+// TableName keeps the log's table name short.
+func (AuditEntry) TableName() string { return tableAuditLog }
+
+// errAuditAppendOnly is returned for an attempt to change or remove an
+// entry of the audit log.
+var errAuditAppendOnly = errors.New("the audit log can only be appended to")
+
+// actorKey holds the actor of a Store's changes in its context.
+type actorKey struct{}
+
+// As returns a Store whose changes are recorded in the audit log as made
+// by actor. It shares the database with s.
+func (s *Store) As(actor string) *Store {
+	a := *s
+	a.db = s.db.WithContext(context.WithValue(s.db.Statement.Context, actorKey{}, actor))
+	return &a
+}
+
+// actorOf returns the actor the statement's changes are made by.
+func actorOf(tx *gorm.DB) string {
+	if ctx := tx.Statement.Context; ctx != nil {
+		if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+			return actor
+		}
+	}
+	return ActorLocal
+}
+
+// audit appends entries to the audit log, made by the statement's actor.
+func audit(tx *gorm.DB, entries ...AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	now, actor := time.Now(), actorOf(tx)
+	for i := range entries {
+		entries[i].At, entries[i].Actor = now, actor
+	}
+	if err := tx.Session(&gorm.Session{NewDB: true}).Create(&entries).Error; err != nil {
+		return fmt.Errorf("append to audit log: %w", err)
+	}
+	return nil
+}
+
+// auditEdit records an edit from the field changes it made.
+func auditEdit(tx *gorm.DB, changes []FieldChange) error {
+	diff := make(map[string][2]json.RawMessage, len(changes))
+	for _, c := range changes {
+		diff[c.Field] = [2]json.RawMessage{json.RawMessage(c.OldValue), json.RawMessage(c.NewValue)}
+	}
+	raw, err := json.Marshal(diff)
+	if err != nil {
+		return err
+	}
+	return audit(tx, AuditEntry{
+		Action: AuditUpdate, Entity: changes[0].Entity, TargetID: changes[0].TargetID, Changes: string(raw),
+	})
+}
+
+// unauditedModels are the bookkeeping tables, whose rows aren't records
+// of the house and are left out of the audit log, along with accounts and
+// tokens.
+func unauditedModels() []any {
+	return []any{
+		&AuditEntry{}, &UndoEntry{}, &DeletionRecord{}, &FieldChange{}, &Setting{}, &ChatInput{},
+		&JobRun{}, &ReminderState{}, &ReminderSnooze{}, &UsageCounter{}, &Embedding{}, &Thumbnail{}, &LLMUsage{},
+		&APIToken{}, &SecondFactor{}, &User{}, &UserSession{},
+	}
+}
+
+// registerAuditing records the creation of every record in the audit log,
+// and refuses to change or remove the log's entries.
+func registerAuditing(db *gorm.DB) error {
+	tables := func(models []any) (map[string]bool, error) {
+		names := map[string]bool{}
+		for _, model := range models {
+			stmt := &gorm.Statement{DB: db}
+			if err := stmt.Parse(model); err != nil {
+				return nil, err
+			}
+			names[stmt.Schema.Table] = true
+		}
+		return names, nil
+	}
+	audited, err := tables(allModels())
+	if err != nil {
+		return err
+	}
+	skipped, err := tables(unauditedModels())
+	if err != nil {
+		return err
+	}
+	for table := range skipped {
+		delete(audited, table)
+	}
+
+	created := func(tx *gorm.DB) {
+		if tx.Error != nil || tx.Statement.Schema == nil || !audited[tx.Statement.Schema.Table] {
+			return
+		}
+		sch := tx.Statement.Schema
+		_, fields, err := trackedFields(tx, reflect.New(sch.ModelType).Interface())
+		if err != nil {
+			_ = tx.AddError(err)
+			return
+		}
+		var entries []AuditEntry
+		add := func(row reflect.Value) {
+			entry, err := creationEntry(tx.Statement.Context, sch, fields, row)
+			if err != nil {
+				_ = tx.AddError(err)
+				return
+			}
+			entries = append(entries, entry)
+		}
+		switch rows := tx.Statement.ReflectValue; rows.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := range rows.Len() {
+				add(reflect.Indirect(rows.Index(i)))
+			}
+		case reflect.Struct:
+			add(rows)
+		}
+		if err := audit(tx, entries...); err != nil {
+			_ = tx.AddError(err)
+		}
+	}
+	if err := db.Callback().Create().After("gorm:create").Register("webcasa:audit", created); err != nil {
+		return err
+	}
+
+	guard := func(tx *gorm.DB) {
+		if tx.Statement.Schema != nil && tx.Statement.Schema.Table == tableAuditLog {
+			_ = tx.AddError(errAuditAppendOnly)
+		}
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("webcasa:audit_update", guard); err != nil {
+		return err
+	}
+	return db.Callback().Delete().Before("gorm:delete").Register("webcasa:audit_delete", guard)
+}
+
+// creationEntry records a new row with the fields it was created with.
+func creationEntry(ctx context.Context, sch *schema.Schema, fields []*schema.Field, row reflect.Value) (AuditEntry, error) {
+	id, _ := sch.PrioritizedPrimaryField.ValueOf(ctx, row)
+	entry := AuditEntry{Action: AuditCreate, Entity: sch.Table}
+	if n, ok := id.(uint); ok {
+		entry.TargetID = n
+	}
+	diff := map[string][2]json.RawMessage{}
+	for _, f := range fields {
+		v, zero := f.ValueOf(ctx, row)
+		if zero {
+			continue
+		}
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return AuditEntry{}, fmt.Errorf("record %s: %w", f.DBName, err)
+		}
+		diff[f.DBName] = [2]json.RawMessage{json.RawMessage("null"), raw}
+	}
+	raw, err := json.Marshal(diff)
+	if err != nil {
+		return AuditEntry{}, err
+	}
+	entry.Changes = string(raw)
+	return entry, nil
+}
+
+// AuditTrail returns every recorded change to a row, oldest first. entity
+// is the table name, e.g. "projects".
+func (s *Store) AuditTrail(entity string, id uint) ([]AuditEntry, error) {
+	if _, err := s.historyModel(entity); err != nil {
+		return nil, err
+	}
+	var entries []AuditEntry
+	err := s.db.Where(ColEntity+" = ? AND "+ColTargetID+" = ?", entity, id).
+		Order(ColID).Find(&entries).Error
+	return entries, err
+}
+
+// MaxAuditPage is the most entries ListAuditLog returns at once.
+const MaxAuditPage = 1000
+
+// AuditQuery selects a page of the audit log. Entity and Actor narrow it
+// to one table or one actor when set, and Before to the entries older
+// than the one with that ID.
+type AuditQuery struct {
+	Entity string
+	Actor  string
+	Before uint
+	Limit  int
+}
+
+// ListAuditLog returns up to q.Limit entries of the audit log, newest
+// first, and the Before that picks up where they leave off, 0 on the last
+// page.
+func (s *Store) ListAuditLog(q AuditQuery) ([]AuditEntry, uint, error) {
+	if q.Limit <= 0 || q.Limit > MaxAuditPage {
+		return nil, 0, fmt.Errorf("page size must be between 1 and %d", MaxAuditPage)
+	}
+	db := s.db.Order(ColID + " desc").Limit(q.Limit + 1)
+	if q.Entity != "" {
+		db = db.Where(ColEntity+" = ?", q.Entity)
+	}
+	if q.Actor != "" {
+		db = db.Where(ColActor+" = ?", q.Actor)
+	}
+	if q.Before != 0 {
+		db = db.Where(ColID+" < ?", q.Before)
+	}
+	var entries []AuditEntry
+	if err := db.Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	if len(entries) <= q.Limit {
+		return entries, 0, nil
+	}
+	entries = entries[:q.Limit]
+	return entries, entries[len(entries)-1].ID, nil
+}
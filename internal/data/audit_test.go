@@ -0,0 +1,91 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditTrail(t *testing.T) {
+	store := newTestStore(t)
+	kettle := Appliance{Name: "Kettle", Brand: "Fellow"}
+	require.NoError(t, store.CreateAppliance(&kettle))
+	alice := store.As("user:alice")
+	kettle.Name = "Electric kettle"
+	require.NoError(t, alice.UpdateAppliance(kettle))
+	require.NoError(t, alice.DeleteAppliance(kettle.ID))
+	require.NoError(t, store.RestoreAppliance(kettle.ID))
+	_, err := store.Undo()
+	require.NoError(t, err)
+
+	trail, err := store.AuditTrail("appliances", kettle.ID)
+	require.NoError(t, err)
+	require.Len(t, trail, 5)
+	type step struct{ action, actor, changes string }
+	var got []step
+	for _, e := range trail {
+		assert.Equal(t, "appliances", e.Entity)
+		assert.Equal(t, kettle.ID, e.TargetID)
+		got = append(got, step{e.Action, e.Actor, e.Changes})
+	}
+	assert.Equal(t, []step{
+		{AuditCreate, ActorLocal, `{"brand":[null,"Fellow"],"name":[null,"Kettle"]}`},
+		{AuditUpdate, "user:alice", `{"name":["Kettle","Electric kettle"]}`},
+		{AuditDelete, "user:alice", ""},
+		{AuditRestore, ActorLocal, ""},
+		{AuditDelete, ActorLocal, ""},
+	}, got)
+
+	_, err = store.AuditTrail("nope", 1)
+	require.ErrorIs(t, err, ErrUnknownEntity)
+}
+
+func TestAuditLogIsAppendOnly(t *testing.T) {
+	store := newTestStore(t)
+	require.NoError(t, store.CreateVendor(&Vendor{Name: "Acme"}))
+	var entry AuditEntry
+	require.NoError(t, store.db.Where(ColEntity+" = ?", "vendors").First(&entry).Error)
+	require.ErrorIs(t, store.db.Model(&entry).Update(ColActor, "someone else").Error, errAuditAppendOnly)
+	require.ErrorIs(t, store.db.Delete(&entry).Error, errAuditAppendOnly)
+
+	// Bookkeeping isn't audited.
+	var n int64
+	require.NoError(t, store.db.Model(&AuditEntry{}).Where(ColEntity+" = ?", "settings").Count(&n).Error)
+	assert.Zero(t, n)
+}
+
+func TestListAuditLog(t *testing.T) {
+	store := newTestStore(t)
+	require.NoError(t, store.CreateVendor(&Vendor{Name: "Acme"}))
+	alice := store.As("user:alice")
+	for _, name := range []string{"Kettle", "Toaster", "Blender"} {
+		require.NoError(t, alice.CreateAppliance(&Appliance{Name: name}))
+	}
+
+	page, before, err := store.ListAuditLog(AuditQuery{Entity: "appliances", Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Contains(t, page[0].Changes, "Blender")
+	assert.Contains(t, page[1].Changes, "Toaster")
+	require.NotZero(t, before)
+	page, before, err = store.ListAuditLog(AuditQuery{Entity: "appliances", Before: before, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Contains(t, page[0].Changes, "Kettle")
+	assert.Zero(t, before)
+
+	page, _, err = store.ListAuditLog(AuditQuery{Actor: ActorLocal, Limit: 100})
+	require.NoError(t, err)
+	require.NotEmpty(t, page)
+	for _, e := range page {
+		assert.Equal(t, ActorLocal, e.Actor)
+		assert.NotEqual(t, "appliances", e.Entity)
+	}
+
+	_, _, err = store.ListAuditLog(AuditQuery{})
+	require.Error(t, err)
+}
@@ -0,0 +1,146 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BidRequest records that a project's bid request went to a vendor. Until
+// the vendor's quote for the project comes in, or the request is closed
+// (the vendor passed, or it no longer matters), it comes up as a reminder
+// on FollowUpOn. Quoted is worked out when listing.
+type BidRequest struct {
+	ID         uint    `gorm:"primaryKey"`
+	ProjectID  uint    `gorm:"index"`
+	Project    Project `gorm:"constraint:OnDelete:CASCADE;"`
+	VendorID   uint    `gorm:"index"`
+	Vendor     Vendor  `gorm:"constraint:OnDelete:CASCADE;"`
+	SentAt     time.Time
+	FollowUpOn *time.Time `gorm:"index"`
+	ClosedAt   *time.Time
+	Quoted     bool `gorm:"->;-:migration"`
+	Notes      string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// quotedExpr is true when the vendor has a live quote for the project.
+const quotedExpr = "EXISTS (SELECT 1 FROM quotes WHERE quotes.project_id = bid_requests.project_id" +
+	" AND quotes.vendor_id = bid_requests.vendor_id AND quotes.deleted_at IS NULL)"
+
+func bidRequestQuery(db *gorm.DB) *gorm.DB {
+	return db.Model(&BidRequest{}).
+		Select("bid_requests.*, "+quotedExpr+" AS quoted").
+		Preload("Vendor", func(q *gorm.DB) *gorm.DB { return q.Unscoped() }).
+		Preload("Project", func(q *gorm.DB) *gorm.DB { return q.Unscoped() })
+}
+
+// ListBidRequests returns who a project's bid request went to, oldest
+// first.
+func (s *Store) ListBidRequests(projectID uint) ([]BidRequest, error) {
+	var bids []BidRequest
+	err := bidRequestQuery(s.db).
+		Where("bid_requests."+ColProjectID+" = ?", projectID).
+		Order("bid_requests.sent_at, bid_requests." + ColID).
+		Find(&bids).Error
+	return bids, err
+}
+
+// GetBidRequest returns a bid request with its vendor and project, as
+// ListBidRequests does.
+func (s *Store) GetBidRequest(id uint) (BidRequest, error) {
+	var bid BidRequest
+	err := bidRequestQuery(s.db).First(&bid, "bid_requests."+ColID+" = ?", id).Error
+	return bid, err
+}
+
+// RecordBidRequests notes that the project's bid request was sent to each
+// vendor at sentAt, with a follow-up followUpDays later (none when zero).
+// Sending to a vendor again reopens and updates its existing request.
+func (s *Store) RecordBidRequests(projectID uint, vendorIDs []uint, sentAt time.Time, followUpDays int) error {
+	if len(vendorIDs) == 0 {
+		return fmt.Errorf("pick at least one vendor")
+	}
+	if followUpDays < 0 {
+		return fmt.Errorf("follow-up days must be zero or more")
+	}
+	if err := s.requireParentAlive(&Project{}, projectID); err != nil {
+		return parentRestoreError("project", err)
+	}
+	var followUp *time.Time
+	if followUpDays > 0 {
+		t := sentAt.AddDate(0, 0, followUpDays)
+		followUp = &t
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for _, vendorID := range vendorIDs {
+			if err := tx.First(&Vendor{}, vendorID).Error; err != nil {
+				return fmt.Errorf("vendor %d: %w", vendorID, err)
+			}
+			var bid BidRequest
+			err := tx.Where(ColProjectID+" = ? AND "+ColVendorID+" = ?", projectID, vendorID).
+				First(&bid).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				bid = BidRequest{ProjectID: projectID, VendorID: vendorID}
+			} else if err != nil {
+				return err
+			}
+			bid.SentAt, bid.FollowUpOn, bid.ClosedAt = sentAt, followUp, nil
+			if err := tx.Omit("Project", "Vendor").Save(&bid).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CloseBidRequest stops the follow-up reminders for a bid request, or
+// reopens it when closed is false.
+func (s *Store) CloseBidRequest(id uint, closed bool, now time.Time) error {
+	var closedAt *time.Time
+	if closed {
+		closedAt = &now
+	}
+	result := s.db.Model(&BidRequest{}).Where(ColID+" = ?", id).Update("closed_at", closedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RemoveBidRequest forgets that a bid request was sent.
+func (s *Store) RemoveBidRequest(id uint) error {
+	result := s.db.Delete(&BidRequest{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListBidFollowUpsDue returns the open bid requests of the current house's
+// live projects whose follow-up falls within horizon of now, overdue ones
+// included, soonest first. A request stops being open once the vendor's
+// quote is in.
+func (s *Store) ListBidFollowUpsDue(now time.Time, horizon time.Duration) ([]BidRequest, error) {
+	var bids []BidRequest
+	err := bidRequestQuery(s.db).
+		Joins("JOIN projects ON projects.id = bid_requests.project_id AND projects.deleted_at IS NULL").
+		Scopes(s.inHouse("projects")).
+		Where("bid_requests.closed_at IS NULL AND bid_requests.follow_up_on <= ?", now.Add(horizon)).
+		Where("NOT " + quotedExpr).
+		Order("bid_requests.follow_up_on, bid_requests." + ColID).
+		Find(&bids).Error
+	return bids, err
+}
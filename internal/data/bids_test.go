@@ -0,0 +1,65 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBidRequestFollowUps(t *testing.T) {
+	store := newTestStore(t)
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	project := Project{Title: "New fence", ProjectTypeID: types[0].ID, Status: ProjectStatusQuoted}
+	require.NoError(t, store.CreateProject(&project))
+	acme := Vendor{Name: "Acme Fencing"}
+	bolt := Vendor{Name: "Bolt Builders"}
+	require.NoError(t, store.CreateVendor(&acme))
+	require.NoError(t, store.CreateVendor(&bolt))
+
+	sent := time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)
+	require.Error(t, store.RecordBidRequests(project.ID, nil, sent, 7))
+	require.NoError(t, store.RecordBidRequests(project.ID, []uint{acme.ID, bolt.ID}, sent, 7))
+	bids, err := store.ListBidRequests(project.ID)
+	require.NoError(t, err)
+	require.Len(t, bids, 2)
+	assert.Equal(t, "Acme Fencing", bids[0].Vendor.Name)
+
+	due, err := store.ListBidFollowUpsDue(sent, 24*time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, due, "not time to follow up yet")
+
+	// Acme's quote comes in, so only Bolt needs a nudge.
+	require.NoError(t, store.CreateQuote(&Quote{ProjectID: project.ID, TotalCents: 420000}, acme))
+	due, err = store.ListBidFollowUpsDue(sent.AddDate(0, 0, 7), 0)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, bolt.ID, due[0].VendorID)
+	bids, err = store.ListBidRequests(project.ID)
+	require.NoError(t, err)
+	assert.True(t, bids[0].Quoted)
+	assert.False(t, bids[1].Quoted)
+
+	require.NoError(t, store.CloseBidRequest(due[0].ID, true, sent))
+	closed, err := store.GetBidRequest(due[0].ID)
+	require.NoError(t, err)
+	assert.NotNil(t, closed.ClosedAt)
+	due, err = store.ListBidFollowUpsDue(sent.AddDate(0, 0, 30), 0)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	// Sending again reopens the request instead of adding another.
+	require.NoError(t, store.RecordBidRequests(project.ID, []uint{bolt.ID}, sent.AddDate(0, 0, 10), 3))
+	bids, err = store.ListBidRequests(project.ID)
+	require.NoError(t, err)
+	require.Len(t, bids, 2)
+	assert.Nil(t, bids[1].ClosedAt)
+
+	require.NoError(t, store.RemoveBidRequest(bids[1].ID))
+	assert.Error(t, store.RemoveBidRequest(bids[1].ID))
+}
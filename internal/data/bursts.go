@@ -0,0 +1,90 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/cpcloud/webcasa/internal/imagedup"
+	"gorm.io/gorm"
+)
+
+// PhotoBurst is a run of near-identical photos attached to one entity.
+// Best is the sharpest, offered as the one to keep.
+type PhotoBurst struct {
+	Documents []Document
+	Best      uint
+}
+
+// PhotoBursts finds photos attached to the entity that were uploaded
+// within minutes of each other and look nearly the same.
+func (s *Store) PhotoBursts(entityKind string, entityID uint) ([]PhotoBurst, error) {
+	var docs []Document
+	err := s.db.Select(listDocumentColumns).
+		Where(ColEntityKind+" = ? AND "+ColEntityID+" = ? AND "+ColImageHash+" != ''", entityKind, entityID).
+		Order(ColCreatedAt + ", " + ColID).
+		Find(&docs).Error
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]Document, len(docs))
+	photos := make([]imagedup.Photo, 0, len(docs))
+	for _, d := range docs {
+		h, err := imagedup.ParseHash(d.ImageHash)
+		if err != nil {
+			continue
+		}
+		byID[d.ID] = d
+		photos = append(photos, imagedup.Photo{
+			ID: d.ID, Hash: h, Taken: d.CreatedAt, Sharpness: d.Sharpness,
+		})
+	}
+	var bursts []PhotoBurst
+	for _, b := range imagedup.Bursts(photos, imagedup.DefaultWindow, imagedup.DefaultMaxDistance) {
+		pb := PhotoBurst{Best: b.Best}
+		for _, p := range b.Photos {
+			pb.Documents = append(pb.Documents, byID[p.ID])
+		}
+		bursts = append(bursts, pb)
+	}
+	return bursts, nil
+}
+
+// KeepBestShot soft-deletes the other shots of a burst, keeping keep. The
+// discarded photos must be attached to the same entity as the kept one;
+// they can be restored like any deleted document.
+func (s *Store) KeepBestShot(keep uint, discard []uint) error {
+	discard = slices.Compact(slices.Sorted(slices.Values(discard)))
+	if len(discard) == 0 {
+		return fmt.Errorf("no photos to discard")
+	}
+	if slices.Contains(discard, keep) {
+		return fmt.Errorf("document %d can't be both kept and discarded", keep)
+	}
+	kept, err := s.GetDocumentMetadata(keep)
+	if err != nil {
+		return err
+	}
+	var others []Document
+	if err := s.db.Select(listDocumentColumns).Find(&others, discard).Error; err != nil {
+		return err
+	}
+	if len(others) != len(discard) {
+		return fmt.Errorf("some of the photos to discard don't exist")
+	}
+	for _, d := range others {
+		if d.EntityKind != kept.EntityKind || d.EntityID != kept.EntityID {
+			return fmt.Errorf("document %d isn't attached to the same record as %d", d.ID, keep)
+		}
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for _, id := range discard {
+			if err := softDeleteIn(tx, &Document{}, DeletionEntityDocument, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
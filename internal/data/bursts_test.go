@@ -0,0 +1,79 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPhotoBurstsAndKeepBestShot(t *testing.T) {
+	store := newTestStore(t)
+	types, _ := store.ProjectTypes()
+	require.NoError(t, store.CreateProject(&Project{
+		Title: "Deck", ProjectTypeID: types[0].ID, Status: ProjectStatusPlanned,
+	}))
+	projects, _ := store.ListProjects(false)
+	project := projects[0].ID
+
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	add := func(hash string, sharp float64, offset time.Duration) uint {
+		doc := Document{
+			Title: "photo", EntityKind: DocumentEntityProject, EntityID: project,
+			MIMEType: "image/jpeg", Data: []byte("x"), ImageHash: hash, Sharpness: sharp,
+			CreatedAt: at.Add(offset),
+		}
+		require.NoError(t, store.CreateDocument(&doc))
+		return doc.ID
+	}
+	first := add("f0f0f0f0f0f0f0f0", 10, 0)
+	sharpest := add("f0f0f0f0f0f0f0f1", 30, 20*time.Second)
+	third := add("f0f0f0f0f0f0f0f3", 20, time.Minute)
+	add("0f0f0f0f0f0f0f0f", 99, 90*time.Second) // a different picture
+	add("f0f0f0f0f0f0f0f0", 99, 3*time.Hour)    // the same one, much later
+
+	bursts, err := store.PhotoBursts(DocumentEntityProject, project)
+	require.NoError(t, err)
+	require.Len(t, bursts, 1)
+	assert.Equal(t, sharpest, bursts[0].Best)
+	var ids []uint
+	for _, d := range bursts[0].Documents {
+		ids = append(ids, d.ID)
+		assert.Empty(t, d.Data)
+	}
+	assert.Equal(t, []uint{first, sharpest, third}, ids)
+
+	require.Error(t, store.KeepBestShot(sharpest, []uint{sharpest}))
+	require.Error(t, store.KeepBestShot(sharpest, nil))
+
+	require.NoError(t, store.KeepBestShot(sharpest, []uint{first, third}))
+	bursts, err = store.PhotoBursts(DocumentEntityProject, project)
+	require.NoError(t, err)
+	assert.Empty(t, bursts)
+	require.NoError(t, store.RestoreDocument(first), "discarded shots can be restored")
+}
+
+func TestKeepBestShotStaysWithinEntity(t *testing.T) {
+	store := newTestStore(t)
+	loose := Document{Title: "loose", Data: []byte("x"), ImageHash: "f0f0f0f0f0f0f0f0"}
+	require.NoError(t, store.CreateDocument(&loose))
+	types, _ := store.ProjectTypes()
+	require.NoError(t, store.CreateProject(&Project{
+		Title: "Deck", ProjectTypeID: types[0].ID, Status: ProjectStatusPlanned,
+	}))
+	projects, _ := store.ListProjects(false)
+	attached := Document{
+		Title: "attached", EntityKind: DocumentEntityProject, EntityID: projects[0].ID,
+		Data: []byte("x"), ImageHash: "f0f0f0f0f0f0f0f0",
+	}
+	require.NoError(t, store.CreateDocument(&attached))
+
+	err := store.KeepBestShot(attached.ID, []uint{loose.ID})
+	assert.ErrorContains(t, err, "same record")
+	err = store.KeepBestShot(attached.ID, []uint{9999})
+	assert.Error(t, err)
+}
@@ -0,0 +1,249 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Warranty claim statuses.
+const (
+	ClaimStatusDraft    = "draft"
+	ClaimStatusFiled    = "filed"
+	ClaimStatusApproved = "approved"
+	ClaimStatusDenied   = "denied"
+	ClaimStatusClosed   = "closed"
+)
+
+// ClaimStatuses lists the statuses of a warranty claim in the order they
+// usually come.
+func ClaimStatuses() []string {
+	return []string{ClaimStatusDraft, ClaimStatusFiled, ClaimStatusApproved, ClaimStatusDenied, ClaimStatusClosed}
+}
+
+// Directions of claim correspondence.
+const (
+	CorrespondenceSent     = "sent"
+	CorrespondenceReceived = "received"
+)
+
+// WarrantyClaim is a claim on an appliance's warranty: the problem, where
+// the claim stands, and what was said back and forth. PurchaseDocumentID
+// and WarrantyDocumentID pick the appliance's documents that go with the
+// claim, the receipt and the warranty; when left out on creation they're
+// guessed from the documents' titles and file names. FiledOn is set when
+// the claim is first marked filed, unless given.
+type WarrantyClaim struct {
+	ID          uint      `gorm:"primaryKey"`
+	ApplianceID uint      `gorm:"index"`
+	Appliance   Appliance `gorm:"constraint:OnDelete:CASCADE;"`
+	Status      string
+	Problem     string
+	// ClaimNumber is the manufacturer's reference for the claim.
+	ClaimNumber        string
+	FiledOn            *time.Time
+	PurchaseDocumentID *uint
+	WarrantyDocumentID *uint
+	Notes              string
+	Correspondence     []ClaimCorrespondence `gorm:"constraint:OnDelete:CASCADE;"`
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	Version            int            `gorm:"not null;default:1"`
+	DeletedAt          gorm.DeletedAt `gorm:"index"`
+}
+
+// ClaimCorrespondence is a letter, email or call about a claim, sent to
+// the manufacturer or received from it.
+type ClaimCorrespondence struct {
+	ID              uint `gorm:"primaryKey"`
+	WarrantyClaimID uint `gorm:"index"`
+	OccurredAt      time.Time
+	Direction       string
+	Summary         string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// purchaseWords and warrantyWords pick out an appliance's receipt and
+// warranty among its documents by title or file name.
+var (
+	purchaseWords = regexp.MustCompile(`(?i)receipt|invoice|purchase|bill of sale|order`)
+	warrantyWords = regexp.MustCompile(`(?i)warrant|guarantee`)
+)
+
+func claimCorrespondencePreload(q *gorm.DB) *gorm.DB {
+	return q.Order(ColOccurredAt + ", " + ColID)
+}
+
+// ListWarrantyClaims returns the warranty claims on an appliance, or on
+// every appliance when applianceID is 0, most recent first, with their
+// correspondence.
+func (s *Store) ListWarrantyClaims(applianceID uint, includeDeleted bool) ([]WarrantyClaim, error) {
+	var claims []WarrantyClaim
+	db := s.db.Preload("Correspondence", claimCorrespondencePreload).
+		Preload("Appliance", func(q *gorm.DB) *gorm.DB { return q.Unscoped() }).
+		Order(ColCreatedAt + " desc, " + ColID + " desc")
+	if applianceID != 0 {
+		db = db.Where(ColApplianceID+" = ?", applianceID)
+	}
+	if includeDeleted {
+		db = db.Unscoped()
+	}
+	return claims, db.Find(&claims).Error
+}
+
+func (s *Store) GetWarrantyClaim(id uint) (WarrantyClaim, error) {
+	var claim WarrantyClaim
+	err := s.db.Preload("Correspondence", claimCorrespondencePreload).
+		Preload("Appliance", func(q *gorm.DB) *gorm.DB { return q.Unscoped() }).
+		First(&claim, id).Error
+	return claim, err
+}
+
+// CreateWarrantyClaim opens a claim on an appliance, as a draft unless a
+// status is given, picking its receipt and warranty documents when they
+// aren't.
+func (s *Store) CreateWarrantyClaim(claim *WarrantyClaim, now time.Time) error {
+	if claim.Status == "" {
+		claim.Status = ClaimStatusDraft
+	}
+	if err := s.requireParentAlive(&Appliance{}, claim.ApplianceID); err != nil {
+		return fmt.Errorf("appliance not found or deleted")
+	}
+	if err := s.validateWarrantyClaim(claim, now); err != nil {
+		return err
+	}
+	if claim.PurchaseDocumentID == nil || claim.WarrantyDocumentID == nil {
+		purchase, warranty, err := s.GuessClaimDocuments(claim.ApplianceID)
+		if err != nil {
+			return err
+		}
+		if claim.PurchaseDocumentID == nil {
+			claim.PurchaseDocumentID = purchase
+		}
+		if claim.WarrantyDocumentID == nil {
+			claim.WarrantyDocumentID = warranty
+		}
+	}
+	claim.Correspondence = nil
+	return s.db.Omit("Appliance").Create(claim).Error
+}
+
+// UpdateWarrantyClaim saves a claim. Its correspondence is left as it is;
+// add to it with AddClaimCorrespondence.
+func (s *Store) UpdateWarrantyClaim(claim WarrantyClaim, now time.Time) error {
+	var old WarrantyClaim
+	if err := s.db.First(&old, claim.ID).Error; err != nil {
+		return err
+	}
+	claim.ApplianceID = old.ApplianceID
+	if claim.FiledOn == nil && claim.Status != ClaimStatusDraft {
+		claim.FiledOn = old.FiledOn
+	}
+	if err := s.validateWarrantyClaim(&claim, now); err != nil {
+		return err
+	}
+	claim.Appliance, claim.Correspondence = Appliance{}, nil
+	return s.updateByID(&WarrantyClaim{}, claim.ID, claim)
+}
+
+func (s *Store) DeleteWarrantyClaim(id uint) error {
+	return s.softDelete(&WarrantyClaim{}, DeletionEntityClaim, id)
+}
+
+func (s *Store) RestoreWarrantyClaim(id uint) error {
+	var claim WarrantyClaim
+	if err := s.db.Unscoped().First(&claim, id).Error; err != nil {
+		return err
+	}
+	if err := s.requireParentAlive(&Appliance{}, claim.ApplianceID); err != nil {
+		return parentRestoreError("appliance", err)
+	}
+	return s.restoreEntity(&WarrantyClaim{}, DeletionEntityClaim, id)
+}
+
+// AddClaimCorrespondence records a letter, email or call about a claim,
+// dated now when it isn't.
+func (s *Store) AddClaimCorrespondence(c *ClaimCorrespondence, now time.Time) error {
+	if err := s.requireParentAlive(&WarrantyClaim{}, c.WarrantyClaimID); err != nil {
+		return fmt.Errorf("claim not found or deleted")
+	}
+	c.Summary = strings.TrimSpace(c.Summary)
+	if c.Summary == "" {
+		return fmt.Errorf("say what was sent or received")
+	}
+	if c.Direction != CorrespondenceSent && c.Direction != CorrespondenceReceived {
+		return fmt.Errorf("direction is %s or %s", CorrespondenceSent, CorrespondenceReceived)
+	}
+	if c.OccurredAt.IsZero() {
+		c.OccurredAt = now
+	}
+	return s.db.Create(c).Error
+}
+
+// RemoveClaimCorrespondence takes an entry off a claim's correspondence.
+func (s *Store) RemoveClaimCorrespondence(id uint) error {
+	result := s.db.Delete(&ClaimCorrespondence{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GuessClaimDocuments picks, among an appliance's documents, the newest
+// that looks like its receipt and the newest that looks like its
+// warranty. Either is nil when none does.
+func (s *Store) GuessClaimDocuments(applianceID uint) (purchase, warranty *uint, err error) {
+	docs, err := s.ListDocumentsByEntity(DocumentEntityAppliance, applianceID, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	slices.SortStableFunc(docs, func(a, b Document) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	for _, d := range docs {
+		text := d.Title + " " + d.FileName
+		switch {
+		case warranty == nil && warrantyWords.MatchString(text):
+			warranty = &d.ID
+		case purchase == nil && purchaseWords.MatchString(text):
+			purchase = &d.ID
+		}
+	}
+	return purchase, warranty, nil
+}
+
+func (s *Store) validateWarrantyClaim(claim *WarrantyClaim, now time.Time) error {
+	if !slices.Contains(ClaimStatuses(), claim.Status) {
+		return fmt.Errorf("unknown claim status %q -- use one of %s",
+			claim.Status, strings.Join(ClaimStatuses(), ", "))
+	}
+	claim.Problem = strings.TrimSpace(claim.Problem)
+	if claim.Problem == "" {
+		return fmt.Errorf("describe the problem being claimed")
+	}
+	if claim.Status != ClaimStatusDraft && claim.FiledOn == nil {
+		claim.FiledOn = &now
+	}
+	for _, id := range []*uint{claim.PurchaseDocumentID, claim.WarrantyDocumentID} {
+		if id == nil {
+			continue
+		}
+		var n int64
+		if err := s.db.Model(&Document{}).Where(ColID+" = ?", *id).Count(&n).Error; err != nil {
+			return err
+		}
+		if n == 0 {
+			return fmt.Errorf("document %d not found", *id)
+		}
+	}
+	return nil
+}
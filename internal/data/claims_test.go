@@ -0,0 +1,91 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestWarrantyClaim(t *testing.T) {
+	store := newTestStore(t)
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	fridge := Appliance{Name: "Fridge", SerialNumber: "FR-1"}
+	require.NoError(t, store.CreateAppliance(&fridge))
+	doc := func(title, file string) Document {
+		d := Document{
+			Title: title, FileName: file, EntityKind: DocumentEntityAppliance, EntityID: fridge.ID,
+			MIMEType: "application/pdf", Data: []byte(title), SizeBytes: int64(len(title)),
+		}
+		require.NoError(t, store.CreateDocument(&d))
+		return d
+	}
+	doc("Owner's manual", "manual.pdf")
+	receipt := doc("Best Buy receipt", "scan-0012.pdf")
+	warranty := doc("Extended coverage", "warranty-card.pdf")
+
+	require.ErrorContains(t, store.CreateWarrantyClaim(&WarrantyClaim{ApplianceID: fridge.ID}, now), "describe the problem")
+	require.ErrorContains(t, store.CreateWarrantyClaim(&WarrantyClaim{ApplianceID: 999, Problem: "x"}, now), "appliance not found")
+	require.ErrorContains(t, store.CreateWarrantyClaim(&WarrantyClaim{
+		ApplianceID: fridge.ID, Problem: "x", Status: "pending",
+	}, now), "unknown claim status")
+
+	claim := WarrantyClaim{ApplianceID: fridge.ID, Problem: "  Compressor clicks and won't cool  "}
+	require.NoError(t, store.CreateWarrantyClaim(&claim, now))
+	assert.Equal(t, ClaimStatusDraft, claim.Status)
+	assert.Equal(t, "Compressor clicks and won't cool", claim.Problem)
+	assert.Nil(t, claim.FiledOn, "drafts aren't filed")
+	require.NotNil(t, claim.PurchaseDocumentID)
+	require.NotNil(t, claim.WarrantyDocumentID)
+	assert.Equal(t, receipt.ID, *claim.PurchaseDocumentID)
+	assert.Equal(t, warranty.ID, *claim.WarrantyDocumentID)
+
+	got, err := store.GetWarrantyClaim(claim.ID)
+	require.NoError(t, err)
+	got.Status, got.ClaimNumber = ClaimStatusFiled, "WC-88"
+	require.NoError(t, store.UpdateWarrantyClaim(got, now))
+	got, err = store.GetWarrantyClaim(claim.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.FiledOn)
+	assert.Equal(t, now, got.FiledOn.UTC())
+	got.Status = ClaimStatusApproved
+	require.NoError(t, store.UpdateWarrantyClaim(got, now.AddDate(0, 0, 9)))
+	got, err = store.GetWarrantyClaim(claim.ID)
+	require.NoError(t, err)
+	assert.Equal(t, now, got.FiledOn.UTC(), "filing date stays when the claim moves on")
+
+	reply := ClaimCorrespondence{
+		WarrantyClaimID: claim.ID, Direction: CorrespondenceReceived,
+		Summary: "Approved, technician visit booked", OccurredAt: now.AddDate(0, 0, 9),
+	}
+	require.NoError(t, store.AddClaimCorrespondence(&reply, now))
+	letter := ClaimCorrespondence{WarrantyClaimID: claim.ID, Direction: CorrespondenceSent, Summary: "Claim letter"}
+	require.NoError(t, store.AddClaimCorrespondence(&letter, now))
+	require.ErrorContains(t, store.AddClaimCorrespondence(&ClaimCorrespondence{
+		WarrantyClaimID: claim.ID, Direction: "cc", Summary: "x",
+	}, now), "direction")
+	got, err = store.GetWarrantyClaim(claim.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Correspondence, 2)
+	assert.Equal(t, letter.ID, got.Correspondence[0].ID, "oldest first")
+	assert.Equal(t, "Fridge", got.Appliance.Name)
+
+	require.NoError(t, store.RemoveClaimCorrespondence(letter.ID))
+	assert.ErrorIs(t, store.RemoveClaimCorrespondence(letter.ID), gorm.ErrRecordNotFound)
+
+	require.ErrorContains(t, store.DeleteAppliance(fridge.ID), "active warranty claim")
+	require.NoError(t, store.DeleteWarrantyClaim(claim.ID))
+	require.NoError(t, store.DeleteAppliance(fridge.ID))
+	require.ErrorContains(t, store.RestoreWarrantyClaim(claim.ID), "appliance is deleted")
+	require.NoError(t, store.RestoreAppliance(fridge.ID))
+	require.NoError(t, store.RestoreWarrantyClaim(claim.ID))
+	claims, err := store.ListWarrantyClaims(fridge.ID, false)
+	require.NoError(t, err)
+	require.Len(t, claims, 1)
+	assert.Len(t, claims[0].Correspondence, 1)
+}
@@ -0,0 +1,112 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Contact roles.
+const (
+	ContactRoleInspector      = "inspector"
+	ContactRoleInsuranceAgent = "insurance_agent"
+	ContactRoleUtility        = "utility"
+	ContactRoleHOA            = "hoa"
+	ContactRoleRealEstate     = "real_estate_agent"
+	ContactRoleNeighbor       = "neighbor"
+	ContactRoleOther          = "other"
+)
+
+// ContactRoles lists the roles a contact can have.
+func ContactRoles() []string {
+	return []string{
+		ContactRoleInspector, ContactRoleInsuranceAgent, ContactRoleUtility, ContactRoleHOA,
+		ContactRoleRealEstate, ContactRoleNeighbor, ContactRoleOther,
+	}
+}
+
+// Contact is someone to call about the house who isn't hired for the work
+// -- the inspector, the insurance agent, the water company, the HOA --
+// kept apart from vendors. Name is the person or, for a utility, the
+// company; Company is who a person works for. AccountNumber is the
+// house's account or policy with them. Emergency contacts come first and
+// are shown to house sitters.
+type Contact struct {
+	ID            uint  `gorm:"primaryKey"`
+	HouseID       *uint `gorm:"index"`
+	Name          string
+	Role          string
+	Company       string
+	Phone         string
+	Email         string
+	Website       string
+	AccountNumber string
+	Emergency     bool
+	Notes         string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Version       int            `gorm:"not null;default:1"`
+	DeletedAt     gorm.DeletedAt `gorm:"index"`
+}
+
+// ListContacts returns the current house's contacts, emergency contacts
+// first, then by name.
+func (s *Store) ListContacts(includeDeleted bool) ([]Contact, error) {
+	var items []Contact
+	db := s.db.Scopes(s.inHouse("contacts")).
+		Order("emergency desc, " + ColName + ", " + ColID)
+	if includeDeleted {
+		db = db.Unscoped()
+	}
+	return items, db.Find(&items).Error
+}
+
+func (s *Store) GetContact(id uint) (Contact, error) {
+	var item Contact
+	err := s.db.First(&item, id).Error
+	return item, err
+}
+
+func (s *Store) CreateContact(item *Contact) error {
+	if err := validateContact(item); err != nil {
+		return err
+	}
+	return s.db.Create(item).Error
+}
+
+func (s *Store) UpdateContact(item Contact) error {
+	if err := validateContact(&item); err != nil {
+		return err
+	}
+	return s.updateByID(&Contact{}, item.ID, item)
+}
+
+func (s *Store) DeleteContact(id uint) error {
+	return s.softDelete(&Contact{}, DeletionEntityContact, id)
+}
+
+func (s *Store) RestoreContact(id uint) error {
+	return s.restoreEntity(&Contact{}, DeletionEntityContact, id)
+}
+
+func validateContact(c *Contact) error {
+	c.Name = strings.TrimSpace(c.Name)
+	c.Company = strings.TrimSpace(c.Company)
+	c.AccountNumber = strings.TrimSpace(c.AccountNumber)
+	if c.Role == "" {
+		c.Role = ContactRoleOther
+	}
+	switch {
+	case c.Name == "":
+		return fmt.Errorf("a contact needs a name")
+	case !slices.Contains(ContactRoles(), c.Role):
+		return fmt.Errorf("unknown contact role %q", c.Role)
+	}
+	return nil
+}
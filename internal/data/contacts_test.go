@@ -0,0 +1,47 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContacts(t *testing.T) {
+	store := newTestStore(t)
+
+	require.ErrorContains(t, store.CreateContact(&Contact{Role: ContactRoleHOA}), "needs a name")
+	require.ErrorContains(t, store.CreateContact(&Contact{Name: "Pat", Role: "plumber"}), "unknown contact role")
+
+	agent := Contact{Name: "Dana Reyes", Company: "Acme Mutual", Role: ContactRoleInsuranceAgent, Phone: "555-0101"}
+	require.NoError(t, store.CreateContact(&agent))
+	water := Contact{
+		Name: "Springfield Water", Role: ContactRoleUtility, Phone: "555-0199",
+		AccountNumber: "W-42", Emergency: true,
+	}
+	require.NoError(t, store.CreateContact(&water))
+	neighbor := Contact{Name: "Al", Emergency: true}
+	require.NoError(t, store.CreateContact(&neighbor))
+	assert.Equal(t, ContactRoleOther, neighbor.Role)
+
+	all, err := store.ListContacts(false)
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, []string{"Al", "Springfield Water", "Dana Reyes"},
+		[]string{all[0].Name, all[1].Name, all[2].Name}, "emergency contacts first")
+
+	hits, err := store.Search("W-42")
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, SearchKindContact, hits[0].Kind)
+	assert.Equal(t, water.ID, hits[0].ID)
+
+	require.NoError(t, store.DeleteContact(agent.ID))
+	all, err = store.ListContacts(false)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+	require.NoError(t, store.RestoreContact(agent.ID))
+}
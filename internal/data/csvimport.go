@@ -0,0 +1,361 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Kinds of record a spreadsheet can be imported as.
+const (
+	SheetAppliances  = "appliances"
+	SheetVendors     = "vendors"
+	SheetMaintenance = "maintenance"
+	SheetUtilities   = "utilities"
+)
+
+// ErrUnknownSheetKind is returned for a kind of record spreadsheets can't
+// be imported as.
+var ErrUnknownSheetKind = errors.New("spreadsheets can be imported as appliances, vendors, maintenance or utilities")
+
+// SheetField is a field of a record that a spreadsheet column can fill.
+type SheetField struct {
+	Key      string `json:"key"`
+	Label    string `json:"label"`
+	Required bool   `json:"required,omitempty"`
+	// Hint says what the cells should look like.
+	Hint string `json:"hint,omitempty"`
+}
+
+// SheetRowError is why a row of a spreadsheet couldn't be imported. Row
+// counts from 1 for the first row after the header.
+type SheetRowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// SheetResult is what importing a spreadsheet did, or would do.
+type SheetResult struct {
+	// Created is how many records were created, or would be.
+	Created int             `json:"created"`
+	Errors  []SheetRowError `json:"errors"`
+}
+
+const (
+	hintDate  = "YYYY-MM-DD"
+	hintMoney = "e.g. 1,250.00"
+)
+
+var sheetFields = map[string][]SheetField{
+	SheetAppliances: {
+		{Key: "name", Label: "Name", Required: true},
+		{Key: "brand", Label: "Brand"},
+		{Key: "model_number", Label: "Model Number"},
+		{Key: "serial_number", Label: "Serial Number"},
+		{Key: "purchase_date", Label: "Purchase Date", Hint: hintDate},
+		{Key: "warranty_expiry", Label: "Warranty Expiry", Hint: hintDate},
+		{Key: "location", Label: "Location"},
+		{Key: "room", Label: "Room", Hint: "a room's name"},
+		{Key: "cost", Label: "Cost", Hint: hintMoney},
+		{Key: "notes", Label: "Notes"},
+	},
+	SheetVendors: {
+		{Key: "name", Label: "Name", Required: true},
+		{Key: "contact_name", Label: "Contact Name"},
+		{Key: "email", Label: "Email"},
+		{Key: "phone", Label: "Phone"},
+		{Key: "website", Label: "Website"},
+		{Key: "notes", Label: "Notes"},
+	},
+	SheetMaintenance: {
+		{Key: "name", Label: "Name", Required: true},
+		{Key: "category", Label: "Category", Required: true, Hint: "a maintenance category's name"},
+		{Key: "appliance", Label: "Appliance", Hint: "an appliance's name"},
+		{Key: "last_serviced", Label: "Last Serviced", Hint: hintDate},
+		{Key: "interval", Label: "Interval", Hint: "months, or e.g. 1y 6m"},
+		{Key: "manual_url", Label: "Manual URL"},
+		{Key: "cost", Label: "Cost", Hint: hintMoney},
+		{Key: "notes", Label: "Notes"},
+	},
+	SheetUtilities: {
+		{Key: "type", Label: "Type", Required: true, Hint: "electric, gas, water or internet"},
+		{Key: "period_start", Label: "Period Start", Hint: hintDate},
+		{Key: "period_end", Label: "Period End", Required: true, Hint: hintDate + ", or YYYY-MM for the month"},
+		{Key: "usage", Label: "Usage", Hint: "e.g. 640"},
+		{Key: "unit", Label: "Unit", Hint: "kWh, therms, gallons or GB unless given"},
+		{Key: "cost", Label: "Cost", Hint: hintMoney},
+		{Key: "notes", Label: "Notes"},
+	},
+}
+
+// SheetFields returns the fields a spreadsheet's columns can fill for a
+// kind of record.
+func SheetFields(kind string) ([]SheetField, error) {
+	fields, ok := sheetFields[kind]
+	if !ok {
+		return nil, ErrUnknownSheetKind
+	}
+	return fields, nil
+}
+
+// ImportSheet creates a record of kind from each row of a spreadsheet, a
+// map from field key to cell, once its columns have been matched to
+// fields. Rows that don't validate are reported and skipped, and the rest
+// are created together. With dryRun nothing is created.
+func (s *Store) ImportSheet(kind string, rows []map[string]string, dryRun bool) (SheetResult, error) {
+	if _, err := SheetFields(kind); err != nil {
+		return SheetResult{}, err
+	}
+	result := SheetResult{Errors: []SheetRowError{}}
+	err := s.InTransaction(func(tx *Store) error {
+		names, err := tx.sheetNames(kind)
+		if err != nil {
+			return err
+		}
+		var records []any
+		for i, row := range rows {
+			record, err := names.record(kind, trimCells(row))
+			if err != nil {
+				result.Errors = append(result.Errors, SheetRowError{Row: i + 1, Error: err.Error()})
+				continue
+			}
+			records = append(records, record)
+		}
+		result.Created = len(records)
+		if dryRun {
+			return nil
+		}
+		for _, record := range records {
+			if err := tx.db.Create(record).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+func trimCells(row map[string]string) map[string]string {
+	trimmed := make(map[string]string, len(row))
+	for k, v := range row {
+		trimmed[k] = strings.TrimSpace(v)
+	}
+	return trimmed
+}
+
+// sheetNames resolves the names a spreadsheet refers to records by, in
+// lower case, to their IDs. An ID of 0 means the name is ambiguous.
+type sheetNames struct {
+	rooms, categories, appliances map[string]uint
+	// vendors holds every vendor name taken, deleted vendors' included,
+	// and those earlier rows take.
+	vendors map[string]bool
+	// bills holds the utility bills already in, and those earlier rows
+	// add, by type and the day their period ends.
+	bills map[string]bool
+}
+
+func (s *Store) sheetNames(kind string) (*sheetNames, error) {
+	n := &sheetNames{
+		rooms: map[string]uint{}, categories: map[string]uint{}, appliances: map[string]uint{},
+		vendors: map[string]bool{}, bills: map[string]bool{},
+	}
+	add := func(names map[string]uint, name string, id uint) {
+		key := strings.ToLower(name)
+		if _, seen := names[key]; seen {
+			id = 0
+		}
+		names[key] = id
+	}
+	switch kind {
+	case SheetAppliances:
+		rooms, err := s.ListRooms(false)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rooms {
+			add(n.rooms, r.Name, r.ID)
+		}
+	case SheetVendors:
+		vendors, err := s.ListVendors(true)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range vendors {
+			n.vendors[strings.ToLower(v.Name)] = true
+		}
+	case SheetMaintenance:
+		categories, err := s.MaintenanceCategories()
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range categories {
+			add(n.categories, c.Name, c.ID)
+		}
+		appliances, err := s.ListAppliances(false)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range appliances {
+			add(n.appliances, a.Name, a.ID)
+		}
+	case SheetUtilities:
+		bills, err := s.ListUtilityBills(false)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range bills {
+			n.bills[b.Type+" "+b.PeriodEnd.Format(DateLayout)] = true
+		}
+	}
+	return n, nil
+}
+
+// lookup finds the ID of what a cell names, or nil for a blank cell.
+func lookup(names map[string]uint, what, name string) (*uint, error) {
+	if name == "" {
+		return nil, nil
+	}
+	id, ok := names[strings.ToLower(name)]
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("no %s is named %q", what, name)
+	case id == 0:
+		return nil, fmt.Errorf("more than one %s is named %q", what, name)
+	}
+	return &id, nil
+}
+
+// record builds the record a row describes.
+func (n *sheetNames) record(kind string, row map[string]string) (any, error) {
+	if kind == SheetUtilities {
+		return n.utilityBill(row)
+	}
+	if row["name"] == "" {
+		return nil, errors.New("name is required")
+	}
+	var (
+		err  error
+		errs []error
+	)
+	check := func(field string, e error) {
+		if e != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", field, e))
+		}
+	}
+	switch kind {
+	case SheetAppliances:
+		a := &Appliance{
+			Name: row["name"], Brand: row["brand"], ModelNumber: row["model_number"],
+			SerialNumber: row["serial_number"], Location: row["location"], Notes: row["notes"],
+		}
+		a.PurchaseDate, err = ParseOptionalDate(row["purchase_date"])
+		check("purchase date", err)
+		a.WarrantyExpiry, err = ParseOptionalDate(row["warranty_expiry"])
+		check("warranty expiry", err)
+		a.CostCents, err = ParseOptionalCents(row["cost"])
+		check("cost", err)
+		a.RoomID, err = lookup(n.rooms, "room", row["room"])
+		errs = append(errs, err)
+		return a, rowError(errs)
+
+	case SheetVendors:
+		key := strings.ToLower(row["name"])
+		if n.vendors[key] {
+			return nil, fmt.Errorf("a vendor named %q already exists", row["name"])
+		}
+		n.vendors[key] = true
+		return &Vendor{
+			Name: row["name"], ContactName: row["contact_name"], Email: row["email"],
+			Phone: row["phone"], Website: row["website"], Notes: row["notes"],
+		}, nil
+
+	case SheetMaintenance:
+		m := &MaintenanceItem{Name: row["name"], ManualURL: row["manual_url"], Notes: row["notes"]}
+		if row["category"] == "" {
+			errs = append(errs, errors.New("category is required"))
+		} else if id, err := lookup(n.categories, "maintenance category", row["category"]); err != nil {
+			errs = append(errs, err)
+		} else {
+			m.CategoryID = *id
+		}
+		m.ApplianceID, err = lookup(n.appliances, "appliance", row["appliance"])
+		errs = append(errs, err)
+		m.LastServicedAt, err = ParseOptionalDate(row["last_serviced"])
+		check("last serviced", err)
+		m.IntervalMonths, err = ParseIntervalMonths(row["interval"])
+		check("interval", err)
+		m.CostCents, err = ParseOptionalCents(row["cost"])
+		check("cost", err)
+		m.NextDueAt = nextDueAt(m.LastServicedAt, m.IntervalMonths)
+		return m, rowError(errs)
+	}
+	return nil, ErrUnknownSheetKind
+}
+
+// utilityBill builds the utility bill a row describes. A period end given
+// as a month takes the whole month as the period. A bill for the same
+// utility and period end as one already in is refused, so a month's
+// export can be imported over the last one.
+func (n *sheetNames) utilityBill(row map[string]string) (any, error) {
+	b := &UtilityBill{Type: strings.ToLower(row["type"]), Unit: row["unit"], Notes: row["notes"]}
+	var errs []error
+	if !slices.Contains(UtilityTypes(), b.Type) {
+		errs = append(errs, fmt.Errorf("type: %q isn't electric, gas, water or internet", row["type"]))
+	}
+	if month, err := time.Parse("2006-01", row["period_end"]); err == nil {
+		b.PeriodStart, b.PeriodEnd = &month, month.AddDate(0, 1, -1)
+	} else if end, err := ParseRequiredDate(row["period_end"]); err != nil {
+		errs = append(errs, fmt.Errorf("period end: %w", err))
+	} else {
+		b.PeriodEnd = end
+	}
+	if start, err := ParseOptionalDate(row["period_start"]); err != nil {
+		errs = append(errs, fmt.Errorf("period start: %w", err))
+	} else if start != nil {
+		b.PeriodStart = start
+	}
+	if row["usage"] != "" {
+		usage, err := ParseRequiredFloat(strings.ReplaceAll(row["usage"], ",", ""))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("usage: %w", err))
+		}
+		b.Usage = &usage
+	}
+	if cost, err := ParseOptionalCents(row["cost"]); err != nil {
+		errs = append(errs, fmt.Errorf("cost: %w", err))
+	} else if cost != nil {
+		b.CostCents = *cost
+	}
+	if err := rowError(errs); err != nil {
+		return nil, err
+	}
+	if err := validateUtilityBill(b); err != nil {
+		return nil, err
+	}
+	key := b.Type + " " + b.PeriodEnd.Format(DateLayout)
+	if n.bills[key] {
+		return nil, fmt.Errorf("a %s bill for the period ending %s is already in", b.Type, b.PeriodEnd.Format(DateLayout))
+	}
+	n.bills[key] = true
+	return b, nil
+}
+
+// rowError joins what's wrong with a row into one line.
+func rowError(errs []error) error {
+	var msgs []string
+	for _, err := range errs {
+		if err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(msgs, "; "))
+}
@@ -0,0 +1,114 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportSheetAppliances(t *testing.T) {
+	store := newTestStore(t)
+	require.NoError(t, store.CreateRoom(&Room{Name: "Kitchen"}))
+	rows := []map[string]string{
+		{"name": " Fridge ", "brand": "Bosch", "cost": "1,899.00", "purchase_date": "2024-05-01", "room": "kitchen"},
+		{"name": "", "brand": "Miele"},
+		{"name": "Dryer", "purchase_date": "May 2024", "cost": "lots", "room": "Garage"},
+		{"name": "Washer"},
+	}
+
+	result, err := store.ImportSheet(SheetAppliances, rows, true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Created)
+	require.Len(t, result.Errors, 2)
+	assert.Equal(t, SheetRowError{Row: 2, Error: "name is required"}, result.Errors[0])
+	assert.Equal(t, 3, result.Errors[1].Row)
+	assert.Contains(t, result.Errors[1].Error, "purchase date: invalid date value; cost: ")
+	assert.Contains(t, result.Errors[1].Error, `no room is named "Garage"`)
+	items, err := store.ListAppliances(false)
+	require.NoError(t, err)
+	assert.Empty(t, items, "a dry run creates nothing")
+
+	result, err = store.ImportSheet(SheetAppliances, rows, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Created)
+	items, err = store.ListAppliances(false)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	fridge := items[0]
+	if fridge.Name != "Fridge" {
+		fridge = items[1]
+	}
+	assert.Equal(t, "Fridge", fridge.Name)
+	require.NotNil(t, fridge.CostCents)
+	assert.Equal(t, int64(189_900), *fridge.CostCents)
+	assert.NotNil(t, fridge.RoomID)
+}
+
+func TestImportSheetVendorsAndMaintenance(t *testing.T) {
+	store := newTestStore(t)
+	require.NoError(t, store.CreateVendor(&Vendor{Name: "Acme"}))
+	result, err := store.ImportSheet(SheetVendors, []map[string]string{
+		{"name": "ACME"}, {"name": "Bolt Electric"}, {"name": "bolt electric"},
+	}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, []SheetRowError{
+		{Row: 1, Error: `a vendor named "ACME" already exists`},
+		{Row: 3, Error: `a vendor named "bolt electric" already exists`},
+	}, result.Errors)
+
+	require.NoError(t, store.CreateAppliance(&Appliance{Name: "Furnace"}))
+	result, err = store.ImportSheet(SheetMaintenance, []map[string]string{
+		{"name": "Filter", "category": "hvac", "appliance": "Furnace", "last_serviced": "2026-01-15", "interval": "3m"},
+		{"name": "Gutters"},
+	}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, []SheetRowError{{Row: 2, Error: "category is required"}}, result.Errors)
+	items, err := store.ListMaintenance(false)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.NotNil(t, items[0].NextDueAt)
+	assert.Equal(t, "2026-04-15", items[0].NextDueAt.Format(DateLayout))
+
+	_, err = store.ImportSheet("rooms", nil, false)
+	require.ErrorIs(t, err, ErrUnknownSheetKind)
+}
+
+func TestImportSheetUtilities(t *testing.T) {
+	store := newTestStore(t)
+	usage := 52.0
+	require.NoError(t, store.CreateUtilityBill(&UtilityBill{
+		Type: UtilityGas, PeriodEnd: time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC), Usage: &usage,
+	}))
+	result, err := store.ImportSheet(SheetUtilities, []map[string]string{
+		{"type": "Gas", "period_end": "2026-01", "usage": "52"},
+		{"type": "gas", "period_end": "2026-02", "usage": "1,040", "cost": "96.10"},
+		{"type": "Electric", "period_start": "2026-02-03", "period_end": "2026-03-02", "usage": "640.5"},
+		{"type": "sewer", "period_end": "March"},
+	}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Created)
+	require.Len(t, result.Errors, 2)
+	assert.Equal(t, SheetRowError{Row: 1, Error: "a gas bill for the period ending 2026-01-31 is already in"}, result.Errors[0])
+	assert.Equal(t, 4, result.Errors[1].Row)
+	assert.Contains(t, result.Errors[1].Error, `type: "sewer" isn't electric`)
+	assert.Contains(t, result.Errors[1].Error, "period end: invalid date value")
+
+	bills, err := store.ListUtilityBills(false)
+	require.NoError(t, err)
+	require.Len(t, bills, 3)
+	electric, gas := bills[0], bills[1]
+	assert.Equal(t, UtilityElectric, electric.Type)
+	assert.Equal(t, "kWh", electric.Unit)
+	assert.Equal(t, "2026-02-03", electric.PeriodStart.Format(DateLayout))
+	assert.Equal(t, "2026-02-01", gas.PeriodStart.Format(DateLayout))
+	assert.Equal(t, "2026-02-28", gas.PeriodEnd.Format(DateLayout))
+	assert.InDelta(t, 1040, *gas.Usage, 0.001)
+	assert.Equal(t, int64(9610), gas.CostCents)
+}
@@ -0,0 +1,332 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Types of custom field.
+const (
+	CustomText   = "text"
+	CustomNumber = "number"
+	CustomDate   = "date"
+	CustomBool   = "bool"
+)
+
+// CustomField is a field the user added to every record of a kind, for
+// what the fixed fields don't cover: an appliance's filter size, a
+// project's permit number. EntityKind is DocumentEntityAppliance or
+// DocumentEntityProject. Key, made from the label when the field is
+// added, names the field's value in a record's Custom map.
+type CustomField struct {
+	ID         uint   `gorm:"primaryKey"`
+	EntityKind string `gorm:"uniqueIndex:idx_custom_field_key,priority:1"`
+	Key        string `gorm:"uniqueIndex:idx_custom_field_key,priority:2"`
+	Label      string
+	Type       string
+	// Position orders a kind's fields in tables and forms.
+	Position  int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// CustomValue is a record's value for a custom field, kept as text:
+// numbers as written in Go, dates as DateLayout, booleans as "true" or
+// "false". A record without a value has no row.
+type CustomValue struct {
+	ID       uint        `gorm:"primaryKey"`
+	FieldID  uint        `gorm:"uniqueIndex:idx_custom_value,priority:1"`
+	Field    CustomField `gorm:"constraint:OnDelete:CASCADE;"`
+	EntityID uint        `gorm:"uniqueIndex:idx_custom_value,priority:2"`
+	Value    string
+}
+
+// ErrCustomField is returned for a custom field that can't be added, or a
+// value that doesn't suit its field.
+var ErrCustomField = errors.New("invalid custom field")
+
+// customKinds maps each kind of record that can have custom fields to its
+// table.
+var customKinds = map[string]string{
+	DocumentEntityAppliance: "appliances",
+	DocumentEntityProject:   "projects",
+}
+
+// CustomFieldTypes lists the types a custom field can have.
+func CustomFieldTypes() []string {
+	return []string{CustomText, CustomNumber, CustomDate, CustomBool}
+}
+
+// customKey makes a field's key from its label: "Filter size" is
+// filter_size.
+func customKey(label string) string {
+	var b strings.Builder
+	under := false
+	for _, r := range strings.ToLower(label) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if under && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+			under = false
+		} else {
+			under = true
+		}
+	}
+	return b.String()
+}
+
+// ListCustomFields returns the custom fields of a kind of record in order,
+// or of every kind when kind is "".
+func (s *Store) ListCustomFields(kind string) ([]CustomField, error) {
+	db := s.db.Order(ColEntityKind + ", position, " + ColID)
+	if kind != "" {
+		if _, ok := customKinds[kind]; !ok {
+			return nil, fmt.Errorf("%w: only appliances and projects have custom fields", ErrCustomField)
+		}
+		db = db.Where(ColEntityKind+" = ?", kind)
+	}
+	var fields []CustomField
+	err := db.Find(&fields).Error
+	return fields, err
+}
+
+// CreateCustomField adds a custom field after the kind's others, making
+// its key from its label.
+func (s *Store) CreateCustomField(field *CustomField) error {
+	if _, ok := customKinds[field.EntityKind]; !ok {
+		return fmt.Errorf("%w: only appliances and projects have custom fields", ErrCustomField)
+	}
+	field.Label = strings.TrimSpace(field.Label)
+	field.Key = customKey(field.Label)
+	if field.Key == "" {
+		return fmt.Errorf("%w: a field needs a label", ErrCustomField)
+	}
+	if field.Type == "" {
+		field.Type = CustomText
+	}
+	if !validCustomType(field.Type) {
+		return fmt.Errorf("%w: a field's type is one of %s", ErrCustomField, strings.Join(CustomFieldTypes(), ", "))
+	}
+	return s.InTransaction(func(tx *Store) error {
+		var taken int64
+		if err := tx.db.Model(&CustomField{}).
+			Where(ColEntityKind+" = ? AND key = ?", field.EntityKind, field.Key).
+			Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return fmt.Errorf("%w: there's already a field like %q", ErrCustomField, field.Label)
+		}
+		var last *int
+		if err := tx.db.Model(&CustomField{}).Where(ColEntityKind+" = ?", field.EntityKind).
+			Select("MAX(position)").Scan(&last).Error; err != nil {
+			return err
+		}
+		if last != nil {
+			field.Position = *last + 1
+		}
+		return tx.db.Create(field).Error
+	})
+}
+
+func validCustomType(t string) bool {
+	for _, known := range CustomFieldTypes() {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateCustomField relabels a custom field and moves it. Its key and
+// type stay, so values already given keep their meaning.
+func (s *Store) UpdateCustomField(id uint, label string, position int) (CustomField, error) {
+	label = strings.TrimSpace(label)
+	if label == "" {
+		return CustomField{}, fmt.Errorf("%w: a field needs a label", ErrCustomField)
+	}
+	var field CustomField
+	if err := s.db.First(&field, id).Error; err != nil {
+		return CustomField{}, err
+	}
+	err := s.db.Model(&field).Updates(map[string]any{"label": label, "position": position}).Error
+	return field, err
+}
+
+// DeleteCustomField removes a custom field and every record's value for it.
+func (s *Store) DeleteCustomField(id uint) error {
+	return s.InTransaction(func(tx *Store) error {
+		var field CustomField
+		if err := tx.db.First(&field, id).Error; err != nil {
+			return err
+		}
+		if err := tx.db.Where("field_id = ?", id).Delete(&CustomValue{}).Error; err != nil {
+			return err
+		}
+		if err := tx.db.Delete(&field).Error; err != nil {
+			return err
+		}
+		return audit(tx.db, AuditEntry{Action: AuditDelete, Entity: "custom_fields", TargetID: id})
+	})
+}
+
+// customValue checks a value for a field and puts it in its stored form.
+func customValue(field CustomField, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	switch field.Type {
+	case CustomNumber:
+		n, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", ""), 64)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s: %w", ErrCustomField, field.Label, ErrInvalidFloat)
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	case CustomDate:
+		t, err := ParseOptionalDate(value)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s: %w", ErrCustomField, field.Label, err)
+		}
+		return t.Format(DateLayout), nil
+	case CustomBool:
+		b, err := strconv.ParseBool(strings.ToLower(value))
+		if err != nil {
+			return "", fmt.Errorf("%w: %s: %q isn't true or false", ErrCustomField, field.Label, value)
+		}
+		return strconv.FormatBool(b), nil
+	}
+	return value, nil
+}
+
+// setCustomValues saves a record's values for its kind's custom fields,
+// keyed by field key. A blank value clears it, and fields left out keep
+// theirs. Changes are recorded in the audit log against the record.
+func (s *Store) setCustomValues(kind string, id uint, values map[string]string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	fields, err := s.ListCustomFields(kind)
+	if err != nil {
+		return err
+	}
+	byKey := make(map[string]CustomField, len(fields))
+	for _, f := range fields {
+		byKey[f.Key] = f
+	}
+	diff := map[string][2]*string{}
+	for key, raw := range values {
+		field, ok := byKey[key]
+		if !ok {
+			return fmt.Errorf("%w: there's no field %q", ErrCustomField, key)
+		}
+		value, err := customValue(field, raw)
+		if err != nil {
+			return err
+		}
+		var existing CustomValue
+		found := s.db.Where("field_id = ? AND "+ColEntityID+" = ?", field.ID, id).
+			Limit(1).Find(&existing)
+		if found.Error != nil {
+			return found.Error
+		}
+		switch {
+		case found.RowsAffected > 0 && existing.Value == value:
+			continue
+		case found.RowsAffected > 0 && value == "":
+			err = s.db.Delete(&existing).Error
+		case found.RowsAffected > 0:
+			err = s.db.Model(&existing).Update("value", value).Error
+		case value == "":
+			continue
+		default:
+			err = s.db.Create(&CustomValue{FieldID: field.ID, EntityID: id, Value: value}).Error
+		}
+		if err != nil {
+			return err
+		}
+		change := [2]*string{nil, &value}
+		if found.RowsAffected > 0 {
+			change[0] = &existing.Value
+		}
+		if value == "" {
+			change[1] = nil
+		}
+		diff["custom."+key] = change
+	}
+	if len(diff) == 0 {
+		return nil
+	}
+	raw, err := json.Marshal(diff)
+	if err != nil {
+		return err
+	}
+	return audit(s.db, AuditEntry{Action: AuditUpdate, Entity: customKinds[kind], TargetID: id, Changes: string(raw)})
+}
+
+// customValuesOf returns the custom field values of the records of a
+// kind, keyed by record and then field key.
+func (s *Store) customValuesOf(kind string, ids []uint) (map[uint]map[string]string, error) {
+	var rows []struct {
+		EntityID uint
+		Key      string
+		Value    string
+	}
+	err := s.db.Model(&CustomValue{}).
+		Select("custom_values.entity_id, custom_fields.key, custom_values.value").
+		Joins("JOIN custom_fields ON custom_fields.id = custom_values.field_id").
+		Where("custom_fields.entity_kind = ? AND custom_values.entity_id IN ?", kind, ids).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[uint]map[string]string)
+	for _, row := range rows {
+		if values[row.EntityID] == nil {
+			values[row.EntityID] = map[string]string{}
+		}
+		values[row.EntityID][row.Key] = row.Value
+	}
+	return values, nil
+}
+
+// fillAppliancesCustom fills in the appliances' custom field values.
+func (s *Store) fillAppliancesCustom(items []Appliance) error {
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	values, err := s.customValuesOf(DocumentEntityAppliance, ids)
+	if err != nil {
+		return err
+	}
+	for i := range items {
+		items[i].Custom = values[items[i].ID]
+	}
+	return nil
+}
+
+// fillProjectsCustom fills in the projects' custom field values.
+func (s *Store) fillProjectsCustom(projects []Project) error {
+	ids := make([]uint, len(projects))
+	for i, p := range projects {
+		ids[i] = p.ID
+	}
+	values, err := s.customValuesOf(DocumentEntityProject, ids)
+	if err != nil {
+		return err
+	}
+	for i := range projects {
+		projects[i].Custom = values[projects[i].ID]
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomFields(t *testing.T) {
+	store := newTestStore(t)
+	size := CustomField{EntityKind: DocumentEntityAppliance, Label: " Filter size ", Type: CustomText}
+	require.NoError(t, store.CreateCustomField(&size))
+	assert.Equal(t, "filter_size", size.Key)
+	assert.Equal(t, "Filter size", size.Label)
+	gallons := CustomField{EntityKind: DocumentEntityAppliance, Label: "Tank (gallons)", Type: CustomNumber}
+	require.NoError(t, store.CreateCustomField(&gallons))
+	assert.Equal(t, "tank_gallons", gallons.Key)
+	assert.Equal(t, 1, gallons.Position)
+	require.NoError(t, store.CreateCustomField(&CustomField{EntityKind: DocumentEntityAppliance, Label: "Flushed", Type: CustomDate}))
+	require.NoError(t, store.CreateCustomField(&CustomField{EntityKind: DocumentEntityProject, Label: "Permit", Type: CustomBool}))
+
+	heater := Appliance{Name: "Water heater", Custom: map[string]string{"filter_size": "n/a", "tank_gallons": "1,050", "flushed": "2026-03-01"}}
+	require.NoError(t, store.CreateAppliance(&heater))
+	got, err := store.GetAppliance(heater.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"filter_size": "n/a", "tank_gallons": "1050", "flushed": "2026-03-01"}, got.Custom)
+
+	// Fields left out keep their values and blank ones are cleared.
+	got.Custom = map[string]string{"filter_size": ""}
+	require.NoError(t, store.UpdateAppliance(got))
+	items, err := store.ListAppliances(false)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, map[string]string{"tank_gallons": "1050", "flushed": "2026-03-01"}, items[0].Custom)
+	trail, err := store.AuditTrail("appliances", heater.ID)
+	require.NoError(t, err)
+	assert.Contains(t, trail[len(trail)-1].Changes, `"custom.filter_size":["n/a",null]`)
+
+	// A bad value changes nothing.
+	got = items[0]
+	got.Name = "Boiler"
+	got.Custom = map[string]string{"tank_gallons": "lots"}
+	require.ErrorIs(t, store.UpdateAppliance(got), ErrCustomField)
+	got.Custom = map[string]string{"color": "red"}
+	require.ErrorIs(t, store.UpdateAppliance(got), ErrCustomField)
+	got, err = store.GetAppliance(heater.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Water heater", got.Name)
+
+	deck := Project{Title: "Deck", ProjectTypeID: 1, Status: ProjectStatusPlanned, Custom: map[string]string{"permit": "Yes"}}
+	require.Error(t, store.CreateProject(&deck), "yes isn't a boolean")
+	deck.Custom["permit"] = "TRUE"
+	require.NoError(t, store.CreateProject(&deck))
+	project, err := store.GetProject(deck.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"permit": "true"}, project.Custom)
+
+	require.NoError(t, store.DeleteCustomField(size.ID))
+	fields, err := store.ListCustomFields(DocumentEntityAppliance)
+	require.NoError(t, err)
+	require.Len(t, fields, 2)
+	assert.Equal(t, "tank_gallons", fields[0].Key)
+
+	err = store.CreateCustomField(&CustomField{EntityKind: DocumentEntityAppliance, Label: "tank gallons"})
+	require.ErrorIs(t, err, ErrCustomField)
+	err = store.CreateCustomField(&CustomField{EntityKind: DocumentEntityVendor, Label: "Rating"})
+	require.ErrorIs(t, err, ErrCustomField)
+	err = store.CreateCustomField(&CustomField{EntityKind: DocumentEntityAppliance, Label: "Rating", Type: "stars"})
+	require.ErrorIs(t, err, ErrCustomField)
+}
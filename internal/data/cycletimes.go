@@ -0,0 +1,202 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// CycleStat sums up a set of durations, in days. AvgDays and MedianDays
+// are nil when Count is 0.
+type CycleStat struct {
+	Count      int
+	AvgDays    *float64
+	MedianDays *float64
+}
+
+// ProjectCycle is how long one project took at each step. A project
+// starts when its status first moves to underway and finishes when it
+// last moves to completed, as its change history records; projects moved
+// along before the history was kept have neither.
+type ProjectCycle struct {
+	ProjectID uint
+	Title     string
+	Status    string
+	// QuoteDays averages the days from asking for each of the project's
+	// quotes to getting it.
+	QuoteDays *float64
+	// AcceptDays is the days from the last quote in before the project
+	// started to its start: how long choosing a quote took.
+	AcceptDays  *float64
+	StartedOn   *time.Time
+	CompletedOn *time.Time
+	// PlannedDays runs from the project's start date to its end date, and
+	// ActualDays from when it started to when it was completed.
+	PlannedDays *float64
+	ActualDays  *float64
+}
+
+// CycleTimes is how long the current house's projects take from asking
+// for quotes to finishing the work.
+type CycleTimes struct {
+	// RequestToQuote counts the quotes, QuoteToAcceptance the projects
+	// started after a quote came in, and Overrun the completed projects
+	// with planned dates, by how many days they ran over (negative when
+	// they finished early).
+	RequestToQuote    CycleStat
+	QuoteToAcceptance CycleStat
+	Overrun           CycleStat
+	// Projects lists the projects with any of the times, newest first.
+	Projects []ProjectCycle
+}
+
+// inDays is the length of d in days.
+func inDays(d time.Duration) float64 { return d.Hours() / 24 }
+
+// CycleTimes works out the current house's cycle times. A quote is asked
+// for on its RequestedDate or, without one, on the first bid request to
+// the vendor for the project sent by the time it came in.
+func (s *Store) CycleTimes() (CycleTimes, error) {
+	var projects []Project
+	err := s.db.Scopes(s.inHouse("projects")).
+		Order(ColCreatedAt + " desc, " + ColID + " desc").
+		Find(&projects).Error
+	if err != nil {
+		return CycleTimes{}, err
+	}
+	ids := make([]uint, len(projects))
+	for i, p := range projects {
+		ids[i] = p.ID
+	}
+
+	var quotes []Quote
+	err = s.db.Where(ColProjectID+" IN ? AND "+ColReceivedDate+" IS NOT NULL", ids).
+		Order(ColReceivedDate + ", " + ColID).
+		Find(&quotes).Error
+	if err != nil {
+		return CycleTimes{}, err
+	}
+	var bids []BidRequest
+	err = s.db.Where(ColProjectID+" IN ?", ids).Order("sent_at").Find(&bids).Error
+	if err != nil {
+		return CycleTimes{}, err
+	}
+	var changes []FieldChange
+	err = s.db.Where(ColEntity+" = ? AND "+ColField+" = ? AND "+ColTargetID+" IN ?", "projects", ColStatus, ids).
+		Order(ColChangedAt + ", " + ColID).
+		Find(&changes).Error
+	if err != nil {
+		return CycleTimes{}, err
+	}
+
+	started := map[uint]time.Time{}
+	completed := map[uint]time.Time{}
+	for _, c := range changes {
+		var status string
+		if json.Unmarshal([]byte(c.NewValue), &status) != nil {
+			continue
+		}
+		switch status {
+		case ProjectStatusInProgress:
+			if _, ok := started[c.TargetID]; !ok {
+				started[c.TargetID] = c.ChangedAt
+			}
+		case ProjectStatusCompleted:
+			completed[c.TargetID] = c.ChangedAt
+		}
+	}
+
+	type key struct{ project, vendor uint }
+	sent := map[key][]time.Time{}
+	for _, b := range bids {
+		k := key{b.ProjectID, b.VendorID}
+		sent[k] = append(sent[k], b.SentAt)
+	}
+	quoteDays := map[uint][]float64{}
+	lastQuote := map[uint]time.Time{}
+	var allQuoteDays []float64
+	for _, q := range quotes {
+		received := *q.ReceivedDate
+		if start, ok := started[q.ProjectID]; !ok || !received.After(start) {
+			lastQuote[q.ProjectID] = received
+		}
+		requested := q.RequestedDate
+		if requested == nil {
+			for _, at := range sent[key{q.ProjectID, q.VendorID}] {
+				if !at.After(received) {
+					requested = &at
+					break
+				}
+			}
+		}
+		if requested == nil || requested.After(received) {
+			continue
+		}
+		d := inDays(received.Sub(*requested))
+		quoteDays[q.ProjectID] = append(quoteDays[q.ProjectID], d)
+		allQuoteDays = append(allQuoteDays, d)
+	}
+
+	out := CycleTimes{Projects: []ProjectCycle{}}
+	var acceptDays, overrunDays []float64
+	for _, p := range projects {
+		pc := ProjectCycle{ProjectID: p.ID, Title: p.Title, Status: p.Status}
+		if d := quoteDays[p.ID]; len(d) > 0 {
+			pc.QuoteDays = summarize(d).AvgDays
+		}
+		if start, ok := started[p.ID]; ok {
+			pc.StartedOn = &start
+			if q, ok := lastQuote[p.ID]; ok {
+				d := inDays(start.Sub(q))
+				pc.AcceptDays = &d
+				acceptDays = append(acceptDays, d)
+			}
+		}
+		if end, ok := completed[p.ID]; ok && p.Status == ProjectStatusCompleted {
+			pc.CompletedOn = &end
+			if pc.StartedOn != nil && !end.Before(*pc.StartedOn) {
+				d := inDays(end.Sub(*pc.StartedOn))
+				pc.ActualDays = &d
+			}
+		}
+		if p.StartDate != nil && p.EndDate != nil && !p.EndDate.Before(*p.StartDate) {
+			d := inDays(p.EndDate.Sub(*p.StartDate))
+			pc.PlannedDays = &d
+			if pc.ActualDays != nil {
+				overrunDays = append(overrunDays, *pc.ActualDays-d)
+			}
+		}
+		if pc.QuoteDays == nil && pc.AcceptDays == nil && pc.PlannedDays == nil && pc.ActualDays == nil {
+			continue
+		}
+		out.Projects = append(out.Projects, pc)
+	}
+	out.RequestToQuote = summarize(allQuoteDays)
+	out.QuoteToAcceptance = summarize(acceptDays)
+	out.Overrun = summarize(overrunDays)
+	return out, nil
+}
+
+// summarize averages and takes the median of durations in days.
+func summarize(d []float64) CycleStat {
+	st := CycleStat{Count: len(d)}
+	if len(d) == 0 {
+		return st
+	}
+	var sum float64
+	for _, x := range d {
+		sum += x
+	}
+	avg := sum / float64(len(d))
+	st.AvgDays = &avg
+	sorted := slices.Sorted(slices.Values(d))
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + median) / 2
+	}
+	st.MedianDays = &median
+	return st
+}
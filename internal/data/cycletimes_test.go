@@ -0,0 +1,75 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCycleTimes(t *testing.T) {
+	store := newTestStore(t)
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 9, 0, 0, 0, time.UTC) }
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	roof := Project{
+		Title: "Roof", ProjectTypeID: types[0].ID, Status: ProjectStatusQuoted,
+		StartDate: ptr(day(3, 1)), EndDate: ptr(day(3, 11)),
+	}
+	require.NoError(t, store.CreateProject(&roof))
+	deck := Project{Title: "Deck", ProjectTypeID: types[0].ID, Status: ProjectStatusPlanned}
+	require.NoError(t, store.CreateProject(&deck))
+	require.NoError(t, store.CreateProject(&Project{Title: "Someday", ProjectTypeID: types[0].ID}))
+
+	// One quote asked for outright, one through a bid request.
+	require.NoError(t, store.CreateQuote(&Quote{
+		ProjectID: roof.ID, TotalCents: 900000, RequestedDate: ptr(day(1, 2)), ReceivedDate: ptr(day(1, 6)),
+	}, Vendor{Name: "Top Roofing"}))
+	bolt := Vendor{Name: "Bolt Roofing"}
+	require.NoError(t, store.CreateVendor(&bolt))
+	require.NoError(t, store.RecordBidRequests(roof.ID, []uint{bolt.ID}, day(1, 4), 0))
+	require.NoError(t, store.CreateQuote(&Quote{ProjectID: roof.ID, TotalCents: 800000, ReceivedDate: ptr(day(1, 12))}, bolt))
+	// Nobody recorded asking for the deck quote.
+	require.NoError(t, store.CreateQuote(&Quote{ProjectID: deck.ID, TotalCents: 300000, ReceivedDate: ptr(day(2, 1))}, bolt))
+
+	// The roof goes underway two weeks after the last quote and takes two
+	// days longer than planned.
+	setStatus := func(p *Project, status string, at time.Time) {
+		t.Helper()
+		p.Status = status
+		require.NoError(t, store.UpdateProject(*p))
+		var err error
+		*p, err = store.GetProject(p.ID)
+		require.NoError(t, err)
+		var change FieldChange
+		require.NoError(t, store.db.Where(ColTargetID+" = ? AND "+ColField+" = ?", p.ID, ColStatus).
+			Last(&change).Error)
+		require.NoError(t, store.db.Model(&change).Update(ColChangedAt, at).Error)
+	}
+	setStatus(&roof, ProjectStatusInProgress, day(1, 26))
+	setStatus(&roof, ProjectStatusCompleted, day(2, 7))
+
+	ct, err := store.CycleTimes()
+	require.NoError(t, err)
+	assert.Equal(t, 2, ct.RequestToQuote.Count)
+	require.NotNil(t, ct.RequestToQuote.AvgDays)
+	assert.InDelta(t, 6, *ct.RequestToQuote.AvgDays, 0.001)
+	assert.Equal(t, 1, ct.QuoteToAcceptance.Count)
+	assert.InDelta(t, 14, *ct.QuoteToAcceptance.MedianDays, 0.001)
+	assert.Equal(t, 1, ct.Overrun.Count)
+	assert.InDelta(t, 2, *ct.Overrun.AvgDays, 0.001)
+
+	require.Len(t, ct.Projects, 1, "the deck and someday projects have no times")
+	r := ct.Projects[0]
+	assert.Equal(t, roof.ID, r.ProjectID)
+	assert.InDelta(t, 6, *r.QuoteDays, 0.001)
+	assert.Equal(t, day(1, 26), r.StartedOn.UTC())
+	assert.InDelta(t, 10, *r.PlannedDays, 0.001)
+	assert.InDelta(t, 12, *r.ActualDays, 0.001)
+}
@@ -9,13 +9,14 @@ import (
 	"gorm.io/gorm"
 )
 
-// ListMaintenanceWithSchedule returns all non-deleted maintenance items that
-// have a positive interval, preloading Category and Appliance. These are the
-// items eligible for overdue/upcoming computation.
+// ListMaintenanceWithSchedule returns the current house's non-deleted
+// maintenance items that have a positive interval, preloading Category and
+// Appliance. These are the items eligible for overdue/upcoming computation.
 func (s *Store) ListMaintenanceWithSchedule() ([]MaintenanceItem, error) {
 	var items []MaintenanceItem
 	err := s.db.
 		Where(ColIntervalMonths+" > 0").
+		Scopes(s.inHouse(tableMaintenanceItems)).
 		Preload("Category").
 		Preload("Appliance", func(q *gorm.DB) *gorm.DB {
 			return q.Unscoped()
@@ -25,24 +26,25 @@ func (s *Store) ListMaintenanceWithSchedule() ([]MaintenanceItem, error) {
 	return items, err
 }
 
-// ListActiveProjects returns non-deleted projects with status "underway" or
-// "delayed", preloading ProjectType.
+// ListActiveProjects returns the current house's non-deleted projects with
+// status "underway" or "delayed", preloading ProjectType.
 func (s *Store) ListActiveProjects() ([]Project, error) {
 	var projects []Project
 	err := s.db.
 		Where(ColStatus+" IN ?", []string{ProjectStatusInProgress, ProjectStatusDelayed}).
+		Scopes(s.inHouse("projects")).
 		Preload("ProjectType").
 		Order(ColUpdatedAt + " desc").
 		Find(&projects).Error
 	return projects, err
 }
 
-// ListOpenIncidents returns non-deleted incidents (open or in-progress),
-// preloading Appliance and Vendor. Ordered by severity (urgent first) then
-// most recently updated.
+// ListOpenIncidents returns the current house's non-deleted incidents
+// (open or in-progress), preloading Appliance and Vendor. Ordered by
+// severity (urgent first) then most recently updated.
 func (s *Store) ListOpenIncidents() ([]Incident, error) {
 	var incidents []Incident
-	err := s.db.
+	err := s.db.Scopes(s.inHouse("incidents")).
 		Where(ColStatus+" IN ?", []string{IncidentStatusOpen, IncidentStatusInProgress}).
 		Preload("Appliance", func(q *gorm.DB) *gorm.DB {
 			return q.Unscoped()
@@ -59,27 +61,13 @@ func (s *Store) ListOpenIncidents() ([]Incident, error) {
 	return incidents, err
 }
 
-// ListExpiringWarranties returns non-deleted appliances whose warranty expires
-// between (now - lookBack) and (now + horizon).
-func (s *Store) ListExpiringWarranties(
-	now time.Time,
-	lookBack, horizon time.Duration,
-) ([]Appliance, error) {
-	var appliances []Appliance
-	from := now.Add(-lookBack)
-	to := now.Add(horizon)
-	err := s.db.
-		Where(ColWarrantyExpiry+" IS NOT NULL AND "+ColWarrantyExpiry+" BETWEEN ? AND ?", from, to).
-		Order(ColWarrantyExpiry + " asc").
-		Find(&appliances).Error
-	return appliances, err
-}
-
-// ListRecentServiceLogs returns the most recent service log entries across all
-// maintenance items, preloading MaintenanceItem and Vendor.
+// ListRecentServiceLogs returns the most recent service log entries across
+// the current house's maintenance items, preloading MaintenanceItem and
+// Vendor.
 func (s *Store) ListRecentServiceLogs(limit int) ([]ServiceLogEntry, error) {
 	var entries []ServiceLogEntry
 	err := s.db.
+		Where(ColMaintenanceItemID+" IN (?)", s.houseMaintenanceIDs()).
 		Preload("MaintenanceItem", func(q *gorm.DB) *gorm.DB {
 			return q.Unscoped()
 		}).
@@ -92,13 +80,14 @@ func (s *Store) ListRecentServiceLogs(limit int) ([]ServiceLogEntry, error) {
 	return entries, err
 }
 
-// YTDServiceSpendCents returns the total cost of service log entries with
-// ServicedAt on or after the given start-of-year.
+// YTDServiceSpendCents returns the total cost of the current house's service
+// log entries with ServicedAt on or after the given start-of-year.
 func (s *Store) YTDServiceSpendCents(yearStart time.Time) (int64, error) {
 	var total *int64
 	err := s.db.Model(&ServiceLogEntry{}).
 		Select("COALESCE(SUM("+ColCostCents+"), 0)").
 		Where(ColServicedAt+" >= ?", yearStart).
+		Where(ColMaintenanceItemID+" IN (?)", s.houseMaintenanceIDs()).
 		Scan(&total).Error
 	if err != nil {
 		return 0, err
@@ -109,15 +98,17 @@ func (s *Store) YTDServiceSpendCents(yearStart time.Time) (int64, error) {
 	return *total, nil
 }
 
-// TotalProjectSpendCents returns the total actual spend across all non-deleted
-// projects. Unlike service log entries (which have a serviced_at date),
-// projects have no per-transaction date, so YTD filtering is not meaningful.
+// TotalProjectSpendCents returns the total actual spend across the current
+// house's non-deleted projects. Unlike service log entries (which have a
+// serviced_at date), projects have no per-transaction date, so YTD filtering
+// is not meaningful.
 // The previous updated_at filter was incorrect: editing any project field
 // (e.g. description) would cause its spend to appear/disappear from the total.
 func (s *Store) TotalProjectSpendCents() (int64, error) {
 	var total *int64
 	err := s.db.Model(&Project{}).
 		Select("COALESCE(SUM(" + ColActualCents + "), 0)").
+		Scopes(s.inHouse("projects")).
 		Scan(&total).Error
 	if err != nil {
 		return 0, err
@@ -127,3 +118,101 @@ func (s *Store) TotalProjectSpendCents() (int64, error) {
 	}
 	return *total, nil
 }
+
+// houseMaintenanceIDs is a subquery for the IDs of the current house's
+// maintenance items, deleted ones included.
+func (s *Store) houseMaintenanceIDs() *gorm.DB {
+	return s.db.Unscoped().Model(&MaintenanceItem{}).
+		Select(ColID).Scopes(s.inHouse(tableMaintenanceItems))
+}
+
+// houseProjectIDs is a subquery for the IDs of the current house's
+// projects, deleted ones included.
+func (s *Store) houseProjectIDs() *gorm.DB {
+	return s.db.Unscoped().Model(&Project{}).
+		Select(ColID).Scopes(s.inHouse("projects"))
+}
+
+// SpendBucket is one bar of a spending chart: a month ("2026-03"), a
+// project type or a maintenance category, and what was spent on it.
+type SpendBucket struct {
+	Label       string
+	AmountCents int64
+}
+
+// houseCosts is a subquery of the current house's dated running costs,
+// service visits and expenses, as rows of (item_id, at, cents). item_id
+// is the maintenance item a cost went to, if any. A visit an expense pays
+// for is left to the expense, so it counts once.
+func (s *Store) houseCosts() *gorm.DB {
+	paid := s.db.Model(&Expense{}).Select("service_log_entry_id").Where("service_log_entry_id IS NOT NULL")
+	visits := s.db.Model(&ServiceLogEntry{}).
+		Select(ColMaintenanceItemID+" AS item_id, "+ColServicedAt+" AS at, "+ColCostCents+" AS cents").
+		Where(ColCostCents+" IS NOT NULL").
+		Where(ColMaintenanceItemID+" IN (?)", s.houseMaintenanceIDs()).
+		Where(ColID+" NOT IN (?)", paid)
+	expenses := s.db.Model(&Expense{}).
+		Select(ColMaintenanceItemID + " AS item_id, spent_on AS at, amount_cents AS cents").
+		Scopes(s.inHouse("expenses"))
+	return s.db.Raw("? UNION ALL ?", visits, expenses)
+}
+
+// SpendByMonth totals the current house's service visits and expenses
+// for each of the given number of calendar months up to and including
+// now's, oldest first. A cost falls in its month in its own time zone.
+// Months with nothing spent are zero.
+func (s *Store) SpendByMonth(now time.Time, months int) ([]SpendBucket, error) {
+	first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, 1-months, 0)
+	var sums []SpendBucket
+	err := s.db.Table("(?) AS costs", s.houseCosts()).
+		Select("substr(costs.at, 1, 7) AS label, SUM(costs.cents) AS amount_cents").
+		Where("costs.at >= ?", first).
+		Group("label").
+		Scan(&sums).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SpendBucket, months)
+	for i := range out {
+		out[i].Label = first.AddDate(0, i, 0).Format("2006-01")
+		for _, sum := range sums {
+			if sum.Label == out[i].Label {
+				out[i].AmountCents = sum.AmountCents
+			}
+		}
+	}
+	return out, nil
+}
+
+// SpendByProjectType totals the actual cost of the current house's
+// projects by project type, the costliest first. Types with nothing spent
+// are left out.
+func (s *Store) SpendByProjectType() ([]SpendBucket, error) {
+	var out []SpendBucket
+	err := s.db.Model(&Project{}).
+		Select("project_types.name AS label, SUM(projects." + ColActualCents + ") AS amount_cents").
+		Joins("JOIN project_types ON project_types.id = projects." + ColProjectTypeID).
+		Scopes(s.inHouse("projects")).
+		Group("project_types.name").
+		Having("SUM(projects." + ColActualCents + ") > 0").
+		Order("amount_cents desc, label").
+		Scan(&out).Error
+	return out, err
+}
+
+// MaintenanceCostByCategory totals what the current house's maintenance
+// cost since the given time, service visits and expenses for maintenance
+// items, by maintenance category, the costliest first.
+func (s *Store) MaintenanceCostByCategory(since time.Time) ([]SpendBucket, error) {
+	var out []SpendBucket
+	err := s.db.Table("(?) AS costs", s.houseCosts()).
+		Select("maintenance_categories.name AS label, SUM(costs.cents) AS amount_cents").
+		Joins("JOIN "+tableMaintenanceItems+" ON "+tableMaintenanceItems+".id = costs.item_id").
+		Joins("JOIN maintenance_categories ON maintenance_categories.id = "+tableMaintenanceItems+".category_id").
+		Where("costs.at >= ?", since).
+		Group("maintenance_categories.name").
+		Having("SUM(costs.cents) > 0").
+		Order("amount_cents desc, label").
+		Scan(&out).Error
+	return out, err
+}
@@ -133,9 +133,10 @@ func TestListExpiringWarranties(t *testing.T) {
 	// No warranty -- should NOT appear.
 	require.NoError(t, store.db.Create(&Appliance{Name: "None"}).Error)
 
-	apps, err := store.ListExpiringWarranties(now, 30*24*time.Hour, 90*24*time.Hour)
+	warranties, err := store.ListExpiringWarranties(now, 30*24*time.Hour, 90*24*time.Hour)
 	require.NoError(t, err)
-	require.Len(t, apps, 2)
+	require.Len(t, warranties, 2)
+	assert.Equal(t, "Recent warranty", warranties[0].Title())
 }
 
 func TestListRecentServiceLogs(t *testing.T) {
@@ -246,3 +247,77 @@ func TestTotalProjectSpendUnaffectedByEdits(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, spend1, spend2, "editing a project must not change the spending total")
 }
+
+func TestSpendingCharts(t *testing.T) {
+	store := newTestStore(t)
+	require.NoError(t, store.CreateHouseProfile(HouseProfile{Nickname: "Elm Street"}))
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 12, 0, 0, 0, time.UTC) }
+	cents := func(c int64) *int64 { return &c }
+
+	cats, err := store.MaintenanceCategories()
+	require.NoError(t, err)
+	gutters := MaintenanceItem{Name: "Clean gutters", CategoryID: cats[0].ID}
+	require.NoError(t, store.CreateMaintenance(&gutters))
+	furnace := MaintenanceItem{Name: "Furnace tune-up", CategoryID: cats[1].ID}
+	require.NoError(t, store.CreateMaintenance(&furnace))
+	require.NoError(t, store.CreateServiceLog(&ServiceLogEntry{
+		MaintenanceItemID: gutters.ID, ServicedAt: day(4, 5), CostCents: cents(12_000),
+	}, Vendor{}))
+	visit := ServiceLogEntry{MaintenanceItemID: furnace.ID, ServicedAt: day(6, 1), CostCents: cents(15_000)}
+	require.NoError(t, store.CreateServiceLog(&visit, Vendor{}))
+	// Paying for the visit stands in for its cost.
+	require.NoError(t, store.CreateExpense(&Expense{
+		SpentOn: day(6, 3), AmountCents: 16_000, Description: "Tune-up", ServiceLogEntryID: &visit.ID,
+	}))
+	require.NoError(t, store.CreateExpense(&Expense{SpentOn: day(6, 20), AmountCents: 4_000, Description: "Salt"}))
+	// Too long ago for the chart.
+	require.NoError(t, store.CreateExpense(&Expense{SpentOn: day(1, 2), AmountCents: 9_000, Description: "Snow"}))
+
+	months, err := store.SpendByMonth(day(7, 15), 4)
+	require.NoError(t, err)
+	assert.Equal(t, []SpendBucket{
+		{Label: "2026-04", AmountCents: 12_000},
+		{Label: "2026-05"},
+		{Label: "2026-06", AmountCents: 20_000},
+		{Label: "2026-07"},
+	}, months)
+
+	byCategory, err := store.MaintenanceCostByCategory(day(3, 1))
+	require.NoError(t, err)
+	assert.Equal(t, []SpendBucket{
+		{Label: cats[1].Name, AmountCents: 16_000},
+		{Label: cats[0].Name, AmountCents: 12_000},
+	}, byCategory)
+
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	for _, p := range []Project{
+		{Title: "Deck", ProjectTypeID: types[0].ID, ActualCents: cents(50_000)},
+		{Title: "Porch", ProjectTypeID: types[0].ID, ActualCents: cents(25_000)},
+		{Title: "Roof", ProjectTypeID: types[1].ID, ActualCents: cents(90_000)},
+		{Title: "Shed", ProjectTypeID: types[2].ID},
+	} {
+		p.Status = ProjectStatusCompleted
+		require.NoError(t, store.CreateProject(&p))
+	}
+	byType, err := store.SpendByProjectType()
+	require.NoError(t, err)
+	assert.Equal(t, []SpendBucket{
+		{Label: types[1].Name, AmountCents: 90_000},
+		{Label: types[0].Name, AmountCents: 75_000},
+	}, byType)
+
+	// Another house starts with empty charts.
+	require.NoError(t, store.AddHouse(&HouseProfile{Nickname: "Lake cabin"}))
+	months, err = store.SpendByMonth(day(7, 15), 4)
+	require.NoError(t, err)
+	for _, m := range months {
+		assert.Zero(t, m.AmountCents)
+	}
+	byType, err = store.SpendByProjectType()
+	require.NoError(t, err)
+	assert.Empty(t, byType)
+	byCategory, err = store.MaintenanceCostByCategory(day(3, 1))
+	require.NoError(t, err)
+	assert.Empty(t, byCategory)
+}
@@ -0,0 +1,254 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SmartDevice is a network-connected device in the home (thermostat, smoke
+// detector, camera, hub, ...). Battery-powered devices are linked to an
+// auto-created maintenance item so battery swaps get scheduled like any
+// other recurring task.
+type SmartDevice struct {
+	ID                    uint  `gorm:"primaryKey"`
+	HouseID               *uint `gorm:"index"`
+	Name                  string
+	Room                  string
+	Manufacturer          string
+	ModelNumber           string
+	IPAddress             string
+	MACAddress            string `gorm:"index"`
+	FirmwareVersion       string
+	BatteryType           string
+	LastBatteryChange     *time.Time
+	BatteryIntervalMonths int
+	MaintenanceItemID     *uint           `gorm:"index"`
+	MaintenanceItem       MaintenanceItem `gorm:"constraint:OnDelete:SET NULL;"`
+	Notes                 string
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+	Version               int            `gorm:"not null;default:1"`
+	DeletedAt             gorm.DeletedAt `gorm:"index"`
+}
+
+const (
+	// defaultBatteryIntervalMonths is used when a device has a battery type
+	// but no explicit replacement interval.
+	defaultBatteryIntervalMonths = 12
+
+	// batteryMaintenanceCategory is the seeded category that auto-created
+	// battery replacement items are filed under.
+	batteryMaintenanceCategory = "Electrical"
+)
+
+func (s *Store) ListSmartDevices(includeDeleted bool) ([]SmartDevice, error) {
+	var items []SmartDevice
+	db := s.db.Preload("MaintenanceItem", func(q *gorm.DB) *gorm.DB {
+		return q.Unscoped()
+	}).Scopes(s.inHouse("smart_devices")).Order(ColRoom + ", " + ColName + ", " + ColID)
+	if includeDeleted {
+		db = db.Unscoped()
+	}
+	if err := db.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (s *Store) GetSmartDevice(id uint) (SmartDevice, error) {
+	var item SmartDevice
+	err := s.db.Preload("MaintenanceItem", func(q *gorm.DB) *gorm.DB {
+		return q.Unscoped()
+	}).First(&item, id).Error
+	return item, err
+}
+
+// CreateSmartDevice inserts a device. When the device takes batteries a
+// recurring "replace battery" maintenance item is created and linked.
+func (s *Store) CreateSmartDevice(item *SmartDevice) error {
+	if err := normalizeSmartDevice(item); err != nil {
+		return err
+	}
+	// The link is owned by the store; a new device has none yet.
+	item.MaintenanceItemID = nil
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := syncBatteryMaintenance(tx, item); err != nil {
+			return err
+		}
+		return tx.Create(item).Error
+	})
+}
+
+// UpdateSmartDevice persists changes to a device and keeps the linked battery
+// maintenance item's name, interval, and last-serviced date in step.
+func (s *Store) UpdateSmartDevice(item SmartDevice) error {
+	if err := normalizeSmartDevice(&item); err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var existing SmartDevice
+		if err := tx.First(&existing, item.ID).Error; err != nil {
+			return err
+		}
+		// The link is owned by the store; clients don't send it back.
+		item.MaintenanceItemID = existing.MaintenanceItemID
+		if err := syncBatteryMaintenance(tx, &item); err != nil {
+			return err
+		}
+		return updateByIDWith(tx, &SmartDevice{}, item.ID, item)
+	})
+}
+
+// RecordBatteryChange marks a device's battery as replaced at the given time
+// and logs a service entry against the linked maintenance item, if any.
+func (s *Store) RecordBatteryChange(id uint, changedAt time.Time) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var device SmartDevice
+		if err := tx.First(&device, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&device).
+			Update(ColLastBatteryChange, changedAt).Error; err != nil {
+			return err
+		}
+		if device.MaintenanceItemID == nil {
+			return nil
+		}
+		entry := ServiceLogEntry{
+			MaintenanceItemID: *device.MaintenanceItemID,
+			ServicedAt:        changedAt,
+			Notes:             "Battery replaced",
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+		return tx.Model(&MaintenanceItem{}).
+			Where(ColID+" = ?", *device.MaintenanceItemID).
+			Update(ColLastServicedAt, changedAt).Error
+	})
+}
+
+// DeleteSmartDevice soft-deletes a device. The linked battery maintenance
+// item is left in place so its service history stays visible.
+func (s *Store) DeleteSmartDevice(id uint) error {
+	return s.softDelete(&SmartDevice{}, DeletionEntitySmartDevice, id)
+}
+
+func (s *Store) RestoreSmartDevice(id uint) error {
+	return s.restoreEntity(&SmartDevice{}, DeletionEntitySmartDevice, id)
+}
+
+// KnownMACAddresses returns the normalized MAC addresses of all non-deleted
+// devices, for filtering network discovery results.
+func (s *Store) KnownMACAddresses() (map[string]bool, error) {
+	var macs []string
+	err := s.db.Model(&SmartDevice{}).
+		Where(ColMACAddress+" <> ''").
+		Pluck(ColMACAddress, &macs).Error
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(macs))
+	for _, m := range macs {
+		known[m] = true
+	}
+	return known, nil
+}
+
+// NormalizeMAC parses a hardware address in any format net.ParseMAC accepts
+// and returns it in lowercase colon-separated form.
+func NormalizeMAC(mac string) (string, error) {
+	hw, err := net.ParseMAC(strings.TrimSpace(mac))
+	if err != nil {
+		return "", fmt.Errorf("invalid MAC address %q", mac)
+	}
+	return hw.String(), nil
+}
+
+// normalizeSmartDevice validates and canonicalizes the network fields.
+func normalizeSmartDevice(item *SmartDevice) error {
+	if strings.TrimSpace(item.Name) == "" {
+		return fmt.Errorf("device name is required")
+	}
+	if item.MACAddress != "" {
+		mac, err := NormalizeMAC(item.MACAddress)
+		if err != nil {
+			return err
+		}
+		item.MACAddress = mac
+	}
+	if ip := strings.TrimSpace(item.IPAddress); ip != "" {
+		addr, err := netip.ParseAddr(ip)
+		if err != nil {
+			return fmt.Errorf("invalid IP address %q", item.IPAddress)
+		}
+		item.IPAddress = addr.String()
+	}
+	item.BatteryType = strings.TrimSpace(item.BatteryType)
+	if item.BatteryIntervalMonths < 0 {
+		return fmt.Errorf(
+			"battery interval must be non-negative, got %d",
+			item.BatteryIntervalMonths,
+		)
+	}
+	if item.BatteryType != "" && item.BatteryIntervalMonths == 0 {
+		item.BatteryIntervalMonths = defaultBatteryIntervalMonths
+	}
+	return nil
+}
+
+// batteryMaintenanceName is the title given to auto-created battery items.
+func batteryMaintenanceName(device SmartDevice) string {
+	return fmt.Sprintf("Replace %s battery (%s)", device.Name, device.BatteryType)
+}
+
+// syncBatteryMaintenance creates or refreshes the maintenance item for a
+// battery-powered device. Devices without a battery type are left alone;
+// an existing link is kept so its history isn't orphaned.
+func syncBatteryMaintenance(tx *gorm.DB, device *SmartDevice) error {
+	if device.BatteryType == "" {
+		return nil
+	}
+	if device.MaintenanceItemID != nil {
+		err := tx.Model(&MaintenanceItem{}).
+			Where(ColID+" = ?", *device.MaintenanceItemID).
+			Updates(map[string]any{
+				ColName:           batteryMaintenanceName(*device),
+				ColIntervalMonths: device.BatteryIntervalMonths,
+				ColLastServicedAt: device.LastBatteryChange,
+			}).Error
+		return err
+	}
+	var cat MaintenanceCategory
+	err := tx.Where(ColName+" = ?", batteryMaintenanceCategory).First(&cat).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf(
+			"maintenance category %q not found -- run SeedDefaults first",
+			batteryMaintenanceCategory,
+		)
+	}
+	if err != nil {
+		return err
+	}
+	item := MaintenanceItem{
+		Name:           batteryMaintenanceName(*device),
+		CategoryID:     cat.ID,
+		IntervalMonths: device.BatteryIntervalMonths,
+		LastServicedAt: device.LastBatteryChange,
+		Notes:          "Created automatically for a battery-powered device.",
+	}
+	if err := tx.Create(&item).Error; err != nil {
+		return fmt.Errorf("create battery maintenance: %w", err)
+	}
+	device.MaintenanceItemID = &item.ID
+	return nil
+}
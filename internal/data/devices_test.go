@@ -0,0 +1,140 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateSmartDeviceNormalizesNetworkFields(t *testing.T) {
+	store := newTestStore(t)
+	dev := SmartDevice{
+		Name:       "Hallway Hub",
+		Room:       "Hallway",
+		MACAddress: "AA-BB-CC-DD-EE-FF",
+		IPAddress:  " 192.168.1.40 ",
+	}
+	require.NoError(t, store.CreateSmartDevice(&dev))
+
+	got, err := store.GetSmartDevice(dev.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "aa:bb:cc:dd:ee:ff", got.MACAddress)
+	assert.Equal(t, "192.168.1.40", got.IPAddress)
+	assert.Nil(t, got.MaintenanceItemID, "mains-powered device gets no battery item")
+}
+
+func TestCreateSmartDeviceRejectsBadAddresses(t *testing.T) {
+	store := newTestStore(t)
+	assert.Error(t, store.CreateSmartDevice(&SmartDevice{Name: "X", MACAddress: "nope"}))
+	assert.Error(t, store.CreateSmartDevice(&SmartDevice{Name: "X", IPAddress: "999.1.1.1"}))
+	assert.Error(t, store.CreateSmartDevice(&SmartDevice{Name: " "}))
+}
+
+func TestBatteryDeviceCreatesMaintenanceItem(t *testing.T) {
+	store := newTestStore(t)
+	changed := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	dev := SmartDevice{
+		Name:              "Smoke Detector",
+		BatteryType:       "9V",
+		LastBatteryChange: &changed,
+	}
+	require.NoError(t, store.CreateSmartDevice(&dev))
+	require.NotNil(t, dev.MaintenanceItemID)
+
+	item, err := store.GetMaintenance(*dev.MaintenanceItemID)
+	require.NoError(t, err)
+	assert.Equal(t, "Replace Smoke Detector battery (9V)", item.Name)
+	assert.Equal(t, defaultBatteryIntervalMonths, item.IntervalMonths)
+	assert.Equal(t, batteryMaintenanceCategory, item.Category.Name)
+	require.NotNil(t, item.LastServicedAt)
+	assert.True(t, changed.Equal(*item.LastServicedAt))
+}
+
+func TestCreateSmartDeviceIgnoresMaintenanceLink(t *testing.T) {
+	store := newTestStore(t)
+	categories, err := store.MaintenanceCategories()
+	require.NoError(t, err)
+	furnace := MaintenanceItem{Name: "Service furnace", CategoryID: categories[0].ID, IntervalMonths: 12}
+	require.NoError(t, store.CreateMaintenance(&furnace))
+
+	// A client naming someone else's item gets a battery item of its own,
+	// and a mains-powered device gets none.
+	dev := SmartDevice{Name: "Smoke Detector", BatteryType: "9V", MaintenanceItemID: &furnace.ID}
+	require.NoError(t, store.CreateSmartDevice(&dev))
+	require.NotNil(t, dev.MaintenanceItemID)
+	assert.NotEqual(t, furnace.ID, *dev.MaintenanceItemID)
+	hub := SmartDevice{Name: "Hub", MaintenanceItemID: &furnace.ID}
+	require.NoError(t, store.CreateSmartDevice(&hub))
+	assert.Nil(t, hub.MaintenanceItemID)
+
+	got, err := store.GetMaintenance(furnace.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Service furnace", got.Name)
+	assert.Equal(t, 12, got.IntervalMonths)
+}
+
+func TestUpdateSmartDeviceSyncsMaintenanceItem(t *testing.T) {
+	store := newTestStore(t)
+	dev := SmartDevice{Name: "Door Sensor", BatteryType: "CR2032", BatteryIntervalMonths: 24}
+	require.NoError(t, store.CreateSmartDevice(&dev))
+	itemID := *dev.MaintenanceItemID
+
+	dev.Name = "Back Door Sensor"
+	dev.BatteryIntervalMonths = 18
+	dev.MaintenanceItemID = nil // clients don't round-trip the link
+	require.NoError(t, store.UpdateSmartDevice(dev))
+
+	got, err := store.GetSmartDevice(dev.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.MaintenanceItemID)
+	assert.Equal(t, itemID, *got.MaintenanceItemID)
+
+	item, err := store.GetMaintenance(itemID)
+	require.NoError(t, err)
+	assert.Equal(t, "Replace Back Door Sensor battery (CR2032)", item.Name)
+	assert.Equal(t, 18, item.IntervalMonths)
+}
+
+func TestRecordBatteryChangeLogsService(t *testing.T) {
+	store := newTestStore(t)
+	dev := SmartDevice{Name: "Thermostat", BatteryType: "AA"}
+	require.NoError(t, store.CreateSmartDevice(&dev))
+
+	at := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, store.RecordBatteryChange(dev.ID, at))
+
+	got, err := store.GetSmartDevice(dev.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.LastBatteryChange)
+	assert.True(t, at.Equal(*got.LastBatteryChange))
+
+	logs, err := store.ListServiceLog(*dev.MaintenanceItemID, false)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, "Battery replaced", logs[0].Notes)
+}
+
+func TestSoftDeleteRestoreSmartDevice(t *testing.T) {
+	store := newTestStore(t)
+	dev := SmartDevice{Name: "Camera", MACAddress: "aa:bb:cc:00:11:22"}
+	require.NoError(t, store.CreateSmartDevice(&dev))
+
+	known, err := store.KnownMACAddresses()
+	require.NoError(t, err)
+	assert.True(t, known["aa:bb:cc:00:11:22"])
+
+	require.NoError(t, store.DeleteSmartDevice(dev.ID))
+	items, err := store.ListSmartDevices(false)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	require.NoError(t, store.RestoreSmartDevice(dev.ID))
+	items, err = store.ListSmartDevices(false)
+	require.NoError(t, err)
+	assert.Len(t, items, 1)
+}
@@ -4,26 +4,133 @@
 package data
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/cpcloud/webcasa/internal/data/sqlite"
 )
 
+// ErrNoContent is returned by OpenDocument for a document without a file.
+var ErrNoContent = errors.New("document has no content")
+
+// OpenDocument streams the document's content: from its file when it's
+// kept as one, else from its BLOB using SQLite's incremental BLOB I/O, so
+// memory use doesn't grow with the file. The reader sees the content as it
+// was when opened; close it promptly, as it holds a read transaction. It
+// can seek, so ranges of a file can be served without reading what comes
+// before them.
+func (s *Store) OpenDocument(id uint) (io.ReadSeekCloser, error) {
+	id, err := s.contentID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.openContent(id)
+}
+
+// openContent streams the content held in the row of document id, which
+// may be in the trash.
+func (s *Store) openContent(id uint) (io.ReadSeekCloser, error) {
+	var meta struct {
+		ID         uint
+		Length     *int64
+		StoredFile string
+	}
+	err := s.db.Unscoped().Model(&Document{}).
+		Select(ColID+", length("+ColData+") AS length, "+ColStoredFile).
+		Where(ColID+" = ?", id).
+		Take(&meta).Error
+	if err != nil {
+		return nil, err
+	}
+	if meta.StoredFile != "" {
+		path, err := s.storedFilePath(meta.StoredFile)
+		if err != nil {
+			return nil, err
+		}
+		f, err := os.Open(path) //nolint:gosec // named by its checksum
+		if err != nil {
+			return nil, fmt.Errorf("open document file: %w", err)
+		}
+		return f, nil
+	}
+	if meta.Length == nil || *meta.Length == 0 {
+		return nil, ErrNoContent
+	}
+	if s.path == ":memory:" {
+		// Another connection can't see an in-memory database.
+		var doc Document
+		if err := s.db.Unscoped().Select(ColData).First(&doc, id).Error; err != nil {
+			return nil, err
+		}
+		return nopSeekCloser{bytes.NewReader(doc.Data)}, nil
+	}
+	r, err := sqlite.OpenBlob(s.path, "documents", ColData, int64(id))
+	if err != nil {
+		return nil, fmt.Errorf("open document content: %w", err)
+	}
+	return r, nil
+}
+
+type nopSeekCloser struct{ *bytes.Reader }
+
+func (nopSeekCloser) Close() error { return nil }
+
+// CreateDocumentFrom creates doc with its file read from content instead of
+// doc.Data, streaming it into the BLOB, or the files directory, so a large
+// upload is never held in memory whole. doc.SizeBytes must be the
+// content's length. If the content can't be stored the document isn't
+// kept. As with CreateDocument, a file already stored is shared rather
+// than stored again.
+func (s *Store) CreateDocumentFrom(doc *Document, content io.Reader) error {
+	if s.inFiles {
+		name, n, err := s.storeFile(content)
+		if err != nil {
+			return fmt.Errorf("store document file: %w", err)
+		}
+		if n != doc.SizeBytes {
+			return fmt.Errorf("store document file: read %d bytes, expected %d", n, doc.SizeBytes)
+		}
+		doc.StoredFile, doc.Data = name, nil
+		return s.CreateDocument(doc)
+	}
+	if s.path == ":memory:" {
+		// Another connection can't see an in-memory database.
+		b, err := io.ReadAll(content)
+		if err != nil {
+			return err
+		}
+		doc.Data = b
+		return s.CreateDocument(doc)
+	}
+	doc.Data = nil
+	if err := s.CreateDocument(doc); err != nil {
+		return err
+	}
+	if doc.SizeBytes == 0 || doc.ContentOf != nil {
+		return nil
+	}
+	if err := s.writeBlob(doc.ID, doc.SizeBytes, content); err != nil {
+		_ = s.db.Unscoped().Delete(&Document{}, doc.ID).Error
+		return fmt.Errorf("store document content: %w", err)
+	}
+	return nil
+}
+
 // ExtractDocument writes the document's BLOB content to the XDG cache
 // directory and returns the resulting filesystem path. If the cached file
 // already exists and has the expected size, the extraction is skipped.
 func (s *Store) ExtractDocument(id uint) (string, error) {
 	var doc Document
-	err := s.db.Select("data", "file_name", "sha256", "size_bytes").
+	err := s.db.Select("file_name", "sha256", "size_bytes").
 		First(&doc, id).Error
 	if err != nil {
 		return "", fmt.Errorf("load document content: %w", err)
 	}
-	if len(doc.Data) == 0 {
-		return "", fmt.Errorf("document has no content")
-	}
 
 	cacheDir, err := DocumentCacheDir()
 	if err != nil {
@@ -45,12 +152,39 @@ func (s *Store) ExtractDocument(id uint) (string, error) {
 		return cachePath, nil
 	}
 
-	if err := os.WriteFile(cachePath, doc.Data, 0o600); err != nil {
+	content, err := s.OpenDocument(id)
+	if err != nil {
+		return "", err
+	}
+	defer content.Close()
+	if err := writeCacheFile(cachePath, content); err != nil {
 		return "", fmt.Errorf("write cached document: %w", err)
 	}
 	return cachePath, nil
 }
 
+// writeCacheFile copies r to path through a temporary file, so a failed
+// copy never leaves a truncated file that looks like a cache hit.
+func writeCacheFile(path string, r io.Reader) error {
+	f, err := os.CreateTemp(filepath.Dir(path), ".extract-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) //nolint:errcheck // gone after a successful rename
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Chmod(0o600); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
 // EvictStaleCache removes cached document files from dir that haven't been
 // modified in the given number of days. A ttlDays of 0 disables eviction.
 // Returns the number of files removed and any error encountered while listing
@@ -0,0 +1,170 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Documents with the same file share one copy of it: the first keeps the
+// content and the rest point at it with ContentOf. Documents in the trash
+// keep holding content for those that point at them, and a document that
+// is purged or gets a new file first hands its content to one of them.
+
+// contentHolder finds the document holding the content with the given
+// checksum and size, if one does.
+func contentHolder(db *gorm.DB, checksum string, size int64) (uint, bool, error) {
+	var ids []uint
+	err := db.Unscoped().Model(&Document{}).
+		Where(ColChecksum+" = ? AND "+ColSizeBytes+" = ? AND "+ColContentOf+" IS NULL AND "+hasContent,
+			checksum, size).
+		Order(ColID).Limit(1).Pluck(ColID, &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, false, err
+	}
+	return ids[0], true, nil
+}
+
+// shareContent points doc at an earlier document with the same file, if
+// there is one, rather than storing the file again. Data, when given, must
+// match the checksum.
+func (s *Store) shareContent(doc *Document) error {
+	if doc.ChecksumSHA256 == "" || doc.SizeBytes == 0 {
+		return nil
+	}
+	if len(doc.Data) > 0 && fmt.Sprintf("%x", sha256.Sum256(doc.Data)) != doc.ChecksumSHA256 {
+		return nil
+	}
+	holder, ok, err := contentHolder(s.db, doc.ChecksumSHA256, doc.SizeBytes)
+	if err != nil || !ok {
+		return err
+	}
+	doc.ContentOf, doc.Data, doc.StoredFile = &holder, nil, ""
+	return nil
+}
+
+// contentID returns the ID of the document whose row holds the content of
+// document id, which may be in the trash while id isn't.
+func (s *Store) contentID(id uint) (uint, error) {
+	var doc Document
+	if err := s.db.Select(ColID, ColContentOf).First(&doc, id).Error; err != nil {
+		return 0, err
+	}
+	if doc.ContentOf != nil {
+		return *doc.ContentOf, nil
+	}
+	return id, nil
+}
+
+// handOffContent moves the content document id holds for others to the
+// first of them, which the rest then point at.
+func handOffContent(tx *gorm.DB, id uint) error {
+	var sharers []uint
+	if err := tx.Unscoped().Model(&Document{}).Where(ColContentOf+" = ?", id).
+		Order(ColID).Pluck(ColID, &sharers).Error; err != nil {
+		return err
+	}
+	if len(sharers) == 0 {
+		return nil
+	}
+	heir := sharers[0]
+	err := tx.Unscoped().Model(&Document{}).Where(ColID+" = ?", heir).UpdateColumns(map[string]any{
+		ColData:       gorm.Expr("(SELECT "+ColData+" FROM documents WHERE "+ColID+" = ?)", id),
+		ColStoredFile: gorm.Expr("(SELECT "+ColStoredFile+" FROM documents WHERE "+ColID+" = ?)", id),
+		ColContentOf:  nil,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("hand off document %d's content: %w", id, err)
+	}
+	return tx.Unscoped().Model(&Document{}).Where(ColContentOf+" = ?", id).
+		UpdateColumn(ColContentOf, heir).Error
+}
+
+// storedCopies counts the copies of a file kept among a group of
+// documents: one per row holding it in the database, and one per file
+// holding it in the files directory, however many rows use that.
+const storedCopies = "SUM(CASE WHEN " + ColContentOf + " IS NULL AND length(" + ColData + ") > 0 THEN 1 ELSE 0 END)" +
+	" + COUNT(DISTINCT CASE WHEN " + ColContentOf + " IS NULL AND " + ColStoredFile + " <> '' THEN " + ColStoredFile + " END)"
+
+// DuplicateSet is a file attached as more than one document.
+type DuplicateSet struct {
+	ChecksumSHA256 string
+	SizeBytes      int64
+	// Documents are the documents with the file, oldest first, without
+	// their content.
+	Documents []Document
+	// Copies is how many times the file is stored; MergeDuplicateDocuments
+	// brings it down to 1.
+	Copies int
+}
+
+// DuplicateDocuments returns the files attached as more than one document,
+// those taking the most room first, counting documents in the trash too.
+func (s *Store) DuplicateDocuments() ([]DuplicateSet, error) {
+	var groups []struct {
+		Checksum  string `gorm:"column:sha256"`
+		SizeBytes int64
+		Copies    int
+	}
+	err := s.db.Unscoped().Model(&Document{}).
+		Select(ColChecksum + ", " + ColSizeBytes + ", " + storedCopies + " AS copies").
+		Where(ColChecksum + " != '' AND " + ColSizeBytes + " > 0").
+		Group(ColChecksum + ", " + ColSizeBytes).
+		Having("COUNT(*) > 1").
+		Order("(COUNT(*) - 1) * " + ColSizeBytes + " DESC, " + ColChecksum).
+		Scan(&groups).Error
+	if err != nil {
+		return nil, err
+	}
+	sets := make([]DuplicateSet, 0, len(groups))
+	for _, g := range groups {
+		set := DuplicateSet{ChecksumSHA256: g.Checksum, SizeBytes: g.SizeBytes, Copies: g.Copies}
+		if err := s.db.Unscoped().Select(listDocumentColumns).
+			Where(ColChecksum+" = ? AND "+ColSizeBytes+" = ?", g.Checksum, g.SizeBytes).
+			Order(ColID).Find(&set.Documents).Error; err != nil {
+			return nil, err
+		}
+		sets = append(sets, set)
+	}
+	return sets, nil
+}
+
+// MergeDuplicateDocuments stores each duplicated file once, the other
+// documents with it pointing at the oldest copy. It returns how many
+// copies it dropped and the bytes they took; SQLite reuses the space for
+// new data, and a VACUUM gives it back to the disk.
+func (s *Store) MergeDuplicateDocuments() (dropped int, freed int64, err error) {
+	sets, err := s.DuplicateDocuments()
+	if err != nil {
+		return 0, 0, err
+	}
+	err = s.InTransaction(func(tx *Store) error {
+		for _, set := range sets {
+			if set.Copies < 2 {
+				continue
+			}
+			holder, ok, err := contentHolder(tx.db, set.ChecksumSHA256, set.SizeBytes)
+			if err != nil || !ok {
+				return err
+			}
+			res := tx.db.Unscoped().Model(&Document{}).
+				Where(ColChecksum+" = ? AND "+ColSizeBytes+" = ? AND "+ColID+" <> ? AND "+ColContentOf+" IS NULL",
+					set.ChecksumSHA256, set.SizeBytes, holder).
+				UpdateColumns(map[string]any{ColData: nil, ColStoredFile: "", ColContentOf: holder})
+			if res.Error != nil {
+				return res.Error
+			}
+			dropped += int(res.RowsAffected)
+			freed += int64(set.Copies-1) * set.SizeBytes
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return dropped, freed, nil
+}
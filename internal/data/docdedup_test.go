@@ -0,0 +1,84 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuplicateDocumentsShareContent(t *testing.T) {
+	store := newTestStore(t)
+	manual := []byte("Descale monthly.")
+	sum := fmt.Sprintf("%x", sha256.Sum256(manual))
+	newManual := func(title string) Document {
+		return Document{Title: title, FileName: "manual.txt", MIMEType: "text/plain",
+			SizeBytes: int64(len(manual)), ChecksumSHA256: sum, Data: manual}
+	}
+	first, second := newManual("Kettle"), newManual("Kettle, kitchen")
+	require.NoError(t, store.CreateDocument(&first))
+	require.NoError(t, store.CreateDocument(&second))
+	assert.Nil(t, first.ContentOf)
+	require.NotNil(t, second.ContentOf)
+	assert.Equal(t, first.ID, *second.ContentOf)
+
+	got, err := store.GetDocument(second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, manual, got.Data)
+	r, err := store.OpenDocument(second.ID)
+	require.NoError(t, err)
+	read, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, manual, read)
+
+	// Still readable with the document holding the file in the trash.
+	require.NoError(t, store.DeleteDocument(first.ID))
+	got, err = store.GetDocument(second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, manual, got.Data)
+
+	// A copy stored twice, as before files were shared, is merged.
+	third := newManual("Kettle, garage")
+	require.NoError(t, store.db.Create(&third).Error)
+	sets, err := store.DuplicateDocuments()
+	require.NoError(t, err)
+	require.Len(t, sets, 1)
+	assert.Equal(t, 2, sets[0].Copies)
+	assert.Len(t, sets[0].Documents, 3)
+	dropped, freed, err := store.MergeDuplicateDocuments()
+	require.NoError(t, err)
+	assert.Equal(t, 1, dropped)
+	assert.Equal(t, int64(len(manual)), freed)
+	sets, err = store.DuplicateDocuments()
+	require.NoError(t, err)
+	assert.Equal(t, 1, sets[0].Copies)
+
+	// A new file for the holder leaves the others the old one.
+	require.NoError(t, store.RestoreDocument(first.ID))
+	first, err = store.GetDocument(first.ID)
+	require.NoError(t, err)
+	first.Data = []byte("Descale weekly.")
+	require.NoError(t, store.UpdateDocument(first))
+	for _, id := range []uint{second.ID, third.ID} {
+		got, err := store.GetDocument(id)
+		require.NoError(t, err)
+		assert.Equal(t, manual, got.Data)
+	}
+	got, err = store.GetDocument(first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Descale weekly.", string(got.Data))
+	assert.Nil(t, got.ContentOf)
+
+	// A file that doesn't match its checksum isn't shared.
+	odd := newManual("Odd")
+	odd.Data = []byte("something else")
+	require.NoError(t, store.CreateDocument(&odd))
+	assert.Nil(t, odd.ContentOf)
+}
@@ -0,0 +1,282 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data/sqlite"
+	"gorm.io/gorm"
+)
+
+// Document files can be kept as files in a directory rather than in the
+// database, so a collection of large scans doesn't make the database file
+// huge and its backups slow. Each file is named by its SHA-256, under a
+// subdirectory named by the hash's first two characters, so a file attached
+// twice is kept once and a kept file never changes. A document whose
+// content is kept there has StoredFile set and no Data. Files no document
+// uses any more are left until CollectDocumentFiles removes them, since
+// another upload of the same file may be about to use one.
+
+// ErrFilesDir is returned for a document whose file is kept in a files
+// directory when none is set.
+var ErrFilesDir = errors.New("the document's file is kept in a files directory, but none is set")
+
+// strayFileAge is how old a file that no document uses, or a partial
+// upload, must be before CollectDocumentFiles removes it, so one being
+// written as it runs is left alone.
+const strayFileAge = time.Hour
+
+// hasContent is true of a row that holds a document's content itself.
+const hasContent = "(length(" + ColData + ") > 0 OR " + ColStoredFile + " <> '')"
+
+// SetDocumentStorage sets where document files are kept: in dir when
+// inFiles, else in the database. Files already kept in dir are read from it
+// either way, until MoveDocumentFiles moves them. Files can't be kept
+// outside an encrypted database.
+func (s *Store) SetDocumentStorage(dir string, inFiles bool) error {
+	if inFiles && dir == "" {
+		return errors.New("keeping document files as files needs a directory")
+	}
+	if inFiles && s.sealed != nil {
+		return errors.New("document files can't be kept outside an encrypted database")
+	}
+	if inFiles {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return fmt.Errorf("create files directory: %w", err)
+		}
+	}
+	s.filesDir, s.inFiles = dir, inFiles
+	return nil
+}
+
+// storedFilePath is where the file named name is kept.
+func (s *Store) storedFilePath(name string) (string, error) {
+	if s.filesDir == "" {
+		return "", ErrFilesDir
+	}
+	if _, err := hex.DecodeString(name); err != nil || len(name) != sha256.Size*2 {
+		return "", fmt.Errorf("invalid stored file name %q", name)
+	}
+	return filepath.Join(s.filesDir, name[:2], name), nil
+}
+
+// storeFile copies r into the files directory, returning the name it's
+// kept under and its length. The file is written in full before it takes
+// its name, so a failed copy never leaves a truncated one.
+func (s *Store) storeFile(r io.Reader) (string, int64, error) {
+	tmp, err := os.CreateTemp(s.filesDir, ".upload-*")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // gone after a successful rename
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, hash), r)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	name := hex.EncodeToString(hash.Sum(nil))
+	path, err := s.storedFilePath(name)
+	if err != nil {
+		return "", 0, err
+	}
+	if _, err := os.Stat(path); err == nil {
+		// Already kept; mark it used so it isn't collected as a stray.
+		now := time.Now()
+		return name, n, os.Chtimes(path, now, now)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", 0, err
+	}
+	return name, n, os.Rename(tmp.Name(), path)
+}
+
+// storeContent moves doc's Data into the files directory when files are
+// kept there.
+func (s *Store) storeContent(doc *Document) error {
+	if !s.inFiles || len(doc.Data) == 0 {
+		return nil
+	}
+	name, _, err := s.storeFile(bytes.NewReader(doc.Data))
+	if err != nil {
+		return fmt.Errorf("store document file: %w", err)
+	}
+	doc.StoredFile, doc.Data = name, nil
+	return nil
+}
+
+// contentOf returns the content held in the row of document id, wherever
+// it's kept, reading through db so it can be in the trash.
+func (s *Store) contentOf(db *gorm.DB, id uint) ([]byte, error) {
+	var doc Document
+	if err := db.Unscoped().Select(ColData, ColStoredFile).First(&doc, id).Error; err != nil {
+		return nil, err
+	}
+	if doc.StoredFile == "" {
+		return doc.Data, nil
+	}
+	path, err := s.storedFilePath(doc.StoredFile)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path) //nolint:gosec // named by its checksum, checked above
+}
+
+// MoveDocumentFiles moves the content of every document kept in the other
+// place to where SetDocumentStorage says files are kept, one at a time,
+// returning how many it moved. Moving files out of the database leaves
+// its file as large until it's compacted with Compact.
+func (s *Store) MoveDocumentFiles() (int, error) {
+	where := "length(" + ColData + ") > 0"
+	if !s.inFiles {
+		where = ColStoredFile + " <> ''"
+	}
+	var ids []uint
+	if err := s.db.Unscoped().Model(&Document{}).Where(where).Order(ColID).
+		Pluck(ColID, &ids).Error; err != nil {
+		return 0, err
+	}
+	for i, id := range ids {
+		var err error
+		if s.inFiles {
+			err = s.moveToFile(id)
+		} else {
+			err = s.moveToDatabase(id)
+		}
+		if err != nil {
+			return i, fmt.Errorf("move document %d's file: %w", id, err)
+		}
+	}
+	return len(ids), nil
+}
+
+func (s *Store) moveToFile(id uint) error {
+	r, err := s.openContent(id)
+	if err != nil {
+		return err
+	}
+	name, _, err := s.storeFile(r)
+	_ = r.Close()
+	if err != nil {
+		return err
+	}
+	return s.db.Unscoped().Model(&Document{}).Where(ColID+" = ?", id).
+		UpdateColumns(map[string]any{ColStoredFile: name, ColData: nil}).Error
+}
+
+func (s *Store) moveToDatabase(id uint) error {
+	var doc Document
+	if err := s.db.Unscoped().Select(ColID, ColStoredFile).First(&doc, id).Error; err != nil {
+		return err
+	}
+	path, err := s.storedFilePath(doc.StoredFile)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path) //nolint:gosec // named by its checksum, checked above
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck // read-only
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if err := s.writeBlob(id, info.Size(), f); err != nil {
+		return err
+	}
+	return s.db.Unscoped().Model(&Document{}).Where(ColID+" = ?", id).
+		UpdateColumn(ColStoredFile, "").Error
+}
+
+// writeBlob replaces the content in document id's row with size bytes
+// read from r, streaming them in so a large file is never held in memory
+// whole.
+func (s *Store) writeBlob(id uint, size int64, r io.Reader) error {
+	if s.path == ":memory:" {
+		// Another connection can't see an in-memory database.
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		return s.db.Unscoped().Model(&Document{}).Where(ColID+" = ?", id).
+			UpdateColumn(ColData, b).Error
+	}
+	err := s.db.Unscoped().Model(&Document{}).Where(ColID+" = ?", id).
+		UpdateColumn(ColData, gorm.Expr("zeroblob(?)", size)).Error
+	if err != nil {
+		return err
+	}
+	return sqlite.WriteBlob(s.path, "documents", ColData, int64(id), r)
+}
+
+// CollectDocumentFiles removes the files in the files directory that no
+// document uses any more, and partial uploads, once they're an hour old.
+// It returns how many files it removed and how many bytes they took.
+func (s *Store) CollectDocumentFiles() (removed int, freed int64, err error) {
+	if s.filesDir == "" {
+		return 0, 0, nil
+	}
+	var used []string
+	if err := s.db.Unscoped().Model(&Document{}).Where(ColStoredFile+" <> ''").
+		Distinct().Pluck(ColStoredFile, &used).Error; err != nil {
+		return 0, 0, err
+	}
+	keep := make(map[string]bool, len(used))
+	for _, name := range used {
+		keep[name] = true
+	}
+	cutoff := time.Now().Add(-strayFileAge)
+	err = filepath.WalkDir(s.filesDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || keep[d.Name()] {
+			return nil
+		}
+		name := d.Name()
+		if !strings.HasPrefix(name, ".upload-") && len(name) != sha256.Size*2 {
+			return nil // not ours
+		}
+		info, err := d.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			return nil
+		}
+		if os.Remove(path) == nil {
+			removed++
+			freed += info.Size()
+		}
+		return nil
+	})
+	return removed, freed, err
+}
+
+// Compact rebuilds the database file without its free pages, giving the
+// room taken by deleted rows, and by files moved out of it, back to the
+// disk. It takes a while on a large database and blocks writes meanwhile.
+func (s *Store) Compact() error {
+	if err := s.db.Exec("VACUUM").Error; err != nil {
+		return fmt.Errorf("compact database: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentFilesKeptAsFiles(t *testing.T) {
+	store := newTestStore(t)
+	dir := t.TempDir()
+	require.NoError(t, store.SetDocumentStorage(dir, true))
+	scan := bytes.Repeat([]byte("deed page "), 1000)
+	sum := fmt.Sprintf("%x", sha256.Sum256(scan))
+	newScan := func(title string) Document {
+		return Document{Title: title, FileName: "deed.pdf", MIMEType: "application/pdf",
+			SizeBytes: int64(len(scan)), ChecksumSHA256: sum}
+	}
+
+	deed := newScan("Deed")
+	require.NoError(t, store.CreateDocumentFrom(&deed, bytes.NewReader(scan)))
+	assert.Equal(t, sum, deed.StoredFile)
+	path := filepath.Join(dir, sum[:2], sum)
+	require.FileExists(t, path)
+	got, err := store.GetDocument(deed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, scan, got.Data)
+	r, err := store.OpenDocument(deed.ID)
+	require.NoError(t, err)
+	read, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, scan, read)
+
+	// The same file again is shared.
+	copied := newScan("Deed, copy")
+	copied.Data = scan
+	require.NoError(t, store.CreateDocument(&copied))
+	require.NotNil(t, copied.ContentOf)
+	assert.Empty(t, copied.StoredFile)
+
+	// A new file for the deed leaves the copy the old one.
+	deed.Data, deed.SizeBytes = []byte("amended deed"), int64(len("amended deed"))
+	require.NoError(t, store.UpdateDocument(deed))
+	got, err = store.GetDocument(copied.ID)
+	require.NoError(t, err)
+	assert.Equal(t, scan, got.Data)
+	got, err = store.GetDocument(deed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "amended deed", string(got.Data))
+	assert.NotEqual(t, sum, got.StoredFile)
+
+	stats, err := store.StorageStats()
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(scan))+int64(len("amended deed")), stats.StoredFileBytes)
+
+	// Back into the database, after which the files are strays.
+	require.NoError(t, store.SetDocumentStorage(dir, false))
+	moved, err := store.MoveDocumentFiles()
+	require.NoError(t, err)
+	assert.Equal(t, 2, moved)
+	got, err = store.GetDocument(copied.ID)
+	require.NoError(t, err)
+	assert.Equal(t, scan, got.Data)
+	assert.Empty(t, got.StoredFile)
+
+	removed, _, err := store.CollectDocumentFiles()
+	require.NoError(t, err)
+	assert.Zero(t, removed, "new strays are left alone for a while")
+	old := time.Now().Add(-2 * strayFileAge)
+	require.NoError(t, os.Chtimes(path, old, old))
+	removed, freed, err := store.CollectDocumentFiles()
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, int64(len(scan)), freed)
+	assert.NoFileExists(t, path)
+
+	// And out again.
+	require.NoError(t, store.SetDocumentStorage(dir, true))
+	moved, err = store.MoveDocumentFiles()
+	require.NoError(t, err)
+	assert.Equal(t, 2, moved)
+	require.FileExists(t, path)
+	r, err = store.OpenDocument(copied.ID)
+	require.NoError(t, err)
+	read, err = io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, scan, read)
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxDocumentPage caps how many documents one page may hold.
+const MaxDocumentPage = 500
+
+// sizeHumanExpr renders size_bytes the way the Documents tab shows it. It
+// is computed when listing rather than stored as a generated column,
+// because SQLite refuses incremental BLOB I/O (OpenDocument) on tables
+// with generated columns.
+const sizeHumanExpr = "CASE" +
+	" WHEN size_bytes IS NULL OR size_bytes <= 0 THEN NULL" +
+	" WHEN size_bytes < 1024 THEN size_bytes || ' B'" +
+	" WHEN size_bytes < 1048576 THEN printf('%.1f KB', size_bytes / 1024.0)" +
+	" WHEN size_bytes < 1073741824 THEN printf('%.1f MB', size_bytes / 1048576.0)" +
+	" ELSE printf('%.1f GB', size_bytes / 1073741824.0)" +
+	" END AS " + ColSizeHuman
+
+// migrateDocuments drops the generated size column earlier builds added,
+// and the entity index that idx_doc_entity_list replaced.
+func (s *Store) migrateDocuments() error {
+	m := s.db.Migrator()
+	if m.HasColumn(&Document{}, ColSizeHuman) {
+		if err := s.db.Exec("ALTER TABLE documents DROP COLUMN " + ColSizeHuman).Error; err != nil {
+			return fmt.Errorf("drop %s: %w", ColSizeHuman, err)
+		}
+	}
+	if m.HasIndex(&Document{}, "idx_doc_entity") {
+		if err := m.DropIndex(&Document{}, "idx_doc_entity"); err != nil {
+			return fmt.Errorf("drop idx_doc_entity: %w", err)
+		}
+	}
+	return nil
+}
+
+// DocumentCursor marks the last document of a page. The next page starts
+// just after it in the list's newest-first order.
+type DocumentCursor struct {
+	UpdatedAt time.Time
+	ID        uint
+}
+
+// String encodes the cursor for a URL query. The time keeps its offset so
+// it compares against the stored text exactly.
+func (c DocumentCursor) String() string {
+	raw := c.UpdatedAt.Format(time.RFC3339Nano) + " " + strconv.FormatUint(uint64(c.ID), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseDocumentCursor decodes a cursor produced by DocumentCursor.String.
+func ParseDocumentCursor(raw string) (DocumentCursor, error) {
+	invalid := fmt.Errorf("invalid cursor %q", raw)
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return DocumentCursor{}, invalid
+	}
+	ts, id, ok := strings.Cut(string(b), " ")
+	if !ok {
+		return DocumentCursor{}, invalid
+	}
+	at, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return DocumentCursor{}, invalid
+	}
+	n, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return DocumentCursor{}, invalid
+	}
+	return DocumentCursor{UpdatedAt: at, ID: uint(n)}, nil
+}
+
+// DocumentPageQuery selects one page of documents. A zero EntityKind lists
+// documents of every entity.
+type DocumentPageQuery struct {
+	EntityKind     string
+	EntityID       uint
+	IncludeDeleted bool
+	After          *DocumentCursor
+	Limit          int
+}
+
+// ListDocumentPage returns up to q.Limit documents, newest first, after
+// q.After, without their BLOB data. The returned cursor is nil on the last
+// page.
+func (s *Store) ListDocumentPage(q DocumentPageQuery) ([]Document, *DocumentCursor, error) {
+	if q.Limit <= 0 || q.Limit > MaxDocumentPage {
+		return nil, nil, fmt.Errorf("page size must be between 1 and %d", MaxDocumentPage)
+	}
+	db := s.db.Select(listDocumentColumns).Scopes(s.inHouse("documents")).
+		Order(ColUpdatedAt + " desc, " + ColID + " desc").
+		Limit(q.Limit + 1)
+	if q.EntityKind != "" {
+		db = db.Where(ColEntityKind+" = ? AND "+ColEntityID+" = ?", q.EntityKind, q.EntityID)
+	}
+	if q.After != nil {
+		db = db.Where("("+ColUpdatedAt+", "+ColID+") < (?, ?)", q.After.UpdatedAt, q.After.ID)
+	}
+	if q.IncludeDeleted {
+		db = db.Unscoped()
+	}
+	var docs []Document
+	if err := db.Find(&docs).Error; err != nil {
+		return nil, nil, err
+	}
+	if len(docs) <= q.Limit {
+		return docs, nil, nil
+	}
+	docs = docs[:q.Limit]
+	last := docs[len(docs)-1]
+	return docs, &DocumentCursor{UpdatedAt: last.UpdatedAt, ID: last.ID}, nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListDocumentPageWalksEveryDocumentOnce(t *testing.T) {
+	store := newTestStore(t)
+	base := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	for i := range 7 {
+		// Pairs share a timestamp so the id has to break the tie.
+		require.NoError(t, store.CreateDocument(&Document{
+			Title:     "doc",
+			FileName:  "doc.txt",
+			SizeBytes: int64(i),
+			Data:      []byte("x"),
+			UpdatedAt: base.Add(time.Duration(i/2) * time.Hour),
+		}))
+	}
+	all, err := store.ListDocuments(false)
+	require.NoError(t, err)
+	require.Len(t, all, 7)
+
+	var seen []uint
+	var after *DocumentCursor
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 4)
+		if after != nil {
+			// Cursors survive a round trip through a URL.
+			parsed, err := ParseDocumentCursor(after.String())
+			require.NoError(t, err)
+			after = &parsed
+		}
+		docs, next, err := store.ListDocumentPage(DocumentPageQuery{After: after, Limit: 3})
+		require.NoError(t, err)
+		for _, d := range docs {
+			seen = append(seen, d.ID)
+			assert.Empty(t, d.Data)
+		}
+		if next == nil {
+			break
+		}
+		after = next
+	}
+	want := make([]uint, len(all))
+	for i, d := range all {
+		want[i] = d.ID
+	}
+	assert.Equal(t, want, seen)
+}
+
+func TestListDocumentPageScopesToEntity(t *testing.T) {
+	store := newTestStore(t)
+	types, _ := store.ProjectTypes()
+	require.NoError(t, store.CreateProject(&Project{
+		Title: "Deck", ProjectTypeID: types[0].ID, Status: ProjectStatusPlanned,
+	}))
+	projects, _ := store.ListProjects(false)
+	require.NoError(t, store.CreateDocument(&Document{
+		Title: "Permit", EntityKind: DocumentEntityProject, EntityID: projects[0].ID, Data: []byte("x"),
+	}))
+	require.NoError(t, store.CreateDocument(&Document{Title: "Loose", Data: []byte("x")}))
+
+	docs, next, err := store.ListDocumentPage(DocumentPageQuery{
+		EntityKind: DocumentEntityProject, EntityID: projects[0].ID, Limit: 10,
+	})
+	require.NoError(t, err)
+	assert.Nil(t, next)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "Permit", docs[0].Title)
+
+	_, _, err = store.ListDocumentPage(DocumentPageQuery{Limit: MaxDocumentPage + 1})
+	assert.Error(t, err)
+	_, err = ParseDocumentCursor("nonsense")
+	assert.Error(t, err)
+}
+
+func TestDocumentSizeHumanIsComputed(t *testing.T) {
+	store := newTestStore(t)
+	for _, n := range []int64{0, 512, 1536, 5 * 1048576} {
+		require.NoError(t, store.CreateDocument(&Document{Title: "doc", SizeBytes: n, Data: []byte("x")}))
+	}
+	docs, err := store.ListDocuments(false)
+	require.NoError(t, err)
+	got := make(map[int64]string)
+	for _, d := range docs {
+		got[d.SizeBytes] = d.SizeHuman
+	}
+	assert.Equal(t, map[int64]string{0: "", 512: "512 B", 1536: "1.5 KB", 5 * 1048576: "5.0 MB"}, got)
+
+	doc, err := store.GetDocumentMetadata(docs[0].ID)
+	require.NoError(t, err)
+	assert.NotEmpty(t, doc.SizeHuman)
+}
+
+func TestMigrateDropsGeneratedSizeColumn(t *testing.T) {
+	store := newTestStore(t)
+	require.NoError(t, store.db.Exec(
+		"ALTER TABLE documents ADD COLUMN size_human TEXT GENERATED ALWAYS AS (size_bytes || ' B') VIRTUAL",
+	).Error)
+	require.NoError(t, store.AutoMigrate())
+	assert.False(t, store.db.Migrator().HasColumn(&Document{}, ColSizeHuman))
+}
+
+func TestDocumentListUsesIndex(t *testing.T) {
+	store := newTestStore(t)
+	var plan []struct{ Detail string }
+	require.NoError(t, store.db.Raw(
+		"EXPLAIN QUERY PLAN SELECT id FROM documents WHERE deleted_at IS NULL ORDER BY updated_at DESC, id DESC LIMIT 10",
+	).Scan(&plan).Error)
+	require.NotEmpty(t, plan)
+	assert.Contains(t, plan[0].Detail, "idx_doc_list")
+	for _, row := range plan {
+		assert.NotContains(t, row.Detail, "TEMP B-TREE")
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cpcloud/webcasa/internal/pdftext"
+	"gorm.io/gorm"
+)
+
+// docTextTable holds the text of the PDF and text documents attached to
+// records, a row per page, so a question about a record can be answered
+// from the pages of its manuals, contracts and reports that match it. Like
+// the search index it is derived data: it isn't exported or dumped, and
+// IndexDocumentText rebuilds whatever is missing. A row's sha256 is that
+// of the file its text came from, so a replaced file is read again.
+const docTextTable = searchIndexTable + "_pages"
+
+func ensureDocTextIndex(db *gorm.DB) error {
+	return db.Exec("CREATE VIRTUAL TABLE IF NOT EXISTS " + docTextTable + " USING fts5(" +
+		"document_id UNINDEXED, page UNINDEXED, sha256 UNINDEXED, body, " +
+		"tokenize = 'porter unicode61 remove_diacritics 2')").Error
+}
+
+// textDocuments narrows a query on documents to the live PDF and text
+// documents attached to a record, or to every one when kind is "".
+func textDocuments(db *gorm.DB, kind string, id uint) *gorm.DB {
+	db = db.Model(&Document{})
+	if kind != "" {
+		db = db.Where("documents."+ColEntityKind+" = ? AND documents."+ColEntityID+" = ?", kind, id)
+	}
+	return db.Where("(documents."+ColMIMEType+" = ? OR documents."+ColMIMEType+" LIKE ?)", "application/pdf", "text/%")
+}
+
+// IndexDocumentText reads the text of the PDF and text documents attached
+// to a record, or of every one when kind is "", that haven't been read
+// yet, or whose file changed since. A PDF that can't be read, such as a
+// password-protected one, is indexed as having no text rather than read
+// again each time.
+func (s *Store) IndexDocumentText(kind string, id uint) error {
+	var pending []uint
+	err := textDocuments(s.db, kind, id).
+		Where("NOT EXISTS (SELECT 1 FROM "+docTextTable+" AS p "+
+			"WHERE p.document_id = documents.id AND p.sha256 = documents.sha256)").
+		Pluck("documents."+ColID, &pending).Error
+	if err != nil {
+		return fmt.Errorf("find unread documents: %w", err)
+	}
+	for _, docID := range pending {
+		doc, err := s.GetDocument(docID)
+		if err != nil {
+			return fmt.Errorf("load document %d: %w", docID, err)
+		}
+		pages := textPages(doc)
+		err = s.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("DELETE FROM "+docTextTable+" WHERE document_id = ?", docID).Error; err != nil {
+				return err
+			}
+			if len(pages) == 0 {
+				// Page 0 marks a document with nothing to read.
+				return tx.Exec("INSERT INTO "+docTextTable+"(document_id, page, sha256, body) VALUES (?, 0, ?, '')",
+					docID, doc.ChecksumSHA256).Error
+			}
+			for i, text := range pages {
+				if strings.TrimSpace(text) == "" {
+					continue
+				}
+				err := tx.Exec("INSERT INTO "+docTextTable+"(document_id, page, sha256, body) VALUES (?, ?, ?, ?)",
+					docID, i+1, doc.ChecksumSHA256, text).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("index document %d: %w", docID, err)
+		}
+	}
+	return nil
+}
+
+// textPages returns the text of each page of a document. A text file's
+// pages are separated by form feeds, as printed documents saved as text
+// are.
+func textPages(doc Document) []string {
+	if doc.MIMEType == "application/pdf" {
+		pages, err := pdftext.Pages(doc.Data)
+		if err != nil {
+			return nil
+		}
+		return pages
+	}
+	if !utf8.Valid(doc.Data) {
+		return nil
+	}
+	var pages []string
+	for _, page := range bytes.Split(doc.Data, []byte("\f")) {
+		pages = append(pages, strings.TrimSpace(string(page)))
+	}
+	return pages
+}
+
+// TextPage is the text of a page of a document.
+type TextPage struct {
+	DocumentID uint
+	Title      string
+	Page       int
+	Text       string
+}
+
+// pageStopWords are left out of a question before it's matched against
+// document pages, since nearly every page has them.
+var pageStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "was": true, "how": true,
+	"what": true, "when": true, "where": true, "which": true, "who": true, "why": true,
+	"does": true, "did": true, "can": true, "should": true, "would": true, "could": true,
+	"this": true, "that": true, "these": true, "with": true, "from": true, "into": true,
+	"have": true, "has": true, "you": true, "your": true, "its": true, "about": true,
+	"there": true, "their": true, "them": true, "then": true, "than": true, "not": true,
+	"manual": true, "say": true, "says": true, "tell": true,
+}
+
+// pageQuery turns a question into an FTS5 query matching pages with any
+// of its words, so the pages with most of them rank first. It returns ""
+// when the question has no words worth matching.
+func pageQuery(question string) string {
+	words := strings.FieldsFunc(strings.ToLower(question), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var terms []string
+	for _, w := range words {
+		if utf8.RuneCountInString(w) >= 3 && !pageStopWords[w] {
+			terms = append(terms, `"`+w+`"`)
+		}
+	}
+	return strings.Join(terms, " OR ")
+}
+
+// SearchDocumentText returns up to limit pages of the documents attached
+// to a record that best match question, or, when none match, their first
+// pages. Call IndexDocumentText first to read any new documents.
+func (s *Store) SearchDocumentText(kind string, id uint, question string, limit int) ([]TextPage, error) {
+	var pages []TextPage
+	base := textDocuments(s.db, kind, id).
+		Select("p.document_id, documents.title, p.page, p.body AS text").
+		Joins("JOIN " + docTextTable + " AS p ON p.document_id = documents.id AND p.sha256 = documents.sha256").
+		Where("p.page > 0").
+		Limit(limit).
+		Session(&gorm.Session{})
+	if match := pageQuery(question); match != "" {
+		err := base.
+			Where("p."+docTextTable+" MATCH ?", match).
+			Order("bm25(p." + docTextTable + ")").
+			Scan(&pages).Error
+		if err != nil || len(pages) > 0 {
+			return pages, err
+		}
+	}
+	err := base.Order("documents." + ColID).Order("p.page").Scan(&pages).Error
+	return pages, err
+}
@@ -0,0 +1,65 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchDocumentText(t *testing.T) {
+	store := newTestStore(t)
+	dishwasher := Appliance{Name: "Dishwasher"}
+	require.NoError(t, store.CreateAppliance(&dishwasher))
+	other := Appliance{Name: "Fridge"}
+	require.NoError(t, store.CreateAppliance(&other))
+
+	attach := func(applianceID uint, title, mime, text string) Document {
+		doc := Document{
+			Title: title, FileName: title + ".txt", MIMEType: mime, Data: []byte(text),
+			SizeBytes: int64(len(text)), ChecksumSHA256: title + text,
+			EntityKind: DocumentEntityAppliance, EntityID: applianceID,
+		}
+		require.NoError(t, store.CreateDocument(&doc))
+		return doc
+	}
+	manual := attach(dishwasher.ID, "Manual", "text/plain",
+		"Safety first.\fLoading racks: put cups on top.\fCleaning the filter: twist the filter out and rinse it.")
+	attach(dishwasher.ID, "Photo", "image/jpeg", "filter")
+	attach(other.ID, "Fridge manual", "text/plain", "Replace the water filter every six months.")
+	attach(dishwasher.ID, "Broken", "application/pdf", "not really a PDF")
+
+	require.NoError(t, store.IndexDocumentText(DocumentEntityAppliance, dishwasher.ID))
+	pages, err := store.SearchDocumentText(DocumentEntityAppliance, dishwasher.ID, "How do I clean the filters?", 5)
+	require.NoError(t, err)
+	require.Len(t, pages, 1)
+	assert.Equal(t, TextPage{
+		DocumentID: manual.ID, Title: "Manual", Page: 3,
+		Text: "Cleaning the filter: twist the filter out and rinse it.",
+	}, pages[0])
+
+	// With nothing matching, the first pages stand in.
+	pages, err = store.SearchDocumentText(DocumentEntityAppliance, dishwasher.ID, "warranty?", 2)
+	require.NoError(t, err)
+	require.Len(t, pages, 2)
+	assert.Equal(t, []int{1, 2}, []int{pages[0].Page, pages[1].Page})
+
+	// A replaced file is read again.
+	manual.Data = []byte("Descale monthly.")
+	manual.SizeBytes, manual.ChecksumSHA256 = int64(len(manual.Data)), "new"
+	require.NoError(t, store.UpdateDocument(manual))
+	require.NoError(t, store.IndexDocumentText(DocumentEntityAppliance, dishwasher.ID))
+	pages, err = store.SearchDocumentText(DocumentEntityAppliance, dishwasher.ID, "descale", 5)
+	require.NoError(t, err)
+	require.Len(t, pages, 1)
+	assert.Equal(t, "Descale monthly.", pages[0].Text)
+
+	// Deleted manuals aren't searched.
+	require.NoError(t, store.DeleteDocument(manual.ID))
+	pages, err = store.SearchDocumentText(DocumentEntityAppliance, dishwasher.ID, "descale", 5)
+	require.NoError(t, err)
+	assert.Empty(t, pages)
+}
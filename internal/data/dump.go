@@ -0,0 +1,128 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// dumpTables are the tables "webcasa export tables" writes, by the names it
+// knows them by, with the date column -since and -until filter on.
+var dumpTables = []dumpTable{
+	{"house", &HouseProfile{}, ""},
+	{"projects", &Project{}, ColCreatedAt},
+	{"vendors", &Vendor{}, ColCreatedAt},
+	{"quotes", &Quote{}, ColCreatedAt},
+	{"appliances", &Appliance{}, ColCreatedAt},
+	{"maintenance", &MaintenanceItem{}, ColCreatedAt},
+	{"service-logs", &ServiceLogEntry{}, ColServicedAt},
+	{"expenses", &Expense{}, "spent_on"},
+}
+
+type dumpTable struct {
+	name    string
+	model   any
+	dateCol string
+}
+
+// DumpTableNames lists the tables DumpTable accepts.
+func DumpTableNames() []string {
+	names := make([]string, len(dumpTables))
+	for i, t := range dumpTables {
+		names[i] = t.name
+	}
+	return names
+}
+
+// DumpOptions filters the rows DumpTable returns.
+type DumpOptions struct {
+	// IncludeDeleted adds rows in the trash.
+	IncludeDeleted bool
+	// Since and Until bound the row's date, service date for service logs
+	// and creation date otherwise, as [Since, Until). Zero is unbounded.
+	// The house profile is never filtered.
+	Since, Until time.Time
+}
+
+// DumpedTable is one table's rows flattened to its columns, for writing
+// out as CSV or JSON. Values are nil, or the column's Go value with
+// pointers followed.
+type DumpedTable struct {
+	Name    string
+	Columns []string
+	Rows    [][]any
+}
+
+// DumpTable loads a table's rows, oldest first, as plain columns without
+// the related records.
+func (s *Store) DumpTable(name string, o DumpOptions) (DumpedTable, error) {
+	i := slices.IndexFunc(dumpTables, func(t dumpTable) bool { return t.name == name })
+	if i < 0 {
+		return DumpedTable{}, fmt.Errorf("%w: %s", ErrUnknownEntity, name)
+	}
+	spec := dumpTables[i]
+	stmt := &gorm.Statement{DB: s.db}
+	if err := stmt.Parse(spec.model); err != nil {
+		return DumpedTable{}, fmt.Errorf("parse model: %w", err)
+	}
+
+	q := s.db.Model(spec.model)
+	if o.IncludeDeleted {
+		q = q.Unscoped()
+	}
+	if spec.dateCol != "" && !o.Since.IsZero() {
+		q = q.Where(spec.dateCol+" >= ?", o.Since)
+	}
+	if spec.dateCol != "" && !o.Until.IsZero() {
+		q = q.Where(spec.dateCol+" < ?", o.Until)
+	}
+	rows := reflect.New(reflect.SliceOf(reflect.TypeOf(spec.model).Elem()))
+	if err := q.Order(ColID).Find(rows.Interface()).Error; err != nil {
+		return DumpedTable{}, fmt.Errorf("load %s: %w", name, err)
+	}
+
+	out := DumpedTable{Name: name}
+	var fields []int
+	for j, f := range stmt.Schema.Fields {
+		if f.DBName != "" {
+			out.Columns = append(out.Columns, f.DBName)
+			fields = append(fields, j)
+		}
+	}
+	ctx := context.Background()
+	for r := range rows.Elem().Len() {
+		row := rows.Elem().Index(r)
+		values := make([]any, 0, len(fields))
+		for _, j := range fields {
+			v, _ := stmt.Schema.Fields[j].ValueOf(ctx, row)
+			values = append(values, plainValue(v))
+		}
+		out.Rows = append(out.Rows, values)
+	}
+	return out, nil
+}
+
+// plainValue follows pointers and unwraps soft-delete times.
+func plainValue(v any) any {
+	if d, ok := v.(gorm.DeletedAt); ok {
+		if !d.Valid {
+			return nil
+		}
+		return d.Time
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		return rv.Elem().Interface()
+	}
+	return v
+}
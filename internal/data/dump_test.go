@@ -0,0 +1,53 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpTable(t *testing.T) {
+	store := newTestStore(t)
+	categories, err := store.MaintenanceCategories()
+	require.NoError(t, err)
+	furnace := MaintenanceItem{Name: "Furnace", CategoryID: categories[0].ID}
+	require.NoError(t, store.CreateMaintenance(&furnace))
+	cost := int64(12_500)
+	var entries []ServiceLogEntry
+	for _, day := range []int{1, 15, 28} {
+		entry := ServiceLogEntry{
+			MaintenanceItemID: furnace.ID,
+			ServicedAt:        time.Date(2026, 2, day, 0, 0, 0, 0, time.UTC),
+			CostCents:         &cost,
+		}
+		require.NoError(t, store.CreateServiceLog(&entry, Vendor{}))
+		entries = append(entries, entry)
+	}
+	require.NoError(t, store.DeleteServiceLog(entries[2].ID))
+
+	all, err := store.DumpTable("service-logs", DumpOptions{IncludeDeleted: true})
+	require.NoError(t, err)
+	assert.Len(t, all.Rows, 3)
+	assert.Contains(t, all.Columns, ColServicedAt)
+	assert.NotContains(t, all.Columns, "maintenance_item", "related records are left out")
+
+	ranged, err := store.DumpTable("service-logs", DumpOptions{
+		Since: time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC),
+		Until: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	require.Len(t, ranged.Rows, 1, "Feb 1 is too early and Feb 28 is in the trash")
+	assert.Equal(t, entries[1].ID, ranged.Rows[0][0])
+	costCol := slices.Index(ranged.Columns, "cost_cents")
+	require.GreaterOrEqual(t, costCol, 0)
+	assert.Equal(t, cost, ranged.Rows[0][costCol], "pointers are followed")
+
+	_, err = store.DumpTable("chat_inputs", DumpOptions{})
+	assert.ErrorIs(t, err, ErrUnknownEntity)
+}
@@ -0,0 +1,376 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"slices"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// embeddingsTable caches the embeddings of document pages and of the notes
+// on records, so questions can be matched to them by meaning. Like the
+// search index it is derived data: it isn't exported or dumped, and only
+// text that is new, or changed since it was embedded, is embedded again.
+const embeddingsTable = searchIndexTable + "_embeddings"
+
+// EmbedPage is the Source of a page of a document. The Source of a
+// record's notes is its table.
+const EmbedPage = "page"
+
+// Embedding is the embedding of a page of a document or of a record's
+// notes, by one model.
+type Embedding struct {
+	ID       uint   `gorm:"primaryKey"`
+	Source   string `gorm:"uniqueIndex:idx_embedding_source,priority:1"`
+	SourceID uint   `gorm:"uniqueIndex:idx_embedding_source,priority:2"`
+	// Part is the page number of a page, and 0 for notes.
+	Part int `gorm:"uniqueIndex:idx_embedding_source,priority:3"`
+	// Checksum is the SHA-256 of the text, so changed text is embedded
+	// again.
+	Checksum   string
+	Model      string
+	Dimensions int
+	// Vector holds the embedding as little-endian float32s.
+	Vector []byte
+}
+
+// TableName keeps the cache with the search index it sits beside.
+func (Embedding) TableName() string { return embeddingsTable }
+
+// EmbedChunk is a piece of text to embed: a page of a document or a
+// record's notes.
+type EmbedChunk struct {
+	Source   string
+	SourceID uint
+	Part     int
+	// Label is the document's title, or the record's name.
+	Label string
+	Text  string
+}
+
+func (c EmbedChunk) checksum() string {
+	sum := sha256.Sum256([]byte(c.Text))
+	return hex.EncodeToString(sum[:])
+}
+
+type embedKey struct {
+	source string
+	id     uint
+	part   int
+}
+
+// embedded returns the checksum and model of each embedding of sources,
+// by its source.
+func (s *Store) embedded(sources func(*gorm.DB) *gorm.DB) (map[embedKey]Embedding, error) {
+	var rows []Embedding
+	err := sources(s.db.Model(&Embedding{})).
+		Select(ColID, "source", "source_id", "part", "checksum", "model").
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("load embeddings: %w", err)
+	}
+	known := make(map[embedKey]Embedding, len(rows))
+	for _, e := range rows {
+		known[embedKey{e.Source, e.SourceID, e.Part}] = e
+	}
+	return known, nil
+}
+
+// stale keeps the chunks model hasn't embedded as they are now. When all
+// is set, the chunks are every one of their sources, and the embeddings
+// of text that's gone are forgotten.
+func (s *Store) stale(model string, chunks []EmbedChunk, known map[embedKey]Embedding, all bool) ([]EmbedChunk, error) {
+	var out []EmbedChunk
+	for _, c := range chunks {
+		k := embedKey{c.Source, c.SourceID, c.Part}
+		if e, ok := known[k]; !ok || e.Model != model || e.Checksum != c.checksum() {
+			out = append(out, c)
+		}
+		delete(known, k)
+	}
+	if all && len(known) > 0 {
+		gone := make([]uint, 0, len(known))
+		for _, e := range known {
+			gone = append(gone, e.ID)
+		}
+		if err := s.db.Delete(&Embedding{}, gone).Error; err != nil {
+			return nil, fmt.Errorf("forget embeddings: %w", err)
+		}
+	}
+	return out, nil
+}
+
+// StalePages returns the pages of the documents attached to a record, or
+// of every document when kind is "", that model hasn't embedded as they
+// are now. Documents whose text hasn't been read yet are read first.
+func (s *Store) StalePages(model, kind string, id uint) ([]EmbedChunk, error) {
+	if err := s.IndexDocumentText(kind, id); err != nil {
+		return nil, err
+	}
+	var pages []TextPage
+	err := textDocuments(s.db, kind, id).
+		Select("p.document_id, documents.title, p.page, p.body AS text").
+		Joins("JOIN " + docTextTable + " AS p ON p.document_id = documents.id AND p.sha256 = documents.sha256").
+		Where("p.page > 0").
+		Order("p.document_id").Order("p.page").
+		Scan(&pages).Error
+	if err != nil {
+		return nil, fmt.Errorf("list document pages: %w", err)
+	}
+	chunks := make([]EmbedChunk, len(pages))
+	docs := make([]uint, 0, len(pages))
+	for i, p := range pages {
+		chunks[i] = EmbedChunk{Source: EmbedPage, SourceID: p.DocumentID, Part: p.Page, Label: p.Title, Text: p.Text}
+		docs = append(docs, p.DocumentID)
+	}
+	known, err := s.embedded(func(q *gorm.DB) *gorm.DB {
+		q = q.Where("source = ?", EmbedPage)
+		if kind != "" {
+			q = q.Where("source_id IN ?", append(docs, 0))
+		}
+		return q
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.stale(model, chunks, known, kind == "")
+}
+
+// noteTable is a table whose rows have notes.
+type noteTable struct {
+	name      string
+	label     string
+	trashable bool
+}
+
+// noteTables lists the tables of records with notes, with the column that
+// names each record.
+func (s *Store) noteTables() ([]noteTable, error) {
+	skipped := map[string]bool{}
+	for _, t := range privateTables {
+		skipped[t] = true
+	}
+	for _, model := range unauditedModels() {
+		stmt := &gorm.Statement{DB: s.db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, err
+		}
+		skipped[stmt.Schema.Table] = true
+	}
+	var tables []noteTable
+	for _, model := range allModels() {
+		stmt := &gorm.Statement{DB: s.db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, err
+		}
+		sch := stmt.Schema
+		if skipped[sch.Table] || sch.LookUpField(ColNotes) == nil {
+			continue
+		}
+		t := noteTable{name: sch.Table, label: ColID, trashable: sch.LookUpField(ColDeletedAt) != nil}
+		for _, col := range []string{ColName, ColTitle} {
+			if sch.LookUpField(col) != nil {
+				t.label = col
+				break
+			}
+		}
+		tables = append(tables, t)
+	}
+	return tables, nil
+}
+
+// notes returns the notes on the live records of a table, of only the
+// rows ids when any are given.
+func (s *Store) notes(t noteTable, ids ...uint) ([]EmbedChunk, error) {
+	var rows []struct {
+		ID    uint
+		Label string
+		Notes string
+	}
+	q := s.db.Table(t.name).
+		Select(ColID + ", CAST(" + t.label + " AS TEXT) AS label, " + ColNotes).
+		Where(ColNotes + " <> ''")
+	if t.trashable {
+		q = q.Where(ColDeletedAt + " IS NULL")
+	}
+	if len(ids) > 0 {
+		q = q.Where(ColID+" IN ?", ids)
+	}
+	if err := q.Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("list %s notes: %w", t.name, err)
+	}
+	chunks := make([]EmbedChunk, len(rows))
+	for i, r := range rows {
+		chunks[i] = EmbedChunk{Source: t.name, SourceID: r.ID, Label: r.Label, Text: r.Notes}
+	}
+	return chunks, nil
+}
+
+// StaleNotes returns the notes on records that model hasn't embedded as
+// they are now, and forgets the embeddings of notes that are gone.
+func (s *Store) StaleNotes(model string) ([]EmbedChunk, error) {
+	tables, err := s.noteTables()
+	if err != nil {
+		return nil, err
+	}
+	var chunks []EmbedChunk
+	for _, t := range tables {
+		notes, err := s.notes(t)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, notes...)
+	}
+	known, err := s.embedded(func(q *gorm.DB) *gorm.DB { return q.Where("source <> ?", EmbedPage) })
+	if err != nil {
+		return nil, err
+	}
+	return s.stale(model, chunks, known, true)
+}
+
+// SaveEmbedding stores the embedding of a chunk by model.
+func (s *Store) SaveEmbedding(model string, c EmbedChunk, vector []float32) error {
+	e := Embedding{
+		Source: c.Source, SourceID: c.SourceID, Part: c.Part, Checksum: c.checksum(),
+		Model: model, Dimensions: len(vector), Vector: encodeVector(vector),
+	}
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "source"}, {Name: "source_id"}, {Name: "part"}},
+		DoUpdates: clause.AssignmentColumns([]string{"checksum", "model", "dimensions", "vector"}),
+	}).Create(&e).Error
+}
+
+// ClearEmbeddings forgets every embedding, as when the embedding model
+// changes, and returns how many there were.
+func (s *Store) ClearEmbeddings() (int64, error) {
+	res := s.db.Where("1 = 1").Delete(&Embedding{})
+	return res.RowsAffected, res.Error
+}
+
+// CountEmbeddings returns how many embeddings model made are cached, and
+// how many by other models.
+func (s *Store) CountEmbeddings(model string) (mine, others int64, err error) {
+	if err := s.db.Model(&Embedding{}).Where("model = ?", model).Count(&mine).Error; err != nil {
+		return 0, 0, err
+	}
+	err = s.db.Model(&Embedding{}).Where("model <> ?", model).Count(&others).Error
+	return mine, others, err
+}
+
+// NearestPages returns up to limit pages of the documents attached to a
+// record whose embeddings by model are nearest to vector. Pages embedded
+// before their text changed are left out.
+func (s *Store) NearestPages(model, kind string, id uint, vector []float32, limit int) ([]TextPage, error) {
+	var rows []struct {
+		TextPage
+		Vector []byte
+	}
+	err := textDocuments(s.db, kind, id).
+		Select("p.document_id, documents.title, p.page, p.body AS text, e.vector").
+		Joins("JOIN "+docTextTable+" AS p ON p.document_id = documents.id AND p.sha256 = documents.sha256").
+		Joins("JOIN "+embeddingsTable+" AS e ON e.source = ? AND e.source_id = p.document_id AND e.part = p.page", EmbedPage).
+		Where("e.model = ? AND e.dimensions = ?", model, len(vector)).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("search document pages: %w", err)
+	}
+	scores := make(map[int]float64, len(rows))
+	for i := range rows {
+		scores[i] = cosine(vector, decodeVector(rows[i].Vector))
+	}
+	order := nearest(scores, limit)
+	pages := make([]TextPage, len(order))
+	for i, n := range order {
+		pages[i] = rows[n].TextPage
+	}
+	return pages, nil
+}
+
+// NearestNotes returns up to limit of the notes on live records whose
+// embeddings by model are nearest to vector.
+func (s *Store) NearestNotes(model string, vector []float32, limit int) ([]EmbedChunk, error) {
+	var rows []Embedding
+	err := s.db.Where("source <> ? AND model = ? AND dimensions = ?", EmbedPage, model, len(vector)).
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("search notes: %w", err)
+	}
+	scores := make(map[int]float64, len(rows))
+	for i, e := range rows {
+		scores[i] = cosine(vector, decodeVector(e.Vector))
+	}
+	tables, err := s.noteTables()
+	if err != nil {
+		return nil, err
+	}
+	var out []EmbedChunk
+	for _, n := range nearest(scores, len(rows)) {
+		e := rows[n]
+		i := slices.IndexFunc(tables, func(t noteTable) bool { return t.name == e.Source })
+		if i < 0 {
+			continue
+		}
+		notes, err := s.notes(tables[i], e.SourceID)
+		if err != nil {
+			return nil, err
+		}
+		if len(notes) == 0 || notes[0].checksum() != e.Checksum {
+			continue
+		}
+		if out = append(out, notes[0]); len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// nearest returns the indices of the limit highest scores, highest first.
+func nearest(scores map[int]float64, limit int) []int {
+	order := make([]int, 0, len(scores))
+	for i := range scores {
+		order = append(order, i)
+	}
+	slices.SortFunc(order, func(x, y int) int {
+		return cmp.Or(cmp.Compare(scores[y], scores[x]), cmp.Compare(x, y))
+	})
+	return order[:min(limit, len(order))]
+}
+
+func encodeVector(v []float32) []byte {
+	b := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(f))
+	}
+	return b
+}
+
+func decodeVector(b []byte) []float32 {
+	v := make([]float32, len(b)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	return v
+}
+
+// cosine is the cosine similarity of two vectors of the same length.
+func cosine(a, b []float32) float64 {
+	var dot, na, nb float64
+	for i := range min(len(a), len(b)) {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddingsAreIncremental(t *testing.T) {
+	store := newTestStore(t)
+	kettle := Appliance{Name: "Kettle", Notes: "Descale monthly."}
+	require.NoError(t, store.CreateAppliance(&kettle))
+	require.NoError(t, store.CreateVendor(&Vendor{Name: "Acme", Notes: "Slow to call back."}))
+	text := "Filling: up to the MAX line.\fDescaling: boil vinegar."
+	require.NoError(t, store.CreateDocument(&Document{
+		Title: "Kettle manual", FileName: "kettle.txt", MIMEType: "text/plain", Data: []byte(text),
+		SizeBytes: int64(len(text)), ChecksumSHA256: "v1", EntityKind: DocumentEntityAppliance, EntityID: kettle.ID,
+	}))
+
+	// embedAll embeds each chunk as a vector pointing one way for text
+	// about descaling and another for anything else.
+	embedAll := func(model string, chunks []EmbedChunk) {
+		for _, c := range chunks {
+			v := []float32{0, 1}
+			if c.Text == "Descale monthly." || c.Part == 2 {
+				v = []float32{1, 0}
+			}
+			require.NoError(t, store.SaveEmbedding(model, c, v))
+		}
+	}
+
+	pages, err := store.StalePages("m1", "", 0)
+	require.NoError(t, err)
+	require.Len(t, pages, 2)
+	assert.Equal(t, EmbedChunk{Source: EmbedPage, SourceID: 1, Part: 2, Label: "Kettle manual", Text: "Descaling: boil vinegar."}, pages[1])
+	notes, err := store.StaleNotes("m1")
+	require.NoError(t, err)
+	require.Len(t, notes, 2)
+	embedAll("m1", append(pages, notes...))
+
+	pages, err = store.StalePages("m1", DocumentEntityAppliance, kettle.ID)
+	require.NoError(t, err)
+	assert.Empty(t, pages)
+	notes, err = store.StaleNotes("m1")
+	require.NoError(t, err)
+	assert.Empty(t, notes)
+
+	// Only changed text is embedded again, and another model embeds it all.
+	kettle.Notes = "Descale every two weeks."
+	require.NoError(t, store.UpdateAppliance(kettle))
+	notes, err = store.StaleNotes("m1")
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.Equal(t, "Descale every two weeks.", notes[0].Text)
+	pages, err = store.StalePages("m2", "", 0)
+	require.NoError(t, err)
+	assert.Len(t, pages, 2)
+
+	found, err := store.NearestPages("m1", DocumentEntityAppliance, kettle.ID, []float32{0.9, 0.1}, 1)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, 2, found[0].Page)
+	// The kettle's notes changed since they were embedded, so only the
+	// vendor's match.
+	matched, err := store.NearestNotes("m1", []float32{1, 0}, 5)
+	require.NoError(t, err)
+	require.Len(t, matched, 1)
+	assert.Equal(t, "Acme", matched[0].Label)
+	none, err := store.NearestNotes("m1", []float32{1, 0, 0}, 5)
+	require.NoError(t, err)
+	assert.Empty(t, none, "vectors of another size don't match")
+
+	// Notes that are gone are forgotten.
+	require.NoError(t, store.DeleteAppliance(kettle.ID))
+	_, err = store.StaleNotes("m1")
+	require.NoError(t, err)
+	mine, others, err := store.CountEmbeddings("m1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), mine)
+	assert.Zero(t, others)
+
+	cleared, err := store.ClearEmbeddings()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), cleared)
+}
@@ -0,0 +1,416 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data/sqlite"
+)
+
+// An encrypted database is a whole SQLite file sealed at rest, laid out as
+//
+//	magic (8) | salt (16) | nonce (12) | AES-256-GCM ciphertext
+//
+// with the key derived from the passphrase by PBKDF2-SHA256 and the header
+// authenticated as additional data. It's opened into memory, and every
+// change is sealed back to the file shortly after it's made and on Close.
+const (
+	encryptedMagic     = "WCDBENC1"
+	encryptedSaltSize  = 16
+	encryptedKDFRounds = 600_000
+	encryptedKeySize   = 32
+	encryptedHeader    = len(encryptedMagic) + encryptedSaltSize
+	// MinPassphrase is the shortest passphrase a database can be encrypted
+	// with.
+	MinPassphrase = 8
+)
+
+// sealInterval is how often an encrypted store checks for changes to seal.
+var sealInterval = time.Second
+
+var (
+	// ErrEncrypted is returned by Open and OpenReadOnly for a database that
+	// needs a passphrase; use OpenEncrypted.
+	ErrEncrypted = errors.New("database is encrypted; a passphrase is needed to open it")
+	// ErrWrongPassphrase is returned when an encrypted database can't be
+	// opened, either because the passphrase is wrong or the file was
+	// modified.
+	ErrWrongPassphrase = errors.New("wrong passphrase or corrupted database")
+)
+
+// IsEncrypted reports whether the file at path is an encrypted database. A
+// file that doesn't exist isn't.
+func IsEncrypted(path string) (bool, error) {
+	if path == ":memory:" {
+		return false, nil
+	}
+	f, err := os.Open(path) //nolint:gosec // the user's own database
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	head := make([]byte, len(encryptedMagic))
+	if _, err := io.ReadFull(f, head); err != nil {
+		return false, nil
+	}
+	return string(head) == encryptedMagic, nil
+}
+
+// OpenEncrypted opens the encrypted database at path, creating it if it
+// doesn't exist.
+func OpenEncrypted(path, passphrase string) (*Store, error) {
+	return openEncrypted(path, passphrase, false)
+}
+
+// OpenEncryptedReadOnly opens an encrypted database without ever writing
+// to it.
+func OpenEncryptedReadOnly(path, passphrase string) (*Store, error) {
+	return openEncrypted(path, passphrase, true)
+}
+
+func openEncrypted(path, passphrase string, readOnly bool) (*Store, error) {
+	if path == ":memory:" {
+		return nil, fmt.Errorf("an in-memory database can't be encrypted")
+	}
+	if err := ValidateDBPath(path); err != nil {
+		return nil, err
+	}
+	var (
+		f     *sealedFile
+		image []byte
+	)
+	raw, err := os.ReadFile(path) //nolint:gosec // the user's own database
+	switch {
+	case errors.Is(err, os.ErrNotExist) && !readOnly:
+		if f, err = newSealedFile(path, passphrase); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, fmt.Errorf("read database: %w", err)
+	default:
+		if f, image, err = unsealFile(path, raw, passphrase); err != nil {
+			return nil, err
+		}
+	}
+
+	s, err := open(":memory:", readOnly)
+	if err != nil {
+		return nil, err
+	}
+	if image != nil {
+		if err := s.withConn(func(c *sql.Conn) error {
+			return c.Raw(func(dc any) error { return sqlite.Restore(dc, image) })
+		}); err != nil {
+			_ = s.Close()
+			return nil, fmt.Errorf("load database: %w", err)
+		}
+	}
+	if !readOnly {
+		s.sealed = f
+		f.start(s)
+	}
+	return s, nil
+}
+
+// EncryptDatabase seals the plain database at src into a new encrypted
+// database at dst.
+func EncryptDatabase(src, dst, passphrase string) error {
+	if encrypted, err := IsEncrypted(src); err != nil {
+		return err
+	} else if encrypted {
+		return fmt.Errorf("%s is already encrypted", src)
+	}
+	if err := refuseExisting(dst); err != nil {
+		return err
+	}
+	if _, err := os.Stat(src); err != nil {
+		return err
+	}
+	store, err := OpenReadOnly(src)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	image, err := store.serialize()
+	if err != nil {
+		return err
+	}
+	f, err := newSealedFile(dst, passphrase)
+	if err != nil {
+		return err
+	}
+	return f.write(image)
+}
+
+// DecryptDatabase writes the encrypted database at src out as a plain
+// database at dst.
+func DecryptDatabase(src, dst, passphrase string) error {
+	if err := refuseExisting(dst); err != nil {
+		return err
+	}
+	raw, err := os.ReadFile(src) //nolint:gosec // the user's own database
+	if err != nil {
+		return fmt.Errorf("read database: %w", err)
+	}
+	_, image, err := unsealFile(src, raw, passphrase)
+	if err != nil {
+		return err
+	}
+	return writeAtomically(dst, image)
+}
+
+// Encrypted reports whether the store is sealed at rest.
+func (s *Store) Encrypted() bool {
+	return s.sealed != nil
+}
+
+// serialize returns the database as the bytes of a SQLite file. The image
+// is marked as using a rollback journal, since an in-memory database can't
+// load one marked for WAL.
+func (s *Store) serialize() ([]byte, error) {
+	var image []byte
+	err := s.withConn(func(c *sql.Conn) error {
+		return c.Raw(func(dc any) error {
+			var err error
+			image, err = sqlite.Serialize(dc)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("serialize database: %w", err)
+	}
+	if len(image) > 19 {
+		image[18], image[19] = 1, 1
+	}
+	return image, nil
+}
+
+func (s *Store) withConn(fn func(*sql.Conn) error) error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return fmt.Errorf("get underlying db: %w", err)
+	}
+	c, err := sqlDB.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	return fn(c)
+}
+
+// sealedFile is where an encrypted store is kept. The key is derived once,
+// when it's opened, and each save uses a fresh nonce.
+type sealedFile struct {
+	path string
+	salt []byte
+	aead cipher.AEAD
+
+	mu sync.Mutex
+	// changes is the connection's total_changes() when last saved.
+	changes int64
+	// unsaved is why the last save failed, or nil if it worked.
+	unsaved atomic.Pointer[string]
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+func newSealedFile(path, passphrase string) (*sealedFile, error) {
+	if len(passphrase) < MinPassphrase {
+		return nil, fmt.Errorf("passphrase must be at least %d characters", MinPassphrase)
+	}
+	salt := make([]byte, encryptedSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generate salt: %w", err)
+	}
+	aead, err := newDatabaseAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	return &sealedFile{path: path, salt: salt, aead: aead}, nil
+}
+
+func unsealFile(path string, raw []byte, passphrase string) (*sealedFile, []byte, error) {
+	if !bytes.HasPrefix(raw, []byte(encryptedMagic)) {
+		return nil, nil, fmt.Errorf("%s is not an encrypted database", path)
+	}
+	if len(raw) < encryptedHeader {
+		return nil, nil, ErrWrongPassphrase
+	}
+	salt := bytes.Clone(raw[len(encryptedMagic):encryptedHeader])
+	aead, err := newDatabaseAEAD(passphrase, salt)
+	if err != nil {
+		return nil, nil, err
+	}
+	headerLen := encryptedHeader + aead.NonceSize()
+	if len(raw) < headerLen+aead.Overhead() {
+		return nil, nil, ErrWrongPassphrase
+	}
+	header := raw[:headerLen]
+	image, err := aead.Open(nil, raw[encryptedHeader:headerLen], raw[headerLen:], header)
+	if err != nil {
+		return nil, nil, ErrWrongPassphrase
+	}
+	return &sealedFile{path: path, salt: salt, aead: aead}, image, nil
+}
+
+func newDatabaseAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, encryptedKDFRounds, encryptedKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// write seals image and replaces the file with it.
+func (f *sealedFile) write(image []byte) error {
+	nonce := make([]byte, f.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("generate nonce: %w", err)
+	}
+	header := make([]byte, 0, encryptedHeader+len(nonce))
+	header = append(header, encryptedMagic...)
+	header = append(header, f.salt...)
+	header = append(header, nonce...)
+	return writeAtomically(f.path, f.aead.Seal(header, nonce, image, header))
+}
+
+// start saves the store once so a new database exists on disk, then
+// keeps saving it in the background as it changes.
+func (f *sealedFile) start(s *Store) {
+	f.mu.Lock()
+	f.changes = -1
+	f.note(s, f.save(s))
+	f.mu.Unlock()
+	f.stop = make(chan struct{})
+	f.stopped = make(chan struct{})
+	go func() {
+		defer close(f.stopped)
+		tick := time.NewTicker(sealInterval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-f.stop:
+				return
+			case <-tick.C:
+				// A failed save is tried again on the next tick, and
+				// Close reports it if it still fails then.
+				f.mu.Lock()
+				f.note(s, f.save(s))
+				f.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// save seals the store to its file if it has changed since last time.
+// Callers hold f.mu.
+func (f *sealedFile) save(s *Store) error {
+	var changes int64
+	if err := s.withConn(func(c *sql.Conn) error {
+		return c.QueryRowContext(context.Background(), "SELECT total_changes()").Scan(&changes)
+	}); err != nil {
+		return fmt.Errorf("check for changes: %w", err)
+	}
+	if changes == f.changes {
+		return nil
+	}
+	image, err := s.serialize()
+	if err != nil {
+		return err
+	}
+	if err := f.write(image); err != nil {
+		return fmt.Errorf("save encrypted database: %w", err)
+	}
+	f.changes = changes
+	return nil
+}
+
+// note records how a background save went for Health, logging when saves
+// start failing and when they work again. Callers hold f.mu.
+func (f *sealedFile) note(s *Store, err error) {
+	was := f.unsaved.Load()
+	switch {
+	case err != nil:
+		msg := err.Error()
+		f.unsaved.Store(&msg)
+		if was == nil {
+			s.health.logf("webcasa: can't save the encrypted database, changes are only in memory until it can: %v\n", err)
+		}
+	case was != nil:
+		f.unsaved.Store(nil)
+		s.health.logf("webcasa: encrypted database saved again\n")
+	}
+}
+
+// close stops saving in the background and saves once more.
+func (f *sealedFile) close(s *Store) error {
+	close(f.stop)
+	<-f.stopped
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.save(s)
+}
+
+// backup seals a copy of the store to path with the same key.
+func (f *sealedFile) backup(s *Store, path string) error {
+	image, err := s.serialize()
+	if err != nil {
+		return err
+	}
+	copied := &sealedFile{path: path, salt: f.salt, aead: f.aead}
+	return copied.write(image)
+}
+
+// writeAtomically replaces path with b, so a crash leaves either the old
+// file or the new one.
+func writeAtomically(path string, b []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func refuseExisting(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPassphrase = "correct horse battery"
+
+func vendorNames(t *testing.T, store *Store) []string {
+	t.Helper()
+	vendors, err := store.ListVendors(false)
+	require.NoError(t, err)
+	names := make([]string, 0, len(vendors))
+	for _, v := range vendors {
+		names = append(names, v.Name)
+	}
+	return names
+}
+
+func TestEncryptDecryptDatabase(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "plain.db")
+	sealed := filepath.Join(dir, "sealed.db")
+	store, err := Open(plain)
+	require.NoError(t, err)
+	require.NoError(t, store.AutoMigrate())
+	require.NoError(t, store.CreateVendor(&Vendor{Name: "Acme Plumbing"}))
+	require.NoError(t, store.Close())
+
+	require.ErrorContains(t, EncryptDatabase(plain, sealed, "short"), "at least 8")
+	require.NoError(t, EncryptDatabase(plain, sealed, testPassphrase))
+	require.ErrorContains(t, EncryptDatabase(plain, sealed, testPassphrase), "already exists")
+	raw, err := os.ReadFile(sealed)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "Acme Plumbing")
+	assert.NotContains(t, string(raw), "SQLite format")
+
+	encrypted, err := IsEncrypted(sealed)
+	require.NoError(t, err)
+	assert.True(t, encrypted)
+	_, err = Open(sealed)
+	require.ErrorIs(t, err, ErrEncrypted)
+	_, err = OpenEncrypted(sealed, "not the passphrase")
+	require.ErrorIs(t, err, ErrWrongPassphrase)
+	require.ErrorContains(t, EncryptDatabase(sealed, filepath.Join(dir, "again.db"), testPassphrase), "already encrypted")
+
+	// Changes are sealed back to the file on Close.
+	store, err = OpenEncrypted(sealed, testPassphrase)
+	require.NoError(t, err)
+	assert.True(t, store.Encrypted())
+	require.NoError(t, store.AutoMigrate())
+	assert.Equal(t, []string{"Acme Plumbing"}, vendorNames(t, store))
+	require.NoError(t, store.CreateVendor(&Vendor{Name: "Bright Electric"}))
+	backup := filepath.Join(dir, "backup.db")
+	require.NoError(t, store.Backup(backup))
+	require.NoError(t, store.Close())
+
+	ro, err := OpenEncryptedReadOnly(sealed, testPassphrase)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Acme Plumbing", "Bright Electric"}, vendorNames(t, ro))
+	require.Error(t, ro.CreateVendor(&Vendor{Name: "Nope"}))
+	require.NoError(t, ro.Close())
+
+	// A backup stays encrypted with the same passphrase.
+	_, err = Open(backup)
+	require.ErrorIs(t, err, ErrEncrypted)
+	require.NoError(t, DecryptDatabase(backup, filepath.Join(dir, "from-backup.db"), testPassphrase))
+
+	decrypted := filepath.Join(dir, "decrypted.db")
+	require.ErrorIs(t, DecryptDatabase(sealed, decrypted, "not the passphrase"), ErrWrongPassphrase)
+	require.NoError(t, DecryptDatabase(sealed, decrypted, testPassphrase))
+	store, err = Open(decrypted)
+	require.NoError(t, err)
+	defer store.Close()
+	require.NoError(t, store.AutoMigrate())
+	assert.ElementsMatch(t, []string{"Acme Plumbing", "Bright Electric"}, vendorNames(t, store))
+}
+
+func TestOpenEncryptedCreatesDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "new.db")
+	_, err := OpenEncrypted(path, "short")
+	require.ErrorContains(t, err, "at least 8")
+
+	store, err := OpenEncrypted(path, testPassphrase)
+	require.NoError(t, err)
+	encrypted, err := IsEncrypted(path)
+	require.NoError(t, err)
+	assert.True(t, encrypted, "a new encrypted database is written when it's opened")
+	require.NoError(t, store.AutoMigrate())
+	require.NoError(t, store.SeedDefaults())
+	require.NoError(t, store.CreateVendor(&Vendor{Name: "Acme"}))
+	require.NoError(t, store.Close())
+
+	store, err = OpenEncrypted(path, testPassphrase)
+	require.NoError(t, err)
+	defer store.Close()
+	assert.Equal(t, []string{"Acme"}, vendorNames(t, store))
+}
+
+func TestEncryptedSaveFailuresAreReported(t *testing.T) {
+	interval := sealInterval
+	sealInterval = 10 * time.Millisecond
+	t.Cleanup(func() { sealInterval = interval })
+	dir := filepath.Join(t.TempDir(), "data")
+	require.NoError(t, os.Mkdir(dir, 0o700))
+	store, err := OpenEncrypted(filepath.Join(dir, "sealed.db"), testPassphrase)
+	require.NoError(t, err)
+	var log bytes.Buffer
+	store.SetHealthLog(&log)
+	require.NoError(t, store.AutoMigrate())
+	require.Eventually(t, func() bool { return store.Health().Unsaved == "" }, time.Second, sealInterval)
+
+	// With the directory gone, changes can't be saved, and Health says so.
+	require.NoError(t, os.RemoveAll(dir))
+	require.NoError(t, store.CreateVendor(&Vendor{Name: "Acme"}))
+	require.Eventually(t, func() bool { return store.Health().Unsaved != "" }, time.Second, sealInterval)
+	assert.True(t, store.Health().OK, "the database itself still answers")
+
+	// Once it's back, the next save catches up.
+	require.NoError(t, os.Mkdir(dir, 0o700))
+	require.Eventually(t, func() bool { return store.Health().Unsaved == "" }, time.Second, sealInterval)
+	require.NoError(t, store.Close())
+	assert.Contains(t, log.String(), "can't save the encrypted database")
+	assert.Contains(t, log.String(), "saved again")
+
+	store, err = OpenEncrypted(filepath.Join(dir, "sealed.db"), testPassphrase)
+	require.NoError(t, err)
+	defer store.Close()
+	assert.Equal(t, []string{"Acme"}, vendorNames(t, store))
+}
@@ -0,0 +1,184 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Estimate defaults, used when the corresponding input is zero.
+const (
+	DefaultPaintCoats            = 2
+	DefaultPaintCoverageSqFt     = 350.0 // per gallon, typical for interior latex
+	DefaultFlooringWasteFraction = 0.10
+)
+
+// EstimateInputs are the knobs for a material estimate. Zero values fall
+// back to the defaults above; zero tile dimensions skip the tile count.
+type EstimateInputs struct {
+	Coats                 int
+	CoverageSqFtPerGallon float64
+	WasteFraction         float64
+	TileWidthIn           float64
+	TileLengthIn          float64
+}
+
+// MaterialEstimate is a paint/flooring/tile estimate for one room, saved on
+// a project. The room's dimensions are copied in so the estimate still reads
+// correctly if the room is later edited or deleted.
+type MaterialEstimate struct {
+	ID                    uint    `gorm:"primaryKey"`
+	ProjectID             uint    `gorm:"index"`
+	Project               Project `gorm:"constraint:OnDelete:RESTRICT;"`
+	RoomID                *uint   `gorm:"index"`
+	Room                  Room    `gorm:"constraint:OnDelete:SET NULL;"`
+	RoomName              string
+	LengthFt              float64
+	WidthFt               float64
+	HeightFt              float64
+	Doors                 int
+	Windows               int
+	Coats                 int
+	CoverageSqFtPerGallon float64
+	WasteFraction         float64
+	TileWidthIn           float64
+	TileLengthIn          float64
+	WallAreaSqFt          float64
+	FloorAreaSqFt         float64
+	PaintGallons          float64
+	PaintGallonsToBuy     int
+	FlooringSqFt          float64
+	TileCount             int
+	Notes                 string
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+	DeletedAt             gorm.DeletedAt `gorm:"index"`
+}
+
+// EstimateMaterials computes paint, flooring, and tile quantities for a room.
+// Quantities you buy (gallons, square feet, tiles) are rounded up.
+func EstimateMaterials(room Room, in EstimateInputs) (MaterialEstimate, error) {
+	if in.Coats < 0 || in.CoverageSqFtPerGallon < 0 || in.WasteFraction < 0 {
+		return MaterialEstimate{}, fmt.Errorf("estimate inputs must be non-negative")
+	}
+	if in.TileWidthIn < 0 || in.TileLengthIn < 0 {
+		return MaterialEstimate{}, fmt.Errorf("tile dimensions must be non-negative")
+	}
+	if in.Coats == 0 {
+		in.Coats = DefaultPaintCoats
+	}
+	if in.CoverageSqFtPerGallon == 0 {
+		in.CoverageSqFtPerGallon = DefaultPaintCoverageSqFt
+	}
+	if in.WasteFraction == 0 {
+		in.WasteFraction = DefaultFlooringWasteFraction
+	}
+
+	wall := room.WallAreaSqFt()
+	floor := room.FloorAreaSqFt()
+	gallons := wall * float64(in.Coats) / in.CoverageSqFtPerGallon
+	withWaste := floor * (1 + in.WasteFraction)
+
+	est := MaterialEstimate{
+		RoomName:              room.Name,
+		LengthFt:              room.LengthFt,
+		WidthFt:               room.WidthFt,
+		HeightFt:              room.HeightFt,
+		Doors:                 room.Doors,
+		Windows:               room.Windows,
+		Coats:                 in.Coats,
+		CoverageSqFtPerGallon: in.CoverageSqFtPerGallon,
+		WasteFraction:         in.WasteFraction,
+		TileWidthIn:           in.TileWidthIn,
+		TileLengthIn:          in.TileLengthIn,
+		WallAreaSqFt:          round2(wall),
+		FloorAreaSqFt:         round2(floor),
+		PaintGallons:          round2(gallons),
+		PaintGallonsToBuy:     int(ceilQty(gallons)),
+		FlooringSqFt:          ceilQty(withWaste),
+	}
+	if in.TileWidthIn > 0 && in.TileLengthIn > 0 {
+		tileSqFt := in.TileWidthIn * in.TileLengthIn / 144
+		est.TileCount = int(ceilQty(withWaste / tileSqFt))
+	}
+	if room.ID != 0 {
+		id := room.ID
+		est.RoomID = &id
+	}
+	return est, nil
+}
+
+// ListMaterialEstimates returns the estimates saved on a project, newest
+// first.
+func (s *Store) ListMaterialEstimates(projectID uint, includeDeleted bool) ([]MaterialEstimate, error) {
+	var items []MaterialEstimate
+	db := s.db.
+		Where(ColProjectID+" = ?", projectID).
+		Order(ColCreatedAt + " desc, " + ColID + " desc")
+	if includeDeleted {
+		db = db.Unscoped()
+	}
+	return items, db.Find(&items).Error
+}
+
+func (s *Store) GetMaterialEstimate(id uint) (MaterialEstimate, error) {
+	var item MaterialEstimate
+	err := s.db.First(&item, id).Error
+	return item, err
+}
+
+// SaveMaterialEstimate computes an estimate for the room and stores it on
+// the project.
+func (s *Store) SaveMaterialEstimate(
+	projectID, roomID uint,
+	in EstimateInputs,
+	notes string,
+) (MaterialEstimate, error) {
+	if err := s.requireParentAlive(&Project{}, projectID); err != nil {
+		return MaterialEstimate{}, fmt.Errorf("project not found or deleted")
+	}
+	room, err := s.GetRoom(roomID)
+	if err != nil {
+		return MaterialEstimate{}, fmt.Errorf("room not found or deleted")
+	}
+	est, err := EstimateMaterials(room, in)
+	if err != nil {
+		return MaterialEstimate{}, err
+	}
+	est.ProjectID = projectID
+	est.Notes = notes
+	if err := s.db.Create(&est).Error; err != nil {
+		return MaterialEstimate{}, err
+	}
+	return est, nil
+}
+
+func (s *Store) DeleteMaterialEstimate(id uint) error {
+	return s.softDelete(&MaterialEstimate{}, DeletionEntityEstimate, id)
+}
+
+func (s *Store) RestoreMaterialEstimate(id uint) error {
+	var item MaterialEstimate
+	if err := s.db.Unscoped().First(&item, id).Error; err != nil {
+		return err
+	}
+	if err := s.requireParentAlive(&Project{}, item.ProjectID); err != nil {
+		return parentRestoreError("project", err)
+	}
+	return s.restoreEntity(&MaterialEstimate{}, DeletionEntityEstimate, id)
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// ceilQty rounds a purchase quantity up, ignoring floating-point noise so
+// that 132.00000000000003 square feet doesn't become 133.
+func ceilQty(v float64) float64 {
+	return math.Ceil(v - 1e-9)
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoomAreas(t *testing.T) {
+	room := Room{LengthFt: 12, WidthFt: 10, HeightFt: 8, Doors: 1, Windows: 2}
+	assert.InDelta(t, 120.0, room.FloorAreaSqFt(), 1e-9)
+	// 2*(12+10)*8 = 352, less 21 for the door and 30 for the windows.
+	assert.InDelta(t, 301.0, room.WallAreaSqFt(), 1e-9)
+
+	tiny := Room{LengthFt: 2, WidthFt: 2, HeightFt: 2, Doors: 2}
+	assert.Zero(t, tiny.WallAreaSqFt(), "openings can't make area negative")
+}
+
+func TestEstimateMaterialsDefaults(t *testing.T) {
+	room := Room{Name: "Bedroom", LengthFt: 12, WidthFt: 10, HeightFt: 8, Doors: 1, Windows: 2}
+	est, err := EstimateMaterials(room, EstimateInputs{TileWidthIn: 12, TileLengthIn: 12})
+	require.NoError(t, err)
+
+	assert.Equal(t, DefaultPaintCoats, est.Coats)
+	// 301 sq ft * 2 coats / 350 = 1.72 gal
+	assert.InDelta(t, 1.72, est.PaintGallons, 1e-9)
+	assert.Equal(t, 2, est.PaintGallonsToBuy)
+	// 120 sq ft + 10% waste = 132, exactly -- no spurious round-up.
+	assert.InDelta(t, 132.0, est.FlooringSqFt, 1e-9)
+	assert.Equal(t, 132, est.TileCount)
+	assert.Equal(t, "Bedroom", est.RoomName)
+}
+
+func TestEstimateMaterialsCustomInputs(t *testing.T) {
+	room := Room{LengthFt: 10, WidthFt: 8, HeightFt: 9}
+	est, err := EstimateMaterials(room, EstimateInputs{
+		Coats: 1, CoverageSqFtPerGallon: 400, WasteFraction: 0.15,
+		TileWidthIn: 12, TileLengthIn: 24,
+	})
+	require.NoError(t, err)
+	// 2*(10+8)*9 = 324 sq ft / 400 = 0.81 gal
+	assert.InDelta(t, 0.81, est.PaintGallons, 1e-9)
+	assert.Equal(t, 1, est.PaintGallonsToBuy)
+	// 80 * 1.15 = 92 sq ft; 2 sq ft tiles -> 46
+	assert.InDelta(t, 92.0, est.FlooringSqFt, 1e-9)
+	assert.Equal(t, 46, est.TileCount)
+
+	noTile, err := EstimateMaterials(room, EstimateInputs{})
+	require.NoError(t, err)
+	assert.Zero(t, noTile.TileCount)
+
+	_, err = EstimateMaterials(room, EstimateInputs{Coats: -1})
+	assert.Error(t, err)
+}
+
+func TestSaveMaterialEstimateOnProject(t *testing.T) {
+	store := newTestStore(t)
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	project := Project{Title: "Repaint", ProjectTypeID: types[0].ID, Status: ProjectStatusPlanned}
+	require.NoError(t, store.CreateProject(&project))
+	room := Room{Name: "Den", LengthFt: 14, WidthFt: 12, HeightFt: 8}
+	require.NoError(t, store.CreateRoom(&room))
+
+	est, err := store.SaveMaterialEstimate(project.ID, room.ID, EstimateInputs{}, "eggshell")
+	require.NoError(t, err)
+	require.NotNil(t, est.RoomID)
+
+	// Editing the room doesn't rewrite the saved snapshot.
+	room.LengthFt = 20
+	require.NoError(t, store.UpdateRoom(room))
+	saved, err := store.ListMaterialEstimates(project.ID, false)
+	require.NoError(t, err)
+	require.Len(t, saved, 1)
+	assert.InDelta(t, 14.0, saved[0].LengthFt, 1e-9)
+	assert.Equal(t, "eggshell", saved[0].Notes)
+
+	require.ErrorContains(t, store.DeleteProject(project.ID), "material estimate")
+	require.NoError(t, store.DeleteMaterialEstimate(est.ID))
+	require.NoError(t, store.DeleteProject(project.ID))
+	require.ErrorContains(t, store.RestoreMaterialEstimate(est.ID), "project is deleted")
+
+	_, err = store.SaveMaterialEstimate(project.ID, room.ID, EstimateInputs{}, "")
+	assert.Error(t, err)
+}
+
+func TestRoomValidation(t *testing.T) {
+	store := newTestStore(t)
+	assert.Error(t, store.CreateRoom(&Room{LengthFt: 10}))
+	assert.Error(t, store.CreateRoom(&Room{Name: "X", LengthFt: -1}))
+	assert.Error(t, store.CreateRoom(&Room{Name: "X", Doors: -1}))
+}
@@ -0,0 +1,241 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Expense is money spent on the house: a bill, a part, a deposit on a
+// quote, a service visit. It can say what it was for -- a project, an
+// appliance, a maintenance item -- and what it pays for: a quote, or a
+// service log entry, whose own cost it then stands in for. ReceiptID is
+// the document holding the receipt.
+type Expense struct {
+	ID                uint      `gorm:"primaryKey"`
+	HouseID           *uint     `gorm:"index"`
+	SpentOn           time.Time `gorm:"index"`
+	AmountCents       int64
+	Category          string
+	Description       string
+	ProjectID         *uint           `gorm:"index"`
+	Project           Project         `gorm:"constraint:OnDelete:SET NULL;"`
+	ApplianceID       *uint           `gorm:"index"`
+	Appliance         Appliance       `gorm:"constraint:OnDelete:SET NULL;"`
+	MaintenanceItemID *uint           `gorm:"index"`
+	MaintenanceItem   MaintenanceItem `gorm:"constraint:OnDelete:SET NULL;"`
+	QuoteID           *uint           `gorm:"index"`
+	Quote             Quote           `gorm:"constraint:OnDelete:SET NULL;"`
+	ServiceLogEntryID *uint           `gorm:"index"`
+	ServiceLogEntry   ServiceLogEntry `gorm:"constraint:OnDelete:SET NULL;"`
+	VendorID          *uint           `gorm:"index"`
+	Vendor            Vendor          `gorm:"constraint:OnDelete:SET NULL;"`
+	ReceiptID         *uint
+	Notes             string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	Version           int            `gorm:"not null;default:1"`
+	DeletedAt         gorm.DeletedAt `gorm:"index"`
+}
+
+// SpendTotal is what was spent in one category over one period, "2026"
+// or "2026-03": the gross cost and the part of it paid out of pocket.
+type SpendTotal struct {
+	Period           string
+	Category         string
+	AmountCents      int64
+	OutOfPocketCents int64
+}
+
+// OwnershipCost is what one appliance cost over a period: buying it, and
+// keeping it running -- service, filters, repairs and expenses.
+type OwnershipCost struct {
+	ApplianceID   uint
+	Name          string
+	PurchaseCents int64
+	UpkeepCents   int64
+	TotalCents    int64
+}
+
+func preloadExpense(db *gorm.DB) *gorm.DB {
+	unscoped := func(q *gorm.DB) *gorm.DB { return q.Unscoped() }
+	return db.
+		Preload("Project", unscoped).
+		Preload("Appliance", unscoped).
+		Preload("MaintenanceItem", unscoped).
+		Preload("Quote", unscoped).
+		Preload("ServiceLogEntry", unscoped).
+		Preload("Vendor", unscoped)
+}
+
+// ListExpenses returns the current house's expenses, newest first.
+func (s *Store) ListExpenses(includeDeleted bool) ([]Expense, error) {
+	var items []Expense
+	db := preloadExpense(s.db).Scopes(s.inHouse("expenses")).
+		Order("spent_on desc, " + ColID + " desc")
+	if includeDeleted {
+		db = db.Unscoped()
+	}
+	return items, db.Find(&items).Error
+}
+
+func (s *Store) GetExpense(id uint) (Expense, error) {
+	var item Expense
+	err := preloadExpense(s.db).First(&item, id).Error
+	return item, err
+}
+
+func (s *Store) CreateExpense(item *Expense) error {
+	if err := s.fillExpense(item); err != nil {
+		return err
+	}
+	return s.db.Create(item).Error
+}
+
+func (s *Store) UpdateExpense(item Expense) error {
+	if err := s.fillExpense(&item); err != nil {
+		return err
+	}
+	return s.updateByID(&Expense{}, item.ID, item)
+}
+
+func (s *Store) DeleteExpense(id uint) error {
+	return s.softDelete(&Expense{}, DeletionEntityExpense, id)
+}
+
+func (s *Store) RestoreExpense(id uint) error {
+	return s.restoreEntity(&Expense{}, DeletionEntityExpense, id)
+}
+
+// ExpenseCategories lists the categories expenses have been filed under,
+// for suggestions.
+func (s *Store) ExpenseCategories() ([]string, error) {
+	var cats []string
+	err := s.db.Model(&Expense{}).Scopes(s.inHouse("expenses")).
+		Where("category <> ''").
+		Distinct("category").Order("category").
+		Pluck("category", &cats).Error
+	return cats, err
+}
+
+// fillExpense validates an expense and fills in what its links imply: a
+// quote's project and vendor, and a service log entry's maintenance item
+// and vendor, unless they were given.
+func (s *Store) fillExpense(e *Expense) error {
+	e.Category = strings.TrimSpace(e.Category)
+	e.Description = strings.TrimSpace(e.Description)
+	switch {
+	case e.SpentOn.IsZero():
+		return fmt.Errorf("an expense needs a date")
+	case e.AmountCents <= 0:
+		return fmt.Errorf("an expense needs an amount above zero")
+	case e.Description == "":
+		return fmt.Errorf("an expense needs a description")
+	}
+	if e.QuoteID != nil {
+		var q Quote
+		if err := s.db.First(&q, *e.QuoteID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("quote #%d doesn't exist", *e.QuoteID)
+		} else if err != nil {
+			return err
+		}
+		e.ProjectID = cmp.Or(e.ProjectID, &q.ProjectID)
+		e.VendorID = cmp.Or(e.VendorID, &q.VendorID)
+	}
+	if e.ServiceLogEntryID != nil {
+		var entry ServiceLogEntry
+		if err := s.db.First(&entry, *e.ServiceLogEntryID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("service log entry #%d doesn't exist", *e.ServiceLogEntryID)
+		} else if err != nil {
+			return err
+		}
+		e.MaintenanceItemID = cmp.Or(e.MaintenanceItemID, &entry.MaintenanceItemID)
+		e.VendorID = cmp.Or(e.VendorID, entry.VendorID)
+	}
+	return nil
+}
+
+// RollUpSpending totals entries by category for each calendar month, or
+// each year, in their own time zone. Periods come oldest first and
+// categories alphabetically within them.
+func RollUpSpending(entries []SpendEntry, monthly bool) []SpendTotal {
+	type key struct{ period, category string }
+	sums := map[key]*SpendTotal{}
+	for _, e := range entries {
+		period := strconv.Itoa(e.Date.Year())
+		if monthly {
+			period = e.Date.Format("2006-01")
+		}
+		k := key{period, e.Category}
+		if sums[k] == nil {
+			sums[k] = &SpendTotal{Period: period, Category: e.Category}
+		}
+		sums[k].AmountCents += e.AmountCents
+		sums[k].OutOfPocketCents += e.OutOfPocketCents()
+	}
+	out := make([]SpendTotal, 0, len(sums))
+	for _, t := range sums {
+		out = append(out, *t)
+	}
+	slices.SortFunc(out, func(a, b SpendTotal) int {
+		return cmp.Or(cmp.Compare(a.Period, b.Period), cmp.Compare(a.Category, b.Category))
+	})
+	return out
+}
+
+// CostOfOwnership totals what each appliance cost in [since, until):
+// its purchase if it fell then, and everything spent keeping it --
+// service on maintenance items for it, water filters, incidents and
+// expenses. Appliances that cost nothing are left out; the costliest come
+// first.
+func (s *Store) CostOfOwnership(since, until time.Time) ([]OwnershipCost, error) {
+	entries, err := s.ListSpending(since, until)
+	if err != nil {
+		return nil, err
+	}
+	byID := map[uint]*OwnershipCost{}
+	var ids []uint
+	for _, e := range entries {
+		if e.ApplianceID == nil {
+			continue
+		}
+		c := byID[*e.ApplianceID]
+		if c == nil {
+			c = &OwnershipCost{ApplianceID: *e.ApplianceID}
+			byID[*e.ApplianceID] = c
+			ids = append(ids, *e.ApplianceID)
+		}
+		if e.Category == SpendAppliance {
+			c.PurchaseCents += e.AmountCents
+		} else {
+			c.UpkeepCents += e.AmountCents
+		}
+		c.TotalCents += e.AmountCents
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var appliances []Appliance
+	if err := s.db.Unscoped().Scopes(s.inHouse("appliances")).Where(ids).Find(&appliances).Error; err != nil {
+		return nil, err
+	}
+	out := make([]OwnershipCost, 0, len(appliances))
+	for _, a := range appliances {
+		c := *byID[a.ID]
+		c.Name = a.Name
+		out = append(out, c)
+	}
+	slices.SortFunc(out, func(a, b OwnershipCost) int {
+		return cmp.Or(cmp.Compare(b.TotalCents, a.TotalCents), cmp.Compare(a.Name, b.Name))
+	})
+	return out, nil
+}
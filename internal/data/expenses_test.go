@@ -0,0 +1,115 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpenses(t *testing.T) {
+	store := newTestStore(t)
+	require.NoError(t, store.CreateHouseProfile(HouseProfile{Nickname: "Elm Street"}))
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 12, 0, 0, 0, time.UTC) }
+	cents := func(c int64) *int64 { return &c }
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	require.ErrorContains(t, store.CreateExpense(&Expense{AmountCents: 100, Description: "Salt"}), "date")
+	require.ErrorContains(t, store.CreateExpense(&Expense{SpentOn: day(1, 1), Description: "Salt"}), "amount")
+	require.ErrorContains(t, store.CreateExpense(&Expense{SpentOn: day(1, 1), AmountCents: 100}), "description")
+
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	project := Project{Title: "Deck", ProjectTypeID: types[0].ID, Status: ProjectStatusInProgress}
+	require.NoError(t, store.CreateProject(&project))
+	quote := Quote{ProjectID: project.ID, TotalCents: 900_000}
+	require.NoError(t, store.CreateQuote(&quote, Vendor{Name: "Deck Builders"}))
+	furnace := Appliance{Name: "Furnace", PurchaseDate: ptr(day(2, 1)), CostCents: cents(400_000)}
+	require.NoError(t, store.CreateAppliance(&furnace))
+	cats, err := store.MaintenanceCategories()
+	require.NoError(t, err)
+	tuneUp := MaintenanceItem{Name: "Furnace tune-up", CategoryID: cats[0].ID, ApplianceID: &furnace.ID}
+	require.NoError(t, store.CreateMaintenance(&tuneUp))
+	visit := ServiceLogEntry{MaintenanceItemID: tuneUp.ID, ServicedAt: day(3, 10), CostCents: cents(15_000)}
+	require.NoError(t, store.CreateServiceLog(&visit, Vendor{Name: "HVAC Pros"}))
+
+	// A deposit on the quote is filed under its project and vendor.
+	deposit := Expense{
+		SpentOn: day(3, 2), AmountCents: 300_000, Category: " Deposits ", Description: "Deck deposit",
+		QuoteID: &quote.ID,
+	}
+	require.NoError(t, store.CreateExpense(&deposit))
+	got, err := store.GetExpense(deposit.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Deposits", got.Category)
+	require.NotNil(t, got.ProjectID)
+	assert.Equal(t, project.ID, *got.ProjectID)
+	assert.Equal(t, "Deck Builders", got.Vendor.Name)
+	require.NotNil(t, got.HouseID, "filed under the current house")
+
+	// Paying for the service visit stands in for its cost.
+	paid := Expense{
+		SpentOn: day(3, 12), AmountCents: 16_500, Category: "HVAC", Description: "Tune-up and a filter",
+		ServiceLogEntryID: &visit.ID,
+	}
+	require.NoError(t, store.CreateExpense(&paid))
+	got, err = store.GetExpense(paid.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.MaintenanceItemID)
+	assert.Equal(t, tuneUp.ID, *got.MaintenanceItemID)
+	assert.Equal(t, "HVAC Pros", got.Vendor.Name)
+	require.NoError(t, store.CreateExpense(&Expense{
+		SpentOn: day(11, 20), AmountCents: 2_000, Description: "Filters", ApplianceID: &furnace.ID,
+	}))
+
+	entries, err := store.ListSpending(day(1, 1), day(12, 31))
+	require.NoError(t, err)
+	var total int64
+	for _, e := range entries {
+		total += e.AmountCents
+		assert.NotEqual(t, SpendMaintenance, e.Category, "the visit counts as the expense")
+	}
+	assert.Equal(t, int64(400_000+300_000+16_500+2_000), total)
+
+	assert.Equal(t, []SpendTotal{
+		{Period: "2026-02", Category: SpendAppliance, AmountCents: 400_000, OutOfPocketCents: 400_000},
+		{Period: "2026-03", Category: "Deposits", AmountCents: 300_000, OutOfPocketCents: 300_000},
+		{Period: "2026-03", Category: "HVAC", AmountCents: 16_500, OutOfPocketCents: 16_500},
+		{Period: "2026-11", Category: SpendExpense, AmountCents: 2_000, OutOfPocketCents: 2_000},
+	}, RollUpSpending(entries, true))
+	yearly := RollUpSpending(entries, false)
+	require.Len(t, yearly, 4)
+	assert.Equal(t, "2026", yearly[0].Period)
+
+	owned, err := store.CostOfOwnership(day(1, 1), day(12, 31))
+	require.NoError(t, err)
+	assert.Equal(t, []OwnershipCost{{
+		ApplianceID: furnace.ID, Name: "Furnace",
+		PurchaseCents: 400_000, UpkeepCents: 18_500, TotalCents: 418_500,
+	}}, owned)
+
+	categories, err := store.ExpenseCategories()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Deposits", "HVAC"}, categories)
+
+	// A deleted expense no longer counts, and the visit's own cost is back.
+	require.NoError(t, store.DeleteExpense(paid.ID))
+	items, err := store.ListExpenses(false)
+	require.NoError(t, err)
+	assert.Len(t, items, 2)
+	entries, err = store.ListSpending(day(3, 1), day(4, 1))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, SpendMaintenance, entries[1].Category)
+	require.NoError(t, store.RestoreExpense(paid.ID))
+
+	// Another house has its own expenses.
+	require.NoError(t, store.AddHouse(&HouseProfile{Nickname: "Lake cabin"}))
+	items, err = store.ListExpenses(false)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
@@ -0,0 +1,351 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ChangeOrder changes what a project's contract is worth. Once approved,
+// AmountCents (negative for a credit) is added to the project's budget.
+// DocumentID optionally points at the signed change order.
+type ChangeOrder struct {
+	ID          uint    `gorm:"primaryKey"`
+	ProjectID   uint    `gorm:"index"`
+	Project     Project `gorm:"constraint:OnDelete:CASCADE;"`
+	AmountCents int64
+	Reason      string
+	ApprovedOn  *time.Time
+	DocumentID  *uint
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Version     int `gorm:"not null;default:1"`
+}
+
+// Invoice is a bill against a project. RetainageCents is the part of
+// AmountCents held back until the work is accepted; PaidOn is when the
+// rest was paid, and RetainageReleasedOn when the held part was.
+// InsuranceCents, WarrantyCents and RebateCents are the parts of
+// AmountCents an insurer, a warranty and a rebate paid.
+type Invoice struct {
+	ID                  uint    `gorm:"primaryKey"`
+	ProjectID           uint    `gorm:"index"`
+	Project             Project `gorm:"constraint:OnDelete:CASCADE;"`
+	VendorID            *uint   `gorm:"index"`
+	Vendor              Vendor  `gorm:"constraint:OnDelete:SET NULL;"`
+	Number              string
+	InvoicedOn          time.Time
+	AmountCents         int64
+	RetainageCents      int64
+	PaidOn              *time.Time
+	RetainageReleasedOn *time.Time
+	InsuranceCents      int64
+	WarrantyCents       int64
+	RebateCents         int64
+	DocumentID          *uint
+	Notes               string
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Version             int `gorm:"not null;default:1"`
+}
+
+// ProjectFinancials is where a project's money stands against its
+// contract.
+type ProjectFinancials struct {
+	// BudgetCents is the project's own budget, before change orders.
+	BudgetCents *int64
+	// ApprovedChangeCents and PendingChangeCents sum the change orders.
+	ApprovedChangeCents int64
+	PendingChangeCents  int64
+	// EffectiveBudgetCents is the budget with approved change orders; nil
+	// when there is neither.
+	EffectiveBudgetCents *int64
+	InvoicedCents        int64
+	// PaidCents counts paid invoices less their retainage, plus released
+	// retainage.
+	PaidCents int64
+	// DueCents is what unpaid invoices ask for now, retainage aside.
+	DueCents int64
+	// RetainageHeldCents is retainage not yet released.
+	RetainageHeldCents int64
+	// RemainingCents is what's left of the effective budget to invoice.
+	RemainingCents *int64
+}
+
+func validateChangeOrder(co ChangeOrder) error {
+	if strings.TrimSpace(co.Reason) == "" {
+		return fmt.Errorf("a change order needs a reason")
+	}
+	if co.AmountCents == 0 {
+		return fmt.Errorf("a change order needs an amount")
+	}
+	return nil
+}
+
+func validateInvoice(inv Invoice) error {
+	switch {
+	case inv.InvoicedOn.IsZero():
+		return fmt.Errorf("an invoice needs a date")
+	case inv.AmountCents <= 0:
+		return fmt.Errorf("an invoice needs an amount above zero")
+	case inv.RetainageCents < 0 || inv.RetainageCents > inv.AmountCents:
+		return fmt.Errorf("retainage must be between zero and the invoice amount")
+	case inv.RetainageReleasedOn != nil && inv.RetainageCents == 0:
+		return fmt.Errorf("there is no retainage to release")
+	}
+	return inv.Funding().validate(inv.AmountCents)
+}
+
+// ListChangeOrders returns a project's change orders, oldest first.
+func (s *Store) ListChangeOrders(projectID uint) ([]ChangeOrder, error) {
+	var items []ChangeOrder
+	err := s.db.Where(ColProjectID+" = ?", projectID).
+		Order(ColCreatedAt + ", " + ColID).
+		Find(&items).Error
+	return items, err
+}
+
+// ListPendingChangeOrders returns the change orders of the current house's
+// live projects still awaiting approval, oldest first, with their
+// projects.
+func (s *Store) ListPendingChangeOrders() ([]ChangeOrder, error) {
+	var items []ChangeOrder
+	err := s.db.Preload("Project").
+		Joins("JOIN projects ON projects.id = change_orders.project_id AND projects.deleted_at IS NULL").
+		Scopes(s.inHouse("projects")).
+		Where("change_orders.approved_on IS NULL").
+		Order("change_orders." + ColCreatedAt + ", change_orders." + ColID).
+		Find(&items).Error
+	return items, err
+}
+
+func (s *Store) GetChangeOrder(id uint) (ChangeOrder, error) {
+	var item ChangeOrder
+	err := s.db.First(&item, id).Error
+	return item, err
+}
+
+func (s *Store) CreateChangeOrder(co *ChangeOrder) error {
+	if err := validateChangeOrder(*co); err != nil {
+		return err
+	}
+	if err := s.requireParentAlive(&Project{}, co.ProjectID); err != nil {
+		return parentRestoreError("project", err)
+	}
+	return s.db.Omit("Project").Create(co).Error
+}
+
+// UpdateChangeOrder saves a change order. It stays on its project.
+func (s *Store) UpdateChangeOrder(co ChangeOrder) error {
+	if err := validateChangeOrder(co); err != nil {
+		return err
+	}
+	var existing ChangeOrder
+	if err := s.db.First(&existing, co.ID).Error; err != nil {
+		return err
+	}
+	co.ProjectID = existing.ProjectID
+	return s.updateByID(&ChangeOrder{}, co.ID, co)
+}
+
+// ApproveChangeOrder marks a pending change order approved on the given
+// day. One already approved keeps its date.
+func (s *Store) ApproveChangeOrder(id uint, on time.Time) (ChangeOrder, error) {
+	co, err := s.GetChangeOrder(id)
+	if err != nil || co.ApprovedOn != nil {
+		return co, err
+	}
+	co.ApprovedOn = &on
+	if err := s.UpdateChangeOrder(co); err != nil {
+		return co, err
+	}
+	return s.GetChangeOrder(id)
+}
+
+func (s *Store) RemoveChangeOrder(id uint) error {
+	result := s.db.Delete(&ChangeOrder{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListInvoices returns a project's invoices by date.
+func (s *Store) ListInvoices(projectID uint) ([]Invoice, error) {
+	var items []Invoice
+	err := s.db.Where(ColProjectID+" = ?", projectID).
+		Preload("Vendor", func(q *gorm.DB) *gorm.DB { return q.Unscoped() }).
+		Order(ColInvoicedOn + ", " + ColID).
+		Find(&items).Error
+	return items, err
+}
+
+func (s *Store) GetInvoice(id uint) (Invoice, error) {
+	var item Invoice
+	err := s.db.Preload("Vendor", func(q *gorm.DB) *gorm.DB { return q.Unscoped() }).
+		First(&item, id).Error
+	return item, err
+}
+
+func (s *Store) CreateInvoice(inv *Invoice) error {
+	if err := validateInvoice(*inv); err != nil {
+		return err
+	}
+	if err := s.requireParentAlive(&Project{}, inv.ProjectID); err != nil {
+		return parentRestoreError("project", err)
+	}
+	return s.db.Omit("Project", "Vendor").Create(inv).Error
+}
+
+// UpdateInvoice saves an invoice. It stays on its project.
+func (s *Store) UpdateInvoice(inv Invoice) error {
+	if err := validateInvoice(inv); err != nil {
+		return err
+	}
+	var existing Invoice
+	if err := s.db.First(&existing, inv.ID).Error; err != nil {
+		return err
+	}
+	inv.ProjectID = existing.ProjectID
+	return s.updateByID(&Invoice{}, inv.ID, inv)
+}
+
+func (s *Store) RemoveInvoice(id uint) error {
+	result := s.db.Delete(&Invoice{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ProjectFinancials works out where a project's money stands from its
+// budget, change orders and invoices.
+func (s *Store) ProjectFinancials(projectID uint) (ProjectFinancials, error) {
+	var project Project
+	if err := s.db.First(&project, projectID).Error; err != nil {
+		return ProjectFinancials{}, err
+	}
+	changes, err := s.ListChangeOrders(projectID)
+	if err != nil {
+		return ProjectFinancials{}, err
+	}
+	invoices, err := s.ListInvoices(projectID)
+	if err != nil {
+		return ProjectFinancials{}, err
+	}
+
+	f := ProjectFinancials{BudgetCents: project.BudgetCents}
+	approved := false
+	for _, co := range changes {
+		if co.ApprovedOn != nil {
+			f.ApprovedChangeCents += co.AmountCents
+			approved = true
+		} else {
+			f.PendingChangeCents += co.AmountCents
+		}
+	}
+	for _, inv := range invoices {
+		f.InvoicedCents += inv.AmountCents
+		if inv.PaidOn != nil {
+			f.PaidCents += inv.AmountCents - inv.RetainageCents
+		} else {
+			f.DueCents += inv.AmountCents - inv.RetainageCents
+		}
+		if inv.RetainageReleasedOn != nil {
+			f.PaidCents += inv.RetainageCents
+		} else {
+			f.RetainageHeldCents += inv.RetainageCents
+		}
+	}
+	if project.BudgetCents != nil || approved {
+		effective := f.ApprovedChangeCents
+		if project.BudgetCents != nil {
+			effective += *project.BudgetCents
+		}
+		remaining := effective - f.InvoicedCents
+		f.EffectiveBudgetCents, f.RemainingCents = &effective, &remaining
+	}
+	return f, nil
+}
+
+// BudgetRevision is one value a project's budget or actual cost took on.
+// Field is ColBudgetCents or ColActualCents. The first revision of each
+// has no FromCents: it's the value the project had when it was created,
+// as far back as the field history goes.
+type BudgetRevision struct {
+	At        time.Time
+	Field     string
+	FromCents *int64
+	ToCents   *int64
+}
+
+// BudgetRevisions returns how a project's budget and actual cost changed
+// over time, oldest first, read from the field history.
+func (s *Store) BudgetRevisions(projectID uint) ([]BudgetRevision, error) {
+	var project Project
+	if err := s.db.First(&project, projectID).Error; err != nil {
+		return nil, err
+	}
+	var changes []FieldChange
+	err := s.db.Where(ColEntity+" = ? AND "+ColTargetID+" = ? AND "+ColField+" IN ?",
+		"projects", projectID, []string{ColBudgetCents, ColActualCents}).
+		Order(ColChangedAt + ", " + ColID).
+		Find(&changes).Error
+	if err != nil {
+		return nil, err
+	}
+	decode := func(c FieldChange, raw string) (*int64, error) {
+		var v *int64
+		if err := json.Unmarshal([]byte(raw), &v); err != nil {
+			return nil, fmt.Errorf("decode %s change %d: %w", c.Field, c.ID, err)
+		}
+		return v, nil
+	}
+
+	var revs []BudgetRevision
+	for field, current := range map[string]*int64{
+		ColBudgetCents: project.BudgetCents, ColActualCents: project.ActualCents,
+	} {
+		initial := current
+		if i := slices.IndexFunc(changes, func(c FieldChange) bool { return c.Field == field }); i >= 0 {
+			if initial, err = decode(changes[i], changes[i].OldValue); err != nil {
+				return nil, err
+			}
+		}
+		if initial != nil {
+			revs = append(revs, BudgetRevision{At: project.CreatedAt, Field: field, ToCents: initial})
+		}
+	}
+	for _, c := range changes {
+		from, err := decode(c, c.OldValue)
+		if err != nil {
+			return nil, err
+		}
+		to, err := decode(c, c.NewValue)
+		if err != nil {
+			return nil, err
+		}
+		revs = append(revs, BudgetRevision{At: c.ChangedAt, Field: c.Field, FromCents: from, ToCents: to})
+	}
+	slices.SortStableFunc(revs, func(a, b BudgetRevision) int {
+		if c := a.At.Compare(b.At); c != 0 {
+			return c
+		}
+		// The budget before the actual cost when both start together.
+		return strings.Compare(b.Field, a.Field)
+	})
+	return revs, nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectFinancials(t *testing.T) {
+	store := newTestStore(t)
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	budget := int64(4_000_000)
+	project := Project{
+		Title: "Addition", ProjectTypeID: types[0].ID, Status: ProjectStatusInProgress, BudgetCents: &budget,
+	}
+	require.NoError(t, store.CreateProject(&project))
+	day := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	require.Error(t, store.CreateChangeOrder(&ChangeOrder{ProjectID: project.ID, AmountCents: 100}))
+	require.Error(t, store.CreateChangeOrder(&ChangeOrder{ProjectID: project.ID, Reason: "Nothing"}))
+	extra := ChangeOrder{ProjectID: project.ID, AmountCents: 250_000, Reason: "Extra window", ApprovedOn: &day}
+	require.NoError(t, store.CreateChangeOrder(&extra))
+	credit := ChangeOrder{ProjectID: project.ID, AmountCents: -50_000, Reason: "Owner supplies tile"}
+	require.NoError(t, store.CreateChangeOrder(&credit))
+	pending, err := store.ListPendingChangeOrders()
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "Addition", pending[0].Project.Title)
+
+	require.Error(t, store.CreateInvoice(&Invoice{
+		ProjectID: project.ID, InvoicedOn: day, AmountCents: 100, RetainageCents: 200,
+	}), "retainage can't exceed the invoice")
+	first := Invoice{ProjectID: project.ID, InvoicedOn: day, AmountCents: 1_000_000, RetainageCents: 100_000}
+	require.NoError(t, store.CreateInvoice(&first))
+	second := Invoice{
+		ProjectID: project.ID, InvoicedOn: day.AddDate(0, 1, 0), AmountCents: 500_000, RetainageCents: 50_000,
+	}
+	require.NoError(t, store.CreateInvoice(&second))
+
+	// The first invoice is paid, less its retainage.
+	first.PaidOn = &day
+	require.NoError(t, store.UpdateInvoice(first))
+
+	f, err := store.ProjectFinancials(project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(250_000), f.ApprovedChangeCents)
+	assert.Equal(t, int64(-50_000), f.PendingChangeCents)
+	require.NotNil(t, f.EffectiveBudgetCents)
+	assert.Equal(t, int64(4_250_000), *f.EffectiveBudgetCents)
+	assert.Equal(t, int64(1_500_000), f.InvoicedCents)
+	assert.Equal(t, int64(900_000), f.PaidCents)
+	assert.Equal(t, int64(450_000), f.DueCents)
+	assert.Equal(t, int64(150_000), f.RetainageHeldCents)
+	require.NotNil(t, f.RemainingCents)
+	assert.Equal(t, int64(2_750_000), *f.RemainingCents)
+
+	// Approving the credit lowers the budget; releasing retainage pays it.
+	credit.ApprovedOn = &day
+	require.NoError(t, store.UpdateChangeOrder(credit))
+	invoices, err := store.ListInvoices(project.ID)
+	require.NoError(t, err)
+	first = invoices[0]
+	require.NotNil(t, first.PaidOn)
+	first.RetainageReleasedOn = &day
+	require.NoError(t, store.UpdateInvoice(first))
+	f, err = store.ProjectFinancials(project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4_200_000), *f.EffectiveBudgetCents)
+	assert.Equal(t, int64(1_000_000), f.PaidCents)
+	assert.Equal(t, int64(50_000), f.RetainageHeldCents)
+
+	require.NoError(t, store.RemoveChangeOrder(extra.ID))
+	require.NoError(t, store.RemoveInvoice(second.ID))
+	require.Error(t, store.RemoveInvoice(second.ID))
+	items, err := store.ListChangeOrders(project.ID)
+	require.NoError(t, err)
+	assert.Len(t, items, 1)
+}
+
+func TestBudgetRevisions(t *testing.T) {
+	store := newTestStore(t)
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	budget := int64(3_000_000)
+	project := Project{Title: "Kitchen", ProjectTypeID: types[0].ID, Status: ProjectStatusPlanned, BudgetCents: &budget}
+	require.NoError(t, store.CreateProject(&project))
+
+	revs, err := store.BudgetRevisions(project.ID)
+	require.NoError(t, err)
+	require.Len(t, revs, 1, "the budget it was created with")
+	assert.Equal(t, ColBudgetCents, revs[0].Field)
+	assert.Nil(t, revs[0].FromCents)
+	assert.Equal(t, budget, *revs[0].ToCents)
+
+	// The budget grows, then the actual cost comes in; a title change
+	// isn't a revision.
+	project, err = store.GetProject(project.ID)
+	require.NoError(t, err)
+	more := int64(3_400_000)
+	project.BudgetCents = &more
+	project.Title = "Kitchen remodel"
+	require.NoError(t, store.UpdateProject(project))
+	project, err = store.GetProject(project.ID)
+	require.NoError(t, err)
+	actual := int64(3_650_000)
+	project.ActualCents = &actual
+	require.NoError(t, store.UpdateProject(project))
+
+	revs, err = store.BudgetRevisions(project.ID)
+	require.NoError(t, err)
+	require.Len(t, revs, 3)
+	assert.Equal(t, budget, *revs[1].FromCents)
+	assert.Equal(t, more, *revs[1].ToCents)
+	assert.Equal(t, ColActualCents, revs[2].Field)
+	assert.Nil(t, revs[2].FromCents)
+	assert.Equal(t, actual, *revs[2].ToCents)
+	assert.False(t, revs[2].At.Before(revs[1].At))
+
+	_, err = store.BudgetRevisions(project.ID + 100)
+	require.Error(t, err)
+}
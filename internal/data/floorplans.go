@@ -0,0 +1,170 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FloorPlan is an uploaded floor plan image with clickable room regions.
+// The image bytes are never serialized; fetch them through the image
+// endpoint instead.
+type FloorPlan struct {
+	ID            uint  `gorm:"primaryKey"`
+	HouseID       *uint `gorm:"index"`
+	Name          string
+	Level         string
+	FileName      string
+	ImageMIMEType string
+	SizeBytes     int64
+	ImageData     []byte        `json:"-"`
+	Hotspots      []RoomHotspot `gorm:"constraint:OnDelete:CASCADE;"`
+	Notes         string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Version       int            `gorm:"not null;default:1"`
+	DeletedAt     gorm.DeletedAt `gorm:"index"`
+}
+
+// RoomHotspot is a rectangle on a floor plan that links to a room. The
+// coordinates are fractions of the image size (0 to 1), so they survive
+// the image being displayed at any scale.
+type RoomHotspot struct {
+	ID          uint `gorm:"primaryKey"`
+	FloorPlanID uint `gorm:"index"`
+	RoomID      uint `gorm:"index"`
+	Room        Room `gorm:"constraint:OnDelete:RESTRICT;"`
+	X           float64
+	Y           float64
+	Width       float64
+	Height      float64
+}
+
+// ListFloorPlans returns floor plans with their hotspots, without the image
+// bytes.
+func (s *Store) ListFloorPlans(includeDeleted bool) ([]FloorPlan, error) {
+	var items []FloorPlan
+	db := s.db.Omit(ColImageData).Scopes(s.inHouse("floor_plans")).
+		Preload("Hotspots", func(q *gorm.DB) *gorm.DB {
+			return q.Order(ColID)
+		}).
+		Order(ColName + ", " + ColID)
+	if includeDeleted {
+		db = db.Unscoped()
+	}
+	return items, db.Find(&items).Error
+}
+
+// GetFloorPlan returns a floor plan with its hotspots and image bytes.
+func (s *Store) GetFloorPlan(id uint) (FloorPlan, error) {
+	var item FloorPlan
+	err := s.db.Preload("Hotspots", func(q *gorm.DB) *gorm.DB {
+		return q.Order(ColID)
+	}).First(&item, id).Error
+	return item, err
+}
+
+// CreateFloorPlan stores a floor plan. The image must be an image type no
+// larger than the document size limit.
+func (s *Store) CreateFloorPlan(item *FloorPlan) error {
+	if err := validateFloorPlan(item); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(item.ImageMIMEType, "image/") {
+		return fmt.Errorf("floor plan must be an image, got %q", item.ImageMIMEType)
+	}
+	if item.SizeBytes > s.maxDocumentSize {
+		return fmt.Errorf(
+			"file is too large (%s) -- maximum allowed is %s",
+			formatBytes(item.SizeBytes), formatBytes(s.maxDocumentSize),
+		)
+	}
+	item.Hotspots = nil
+	return s.db.Create(item).Error
+}
+
+// UpdateFloorPlan changes a floor plan's name, level, and notes. The image
+// and hotspots are left alone.
+func (s *Store) UpdateFloorPlan(item FloorPlan) error {
+	if err := validateFloorPlan(&item); err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return recordChanges(tx, &FloorPlan{}, item.ID, func(tx *gorm.DB) error {
+			return versioned(tx, &FloorPlan{}, item.ID, item.Version, func(q *gorm.DB) *gorm.DB {
+				return q.Select(ColName, ColLevel, ColNotes).Updates(item)
+			})
+		})
+	})
+}
+
+// SetFloorPlanHotspots replaces all of a floor plan's hotspots.
+func (s *Store) SetFloorPlanHotspots(planID uint, hotspots []RoomHotspot) error {
+	if err := s.requireParentAlive(&FloorPlan{}, planID); err != nil {
+		return fmt.Errorf("floor plan not found or deleted")
+	}
+	for i := range hotspots {
+		h := &hotspots[i]
+		if err := s.requireParentAlive(&Room{}, h.RoomID); err != nil {
+			return fmt.Errorf("hotspot %d: room not found or deleted", i+1)
+		}
+		if err := validateHotspot(*h); err != nil {
+			return fmt.Errorf("hotspot %d: %w", i+1, err)
+		}
+		h.ID = 0
+		h.FloorPlanID = planID
+		h.Room = Room{}
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(ColFloorPlanID+" = ?", planID).
+			Delete(&RoomHotspot{}).Error; err != nil {
+			return err
+		}
+		if len(hotspots) == 0 {
+			return nil
+		}
+		return tx.Create(&hotspots).Error
+	})
+}
+
+func (s *Store) DeleteFloorPlan(id uint) error {
+	return s.softDelete(&FloorPlan{}, DeletionEntityFloorPlan, id)
+}
+
+func (s *Store) RestoreFloorPlan(id uint) error {
+	return s.restoreEntity(&FloorPlan{}, DeletionEntityFloorPlan, id)
+}
+
+// countRoomHotspots counts hotspots for a room on floor plans that are not
+// deleted.
+func (s *Store) countRoomHotspots(roomID uint) (int64, error) {
+	var count int64
+	err := s.db.Model(&RoomHotspot{}).
+		Joins("JOIN floor_plans ON floor_plans.id = room_hotspots.floor_plan_id").
+		Where("room_hotspots."+ColRoomID+" = ? AND floor_plans."+ColDeletedAt+" IS NULL", roomID).
+		Count(&count).Error
+	return count, err
+}
+
+func validateFloorPlan(item *FloorPlan) error {
+	item.Name = strings.TrimSpace(item.Name)
+	if item.Name == "" {
+		return fmt.Errorf("floor plan name is required")
+	}
+	return nil
+}
+
+func validateHotspot(h RoomHotspot) error {
+	if h.Width <= 0 || h.Height <= 0 {
+		return fmt.Errorf("width and height must be positive")
+	}
+	if h.X < 0 || h.Y < 0 || h.X+h.Width > 1+1e-9 || h.Y+h.Height > 1+1e-9 {
+		return fmt.Errorf("region must lie within the image")
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFloorPlanHotspots(t *testing.T) {
+	store := newTestStore(t)
+	kitchen := Room{Name: "Kitchen", LengthFt: 12, WidthFt: 14, HeightFt: 9}
+	require.NoError(t, store.CreateRoom(&kitchen))
+	plan := FloorPlan{
+		Name: "Main floor", ImageMIMEType: "image/png",
+		ImageData: []byte("png"), SizeBytes: 3,
+	}
+	require.NoError(t, store.CreateFloorPlan(&plan))
+
+	require.NoError(t, store.SetFloorPlanHotspots(plan.ID, []RoomHotspot{
+		{RoomID: kitchen.ID, X: 0.1, Y: 0.1, Width: 0.3, Height: 0.4},
+	}))
+	plans, err := store.ListFloorPlans(false)
+	require.NoError(t, err)
+	require.Len(t, plans, 1)
+	assert.Empty(t, plans[0].ImageData, "list omits the image")
+	require.Len(t, plans[0].Hotspots, 1)
+	assert.Equal(t, kitchen.ID, plans[0].Hotspots[0].RoomID)
+
+	got, err := store.GetFloorPlan(plan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("png"), got.ImageData)
+
+	// Setting hotspots replaces the old ones.
+	require.NoError(t, store.SetFloorPlanHotspots(plan.ID, []RoomHotspot{
+		{RoomID: kitchen.ID, X: 0.5, Y: 0.5, Width: 0.2, Height: 0.2},
+	}))
+	got, err = store.GetFloorPlan(plan.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Hotspots, 1)
+	assert.InDelta(t, 0.5, got.Hotspots[0].X, 1e-9)
+
+	// The room can't go while it's on a live plan.
+	require.ErrorContains(t, store.DeleteRoom(kitchen.ID), "floor plan")
+	require.NoError(t, store.DeleteFloorPlan(plan.ID))
+	require.NoError(t, store.DeleteRoom(kitchen.ID))
+}
+
+func TestFloorPlanValidation(t *testing.T) {
+	store := newTestStore(t)
+	assert.Error(t, store.CreateFloorPlan(&FloorPlan{Name: "Plan", ImageMIMEType: "application/pdf"}))
+	assert.Error(t, store.CreateFloorPlan(&FloorPlan{ImageMIMEType: "image/png"}))
+
+	room := Room{Name: "Hall"}
+	require.NoError(t, store.CreateRoom(&room))
+	plan := FloorPlan{Name: "Plan", ImageMIMEType: "image/jpeg"}
+	require.NoError(t, store.CreateFloorPlan(&plan))
+	assert.Error(t, store.SetFloorPlanHotspots(plan.ID, []RoomHotspot{
+		{RoomID: room.ID, X: 0.8, Y: 0, Width: 0.5, Height: 0.1},
+	}), "region past the right edge")
+	assert.Error(t, store.SetFloorPlanHotspots(plan.ID, []RoomHotspot{
+		{RoomID: room.ID + 100, Width: 0.1, Height: 0.1},
+	}), "unknown room")
+}
+
+func TestRoomDrilldown(t *testing.T) {
+	store := newTestStore(t)
+	room := Room{Name: "Laundry", LengthFt: 8, WidthFt: 6, HeightFt: 8}
+	require.NoError(t, store.CreateRoom(&room))
+	other := Room{Name: "Garage"}
+	require.NoError(t, store.CreateRoom(&other))
+
+	washer := Appliance{Name: "Washer", RoomID: &room.ID}
+	require.NoError(t, store.CreateAppliance(&washer))
+	require.NoError(t, store.CreateAppliance(&Appliance{Name: "Freezer", RoomID: &other.ID}))
+
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	assigned := Project{Title: "Shelving", ProjectTypeID: types[0].ID, Status: ProjectStatusPlanned, RoomID: &room.ID}
+	require.NoError(t, store.CreateProject(&assigned))
+	estimated := Project{Title: "Repaint", ProjectTypeID: types[0].ID, Status: ProjectStatusPlanned}
+	require.NoError(t, store.CreateProject(&estimated))
+	_, err = store.SaveMaterialEstimate(estimated.ID, room.ID, EstimateInputs{}, "")
+	require.NoError(t, err)
+
+	finish := RoomFinish{RoomID: room.ID, Surface: "Walls", Brand: "Acme", Color: "Linen"}
+	require.NoError(t, store.CreateRoomFinish(&finish))
+	assert.Equal(t, FinishSurfaceWalls, finish.Surface, "surface is normalized")
+
+	d, err := store.RoomDrilldown(room.ID)
+	require.NoError(t, err)
+	require.Len(t, d.Appliances, 1)
+	assert.Equal(t, "Washer", d.Appliances[0].Name)
+	assert.Len(t, d.Projects, 2)
+	assert.Len(t, d.Finishes, 1)
+	assert.Len(t, d.Estimates, 1)
+
+	require.ErrorContains(t, store.DeleteRoom(room.ID), "appliance")
+	require.NoError(t, store.DeleteAppliance(washer.ID))
+	require.ErrorContains(t, store.DeleteRoom(room.ID), "project")
+	require.NoError(t, store.DeleteProject(assigned.ID))
+	require.ErrorContains(t, store.DeleteRoom(room.ID), "finish")
+	require.NoError(t, store.DeleteRoomFinish(finish.ID))
+	require.NoError(t, store.DeleteRoom(room.ID))
+
+	require.ErrorContains(t, store.RestoreAppliance(washer.ID), "room is deleted")
+	require.ErrorContains(t, store.RestoreRoomFinish(finish.ID), "room is deleted")
+	assert.Error(t, store.CreateRoomFinish(&RoomFinish{RoomID: other.ID, Surface: "roof", Color: "x"}))
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import "fmt"
+
+// Funding sources: who paid for a cost in the end.
+const (
+	FundingOutOfPocket = "out_of_pocket"
+	FundingInsurance   = "insurance"
+	FundingWarranty    = "warranty"
+	FundingRebate      = "rebate"
+)
+
+// FundingSources lists the funding sources, out of pocket first.
+func FundingSources() []string {
+	return []string{FundingOutOfPocket, FundingInsurance, FundingWarranty, FundingRebate}
+}
+
+// Funding splits a cost by who paid it: the parts an insurance payout, a
+// warranty and a rebate covered. Whatever's left of the gross cost came
+// out of pocket.
+type Funding struct {
+	InsuranceCents int64
+	WarrantyCents  int64
+	RebateCents    int64
+}
+
+// CoveredCents is the part of the cost someone else paid.
+func (f Funding) CoveredCents() int64 {
+	return f.InsuranceCents + f.WarrantyCents + f.RebateCents
+}
+
+// Add sums two splits.
+func (f Funding) Add(g Funding) Funding {
+	return Funding{
+		InsuranceCents: f.InsuranceCents + g.InsuranceCents,
+		WarrantyCents:  f.WarrantyCents + g.WarrantyCents,
+		RebateCents:    f.RebateCents + g.RebateCents,
+	}
+}
+
+// Cents returns the part of gross paid from source.
+func (f Funding) Cents(source string, gross int64) int64 {
+	switch source {
+	case FundingInsurance:
+		return f.InsuranceCents
+	case FundingWarranty:
+		return f.WarrantyCents
+	case FundingRebate:
+		return f.RebateCents
+	}
+	return gross - f.CoveredCents()
+}
+
+// validate checks the split against the gross cost it splits.
+func (f Funding) validate(gross int64) error {
+	if f.InsuranceCents < 0 || f.WarrantyCents < 0 || f.RebateCents < 0 {
+		return fmt.Errorf("insurance, warranty and rebate amounts can't be negative")
+	}
+	if f.CoveredCents() > gross {
+		return fmt.Errorf("insurance, warranty and rebate cover %s, more than the %s it cost",
+			FormatCents(f.CoveredCents()), FormatCents(gross))
+	}
+	return nil
+}
+
+// Funding is how the entry's cost was paid.
+func (l ServiceLogEntry) Funding() Funding {
+	return Funding{InsuranceCents: l.InsuranceCents, WarrantyCents: l.WarrantyCents, RebateCents: l.RebateCents}
+}
+
+// Funding is how the invoice was paid.
+func (inv Invoice) Funding() Funding {
+	return Funding{InsuranceCents: inv.InsuranceCents, WarrantyCents: inv.WarrantyCents, RebateCents: inv.RebateCents}
+}
+
+func validateServiceLogFunding(entry ServiceLogEntry) error {
+	var gross int64
+	if entry.CostCents != nil {
+		gross = *entry.CostCents
+	}
+	return entry.Funding().validate(gross)
+}
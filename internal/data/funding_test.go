@@ -0,0 +1,58 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFundingSplit(t *testing.T) {
+	store := newTestStore(t)
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 12, 0, 0, 0, time.UTC) }
+	cents := func(c int64) *int64 { return &c }
+
+	cats, err := store.MaintenanceCategories()
+	require.NoError(t, err)
+	roof := MaintenanceItem{Name: "Roof repair", CategoryID: cats[0].ID}
+	require.NoError(t, store.CreateMaintenance(&roof))
+
+	require.ErrorContains(t, store.CreateServiceLog(&ServiceLogEntry{
+		MaintenanceItemID: roof.ID, ServicedAt: day(3, 1), CostCents: cents(1_000), InsuranceCents: 2_000,
+	}, Vendor{}), "more than the $10.00 it cost")
+	require.ErrorContains(t, store.CreateServiceLog(&ServiceLogEntry{
+		MaintenanceItemID: roof.ID, ServicedAt: day(3, 1), RebateCents: -5,
+	}, Vendor{}), "can't be negative")
+
+	storm := ServiceLogEntry{
+		MaintenanceItemID: roof.ID, ServicedAt: day(3, 2), CostCents: cents(800_000),
+		InsuranceCents: 650_000, RebateCents: 10_000,
+	}
+	require.NoError(t, store.CreateServiceLog(&storm, Vendor{Name: "Top Roofing"}))
+
+	done := day(5, 1)
+	project := Project{Title: "New HVAC", ProjectTypeID: 1, Status: ProjectStatusCompleted, ActualCents: cents(1_200_000), EndDate: &done}
+	require.NoError(t, store.CreateProject(&project))
+	require.ErrorContains(t, store.CreateInvoice(&Invoice{
+		ProjectID: project.ID, InvoicedOn: day(4, 1), AmountCents: 100, WarrantyCents: 101,
+	}), "more than")
+	require.NoError(t, store.CreateInvoice(&Invoice{
+		ProjectID: project.ID, InvoicedOn: day(4, 1), AmountCents: 1_200_000, RebateCents: 150_000,
+	}))
+
+	entries, err := store.ListSpending(day(1, 1), day(12, 31))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, int64(800_000), entries[0].AmountCents)
+	assert.Equal(t, int64(140_000), entries[0].OutOfPocketCents())
+	assert.Equal(t, int64(650_000), entries[0].Cents(FundingInsurance, entries[0].AmountCents))
+	assert.Equal(t, int64(1_050_000), entries[1].OutOfPocketCents(), "a project's rebates come from its invoices")
+
+	totals := RollUpSpending(entries, false)
+	require.Len(t, totals, 2)
+	assert.Equal(t, SpendTotal{Period: "2026", Category: SpendMaintenance, AmountCents: 800_000, OutOfPocketCents: 140_000}, totals[0])
+}
@@ -0,0 +1,57 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+// Lookups for many records at once, so a caller walking from records to
+// their related records makes one query per step rather than one per
+// record.
+
+// ProjectsByID returns the projects with the given IDs. Deleted projects
+// are included, since live records can still point at them.
+func (s *Store) ProjectsByID(ids []uint) ([]Project, error) {
+	return findIn[Project](s.db.Unscoped(), ColID, ids)
+}
+
+// VendorsByID returns the vendors with the given IDs, deleted ones
+// included.
+func (s *Store) VendorsByID(ids []uint) ([]Vendor, error) {
+	return findIn[Vendor](s.db.Unscoped(), ColID, ids)
+}
+
+// QuotesByProjects returns the quotes of the given projects.
+func (s *Store) QuotesByProjects(projectIDs []uint) ([]Quote, error) {
+	return findIn[Quote](s.db, ColProjectID, projectIDs)
+}
+
+// QuotesByVendors returns the quotes from the given vendors for the
+// current house's projects.
+func (s *Store) QuotesByVendors(vendorIDs []uint) ([]Quote, error) {
+	return findIn[Quote](s.db.Where(ColProjectID+" IN (?)", s.houseProjectIDs()), ColVendorID, vendorIDs)
+}
+
+// DocumentsByEntities returns the metadata of documents attached to any of
+// the given records of one kind.
+func (s *Store) DocumentsByEntities(entityKind string, entityIDs []uint) ([]Document, error) {
+	return findIn[Document](
+		s.db.Select(listDocumentColumns).Where(ColEntityKind+" = ?", entityKind),
+		ColEntityID, entityIDs,
+	)
+}
+
+// findIn returns the rows whose col is one of ids, oldest first.
+func findIn[T any](db *gorm.DB, col string, ids []uint) ([]T, error) {
+	var rows []T
+	ids = slices.Compact(slices.Sorted(slices.Values(ids)))
+	if len(ids) == 0 {
+		return rows, nil
+	}
+	err := db.Where(col+" IN ?", ids).Order(ColID).Find(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchLookups(t *testing.T) {
+	store := newTestStore(t)
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	for _, title := range []string{"Roof", "Deck", "Fence"} {
+		require.NoError(t, store.CreateProject(&Project{
+			Title: title, ProjectTypeID: types[0].ID, Status: ProjectStatusPlanned,
+		}))
+	}
+	projects, err := store.ListProjects(false)
+	require.NoError(t, err)
+	byTitle := map[string]uint{}
+	for _, p := range projects {
+		byTitle[p.Title] = p.ID
+	}
+	roof, deck, fence := byTitle["Roof"], byTitle["Deck"], byTitle["Fence"]
+
+	require.NoError(t, store.CreateQuote(&Quote{ProjectID: roof, TotalCents: 100}, Vendor{Name: "Acme"}))
+	require.NoError(t, store.CreateQuote(&Quote{ProjectID: roof, TotalCents: 200}, Vendor{Name: "Bolt"}))
+	require.NoError(t, store.CreateQuote(&Quote{ProjectID: fence, TotalCents: 300}, Vendor{Name: "Acme"}))
+	require.NoError(t, store.CreateDocument(&Document{
+		Title: "Plan", EntityKind: DocumentEntityProject, EntityID: deck, Data: []byte("x"),
+	}))
+	require.NoError(t, store.DeleteProject(deck))
+
+	got, err := store.ProjectsByID([]uint{fence, deck, fence})
+	require.NoError(t, err)
+	require.Len(t, got, 2, "duplicates are dropped, deleted projects kept")
+
+	quotes, err := store.QuotesByProjects([]uint{roof, deck})
+	require.NoError(t, err)
+	require.Len(t, quotes, 2)
+	for _, q := range quotes {
+		assert.Equal(t, roof, q.ProjectID)
+	}
+
+	vendors, err := store.ListVendors(false)
+	require.NoError(t, err)
+	require.Len(t, vendors, 2)
+	got2, err := store.VendorsByID([]uint{vendors[0].ID, vendors[1].ID})
+	require.NoError(t, err)
+	assert.Len(t, got2, 2)
+	acme := vendors[0].ID
+	if vendors[0].Name != "Acme" {
+		acme = vendors[1].ID
+	}
+	quotes, err = store.QuotesByVendors([]uint{acme})
+	require.NoError(t, err)
+	assert.Len(t, quotes, 2)
+
+	docs, err := store.DocumentsByEntities(DocumentEntityProject, []uint{roof, deck})
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "Plan", docs[0].Title)
+	assert.Nil(t, docs[0].Data, "only metadata is loaded")
+
+	none, err := store.QuotesByProjects(nil)
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
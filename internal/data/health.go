@@ -0,0 +1,146 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data/sqlite"
+)
+
+// retryDelays are the waits before retrying a statement that failed with a
+// transient error, about two seconds in all.
+var retryDelays = []time.Duration{
+	100 * time.Millisecond, 400 * time.Millisecond, 1500 * time.Millisecond,
+}
+
+// probeInterval is how often an unavailable database is checked for
+// recovery.
+const probeInterval = time.Second
+
+// HealthStatus says whether the database is reachable.
+type HealthStatus struct {
+	OK bool
+	// Since is when the current state began.
+	Since time.Time
+	// Error is the failure that made the database unavailable.
+	Error string
+	// Unsaved is why an encrypted database's changes couldn't be sealed to
+	// its file; until a save works, they're only in memory.
+	Unsaved string
+}
+
+// health tracks statement outcomes. A transient failure that outlasts its
+// retries marks the database unavailable and starts probing it; the first
+// success marks it available again.
+type health struct {
+	probe func(context.Context) error
+
+	mu      sync.Mutex
+	status  HealthStatus
+	probing bool
+	log     io.Writer
+}
+
+func newHealth() *health {
+	return &health{status: HealthStatus{OK: true, Since: time.Now()}, log: io.Discard}
+}
+
+func (h *health) observe(err error) {
+	switch {
+	case err == nil:
+		h.recovered()
+	case sqlite.IsTransient(err):
+		h.degrade(err)
+	}
+}
+
+func (h *health) degrade(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.status.OK {
+		h.status = HealthStatus{Since: time.Now(), Error: err.Error()}
+		fmt.Fprintf(h.log, "webcasa: database unavailable, serving 503s until it recovers: %v\n", err)
+	}
+	if !h.probing && h.probe != nil {
+		h.probing = true
+		go h.probeUntilOK()
+	}
+}
+
+func (h *health) recovered() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.status.OK {
+		return
+	}
+	down := time.Since(h.status.Since).Round(time.Millisecond)
+	h.status = HealthStatus{OK: true, Since: time.Now()}
+	fmt.Fprintf(h.log, "webcasa: database recovered after %s\n", down)
+}
+
+func (h *health) probeUntilOK() {
+	t := time.NewTicker(probeInterval)
+	defer t.Stop()
+	for range t.C {
+		ctx, cancel := context.WithTimeout(context.Background(), probeInterval)
+		err := h.probe(ctx)
+		cancel()
+		if err == nil {
+			h.recovered()
+		}
+		h.mu.Lock()
+		done := h.status.OK
+		if done {
+			h.probing = false
+		}
+		h.mu.Unlock()
+		if done {
+			return
+		}
+	}
+}
+
+func (h *health) get() HealthStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.status
+}
+
+// Health reports whether the database is currently reachable, and for an
+// encrypted one, whether its changes are being saved.
+func (s *Store) Health() HealthStatus {
+	st := s.health.get()
+	if s.sealed != nil {
+		if unsaved := s.sealed.unsaved.Load(); unsaved != nil {
+			st.Unsaved = *unsaved
+		}
+	}
+	return st
+}
+
+// logf reports to the health log.
+func (h *health) logf(format string, args ...any) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fmt.Fprintf(h.log, format, args...)
+}
+
+// SetHealthLog sets where database outages and recoveries are reported.
+func (s *Store) SetHealthLog(w io.Writer) {
+	s.health.mu.Lock()
+	defer s.health.mu.Unlock()
+	s.health.log = w
+}
+
+// Ping reads from the database file, updating Health with the outcome.
+func (s *Store) Ping(ctx context.Context) error {
+	err := s.health.probe(ctx)
+	s.health.observe(err)
+	return err
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a bytes.Buffer safe to write from the probe goroutine.
+type syncBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.Write(p)
+}
+
+func (s *syncBuffer) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.String()
+}
+
+func TestHealthDegradesAndRecovers(t *testing.T) {
+	var down atomic.Bool
+	down.Store(true)
+	var log syncBuffer
+	h := newHealth()
+	h.log = &log
+	h.probe = func(context.Context) error {
+		if down.Load() {
+			return driver.ErrBadConn
+		}
+		return nil
+	}
+
+	// Ordinary errors say nothing about availability.
+	h.observe(errors.New("UNIQUE constraint failed"))
+	assert.True(t, h.get().OK)
+
+	h.observe(driver.ErrBadConn)
+	st := h.get()
+	assert.False(t, st.OK)
+	assert.Contains(t, st.Error, "bad connection")
+	assert.Contains(t, log.String(), "database unavailable")
+
+	down.Store(false)
+	require.Eventually(t, func() bool { return h.get().OK }, 5*time.Second, 10*time.Millisecond)
+	assert.Contains(t, log.String(), "database recovered after")
+}
+
+func TestStorePing(t *testing.T) {
+	store := newTestStore(t)
+	require.NoError(t, store.Ping(context.Background()))
+	assert.True(t, store.Health().OK)
+}
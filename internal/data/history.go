@@ -0,0 +1,189 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// FieldChange records one field of one row changing value. Entity is the
+// table name. Values are stored as JSON so a field can be put back exactly
+// as it was.
+type FieldChange struct {
+	ID        uint      `gorm:"primaryKey"`
+	Entity    string    `gorm:"index:idx_field_change_row,priority:1"`
+	TargetID  uint      `gorm:"index:idx_field_change_row,priority:2"`
+	ChangedAt time.Time `gorm:"index:idx_field_change_row,priority:3"`
+	Field     string
+	OldValue  string
+	NewValue  string
+}
+
+// ErrUnknownEntity means a history lookup named a table the store doesn't
+// have.
+var ErrUnknownEntity = errors.New("unknown entity")
+
+// untrackedColumns lists columns of model left out of the history on top
+// of the bookkeeping ones: document columns describing the file, which
+// can't be reverted without its content, and links the store maintains.
+func untrackedColumns(model any) []string {
+	switch model.(type) {
+	case *Document:
+		return []string{
+			ColFileName, ColMIMEType, ColSizeBytes, ColChecksum,
+			ColOriginalChecksum, ColOriginalSize, ColImageHash, ColSharpness, ColLastOpenedAt, ColContentOf,
+			ColStoredFile,
+		}
+	case *SmartDevice, *AirFilterSpec:
+		return []string{ColMaintenanceItemID}
+	case *MaintenanceItem:
+		return []string{ColNextDueAt}
+	}
+	return nil
+}
+
+// trackedFields returns the fields of model whose changes are recorded:
+// plain, user-editable columns, not blobs.
+func trackedFields(db *gorm.DB, model any) (*schema.Schema, []*schema.Field, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, nil, err
+	}
+	skip := append([]string{ColID, ColCreatedAt, ColUpdatedAt, ColDeletedAt, ColVersion}, untrackedColumns(model)...)
+	var fields []*schema.Field
+	for _, f := range stmt.Schema.Fields {
+		if f.DBName == "" || !f.Readable || !f.Updatable || slices.Contains(skip, f.DBName) {
+			continue
+		}
+		if f.FieldType == reflect.TypeOf([]byte(nil)) {
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return stmt.Schema, fields, nil
+}
+
+// recordChanges runs update and records a FieldChange for every tracked
+// field of the row model/id it changed. Rows that don't exist are left to
+// update to deal with.
+func recordChanges(tx *gorm.DB, model any, id uint, update func(tx *gorm.DB) error) error {
+	sch, fields, err := trackedFields(tx, model)
+	if err != nil {
+		return err
+	}
+	cols := make([]string, 0, len(fields)+1)
+	cols = append(cols, ColID)
+	for _, f := range fields {
+		cols = append(cols, f.DBName)
+	}
+	load := func() (reflect.Value, error) {
+		row := reflect.New(sch.ModelType)
+		err := tx.Unscoped().Model(model).Select(cols).Where(ColID+" = ?", id).
+			Take(row.Interface()).Error
+		return row.Elem(), err
+	}
+
+	before, err := load()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return update(tx)
+	} else if err != nil {
+		return err
+	}
+	if err := update(tx); err != nil {
+		return err
+	}
+	after, err := load()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var changes []FieldChange
+	for _, f := range fields {
+		oldVal, _ := f.ValueOf(context.Background(), before)
+		newVal, _ := f.ValueOf(context.Background(), after)
+		oldJSON, err := json.Marshal(oldVal)
+		if err != nil {
+			return fmt.Errorf("record %s: %w", f.DBName, err)
+		}
+		newJSON, err := json.Marshal(newVal)
+		if err != nil {
+			return fmt.Errorf("record %s: %w", f.DBName, err)
+		}
+		if bytes.Equal(oldJSON, newJSON) {
+			continue
+		}
+		changes = append(changes, FieldChange{
+			Entity:    sch.Table,
+			TargetID:  id,
+			ChangedAt: now,
+			Field:     f.DBName,
+			OldValue:  string(oldJSON),
+			NewValue:  string(newJSON),
+		})
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	if err := tx.Create(&changes).Error; err != nil {
+		return err
+	}
+	if err := auditEdit(tx, changes); err != nil {
+		return err
+	}
+	return logEdit(tx, changes)
+}
+
+// FieldHistory returns the recorded changes to a row, newest first. entity
+// is the table name, e.g. "projects".
+func (s *Store) FieldHistory(entity string, id uint) ([]FieldChange, error) {
+	if _, err := s.historyModel(entity); err != nil {
+		return nil, err
+	}
+	var changes []FieldChange
+	err := s.db.Where(ColEntity+" = ? AND "+ColTargetID+" = ?", entity, id).
+		Order(ColChangedAt + " desc, " + ColID + " desc").
+		Find(&changes).Error
+	return changes, err
+}
+
+// RevertField sets the field a change touched back to the value it had
+// before that change. The revert is itself recorded as a change.
+func (s *Store) RevertField(changeID uint) error {
+	var change FieldChange
+	if err := s.db.First(&change, changeID).Error; err != nil {
+		return err
+	}
+	model, err := s.historyModel(change.Entity)
+	if err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return setField(tx, model, change.TargetID, change.Field, change.OldValue)
+	})
+}
+
+// historyModel returns a new instance of the model stored in table entity.
+func (s *Store) historyModel(entity string) (any, error) {
+	for _, model := range allModels() {
+		stmt := &gorm.Statement{DB: s.db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, err
+		}
+		if stmt.Schema.Table == entity {
+			return reflect.New(stmt.Schema.ModelType).Interface(), nil
+		}
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownEntity, entity)
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdatesRecordFieldHistory(t *testing.T) {
+	store := newTestStore(t)
+	types, _ := store.ProjectTypes()
+	project := Project{Title: "Deck", ProjectTypeID: types[0].ID, Status: ProjectStatusPlanned}
+	require.NoError(t, store.CreateProject(&project))
+
+	project.Title = "Back deck"
+	require.NoError(t, store.UpdateProject(project))
+	project, err := store.GetProject(project.ID)
+	require.NoError(t, err)
+	budget := int64(650000)
+	project.BudgetCents = &budget
+	project.Status = ProjectStatusInProgress
+	require.NoError(t, store.UpdateProject(project))
+	// Saving without changes records nothing.
+	project, err = store.GetProject(project.ID)
+	require.NoError(t, err)
+	require.NoError(t, store.UpdateProject(project))
+
+	history, err := store.FieldHistory("projects", project.ID)
+	require.NoError(t, err)
+	fields := make([]string, len(history))
+	for i, c := range history {
+		fields[i] = c.Field
+	}
+	assert.ElementsMatch(t, []string{"title", "budget_cents", "status"}, fields)
+	assert.Equal(t, "title", history[len(history)-1].Field, "newest first")
+	assert.Equal(t, `"Deck"`, history[len(history)-1].OldValue)
+	assert.Equal(t, `"Back deck"`, history[len(history)-1].NewValue)
+
+	// A field that was unset goes back to unset.
+	for _, c := range history {
+		if c.Field == "budget_cents" {
+			assert.Equal(t, "null", c.OldValue)
+			require.NoError(t, store.RevertField(c.ID))
+		}
+	}
+	got, err := store.GetProject(project.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got.BudgetCents)
+	assert.Equal(t, ProjectStatusInProgress, got.Status)
+
+	_, err = store.FieldHistory("nonsense", 1)
+	assert.Error(t, err)
+}
+
+func TestRevertField(t *testing.T) {
+	store := newTestStore(t)
+	noticed := time.Date(2026, 2, 3, 0, 0, 0, 0, time.UTC)
+	incident := Incident{
+		Title: "Leak", Status: IncidentStatusOpen, Severity: IncidentSeverityUrgent,
+		DateNoticed: noticed, Description: "under the sink",
+	}
+	require.NoError(t, store.CreateIncident(&incident))
+	incident.Description = "under the sink, fixed"
+	incident.DateNoticed = noticed.AddDate(0, 0, 1)
+	require.NoError(t, store.UpdateIncident(incident))
+
+	history, err := store.FieldHistory("incidents", incident.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	for _, c := range history {
+		require.NoError(t, store.RevertField(c.ID))
+	}
+	got, err := store.GetIncident(incident.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "under the sink", got.Description)
+	assert.True(t, noticed.Equal(got.DateNoticed))
+
+	// Reverts are changes too.
+	history, err = store.FieldHistory("incidents", incident.ID)
+	require.NoError(t, err)
+	assert.Len(t, history, 4)
+
+	require.NoError(t, store.DeleteIncident(incident.ID))
+	assert.Error(t, store.RevertField(history[0].ID))
+}
+
+func TestDocumentFileColumnsAreNotTracked(t *testing.T) {
+	store := newTestStore(t)
+	doc := Document{Title: "Manual", FileName: "a.pdf", Data: []byte("one")}
+	require.NoError(t, store.CreateDocument(&doc))
+	doc.Title = "Owner's manual"
+	doc.FileName = "b.pdf"
+	doc.Data = []byte("two")
+	require.NoError(t, store.UpdateDocument(doc))
+
+	history, err := store.FieldHistory("documents", doc.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "title", history[0].Field)
+}
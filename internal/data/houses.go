@@ -0,0 +1,226 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrHouseArchived means the house is already archived.
+var ErrHouseArchived = errors.New("house is already archived")
+
+// ErrHouseActive means the house isn't archived.
+var ErrHouseActive = errors.New("house is active")
+
+// settingCurrentHouse holds the ID of the house picked with SwitchHouse.
+const settingCurrentHouse = "house.current"
+
+// activeHouse leaves archived houses out of a query.
+func activeHouse(db *gorm.DB) *gorm.DB {
+	return db.Where(ColArchivedAt + " IS NULL")
+}
+
+// ListHouses returns the house profiles, oldest first. Archived houses are
+// only included when asked for.
+func (s *Store) ListHouses(includeArchived bool) ([]HouseProfile, error) {
+	var houses []HouseProfile
+	q := s.db.Order(ColID)
+	if !includeArchived {
+		q = q.Scopes(activeHouse)
+	}
+	if err := q.Find(&houses).Error; err != nil {
+		return nil, err
+	}
+	return houses, nil
+}
+
+// ArchiveHouse puts a house away, e.g. once it's sold. Its profile drops out
+// of every view, and a new house can be set up, but nothing is deleted:
+// ListHouses(true) still finds it, ViewHouse shows its records and
+// ReactivateHouse brings it back.
+func (s *Store) ArchiveHouse(id uint) error {
+	return s.setHouseArchived(id, true)
+}
+
+// ReactivateHouse brings an archived house back. It doesn't become the
+// current house until switched to.
+func (s *Store) ReactivateHouse(id uint) error {
+	return s.setHouseArchived(id, false)
+}
+
+func (s *Store) setHouseArchived(id uint, archive bool) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var house HouseProfile
+		if err := tx.First(&house, id).Error; err != nil {
+			return err
+		}
+		if archive == (house.ArchivedAt != nil) {
+			if archive {
+				return fmt.Errorf("house #%d: %w", id, ErrHouseArchived)
+			}
+			return fmt.Errorf("house #%d: %w", id, ErrHouseActive)
+		}
+		var value any
+		if archive {
+			value = time.Now()
+		}
+		return tx.Model(&HouseProfile{}).Where(ColID+" = ?", id).
+			UpdateColumn(ColArchivedAt, value).Error
+	})
+}
+
+// viewedHouseKey holds the house a store made by ViewHouse shows.
+type viewedHouseKey struct{}
+
+// ViewHouse returns a store that shows house id's records in place of the
+// current house's, archived or not, so a house can be looked back on after
+// it's sold. It doesn't switch houses; changes belong to the current one.
+func (s *Store) ViewHouse(id uint) (*Store, error) {
+	var house HouseProfile
+	if err := s.db.First(&house, id).Error; err != nil {
+		return nil, err
+	}
+	v := *s
+	v.db = s.db.WithContext(context.WithValue(s.db.Statement.Context, viewedHouseKey{}, id))
+	return &v, nil
+}
+
+// currentHouse is the house picked with SwitchHouse, or the first active
+// one when none was picked or the pick has since been archived. A store
+// made by ViewHouse has the house it shows.
+func currentHouse(db *gorm.DB) (HouseProfile, error) {
+	var profile HouseProfile
+	if ctx := db.Statement.Context; ctx != nil {
+		if id, ok := ctx.Value(viewedHouseKey{}).(uint); ok {
+			err := db.First(&profile, id).Error
+			return profile, err
+		}
+	}
+	var picked Setting
+	err := db.Where("key = ?", settingCurrentHouse).First(&picked).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return HouseProfile{}, err
+	}
+	if id, _ := strconv.ParseUint(picked.Value, 10, 64); id > 0 {
+		err := db.Scopes(activeHouse).First(&profile, id).Error
+		if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
+			return profile, err
+		}
+	}
+	err = db.Scopes(activeHouse).Order(ColID).First(&profile).Error
+	return profile, err
+}
+
+// SwitchHouse makes another active house the current one. Projects,
+// appliances, maintenance and documents are listed for the current house,
+// and new ones are filed under it.
+func (s *Store) SwitchHouse(id uint) error {
+	var house HouseProfile
+	if err := s.db.First(&house, id).Error; err != nil {
+		return err
+	}
+	if house.ArchivedAt != nil {
+		return fmt.Errorf("house #%d: %w -- reactivate it first", id, ErrHouseArchived)
+	}
+	return s.PutSetting(settingCurrentHouse, strconv.FormatUint(uint64(id), 10))
+}
+
+// AddHouse sets up another house alongside the current one and switches to
+// it. Use CreateHouseProfile for the first.
+func (s *Store) AddHouse(profile *HouseProfile) error {
+	profile.ID = 0
+	profile.ArchivedAt = nil
+	if err := s.db.Create(profile).Error; err != nil {
+		return err
+	}
+	return s.SwitchHouse(profile.ID)
+}
+
+// houseTables are the tables whose rows belong to a house.
+var houseTables = []string{"projects", "appliances", tableMaintenanceItems, "documents", "expenses", "sitter_stays", "warranties", "inventory_items",
+	"rebates", "contacts", "utility_bills", "rooms", "floor_plans", "walkthroughs", "landscape_assets", "pest_treatments",
+	"water_tests", "water_filter_changes", "smart_devices", "incidents",
+}
+
+// inHouse limits a query on table to the current house's rows. With no
+// active house, only rows not yet filed under one are left.
+func (s *Store) inHouse(table string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		house, err := s.HouseProfile()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return db.Where(table + "." + ColHouseID + " IS NULL")
+		} else if err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		return db.Where(table+"."+ColHouseID+" = ?", house.ID)
+	}
+}
+
+// claimUnfiled files the rows not yet under a house -- everything, in a
+// database from before houses were told apart -- under the current house.
+func claimUnfiled(db *gorm.DB) error {
+	house, err := currentHouse(db)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for _, table := range houseTables {
+		if err := db.Exec(
+			"UPDATE "+table+" SET "+ColHouseID+" = ? WHERE "+ColHouseID+" IS NULL", house.ID,
+		).Error; err != nil {
+			return fmt.Errorf("file %s under house #%d: %w", table, house.ID, err)
+		}
+	}
+	return nil
+}
+
+// registerHouseFiling files new rows of the houseTables -- projects,
+// appliances, rooms, pest treatments and the rest -- under the current
+// house, however they're created, unless the caller already picked one.
+func registerHouseFiling(db *gorm.DB) error {
+	file := func(tx *gorm.DB) {
+		if tx.Error != nil || tx.Statement.Schema == nil {
+			return
+		}
+		field := tx.Statement.Schema.LookUpField("HouseID")
+		if field == nil {
+			return
+		}
+		house, err := currentHouse(tx.Session(&gorm.Session{NewDB: true}))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return
+		} else if err != nil {
+			_ = tx.AddError(err)
+			return
+		}
+		ctx := tx.Statement.Context
+		set := func(row reflect.Value) {
+			if _, zero := field.ValueOf(ctx, row); !zero {
+				return
+			}
+			id := house.ID
+			if err := field.Set(ctx, row, &id); err != nil {
+				_ = tx.AddError(err)
+			}
+		}
+		switch rows := tx.Statement.ReflectValue; rows.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := range rows.Len() {
+				set(reflect.Indirect(rows.Index(i)))
+			}
+		case reflect.Struct:
+			set(rows)
+		}
+	}
+	return db.Callback().Create().Before("gorm:create").Register("webcasa:house", file)
+}
@@ -0,0 +1,324 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestArchiveHouse(t *testing.T) {
+	store := newTestStore(t)
+	require.NoError(t, store.CreateHouseProfile(HouseProfile{Nickname: "Elm Street"}))
+	sold, err := store.HouseProfile()
+	require.NoError(t, err)
+
+	require.NoError(t, store.ArchiveHouse(sold.ID))
+	_, err = store.HouseProfile()
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound, "an archived house drops out of views")
+	assert.ErrorIs(t, store.ArchiveHouse(sold.ID), ErrHouseArchived)
+	houses, err := store.ListHouses(false)
+	require.NoError(t, err)
+	assert.Empty(t, houses)
+	houses, err = store.ListHouses(true)
+	require.NoError(t, err)
+	require.Len(t, houses, 1)
+	assert.NotNil(t, houses[0].ArchivedAt)
+
+	// The next house starts fresh, and saving it leaves the old one alone.
+	require.NoError(t, store.CreateHouseProfile(HouseProfile{Nickname: "Oak Lane"}))
+	current, err := store.HouseProfile()
+	require.NoError(t, err)
+	assert.Equal(t, "Oak Lane", current.Nickname)
+	current.City = "Portland"
+	require.NoError(t, store.UpdateHouseProfile(current))
+	houses, err = store.ListHouses(true)
+	require.NoError(t, err)
+	require.Len(t, houses, 2)
+	assert.Empty(t, houses[0].City)
+
+	// Reactivating a house doesn't switch to it.
+	require.NoError(t, store.ReactivateHouse(sold.ID))
+	assert.ErrorIs(t, store.ReactivateHouse(sold.ID), ErrHouseActive)
+	still, err := store.HouseProfile()
+	require.NoError(t, err)
+	assert.Equal(t, "Oak Lane", still.Nickname)
+	require.NoError(t, store.ArchiveHouse(current.ID))
+	back, err := store.HouseProfile()
+	require.NoError(t, err)
+	assert.Equal(t, "Elm Street", back.Nickname)
+	assert.Nil(t, back.ArchivedAt)
+
+	assert.ErrorIs(t, store.ArchiveHouse(999), gorm.ErrRecordNotFound)
+}
+
+func TestSwitchHouse(t *testing.T) {
+	store := newTestStore(t)
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+
+	// Records from before the first house are filed under it.
+	early := Project{Title: "Paint the fence", ProjectTypeID: types[0].ID}
+	require.NoError(t, store.CreateProject(&early))
+	require.NoError(t, store.CreateHouseProfile(HouseProfile{Nickname: "Elm Street"}))
+	home, err := store.HouseProfile()
+	require.NoError(t, err)
+	got, err := store.GetProject(early.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.HouseID)
+	assert.Equal(t, home.ID, *got.HouseID)
+
+	cabin := HouseProfile{Nickname: "Lake cabin"}
+	require.NoError(t, store.AddHouse(&cabin))
+	current, err := store.HouseProfile()
+	require.NoError(t, err)
+	assert.Equal(t, cabin.ID, current.ID, "adding a house switches to it")
+
+	dock := Project{Title: "Fix the dock", ProjectTypeID: types[0].ID}
+	require.NoError(t, store.CreateProject(&dock))
+	require.NoError(t, store.CreateAppliance(&Appliance{Name: "Boat lift"}))
+	projects, err := store.ListProjects(false)
+	require.NoError(t, err)
+	require.Len(t, projects, 1)
+	assert.Equal(t, "Fix the dock", projects[0].Title)
+
+	// Edits leave a record in its house.
+	dock.Description = "Replace the rotten boards"
+	require.NoError(t, store.UpdateProject(dock))
+	got, err = store.GetProject(dock.ID)
+	require.NoError(t, err)
+	assert.Equal(t, cabin.ID, *got.HouseID)
+
+	require.NoError(t, store.SwitchHouse(home.ID))
+	projects, err = store.ListProjects(false)
+	require.NoError(t, err)
+	require.Len(t, projects, 1)
+	assert.Equal(t, "Paint the fence", projects[0].Title)
+	appliances, err := store.ListAppliances(false)
+	require.NoError(t, err)
+	assert.Empty(t, appliances)
+
+	require.NoError(t, store.ArchiveHouse(cabin.ID))
+	assert.ErrorIs(t, store.SwitchHouse(cabin.ID), ErrHouseArchived)
+	assert.ErrorIs(t, store.SwitchHouse(999), gorm.ErrRecordNotFound)
+}
+
+func TestHouseRecordsStayApart(t *testing.T) {
+	store := newTestStore(t)
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	hardness := 7.5
+	// record files one of each kind of record under the current house,
+	// named for it.
+	record := func(name string) {
+		t.Helper()
+		require.NoError(t, store.CreateRoom(&Room{Name: name}))
+		require.NoError(t, store.CreateFloorPlan(&FloorPlan{Name: name, ImageMIMEType: "image/png", ImageData: []byte("png")}))
+		require.NoError(t, store.CreateWalkthrough(&Walkthrough{Year: 2026, Title: name}))
+		require.NoError(t, store.CreateLandscapeAsset(&LandscapeAsset{Name: name, Kind: LandscapeKindTree}))
+		require.NoError(t, store.CreatePestTreatment(&PestTreatment{
+			TargetPest: name, TreatedAt: now.AddDate(0, -1, 0), RetreatIntervalMonths: 1,
+		}))
+		require.NoError(t, store.CreateWaterTest(&WaterTest{TestedAt: now, Source: name, HardnessGPG: &hardness}))
+		softener := Appliance{Name: name + " softener"}
+		require.NoError(t, store.CreateAppliance(&softener))
+		require.NoError(t, store.CreateWaterFilterChange(&WaterFilterChange{ApplianceID: softener.ID, ChangedAt: now, FilterModel: name}))
+		require.NoError(t, store.CreateSmartDevice(&SmartDevice{Name: name}))
+		require.NoError(t, store.CreateIncident(&Incident{
+			Title: name, Status: IncidentStatusOpen, Severity: IncidentSeveritySoon, DateNoticed: now,
+		}))
+	}
+	// listed returns the names on each list of the current house.
+	listed := func() map[string][]string {
+		t.Helper()
+		out := map[string][]string{}
+		rooms, err := store.ListRooms(false)
+		require.NoError(t, err)
+		for _, r := range rooms {
+			out["rooms"] = append(out["rooms"], r.Name)
+		}
+		plans, err := store.ListFloorPlans(false)
+		require.NoError(t, err)
+		for _, p := range plans {
+			out["floor plans"] = append(out["floor plans"], p.Name)
+		}
+		walks, err := store.ListWalkthroughs(false)
+		require.NoError(t, err)
+		for _, w := range walks {
+			out["walkthroughs"] = append(out["walkthroughs"], w.Title)
+		}
+		assets, err := store.ListLandscapeAssets(false)
+		require.NoError(t, err)
+		for _, a := range assets {
+			out["landscape"] = append(out["landscape"], a.Name)
+		}
+		pests, err := store.ListPestTreatments(false)
+		require.NoError(t, err)
+		for _, p := range pests {
+			out["pests"] = append(out["pests"], p.TargetPest)
+		}
+		due, err := store.ListPestRetreatmentsDue(now, 0)
+		require.NoError(t, err)
+		for _, p := range due {
+			out["pests due"] = append(out["pests due"], p.TargetPest)
+		}
+		tests, err := store.ListWaterTests(false)
+		require.NoError(t, err)
+		for _, w := range tests {
+			out["water tests"] = append(out["water tests"], w.Source)
+		}
+		filters, err := store.ListWaterFilterChanges(0, false)
+		require.NoError(t, err)
+		for _, f := range filters {
+			out["water filters"] = append(out["water filters"], f.FilterModel)
+		}
+		devices, err := store.ListSmartDevices(false)
+		require.NoError(t, err)
+		for _, d := range devices {
+			out["devices"] = append(out["devices"], d.Name)
+		}
+		incidents, err := store.ListIncidents(false)
+		require.NoError(t, err)
+		for _, i := range incidents {
+			out["incidents"] = append(out["incidents"], i.Title)
+		}
+		open, err := store.ListOpenIncidents()
+		require.NoError(t, err)
+		for _, i := range open {
+			out["open incidents"] = append(out["open incidents"], i.Title)
+		}
+		return out
+	}
+	only := func(name string) map[string][]string {
+		out := map[string][]string{}
+		for _, list := range []string{
+			"rooms", "floor plans", "walkthroughs", "landscape", "pests", "pests due", "water tests", "water filters", "devices",
+			"incidents", "open incidents",
+		} {
+			out[list] = []string{name}
+		}
+		return out
+	}
+
+	// What's recorded before the first house is filed under it.
+	record("Elm")
+	require.NoError(t, store.CreateHouseProfile(HouseProfile{Nickname: "Elm Street"}))
+	home, err := store.HouseProfile()
+	require.NoError(t, err)
+	assert.Equal(t, only("Elm"), listed())
+
+	cabin := HouseProfile{Nickname: "Lake cabin"}
+	require.NoError(t, store.AddHouse(&cabin))
+	assert.Empty(t, listed())
+	// Each house has its own walkthrough for the year.
+	record("Cabin")
+	assert.Equal(t, only("Cabin"), listed())
+
+	require.NoError(t, store.SwitchHouse(home.ID))
+	assert.Equal(t, only("Elm"), listed())
+
+	// An archived house's records stay out of the lists too.
+	require.NoError(t, store.ArchiveHouse(cabin.ID))
+	assert.Equal(t, only("Elm"), listed())
+}
+
+func TestVendorRecordsStayInHouse(t *testing.T) {
+	store := newTestStore(t)
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	categories, err := store.MaintenanceCategories()
+	require.NoError(t, err)
+	plumber := Vendor{Name: "Pipe Pros"}
+	require.NoError(t, store.CreateVendor(&plumber))
+
+	// hire has the plumber quote a project and service an item of the
+	// current house, costing cents.
+	hire := func(cents int64) {
+		t.Helper()
+		project := Project{Title: "Repipe", ProjectTypeID: types[0].ID, Status: ProjectStatusPlanned}
+		require.NoError(t, store.CreateProject(&project))
+		require.NoError(t, store.CreateQuote(&Quote{ProjectID: project.ID, TotalCents: cents}, plumber))
+		item := MaintenanceItem{Name: "Water heater", CategoryID: categories[0].ID}
+		require.NoError(t, store.CreateMaintenance(&item))
+		require.NoError(t, store.CreateServiceLog(&ServiceLogEntry{
+			MaintenanceItemID: item.ID, ServicedAt: time.Now(), CostCents: &cents,
+		}, plumber))
+	}
+	// hired returns what the plumber quoted and charged the current house.
+	hired := func() []int64 {
+		t.Helper()
+		var out []int64
+		quotes, err := store.ListQuotesByVendor(plumber.ID, false)
+		require.NoError(t, err)
+		for _, q := range quotes {
+			out = append(out, q.TotalCents)
+		}
+		quotes, err = store.QuotesByVendors([]uint{plumber.ID})
+		require.NoError(t, err)
+		for _, q := range quotes {
+			out = append(out, q.TotalCents)
+		}
+		logs, err := store.ListServiceLogsByVendor(plumber.ID, false)
+		require.NoError(t, err)
+		for _, l := range logs {
+			out = append(out, *l.CostCents)
+		}
+		return out
+	}
+
+	require.NoError(t, store.CreateHouseProfile(HouseProfile{Nickname: "Elm Street"}))
+	home, err := store.HouseProfile()
+	require.NoError(t, err)
+	hire(1000)
+	cabin := HouseProfile{Nickname: "Lake cabin"}
+	require.NoError(t, store.AddHouse(&cabin))
+	assert.Empty(t, hired())
+	hire(2000)
+	assert.Equal(t, []int64{2000, 2000, 2000}, hired())
+
+	require.NoError(t, store.SwitchHouse(home.ID))
+	assert.Equal(t, []int64{1000, 1000, 1000}, hired())
+}
+
+func TestViewArchivedHouse(t *testing.T) {
+	store := newTestStore(t)
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	titles := func(s *Store) []string {
+		t.Helper()
+		projects, err := s.ListProjects(false)
+		require.NoError(t, err)
+		var out []string
+		for _, p := range projects {
+			out = append(out, p.Title)
+		}
+		return out
+	}
+
+	require.NoError(t, store.CreateHouseProfile(HouseProfile{Nickname: "Elm Street"}))
+	home, err := store.HouseProfile()
+	require.NoError(t, err)
+	require.NoError(t, store.CreateProject(&Project{Title: "Porch", ProjectTypeID: types[0].ID, Status: ProjectStatusCompleted}))
+	require.NoError(t, store.AddHouse(&HouseProfile{Nickname: "Lake cabin"}))
+	require.NoError(t, store.CreateProject(&Project{Title: "Dock", ProjectTypeID: types[0].ID, Status: ProjectStatusPlanned}))
+	require.NoError(t, store.ArchiveHouse(home.ID))
+	assert.Equal(t, []string{"Dock"}, titles(store))
+
+	// Sold and archived, Elm Street's history can still be looked at.
+	view, err := store.ViewHouse(home.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Porch"}, titles(view))
+	viewed, err := view.HouseProfile()
+	require.NoError(t, err)
+	assert.Equal(t, "Elm Street", viewed.Nickname)
+	assert.NotNil(t, viewed.ArchivedAt)
+	// Looking doesn't switch.
+	assert.Equal(t, []string{"Dock"}, titles(store))
+
+	_, err = store.ViewHouse(home.ID + 100)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
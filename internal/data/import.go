@@ -0,0 +1,362 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ImportStrategy says what ImportTables does with a row whose ID is already
+// in the database.
+type ImportStrategy string
+
+const (
+	// ImportSkip keeps the existing row.
+	ImportSkip ImportStrategy = "skip"
+	// ImportOverwrite replaces the existing row with the imported one.
+	ImportOverwrite ImportStrategy = "overwrite"
+	// ImportMerge fills the existing row in from the imported one, keeping
+	// the existing value wherever the imported cell is blank.
+	ImportMerge ImportStrategy = "merge"
+)
+
+// ImportStrategies lists the strategies ImportTables accepts.
+func ImportStrategies() []ImportStrategy {
+	return []ImportStrategy{ImportSkip, ImportOverwrite, ImportMerge}
+}
+
+// ImportError lists the rows that refer to records that neither the import
+// nor the database has.
+type ImportError struct {
+	Problems []string
+}
+
+func (e *ImportError) Error() string {
+	return fmt.Sprintf("import refers to missing records (%d problem(s)): %s",
+		len(e.Problems), strings.Join(e.Problems, "; "))
+}
+
+// ImportedTable counts what ImportTables did with one table's rows.
+type ImportedTable struct {
+	Name                      string
+	Added, Updated, Unchanged int
+}
+
+// importRow is one parsed row, with the columns that had a value.
+type importRow struct {
+	value  reflect.Value
+	filled []string
+}
+
+// ImportTables loads tables written by "webcasa export tables" back in,
+// keeping their IDs, so that an export restores onto another machine. Rows
+// whose ID is new are added; the rest are handled by strategy. Every
+// reference to another record must resolve to a row in the import or the
+// database, or nothing is loaded and an *ImportError lists the ones that
+// don't. It all happens in one transaction.
+func (s *Store) ImportTables(tables []DumpedTable, strategy ImportStrategy) ([]ImportedTable, error) {
+	if !slices.Contains(ImportStrategies(), strategy) {
+		return nil, fmt.Errorf("unknown import strategy %q", strategy)
+	}
+	// Load in dump order, so rows come after the ones they refer to.
+	byName := make(map[string]DumpedTable, len(tables))
+	for _, t := range tables {
+		if !slices.Contains(DumpTableNames(), t.Name) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownEntity, t.Name)
+		}
+		if _, dup := byName[t.Name]; dup {
+			return nil, fmt.Errorf("table %s appears twice", t.Name)
+		}
+		byName[t.Name] = t
+	}
+
+	type parsed struct {
+		spec   dumpTable
+		schema *schema.Schema
+		rows   []importRow
+	}
+	var work []parsed
+	imported := make(map[string]map[uint]bool)
+	for _, spec := range dumpTables {
+		t, ok := byName[spec.name]
+		if !ok {
+			continue
+		}
+		stmt := &gorm.Statement{DB: s.db}
+		if err := stmt.Parse(spec.model); err != nil {
+			return nil, fmt.Errorf("parse model: %w", err)
+		}
+		rows, err := parseImportRows(stmt.Schema, t)
+		if err != nil {
+			return nil, err
+		}
+		ids := make(map[uint]bool, len(rows))
+		for _, row := range rows {
+			ids[rowID(row.value)] = true
+		}
+		imported[stmt.Schema.Table] = ids
+		work = append(work, parsed{spec: spec, schema: stmt.Schema, rows: rows})
+	}
+
+	var problems []string
+	existing := make(map[string]map[uint]bool)
+	for _, p := range work {
+		for _, rel := range p.schema.Relationships.Relations {
+			if rel.Type != schema.BelongsTo || len(rel.References) != 1 {
+				continue
+			}
+			fk := rel.References[0].ForeignKey
+			table := rel.FieldSchema.Table
+			if existing[table] == nil {
+				ids, err := s.existingIDs(table)
+				if err != nil {
+					return nil, err
+				}
+				existing[table] = ids
+			}
+			for _, row := range p.rows {
+				v, zero := fk.ValueOf(context.Background(), row.value)
+				if zero {
+					if fk.FieldType.Kind() != reflect.Pointer {
+						problems = append(problems, fmt.Sprintf("%s #%d has no %s", p.spec.name, rowID(row.value), fk.DBName))
+					}
+					continue
+				}
+				id := toUint(plainValue(v))
+				if !imported[table][id] && !existing[table][id] {
+					problems = append(problems, fmt.Sprintf("%s #%d: %s %d doesn't exist",
+						p.spec.name, rowID(row.value), fk.DBName, id))
+				}
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return nil, &ImportError{Problems: problems}
+	}
+
+	var results []ImportedTable
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for _, p := range work {
+			res := ImportedTable{Name: p.spec.name}
+			for _, row := range p.rows {
+				added, updated, err := importRowInto(tx, p.spec, p.schema, row, strategy)
+				if err != nil {
+					return fmt.Errorf("%s #%d: %w", p.spec.name, rowID(row.value), err)
+				}
+				switch {
+				case added:
+					res.Added++
+				case updated:
+					res.Updated++
+				default:
+					res.Unchanged++
+				}
+			}
+			results = append(results, res)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// importRowInto writes one row, and reports whether it was added or an
+// existing row updated.
+func importRowInto(
+	tx *gorm.DB, spec dumpTable, sch *schema.Schema, row importRow, strategy ImportStrategy,
+) (added, updated bool, err error) {
+	obj := row.value.Addr().Interface()
+	if spec.name == "house" {
+		// There's one active house profile, whatever its ID.
+		var ids []uint
+		if err := tx.Table(sch.Table).Scopes(activeHouse).Limit(1).Pluck(ColID, &ids).Error; err != nil {
+			return false, false, err
+		}
+		if len(ids) > 0 {
+			row.value.FieldByName("ID").SetUint(uint64(ids[0]))
+		}
+	}
+	var count int64
+	if err := tx.Table(sch.Table).Where(ColID+" = ?", rowID(row.value)).Count(&count).Error; err != nil {
+		return false, false, err
+	}
+	if count == 0 {
+		return true, false, tx.Omit(clause.Associations).Create(obj).Error
+	}
+	if strategy == ImportSkip {
+		return false, false, nil
+	}
+
+	columns := make(map[string]any)
+	ctx := context.Background()
+	for _, f := range sch.Fields {
+		if f.DBName == "" || f.DBName == ColID {
+			continue
+		}
+		if strategy == ImportMerge && !slices.Contains(row.filled, f.DBName) {
+			continue
+		}
+		v, _ := f.ValueOf(ctx, row.value)
+		columns[f.DBName] = v
+	}
+	if len(columns) == 0 {
+		return false, false, nil
+	}
+	err = tx.Unscoped().Model(obj).UpdateColumns(columns).Error
+	return false, err == nil, err
+}
+
+func (s *Store) existingIDs(table string) (map[uint]bool, error) {
+	var ids []uint
+	if err := s.db.Table(table).Pluck(ColID, &ids).Error; err != nil {
+		return nil, fmt.Errorf("load %s ids: %w", table, err)
+	}
+	out := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		out[id] = true
+	}
+	return out, nil
+}
+
+// parseImportRows turns a dumped table's cells back into model values.
+func parseImportRows(sch *schema.Schema, t DumpedTable) ([]importRow, error) {
+	fields := make([]*schema.Field, len(t.Columns))
+	for i, col := range t.Columns {
+		f := sch.LookUpField(col)
+		if f == nil || f.DBName == "" {
+			return nil, fmt.Errorf("%s: unknown column %q", t.Name, col)
+		}
+		fields[i] = f
+	}
+	if !slices.Contains(t.Columns, ColID) {
+		return nil, fmt.Errorf("%s: no %s column", t.Name, ColID)
+	}
+	rows := make([]importRow, 0, len(t.Rows))
+	for r, cells := range t.Rows {
+		if len(cells) != len(fields) {
+			return nil, fmt.Errorf("%s row %d: %d cells for %d columns", t.Name, r+1, len(cells), len(fields))
+		}
+		row := importRow{value: reflect.New(sch.ModelType).Elem()}
+		for i, f := range fields {
+			if isBlank(cells[i]) {
+				continue
+			}
+			if err := setImported(row.value.FieldByIndex(f.StructField.Index), cells[i]); err != nil {
+				return nil, fmt.Errorf("%s row %d: %s: %w", t.Name, r+1, f.DBName, err)
+			}
+			row.filled = append(row.filled, f.DBName)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func isBlank(v any) bool {
+	return v == nil || v == ""
+}
+
+var deletedAtType = reflect.TypeFor[gorm.DeletedAt]()
+
+// setImported sets a model field from a CSV string or a decoded JSON value.
+func setImported(field reflect.Value, raw any) error {
+	if field.Type() == deletedAtType {
+		t, err := importTime(raw)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(gorm.DeletedAt{Time: t, Valid: true}))
+		return nil
+	}
+	if field.Kind() == reflect.Pointer {
+		v := reflect.New(field.Type().Elem())
+		if err := setImported(v.Elem(), raw); err != nil {
+			return err
+		}
+		field.Set(v)
+		return nil
+	}
+	if field.Type() == reflect.TypeFor[time.Time]() {
+		t, err := importTime(raw)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	text := fmt.Sprint(raw)
+	if n, ok := raw.(json.Number); ok {
+		text = n.String()
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(text)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(text, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("can't import a %s", field.Type())
+	}
+	return nil
+}
+
+// importTime reads a time as CSV exports write it, in UTC, or as JSON does.
+func importTime(raw any) (time.Time, error) {
+	if t, ok := raw.(time.Time); ok {
+		return t, nil
+	}
+	text, ok := raw.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("want a time, got %v", raw)
+	}
+	for _, layout := range []string{time.RFC3339Nano, time.DateTime, time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, text, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("want a time like 2006-01-02 15:04:05, got " + strconv.Quote(text))
+}
+
+func rowID(v reflect.Value) uint {
+	return uint(v.FieldByName("ID").Uint())
+}
+
+func toUint(v any) uint {
+	return uint(reflect.ValueOf(v).Uint())
+}
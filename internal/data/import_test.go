@@ -0,0 +1,118 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportTablesRoundTrip(t *testing.T) {
+	src := newTestStore(t)
+	types, err := src.ProjectTypes()
+	require.NoError(t, err)
+	categories, err := src.MaintenanceCategories()
+	require.NoError(t, err)
+	vendor := Vendor{Name: "Acme Roofing", Phone: "555-0100"}
+	require.NoError(t, src.CreateVendor(&vendor))
+	roof := Project{Title: "Roof", ProjectTypeID: types[0].ID, Status: ProjectStatusPlanned}
+	require.NoError(t, src.CreateProject(&roof))
+	require.NoError(t, src.CreateQuote(&Quote{ProjectID: roof.ID, TotalCents: 1_200_000}, vendor))
+	serviced := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	gutters := MaintenanceItem{
+		Name: "Gutters", CategoryID: categories[0].ID, LastServicedAt: &serviced, IntervalMonths: 6,
+	}
+	require.NoError(t, src.CreateMaintenance(&gutters))
+	gone := Vendor{Name: "Old Plumbing"}
+	require.NoError(t, src.CreateVendor(&gone))
+	require.NoError(t, src.DeleteVendor(gone.ID))
+
+	var tables []DumpedTable
+	for _, name := range DumpTableNames() {
+		table, err := src.DumpTable(name, DumpOptions{IncludeDeleted: true})
+		require.NoError(t, err)
+		tables = append(tables, table)
+	}
+
+	dst := newTestStore(t)
+	results, err := dst.ImportTables(tables, ImportSkip)
+	require.NoError(t, err)
+	require.Len(t, results, len(DumpTableNames()))
+	assert.Equal(t, ImportedTable{Name: "quotes", Added: 1}, results[slices.IndexFunc(results,
+		func(r ImportedTable) bool { return r.Name == "quotes" })])
+
+	got, err := dst.GetMaintenance(gutters.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.NextDueAt, "the due date is worked out again")
+	assert.True(t, time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC).Equal(*got.NextDueAt))
+	vendors, err := dst.ListVendors(true)
+	require.NoError(t, err)
+	require.Len(t, vendors, 2)
+	assert.Equal(t, "555-0100", vendors[0].Phone)
+	assert.True(t, vendors[1].DeletedAt.Valid, "the trash comes along")
+
+	// Importing again changes nothing, and skipping leaves local edits.
+	local, err := dst.GetProject(roof.ID)
+	require.NoError(t, err)
+	local.Description = "local notes"
+	require.NoError(t, dst.UpdateProject(local))
+	results, err = dst.ImportTables(tables, ImportSkip)
+	require.NoError(t, err)
+	for _, r := range results {
+		assert.Zero(t, r.Added+r.Updated, r.Name)
+	}
+	local, err = dst.GetProject(roof.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "local notes", local.Description)
+
+	// Merging keeps the local value where the import has none.
+	local.Title = "Roof, local"
+	require.NoError(t, dst.UpdateProject(local))
+	_, err = dst.ImportTables(tables, ImportMerge)
+	require.NoError(t, err)
+	local, err = dst.GetProject(roof.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Roof", local.Title)
+	assert.Equal(t, "local notes", local.Description)
+
+	// Overwriting replaces it everywhere.
+	_, err = dst.ImportTables(tables, ImportOverwrite)
+	require.NoError(t, err)
+	local, err = dst.GetProject(roof.ID)
+	require.NoError(t, err)
+	assert.Empty(t, local.Description)
+}
+
+func TestImportTablesChecksReferences(t *testing.T) {
+	store := newTestStore(t)
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	quotes := DumpedTable{
+		Name:    "quotes",
+		Columns: []string{ColID, "project_id", ColVendorID, "total_cents"},
+		Rows:    [][]any{{"1", "7", "3", "5000"}},
+	}
+	projects := DumpedTable{
+		Name:    "projects",
+		Columns: []string{ColID, "title", "project_type_id", "status"},
+		Rows:    [][]any{{"7", "Deck", types[0].ID, ProjectStatusPlanned}},
+	}
+
+	_, err = store.ImportTables([]DumpedTable{quotes, projects}, ImportSkip)
+	var importErr *ImportError
+	require.ErrorAs(t, err, &importErr)
+	assert.Equal(t, []string{"quotes #1: vendor_id 3 doesn't exist"}, importErr.Problems)
+	list, err := store.ListProjects(true)
+	require.NoError(t, err)
+	assert.Empty(t, list, "nothing is loaded")
+
+	_, err = store.ImportTables([]DumpedTable{{Name: "projects", Columns: []string{ColID, "colour"}}}, ImportSkip)
+	assert.ErrorContains(t, err, `unknown column "colour"`)
+	_, err = store.ImportTables(nil, "replace")
+	assert.ErrorContains(t, err, "unknown import strategy")
+}
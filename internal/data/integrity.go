@@ -0,0 +1,94 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// integrityProblems caps how many problems quick_check reports.
+const integrityProblems = 100
+
+// IntegrityError lists what a check found wrong with the database.
+type IntegrityError struct {
+	Problems []string
+}
+
+func (e *IntegrityError) Error() string {
+	return fmt.Sprintf("database is damaged (%d problem(s)): %s",
+		len(e.Problems), strings.Join(e.Problems, "; "))
+}
+
+// CheckIntegrity runs SQLite's quick_check, then reads every document back
+// and compares it with the checksum stored when it was uploaded. It returns
+// an *IntegrityError when either finds damage. Both read the whole file, so
+// this takes a while on a large database.
+func (s *Store) CheckIntegrity() error {
+	var problems []string
+	var rows []string
+	if err := s.db.Raw(fmt.Sprintf("PRAGMA quick_check(%d)", integrityProblems)).
+		Scan(&rows).Error; err != nil {
+		return fmt.Errorf("run quick_check: %w", err)
+	}
+	for _, row := range rows {
+		if row != "ok" {
+			problems = append(problems, row)
+		}
+	}
+
+	// A new database has no documents table until it is migrated, and one
+	// quick_check found damaged may not read at all.
+	if s.db.Migrator().HasTable(&Document{}) {
+		docProblems, err := s.checkDocuments()
+		if err != nil && len(problems) == 0 {
+			return err
+		}
+		problems = append(problems, docProblems...)
+	}
+
+	if len(problems) > 0 {
+		return &IntegrityError{Problems: problems}
+	}
+	return nil
+}
+
+// checkDocuments compares each document's content with its stored
+// checksum.
+func (s *Store) checkDocuments() ([]string, error) {
+	var docs []Document
+	if err := s.db.Select(ColID, ColChecksum).
+		Where(ColChecksum + " != ''").Order(ColID).Find(&docs).Error; err != nil {
+		return nil, fmt.Errorf("list document checksums: %w", err)
+	}
+	var problems []string
+	for _, d := range docs {
+		got, err := s.documentChecksum(d.ID)
+		switch {
+		case errors.Is(err, ErrNoContent):
+			problems = append(problems, fmt.Sprintf("document #%d has lost its file", d.ID))
+		case err != nil:
+			problems = append(problems, fmt.Sprintf("document #%d can't be read: %v", d.ID, err))
+		case got != d.ChecksumSHA256:
+			problems = append(problems, fmt.Sprintf("document #%d doesn't match its checksum", d.ID))
+		}
+	}
+	return problems, nil
+}
+
+func (s *Store) documentChecksum(id uint) (string, error) {
+	r, err := s.OpenDocument(id)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = r.Close() }()
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
@@ -0,0 +1,39 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckIntegrity(t *testing.T) {
+	store := newTestStore(t)
+	content := []byte("deed of sale")
+	deed := Document{
+		Title: "Deed", FileName: "deed.pdf", Data: content,
+		ChecksumSHA256: fmt.Sprintf("%x", sha256.Sum256(content)),
+	}
+	require.NoError(t, store.CreateDocument(&deed))
+	unchecked := Document{Title: "Note", FileName: "note.txt", Data: []byte("x")}
+	require.NoError(t, store.CreateDocument(&unchecked))
+
+	require.NoError(t, store.CheckIntegrity())
+
+	require.NoError(t, store.db.Model(&Document{}).Where(ColID+" = ?", deed.ID).
+		UpdateColumn(ColData, []byte("deed of theft")).Error)
+	err := store.CheckIntegrity()
+	var damaged *IntegrityError
+	require.ErrorAs(t, err, &damaged)
+	assert.Equal(t, []string{fmt.Sprintf("document #%d doesn't match its checksum", deed.ID)}, damaged.Problems)
+
+	require.NoError(t, store.db.Model(&Document{}).Where(ColID+" = ?", deed.ID).
+		UpdateColumn(ColData, nil).Error)
+	require.ErrorAs(t, store.CheckIntegrity(), &damaged)
+	assert.Contains(t, damaged.Problems[0], "lost its file")
+}
@@ -0,0 +1,161 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InventoryItem is something of value kept in the house that isn't an
+// appliance -- jewelry, a TV, art, tools -- recorded for the insurer: what
+// it is, where it is, what it cost and what replacing it would cost now.
+// Photos are documents attached to it.
+type InventoryItem struct {
+	ID           uint  `gorm:"primaryKey"`
+	HouseID      *uint `gorm:"index"`
+	Name         string
+	Category     string
+	Brand        string
+	ModelNumber  string
+	SerialNumber string
+	RoomID       *uint `gorm:"index"`
+	Room         Room  `gorm:"constraint:OnDelete:SET NULL;"`
+	PurchaseDate *time.Time
+	// PurchaseCents is what it cost; ReplacementCents is what buying it
+	// again would cost today.
+	PurchaseCents    *int64
+	ReplacementCents *int64
+	Notes            string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Version          int            `gorm:"not null;default:1"`
+	DeletedAt        gorm.DeletedAt `gorm:"index"`
+}
+
+// ValueCents is what the item is worth to an insurer: its replacement
+// value, or what it cost when that isn't known.
+func (it InventoryItem) ValueCents() int64 {
+	switch {
+	case it.ReplacementCents != nil:
+		return *it.ReplacementCents
+	case it.PurchaseCents != nil:
+		return *it.PurchaseCents
+	}
+	return 0
+}
+
+// InventoryRoom is the inventory of one room, or of no room in particular
+// when RoomID is nil.
+type InventoryRoom struct {
+	RoomID *uint
+	Name   string
+	Items  []InventoryItem
+	// ReplacementCents adds up the items' values.
+	ReplacementCents int64
+}
+
+func (s *Store) ListInventory(includeDeleted bool) ([]InventoryItem, error) {
+	var items []InventoryItem
+	db := s.db.Preload("Room", func(q *gorm.DB) *gorm.DB { return q.Unscoped() }).
+		Scopes(s.inHouse("inventory_items")).
+		Order(ColName + ", " + ColID)
+	if includeDeleted {
+		db = db.Unscoped()
+	}
+	return items, db.Find(&items).Error
+}
+
+func (s *Store) GetInventoryItem(id uint) (InventoryItem, error) {
+	var item InventoryItem
+	err := s.db.Preload("Room", func(q *gorm.DB) *gorm.DB { return q.Unscoped() }).
+		First(&item, id).Error
+	return item, err
+}
+
+func (s *Store) CreateInventoryItem(item *InventoryItem) error {
+	if err := s.validateInventoryItem(item); err != nil {
+		return err
+	}
+	return s.db.Create(item).Error
+}
+
+func (s *Store) UpdateInventoryItem(item InventoryItem) error {
+	if err := s.validateInventoryItem(&item); err != nil {
+		return err
+	}
+	return s.updateByID(&InventoryItem{}, item.ID, item)
+}
+
+func (s *Store) DeleteInventoryItem(id uint) error {
+	return s.softDelete(&InventoryItem{}, DeletionEntityInventory, id)
+}
+
+func (s *Store) RestoreInventoryItem(id uint) error {
+	var item InventoryItem
+	if err := s.db.Unscoped().First(&item, id).Error; err != nil {
+		return err
+	}
+	if item.RoomID != nil {
+		if err := s.requireParentAlive(&Room{}, *item.RoomID); err != nil {
+			return parentRestoreError("room", err)
+		}
+	}
+	return s.restoreEntity(&InventoryItem{}, DeletionEntityInventory, id)
+}
+
+// InventoryByRoom groups the current house's inventory by room, rooms in
+// name order and the items not in a room last, each with its total value.
+func (s *Store) InventoryByRoom() ([]InventoryRoom, error) {
+	items, err := s.ListInventory(false)
+	if err != nil {
+		return nil, err
+	}
+	var rooms []InventoryRoom
+	var unplaced InventoryRoom
+	for _, it := range items {
+		group := &unplaced
+		if it.RoomID != nil {
+			i := slices.IndexFunc(rooms, func(r InventoryRoom) bool { return *r.RoomID == *it.RoomID })
+			if i < 0 {
+				rooms = append(rooms, InventoryRoom{RoomID: it.RoomID, Name: it.Room.Name})
+				i = len(rooms) - 1
+			}
+			group = &rooms[i]
+		}
+		group.Items = append(group.Items, it)
+		group.ReplacementCents += it.ValueCents()
+	}
+	slices.SortStableFunc(rooms, func(a, b InventoryRoom) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(*a.RoomID, *b.RoomID))
+	})
+	if len(unplaced.Items) > 0 {
+		rooms = append(rooms, unplaced)
+	}
+	return rooms, nil
+}
+
+func (s *Store) validateInventoryItem(item *InventoryItem) error {
+	item.Name = strings.TrimSpace(item.Name)
+	item.Category = strings.TrimSpace(item.Category)
+	if item.Name == "" {
+		return fmt.Errorf("an inventory item needs a name")
+	}
+	for _, c := range []*int64{item.PurchaseCents, item.ReplacementCents} {
+		if c != nil && *c < 0 {
+			return fmt.Errorf("purchase price and replacement value can't be negative")
+		}
+	}
+	if item.RoomID != nil {
+		if err := s.requireParentAlive(&Room{}, *item.RoomID); err != nil {
+			return fmt.Errorf("room not found or deleted")
+		}
+	}
+	return nil
+}
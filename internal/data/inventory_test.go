@@ -0,0 +1,49 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInventoryByRoom(t *testing.T) {
+	store := newTestStore(t)
+	cents := func(c int64) *int64 { return &c }
+
+	office := Room{Name: "Office"}
+	require.NoError(t, store.CreateRoom(&office))
+	bedroom := Room{Name: "Bedroom"}
+	require.NoError(t, store.CreateRoom(&bedroom))
+
+	require.ErrorContains(t, store.CreateInventoryItem(&InventoryItem{Name: " "}), "needs a name")
+	require.ErrorContains(t, store.CreateInventoryItem(&InventoryItem{Name: "Ring", ReplacementCents: cents(-1)}), "can't be negative")
+
+	laptop := InventoryItem{Name: "Laptop", RoomID: &office.ID, PurchaseCents: cents(200_000), ReplacementCents: cents(150_000)}
+	require.NoError(t, store.CreateInventoryItem(&laptop))
+	require.NoError(t, store.CreateInventoryItem(&InventoryItem{Name: "Monitor", RoomID: &office.ID, PurchaseCents: cents(40_000)}))
+	require.NoError(t, store.CreateInventoryItem(&InventoryItem{Name: "Ring", RoomID: &bedroom.ID, ReplacementCents: cents(500_000)}))
+	require.NoError(t, store.CreateInventoryItem(&InventoryItem{Name: "Bike"}))
+
+	rooms, err := store.InventoryByRoom()
+	require.NoError(t, err)
+	require.Len(t, rooms, 3)
+	assert.Equal(t, "Bedroom", rooms[0].Name)
+	assert.Equal(t, int64(500_000), rooms[0].ReplacementCents)
+	assert.Equal(t, "Office", rooms[1].Name)
+	assert.Len(t, rooms[1].Items, 2)
+	assert.Equal(t, int64(190_000), rooms[1].ReplacementCents, "the monitor counts at what it cost")
+	assert.Nil(t, rooms[2].RoomID)
+	assert.Equal(t, "Bike", rooms[2].Items[0].Name)
+
+	require.ErrorContains(t, store.DeleteRoom(office.ID), "2 active inventory item(s)")
+
+	require.NoError(t, store.DeleteInventoryItem(laptop.ID))
+	rooms, err = store.InventoryByRoom()
+	require.NoError(t, err)
+	assert.Equal(t, int64(40_000), rooms[1].ReplacementCents)
+	require.NoError(t, store.RestoreInventoryItem(laptop.ID))
+}
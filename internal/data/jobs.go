@@ -0,0 +1,55 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JobRun records the last run of a named background job, so schedules
+// survive restarts.
+type JobRun struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"uniqueIndex"`
+	LastRunAt time.Time
+	// LastSuccessAt is the last run that finished without error.
+	LastSuccessAt *time.Time
+	LastError     string
+	DurationMS    int64
+	UpdatedAt     time.Time
+}
+
+// LastJobRun returns the last recorded run of the named job, or nil if it
+// has never run.
+func (s *Store) LastJobRun(name string) (*JobRun, error) {
+	var run JobRun
+	err := s.db.Where(ColName+" = ?", name).First(&run).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// RecordJobRun stores the outcome of a job run, replacing the previous one.
+func (s *Store) RecordJobRun(name string, at time.Time, took time.Duration, runErr error) error {
+	run := JobRun{Name: name, LastRunAt: at, DurationMS: took.Milliseconds()}
+	update := []string{ColLastRunAt, "last_error", "duration_ms", ColUpdatedAt}
+	if runErr != nil {
+		run.LastError = runErr.Error()
+	} else {
+		run.LastSuccessAt = &at
+		update = append(update, "last_success_at")
+	}
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: ColName}},
+		DoUpdates: clause.AssignmentColumns(update),
+	}).Create(&run).Error
+}
@@ -0,0 +1,185 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Landscape asset kinds.
+const (
+	LandscapeKindTree           = "tree"
+	LandscapeKindShrub          = "shrub"
+	LandscapeKindBed            = "bed"
+	LandscapeKindLawn           = "lawn"
+	LandscapeKindIrrigationZone = "irrigation_zone"
+	LandscapeKindFence          = "fence_section"
+	LandscapeKindOther          = "other"
+)
+
+// LandscapeKinds returns the valid landscape asset kinds in display order.
+func LandscapeKinds() []string {
+	return []string{
+		LandscapeKindTree,
+		LandscapeKindShrub,
+		LandscapeKindBed,
+		LandscapeKindLawn,
+		LandscapeKindIrrigationZone,
+		LandscapeKindFence,
+		LandscapeKindOther,
+	}
+}
+
+// landscapeMaintenanceCategory is the seeded category care tasks are filed
+// under, so they show up alongside the rest of the outdoor maintenance.
+const landscapeMaintenanceCategory = "Landscaping"
+
+// LandscapeAsset is an outdoor feature that needs periodic care: a tree, an
+// irrigation zone, a run of fence. Care tasks are ordinary maintenance items
+// linked back to the asset, so they are scheduled like indoor work.
+type LandscapeAsset struct {
+	ID          uint  `gorm:"primaryKey"`
+	HouseID     *uint `gorm:"index"`
+	Name        string
+	Kind        string
+	Species     string
+	Location    string
+	PlantedDate *time.Time
+	Notes       string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Version     int            `gorm:"not null;default:1"`
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+func (s *Store) ListLandscapeAssets(includeDeleted bool) ([]LandscapeAsset, error) {
+	var items []LandscapeAsset
+	db := s.db.Scopes(s.inHouse("landscape_assets")).Order(ColKind + ", " + ColName + ", " + ColID)
+	if includeDeleted {
+		db = db.Unscoped()
+	}
+	if err := db.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (s *Store) GetLandscapeAsset(id uint) (LandscapeAsset, error) {
+	var item LandscapeAsset
+	err := s.db.First(&item, id).Error
+	return item, err
+}
+
+func (s *Store) CreateLandscapeAsset(item *LandscapeAsset) error {
+	if err := validateLandscapeAsset(item); err != nil {
+		return err
+	}
+	return s.db.Create(item).Error
+}
+
+func (s *Store) UpdateLandscapeAsset(item LandscapeAsset) error {
+	if err := validateLandscapeAsset(&item); err != nil {
+		return err
+	}
+	return s.updateByID(&LandscapeAsset{}, item.ID, item)
+}
+
+func (s *Store) DeleteLandscapeAsset(id uint) error {
+	n, err := s.countDependents(&MaintenanceItem{}, ColLandscapeAssetID, id)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return fmt.Errorf(
+			"landscape asset has %d active care task(s) -- delete or reassign them first",
+			n,
+		)
+	}
+	return s.softDelete(&LandscapeAsset{}, DeletionEntityLandscape, id)
+}
+
+func (s *Store) RestoreLandscapeAsset(id uint) error {
+	return s.restoreEntity(&LandscapeAsset{}, DeletionEntityLandscape, id)
+}
+
+// ListMaintenanceByLandscapeAsset returns the care tasks for an asset.
+func (s *Store) ListMaintenanceByLandscapeAsset(
+	assetID uint,
+	includeDeleted bool,
+) ([]MaintenanceItem, error) {
+	var items []MaintenanceItem
+	db := s.db.Preload("Category").
+		Where(ColLandscapeAssetID+" = ?", assetID).
+		Order(ColUpdatedAt + " desc, " + ColID + " desc")
+	if includeDeleted {
+		db = db.Unscoped()
+	}
+	if err := db.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// CountMaintenanceByLandscapeAsset returns the count of non-deleted care
+// tasks for each landscape asset ID.
+func (s *Store) CountMaintenanceByLandscapeAsset(assetIDs []uint) (map[uint]int, error) {
+	return s.countByFK(&MaintenanceItem{}, ColLandscapeAssetID, assetIDs)
+}
+
+// AddLandscapeCare creates a recurring care task for an asset, e.g.
+// ("Fertilize", 12) on "Maple" becomes "Fertilize Maple" every year. The
+// task lands in the Landscaping category and follows the normal overdue and
+// upcoming rules. item may carry optional fields (notes, cost, last
+// serviced); its name, category, and link are set here.
+func (s *Store) AddLandscapeCare(assetID uint, task string, item *MaintenanceItem) error {
+	task = strings.TrimSpace(task)
+	if task == "" {
+		return fmt.Errorf("care task name is required")
+	}
+	if item.IntervalMonths <= 0 {
+		return fmt.Errorf("care task interval must be positive, got %d", item.IntervalMonths)
+	}
+	asset, err := s.GetLandscapeAsset(assetID)
+	if err != nil {
+		return err
+	}
+	var cat MaintenanceCategory
+	err = s.db.Where(ColName+" = ?", landscapeMaintenanceCategory).First(&cat).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf(
+			"maintenance category %q not found -- run SeedDefaults first",
+			landscapeMaintenanceCategory,
+		)
+	}
+	if err != nil {
+		return err
+	}
+	item.Name = task + " " + asset.Name
+	item.CategoryID = cat.ID
+	item.LandscapeAssetID = &asset.ID
+	return s.db.Create(item).Error
+}
+
+func validateLandscapeAsset(item *LandscapeAsset) error {
+	if strings.TrimSpace(item.Name) == "" {
+		return fmt.Errorf("landscape asset name is required")
+	}
+	if item.Kind == "" {
+		item.Kind = LandscapeKindOther
+	}
+	for _, k := range LandscapeKinds() {
+		if item.Kind == k {
+			return nil
+		}
+	}
+	return fmt.Errorf(
+		"invalid landscape kind %q -- expected one of %s",
+		item.Kind, strings.Join(LandscapeKinds(), ", "),
+	)
+}
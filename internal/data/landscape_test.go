@@ -0,0 +1,92 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLandscapeAssetCRUD(t *testing.T) {
+	store := newTestStore(t)
+	planted := time.Date(2019, 4, 20, 0, 0, 0, 0, time.UTC)
+	maple := LandscapeAsset{
+		Name: "Maple", Kind: LandscapeKindTree, Species: "Acer rubrum",
+		Location: "Front yard", PlantedDate: &planted,
+	}
+	require.NoError(t, store.CreateLandscapeAsset(&maple))
+
+	maple.Location = "Front yard, north corner"
+	require.NoError(t, store.UpdateLandscapeAsset(maple))
+
+	got, err := store.GetLandscapeAsset(maple.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Front yard, north corner", got.Location)
+	assert.Equal(t, "Acer rubrum", got.Species)
+}
+
+func TestLandscapeAssetKindValidation(t *testing.T) {
+	store := newTestStore(t)
+	assert.Error(t, store.CreateLandscapeAsset(&LandscapeAsset{Name: "X", Kind: "volcano"}))
+	assert.Error(t, store.CreateLandscapeAsset(&LandscapeAsset{Kind: LandscapeKindTree}))
+
+	blank := LandscapeAsset{Name: "Rock garden"}
+	require.NoError(t, store.CreateLandscapeAsset(&blank))
+	assert.Equal(t, LandscapeKindOther, blank.Kind)
+}
+
+func TestAddLandscapeCareSchedulesMaintenance(t *testing.T) {
+	store := newTestStore(t)
+	zone := LandscapeAsset{Name: "Zone 3", Kind: LandscapeKindIrrigationZone}
+	require.NoError(t, store.CreateLandscapeAsset(&zone))
+
+	care := MaintenanceItem{IntervalMonths: 12, Notes: "Blow out lines"}
+	require.NoError(t, store.AddLandscapeCare(zone.ID, "Winterize", &care))
+
+	item, err := store.GetMaintenance(care.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Winterize Zone 3", item.Name)
+	assert.Equal(t, landscapeMaintenanceCategory, item.Category.Name)
+	assert.Equal(t, "Zone 3", item.LandscapeAsset.Name)
+
+	// Care tasks are ordinary scheduled maintenance.
+	scheduled, err := store.ListMaintenanceWithSchedule()
+	require.NoError(t, err)
+	require.Len(t, scheduled, 1)
+	assert.Equal(t, care.ID, scheduled[0].ID)
+
+	tasks, err := store.ListMaintenanceByLandscapeAsset(zone.ID, false)
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1)
+}
+
+func TestAddLandscapeCareValidation(t *testing.T) {
+	store := newTestStore(t)
+	tree := LandscapeAsset{Name: "Oak", Kind: LandscapeKindTree}
+	require.NoError(t, store.CreateLandscapeAsset(&tree))
+
+	assert.Error(t, store.AddLandscapeCare(tree.ID, " ", &MaintenanceItem{IntervalMonths: 6}))
+	assert.Error(t, store.AddLandscapeCare(tree.ID, "Prune", &MaintenanceItem{}))
+	assert.Error(t, store.AddLandscapeCare(9999, "Prune", &MaintenanceItem{IntervalMonths: 6}))
+}
+
+func TestDeleteLandscapeAssetBlockedByCareTasks(t *testing.T) {
+	store := newTestStore(t)
+	tree := LandscapeAsset{Name: "Maple", Kind: LandscapeKindTree}
+	require.NoError(t, store.CreateLandscapeAsset(&tree))
+	care := MaintenanceItem{IntervalMonths: 12}
+	require.NoError(t, store.AddLandscapeCare(tree.ID, "Fertilize", &care))
+
+	require.ErrorContains(t, store.DeleteLandscapeAsset(tree.ID), "active care task")
+
+	require.NoError(t, store.DeleteMaintenance(care.ID))
+	require.NoError(t, store.DeleteLandscapeAsset(tree.ID))
+
+	require.ErrorContains(t, store.RestoreMaintenance(care.ID), "landscape asset is deleted")
+	require.NoError(t, store.RestoreLandscapeAsset(tree.ID))
+	require.NoError(t, store.RestoreMaintenance(care.ID))
+}
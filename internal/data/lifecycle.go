@@ -0,0 +1,276 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// House lifecycle event kinds.
+const (
+	HouseEventPurchased = "purchased"
+	HouseEventMovedIn   = "moved_in"
+	HouseEventListed    = "listed"
+	HouseEventSold      = "sold"
+	HouseEventMovedOut  = "moved_out"
+)
+
+// HouseEventKinds lists the lifecycle event kinds in the order they
+// usually happen.
+func HouseEventKinds() []string {
+	return []string{HouseEventPurchased, HouseEventMovedIn, HouseEventListed, HouseEventSold, HouseEventMovedOut}
+}
+
+// HouseEvent is a milestone in owning a house: buying it, moving in,
+// listing it, selling it, moving out. Recording one generates a checklist
+// of the chores that come with it, due relative to OccurredOn.
+type HouseEvent struct {
+	ID             uint `gorm:"primaryKey"`
+	HouseProfileID uint `gorm:"index"`
+	Kind           string
+	OccurredOn     time.Time
+	Notes          string
+	Tasks          []HouseEventTask `gorm:"constraint:OnDelete:CASCADE;"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Version        int            `gorm:"not null;default:1"`
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
+}
+
+// HouseEventTask is one item on an event's checklist. Open tasks with a
+// due date show up with the other reminders on the dashboard.
+type HouseEventTask struct {
+	ID           uint `gorm:"primaryKey"`
+	HouseEventID uint `gorm:"index"`
+	Title        string
+	DueAt        *time.Time `gorm:"index"`
+	DoneAt       *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type checklistStep struct {
+	title string
+	days  int // relative to the event; negative is before it
+}
+
+// houseEventChecklists are the tasks generated for each kind of event.
+var houseEventChecklists = map[string][]checklistStep{
+	HouseEventPurchased: {
+		{"Set up homeowner's insurance", -7},
+		{"Rekey or replace the exterior locks", 1},
+		{"Change garage door and gate codes", 1},
+		{"Find the water shutoff, gas shutoff and breaker panel", 3},
+		{"Collect manuals and warranties from the seller", 7},
+		{"Test smoke and carbon monoxide detectors", 7},
+	},
+	HouseEventMovedIn: {
+		{"Transfer electricity into your name", -7},
+		{"Transfer gas and water", -7},
+		{"Set up internet", -7},
+		{"Forward mail to the new address", -3},
+		{"Update address with banks, employer and insurers", 7},
+		{"Update driver's license and vehicle registration", 30},
+		{"Register to vote at the new address", 30},
+	},
+	HouseEventListed: {
+		{"Gather manuals, warranties and service records for buyers", 0},
+		{"Resolve or disclose open incidents", 7},
+		{"Collect utility bills for the last year", 7},
+	},
+	HouseEventSold: {
+		{"Schedule final utility readings and shutoffs", -7},
+		{"Cancel or transfer internet and other services", -7},
+		{"Hand over keys, remotes and codes", 0},
+		{"Leave manuals and warranties for the new owners", 0},
+		{"Cancel homeowner's insurance once the sale closes", 1},
+		{"Archive the house in webcasa", 30},
+	},
+	HouseEventMovedOut: {
+		{"Forward mail to the new address", -7},
+		{"Take final meter photos", 0},
+		{"Return or cancel rented equipment", 7},
+	},
+}
+
+func houseEventTasksPreload(q *gorm.DB) *gorm.DB {
+	return q.Order(ColDueAt + " IS NULL, " + ColDueAt + ", " + ColID)
+}
+
+// ListHouseEvents returns the active house's lifecycle events with their
+// checklists, most recent first.
+func (s *Store) ListHouseEvents(includeDeleted bool) ([]HouseEvent, error) {
+	house, err := s.HouseProfile()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return []HouseEvent{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var events []HouseEvent
+	db := s.db.Preload("Tasks", houseEventTasksPreload).
+		Where(ColHouseProfileID+" = ?", house.ID).
+		Order(ColOccurredOn + " desc, " + ColID + " desc")
+	if includeDeleted {
+		db = db.Unscoped()
+	}
+	return events, db.Find(&events).Error
+}
+
+func (s *Store) GetHouseEvent(id uint) (HouseEvent, error) {
+	var event HouseEvent
+	err := s.db.Preload("Tasks", houseEventTasksPreload).First(&event, id).Error
+	return event, err
+}
+
+// CreateHouseEvent records an event for the active house and generates
+// its checklist.
+func (s *Store) CreateHouseEvent(event *HouseEvent) error {
+	if err := validateHouseEvent(event); err != nil {
+		return err
+	}
+	house, err := s.HouseProfile()
+	if err != nil {
+		return fmt.Errorf("set up the house profile first")
+	}
+	event.HouseProfileID = house.ID
+	event.Tasks = nil
+	for _, step := range houseEventChecklists[event.Kind] {
+		due := event.OccurredOn.AddDate(0, 0, step.days)
+		event.Tasks = append(event.Tasks, HouseEventTask{Title: step.title, DueAt: &due})
+	}
+	return s.db.Create(event).Error
+}
+
+// UpdateHouseEvent saves an event. Moving its date moves the due dates of
+// the tasks not yet done by the same number of days.
+func (s *Store) UpdateHouseEvent(event HouseEvent) error {
+	if err := validateHouseEvent(&event); err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var old HouseEvent
+		if err := tx.Preload("Tasks").First(&old, event.ID).Error; err != nil {
+			return err
+		}
+		event.HouseProfileID = old.HouseProfileID
+		event.Tasks = nil
+		if err := updateByIDWith(tx, &HouseEvent{}, event.ID, event); err != nil {
+			return err
+		}
+		shift := event.OccurredOn.Sub(old.OccurredOn)
+		if shift == 0 {
+			return nil
+		}
+		for _, task := range old.Tasks {
+			if task.DoneAt != nil || task.DueAt == nil {
+				continue
+			}
+			due := task.DueAt.Add(shift)
+			if err := tx.Model(&HouseEventTask{}).Where(ColID+" = ?", task.ID).
+				Update(ColDueAt, due).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Store) DeleteHouseEvent(id uint) error {
+	return s.softDelete(&HouseEvent{}, DeletionEntityHouseEvent, id)
+}
+
+func (s *Store) RestoreHouseEvent(id uint) error {
+	return s.restoreEntity(&HouseEvent{}, DeletionEntityHouseEvent, id)
+}
+
+// AddHouseEventTask adds a task of the user's own to an event's checklist.
+func (s *Store) AddHouseEventTask(task *HouseEventTask) error {
+	if err := s.requireParentAlive(&HouseEvent{}, task.HouseEventID); err != nil {
+		return fmt.Errorf("event not found or deleted")
+	}
+	task.Title = strings.TrimSpace(task.Title)
+	if task.Title == "" {
+		return fmt.Errorf("task title is required")
+	}
+	task.DoneAt = nil
+	return s.db.Create(task).Error
+}
+
+// SetHouseEventTaskDone ticks a task off, or back on when done is false.
+func (s *Store) SetHouseEventTaskDone(id uint, done bool, now time.Time) (HouseEventTask, error) {
+	var doneAt *time.Time
+	if done {
+		doneAt = &now
+	}
+	result := s.db.Model(&HouseEventTask{}).Where(ColID+" = ?", id).Update(ColDoneAt, doneAt)
+	if result.Error != nil {
+		return HouseEventTask{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return HouseEventTask{}, gorm.ErrRecordNotFound
+	}
+	var task HouseEventTask
+	return task, s.db.First(&task, id).Error
+}
+
+// SetHouseEventTaskDue moves a checklist task to due.
+func (s *Store) SetHouseEventTaskDue(id uint, due time.Time) (HouseEventTask, error) {
+	result := s.db.Model(&HouseEventTask{}).Where(ColID+" = ?", id).Update(ColDueAt, due)
+	if result.Error != nil {
+		return HouseEventTask{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return HouseEventTask{}, gorm.ErrRecordNotFound
+	}
+	var task HouseEventTask
+	return task, s.db.First(&task, id).Error
+}
+
+// RemoveHouseEventTask takes a task off its checklist.
+func (s *Store) RemoveHouseEventTask(id uint) error {
+	result := s.db.Delete(&HouseEventTask{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListHouseEventTasksDue returns the active house's open checklist tasks
+// due within horizon of now, overdue ones included, soonest first. Tasks
+// of deleted events are left out.
+func (s *Store) ListHouseEventTasksDue(now time.Time, horizon time.Duration) ([]HouseEventTask, error) {
+	house, err := s.HouseProfile()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var tasks []HouseEventTask
+	err = s.db.Joins("JOIN house_events ON house_events.id = house_event_tasks.house_event_id").
+		Where("house_events.deleted_at IS NULL AND house_events.house_profile_id = ?", house.ID).
+		Where("house_event_tasks.done_at IS NULL AND house_event_tasks.due_at <= ?", now.Add(horizon)).
+		Order("house_event_tasks.due_at, house_event_tasks." + ColID).
+		Find(&tasks).Error
+	return tasks, err
+}
+
+func validateHouseEvent(event *HouseEvent) error {
+	if !slices.Contains(HouseEventKinds(), event.Kind) {
+		return fmt.Errorf("unknown event kind %q -- use one of %s",
+			event.Kind, strings.Join(HouseEventKinds(), ", "))
+	}
+	if event.OccurredOn.IsZero() {
+		return fmt.Errorf("event date is required")
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHouseEventChecklist(t *testing.T) {
+	store := newTestStore(t)
+	closing := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	event := HouseEvent{Kind: HouseEventSold, OccurredOn: closing}
+	require.ErrorContains(t, store.CreateHouseEvent(&event), "house profile first")
+
+	require.NoError(t, store.CreateHouseProfile(HouseProfile{Nickname: "Elm Street"}))
+	require.ErrorContains(t, store.CreateHouseEvent(&HouseEvent{Kind: "foreclosed", OccurredOn: closing}), "unknown event kind")
+	require.NoError(t, store.CreateHouseEvent(&event))
+
+	got, err := store.GetHouseEvent(event.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Tasks, len(houseEventChecklists[HouseEventSold]))
+	first := got.Tasks[0]
+	assert.Equal(t, closing.AddDate(0, 0, -7), first.DueAt.UTC(), "tasks are ordered by due date")
+
+	// Two weeks before closing, the pre-closing tasks are due.
+	now := closing.AddDate(0, 0, -14)
+	due, err := store.ListHouseEventTasksDue(now, 7*24*time.Hour)
+	require.NoError(t, err)
+	assert.Len(t, due, 2)
+
+	_, err = store.SetHouseEventTaskDone(first.ID, true, now)
+	require.NoError(t, err)
+	due, err = store.ListHouseEventTasksDue(now, 7*24*time.Hour)
+	require.NoError(t, err)
+	assert.Len(t, due, 1, "done tasks aren't reminders")
+
+	// Closing slips a week; the open tasks move with it.
+	got.OccurredOn = closing.AddDate(0, 0, 7)
+	require.NoError(t, store.UpdateHouseEvent(got))
+	moved, err := store.GetHouseEvent(event.ID)
+	require.NoError(t, err)
+	for _, task := range moved.Tasks {
+		if task.ID == first.ID {
+			assert.Equal(t, closing.AddDate(0, 0, -7), task.DueAt.UTC(), "done tasks stay put")
+		} else if task.Title == "Hand over keys, remotes and codes" {
+			assert.Equal(t, closing.AddDate(0, 0, 7), task.DueAt.UTC())
+		}
+	}
+
+	extra := HouseEventTask{HouseEventID: event.ID, Title: "  Clean the gutters  "}
+	require.NoError(t, store.AddHouseEventTask(&extra))
+	assert.Equal(t, "Clean the gutters", extra.Title)
+	require.NoError(t, store.RemoveHouseEventTask(extra.ID))
+
+	require.NoError(t, store.DeleteHouseEvent(event.ID))
+	due, err = store.ListHouseEventTasksDue(now, 365*24*time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, due, "a deleted event's tasks aren't reminders")
+	require.NoError(t, store.RestoreHouseEvent(event.ID))
+	events, err := store.ListHouseEvents(false)
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LLMUsage counts the tokens a model took on a day, so a budget on them
+// can be kept.
+type LLMUsage struct {
+	ID uint `gorm:"primaryKey"`
+	// Day is the local date, as DateLayout.
+	Day              string `gorm:"uniqueIndex:idx_llm_usage_day_model,priority:1"`
+	Model            string `gorm:"uniqueIndex:idx_llm_usage_day_model,priority:2"`
+	Requests         int64
+	PromptTokens     int64
+	CompletionTokens int64
+	// EstimatedRequests is how many of the requests were counted from a
+	// guess, because the server didn't say what they took.
+	EstimatedRequests int64
+}
+
+// TableName names the table for what it counts.
+func (LLMUsage) TableName() string { return "llm_usage" }
+
+// Tokens is how many tokens the model took, prompts and answers together.
+func (u LLMUsage) Tokens() int64 { return u.PromptTokens + u.CompletionTokens }
+
+// RecordLLMUsage adds a request by model, taking prompt and completion
+// tokens, to the count for the day of at.
+func (s *Store) RecordLLMUsage(at time.Time, model string, prompt, completion int, estimated bool) error {
+	var guessed int64
+	if estimated {
+		guessed = 1
+	}
+	u := LLMUsage{
+		Day: at.Format(DateLayout), Model: model, Requests: 1,
+		PromptTokens: int64(prompt), CompletionTokens: int64(completion), EstimatedRequests: guessed,
+	}
+	return s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "day"}, {Name: "model"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"requests":           gorm.Expr("requests + 1"),
+			"prompt_tokens":      gorm.Expr("prompt_tokens + ?", prompt),
+			"completion_tokens":  gorm.Expr("completion_tokens + ?", completion),
+			"estimated_requests": gorm.Expr("estimated_requests + ?", guessed),
+		}),
+	}).Create(&u).Error
+}
+
+// LLMTokensSince returns how many tokens every model took from the day of
+// from on.
+func (s *Store) LLMTokensSince(from time.Time) (int64, error) {
+	var tokens int64
+	err := s.db.Model(&LLMUsage{}).
+		Where("day >= ?", from.Format(DateLayout)).
+		Select("COALESCE(SUM(prompt_tokens + completion_tokens), 0)").
+		Scan(&tokens).Error
+	return tokens, err
+}
+
+// ListLLMUsage returns the counts from the day of from on, newest first.
+func (s *Store) ListLLMUsage(from time.Time) ([]LLMUsage, error) {
+	var usage []LLMUsage
+	err := s.db.Where("day >= ?", from.Format(DateLayout)).
+		Order("day desc, model").
+		Find(&usage).Error
+	return usage, err
+}
@@ -0,0 +1,39 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLLMUsage(t *testing.T) {
+	store := newTestStore(t)
+	day := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+	require.NoError(t, store.RecordLLMUsage(day, "qwen3", 1000, 200, true))
+	require.NoError(t, store.RecordLLMUsage(day.Add(time.Hour), "qwen3", 500, 100, false))
+	require.NoError(t, store.RecordLLMUsage(day, "nomic-embed-text", 300, 0, false))
+	require.NoError(t, store.RecordLLMUsage(day.AddDate(0, 0, -1), "qwen3", 50, 50, false))
+
+	usage, err := store.ListLLMUsage(day)
+	require.NoError(t, err)
+	require.Len(t, usage, 2)
+	assert.Equal(t, "nomic-embed-text", usage[0].Model)
+	assert.Equal(t, LLMUsage{ID: usage[1].ID, Day: "2026-03-10", Model: "qwen3", Requests: 2,
+		PromptTokens: 1500, CompletionTokens: 300, EstimatedRequests: 1}, usage[1])
+	assert.Equal(t, int64(1800), usage[1].Tokens())
+
+	tokens, err := store.LLMTokensSince(day)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2100), tokens)
+	tokens, err = store.LLMTokensSince(day.AddDate(0, 0, -1))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2200), tokens)
+	tokens, err = store.LLMTokensSince(day.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.Zero(t, tokens)
+}
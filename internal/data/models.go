@@ -19,15 +19,60 @@ const (
 	ProjectStatusAbandoned  = "abandoned"
 )
 
+// ProjectStatuses lists the statuses of a project in the order they
+// usually come.
+func ProjectStatuses() []string {
+	return []string{
+		ProjectStatusIdeating, ProjectStatusPlanned, ProjectStatusQuoted, ProjectStatusInProgress,
+		ProjectStatusDelayed, ProjectStatusCompleted, ProjectStatusAbandoned,
+	}
+}
+
+// Reasons a project was delayed or abandoned.
 const (
-	DeletionEntityProject     = "project"
-	DeletionEntityQuote       = "quote"
-	DeletionEntityMaintenance = "maintenance"
-	DeletionEntityAppliance   = "appliance"
-	DeletionEntityServiceLog  = "service_log"
-	DeletionEntityVendor      = "vendor"
-	DeletionEntityDocument    = "document"
-	DeletionEntityIncident    = "incident"
+	StatusReasonWeather     = "weather"
+	StatusReasonBudget      = "budget"
+	StatusReasonVendor      = "vendor_availability"
+	StatusReasonChangedMind = "changed_mind"
+	StatusReasonOther       = "other"
+)
+
+// StatusReasons lists the reasons a project can be delayed or abandoned
+// for.
+func StatusReasons() []string {
+	return []string{
+		StatusReasonWeather, StatusReasonBudget, StatusReasonVendor, StatusReasonChangedMind, StatusReasonOther,
+	}
+}
+
+const (
+	DeletionEntityProject       = "project"
+	DeletionEntityQuote         = "quote"
+	DeletionEntityMaintenance   = "maintenance"
+	DeletionEntityAppliance     = "appliance"
+	DeletionEntityServiceLog    = "service_log"
+	DeletionEntityVendor        = "vendor"
+	DeletionEntityDocument      = "document"
+	DeletionEntityIncident      = "incident"
+	DeletionEntitySmartDevice   = "smart_device"
+	DeletionEntityLandscape     = "landscape_asset"
+	DeletionEntityPestTreatment = "pest_treatment"
+	DeletionEntityWaterTest     = "water_test"
+	DeletionEntityWaterFilter   = "water_filter_change"
+	DeletionEntityAirFilter     = "air_filter_spec"
+	DeletionEntityRoom          = "room"
+	DeletionEntityEstimate      = "material_estimate"
+	DeletionEntityRoomFinish    = "room_finish"
+	DeletionEntityFloorPlan     = "floor_plan"
+	DeletionEntityWalkthrough   = "walkthrough"
+	DeletionEntityHouseEvent    = "house_event"
+	DeletionEntityExpense       = "expense"
+	DeletionEntityClaim         = "warranty_claim"
+	DeletionEntityWarranty      = "warranty"
+	DeletionEntityInventory     = "inventory_item"
+	DeletionEntityRebate        = "rebate"
+	DeletionEntityContact       = "contact"
+	DeletionEntityUtilityBill   = "utility_bill"
 )
 
 // Column name constants for use in raw SQL queries. Centralising these
@@ -37,6 +82,7 @@ const (
 	ColName              = "name"
 	ColCreatedAt         = "created_at"
 	ColUpdatedAt         = "updated_at"
+	ColVersion           = "version"
 	ColDeletedAt         = "deleted_at"
 	ColStatus            = "status"
 	ColActualCents       = "actual_cents"
@@ -45,6 +91,8 @@ const (
 	ColTotalCents        = "total_cents"
 	ColIntervalMonths    = "interval_months"
 	ColLastServicedAt    = "last_serviced_at"
+	ColNextDueAt         = "next_due_at"
+	ColArchivedAt        = "archived_at"
 	ColWarrantyExpiry    = "warranty_expiry"
 	ColServicedAt        = "serviced_at"
 	ColReceivedDate      = "received_date"
@@ -67,14 +115,68 @@ const (
 	ColFileName          = "file_name"
 	ColMIMEType          = "mime_type"
 	ColSizeBytes         = "size_bytes"
+	ColSizeHuman         = "size_human"
 	ColChecksum          = "sha256"
+	ColOriginalChecksum  = "original_sha256"
+	ColOriginalSize      = "original_size_bytes"
+	ColImageHash         = "image_hash"
+	ColSharpness         = "sharpness"
 	ColData              = "data"
+	ColContentOf         = "content_of"
+	ColStoredFile        = "stored_file"
 	ColSeverity          = "severity"
 	ColDescription       = "description"
 	ColDateNoticed       = "date_noticed"
 	ColDateResolved      = "date_resolved"
 	ColLocation          = "location"
 	ColIncidentID        = "incident_id"
+	ColRoom              = "room"
+	ColMACAddress        = "mac_address"
+	ColLastBatteryChange = "last_battery_change"
+	ColLandscapeAssetID  = "landscape_asset_id"
+	ColKind              = "kind"
+	ColTargetPest        = "target_pest"
+	ColTreatedAt         = "treated_at"
+	ColTestedAt          = "tested_at"
+	ColChangedAt         = "changed_at"
+	ColField             = "field"
+	ColStockOnHand       = "stock_on_hand"
+	ColRoomID            = "room_id"
+	ColFloorPlanID       = "floor_plan_id"
+	ColSurface           = "surface"
+	ColLevel             = "level"
+	ColImageData         = "image_data"
+	ColYear              = "year"
+	ColPurchaseDate      = "purchase_date"
+	ColLastRunAt         = "last_run_at"
+	ColLastUsedAt        = "last_used_at"
+	ColRevokedAt         = "revoked_at"
+	ColTokenHash         = "token_hash"
+	ColAccount           = "account"
+	ColConfirmedAt       = "confirmed_at"
+	ColLastStep          = "last_step"
+	ColRecoveryHashes    = "recovery_hashes"
+	ColLastOpenedAt      = "last_opened_at"
+	ColHouseProfileID    = "house_profile_id"
+	ColHouseID           = "house_id"
+	ColOccurredOn        = "occurred_on"
+	ColOccurredAt        = "occurred_at"
+	ColDueAt             = "due_at"
+	ColDoneAt            = "done_at"
+	ColQuoteID           = "quote_id"
+	ColSortOrder         = "sort_order"
+	ColInvoicedOn        = "invoiced_on"
+	ColInsuranceCents    = "insurance_cents"
+	ColWarrantyCents     = "warranty_cents"
+	ColRebateCents       = "rebate_cents"
+	ColUsername          = "username"
+	ColPasswordHash      = "password_hash"
+	ColUserID            = "user_id"
+	ColExpiresAt         = "expires_at"
+	ColLastLoginAt       = "last_login_at"
+	ColVerifiedAt        = "verified_at"
+	ColUndoneAt          = "undone_at"
+	ColActor             = "actor"
 )
 
 const (
@@ -95,14 +197,21 @@ const MaxDocumentSize int64 = 50 << 20 // 50 MiB
 
 // Document entity kind values for polymorphic linking.
 const (
-	DocumentEntityNone        = ""
-	DocumentEntityProject     = "project"
-	DocumentEntityQuote       = "quote"
-	DocumentEntityMaintenance = "maintenance"
-	DocumentEntityAppliance   = "appliance"
-	DocumentEntityServiceLog  = "service_log"
-	DocumentEntityVendor      = "vendor"
-	DocumentEntityIncident    = "incident"
+	DocumentEntityNone          = ""
+	DocumentEntityProject       = "project"
+	DocumentEntityQuote         = "quote"
+	DocumentEntityMaintenance   = "maintenance"
+	DocumentEntityAppliance     = "appliance"
+	DocumentEntityServiceLog    = "service_log"
+	DocumentEntityVendor        = "vendor"
+	DocumentEntityIncident      = "incident"
+	DocumentEntitySmartDevice   = "smart_device"
+	DocumentEntityLandscape     = "landscape_asset"
+	DocumentEntityPestTreatment = "pest_treatment"
+	DocumentEntityWaterTest     = "water_test"
+	DocumentEntityWalkthrough   = "walkthrough"
+	DocumentEntitySitterStay    = "sitter_stay"
+	DocumentEntityInventory     = "inventory_item"
 )
 
 type HouseProfile struct {
@@ -134,8 +243,10 @@ type HouseProfile struct {
 	PropertyTaxCents *int64
 	HOAName          string
 	HOAFeeCents      *int64
+	ArchivedAt       *time.Time `gorm:"index"`
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
+	Version          int `gorm:"not null;default:1"`
 }
 
 type ProjectType struct {
@@ -155,7 +266,9 @@ type Vendor struct {
 	Notes       string
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+	Version     int            `gorm:"not null;default:1"`
 	DeletedAt   gorm.DeletedAt `gorm:"index"`
+	Tags        []string       `gorm:"-"`
 }
 
 type Project struct {
@@ -164,14 +277,30 @@ type Project struct {
 	ProjectTypeID uint
 	ProjectType   ProjectType `gorm:"constraint:OnDelete:RESTRICT;"`
 	Status        string
-	Description   string
-	StartDate     *time.Time
-	EndDate       *time.Time
-	BudgetCents   *int64
-	ActualCents   *int64
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	DeletedAt     gorm.DeletedAt `gorm:"index"`
+	// StatusReason is why a delayed or abandoned project is, one of
+	// StatusReasons. It's empty for other statuses.
+	StatusReason string
+	Description  string
+	StartDate    *time.Time
+	EndDate      *time.Time
+	BudgetCents  *int64
+	ActualCents  *int64
+	RoomID       *uint `gorm:"index"`
+	Room         Room  `gorm:"constraint:OnDelete:SET NULL;"`
+	HouseID      *uint `gorm:"index"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Version      int            `gorm:"not null;default:1"`
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
+	Tags         []string       `gorm:"-"`
+	// Custom holds its values for the custom fields, by key.
+	Custom map[string]string `gorm:"-"`
+	// TasksTotal and TasksDone count the tasks on its checklist.
+	TasksTotal int `gorm:"-"`
+	TasksDone  int `gorm:"-"`
+	// AllowOpenTasks lets an update complete it with tasks still open. It
+	// isn't saved.
+	AllowOpenTasks bool `gorm:"-"`
 }
 
 type Quote struct {
@@ -184,11 +313,18 @@ type Quote struct {
 	LaborCents     *int64
 	MaterialsCents *int64
 	OtherCents     *int64
-	ReceivedDate   *time.Time
-	Notes          string
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
-	DeletedAt      gorm.DeletedAt `gorm:"index"`
+	// RequestedDate is when the quote was asked for. Without one, a bid
+	// request to the vendor for the project stands in.
+	RequestedDate *time.Time
+	ReceivedDate  *time.Time
+	Notes         string
+	// Lines itemize the quote. When there are any, they add up to
+	// TotalCents and set the labor, materials and other amounts.
+	Lines     []QuoteLine `gorm:"constraint:OnDelete:CASCADE;"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Version   int            `gorm:"not null;default:1"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
 
 type MaintenanceCategory struct {
@@ -207,29 +343,45 @@ type Appliance struct {
 	PurchaseDate   *time.Time
 	WarrantyExpiry *time.Time `gorm:"index"`
 	Location       string
+	RoomID         *uint `gorm:"index"`
+	Room           Room  `gorm:"constraint:OnDelete:SET NULL;"`
 	CostCents      *int64
 	Notes          string
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
-	DeletedAt      gorm.DeletedAt `gorm:"index"`
+	// GuestInstructions is how a guest uses it, one step per line, for
+	// its guest card.
+	GuestInstructions string
+	HouseID           *uint `gorm:"index"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	Version           int            `gorm:"not null;default:1"`
+	DeletedAt         gorm.DeletedAt `gorm:"index"`
+	Tags              []string       `gorm:"-"`
+	// Custom holds its values for the custom fields, by key.
+	Custom map[string]string `gorm:"-"`
 }
 
 type MaintenanceItem struct {
-	ID             uint `gorm:"primaryKey"`
-	Name           string
-	CategoryID     uint                `gorm:"index"`
-	Category       MaintenanceCategory `gorm:"constraint:OnDelete:RESTRICT;"`
-	ApplianceID    *uint               `gorm:"index"`
-	Appliance      Appliance           `gorm:"constraint:OnDelete:SET NULL;"`
-	LastServicedAt *time.Time
-	IntervalMonths int
-	ManualURL      string
-	ManualText     string
-	Notes          string
-	CostCents      *int64
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
-	DeletedAt      gorm.DeletedAt `gorm:"index"`
+	ID               uint `gorm:"primaryKey"`
+	Name             string
+	CategoryID       uint                `gorm:"index"`
+	Category         MaintenanceCategory `gorm:"constraint:OnDelete:RESTRICT;"`
+	ApplianceID      *uint               `gorm:"index"`
+	Appliance        Appliance           `gorm:"constraint:OnDelete:SET NULL;"`
+	LandscapeAssetID *uint               `gorm:"index"`
+	LandscapeAsset   LandscapeAsset      `gorm:"constraint:OnDelete:SET NULL;"`
+	LastServicedAt   *time.Time
+	IntervalMonths   int
+	NextDueAt        *time.Time `gorm:"index"`
+	ManualURL        string
+	ManualText       string
+	Notes            string
+	CostCents        *int64
+	HouseID          *uint `gorm:"index"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Version          int            `gorm:"not null;default:1"`
+	DeletedAt        gorm.DeletedAt `gorm:"index"`
+	Tags             []string       `gorm:"-"`
 }
 
 type Incident struct {
@@ -247,8 +399,10 @@ type Incident struct {
 	VendorID     *uint     `gorm:"index"`
 	Vendor       Vendor    `gorm:"constraint:OnDelete:SET NULL;"`
 	Notes        string
+	HouseID      *uint `gorm:"index"`
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
+	Version      int            `gorm:"not null;default:1"`
 	DeletedAt    gorm.DeletedAt `gorm:"index"`
 }
 
@@ -260,28 +414,59 @@ type ServiceLogEntry struct {
 	VendorID          *uint  `gorm:"index"`
 	Vendor            Vendor `gorm:"constraint:OnDelete:SET NULL;"`
 	CostCents         *int64
-	Notes             string
-	CreatedAt         time.Time
-	UpdatedAt         time.Time
-	DeletedAt         gorm.DeletedAt `gorm:"index"`
-}
-
-type Document struct {
-	ID             uint `gorm:"primaryKey"`
-	Title          string
-	FileName       string `gorm:"column:file_name"`
-	EntityKind     string `gorm:"index:idx_doc_entity"`
-	EntityID       uint   `gorm:"index:idx_doc_entity"`
-	MIMEType       string
-	SizeBytes      int64
-	ChecksumSHA256 string `gorm:"column:sha256"`
-	Data           []byte
+	// InsuranceCents, WarrantyCents and RebateCents are the parts of
+	// CostCents an insurer, a warranty and a rebate paid; the rest came
+	// out of pocket.
+	InsuranceCents int64
+	WarrantyCents  int64
+	RebateCents    int64
 	Notes          string
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
+	Version        int            `gorm:"not null;default:1"`
 	DeletedAt      gorm.DeletedAt `gorm:"index"`
 }
 
+// Document is an attachment. SizeHuman ("1.4 MB") is computed from
+// SizeBytes when listing documents. When an uploaded photo was recompressed,
+// SizeBytes and ChecksumSHA256 describe the stored copy and the Original
+// fields the file as uploaded. Photos carry a perceptual ImageHash and a
+// Sharpness score for spotting bursts (see PhotoBursts). LastOpenedAt is
+// when the file was last downloaded. A document whose file is the same as an
+// earlier one's has ContentOf set to that document and no Data of its own,
+// so a manual attached to three appliances is stored once (see
+// DuplicateDocuments). With document files kept as files (see
+// SetDocumentStorage), StoredFile names the file holding the content in
+// place of Data. The list indexes end in UpdatedAt so a
+// page of documents is found without reading the wide rows; the id
+// tiebreaker comes free as the rowid.
+type Document struct {
+	ID                     uint `gorm:"primaryKey"`
+	Title                  string
+	FileName               string `gorm:"column:file_name"`
+	EntityKind             string `gorm:"index:idx_doc_entity_list,priority:1"`
+	EntityID               uint   `gorm:"index:idx_doc_entity_list,priority:2"`
+	MIMEType               string
+	SizeBytes              int64
+	SizeHuman              string `gorm:"column:size_human;->;-:migration"`
+	ChecksumSHA256         string `gorm:"column:sha256"`
+	OriginalChecksumSHA256 string `gorm:"column:original_sha256"`
+	OriginalSizeBytes      int64
+	ImageHash              string
+	Sharpness              float64
+	Data                   []byte
+	ContentOf              *uint  `gorm:"index"`
+	StoredFile             string `gorm:"column:stored_file;index"`
+	Notes                  string
+	LastOpenedAt           *time.Time
+	HouseID                *uint `gorm:"index"`
+	CreatedAt              time.Time
+	UpdatedAt              time.Time      `gorm:"index:idx_doc_list,priority:2;index:idx_doc_entity_list,priority:4"`
+	Version                int            `gorm:"not null;default:1"`
+	DeletedAt              gorm.DeletedAt `gorm:"index;index:idx_doc_list,priority:1;index:idx_doc_entity_list,priority:3"`
+	Tags                   []string       `gorm:"-"`
+}
+
 type DeletionRecord struct {
 	ID         uint       `gorm:"primaryKey"`
 	Entity     string     `gorm:"index:idx_entity_restored,priority:1"`
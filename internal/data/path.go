@@ -32,3 +32,17 @@ func DocumentCacheDir() (string, error) {
 	}
 	return dir, nil
 }
+
+// CrashReportDir returns the directory the server writes crash reports to.
+// It is created when the first report is written.
+// On Linux: $XDG_DATA_HOME/webcasa/crashes (default ~/.local/share/webcasa/crashes)
+func CrashReportDir() string {
+	return filepath.Join(xdg.DataHome, AppName, "crashes")
+}
+
+// CertCacheDir returns the directory Let's Encrypt certificates are kept
+// in. It is created when the first certificate is saved.
+// On Linux: $XDG_DATA_HOME/webcasa/certs (default ~/.local/share/webcasa/certs)
+func CertCacheDir() string {
+	return filepath.Join(xdg.DataHome, AppName, "certs")
+}
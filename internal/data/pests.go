@@ -0,0 +1,174 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PestTreatment is one pest control visit or application: a termite
+// inspection, a perimeter spray, a rodent baiting. When a re-treatment
+// interval is set, the next treatment for that pest is surfaced as a reminder.
+type PestTreatment struct {
+	ID                    uint  `gorm:"primaryKey"`
+	HouseID               *uint `gorm:"index"`
+	TargetPest            string
+	Product               string
+	VendorID              *uint  `gorm:"index"`
+	Vendor                Vendor `gorm:"constraint:OnDelete:SET NULL;"`
+	AreasTreated          string
+	TreatedAt             time.Time
+	RetreatIntervalMonths int
+	CostCents             *int64
+	SafetyNotes           string
+	Notes                 string
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+	Version               int            `gorm:"not null;default:1"`
+	DeletedAt             gorm.DeletedAt `gorm:"index"`
+}
+
+// NextTreatmentDue returns when the pest should be treated again, or nil if
+// no re-treatment interval is set.
+func (p PestTreatment) NextTreatmentDue() *time.Time {
+	if p.RetreatIntervalMonths <= 0 || p.TreatedAt.IsZero() {
+		return nil
+	}
+	next := p.TreatedAt.AddDate(0, p.RetreatIntervalMonths, 0)
+	return &next
+}
+
+func (s *Store) ListPestTreatments(includeDeleted bool) ([]PestTreatment, error) {
+	var items []PestTreatment
+	db := s.db.Scopes(s.inHouse("pest_treatments")).
+		Preload("Vendor", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Order(ColTreatedAt + " desc, " + ColID + " desc")
+	if includeDeleted {
+		db = db.Unscoped()
+	}
+	return items, db.Find(&items).Error
+}
+
+func (s *Store) GetPestTreatment(id uint) (PestTreatment, error) {
+	var item PestTreatment
+	err := s.db.Preload("Vendor").First(&item, id).Error
+	return item, err
+}
+
+func (s *Store) CreatePestTreatment(item *PestTreatment) error {
+	if err := validatePestTreatment(item); err != nil {
+		return err
+	}
+	return s.db.Create(item).Error
+}
+
+func (s *Store) UpdatePestTreatment(item PestTreatment) error {
+	if err := validatePestTreatment(&item); err != nil {
+		return err
+	}
+	return s.updateByID(&PestTreatment{}, item.ID, item)
+}
+
+func (s *Store) DeletePestTreatment(id uint) error {
+	return s.softDelete(&PestTreatment{}, DeletionEntityPestTreatment, id)
+}
+
+func (s *Store) RestorePestTreatment(id uint) error {
+	var item PestTreatment
+	if err := s.db.Unscoped().First(&item, id).Error; err != nil {
+		return err
+	}
+	if item.VendorID != nil {
+		if err := s.requireParentAlive(&Vendor{}, *item.VendorID); err != nil {
+			return parentRestoreError("vendor", err)
+		}
+	}
+	return s.restoreEntity(&PestTreatment{}, DeletionEntityPestTreatment, id)
+}
+
+func (s *Store) CountPestTreatmentsByVendor(vendorIDs []uint) (map[uint]int, error) {
+	return s.countByFK(&PestTreatment{}, ColVendorID, vendorIDs)
+}
+
+// LastPestTreatment returns the most recent treatment whose target pest
+// contains the given text (case-insensitive), answering questions like "when
+// was the last termite inspection?".
+func (s *Store) LastPestTreatment(pest string) (PestTreatment, error) {
+	var item PestTreatment
+	err := s.db.
+		Preload("Vendor", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Where("LOWER("+ColTargetPest+") LIKE ?", "%"+strings.ToLower(strings.TrimSpace(pest))+"%").
+		Order(ColTreatedAt + " desc, " + ColID + " desc").
+		First(&item).Error
+	return item, err
+}
+
+// ListPestRetreatmentsDue returns, for each of the current house's pests,
+// the latest treatment whose re-treatment falls on or before now +
+// horizon. Earlier treatments of the same pest are superseded by the
+// latest one. Results are ordered by due date, soonest (or most overdue)
+// first.
+func (s *Store) ListPestRetreatmentsDue(
+	now time.Time,
+	horizon time.Duration,
+) ([]PestTreatment, error) {
+	return s.pestRetreatmentsDue(s.inHouse("pest_treatments"), now, horizon)
+}
+
+// pestRetreatmentsDue is ListPestRetreatmentsDue for the treatments house
+// picks out.
+func (s *Store) pestRetreatmentsDue(
+	house func(*gorm.DB) *gorm.DB,
+	now time.Time,
+	horizon time.Duration,
+) ([]PestTreatment, error) {
+	var items []PestTreatment
+	err := s.db.Scopes(house).
+		Preload("Vendor", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Order(ColTreatedAt + " desc, " + ColID + " desc").
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	cutoff := now.Add(horizon)
+	seen := make(map[string]bool)
+	var due []PestTreatment
+	for _, item := range items {
+		key := strings.ToLower(item.TargetPest)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		next := item.NextTreatmentDue()
+		if next != nil && !next.After(cutoff) {
+			due = append(due, item)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].NextTreatmentDue().Before(*due[j].NextTreatmentDue())
+	})
+	return due, nil
+}
+
+func validatePestTreatment(item *PestTreatment) error {
+	item.TargetPest = strings.TrimSpace(item.TargetPest)
+	if item.TargetPest == "" {
+		return fmt.Errorf("target pest is required")
+	}
+	if item.TreatedAt.IsZero() {
+		return fmt.Errorf("treatment date is required")
+	}
+	if item.RetreatIntervalMonths < 0 {
+		return fmt.Errorf(
+			"re-treatment interval must be non-negative, got %d",
+			item.RetreatIntervalMonths,
+		)
+	}
+	return nil
+}
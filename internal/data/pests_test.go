@@ -0,0 +1,107 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPestTreatmentValidation(t *testing.T) {
+	store := newTestStore(t)
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	assert.Error(t, store.CreatePestTreatment(&PestTreatment{TreatedAt: now}))
+	assert.Error(t, store.CreatePestTreatment(&PestTreatment{TargetPest: "Ants"}))
+	assert.Error(t, store.CreatePestTreatment(&PestTreatment{
+		TargetPest: "Ants", TreatedAt: now, RetreatIntervalMonths: -1,
+	}))
+}
+
+func TestLastPestTreatmentMatchesCaseInsensitively(t *testing.T) {
+	store := newTestStore(t)
+	vendor := Vendor{Name: "Bug Busters"}
+	require.NoError(t, store.CreateVendor(&vendor))
+
+	older := PestTreatment{
+		TargetPest: "Termites", Product: "Inspection",
+		TreatedAt: time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC),
+	}
+	newer := PestTreatment{
+		TargetPest: "Subterranean termites", Product: "Inspection",
+		VendorID:  &vendor.ID,
+		TreatedAt: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+	}
+	ants := PestTreatment{
+		TargetPest: "Ants",
+		TreatedAt:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for _, p := range []*PestTreatment{&older, &newer, &ants} {
+		require.NoError(t, store.CreatePestTreatment(p))
+	}
+
+	got, err := store.LastPestTreatment("termite")
+	require.NoError(t, err)
+	assert.Equal(t, newer.ID, got.ID)
+	assert.Equal(t, "Bug Busters", got.Vendor.Name)
+
+	_, err = store.LastPestTreatment("bed bugs")
+	assert.Error(t, err)
+}
+
+func TestListPestRetreatmentsDueUsesLatestPerPest(t *testing.T) {
+	store := newTestStore(t)
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	// Superseded by the later treatment, which isn't due yet.
+	require.NoError(t, store.CreatePestTreatment(&PestTreatment{
+		TargetPest: "Ants", RetreatIntervalMonths: 3,
+		TreatedAt: now.AddDate(0, -6, 0),
+	}))
+	require.NoError(t, store.CreatePestTreatment(&PestTreatment{
+		TargetPest: "ants", RetreatIntervalMonths: 3,
+		TreatedAt: now.AddDate(0, -1, 0),
+	}))
+	overdue := PestTreatment{
+		TargetPest: "Termites", RetreatIntervalMonths: 12,
+		TreatedAt: now.AddDate(-1, -1, 0),
+	}
+	require.NoError(t, store.CreatePestTreatment(&overdue))
+	upcoming := PestTreatment{
+		TargetPest: "Mosquitoes", RetreatIntervalMonths: 1,
+		TreatedAt: now.AddDate(0, 0, -20),
+	}
+	require.NoError(t, store.CreatePestTreatment(&upcoming))
+	// No interval: never due.
+	require.NoError(t, store.CreatePestTreatment(&PestTreatment{
+		TargetPest: "Wasps", TreatedAt: now.AddDate(-3, 0, 0),
+	}))
+
+	due, err := store.ListPestRetreatmentsDue(now, 30*24*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Equal(t, overdue.ID, due[0].ID)
+	assert.Equal(t, upcoming.ID, due[1].ID)
+}
+
+func TestPestTreatmentBlocksVendorDelete(t *testing.T) {
+	store := newTestStore(t)
+	vendor := Vendor{Name: "Orkin"}
+	require.NoError(t, store.CreateVendor(&vendor))
+	treatment := PestTreatment{
+		TargetPest: "Rodents", VendorID: &vendor.ID,
+		TreatedAt: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, store.CreatePestTreatment(&treatment))
+
+	require.ErrorContains(t, store.DeleteVendor(vendor.ID), "pest treatment")
+
+	require.NoError(t, store.DeletePestTreatment(treatment.ID))
+	require.NoError(t, store.DeleteVendor(vendor.ID))
+	require.ErrorContains(t, store.RestorePestTreatment(treatment.ID), "vendor is deleted")
+	require.NoError(t, store.RestoreVendor(vendor.ID))
+	require.NoError(t, store.RestorePestTreatment(treatment.ID))
+}
@@ -0,0 +1,233 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Project task statuses.
+const (
+	TaskStatusTodo       = "todo"
+	TaskStatusInProgress = "in_progress"
+	TaskStatusDone       = "done"
+)
+
+// TaskStatuses lists the statuses of a project task in order.
+func TaskStatuses() []string {
+	return []string{TaskStatusTodo, TaskStatusInProgress, TaskStatusDone}
+}
+
+// ErrOpenTasks means a project can't be completed while tasks on its
+// checklist are still open, unless it's told to anyway.
+var ErrOpenTasks = errors.New("project has open tasks")
+
+// ProjectTask is one item on a project's checklist. Assignee is a note on
+// who's doing it, and SortOrder places it on the list. DoneAt is set when
+// it's marked done.
+type ProjectTask struct {
+	ID        uint    `gorm:"primaryKey"`
+	ProjectID uint    `gorm:"index"`
+	Project   Project `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+	Title     string
+	Status    string
+	DueDate   *time.Time `gorm:"index"`
+	Assignee  string
+	SortOrder int
+	DoneAt    *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Version   int `gorm:"not null;default:1"`
+}
+
+// ListProjectTasks returns a project's checklist in order.
+func (s *Store) ListProjectTasks(projectID uint) ([]ProjectTask, error) {
+	var tasks []ProjectTask
+	err := s.db.Where(ColProjectID+" = ?", projectID).
+		Order(ColSortOrder + ", " + ColID).
+		Find(&tasks).Error
+	return tasks, err
+}
+
+// ListProjectTasksDue returns the current house's open project tasks due
+// within horizon of now, overdue ones included, soonest first, with their
+// projects. Tasks of deleted, completed and abandoned projects are left
+// out.
+func (s *Store) ListProjectTasksDue(now time.Time, horizon time.Duration) ([]ProjectTask, error) {
+	var tasks []ProjectTask
+	err := s.db.Joins("JOIN projects ON projects."+ColID+" = project_tasks.project_id").
+		Where("projects."+ColDeletedAt+" IS NULL AND projects."+ColStatus+" NOT IN ?",
+			[]string{ProjectStatusCompleted, ProjectStatusAbandoned}).
+		Scopes(s.inHouse("projects")).
+		Where("project_tasks."+ColStatus+" <> ? AND project_tasks.due_date <= ?", TaskStatusDone, now.Add(horizon)).
+		Preload("Project").
+		Order("project_tasks.due_date, project_tasks." + ColID).
+		Find(&tasks).Error
+	return tasks, err
+}
+
+func (s *Store) GetProjectTask(id uint) (ProjectTask, error) {
+	var task ProjectTask
+	err := s.db.First(&task, id).Error
+	return task, err
+}
+
+// CreateProjectTask adds a task to a project's checklist, at the end
+// unless it gives a place.
+func (s *Store) CreateProjectTask(task *ProjectTask, now time.Time) error {
+	if err := s.requireParentAlive(&Project{}, task.ProjectID); err != nil {
+		return fmt.Errorf("project not found or deleted")
+	}
+	if err := validateProjectTask(task, now); err != nil {
+		return err
+	}
+	if task.SortOrder == 0 {
+		var last *int
+		if err := s.db.Model(&ProjectTask{}).Where(ColProjectID+" = ?", task.ProjectID).
+			Select("MAX(" + ColSortOrder + ")").Scan(&last).Error; err != nil {
+			return err
+		}
+		if last != nil {
+			task.SortOrder = *last + 1
+		} else {
+			task.SortOrder = 1
+		}
+	}
+	return s.db.Create(task).Error
+}
+
+// UpdateProjectTask saves a task's fields. It stays on its project and in
+// its place on the list (see ReorderProjectTasks), and marking it done
+// dates it now.
+func (s *Store) UpdateProjectTask(task ProjectTask, now time.Time) error {
+	old, err := s.GetProjectTask(task.ID)
+	if err != nil {
+		return err
+	}
+	task.ProjectID, task.SortOrder, task.DoneAt = old.ProjectID, old.SortOrder, old.DoneAt
+	if err := validateProjectTask(&task, now); err != nil {
+		return err
+	}
+	return s.updateByID(&ProjectTask{}, task.ID, task)
+}
+
+// DeleteProjectTask takes a task off its checklist.
+func (s *Store) DeleteProjectTask(id uint) error {
+	result := s.db.Delete(&ProjectTask{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ReorderProjectTasks puts a project's tasks in the order of ids, which
+// has to list every one of them.
+func (s *Store) ReorderProjectTasks(projectID uint, ids []uint) error {
+	tasks, err := s.ListProjectTasks(projectID)
+	if err != nil {
+		return err
+	}
+	have := make([]uint, len(tasks))
+	for i, t := range tasks {
+		have[i] = t.ID
+	}
+	want := slices.Clone(ids)
+	slices.Sort(have)
+	slices.Sort(want)
+	if !slices.Equal(have, want) {
+		return fmt.Errorf("the order has to list each of the project's %d tasks once", len(tasks))
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			if err := tx.Model(&ProjectTask{}).Where(ColID+" = ?", id).
+				UpdateColumn(ColSortOrder, i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func validateProjectTask(task *ProjectTask, now time.Time) error {
+	task.Title = strings.TrimSpace(task.Title)
+	task.Assignee = strings.TrimSpace(task.Assignee)
+	if task.Status == "" {
+		task.Status = TaskStatusTodo
+	}
+	switch {
+	case task.Title == "":
+		return fmt.Errorf("task title is required")
+	case !slices.Contains(TaskStatuses(), task.Status):
+		return fmt.Errorf("unknown task status %q -- use one of %s",
+			task.Status, strings.Join(TaskStatuses(), ", "))
+	}
+	if task.Status != TaskStatusDone {
+		task.DoneAt = nil
+	} else if task.DoneAt == nil {
+		task.DoneAt = &now
+	}
+	return nil
+}
+
+// fillProjectsTasks counts each project's tasks and the ones done.
+func (s *Store) fillProjectsTasks(projects []Project) error {
+	if len(projects) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(projects))
+	for i, p := range projects {
+		ids[i] = p.ID
+	}
+	var counts []struct {
+		ProjectID uint
+		Total     int
+		Done      int
+	}
+	err := s.db.Model(&ProjectTask{}).
+		Select(ColProjectID+", COUNT(*) AS total, SUM(CASE WHEN "+ColStatus+" = ? THEN 1 ELSE 0 END) AS done", TaskStatusDone).
+		Where(ColProjectID+" IN ?", ids).
+		Group(ColProjectID).
+		Scan(&counts).Error
+	if err != nil {
+		return err
+	}
+	byProject := make(map[uint]int, len(counts))
+	for i, c := range counts {
+		byProject[c.ProjectID] = i
+	}
+	for i := range projects {
+		if j, ok := byProject[projects[i].ID]; ok {
+			projects[i].TasksTotal, projects[i].TasksDone = counts[j].Total, counts[j].Done
+		}
+	}
+	return nil
+}
+
+// checkOpenTasks refuses to complete p while its checklist has open tasks,
+// unless it allows them. before is its status until now.
+func (s *Store) checkOpenTasks(before string, p Project) error {
+	if p.Status != ProjectStatusCompleted || before == ProjectStatusCompleted || p.AllowOpenTasks {
+		return nil
+	}
+	var open int64
+	err := s.db.Model(&ProjectTask{}).
+		Where(ColProjectID+" = ? AND "+ColStatus+" <> ?", p.ID, TaskStatusDone).
+		Count(&open).Error
+	if err != nil {
+		return err
+	}
+	if open > 0 {
+		return fmt.Errorf("%w: %d still to do -- finish them or complete it anyway", ErrOpenTasks, open)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestProjectTasks(t *testing.T) {
+	store := newTestStore(t)
+	now := time.Now()
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	deck := Project{Title: "Deck", ProjectTypeID: types[0].ID, Status: ProjectStatusInProgress}
+	require.NoError(t, store.CreateProject(&deck))
+
+	require.ErrorContains(t, store.CreateProjectTask(&ProjectTask{ProjectID: 999, Title: "Dig"}, now), "project not found")
+	require.ErrorContains(t, store.CreateProjectTask(&ProjectTask{ProjectID: deck.ID, Title: " "}, now), "title is required")
+	require.ErrorContains(t, store.CreateProjectTask(&ProjectTask{ProjectID: deck.ID, Title: "Dig", Status: "maybe"}, now),
+		"unknown task status")
+
+	var tasks []ProjectTask
+	for _, title := range []string{"Pull permit", "Set footings", "Frame"} {
+		task := ProjectTask{ProjectID: deck.ID, Title: title, Assignee: " Sam "}
+		require.NoError(t, store.CreateProjectTask(&task, now))
+		tasks = append(tasks, task)
+	}
+	assert.Equal(t, TaskStatusTodo, tasks[0].Status)
+	assert.Equal(t, "Sam", tasks[0].Assignee)
+	assert.Equal(t, 3, tasks[2].SortOrder)
+
+	permit := tasks[0]
+	permit.Status, permit.SortOrder = TaskStatusDone, 0
+	require.NoError(t, store.UpdateProjectTask(permit, now))
+	permit, err = store.GetProjectTask(permit.ID)
+	require.NoError(t, err)
+	require.NotNil(t, permit.DoneAt)
+	assert.Equal(t, 1, permit.SortOrder, "an edit keeps the task's place")
+
+	got, err := store.GetProject(deck.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, got.TasksTotal)
+	assert.Equal(t, 1, got.TasksDone)
+
+	require.ErrorContains(t, store.ReorderProjectTasks(deck.ID, []uint{tasks[2].ID, tasks[0].ID}), "each of the project's 3 tasks")
+	require.NoError(t, store.ReorderProjectTasks(deck.ID, []uint{tasks[2].ID, tasks[0].ID, tasks[1].ID}))
+	ordered, err := store.ListProjectTasks(deck.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Frame", "Pull permit", "Set footings"},
+		[]string{ordered[0].Title, ordered[1].Title, ordered[2].Title})
+
+	footings := ordered[2]
+	footings.DueDate = &now
+	require.NoError(t, store.UpdateProjectTask(footings, now))
+	due, err := store.ListProjectTasksDue(now, 24*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "Deck", due[0].Project.Title)
+
+	// Open tasks hold up completing the project, unless it's told to go
+	// ahead anyway.
+	got.Status = ProjectStatusCompleted
+	require.ErrorIs(t, store.UpdateProject(got), ErrOpenTasks)
+	require.ErrorIs(t, store.SetProjectStatus(deck.ID, ProjectStatusCompleted, "", "", now), ErrOpenTasks)
+	got.AllowOpenTasks = true
+	require.NoError(t, store.UpdateProject(got))
+	got, err = store.GetProject(deck.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ProjectStatusCompleted, got.Status)
+	due, err = store.ListProjectTasksDue(now, 24*time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, due, "a completed project's tasks aren't due")
+
+	require.NoError(t, store.DeleteProjectTask(tasks[1].ID))
+	require.ErrorIs(t, store.DeleteProjectTask(tasks[1].ID), gorm.ErrRecordNotFound)
+	got, err = store.GetProject(deck.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, got.TasksTotal)
+}
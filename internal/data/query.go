@@ -21,12 +21,19 @@ type PragmaColumn struct {
 	PK        int     `gorm:"column:pk"`
 }
 
+// privateTables hold credentials and sessions, and the audit log, which
+// says who did what and keeps what deleted records held. They are left out
+// of TableNames and DataDump, and ReadOnlyQuery refuses them, so they
+// never reach a language model.
+var privateTables = []string{"api_tokens", tableAuditLog, "second_factors", "user_sessions", "users"}
+
 // TableNames returns the names of all non-internal tables in the database.
 func (s *Store) TableNames() ([]string, error) {
 	var names []string
 	err := s.db.Raw(
-		"SELECT name FROM sqlite_master WHERE type='table' " +
-			"AND name NOT LIKE 'sqlite_%' ORDER BY name",
+		"SELECT name FROM sqlite_master WHERE type='table' "+
+			"AND name NOT LIKE 'sqlite_%' AND name NOT LIKE '"+searchIndexTable+"%' "+
+			"AND name NOT IN ? ORDER BY name", privateTables,
 	).Scan(&names).Error
 	return names, err
 }
@@ -72,6 +79,11 @@ func (s *Store) ReadOnlyQuery(query string) (columns []string, rows [][]string,
 			return nil, nil, fmt.Errorf("query contains disallowed keyword: %s", kw)
 		}
 	}
+	for _, table := range privateTables {
+		if containsWord(upper, strings.ToUpper(table)) {
+			return nil, nil, fmt.Errorf("the %s table can't be queried", table)
+		}
+	}
 
 	sqlRows, err := s.db.Raw(trimmed).Rows()
 	if err != nil {
@@ -122,7 +134,15 @@ func (s *Store) ReadOnlyQuery(query string) (columns []string, rows [][]string,
 // money columns (ending in "_ct") are formatted as dollars, and internal
 // columns (id, created_at, updated_at, deleted_at) are excluded to reduce
 // noise.
-func (s *Store) DataDump() string {
+func (s *Store) DataDump() string { return s.MaskedDump(nil) }
+
+// Mask returns what to show of a column's value in place of it, for
+// masking values before they leave the machine.
+type Mask func(column, value string) string
+
+// MaskedDump is DataDump with each value passed through mask first, when
+// it isn't nil.
+func (s *Store) MaskedDump(mask Mask) string {
 	names, err := s.TableNames()
 	if err != nil {
 		return ""
@@ -190,6 +210,9 @@ func (s *Store) DataDump() string {
 				if isNoiseColumn(col) {
 					continue
 				}
+				if mask != nil {
+					v = mask(col, v)
+				}
 				parts = append(parts, formatColumnValue(col, v))
 			}
 			b.WriteString("- " + strings.Join(parts, ", ") + "\n")
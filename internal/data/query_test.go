@@ -93,6 +93,16 @@ func TestReadOnlyQueryRejectsAttach(t *testing.T) {
 	assert.Contains(t, err.Error(), "disallowed keyword: ATTACH")
 }
 
+func TestReadOnlyQueryRejectsPrivateTables(t *testing.T) {
+	store := newTestStore(t)
+	_, _, err := store.ReadOnlyQuery(`SELECT password_hash FROM "users"`)
+	require.ErrorContains(t, err, "users table can't be queried")
+
+	names, err := store.TableNames()
+	require.NoError(t, err)
+	assert.NotContains(t, names, "api_tokens")
+}
+
 func TestReadOnlyQueryRejectsPragma(t *testing.T) {
 	store := newTestStore(t)
 	_, _, err := store.ReadOnlyQuery(
@@ -164,6 +174,21 @@ func TestDataDumpExcludesSoftDeletedRecords(t *testing.T) {
 		"active vendor should appear in DataDump")
 }
 
+func TestMaskedDump(t *testing.T) {
+	store := newTestStore(t)
+	require.NoError(t, store.db.Create(&Vendor{Name: "Acme Plumbing", Phone: "555-0100"}).Error)
+
+	dump := store.MaskedDump(func(col, v string) string {
+		if col == "phone" {
+			return "[redacted]"
+		}
+		return v
+	})
+	assert.Contains(t, dump, "Acme Plumbing")
+	assert.Contains(t, dump, "phone: [redacted]")
+	assert.NotContains(t, dump, "555-0100")
+}
+
 func TestColumnHints(t *testing.T) {
 	store := newTestStoreWithDemoData(t, testSeed)
 
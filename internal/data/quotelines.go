@@ -0,0 +1,171 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Quote line categories, matching the quote's labor, materials and other
+// amounts.
+const (
+	QuoteLineLabor     = "labor"
+	QuoteLineMaterials = "materials"
+	QuoteLineOther     = "other"
+)
+
+// QuoteLineCategories lists the categories a quote line can be in.
+func QuoteLineCategories() []string {
+	return []string{QuoteLineLabor, QuoteLineMaterials, QuoteLineOther}
+}
+
+// ErrQuoteLines means a quote's lines are incomplete or don't add up to its
+// total.
+var ErrQuoteLines = errors.New("quote lines don't check out")
+
+// QuoteLine is one line of an itemized quote. AmountCents is Quantity times
+// UnitCents, rounded to the cent, and is worked out on save.
+type QuoteLine struct {
+	ID          uint `gorm:"primaryKey"`
+	QuoteID     uint `gorm:"index"`
+	Description string
+	Quantity    float64
+	UnitCents   int64
+	AmountCents int64
+	Category    string
+	SortOrder   int
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func quoteLinesPreload(q *gorm.DB) *gorm.DB {
+	return q.Order(ColSortOrder + ", " + ColID)
+}
+
+// itemizeQuote checks a quote's lines and fills in what follows from them:
+// each line's amount, and the quote's labor, materials and other amounts.
+// The lines have to add up to TotalCents. A quote without lines is left as
+// it is.
+func itemizeQuote(quote *Quote, lines []QuoteLine) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	sums := map[string]int64{}
+	var total int64
+	for i := range lines {
+		l := &lines[i]
+		l.Description = strings.TrimSpace(l.Description)
+		l.Category = strings.ToLower(strings.TrimSpace(l.Category))
+		if l.Category == "" {
+			l.Category = QuoteLineOther
+		}
+		switch {
+		case l.Description == "":
+			return fmt.Errorf("%w: line %d needs a description", ErrQuoteLines, i+1)
+		case l.Quantity <= 0:
+			return fmt.Errorf("%w: %q needs a quantity above zero", ErrQuoteLines, l.Description)
+		case l.UnitCents < 0:
+			return fmt.Errorf("%w: %q has a negative price", ErrQuoteLines, l.Description)
+		case !slices.Contains(QuoteLineCategories(), l.Category):
+			return fmt.Errorf("%w: %q has unknown category %q -- use one of %s",
+				ErrQuoteLines, l.Description, l.Category, strings.Join(QuoteLineCategories(), ", "))
+		}
+		l.AmountCents = int64(math.Round(l.Quantity * float64(l.UnitCents)))
+		l.SortOrder = i
+		sums[l.Category] += l.AmountCents
+		total += l.AmountCents
+	}
+	if total != quote.TotalCents {
+		return fmt.Errorf("%w: the lines add up to %s but the total is %s",
+			ErrQuoteLines, FormatCents(total), FormatCents(quote.TotalCents))
+	}
+	subtotal := func(category string) *int64 {
+		if v, ok := sums[category]; ok {
+			return &v
+		}
+		return nil
+	}
+	quote.LaborCents = subtotal(QuoteLineLabor)
+	quote.MaterialsCents = subtotal(QuoteLineMaterials)
+	quote.OtherCents = subtotal(QuoteLineOther)
+	return nil
+}
+
+// replaceQuoteLines swaps a quote's lines for new ones.
+func replaceQuoteLines(tx *gorm.DB, quoteID uint, lines []QuoteLine) error {
+	if err := tx.Where(ColQuoteID+" = ?", quoteID).Delete(&QuoteLine{}).Error; err != nil {
+		return err
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	for i := range lines {
+		lines[i].ID = 0
+		lines[i].QuoteID = quoteID
+	}
+	return tx.Create(&lines).Error
+}
+
+// QuoteComparison lines up a project's itemized quotes. Lines are matched
+// across quotes by description, ignoring case and spacing.
+type QuoteComparison struct {
+	Quotes []Quote
+	Rows   []QuoteComparisonRow
+}
+
+// QuoteComparisonRow is one line item across the quotes. AmountCents holds
+// one entry per quote, in the order of QuoteComparison.Quotes, nil where a
+// quote doesn't have the line.
+type QuoteComparisonRow struct {
+	Description string
+	Category    string
+	AmountCents []*int64
+}
+
+// CompareQuotes lines up the live quotes for a project, cheapest first.
+func (s *Store) CompareQuotes(projectID uint) (QuoteComparison, error) {
+	var quotes []Quote
+	err := s.db.Where(ColProjectID+" = ?", projectID).
+		Preload("Vendor", func(q *gorm.DB) *gorm.DB { return q.Unscoped() }).
+		Preload("Lines", quoteLinesPreload).
+		Order(ColTotalCents + ", " + ColID).
+		Find(&quotes).Error
+	if err != nil {
+		return QuoteComparison{}, err
+	}
+	cmp := QuoteComparison{Quotes: quotes, Rows: []QuoteComparisonRow{}}
+	index := map[string]int{}
+	for qi, q := range quotes {
+		for _, l := range q.Lines {
+			key := l.Category + "\x00" + strings.Join(strings.Fields(strings.ToLower(l.Description)), " ")
+			ri, ok := index[key]
+			if !ok {
+				ri = len(cmp.Rows)
+				index[key] = ri
+				cmp.Rows = append(cmp.Rows, QuoteComparisonRow{
+					Description: l.Description,
+					Category:    l.Category,
+					AmountCents: make([]*int64, len(quotes)),
+				})
+			}
+			amount := l.AmountCents
+			if prev := cmp.Rows[ri].AmountCents[qi]; prev != nil {
+				amount += *prev
+			}
+			cmp.Rows[ri].AmountCents[qi] = &amount
+		}
+	}
+	order := map[string]int{QuoteLineLabor: 0, QuoteLineMaterials: 1, QuoteLineOther: 2}
+	slices.SortStableFunc(cmp.Rows, func(a, b QuoteComparisonRow) int {
+		return order[a.Category] - order[b.Category]
+	})
+	return cmp, nil
+}
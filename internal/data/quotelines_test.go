@@ -0,0 +1,112 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteLines(t *testing.T) {
+	store := newTestStore(t)
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	project := Project{Title: "Deck", ProjectTypeID: types[0].ID, Status: ProjectStatusQuoted}
+	require.NoError(t, store.CreateProject(&project))
+
+	quote := Quote{ProjectID: project.ID, TotalCents: 150000, Lines: []QuoteLine{
+		{Description: "Framing labor", Quantity: 16, UnitCents: 5000, Category: QuoteLineLabor},
+		{Description: "Deck boards", Quantity: 40, UnitCents: 1500, Category: "Materials"},
+		{Description: "Permit", Quantity: 1, UnitCents: 10000},
+	}}
+	err = store.CreateQuote(&Quote{ProjectID: project.ID, TotalCents: 1, Lines: quote.Lines},
+		Vendor{Name: "Acme Decks"})
+	require.ErrorIs(t, err, ErrQuoteLines, "lines must add up to the total")
+	err = store.CreateQuote(&Quote{ProjectID: project.ID, TotalCents: 5000, Lines: []QuoteLine{
+		{Description: "Stain", Quantity: 0, UnitCents: 5000},
+	}}, Vendor{Name: "Acme Decks"})
+	require.ErrorIs(t, err, ErrQuoteLines)
+
+	require.NoError(t, store.CreateQuote(&quote, Vendor{Name: "Acme Decks"}))
+	got, err := store.GetQuote(quote.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Lines, 3)
+	assert.Equal(t, "Framing labor", got.Lines[0].Description)
+	assert.Equal(t, int64(80000), got.Lines[0].AmountCents)
+	assert.Equal(t, QuoteLineMaterials, got.Lines[1].Category)
+	assert.Equal(t, QuoteLineOther, got.Lines[2].Category)
+	require.NotNil(t, got.LaborCents)
+	assert.Equal(t, int64(80000), *got.LaborCents)
+	require.NotNil(t, got.MaterialsCents)
+	assert.Equal(t, int64(60000), *got.MaterialsCents)
+	require.NotNil(t, got.OtherCents)
+	assert.Equal(t, int64(10000), *got.OtherCents)
+
+	// Without lines, an update keeps them and the total still has to match.
+	got.Lines = nil
+	got.TotalCents = 140000
+	require.ErrorIs(t, store.UpdateQuote(got, Vendor{Name: "Acme Decks"}), ErrQuoteLines)
+	got.Notes = "Includes cleanup"
+	got.TotalCents = 150000
+	require.NoError(t, store.UpdateQuote(got, Vendor{Name: "Acme Decks"}))
+	got, err = store.GetQuote(quote.ID)
+	require.NoError(t, err)
+	assert.Len(t, got.Lines, 3)
+	assert.Equal(t, "Includes cleanup", got.Notes)
+
+	// New lines replace the old ones.
+	got.Lines = []QuoteLine{{Description: "Everything", Quantity: 1, UnitCents: 150000, Category: QuoteLineLabor}}
+	require.NoError(t, store.UpdateQuote(got, Vendor{Name: "Acme Decks"}))
+	got, err = store.GetQuote(quote.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Lines, 1)
+	assert.Nil(t, got.MaterialsCents)
+
+	// An empty slice drops the itemization and leaves the amounts alone.
+	got.Lines = []QuoteLine{}
+	got.TotalCents = 120000
+	require.NoError(t, store.UpdateQuote(got, Vendor{Name: "Acme Decks"}))
+	got, err = store.GetQuote(quote.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.Lines)
+	assert.Equal(t, int64(120000), got.TotalCents)
+}
+
+func TestCompareQuotes(t *testing.T) {
+	store := newTestStore(t)
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	project := Project{Title: "Roof", ProjectTypeID: types[0].ID, Status: ProjectStatusQuoted}
+	require.NoError(t, store.CreateProject(&project))
+
+	require.NoError(t, store.CreateQuote(&Quote{ProjectID: project.ID, TotalCents: 900000, Lines: []QuoteLine{
+		{Description: "Shingles", Quantity: 30, UnitCents: 20000, Category: QuoteLineMaterials},
+		{Description: "Tear-off and install", Quantity: 1, UnitCents: 300000, Category: QuoteLineLabor},
+	}}, Vendor{Name: "Top Roofing"}))
+	require.NoError(t, store.CreateQuote(&Quote{ProjectID: project.ID, TotalCents: 800000, Lines: []QuoteLine{
+		{Description: "tear-off  and INSTALL", Quantity: 1, UnitCents: 250000, Category: QuoteLineLabor},
+		{Description: "Shingles", Quantity: 30, UnitCents: 18000, Category: QuoteLineMaterials},
+		{Description: "Dumpster", Quantity: 1, UnitCents: 10000},
+	}}, Vendor{Name: "Budget Roofs"}))
+
+	cmp, err := store.CompareQuotes(project.ID)
+	require.NoError(t, err)
+	require.Len(t, cmp.Quotes, 2)
+	assert.Equal(t, "Budget Roofs", cmp.Quotes[0].Vendor.Name, "cheapest first")
+	require.Len(t, cmp.Rows, 3)
+
+	labor := cmp.Rows[0]
+	assert.Equal(t, QuoteLineLabor, labor.Category)
+	require.Len(t, labor.AmountCents, 2)
+	assert.Equal(t, int64(250000), *labor.AmountCents[0])
+	assert.Equal(t, int64(300000), *labor.AmountCents[1])
+
+	assert.Equal(t, "Shingles", cmp.Rows[1].Description)
+	dumpster := cmp.Rows[2]
+	assert.Equal(t, "Dumpster", dumpster.Description)
+	assert.NotNil(t, dumpster.AmountCents[0])
+	assert.Nil(t, dumpster.AmountCents[1], "Top Roofing has no dumpster line")
+}
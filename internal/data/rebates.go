@@ -0,0 +1,199 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Rebate statuses, in the order a rebate usually goes through them.
+const (
+	RebateStatusPlanned   = "planned"
+	RebateStatusSubmitted = "submitted"
+	RebateStatusApproved  = "approved"
+	RebateStatusPaid      = "paid"
+	RebateStatusDenied    = "denied"
+)
+
+// RebateStatuses lists the statuses of a rebate in the order they usually
+// come.
+func RebateStatuses() []string {
+	return []string{RebateStatusPlanned, RebateStatusSubmitted, RebateStatusApproved, RebateStatusPaid, RebateStatusDenied}
+}
+
+// rebateFollowUpDays is how long after submitting a rebate to chase it
+// when no follow-up date is given.
+const rebateFollowUpDays = 42
+
+// Rebate is a utility or government rebate or incentive the house is
+// owed for a project or an appliance: the program, what it pays, and
+// where the application stands. A planned rebate comes due on ApplyBy,
+// the program's deadline; a submitted or approved one on FollowUpOn, when
+// it's time to ask after the money. Submitting dates SubmittedOn today and
+// sets a follow-up six weeks on, unless given; marking it paid dates
+// PaidOn today and takes PaidCents to be the full amount, unless given.
+type Rebate struct {
+	ID      uint  `gorm:"primaryKey"`
+	HouseID *uint `gorm:"index"`
+	Program string
+	// Provider is who pays it: the utility, the state, the manufacturer.
+	Provider    string
+	AmountCents int64
+	Status      string
+	ApplyBy     *time.Time
+	SubmittedOn *time.Time
+	FollowUpOn  *time.Time
+	PaidOn      *time.Time
+	PaidCents   *int64
+	ProjectID   *uint     `gorm:"index"`
+	Project     Project   `gorm:"constraint:OnDelete:SET NULL;"`
+	ApplianceID *uint     `gorm:"index"`
+	Appliance   Appliance `gorm:"constraint:OnDelete:SET NULL;"`
+	DocumentID  *uint
+	Notes       string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Version     int            `gorm:"not null;default:1"`
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+// DueOn is when the rebate next needs something done: its deadline while
+// it's planned, its follow-up once it's in. It's nil for a rebate that's
+// settled or has no date.
+func (r Rebate) DueOn() *time.Time {
+	switch r.Status {
+	case RebateStatusPlanned:
+		return r.ApplyBy
+	case RebateStatusSubmitted, RebateStatusApproved:
+		return r.FollowUpOn
+	}
+	return nil
+}
+
+// Title names the rebate by its program and what it's for.
+func (r Rebate) Title() string {
+	title := r.Program + " rebate"
+	switch {
+	case r.ApplianceID != nil && r.Appliance.Name != "":
+		title += " for " + r.Appliance.Name
+	case r.ProjectID != nil && r.Project.Title != "":
+		title += " for " + r.Project.Title
+	}
+	return title
+}
+
+// NextStep says what the rebate is waiting on, "Apply for the Heat pump
+// rebate" while it's planned and "Follow up on the Heat pump rebate" once
+// it's in.
+func (r Rebate) NextStep() string {
+	if r.Status == RebateStatusPlanned {
+		return "Apply for the " + r.Title()
+	}
+	return "Follow up on the " + r.Title()
+}
+
+func preloadRebate(db *gorm.DB) *gorm.DB {
+	unscoped := func(q *gorm.DB) *gorm.DB { return q.Unscoped() }
+	return db.Preload("Project", unscoped).Preload("Appliance", unscoped)
+}
+
+// ListRebates returns the current house's rebates, newest first.
+func (s *Store) ListRebates(includeDeleted bool) ([]Rebate, error) {
+	var items []Rebate
+	db := preloadRebate(s.db).Scopes(s.inHouse("rebates")).
+		Order(ColCreatedAt + " desc, " + ColID + " desc")
+	if includeDeleted {
+		db = db.Unscoped()
+	}
+	return items, db.Find(&items).Error
+}
+
+func (s *Store) GetRebate(id uint) (Rebate, error) {
+	var item Rebate
+	err := preloadRebate(s.db).First(&item, id).Error
+	return item, err
+}
+
+func (s *Store) CreateRebate(item *Rebate, now time.Time) error {
+	if err := validateRebate(item, now); err != nil {
+		return err
+	}
+	return s.db.Create(item).Error
+}
+
+func (s *Store) UpdateRebate(item Rebate, now time.Time) error {
+	if err := validateRebate(&item, now); err != nil {
+		return err
+	}
+	return s.updateByID(&Rebate{}, item.ID, item)
+}
+
+func (s *Store) DeleteRebate(id uint) error {
+	return s.softDelete(&Rebate{}, DeletionEntityRebate, id)
+}
+
+func (s *Store) RestoreRebate(id uint) error {
+	return s.restoreEntity(&Rebate{}, DeletionEntityRebate, id)
+}
+
+// ListRebatesDue returns the current house's open rebates due by now +
+// within, overdue ones included, soonest first: planned ones by their
+// deadline and submitted or approved ones by their follow-up.
+func (s *Store) ListRebatesDue(now time.Time, within time.Duration) ([]Rebate, error) {
+	var items []Rebate
+	err := preloadRebate(s.db).Scopes(s.inHouse("rebates")).
+		Where("(status = ? AND apply_by IS NOT NULL) OR (status IN ? AND follow_up_on IS NOT NULL)",
+			RebateStatusPlanned, []string{RebateStatusSubmitted, RebateStatusApproved}).
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	until := now.Add(within)
+	items = slices.DeleteFunc(items, func(r Rebate) bool { return r.DueOn().After(until) })
+	slices.SortStableFunc(items, func(a, b Rebate) int {
+		return cmp.Or(a.DueOn().Compare(*b.DueOn()), cmp.Compare(a.ID, b.ID))
+	})
+	return items, nil
+}
+
+func validateRebate(r *Rebate, now time.Time) error {
+	r.Program = strings.TrimSpace(r.Program)
+	r.Provider = strings.TrimSpace(r.Provider)
+	if r.Status == "" {
+		r.Status = RebateStatusPlanned
+	}
+	switch {
+	case r.Program == "":
+		return fmt.Errorf("a rebate needs a program")
+	case r.AmountCents < 0 || (r.PaidCents != nil && *r.PaidCents < 0):
+		return fmt.Errorf("a rebate's amounts can't be negative")
+	case !slices.Contains(RebateStatuses(), r.Status):
+		return fmt.Errorf("unknown rebate status %q", r.Status)
+	}
+	if r.Status != RebateStatusPlanned && r.SubmittedOn == nil {
+		day := now
+		r.SubmittedOn = &day
+	}
+	if (r.Status == RebateStatusSubmitted || r.Status == RebateStatusApproved) && r.FollowUpOn == nil {
+		day := r.SubmittedOn.AddDate(0, 0, rebateFollowUpDays)
+		r.FollowUpOn = &day
+	}
+	if r.Status == RebateStatusPaid {
+		if r.PaidOn == nil {
+			day := now
+			r.PaidOn = &day
+		}
+		if r.PaidCents == nil {
+			paid := r.AmountCents
+			r.PaidCents = &paid
+		}
+	}
+	return nil
+}
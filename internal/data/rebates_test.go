@@ -0,0 +1,75 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRebates(t *testing.T) {
+	store := newTestStore(t)
+	now := time.Date(2026, 2, 8, 0, 0, 0, 0, time.UTC)
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 0, 0, 0, 0, time.UTC) }
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	heater := Appliance{Name: "Water heater"}
+	require.NoError(t, store.CreateAppliance(&heater))
+
+	require.ErrorContains(t, store.CreateRebate(&Rebate{AmountCents: 100}, now), "needs a program")
+	require.ErrorContains(t, store.CreateRebate(&Rebate{Program: "HPWH", AmountCents: -1}, now), "can't be negative")
+	require.ErrorContains(t, store.CreateRebate(&Rebate{Program: "HPWH", Status: "lost"}, now), "unknown rebate status")
+
+	hpwh := Rebate{Program: "Heat pump water heater", AmountCents: 15000, ApplyBy: ptr(day(2, 20)), ApplianceID: &heater.ID}
+	require.NoError(t, store.CreateRebate(&hpwh, now))
+	assert.Equal(t, RebateStatusPlanned, hpwh.Status)
+	assert.Nil(t, hpwh.SubmittedOn)
+
+	// Submitting dates it today and follows up six weeks on.
+	insulation := Rebate{Program: "Attic insulation", AmountCents: 50000, Status: RebateStatusSubmitted}
+	require.NoError(t, store.CreateRebate(&insulation, now))
+	require.NotNil(t, insulation.SubmittedOn)
+	assert.Equal(t, now, *insulation.SubmittedOn)
+	assert.Equal(t, day(3, 22), *insulation.FollowUpOn)
+
+	late := Rebate{Program: "Thermostat", AmountCents: 5000, ApplyBy: ptr(day(6, 1))}
+	require.NoError(t, store.CreateRebate(&late, now))
+
+	due, err := store.ListRebatesDue(now, 30*24*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "Apply for the Heat pump water heater rebate for Water heater", due[0].NextStep())
+
+	due, err = store.ListRebatesDue(now, 60*24*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Equal(t, insulation.ID, due[1].ID)
+	assert.Equal(t, "Follow up on the Attic insulation rebate", due[1].NextStep())
+
+	// Paid in full unless said otherwise, and no longer due.
+	insulation.Status = RebateStatusPaid
+	require.NoError(t, store.UpdateRebate(insulation, day(3, 1)))
+	got, err := store.GetRebate(insulation.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.PaidCents)
+	assert.Equal(t, int64(50000), *got.PaidCents)
+	assert.Equal(t, day(3, 1), got.PaidOn.UTC())
+	assert.Nil(t, got.DueOn())
+
+	due, err = store.ListRebatesDue(now, 60*24*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+
+	require.NoError(t, store.DeleteRebate(hpwh.ID))
+	due, err = store.ListRebatesDue(now, 60*24*time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+	require.NoError(t, store.RestoreRebate(hpwh.ID))
+	all, err := store.ListRebates(false)
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// ReminderState is what the scheduled reminders remember about one thing
+// they have sent to one channel, so they can hold off repeating it and
+// escalate it when it's ignored. Channel identifies the channel, Kind is
+// the reminder kind and TargetID the record it is about. Due is the date
+// it was sent for; a new date starts it over. Sent counts the times it was
+// sent since it went overdue, or before then.
+type ReminderState struct {
+	ID         uint   `gorm:"primaryKey"`
+	Channel    string `gorm:"uniqueIndex:idx_reminder_state,priority:1"`
+	Kind       string `gorm:"uniqueIndex:idx_reminder_state,priority:2"`
+	TargetID   uint   `gorm:"uniqueIndex:idx_reminder_state,priority:3"`
+	Due        time.Time
+	Overdue    bool
+	Sent       int
+	LastSentAt time.Time `gorm:"index"`
+}
+
+// ReminderStates returns every remembered reminder.
+func (s *Store) ReminderStates() ([]ReminderState, error) {
+	var states []ReminderState
+	err := s.db.Find(&states).Error
+	return states, err
+}
+
+// SaveReminderState stores a reminder's state, replacing what was
+// remembered about the same channel, kind and record.
+func (s *Store) SaveReminderState(st ReminderState) error {
+	st.ID = 0
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "channel"}, {Name: ColKind}, {Name: ColTargetID}},
+		DoUpdates: clause.AssignmentColumns([]string{"due", "overdue", "sent", "last_sent_at"}),
+	}).Create(&st).Error
+}
+
+// PruneReminderStates forgets reminders last sent before the cutoff, which
+// have long since been dealt with.
+func (s *Store) PruneReminderStates(before time.Time) error {
+	return s.db.Where("last_sent_at < ?", before).Delete(&ReminderState{}).Error
+}
+
+// ReminderSnooze holds off reminders about one record until Until, as
+// asked from a reminder's snooze link.
+type ReminderSnooze struct {
+	ID       uint   `gorm:"primaryKey"`
+	Kind     string `gorm:"uniqueIndex:idx_reminder_snooze,priority:1"`
+	TargetID uint   `gorm:"uniqueIndex:idx_reminder_snooze,priority:2"`
+	Until    time.Time
+}
+
+// SnoozeReminder holds off reminders of the kind about the record until
+// the given time, replacing any earlier snooze.
+func (s *Store) SnoozeReminder(kind string, id uint, until time.Time) error {
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: ColKind}, {Name: ColTargetID}},
+		DoUpdates: clause.AssignmentColumns([]string{"until"}),
+	}).Create(&ReminderSnooze{Kind: kind, TargetID: id, Until: until}).Error
+}
+
+// ReminderSnoozes returns the snoozes still in effect at now, and forgets
+// the rest.
+func (s *Store) ReminderSnoozes(now time.Time) ([]ReminderSnooze, error) {
+	if err := s.db.Where("until <= ?", now).Delete(&ReminderSnooze{}).Error; err != nil {
+		return nil, err
+	}
+	var snoozes []ReminderSnooze
+	err := s.db.Find(&snoozes).Error
+	return snoozes, err
+}
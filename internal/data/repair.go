@@ -0,0 +1,245 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// DanglingRef is a row pointing at a row that doesn't exist, not even in
+// the trash. They date from databases that were edited without foreign
+// keys, or from before records were soft-deleted.
+type DanglingRef struct {
+	// Table and RowID name the row holding the reference.
+	Table string
+	RowID uint
+	// Column holds the reference; for documents it is entity_id.
+	Column string
+	// Target and TargetID are where it points.
+	Target   string
+	TargetID uint
+	// Nullable references can be detached; the others have to be relinked
+	// or their row purged.
+	Nullable bool
+}
+
+func (r DanglingRef) String() string {
+	return fmt.Sprintf("%s #%d: %s points at missing %s #%d", r.Table, r.RowID, r.Column, r.Target, r.TargetID)
+}
+
+// documentParents maps document entity kinds to the models they attach to.
+var documentParents = map[string]any{
+	DocumentEntityProject:       &Project{},
+	DocumentEntityQuote:         &Quote{},
+	DocumentEntityMaintenance:   &MaintenanceItem{},
+	DocumentEntityAppliance:     &Appliance{},
+	DocumentEntityServiceLog:    &ServiceLogEntry{},
+	DocumentEntityVendor:        &Vendor{},
+	DocumentEntityIncident:      &Incident{},
+	DocumentEntitySmartDevice:   &SmartDevice{},
+	DocumentEntityLandscape:     &LandscapeAsset{},
+	DocumentEntityPestTreatment: &PestTreatment{},
+	DocumentEntityWaterTest:     &WaterTest{},
+	DocumentEntityWalkthrough:   &Walkthrough{},
+	DocumentEntitySitterStay:    &SitterStay{},
+	DocumentEntityInventory:     &InventoryItem{},
+}
+
+// ErrNotDangling means a repair was asked for a reference that no longer
+// dangles, or never did.
+var ErrNotDangling = errors.New("reference isn't dangling")
+
+// DanglingRefs finds every live row whose foreign key, or whose document
+// attachment, points at a row that doesn't exist.
+func (s *Store) DanglingRefs() ([]DanglingRef, error) {
+	var refs []DanglingRef
+	for _, model := range allModels() {
+		stmt := &gorm.Statement{DB: s.db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("parse model: %w", err)
+		}
+		for _, rel := range stmt.Schema.Relationships.BelongsTo {
+			if len(rel.References) != 1 {
+				continue
+			}
+			fk := rel.References[0].ForeignKey
+			parent := rel.FieldSchema.Table
+			rowID, ref, parentID := stmt.Schema.Table+"."+ColID, stmt.Schema.Table+"."+fk.DBName, parent+"."+ColID
+			var rows []struct{ ID, Ref uint }
+			err := s.db.Model(model).
+				Select(rowID + " AS id, " + ref + " AS ref").
+				Joins("LEFT JOIN " + parent + " ON " + parentID + " = " + ref).
+				Where(ref + " IS NOT NULL AND " + parentID + " IS NULL").
+				Order(rowID).
+				Scan(&rows).Error
+			if err != nil {
+				return nil, fmt.Errorf("check %s.%s: %w", stmt.Schema.Table, fk.DBName, err)
+			}
+			for _, row := range rows {
+				refs = append(refs, DanglingRef{
+					Table: stmt.Schema.Table, RowID: row.ID, Column: fk.DBName,
+					Target: parent, TargetID: row.Ref,
+					Nullable: fk.FieldType.Kind() == reflect.Pointer,
+				})
+			}
+		}
+	}
+
+	docRefs, err := s.danglingDocuments()
+	if err != nil {
+		return nil, err
+	}
+	return append(refs, docRefs...), nil
+}
+
+func (s *Store) danglingDocuments() ([]DanglingRef, error) {
+	var docs []Document
+	if err := s.db.Select(ColID, ColEntityKind, ColEntityID).
+		Where(ColEntityKind+" != ?", DocumentEntityNone).
+		Order(ColID).Find(&docs).Error; err != nil {
+		return nil, fmt.Errorf("list attached documents: %w", err)
+	}
+	var refs []DanglingRef
+	for _, d := range docs {
+		parent, ok := documentParents[d.EntityKind]
+		if !ok {
+			continue
+		}
+		target, err := s.tableOf(parent)
+		if err != nil {
+			return nil, err
+		}
+		exists, err := rowExists(s.db, parent, d.EntityID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			refs = append(refs, DanglingRef{
+				Table: TableDocuments, RowID: d.ID, Column: ColEntityID,
+				Target: target, TargetID: d.EntityID, Nullable: true,
+			})
+		}
+	}
+	return refs, nil
+}
+
+// TableDocuments is the documents table, whose references are by entity
+// kind and ID rather than foreign keys.
+const TableDocuments = "documents"
+
+// DetachRef clears a nullable reference. Detached documents stay in the
+// library without a record.
+func (s *Store) DetachRef(ref DanglingRef) error {
+	if !ref.Nullable {
+		return fmt.Errorf("%s.%s is required and can't be detached -- relink or purge the row", ref.Table, ref.Column)
+	}
+	values := map[string]any{ref.Column: nil}
+	if ref.Table == TableDocuments {
+		values = map[string]any{ColEntityKind: DocumentEntityNone, ColEntityID: 0}
+	}
+	return s.repairRef(ref, func(tx *gorm.DB, model any) error {
+		return recordChanges(tx, model, ref.RowID, func(tx *gorm.DB) error {
+			return tx.Model(model).Where(ColID+" = ?", ref.RowID).UpdateColumns(values).Error
+		})
+	})
+}
+
+// RelinkRef points a reference at another row of the same table, which
+// must be live.
+func (s *Store) RelinkRef(ref DanglingRef, to uint) error {
+	target, err := s.modelOf(ref.Target)
+	if err != nil {
+		return err
+	}
+	if err := s.db.First(target, to).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%s #%d doesn't exist or is in the trash", ref.Target, to)
+		}
+		return err
+	}
+	return s.repairRef(ref, func(tx *gorm.DB, model any) error {
+		return recordChanges(tx, model, ref.RowID, func(tx *gorm.DB) error {
+			return tx.Model(model).Where(ColID+" = ?", ref.RowID).UpdateColumn(ref.Column, to).Error
+		})
+	})
+}
+
+// PurgeRef deletes the row holding the reference for good. Rows that
+// pointed at it may dangle in turn.
+func (s *Store) PurgeRef(ref DanglingRef) error {
+	return s.repairRef(ref, func(tx *gorm.DB, model any) error {
+		if _, ok := model.(*Document); ok {
+			if err := handOffContent(tx, ref.RowID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Unscoped().Delete(model, ref.RowID).Error; err != nil {
+			return err
+		}
+		return audit(tx, AuditEntry{Action: AuditPurge, Entity: ref.Table, TargetID: ref.RowID})
+	})
+}
+
+// repairRef runs fix on the reference's row, in a transaction, after
+// checking that it still dangles. Detaching and relinking are recorded in
+// the row's history so they can be reverted.
+func (s *Store) repairRef(ref DanglingRef, fix func(tx *gorm.DB, model any) error) error {
+	model, err := s.modelOf(ref.Table)
+	if err != nil {
+		return err
+	}
+	target, err := s.modelOf(ref.Target)
+	if err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var current uint
+		if err := tx.Unscoped().Model(model).Where(ColID+" = ?", ref.RowID).
+			Select(ref.Column).Scan(&current).Error; err != nil {
+			return err
+		}
+		if current != ref.TargetID {
+			return ErrNotDangling
+		}
+		if exists, err := rowExists(tx, target, ref.TargetID); err != nil {
+			return err
+		} else if exists {
+			return ErrNotDangling
+		}
+		return fix(tx, model)
+	})
+}
+
+// rowExists reports whether the row is there at all, trashed or not.
+func rowExists(db *gorm.DB, model any, id uint) (bool, error) {
+	var n int64
+	err := db.Unscoped().Model(model).Where(ColID+" = ?", id).Count(&n).Error
+	return n > 0, err
+}
+
+func (s *Store) tableOf(model any) (string, error) {
+	stmt := &gorm.Statement{DB: s.db}
+	if err := stmt.Parse(model); err != nil {
+		return "", fmt.Errorf("parse model: %w", err)
+	}
+	return stmt.Schema.Table, nil
+}
+
+// modelOf returns a new instance of the model stored in table.
+func (s *Store) modelOf(table string) (any, error) {
+	for _, model := range allModels() {
+		name, err := s.tableOf(model)
+		if err != nil {
+			return nil, err
+		}
+		if name == table {
+			return reflect.New(reflect.TypeOf(model).Elem()).Interface(), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownEntity, table)
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// hardDelete removes rows the way a database without foreign keys would
+// have, leaving whatever pointed at them dangling.
+func hardDelete(t *testing.T, store *Store, model any, ids ...uint) {
+	t.Helper()
+	require.NoError(t, store.db.Connection(func(tx *gorm.DB) error {
+		if err := tx.Exec("PRAGMA foreign_keys = OFF").Error; err != nil {
+			return err
+		}
+		defer tx.Exec("PRAGMA foreign_keys = ON")
+		return tx.Unscoped().Delete(model, ids).Error
+	}))
+}
+
+func TestDanglingRefsRepair(t *testing.T) {
+	store := newTestStore(t)
+	categories, err := store.MaintenanceCategories()
+	require.NoError(t, err)
+	gutters := MaintenanceItem{Name: "Gutters", CategoryID: categories[0].ID}
+	require.NoError(t, store.CreateMaintenance(&gutters))
+	furnace := MaintenanceItem{Name: "Furnace", CategoryID: categories[0].ID}
+	require.NoError(t, store.CreateMaintenance(&furnace))
+	entry := ServiceLogEntry{MaintenanceItemID: gutters.ID}
+	require.NoError(t, store.CreateServiceLog(&entry, Vendor{Name: "Ladders Inc"}))
+	require.NotNil(t, entry.VendorID)
+	doc := Document{
+		Title: "Gutter quote", FileName: "q.pdf", Data: []byte("x"),
+		EntityKind: DocumentEntityMaintenance, EntityID: gutters.ID,
+	}
+	require.NoError(t, store.CreateDocument(&doc))
+
+	refs, err := store.DanglingRefs()
+	require.NoError(t, err)
+	assert.Empty(t, refs)
+
+	// A trashed parent isn't missing.
+	require.NoError(t, store.db.Delete(&Vendor{}, *entry.VendorID).Error)
+	refs, err = store.DanglingRefs()
+	require.NoError(t, err)
+	assert.Empty(t, refs)
+
+	hardDelete(t, store, &Vendor{}, *entry.VendorID)
+	hardDelete(t, store, &MaintenanceItem{}, gutters.ID)
+	refs, err = store.DanglingRefs()
+	require.NoError(t, err)
+	require.Len(t, refs, 3)
+	byColumn := map[string]DanglingRef{}
+	for _, r := range refs {
+		byColumn[r.Table+"."+r.Column] = r
+	}
+	item := byColumn["service_log_entries.maintenance_item_id"]
+	assert.Equal(t, DanglingRef{
+		Table: "service_log_entries", RowID: entry.ID, Column: ColMaintenanceItemID,
+		Target: "maintenance_items", TargetID: gutters.ID,
+	}, item)
+	vendor := byColumn["service_log_entries.vendor_id"]
+	assert.True(t, vendor.Nullable)
+	attached := byColumn["documents.entity_id"]
+	assert.Equal(t, "maintenance_items", attached.Target)
+	assert.True(t, attached.Nullable)
+
+	require.ErrorContains(t, store.DetachRef(item), "can't be detached")
+	require.ErrorContains(t, store.RelinkRef(item, 9999), "doesn't exist")
+	require.NoError(t, store.RelinkRef(item, furnace.ID))
+	require.NoError(t, store.DetachRef(vendor))
+	require.NoError(t, store.DetachRef(attached))
+	assert.ErrorIs(t, store.DetachRef(attached), ErrNotDangling)
+
+	got, err := store.GetServiceLog(entry.ID)
+	require.NoError(t, err)
+	assert.Equal(t, furnace.ID, got.MaintenanceItemID)
+	assert.Nil(t, got.VendorID)
+	meta, err := store.GetDocumentMetadata(doc.ID)
+	require.NoError(t, err)
+	assert.Equal(t, DocumentEntityNone, meta.EntityKind)
+
+	refs, err = store.DanglingRefs()
+	require.NoError(t, err)
+	assert.Empty(t, refs)
+
+	history, err := store.FieldHistory("service_log_entries", entry.ID)
+	require.NoError(t, err)
+	assert.NotEmpty(t, history, "repairs are recorded")
+}
+
+func TestPurgeRef(t *testing.T) {
+	store := newTestStore(t)
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	p := Project{Title: "Fence", ProjectTypeID: types[0].ID, Status: ProjectStatusPlanned}
+	require.NoError(t, store.CreateProject(&p))
+	q := Quote{ProjectID: p.ID, TotalCents: 100}
+	require.NoError(t, store.CreateQuote(&q, Vendor{Name: "Posts R Us"}))
+
+	hardDelete(t, store, &Project{}, p.ID)
+	refs, err := store.DanglingRefs()
+	require.NoError(t, err)
+	require.Len(t, refs, 1)
+	require.NoError(t, store.PurgeRef(refs[0]))
+
+	var n int64
+	require.NoError(t, store.db.Unscoped().Model(&Quote{}).Where(ColID+" = ?", q.ID).Count(&n).Error)
+	assert.Zero(t, n)
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"fmt"
+	"time"
+)
+
+// RetentionOptions sets how long records may sit untouched before the
+// retention checks point them out.
+type RetentionOptions struct {
+	// DocumentYears is how long a document may go unopened.
+	DocumentYears int
+	// PlannedYears is how long a project may sit in planned.
+	PlannedYears int
+	// VendorYears is how long a vendor may go without any work.
+	VendorYears int
+}
+
+// DefaultRetentionOptions are the thresholds "webcasa doctor" uses unless
+// told otherwise.
+var DefaultRetentionOptions = RetentionOptions{DocumentYears: 3, PlannedYears: 2, VendorYears: 2}
+
+// RetentionReport lists records that are probably clutter. Nothing in it is
+// wrong as such; it's for the owner to decide.
+type RetentionReport struct {
+	// UnopenedDocuments haven't been downloaded, or if never, uploaded,
+	// within DocumentYears. Only their metadata is loaded.
+	UnopenedDocuments []Document
+	// StalePlannedProjects have been planned, and unchanged, for
+	// PlannedYears.
+	StalePlannedProjects []Project
+	// IdleVendors have no quotes, incidents or pest treatments, and no
+	// service logged within VendorYears.
+	IdleVendors []Vendor
+	// UnscheduledMaintenance items have no interval, so never come due.
+	UnscheduledMaintenance []MaintenanceItem
+}
+
+// RetentionReport runs the retention checks as of now.
+func (s *Store) RetentionReport(now time.Time, o RetentionOptions) (RetentionReport, error) {
+	var r RetentionReport
+
+	docCutoff := now.AddDate(-o.DocumentYears, 0, 0)
+	if err := s.db.Select(listDocumentColumns).
+		Where("COALESCE("+ColLastOpenedAt+", "+ColCreatedAt+") < ?", docCutoff).
+		Order(ColID).Find(&r.UnopenedDocuments).Error; err != nil {
+		return r, fmt.Errorf("find unopened documents: %w", err)
+	}
+
+	if err := s.db.
+		Where(ColStatus+" = ? AND "+ColUpdatedAt+" < ?", ProjectStatusPlanned, now.AddDate(-o.PlannedYears, 0, 0)).
+		Order(ColID).Find(&r.StalePlannedProjects).Error; err != nil {
+		return r, fmt.Errorf("find stale projects: %w", err)
+	}
+
+	vendorCutoff := now.AddDate(-o.VendorYears, 0, 0)
+	busy := func(model any) any {
+		return s.db.Model(model).Select(ColVendorID).Where(ColVendorID + " IS NOT NULL")
+	}
+	if err := s.db.
+		Where(ColCreatedAt+" < ?", vendorCutoff).
+		Where(ColID+" NOT IN (?)", busy(&Quote{})).
+		Where(ColID+" NOT IN (?)", busy(&Incident{})).
+		Where(ColID+" NOT IN (?)", busy(&PestTreatment{})).
+		Where(ColID+" NOT IN (?)", s.db.Model(&ServiceLogEntry{}).Select(ColVendorID).
+			Where(ColVendorID+" IS NOT NULL AND "+ColServicedAt+" >= ?", vendorCutoff)).
+		Order(ColID).Find(&r.IdleVendors).Error; err != nil {
+		return r, fmt.Errorf("find idle vendors: %w", err)
+	}
+
+	if err := s.db.Where(ColIntervalMonths + " <= 0").
+		Order(ColID).Find(&r.UnscheduledMaintenance).Error; err != nil {
+		return r, fmt.Errorf("find unscheduled maintenance: %w", err)
+	}
+	return r, nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetentionReport(t *testing.T) {
+	store := newTestStore(t)
+	now := time.Now()
+	old := now.AddDate(-5, 0, 0)
+
+	require.NoError(t, store.CreateVendor(&Vendor{Name: "Gone Fishing", CreatedAt: old}))
+	require.NoError(t, store.CreateVendor(&Vendor{Name: "New Co"}))
+	require.NoError(t, store.CreateVendor(&Vendor{Name: "Quoted", CreatedAt: old}))
+
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	stale := Project{Title: "Sunroom", ProjectTypeID: types[0].ID, Status: ProjectStatusPlanned, CreatedAt: old, UpdatedAt: old}
+	require.NoError(t, store.CreateProject(&stale))
+	require.NoError(t, store.CreateProject(&Project{Title: "Shed", ProjectTypeID: types[0].ID, Status: ProjectStatusPlanned}))
+	require.NoError(t, store.CreateProject(&Project{
+		Title: "Deck", ProjectTypeID: types[0].ID, Status: ProjectStatusInProgress, CreatedAt: old, UpdatedAt: old,
+	}))
+	require.NoError(t, store.CreateQuote(&Quote{ProjectID: stale.ID, TotalCents: 100}, Vendor{Name: "Quoted"}))
+
+	categories, err := store.MaintenanceCategories()
+	require.NoError(t, err)
+	adHoc := MaintenanceItem{Name: "Touch up paint", CategoryID: categories[0].ID}
+	require.NoError(t, store.CreateMaintenance(&adHoc))
+	furnace := MaintenanceItem{Name: "Furnace filter", CategoryID: categories[0].ID, IntervalMonths: 3}
+	require.NoError(t, store.CreateMaintenance(&furnace))
+	require.NoError(t, store.CreateVendor(&Vendor{Name: "Serviced", CreatedAt: old}))
+	require.NoError(t, store.CreateServiceLog(
+		&ServiceLogEntry{MaintenanceItemID: furnace.ID, ServicedAt: now.AddDate(0, -1, 0)}, Vendor{Name: "Serviced"}))
+
+	forgotten := Document{Title: "Old receipt", FileName: "r.pdf", Data: []byte("x"), CreatedAt: old}
+	require.NoError(t, store.CreateDocument(&forgotten))
+	opened := Document{Title: "Deed", FileName: "deed.pdf", Data: []byte("x"), CreatedAt: old}
+	require.NoError(t, store.CreateDocument(&opened))
+	require.NoError(t, store.TouchDocument(opened.ID, now))
+	require.NoError(t, store.CreateDocument(&Document{Title: "New", FileName: "n.pdf", Data: []byte("x")}))
+
+	r, err := store.RetentionReport(now, DefaultRetentionOptions)
+	require.NoError(t, err)
+
+	require.Len(t, r.UnopenedDocuments, 1)
+	assert.Equal(t, forgotten.ID, r.UnopenedDocuments[0].ID)
+	assert.Nil(t, r.UnopenedDocuments[0].Data)
+
+	require.Len(t, r.StalePlannedProjects, 1)
+	assert.Equal(t, "Sunroom", r.StalePlannedProjects[0].Title)
+
+	require.Len(t, r.IdleVendors, 1)
+	assert.Equal(t, "Gone Fishing", r.IdleVendors[0].Name)
+
+	require.Len(t, r.UnscheduledMaintenance, 1)
+	assert.Equal(t, adHoc.ID, r.UnscheduledMaintenance[0].ID)
+}
+
+func TestTouchDocumentKeepsUpdatedAt(t *testing.T) {
+	store := newTestStore(t)
+	doc := Document{Title: "Manual", FileName: "m.pdf", Data: []byte("x")}
+	require.NoError(t, store.CreateDocument(&doc))
+	before, err := store.GetDocumentMetadata(doc.ID)
+	require.NoError(t, err)
+
+	at := time.Now().Add(time.Minute)
+	require.NoError(t, store.TouchDocument(doc.ID, at))
+	require.NoError(t, store.TouchDocument(doc.ID, at.Add(time.Minute)))
+	after, err := store.GetDocumentMetadata(doc.ID)
+	require.NoError(t, err)
+	require.NotNil(t, after.LastOpenedAt)
+	assert.WithinDuration(t, at, *after.LastOpenedAt, time.Millisecond, "opens are recorded at most hourly")
+	assert.Equal(t, before.UpdatedAt, after.UpdatedAt)
+	assert.Equal(t, before.Version, after.Version)
+}
@@ -0,0 +1,283 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Nominal opening sizes subtracted from wall area: a 3'x7' door and a
+// 3'x5' window.
+const (
+	doorAreaSqFt   = 21.0
+	windowAreaSqFt = 15.0
+)
+
+// Room is a space in the house with its measured dimensions, used for
+// material estimates. Dimensions are in feet.
+type Room struct {
+	ID        uint  `gorm:"primaryKey"`
+	HouseID   *uint `gorm:"index"`
+	Name      string
+	Level     string
+	LengthFt  float64
+	WidthFt   float64
+	HeightFt  float64
+	Doors     int
+	Windows   int
+	Notes     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Version   int            `gorm:"not null;default:1"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+// FloorAreaSqFt returns the floor area in square feet.
+func (r Room) FloorAreaSqFt() float64 {
+	return r.LengthFt * r.WidthFt
+}
+
+// WallAreaSqFt returns the paintable wall area in square feet: the
+// perimeter times the ceiling height, less doors and windows.
+func (r Room) WallAreaSqFt() float64 {
+	gross := 2 * (r.LengthFt + r.WidthFt) * r.HeightFt
+	openings := float64(r.Doors)*doorAreaSqFt + float64(r.Windows)*windowAreaSqFt
+	return max(gross-openings, 0)
+}
+
+// Finish surfaces, in display order.
+const (
+	FinishSurfaceWalls   = "walls"
+	FinishSurfaceCeiling = "ceiling"
+	FinishSurfaceTrim    = "trim"
+	FinishSurfaceFloor   = "floor"
+	FinishSurfaceOther   = "other"
+)
+
+// FinishSurfaces returns the surfaces a finish can be recorded for.
+func FinishSurfaces() []string {
+	return []string{
+		FinishSurfaceWalls, FinishSurfaceCeiling, FinishSurfaceTrim,
+		FinishSurfaceFloor, FinishSurfaceOther,
+	}
+}
+
+// RoomFinish records what a surface in a room is finished with -- the paint
+// color on the walls, the flooring product, and so on -- so touch-ups and
+// replacements can match.
+type RoomFinish struct {
+	ID          uint   `gorm:"primaryKey"`
+	RoomID      uint   `gorm:"index"`
+	Room        Room   `gorm:"constraint:OnDelete:CASCADE;"`
+	Surface     string `gorm:"index"`
+	Material    string
+	Brand       string
+	Color       string
+	Sheen       string
+	InstalledAt *time.Time
+	Notes       string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Version     int            `gorm:"not null;default:1"`
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+// RoomDrilldown is everything tied to one room.
+type RoomDrilldown struct {
+	Room       Room
+	Appliances []Appliance
+	Projects   []Project
+	Finishes   []RoomFinish
+	Estimates  []MaterialEstimate
+}
+
+func (s *Store) ListRooms(includeDeleted bool) ([]Room, error) {
+	var items []Room
+	db := s.db.Scopes(s.inHouse("rooms")).Order(ColName + ", " + ColID)
+	if includeDeleted {
+		db = db.Unscoped()
+	}
+	return items, db.Find(&items).Error
+}
+
+func (s *Store) GetRoom(id uint) (Room, error) {
+	var item Room
+	err := s.db.First(&item, id).Error
+	return item, err
+}
+
+func (s *Store) CreateRoom(item *Room) error {
+	if err := validateRoom(item); err != nil {
+		return err
+	}
+	return s.db.Create(item).Error
+}
+
+func (s *Store) UpdateRoom(item Room) error {
+	if err := validateRoom(&item); err != nil {
+		return err
+	}
+	return s.updateByID(&Room{}, item.ID, item)
+}
+
+// DeleteRoom soft-deletes a room. Saved estimates keep their own copy of
+// the dimensions, so they are unaffected; anything that still points at the
+// room must be moved or deleted first.
+func (s *Store) DeleteRoom(id uint) error {
+	n, err := s.countDependents(&Appliance{}, ColRoomID, id)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return fmt.Errorf("room has %d active appliance(s) -- move or delete them first", n)
+	}
+	ni, err := s.countDependents(&InventoryItem{}, ColRoomID, id)
+	if err != nil {
+		return err
+	}
+	if ni > 0 {
+		return fmt.Errorf("room has %d active inventory item(s) -- move or delete them first", ni)
+	}
+	np, err := s.countDependents(&Project{}, ColRoomID, id)
+	if err != nil {
+		return err
+	}
+	if np > 0 {
+		return fmt.Errorf("room has %d active project(s) -- move or delete them first", np)
+	}
+	nf, err := s.countDependents(&RoomFinish{}, ColRoomID, id)
+	if err != nil {
+		return err
+	}
+	if nf > 0 {
+		return fmt.Errorf("room has %d active finish(es) -- delete them first", nf)
+	}
+	nh, err := s.countRoomHotspots(id)
+	if err != nil {
+		return err
+	}
+	if nh > 0 {
+		return fmt.Errorf("room is marked on %d floor plan(s) -- remove the hotspots first", nh)
+	}
+	return s.softDelete(&Room{}, DeletionEntityRoom, id)
+}
+
+func (s *Store) RestoreRoom(id uint) error {
+	return s.restoreEntity(&Room{}, DeletionEntityRoom, id)
+}
+
+// RoomDrilldown gathers a room's appliances, projects, finishes, and saved
+// estimates. A project counts as in the room when it is assigned to the room
+// or has an estimate for it.
+func (s *Store) RoomDrilldown(id uint) (RoomDrilldown, error) {
+	room, err := s.GetRoom(id)
+	if err != nil {
+		return RoomDrilldown{}, err
+	}
+	out := RoomDrilldown{Room: room}
+	if err := s.db.Where(ColRoomID+" = ?", id).
+		Order(ColName + ", " + ColID).
+		Find(&out.Appliances).Error; err != nil {
+		return RoomDrilldown{}, err
+	}
+	estimated := s.db.Model(&MaterialEstimate{}).
+		Select(ColProjectID).
+		Where(ColRoomID+" = ?", id)
+	if err := s.db.Preload("ProjectType").
+		Where(ColRoomID+" = ? OR "+ColID+" IN (?)", id, estimated).
+		Order(ColUpdatedAt + " desc, " + ColID + " desc").
+		Find(&out.Projects).Error; err != nil {
+		return RoomDrilldown{}, err
+	}
+	if out.Finishes, err = s.ListRoomFinishes(id, false); err != nil {
+		return RoomDrilldown{}, err
+	}
+	if err := s.db.Where(ColRoomID+" = ?", id).
+		Order(ColCreatedAt + " desc, " + ColID + " desc").
+		Find(&out.Estimates).Error; err != nil {
+		return RoomDrilldown{}, err
+	}
+	return out, nil
+}
+
+// ListRoomFinishes returns a room's finishes ordered by surface.
+func (s *Store) ListRoomFinishes(roomID uint, includeDeleted bool) ([]RoomFinish, error) {
+	var items []RoomFinish
+	db := s.db.Where(ColRoomID+" = ?", roomID).Order(ColSurface + ", " + ColID)
+	if includeDeleted {
+		db = db.Unscoped()
+	}
+	return items, db.Find(&items).Error
+}
+
+func (s *Store) GetRoomFinish(id uint) (RoomFinish, error) {
+	var item RoomFinish
+	err := s.db.First(&item, id).Error
+	return item, err
+}
+
+func (s *Store) CreateRoomFinish(item *RoomFinish) error {
+	if err := s.validateRoomFinish(item); err != nil {
+		return err
+	}
+	return s.db.Create(item).Error
+}
+
+func (s *Store) UpdateRoomFinish(item RoomFinish) error {
+	if err := s.validateRoomFinish(&item); err != nil {
+		return err
+	}
+	return s.updateByID(&RoomFinish{}, item.ID, item)
+}
+
+func (s *Store) DeleteRoomFinish(id uint) error {
+	return s.softDelete(&RoomFinish{}, DeletionEntityRoomFinish, id)
+}
+
+func (s *Store) RestoreRoomFinish(id uint) error {
+	var item RoomFinish
+	if err := s.db.Unscoped().First(&item, id).Error; err != nil {
+		return err
+	}
+	if err := s.requireParentAlive(&Room{}, item.RoomID); err != nil {
+		return parentRestoreError("room", err)
+	}
+	return s.restoreEntity(&RoomFinish{}, DeletionEntityRoomFinish, id)
+}
+
+func (s *Store) validateRoomFinish(item *RoomFinish) error {
+	if err := s.requireParentAlive(&Room{}, item.RoomID); err != nil {
+		return fmt.Errorf("room not found or deleted")
+	}
+	item.Surface = strings.ToLower(strings.TrimSpace(item.Surface))
+	if item.Surface == "" {
+		item.Surface = FinishSurfaceOther
+	}
+	if !slices.Contains(FinishSurfaces(), item.Surface) {
+		return fmt.Errorf("unknown surface %q", item.Surface)
+	}
+	if strings.TrimSpace(item.Material) == "" && strings.TrimSpace(item.Color) == "" {
+		return fmt.Errorf("finish needs a material or a color")
+	}
+	return nil
+}
+
+func validateRoom(item *Room) error {
+	item.Name = strings.TrimSpace(item.Name)
+	if item.Name == "" {
+		return fmt.Errorf("room name is required")
+	}
+	if item.LengthFt < 0 || item.WidthFt < 0 || item.HeightFt < 0 {
+		return fmt.Errorf("room dimensions must be non-negative")
+	}
+	if item.Doors < 0 || item.Windows < 0 {
+		return fmt.Errorf("door and window counts must be non-negative")
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// registerSchedule keeps MaintenanceItem.NextDueAt in step with
+// LastServicedAt and IntervalMonths however the items are written: through
+// the store's methods, column updates from air filters and smart devices,
+// or history reverts.
+func registerSchedule(db *gorm.DB) error {
+	sync := func(tx *gorm.DB) {
+		if tx.Error != nil || tx.Statement.Schema == nil ||
+			tx.Statement.Schema.Table != tableMaintenanceItems {
+			return
+		}
+		if err := syncNextDue(tx.Session(&gorm.Session{NewDB: true})); err != nil {
+			_ = tx.AddError(err)
+		}
+	}
+	if err := db.Callback().Create().After("gorm:create").Register("webcasa:next_due", sync); err != nil {
+		return err
+	}
+	return db.Callback().Update().After("gorm:update").Register("webcasa:next_due", sync)
+}
+
+// tableMaintenanceItems is MaintenanceItem's table.
+const tableMaintenanceItems = "maintenance_items"
+
+// syncNextDue recomputes every maintenance item's next due date and writes
+// the ones that changed. A house has dozens of items, so redoing them all
+// is cheaper than working out which a write touched. The writes are raw,
+// so they don't bump versions or land in the history.
+func syncNextDue(tx *gorm.DB) error {
+	var items []MaintenanceItem
+	if err := tx.Unscoped().
+		Select(ColID, ColLastServicedAt, ColIntervalMonths, ColNextDueAt).
+		Find(&items).Error; err != nil {
+		return fmt.Errorf("load maintenance schedule: %w", err)
+	}
+	for _, m := range items {
+		next := nextDueAt(m.LastServicedAt, m.IntervalMonths)
+		if sameTime(next, m.NextDueAt) {
+			continue
+		}
+		if err := tx.Exec(
+			"UPDATE "+tableMaintenanceItems+" SET "+ColNextDueAt+" = ? WHERE "+ColID+" = ?", next, m.ID,
+		).Error; err != nil {
+			return fmt.Errorf("update next due date: %w", err)
+		}
+	}
+	return nil
+}
+
+// nextDueAt is ComputeNextDue in UTC, as stored, so the text SQLite
+// compares sorts by time.
+func nextDueAt(last *time.Time, intervalMonths int) *time.Time {
+	next := ComputeNextDue(last, intervalMonths)
+	if next == nil {
+		return nil
+	}
+	utc := next.UTC()
+	return &utc
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// ListMaintenanceDue returns maintenance items due on or before now +
+// within, overdue ones included, soonest first. Items without an interval,
+// or never serviced, have no due date and are left out.
+func (s *Store) ListMaintenanceDue(now time.Time, within time.Duration) ([]MaintenanceItem, error) {
+	return s.maintenanceDueBefore(now.Add(within), true)
+}
+
+// OverdueMaintenance returns maintenance items whose due date has passed,
+// most overdue first.
+func (s *Store) OverdueMaintenance(now time.Time) ([]MaintenanceItem, error) {
+	return s.maintenanceDueBefore(now, false)
+}
+
+func (s *Store) maintenanceDueBefore(cutoff time.Time, inclusive bool) ([]MaintenanceItem, error) {
+	op := " < ?"
+	if inclusive {
+		op = " <= ?"
+	}
+	var items []MaintenanceItem
+	err := s.db.
+		Preload("Category").
+		Preload("Appliance", func(q *gorm.DB) *gorm.DB { return q.Unscoped() }).
+		Where(ColNextDueAt+" IS NOT NULL AND "+ColNextDueAt+op, cutoff.UTC()).
+		Scopes(s.inHouse(tableMaintenanceItems)).
+		Order(ColNextDueAt + ", " + ColID).
+		Find(&items).Error
+	return items, err
+}
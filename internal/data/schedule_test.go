@@ -0,0 +1,80 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceSchedule(t *testing.T) {
+	store := newTestStore(t)
+	categories, err := store.MaintenanceCategories()
+	require.NoError(t, err)
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	at := func(y int, m time.Month, d int) *time.Time {
+		v := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		return &v
+	}
+
+	gutters := MaintenanceItem{
+		Name: "Gutters", CategoryID: categories[0].ID,
+		LastServicedAt: at(2025, 12, 31), IntervalMonths: 6,
+	}
+	furnace := MaintenanceItem{
+		Name: "Furnace", CategoryID: categories[0].ID,
+		LastServicedAt: at(2026, 6, 1), IntervalMonths: 1,
+	}
+	smoke := MaintenanceItem{
+		Name: "Smoke alarms", CategoryID: categories[0].ID,
+		LastServicedAt: at(2026, 1, 1), IntervalMonths: 12,
+	}
+	unscheduled := MaintenanceItem{Name: "Attic", CategoryID: categories[0].ID}
+	for _, m := range []*MaintenanceItem{&gutters, &furnace, &smoke, &unscheduled} {
+		require.NoError(t, store.CreateMaintenance(m))
+	}
+
+	got, err := store.GetMaintenance(gutters.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.NextDueAt)
+	assert.True(t, at(2026, 6, 30).Equal(*got.NextDueAt), "the day is clamped to the month's end")
+
+	overdue, err := store.OverdueMaintenance(now)
+	require.NoError(t, err)
+	assert.Empty(t, overdue)
+	due, err := store.ListMaintenanceDue(now, 30*24*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Equal(t, []string{"Gutters", "Furnace"}, []string{due[0].Name, due[1].Name})
+
+	// Column updates, like the ones smart devices make, move the due date.
+	require.NoError(t, store.db.Model(&MaintenanceItem{}).Where(ColID+" = ?", gutters.ID).
+		Update(ColLastServicedAt, at(2025, 6, 1)).Error)
+	overdue, err = store.OverdueMaintenance(now)
+	require.NoError(t, err)
+	require.Len(t, overdue, 1)
+	assert.Equal(t, gutters.ID, overdue[0].ID)
+
+	// A written due date is replaced by the computed one.
+	got, err = store.GetMaintenance(furnace.ID)
+	require.NoError(t, err)
+	got.IntervalMonths = 3
+	got.NextDueAt = at(2030, 1, 1)
+	require.NoError(t, store.UpdateMaintenance(got))
+	got, err = store.GetMaintenance(furnace.ID)
+	require.NoError(t, err)
+	assert.True(t, at(2026, 9, 1).Equal(*got.NextDueAt))
+	history, err := store.FieldHistory("maintenance_items", furnace.ID)
+	require.NoError(t, err)
+	for _, c := range history {
+		assert.NotEqual(t, ColNextDueAt, c.Field)
+	}
+
+	got, err = store.GetMaintenance(unscheduled.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got.NextDueAt)
+}
@@ -0,0 +1,54 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// SchemaVersion is the database layout this build writes. Bump it when a
+// model change would trip up older builds: a column they'd fill wrong or a
+// table whose rows they'd silently drop.
+const SchemaVersion = 1
+
+const settingSchemaVersion = "schema.version"
+
+// SchemaTooNewError means the database was migrated by a newer webcasa
+// than this one, which could misread or damage it.
+type SchemaTooNewError struct {
+	Found     int
+	Supported int
+}
+
+func (e *SchemaTooNewError) Error() string {
+	return fmt.Sprintf(
+		"database schema version %d is newer than this build supports (%d) -- "+
+			"upgrade webcasa, or open it with --force-read-only to look without changing anything",
+		e.Found, e.Supported,
+	)
+}
+
+// SchemaVersion returns the version recorded in the database: 0 for a new
+// database or one from before versioning.
+func (s *Store) SchemaVersion() (int, error) {
+	if !s.db.Migrator().HasTable(&Setting{}) {
+		return 0, nil
+	}
+	raw, err := s.GetSetting(settingSchemaVersion)
+	if err != nil {
+		return 0, fmt.Errorf("read schema version: %w", err)
+	}
+	if raw == "" {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("schema version %q is not a number", raw)
+	}
+	return v, nil
+}
+
+// ReadOnly reports whether the store was opened with OpenReadOnly.
+func (s *Store) ReadOnly() bool { return s.readOnly }
@@ -0,0 +1,57 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoMigrateRecordsSchemaVersion(t *testing.T) {
+	store := newTestStore(t)
+	v, err := store.SchemaVersion()
+	require.NoError(t, err)
+	assert.Equal(t, SchemaVersion, v)
+}
+
+func TestAutoMigrateRefusesNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "newer.db")
+	store, err := Open(path)
+	require.NoError(t, err)
+	require.NoError(t, store.AutoMigrate())
+	require.NoError(t, store.CreateVendor(&Vendor{Name: "Acme"}))
+	require.NoError(t, store.PutSetting(settingSchemaVersion, strconv.Itoa(SchemaVersion+1)))
+	require.NoError(t, store.Close())
+
+	store, err = Open(path)
+	require.NoError(t, err)
+	err = store.AutoMigrate()
+	var tooNew *SchemaTooNewError
+	require.ErrorAs(t, err, &tooNew)
+	assert.Equal(t, SchemaVersion+1, tooNew.Found)
+	assert.Contains(t, err.Error(), "--force-read-only")
+	require.NoError(t, store.Close())
+
+	ro, err := OpenReadOnly(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ro.Close() })
+	assert.True(t, ro.ReadOnly())
+	vendors, err := ro.ListVendors(false)
+	require.NoError(t, err)
+	require.Len(t, vendors, 1)
+	assert.Error(t, ro.CreateVendor(&Vendor{Name: "Other"}), "writes are refused")
+}
+
+func TestSchemaVersionOfEmptyDatabase(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "empty.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	v, err := store.SchemaVersion()
+	require.NoError(t, err)
+	assert.Zero(t, v)
+}
@@ -0,0 +1,168 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// scopeNames maps the kinds of record a chat question can be scoped to,
+// as in "@project kitchen-remodel", to the column holding their names.
+var scopeNames = map[string]string{
+	DocumentEntityAppliance:   ColName,
+	DocumentEntityIncident:    ColTitle,
+	DocumentEntityMaintenance: ColName,
+	DocumentEntityProject:     ColTitle,
+	DocumentEntityVendor:      ColName,
+}
+
+// ScopeKinds lists the kinds of record a question can be scoped to.
+func ScopeKinds() []string {
+	kinds := make([]string, 0, len(scopeNames))
+	for kind := range scopeNames {
+		kinds = append(kinds, kind)
+	}
+	slices.Sort(kinds)
+	return kinds
+}
+
+// ErrAmbiguousScope is returned when a name could be more than one record.
+var ErrAmbiguousScope = errors.New("more than one record matches")
+
+// ScopedRecord is a record a question is about, with what a model needs
+// to answer it.
+type ScopedRecord struct {
+	Kind  string
+	ID    uint
+	Name  string
+	Table string
+	// Fields are the record's filled-in columns, a "column: value" line
+	// each, with money in dollars.
+	Fields string
+	// Related maps each table with a foreign key to the record to the
+	// key's column, e.g. quotes to project_id.
+	Related map[string]string
+}
+
+// Slug is a name as it's written in a scope: lower case, with a hyphen
+// for each run of anything but letters and digits. "Kitchen Remodel"
+// is kitchen-remodel.
+func Slug(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), "-")
+}
+
+// FindScoped finds the live record of kind that ref names: its ID, or its
+// name as a Slug. A slug that starts just one record's name is enough, so
+// "@project kitchen" finds the kitchen remodel if it's the only kitchen
+// project.
+func (s *Store) FindScoped(kind, ref string) (ScopedRecord, error) {
+	nameCol, ok := scopeNames[kind]
+	if !ok {
+		return ScopedRecord{}, fmt.Errorf("can't scope to a %s", kind)
+	}
+	model := documentParents[kind]
+	table, err := s.tableOf(model)
+	if err != nil {
+		return ScopedRecord{}, err
+	}
+	type named struct {
+		ID   uint
+		Name string
+	}
+	var rows []named
+	err = s.db.Model(model).Select(ColID + ", " + nameCol + " AS name").Order(ColID).Scan(&rows).Error
+	if err != nil {
+		return ScopedRecord{}, fmt.Errorf("list %s: %w", table, err)
+	}
+	var found, prefixed []named
+	id, idErr := strconv.ParseUint(ref, 10, 0)
+	slug := Slug(ref)
+	for _, row := range rows {
+		switch name := Slug(row.Name); {
+		case idErr == nil && row.ID == uint(id), idErr != nil && name == slug:
+			found = append(found, row)
+		case idErr != nil && slug != "" && strings.HasPrefix(name, slug):
+			prefixed = append(prefixed, row)
+		}
+	}
+	if len(found) == 0 {
+		found = prefixed
+	}
+	if len(found) > 1 {
+		names := make([]string, len(found))
+		for i, row := range found {
+			names[i] = row.Name
+		}
+		return ScopedRecord{}, fmt.Errorf("%w: %s could be %s", ErrAmbiguousScope, ref, strings.Join(names, ", "))
+	}
+	if len(found) == 0 {
+		return ScopedRecord{}, fmt.Errorf("no %s is %s: %w", kind, ref, gorm.ErrRecordNotFound)
+	}
+	rec := ScopedRecord{Kind: kind, ID: found[0].ID, Name: found[0].Name, Table: table}
+
+	if rec.Fields, err = s.RecordFields(table, rec.ID, nil); err != nil {
+		return ScopedRecord{}, err
+	}
+	rec.Related = map[string]string{}
+	for _, m := range allModels() {
+		stmt := &gorm.Statement{DB: s.db}
+		if err := stmt.Parse(m); err != nil {
+			return ScopedRecord{}, fmt.Errorf("parse model: %w", err)
+		}
+		for _, rel := range stmt.Schema.Relationships.BelongsTo {
+			if len(rel.References) == 1 && rel.FieldSchema.Table == table && stmt.Schema.Table != table {
+				rec.Related[stmt.Schema.Table] = rel.References[0].ForeignKey.DBName
+			}
+		}
+	}
+	return rec, nil
+}
+
+// RecordFields lists a row's filled-in columns, leaving out bookkeeping
+// ones, the way DataDump does, passing each value through mask first when
+// it isn't nil.
+func (s *Store) RecordFields(table string, id uint, mask Mask) (string, error) {
+	//nolint:gosec // table comes from the model, not user input
+	rows, err := s.db.Raw(fmt.Sprintf("SELECT * FROM %s WHERE id = ?", table), id).Rows()
+	if err != nil {
+		return "", fmt.Errorf("load %s %d: %w", table, id, err)
+	}
+	defer func() { _ = rows.Close() }()
+	cols, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for rows.Next() {
+		values := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return "", err
+		}
+		for i, col := range cols {
+			if values[i] == nil || isNoiseColumn(col) || col == ColVersion {
+				continue
+			}
+			if v := fmt.Sprintf("%v", values[i]); v != "" {
+				if mask != nil {
+					v = mask(col, v)
+				}
+				b.WriteString(formatColumnValue(col, v) + "\n")
+			}
+		}
+	}
+	return b.String(), rows.Err()
+}
@@ -0,0 +1,51 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestSlug(t *testing.T) {
+	assert.Equal(t, "kitchen-remodel", Slug("Kitchen Remodel"))
+	assert.Equal(t, "bob-s-hvac-co", Slug("  Bob's HVAC & Co. "))
+	assert.Equal(t, "", Slug("--"))
+}
+
+func TestFindScoped(t *testing.T) {
+	store := newTestStore(t)
+	for _, name := range []string{"Water Heater", "Water Softener", "Dryer"} {
+		require.NoError(t, store.CreateAppliance(&Appliance{Name: name, Brand: "Acme"}))
+	}
+	heater, err := store.FindScoped(DocumentEntityAppliance, "water-heater")
+	require.NoError(t, err)
+	assert.Equal(t, "Water Heater", heater.Name)
+	assert.Equal(t, "appliances", heater.Table)
+	assert.Contains(t, heater.Fields, "brand: Acme\n")
+	assert.NotContains(t, heater.Fields, "created_at")
+	assert.Equal(t, "appliance_id", heater.Related["maintenance_items"])
+
+	byID, err := store.FindScoped(DocumentEntityAppliance, "3")
+	require.NoError(t, err)
+	assert.Equal(t, "Dryer", byID.Name)
+
+	byPrefix, err := store.FindScoped(DocumentEntityAppliance, "water-s")
+	require.NoError(t, err)
+	assert.Equal(t, "Water Softener", byPrefix.Name)
+
+	_, err = store.FindScoped(DocumentEntityAppliance, "water")
+	require.ErrorIs(t, err, ErrAmbiguousScope)
+	assert.ErrorContains(t, err, "water could be Water Heater, Water Softener")
+
+	_, err = store.FindScoped(DocumentEntityAppliance, "toaster")
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	require.NoError(t, store.DeleteAppliance(byID.ID))
+	_, err = store.FindScoped(DocumentEntityAppliance, "dryer")
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
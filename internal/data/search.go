@@ -0,0 +1,232 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SearchKindDocument and SearchKindContact are a document's and a
+// contact's kinds in search hits. Other hits use the DocumentEntity kinds.
+const (
+	SearchKindDocument = "document"
+	SearchKindContact  = "contact"
+)
+
+// searchIndexTable is the FTS5 table behind Search. Triggers on the
+// indexed tables keep it current.
+const searchIndexTable = "search_index"
+
+// searchIndexVersion is bumped whenever searchSources changes, so that
+// AutoMigrate rebuilds the index.
+const searchIndexVersion = "3"
+
+const settingSearchVersion = "search.version"
+
+// maxSearchHits caps what Search returns.
+const maxSearchHits = 50
+
+// searchSource says how rows of one table are indexed. Expressions refer
+// to the row as "$.". parent, when set, is the record a row is shown
+// under.
+type searchSource struct {
+	kind   string
+	table  string
+	house  string
+	parent string
+	title  string
+	body   string
+}
+
+// searchSources lists the indexed tables. A source's position is part of
+// each of its rows' rowids in the index, so only append to it.
+var searchSources = []searchSource{
+	{
+		kind: DocumentEntityProject, table: "projects", house: "$.house_id",
+		title: "$.title", body: "$.description",
+	},
+	{
+		kind: DocumentEntityQuote, table: "quotes",
+		house:  "(SELECT house_id FROM projects WHERE id = $.project_id)",
+		parent: "$.project_id",
+		title: "concat_ws(' ', (SELECT name FROM vendors WHERE id = $.vendor_id), 'quote for'," +
+			" (SELECT title FROM projects WHERE id = $.project_id))",
+		body: "$.notes",
+	},
+	{
+		kind: DocumentEntityVendor, table: "vendors", house: "NULL",
+		title: "$.name", body: "concat_ws(' ', $.contact_name, $.email, $.phone, $.website, $.notes)",
+	},
+	{
+		kind: DocumentEntityMaintenance, table: tableMaintenanceItems, house: "$.house_id",
+		title: "$.name", body: "$.notes",
+	},
+	{
+		kind: DocumentEntityAppliance, table: "appliances", house: "$.house_id",
+		title: "$.name", body: "concat_ws(' ', $.brand, $.model_number, $.serial_number, $.location, $.notes)",
+	},
+	{
+		kind: DocumentEntityIncident, table: "incidents", house: "$.house_id",
+		title: "$.title", body: "concat_ws(' ', $.description, $.location, $.notes)",
+	},
+	{
+		kind: DocumentEntityServiceLog, table: "service_log_entries",
+		house:  "(SELECT house_id FROM " + tableMaintenanceItems + " WHERE id = $.maintenance_item_id)",
+		parent: "$.maintenance_item_id",
+		title: "concat_ws(' ', (SELECT name FROM " + tableMaintenanceItems +
+			" WHERE id = $.maintenance_item_id), 'service')",
+		body: "$.notes",
+	},
+	{
+		kind: SearchKindDocument, table: "documents", house: "$.house_id",
+		title: "$.title", body: "concat_ws(' ', $.file_name, $.notes)",
+	},
+	{
+		kind: SearchKindContact, table: "contacts", house: "$.house_id",
+		title: "$.name", body: "concat_ws(' ', $.company, $.email, $.phone, $.account_number, $.notes)",
+	},
+}
+
+// searchKinds is how many kinds fit in a rowid: a row's rowid in the index
+// is its ID times searchKinds plus its source's position.
+const searchKinds = 16
+
+// SearchHit is one record matching a search. Kind and ID say which;
+// ParentID is the record it belongs to, the project of a quote or the
+// maintenance item of a service log entry. Snippet is the matching text
+// with the matches wrapped in [ and ].
+type SearchHit struct {
+	Kind     string
+	ID       uint
+	ParentID *uint
+	Title    string
+	Snippet  string
+}
+
+// expr puts row into one of the source's expressions.
+func (src searchSource) expr(e, row string) string {
+	return strings.ReplaceAll(e, "$.", row+".")
+}
+
+// insertSQL inserts row into the index, if it isn't deleted.
+func (src searchSource) insertSQL(pos int, row, from string) string {
+	return fmt.Sprintf(
+		"INSERT INTO %s(rowid, kind, target_id, house_id, parent_id, title, body) "+
+			"SELECT %s.id * %d + %d, '%s', %s.id, %s, %s, %s, %s%s WHERE %s.deleted_at IS NULL",
+		searchIndexTable, row, searchKinds, pos, src.kind, row,
+		src.expr(src.house, row), src.expr(cmp.Or(src.parent, "NULL"), row),
+		src.expr(src.title, row), src.expr(src.body, row),
+		from, row)
+}
+
+// ensureSearchIndex creates the search index and its triggers, filling it
+// from scratch when it is new, when its definition changed, or when a
+// trigger went missing, e.g. because a migration rebuilt a table.
+func ensureSearchIndex(db *gorm.DB) error {
+	var version string
+	err := db.Model(&Setting{}).Select("value").Where("key = ?", settingSearchVersion).Scan(&version).Error
+	if err != nil {
+		return err
+	}
+	var triggers int64
+	err = db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name LIKE ?",
+		searchIndexTable+"_%").Scan(&triggers).Error
+	if err != nil {
+		return err
+	}
+	if version == searchIndexVersion && triggers == int64(3*len(searchSources)) {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		var names []string
+		err := tx.Raw("SELECT name FROM sqlite_master WHERE type = 'trigger' AND name LIKE ?",
+			searchIndexTable+"_%").Scan(&names).Error
+		if err != nil {
+			return err
+		}
+		stmts := make([]string, 0, len(names)+2+4*len(searchSources))
+		for _, name := range names {
+			stmts = append(stmts, "DROP TRIGGER "+strconv.Quote(name))
+		}
+		stmts = append(stmts,
+			"DROP TABLE IF EXISTS "+searchIndexTable,
+			"CREATE VIRTUAL TABLE "+searchIndexTable+" USING fts5("+
+				"kind UNINDEXED, target_id UNINDEXED, house_id UNINDEXED, parent_id UNINDEXED, title, body, "+
+				"tokenize = 'porter unicode61 remove_diacritics 2')")
+		for pos, src := range searchSources {
+			name := searchIndexTable + "_" + src.table
+			del := fmt.Sprintf("DELETE FROM %s WHERE rowid = OLD.id * %d + %d;", searchIndexTable, searchKinds, pos)
+			stmts = append(stmts,
+				fmt.Sprintf("CREATE TRIGGER %s_ins AFTER INSERT ON %s BEGIN %s; END",
+					name, src.table, src.insertSQL(pos, "NEW", "")),
+				fmt.Sprintf("CREATE TRIGGER %s_upd AFTER UPDATE ON %s BEGIN %s %s; END",
+					name, src.table, del, src.insertSQL(pos, "NEW", "")),
+				fmt.Sprintf("CREATE TRIGGER %s_del AFTER DELETE ON %s BEGIN %s END",
+					name, src.table, del),
+				src.insertSQL(pos, "src", " FROM "+src.table+" AS src"),
+			)
+		}
+		for _, stmt := range stmts {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return fmt.Errorf("build search index: %w", err)
+			}
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "key"}},
+			DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+		}).Create(&Setting{Key: settingSearchVersion, Value: searchIndexVersion, UpdatedAt: time.Now()}).Error
+	})
+}
+
+// searchQuery turns what someone typed into an FTS5 query: every word
+// must match, each as a prefix, so "furn filt" finds "furnace filter".
+// Query syntax is not passed through. It returns "" when nothing
+// searchable is left.
+func searchQuery(q string) string {
+	words := strings.FieldsFunc(q, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		words[i] = `"` + w + `"*`
+	}
+	return strings.Join(words, " ")
+}
+
+// Search finds live records of the current house, and records that
+// belong to no house such as vendors, whose titles, notes, descriptions,
+// vendor names or document details match q. Every word must match,
+// as a prefix. Title matches rank first.
+func (s *Store) Search(q string) ([]SearchHit, error) {
+	match := searchQuery(q)
+	if match == "" {
+		return nil, nil
+	}
+	house, err := s.HouseProfile()
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	var houseID *uint
+	if err == nil {
+		houseID = &house.ID
+	}
+	var hits []SearchHit
+	err = s.db.Raw(
+		"SELECT kind, target_id AS id, parent_id, title, "+
+			"snippet("+searchIndexTable+", -1, '[', ']', '…', 12) AS snippet "+
+			"FROM "+searchIndexTable+" WHERE "+searchIndexTable+" MATCH ? "+
+			"AND (house_id IS NULL OR house_id = ?) "+
+			"ORDER BY bm25("+searchIndexTable+", 0, 0, 0, 0, 10, 1) LIMIT ?",
+		match, houseID, maxSearchHits,
+	).Scan(&hits).Error
+	return hits, err
+}
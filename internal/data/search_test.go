@@ -0,0 +1,100 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearch(t *testing.T) {
+	store := newTestStore(t)
+	require.NoError(t, store.CreateHouseProfile(HouseProfile{Nickname: "Elm Street"}))
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	project := Project{
+		Title: "Basement waterproofing", ProjectTypeID: types[0].ID, Status: ProjectStatusPlanned,
+		Description: "Seal the north wall and add a sump pump",
+	}
+	require.NoError(t, store.CreateProject(&project))
+	quote := Quote{ProjectID: project.ID, TotalCents: 900_000, Notes: "Includes a battery backup pump"}
+	require.NoError(t, store.CreateQuote(&quote, Vendor{Name: "Dry Basements Co", Notes: "Licensed in the county"}))
+	furnace := Appliance{Name: "Furnace", Brand: "Carrier", Notes: "Takes a 16x25 filter"}
+	require.NoError(t, store.CreateAppliance(&furnace))
+	cats, err := store.MaintenanceCategories()
+	require.NoError(t, err)
+	require.NoError(t, store.CreateMaintenance(&MaintenanceItem{Name: "Change filter", CategoryID: cats[0].ID}))
+
+	hits, err := store.Search("pump")
+	require.NoError(t, err)
+	kinds := map[string]uint{}
+	for _, h := range hits {
+		kinds[h.Kind] = h.ID
+	}
+	assert.Equal(t, map[string]uint{DocumentEntityProject: project.ID, DocumentEntityQuote: quote.ID}, kinds)
+	for _, h := range hits {
+		if h.Kind == DocumentEntityQuote {
+			require.NotNil(t, h.ParentID)
+			assert.Equal(t, project.ID, *h.ParentID, "a quote is shown under its project")
+		} else {
+			assert.Nil(t, h.ParentID)
+		}
+	}
+
+	// Words match as prefixes, titles rank first, and query syntax is
+	// taken literally.
+	hits, err = store.Search("filt")
+	require.NoError(t, err)
+	require.Len(t, hits, 2)
+	assert.Equal(t, "Change filter", hits[0].Title)
+	hits, err = store.Search(`carr* OR "16x25`)
+	require.NoError(t, err)
+	assert.Empty(t, hits, "every word must match")
+	hits, err = store.Search("carrier 16x25")
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, DocumentEntityAppliance, hits[0].Kind)
+	assert.Contains(t, hits[0].Snippet, "[Carrier]")
+	hits, err = store.Search("  ")
+	require.NoError(t, err)
+	assert.Empty(t, hits)
+
+	// Edits are picked up, and the trash is left out until restored.
+	furnace.Name = "Gas furnace"
+	require.NoError(t, store.UpdateAppliance(furnace))
+	hits, err = store.Search("gas")
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "Gas furnace", hits[0].Title)
+	require.NoError(t, store.DeleteQuote(quote.ID))
+	require.NoError(t, store.DeleteProject(project.ID))
+	hits, err = store.Search("waterproofing")
+	require.NoError(t, err)
+	assert.Empty(t, hits)
+	require.NoError(t, store.RestoreProject(project.ID))
+	hits, err = store.Search("waterproofing")
+	require.NoError(t, err)
+	assert.Len(t, hits, 1)
+
+	// Another house's records aren't found; vendors belong to every house.
+	require.NoError(t, store.AddHouse(&HouseProfile{Nickname: "Lake cabin"}))
+	hits, err = store.Search("basements")
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, DocumentEntityVendor, hits[0].Kind)
+
+	// Migrating again leaves the index alone, and a lost trigger rebuilds
+	// it.
+	require.NoError(t, store.AutoMigrate())
+	require.NoError(t, store.db.Exec("DROP TRIGGER search_index_vendors_ins").Error)
+	require.NoError(t, store.AutoMigrate())
+	hits, err = store.Search("licensed")
+	require.NoError(t, err)
+	assert.Len(t, hits, 1)
+	names, err := store.TableNames()
+	require.NoError(t, err)
+	assert.NotContains(t, names, searchIndexTable)
+}
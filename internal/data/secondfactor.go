@@ -0,0 +1,172 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/totp"
+	"gorm.io/gorm"
+)
+
+// RecoveryCodeCount is how many recovery codes an enrollment hands out.
+const RecoveryCodeCount = 10
+
+// ErrBadSecondFactor is returned for a wrong, expired, or reused code.
+var ErrBadSecondFactor = errors.New("invalid two-factor code")
+
+// SecondFactor is an account's TOTP enrollment. It is pending until the
+// first code is confirmed. Recovery codes are stored hashed.
+type SecondFactor struct {
+	ID          uint   `gorm:"primaryKey"`
+	Account     string `gorm:"uniqueIndex"`
+	Secret      string `json:"-"`
+	ConfirmedAt *time.Time
+	// LastStep is the time step of the last accepted code, so it can't be
+	// used twice.
+	LastStep       int64  `json:"-"`
+	RecoveryHashes string `json:"-"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Confirmed reports whether enrollment has finished.
+func (f SecondFactor) Confirmed() bool { return f.ConfirmedAt != nil }
+
+// RecoveryCodesLeft counts the unused recovery codes.
+func (f SecondFactor) RecoveryCodesLeft() int {
+	return len(f.recoveryHashes())
+}
+
+func (f SecondFactor) recoveryHashes() []string {
+	if f.RecoveryHashes == "" {
+		return nil
+	}
+	return strings.Split(f.RecoveryHashes, ",")
+}
+
+// SecondFactor returns the account's enrollment, or nil if it has none.
+func (s *Store) SecondFactor(account string) (*SecondFactor, error) {
+	var f SecondFactor
+	err := s.db.Where(ColAccount+" = ?", account).First(&f).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// StartSecondFactor begins TOTP enrollment with a fresh secret, replacing
+// any pending one. An account already enrolled must disable two-factor
+// first.
+func (s *Store) StartSecondFactor(account string) (SecondFactor, error) {
+	existing, err := s.SecondFactor(account)
+	if err != nil {
+		return SecondFactor{}, err
+	}
+	if existing != nil && existing.Confirmed() {
+		return SecondFactor{}, fmt.Errorf("two-factor authentication is already on for %s", account)
+	}
+	secret, err := totp.NewSecret()
+	if err != nil {
+		return SecondFactor{}, err
+	}
+	f := SecondFactor{Account: account, Secret: secret}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(ColAccount+" = ?", account).Delete(&SecondFactor{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&f).Error
+	})
+	return f, err
+}
+
+// ConfirmSecondFactor finishes enrollment once the user proves their app
+// works, and returns the recovery codes. They are not stored in the clear
+// and can't be shown again.
+func (s *Store) ConfirmSecondFactor(account, code string, now time.Time) ([]string, error) {
+	f, err := s.SecondFactor(account)
+	if err != nil {
+		return nil, err
+	}
+	if f == nil {
+		return nil, fmt.Errorf("no two-factor enrollment started for %s", account)
+	}
+	if f.Confirmed() {
+		return nil, fmt.Errorf("two-factor authentication is already on for %s", account)
+	}
+	step, ok := totp.Verify(f.Secret, code, now, f.LastStep)
+	if !ok {
+		return nil, ErrBadSecondFactor
+	}
+	codes, err := totp.RecoveryCodes(RecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, c := range codes {
+		hashes[i] = totp.HashRecoveryCode(c)
+	}
+	err = s.db.Model(f).Updates(map[string]any{
+		ColConfirmedAt:    now,
+		ColLastStep:       step,
+		ColRecoveryHashes: strings.Join(hashes, ","),
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// CheckSecondFactor accepts a current TOTP code or an unused recovery code
+// for the account, using it up. It returns ErrBadSecondFactor otherwise.
+func (s *Store) CheckSecondFactor(account, code string, now time.Time) error {
+	f, err := s.SecondFactor(account)
+	if err != nil {
+		return err
+	}
+	if f == nil || !f.Confirmed() {
+		return fmt.Errorf("two-factor authentication is off for %s", account)
+	}
+	if step, ok := totp.Verify(f.Secret, code, now, f.LastStep); ok {
+		// Conditional on the step so two requests can't both use one code.
+		res := s.db.Model(&SecondFactor{}).
+			Where(ColID+" = ? AND "+ColLastStep+" < ?", f.ID, step).
+			Update(ColLastStep, step)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrBadSecondFactor
+		}
+		return nil
+	}
+	hashes := f.recoveryHashes()
+	i := slices.Index(hashes, totp.HashRecoveryCode(code))
+	if i < 0 {
+		return ErrBadSecondFactor
+	}
+	left := slices.Delete(hashes, i, i+1)
+	res := s.db.Model(&SecondFactor{}).
+		Where(ColID+" = ? AND "+ColRecoveryHashes+" = ?", f.ID, f.RecoveryHashes).
+		Update(ColRecoveryHashes, strings.Join(left, ","))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrBadSecondFactor
+	}
+	return nil
+}
+
+// DisableSecondFactor removes the account's enrollment.
+func (s *Store) DisableSecondFactor(account string) error {
+	return s.db.Where(ColAccount+" = ?", account).Delete(&SecondFactor{}).Error
+}
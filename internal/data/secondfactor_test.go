@@ -0,0 +1,75 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecondFactorEnrollment(t *testing.T) {
+	store := newTestStore(t)
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	f, err := store.SecondFactor("admin")
+	require.NoError(t, err)
+	assert.Nil(t, f)
+
+	pending, err := store.StartSecondFactor("admin")
+	require.NoError(t, err)
+	assert.False(t, pending.Confirmed())
+	// Starting over replaces the pending secret.
+	pending, err = store.StartSecondFactor("admin")
+	require.NoError(t, err)
+
+	_, err = store.ConfirmSecondFactor("admin", "000000", now)
+	assert.ErrorIs(t, err, ErrBadSecondFactor)
+	code, err := totp.Code(pending.Secret, totp.Step(now))
+	require.NoError(t, err)
+	recovery, err := store.ConfirmSecondFactor("admin", code, now)
+	require.NoError(t, err)
+	assert.Len(t, recovery, RecoveryCodeCount)
+
+	f, err = store.SecondFactor("admin")
+	require.NoError(t, err)
+	require.NotNil(t, f)
+	assert.True(t, f.Confirmed())
+	assert.NotContains(t, f.RecoveryHashes, recovery[0])
+
+	_, err = store.StartSecondFactor("admin")
+	assert.ErrorContains(t, err, "already on")
+}
+
+func TestCheckSecondFactor(t *testing.T) {
+	store := newTestStore(t)
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	pending, err := store.StartSecondFactor("admin")
+	require.NoError(t, err)
+	code, err := totp.Code(pending.Secret, totp.Step(now))
+	require.NoError(t, err)
+	recovery, err := store.ConfirmSecondFactor("admin", code, now)
+	require.NoError(t, err)
+
+	// The code that confirmed enrollment is spent.
+	assert.ErrorIs(t, store.CheckSecondFactor("admin", code, now), ErrBadSecondFactor)
+
+	later := now.Add(time.Minute)
+	next, err := totp.Code(pending.Secret, totp.Step(later))
+	require.NoError(t, err)
+	require.NoError(t, store.CheckSecondFactor("admin", next, later))
+	assert.ErrorIs(t, store.CheckSecondFactor("admin", next, later), ErrBadSecondFactor)
+
+	require.NoError(t, store.CheckSecondFactor("admin", recovery[3], later))
+	assert.ErrorIs(t, store.CheckSecondFactor("admin", recovery[3], later), ErrBadSecondFactor)
+	f, err := store.SecondFactor("admin")
+	require.NoError(t, err)
+	assert.Equal(t, RecoveryCodeCount-1, f.RecoveryCodesLeft())
+
+	require.NoError(t, store.DisableSecondFactor("admin"))
+	assert.ErrorContains(t, store.CheckSecondFactor("admin", recovery[4], later), "off")
+}
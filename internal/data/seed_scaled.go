@@ -518,6 +518,7 @@ func seedQuotesForProjects(
 				TotalCents:     fq.TotalCents,
 				LaborCents:     fq.LaborCents,
 				MaterialsCents: fq.MaterialsCents,
+				RequestedDate:  fq.RequestedDate,
 				ReceivedDate:   fq.ReceivedDate,
 				Notes:          fq.Notes,
 			})
@@ -0,0 +1,318 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SitterLinkPrefix starts every house-sitter link secret, telling it apart
+// from admin sessions, account sessions and API tokens.
+const SitterLinkPrefix = "wsl_"
+
+// SitterStay is a house-sitter's stay. Its share link opens a restricted
+// page of the house -- emergency info, appliance guides, what's scheduled
+// during the stay -- where the sitter can leave notes and photos. The link
+// works from when it's made until EndsAt, unless revoked. Only a hash of
+// its secret is stored.
+type SitterStay struct {
+	ID      uint  `gorm:"primaryKey"`
+	HouseID *uint `gorm:"index"`
+	Sitter  string
+	// StartsAt and EndsAt bound the stay.
+	StartsAt time.Time
+	EndsAt   time.Time `gorm:"index"`
+	// Instructions are the owner's notes for the sitter: keys, pets,
+	// plants, quirks.
+	Instructions string
+	// Contact is how to reach the owner during the stay.
+	Contact string
+	// Hint is the start of the secret, shown to tell links apart.
+	Hint      string
+	TokenHash string       `gorm:"uniqueIndex" json:"-"`
+	RevokedAt *time.Time   `gorm:"index"`
+	Notes     []SitterNote `gorm:"constraint:OnDelete:CASCADE;"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// SitterNote is something a sitter left: a note, a photo, or both. The
+// photo is a document attached to the stay.
+type SitterNote struct {
+	ID           uint `gorm:"primaryKey"`
+	SitterStayID uint `gorm:"index"`
+	Body         string
+	DocumentID   *uint    `gorm:"index"`
+	Document     Document `gorm:"constraint:OnDelete:SET NULL;"`
+	CreatedAt    time.Time
+}
+
+// SitterGuide is what a sitter's page shows: the house and the owner's
+// insurance for emergencies, vendors to call, the appliances, and what's
+// scheduled during the stay.
+type SitterGuide struct {
+	House        HouseProfile
+	Contacts     []Contact
+	Vendors      []Vendor
+	Appliances   []Appliance
+	Appointments []SitterAppointment
+}
+
+// SitterAppointment is something scheduled during a stay.
+type SitterAppointment struct {
+	At     time.Time
+	Title  string
+	Detail string
+}
+
+// Expired reports whether the stay's link no longer works at now.
+func (s SitterStay) Expired(now time.Time) bool {
+	return s.RevokedAt != nil || !now.Before(s.EndsAt)
+}
+
+// During reports whether t falls within the stay.
+func (s SitterStay) During(t time.Time) bool {
+	return !t.Before(s.StartsAt) && t.Before(s.EndsAt)
+}
+
+// sitterNotesPreload loads notes oldest first with their photos' metadata,
+// not the bytes.
+func sitterNotesPreload(q *gorm.DB) *gorm.DB {
+	return q.Preload("Document", func(q *gorm.DB) *gorm.DB {
+		return q.Unscoped().Select(listDocumentColumns)
+	}).Order("sitter_notes." + ColID)
+}
+
+// ListSitterStays returns the current house's stays, latest first, with
+// what the sitters left.
+func (s *Store) ListSitterStays() ([]SitterStay, error) {
+	var stays []SitterStay
+	err := s.db.Preload("Notes", sitterNotesPreload).
+		Scopes(s.inHouse("sitter_stays")).
+		Order("starts_at desc, " + ColID + " desc").
+		Find(&stays).Error
+	return stays, err
+}
+
+func (s *Store) GetSitterStay(id uint) (SitterStay, error) {
+	var stay SitterStay
+	err := s.db.Preload("Notes", sitterNotesPreload).First(&stay, id).Error
+	return stay, err
+}
+
+// CreateSitterStay saves a stay and returns its link's secret, which is
+// not stored and can't be shown again.
+func (s *Store) CreateSitterStay(stay *SitterStay, now time.Time) (string, error) {
+	stay.Sitter = strings.TrimSpace(stay.Sitter)
+	switch {
+	case stay.Sitter == "":
+		return "", fmt.Errorf("a stay needs the sitter's name")
+	case stay.StartsAt.IsZero() || stay.EndsAt.IsZero():
+		return "", fmt.Errorf("a stay needs a start and an end")
+	case !stay.EndsAt.After(stay.StartsAt):
+		return "", fmt.Errorf("a stay must end after it starts")
+	case !stay.EndsAt.After(now):
+		return "", fmt.Errorf("that stay is already over")
+	}
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("generate link: %w", err)
+	}
+	secret := SitterLinkPrefix + hex.EncodeToString(raw)
+	stay.Hint = secret[:len(SitterLinkPrefix)+6]
+	stay.TokenHash = hashToken(secret)
+	stay.RevokedAt = nil
+	stay.Notes = nil
+	return secret, s.db.Create(stay).Error
+}
+
+// RevokeSitterStay stops a stay's link from working. Revoking twice is
+// harmless.
+func (s *Store) RevokeSitterStay(id uint, now time.Time) error {
+	result := s.db.Model(&SitterStay{}).
+		Where(ColID+" = ? AND "+ColRevokedAt+" IS NULL", id).
+		Update(ColRevokedAt, now)
+	if result.Error != nil || result.RowsAffected > 0 {
+		return result.Error
+	}
+	return s.db.Select(ColID).First(&SitterStay{}, id).Error
+}
+
+// DeleteSitterStay removes a stay and its notes. Its photos are kept
+// among the documents, attached to nothing.
+func (s *Store) DeleteSitterStay(id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("sitter_stay_id = ?", id).Delete(&SitterNote{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&Document{}).
+			Where(ColEntityKind+" = ? AND "+ColEntityID+" = ?", DocumentEntitySitterStay, id).
+			Updates(map[string]any{ColEntityKind: DocumentEntityNone, ColEntityID: 0}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&SitterStay{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// SitterStayBySecret finds the stay whose link has the given secret. It
+// returns gorm.ErrRecordNotFound for unknown, revoked and expired links.
+func (s *Store) SitterStayBySecret(secret string, now time.Time) (SitterStay, error) {
+	var stay SitterStay
+	err := s.db.Preload("Notes", sitterNotesPreload).
+		Where(ColTokenHash+" = ?", hashToken(secret)).
+		First(&stay).Error
+	if err != nil {
+		return SitterStay{}, err
+	}
+	if stay.Expired(now) {
+		return SitterStay{}, gorm.ErrRecordNotFound
+	}
+	return stay, nil
+}
+
+// AddSitterNote saves what a sitter left. photo, when given, is stored as
+// a document attached to the stay.
+func (s *Store) AddSitterNote(stayID uint, body string, photo *Document) (SitterNote, error) {
+	note := SitterNote{SitterStayID: stayID, Body: strings.TrimSpace(body)}
+	if note.Body == "" && photo == nil {
+		return SitterNote{}, fmt.Errorf("leave a note or a photo")
+	}
+	var stay SitterStay
+	if err := s.db.First(&stay, stayID).Error; err != nil {
+		return SitterNote{}, err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if photo != nil {
+			photo.EntityKind = DocumentEntitySitterStay
+			photo.EntityID = stayID
+			photo.HouseID = stay.HouseID
+			if photo.SizeBytes > s.maxDocumentSize {
+				return fmt.Errorf("the photo is too large -- the most allowed is %s", formatBytes(s.maxDocumentSize))
+			}
+			if err := tx.Create(photo).Error; err != nil {
+				return err
+			}
+			note.DocumentID = &photo.ID
+		}
+		return tx.Create(&note).Error
+	})
+	if err != nil {
+		return SitterNote{}, err
+	}
+	return note, nil
+}
+
+// SitterPhoto returns a photo left during the stay, with its bytes.
+func (s *Store) SitterPhoto(stayID, documentID uint) (Document, error) {
+	var note SitterNote
+	err := s.db.Where("sitter_stay_id = ? AND document_id = ?", stayID, documentID).First(&note).Error
+	if err != nil {
+		return Document{}, err
+	}
+	return s.GetDocument(documentID)
+}
+
+// inStayHouse limits a query on table to the rows of the stay's house,
+// whichever house is current.
+func inStayHouse(table string, stay SitterStay) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if stay.HouseID == nil {
+			return db.Where(table + "." + ColHouseID + " IS NULL")
+		}
+		return db.Where(table+"."+ColHouseID+" = ?", *stay.HouseID)
+	}
+}
+
+// SitterGuide gathers what the stay's sitter gets to see, from the stay's
+// house rather than the current one, including its emergency contacts
+// that have a phone number. Vendors aren't filed under a house, so every
+// vendor with a phone number is listed.
+func (s *Store) SitterGuide(stay SitterStay) (SitterGuide, error) {
+	var guide SitterGuide
+	var err error
+	if stay.HouseID != nil {
+		err = s.db.First(&guide.House, *stay.HouseID).Error
+	} else {
+		guide.House, err = s.HouseProfile()
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return SitterGuide{}, fmt.Errorf("load house: %w", err)
+	}
+	if err := s.db.Scopes(inStayHouse("contacts", stay)).Where("emergency AND phone <> ''").
+		Order(ColName).Find(&guide.Contacts).Error; err != nil {
+		return SitterGuide{}, fmt.Errorf("list contacts: %w", err)
+	}
+	if err := s.db.Where("phone <> ''").Order(ColName).Find(&guide.Vendors).Error; err != nil {
+		return SitterGuide{}, fmt.Errorf("list vendors: %w", err)
+	}
+	if err := s.db.Preload("Room").Scopes(inStayHouse("appliances", stay)).
+		Order(ColName).Find(&guide.Appliances).Error; err != nil {
+		return SitterGuide{}, fmt.Errorf("list appliances: %w", err)
+	}
+
+	var maintenance []MaintenanceItem
+	if err := s.db.Preload("Appliance", func(q *gorm.DB) *gorm.DB { return q.Unscoped() }).
+		Scopes(inStayHouse(tableMaintenanceItems, stay)).
+		Where(ColNextDueAt + " IS NOT NULL").Find(&maintenance).Error; err != nil {
+		return SitterGuide{}, fmt.Errorf("list maintenance: %w", err)
+	}
+	for _, m := range maintenance {
+		if !stay.During(*m.NextDueAt) {
+			continue
+		}
+		detail := "maintenance due"
+		if m.Appliance.Name != "" {
+			detail += " · " + m.Appliance.Name
+		}
+		guide.Appointments = append(guide.Appointments, SitterAppointment{At: *m.NextDueAt, Title: m.Name, Detail: detail})
+	}
+
+	var projects []Project
+	if err := s.db.Scopes(inStayHouse("projects", stay)).
+		Where(ColStatus+" NOT IN ?", []string{ProjectStatusCompleted, ProjectStatusAbandoned}).
+		Find(&projects).Error; err != nil {
+		return SitterGuide{}, fmt.Errorf("list projects: %w", err)
+	}
+	for _, p := range projects {
+		if p.StartDate != nil && stay.During(*p.StartDate) {
+			guide.Appointments = append(guide.Appointments, SitterAppointment{At: *p.StartDate, Title: p.Title, Detail: "project starts"})
+		}
+		if p.EndDate != nil && stay.During(*p.EndDate) {
+			guide.Appointments = append(guide.Appointments, SitterAppointment{At: *p.EndDate, Title: p.Title, Detail: "project wraps up"})
+		}
+	}
+
+	pests, err := s.pestRetreatmentsDue(inStayHouse("pest_treatments", stay), stay.EndsAt, 0)
+	if err != nil {
+		return SitterGuide{}, fmt.Errorf("list pest treatments: %w", err)
+	}
+	for _, p := range pests {
+		next := p.NextTreatmentDue()
+		if !stay.During(*next) {
+			continue
+		}
+		detail := "pest treatment"
+		if p.Vendor.Name != "" {
+			detail += " · " + p.Vendor.Name
+		}
+		guide.Appointments = append(guide.Appointments, SitterAppointment{At: *next, Title: p.TargetPest, Detail: detail})
+	}
+	slices.SortStableFunc(guide.Appointments, func(a, b SitterAppointment) int { return a.At.Compare(b.At) })
+	return guide, nil
+}
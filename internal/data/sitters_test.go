@@ -0,0 +1,144 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestSitterStays(t *testing.T) {
+	store := newTestStore(t)
+	require.NoError(t, store.CreateHouseProfile(HouseProfile{Nickname: "Elm Street"}))
+	now := time.Date(2026, 7, 1, 9, 0, 0, 0, time.UTC)
+	starts, ends := now.AddDate(0, 0, 2), now.AddDate(0, 0, 9)
+
+	_, err := store.CreateSitterStay(&SitterStay{StartsAt: starts, EndsAt: ends}, now)
+	require.ErrorContains(t, err, "name")
+	_, err = store.CreateSitterStay(&SitterStay{Sitter: "Sam", StartsAt: ends, EndsAt: starts}, now)
+	require.ErrorContains(t, err, "end after")
+	_, err = store.CreateSitterStay(&SitterStay{
+		Sitter: "Sam", StartsAt: now.AddDate(0, 0, -3), EndsAt: now.AddDate(0, 0, -1),
+	}, now)
+	require.ErrorContains(t, err, "over")
+
+	stay := SitterStay{Sitter: " Sam ", StartsAt: starts, EndsAt: ends, Instructions: "Feed the cat"}
+	secret, err := store.CreateSitterStay(&stay, now)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, SitterLinkPrefix))
+	assert.True(t, strings.HasPrefix(secret, stay.Hint))
+	assert.Equal(t, "Sam", stay.Sitter)
+	require.NotNil(t, stay.HouseID, "filed under the current house")
+
+	// The link works before the stay starts, and not after it ends.
+	got, err := store.SitterStayBySecret(secret, now)
+	require.NoError(t, err)
+	assert.Equal(t, stay.ID, got.ID)
+	_, err = store.SitterStayBySecret(secret, ends)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = store.SitterStayBySecret(SitterLinkPrefix+"nope", now)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// Notes and photos.
+	_, err = store.AddSitterNote(stay.ID, "  ", nil)
+	require.ErrorContains(t, err, "note or a photo")
+	_, err = store.AddSitterNote(stay.ID, "Mail's on the counter", nil)
+	require.NoError(t, err)
+	photo := Document{Title: "Leak", FileName: "leak.jpg", MIMEType: "image/jpeg", Data: []byte("jpeg"), SizeBytes: 4}
+	note, err := store.AddSitterNote(stay.ID, "", &photo)
+	require.NoError(t, err)
+	require.NotNil(t, note.DocumentID)
+	doc, err := store.SitterPhoto(stay.ID, *note.DocumentID)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("jpeg"), doc.Data)
+	assert.Equal(t, DocumentEntitySitterStay, doc.EntityKind)
+	assert.Equal(t, stay.HouseID, doc.HouseID)
+
+	stays, err := store.ListSitterStays()
+	require.NoError(t, err)
+	require.Len(t, stays, 1)
+	require.Len(t, stays[0].Notes, 2)
+	assert.Equal(t, "Mail's on the counter", stays[0].Notes[0].Body)
+	assert.Equal(t, "Leak", stays[0].Notes[1].Document.Title)
+	assert.Empty(t, stays[0].Notes[1].Document.Data, "photo bytes aren't loaded")
+
+	// Another stay's photos aren't reachable through this one.
+	other := SitterStay{Sitter: "Alex", StartsAt: starts, EndsAt: ends}
+	_, err = store.CreateSitterStay(&other, now)
+	require.NoError(t, err)
+	_, err = store.SitterPhoto(other.ID, *note.DocumentID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// Revoking stops the link; deleting keeps the photo.
+	require.NoError(t, store.RevokeSitterStay(stay.ID, now))
+	require.NoError(t, store.RevokeSitterStay(stay.ID, now))
+	_, err = store.SitterStayBySecret(secret, now)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	require.ErrorIs(t, store.RevokeSitterStay(999, now), gorm.ErrRecordNotFound)
+	require.NoError(t, store.DeleteSitterStay(stay.ID))
+	require.ErrorIs(t, store.DeleteSitterStay(stay.ID), gorm.ErrRecordNotFound)
+	doc, err = store.GetDocument(*note.DocumentID)
+	require.NoError(t, err)
+	assert.Equal(t, DocumentEntityNone, doc.EntityKind)
+
+	// Another house has its own stays.
+	require.NoError(t, store.AddHouse(&HouseProfile{Nickname: "Lake cabin"}))
+	stays, err = store.ListSitterStays()
+	require.NoError(t, err)
+	assert.Empty(t, stays)
+}
+
+func TestSitterGuide(t *testing.T) {
+	store := newTestStore(t)
+	require.NoError(t, store.CreateHouseProfile(HouseProfile{Nickname: "Elm Street", InsuranceCarrier: "Acme"}))
+	now := time.Date(2026, 7, 1, 9, 0, 0, 0, time.UTC)
+	stay := SitterStay{Sitter: "Sam", StartsAt: now.AddDate(0, 0, 2), EndsAt: now.AddDate(0, 0, 9)}
+	_, err := store.CreateSitterStay(&stay, now)
+	require.NoError(t, err)
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	require.NoError(t, store.CreateVendor(&Vendor{Name: "Plumb Co", Phone: "555-0100"}))
+	require.NoError(t, store.CreateVendor(&Vendor{Name: "No Phone"}))
+	furnace := Appliance{Name: "Furnace", Location: "Basement"}
+	require.NoError(t, store.CreateAppliance(&furnace))
+	cats, err := store.MaintenanceCategories()
+	require.NoError(t, err)
+	require.NoError(t, store.CreateMaintenance(&MaintenanceItem{
+		Name: "Filter swap", CategoryID: cats[0].ID, ApplianceID: &furnace.ID,
+		IntervalMonths: 1, LastServicedAt: ptr(now.AddDate(0, -1, 4)),
+	}))
+	require.NoError(t, store.CreateMaintenance(&MaintenanceItem{
+		Name: "Gutters", CategoryID: cats[0].ID, IntervalMonths: 6, LastServicedAt: ptr(now),
+	}))
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	require.NoError(t, store.CreateProject(&Project{
+		Title: "Deck", ProjectTypeID: types[0].ID, Status: ProjectStatusPlanned, StartDate: ptr(now.AddDate(0, 0, 3)),
+	}))
+	require.NoError(t, store.CreatePestTreatment(&PestTreatment{
+		TargetPest: "Ants", TreatedAt: now.AddDate(0, -3, 5), RetreatIntervalMonths: 3,
+	}))
+
+	// The guide is the stay's house's, even after switching to another.
+	require.NoError(t, store.AddHouse(&HouseProfile{Nickname: "Lake cabin"}))
+	guide, err := store.SitterGuide(stay)
+	require.NoError(t, err)
+	assert.Equal(t, "Elm Street", guide.House.Nickname)
+	require.Len(t, guide.Vendors, 1)
+	assert.Equal(t, "Plumb Co", guide.Vendors[0].Name)
+	require.Len(t, guide.Appliances, 1)
+	assert.Equal(t, "Furnace", guide.Appliances[0].Name)
+
+	var titles []string
+	for _, a := range guide.Appointments {
+		titles = append(titles, a.Title)
+	}
+	assert.Equal(t, []string{"Deck", "Filter swap", "Ants"}, titles)
+	assert.Equal(t, "maintenance due · Furnace", guide.Appointments[1].Detail)
+}
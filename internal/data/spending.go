@@ -0,0 +1,169 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Spending categories, one per source of recorded costs.
+const (
+	SpendMaintenance = "maintenance"
+	SpendProject     = "project"
+	SpendIncident    = "incident"
+	SpendAppliance   = "appliance"
+	SpendPest        = "pest"
+	SpendWaterFilter = "water_filter"
+	SpendExpense     = "expense"
+)
+
+// SpendEntry is one dated cost pulled from wherever it was recorded.
+// ApplianceID is the appliance it went to, if any. AmountCents is the
+// gross cost; Funding says how much of it others paid.
+type SpendEntry struct {
+	Date        time.Time
+	Category    string
+	Description string
+	Vendor      string
+	AmountCents int64
+	Funding
+	ApplianceID *uint
+}
+
+// OutOfPocketCents is the part of the cost the house paid itself.
+func (e SpendEntry) OutOfPocketCents() int64 {
+	return e.AmountCents - e.CoveredCents()
+}
+
+// ListSpending gathers the current house's recorded costs dated in
+// [since, until), oldest first: service log entries, finished projects,
+// incidents, appliance purchases, pest treatments, water filter changes
+// and expenses. Projects are dated by their end date (falling back to the
+// start date). A service log entry that an expense pays for counts once,
+// as the expense. Expenses take their own category, or SpendExpense
+// without one. Service logged with a funding split keeps it, on the
+// expense too when one pays for it, and a project's is the sum of its
+// invoices'.
+func (s *Store) ListSpending(since, until time.Time) ([]SpendEntry, error) {
+	var out []SpendEntry
+	add := func(date time.Time, category, desc, vendor string, cents *int64, funding Funding, appliance *uint) {
+		if cents == nil || *cents == 0 || date.Before(since) || !date.Before(until) {
+			return
+		}
+		out = append(out, SpendEntry{
+			Date: date, Category: category, Description: desc,
+			Vendor: vendor, AmountCents: *cents, Funding: funding, ApplianceID: appliance,
+		})
+	}
+	unscoped := func(q *gorm.DB) *gorm.DB { return q.Unscoped() }
+
+	var paid []uint
+	if err := s.db.Model(&Expense{}).Where("service_log_entry_id IS NOT NULL").
+		Pluck("service_log_entry_id", &paid).Error; err != nil {
+		return nil, err
+	}
+	var logs []ServiceLogEntry
+	if err := s.db.Preload("MaintenanceItem", unscoped).Preload("Vendor", unscoped).
+		Where(ColMaintenanceItemID+" IN (?)", s.houseMaintenanceIDs()).
+		Where(ColServicedAt+" >= ? AND "+ColServicedAt+" < ?", since, until).
+		Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	for _, l := range logs {
+		if !slices.Contains(paid, l.ID) {
+			add(l.ServicedAt, SpendMaintenance, l.MaintenanceItem.Name, l.Vendor.Name, l.CostCents,
+				l.Funding(), l.MaintenanceItem.ApplianceID)
+		}
+	}
+
+	var projects []Project
+	if err := s.db.Scopes(s.inHouse("projects")).Where(ColActualCents + " IS NOT NULL").
+		Find(&projects).Error; err != nil {
+		return nil, err
+	}
+	var invoices []Invoice
+	if err := s.db.Where(ColProjectID+" IN (?)", s.houseProjectIDs()).
+		Where(ColInsuranceCents + " + " + ColWarrantyCents + " + " + ColRebateCents + " > 0").
+		Find(&invoices).Error; err != nil {
+		return nil, err
+	}
+	projectFunding := map[uint]Funding{}
+	for _, inv := range invoices {
+		projectFunding[inv.ProjectID] = projectFunding[inv.ProjectID].Add(inv.Funding())
+	}
+	for _, p := range projects {
+		date := p.EndDate
+		if date == nil {
+			date = p.StartDate
+		}
+		if date != nil {
+			add(*date, SpendProject, p.Title, "", p.ActualCents, projectFunding[p.ID], nil)
+		}
+	}
+
+	var incidents []Incident
+	if err := s.db.Preload("Vendor", unscoped).Scopes(s.inHouse("incidents")).
+		Where(ColDateNoticed+" >= ? AND "+ColDateNoticed+" < ?", since, until).
+		Find(&incidents).Error; err != nil {
+		return nil, err
+	}
+	for _, i := range incidents {
+		add(i.DateNoticed, SpendIncident, i.Title, i.Vendor.Name, i.CostCents, Funding{}, i.ApplianceID)
+	}
+
+	var appliances []Appliance
+	if err := s.db.Scopes(s.inHouse("appliances")).Where(ColPurchaseDate+" >= ? AND "+ColPurchaseDate+" < ?", since, until).
+		Find(&appliances).Error; err != nil {
+		return nil, err
+	}
+	for _, a := range appliances {
+		add(*a.PurchaseDate, SpendAppliance, a.Name, a.Brand, a.CostCents, Funding{}, &a.ID)
+	}
+
+	var pests []PestTreatment
+	if err := s.db.Preload("Vendor", unscoped).Scopes(s.inHouse("pest_treatments")).
+		Where(ColTreatedAt+" >= ? AND "+ColTreatedAt+" < ?", since, until).
+		Find(&pests).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range pests {
+		add(p.TreatedAt, SpendPest, p.TargetPest+" treatment", p.Vendor.Name, p.CostCents, Funding{}, nil)
+	}
+
+	var filters []WaterFilterChange
+	if err := s.db.Preload("Appliance", unscoped).Scopes(s.inHouse("water_filter_changes")).
+		Where(ColChangedAt+" >= ? AND "+ColChangedAt+" < ?", since, until).
+		Find(&filters).Error; err != nil {
+		return nil, err
+	}
+	for _, f := range filters {
+		add(f.ChangedAt, SpendWaterFilter, f.Appliance.Name+" filter", "", f.CostCents, Funding{}, &f.ApplianceID)
+	}
+
+	var expenses []Expense
+	if err := s.db.Preload("MaintenanceItem", unscoped).Preload("Vendor", unscoped).
+		Preload("ServiceLogEntry", unscoped).Scopes(s.inHouse("expenses")).
+		Where("spent_on >= ? AND spent_on < ?", since, until).
+		Find(&expenses).Error; err != nil {
+		return nil, err
+	}
+	for _, e := range expenses {
+		appliance := e.ApplianceID
+		if appliance == nil {
+			appliance = e.MaintenanceItem.ApplianceID
+		}
+		funding := e.ServiceLogEntry.Funding()
+		if funding.CoveredCents() > e.AmountCents {
+			funding = Funding{}
+		}
+		add(e.SpentOn, cmp.Or(e.Category, SpendExpense), e.Description, e.Vendor.Name, &e.AmountCents, funding, appliance)
+	}
+
+	slices.SortStableFunc(out, func(a, b SpendEntry) int { return a.Date.Compare(b.Date) })
+	return out, nil
+}
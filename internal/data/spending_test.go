@@ -0,0 +1,156 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListSpending(t *testing.T) {
+	store := newTestStore(t)
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 12, 0, 0, 0, time.UTC) }
+	cents := func(c int64) *int64 { return &c }
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	categories, err := store.MaintenanceCategories()
+	require.NoError(t, err)
+	item := MaintenanceItem{Name: "Gutter cleaning", CategoryID: categories[0].ID}
+	require.NoError(t, store.CreateMaintenance(&item))
+	require.NoError(t, store.CreateServiceLog(&ServiceLogEntry{
+		MaintenanceItemID: item.ID, ServicedAt: day(10), CostCents: cents(15000),
+	}, Vendor{Name: "Gutter Pros"}))
+	// Outside the window.
+	require.NoError(t, store.CreateServiceLog(&ServiceLogEntry{
+		MaintenanceItemID: item.ID, ServicedAt: day(20), CostCents: cents(9900),
+	}, Vendor{}))
+
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	require.NoError(t, store.CreateProject(&Project{
+		Title: "Paint porch", ProjectTypeID: types[0].ID, Status: ProjectStatusCompleted,
+		StartDate: ptr(day(1)), EndDate: ptr(day(5)), ActualCents: cents(40000),
+	}))
+	// Appliances without a cost are skipped.
+	require.NoError(t, store.CreateAppliance(&Appliance{Name: "Dryer", PurchaseDate: ptr(day(7))}))
+
+	entries, err := store.ListSpending(day(1), day(15))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, SpendProject, entries[0].Category)
+	assert.Equal(t, "Paint porch", entries[0].Description)
+	assert.Equal(t, day(5), entries[0].Date.UTC())
+	assert.Equal(t, SpendMaintenance, entries[1].Category)
+	assert.Equal(t, "Gutter Pros", entries[1].Vendor)
+	assert.Equal(t, int64(15000), entries[1].AmountCents)
+}
+
+func TestListSpendingStaysInHouse(t *testing.T) {
+	store := newTestStore(t)
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 12, 0, 0, 0, time.UTC) }
+	cents := func(c int64) *int64 { return &c }
+	ptr := func(t time.Time) *time.Time { return &t }
+	categories, err := store.MaintenanceCategories()
+	require.NoError(t, err)
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+
+	// spend records a cost of each kind for the current house, named for
+	// it.
+	spend := func(name string) {
+		t.Helper()
+		item := MaintenanceItem{Name: name + " gutters", CategoryID: categories[0].ID}
+		require.NoError(t, store.CreateMaintenance(&item))
+		require.NoError(t, store.CreateServiceLog(&ServiceLogEntry{
+			MaintenanceItemID: item.ID, ServicedAt: day(2), CostCents: cents(100),
+		}, Vendor{}))
+		require.NoError(t, store.CreateProject(&Project{
+			Title: name + " porch", ProjectTypeID: types[0].ID, Status: ProjectStatusCompleted,
+			EndDate: ptr(day(3)), ActualCents: cents(100),
+		}))
+		require.NoError(t, store.CreateIncident(&Incident{
+			Title: name + " leak", Status: IncidentStatusOpen, Severity: IncidentSeveritySoon,
+			DateNoticed: day(4), CostCents: cents(100),
+		}))
+		softener := Appliance{Name: name + " softener", PurchaseDate: ptr(day(5)), CostCents: cents(100)}
+		require.NoError(t, store.CreateAppliance(&softener))
+		require.NoError(t, store.CreatePestTreatment(&PestTreatment{
+			TargetPest: name + " ants", TreatedAt: day(6), CostCents: cents(100),
+		}))
+		require.NoError(t, store.CreateWaterFilterChange(&WaterFilterChange{
+			ApplianceID: softener.ID, ChangedAt: day(7), CostCents: cents(100),
+		}))
+		require.NoError(t, store.CreateExpense(&Expense{
+			SpentOn: day(8), AmountCents: 100, Description: name + " mulch",
+		}))
+	}
+	described := func() []string {
+		t.Helper()
+		entries, err := store.ListSpending(day(1), day(15))
+		require.NoError(t, err)
+		var out []string
+		for _, e := range entries {
+			out = append(out, e.Description)
+		}
+		return out
+	}
+	want := func(name string) []string {
+		return []string{
+			name + " gutters", name + " porch", name + " leak", name + " softener",
+			name + " ants treatment", name + " softener filter", name + " mulch",
+		}
+	}
+
+	require.NoError(t, store.CreateHouseProfile(HouseProfile{Nickname: "Elm Street"}))
+	home, err := store.HouseProfile()
+	require.NoError(t, err)
+	spend("Elm")
+	cabin := HouseProfile{Nickname: "Lake cabin"}
+	require.NoError(t, store.AddHouse(&cabin))
+	assert.Empty(t, described())
+	spend("Cabin")
+	assert.Equal(t, want("Cabin"), described())
+
+	require.NoError(t, store.SwitchHouse(home.ID))
+	assert.Equal(t, want("Elm"), described())
+	ownership, err := store.CostOfOwnership(day(1), day(15))
+	require.NoError(t, err)
+	require.Len(t, ownership, 1)
+	assert.Equal(t, "Elm softener", ownership[0].Name)
+
+	// Once sold and archived, the cabin's costs stay out of the totals.
+	require.NoError(t, store.ArchiveHouse(cabin.ID))
+	assert.Equal(t, want("Elm"), described())
+}
+
+func TestRecordJobRun(t *testing.T) {
+	store := newTestStore(t)
+	run, err := store.LastJobRun("export:manual")
+	require.NoError(t, err)
+	assert.Nil(t, run)
+
+	first := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, store.RecordJobRun("export:manual", first, time.Second, assert.AnError))
+	run, err = store.LastJobRun("export:manual")
+	require.NoError(t, err)
+	require.NotNil(t, run)
+	assert.Equal(t, assert.AnError.Error(), run.LastError)
+	assert.Nil(t, run.LastSuccessAt)
+
+	require.NoError(t, store.RecordJobRun("export:manual", first.AddDate(0, 1, 0), time.Second, nil))
+	run, err = store.LastJobRun("export:manual")
+	require.NoError(t, err)
+	assert.Empty(t, run.LastError)
+	assert.Equal(t, first.AddDate(0, 1, 0), run.LastRunAt.UTC())
+
+	// A later failure keeps the last success.
+	require.NoError(t, store.RecordJobRun("export:manual", first.AddDate(0, 2, 0), time.Second, assert.AnError))
+	run, err = store.LastJobRun("export:manual")
+	require.NoError(t, err)
+	require.NotNil(t, run.LastSuccessAt)
+	assert.Equal(t, first.AddDate(0, 1, 0), run.LastSuccessAt.UTC())
+}
@@ -0,0 +1,228 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package sqlite
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"unsafe"
+
+	"modernc.org/libc"
+	"modernc.org/libc/sys/types"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// blobChunk is how much of a BLOB one Read copies at most.
+const blobChunk = 64 << 10
+
+// BlobReader streams one BLOB value with SQLite's incremental BLOB I/O, so
+// an attachment is never held in memory whole. It reads through its own
+// read-only connection to the database file, which database/sql can't
+// share, and sees the row as it was when the reader was opened.
+type BlobReader struct {
+	blobHandle
+	off int64
+}
+
+// blobHandle is an open BLOB and the connection holding it.
+type blobHandle struct {
+	tls  *libc.TLS
+	db   uintptr
+	blob uintptr
+	buf  uintptr
+	size int64
+}
+
+// OpenBlob opens the value of column in the row of table with the given
+// rowid in the database file at path.
+func OpenBlob(path, table, column string, rowid int64) (*BlobReader, error) {
+	b := &BlobReader{}
+	if err := b.openHandle(path, table, column, rowid, false); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// WriteBlob copies r into the value of column in the row of table with the
+// given rowid, through its own connection to the database file at path.
+// The value must already be a BLOB of the right length, e.g. one set with
+// zeroblob(n), since incremental I/O can't resize it; r running short or
+// long is an error.
+func WriteBlob(path, table, column string, rowid int64, r io.Reader) (err error) {
+	var b blobHandle
+	if err := b.openHandle(path, table, column, rowid, true); err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := b.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	// GoBytes views the C buffer, so reads land where blob_write looks.
+	chunk := libc.GoBytes(b.buf, blobChunk)
+	var off int64
+	for {
+		n, rerr := io.ReadFull(r, chunk)
+		if n > 0 {
+			if off+int64(n) > b.size {
+				return fmt.Errorf("sqlite: content is longer than the %d byte BLOB", b.size)
+			}
+			if rc := sqlite3.Xsqlite3_blob_write(b.tls, b.blob, b.buf, int32(n), int32(off)); rc != sqlite3.SQLITE_OK {
+				return b.error(rc)
+			}
+			off += int64(n)
+		}
+		if errors.Is(rerr, io.EOF) || errors.Is(rerr, io.ErrUnexpectedEOF) {
+			break
+		}
+		if rerr != nil {
+			return rerr
+		}
+	}
+	if off != b.size {
+		return fmt.Errorf("sqlite: content is %d bytes, the BLOB is %d", off, b.size)
+	}
+	return nil
+}
+
+func (b *blobHandle) openHandle(path, table, column string, rowid int64, write bool) (err error) {
+	b.tls = libc.NewTLS()
+	defer func() {
+		if err != nil {
+			_ = b.Close()
+		}
+	}()
+	flags, writable := int32(sqlite3.SQLITE_OPEN_READONLY), int32(0)
+	if write {
+		flags, writable = sqlite3.SQLITE_OPEN_READWRITE, 1
+	}
+	if err := b.open(path, flags); err != nil {
+		return err
+	}
+	if err := b.openBlob(table, column, rowid, writable); err != nil {
+		return err
+	}
+	b.size = int64(sqlite3.Xsqlite3_blob_bytes(b.tls, b.blob))
+	if b.buf = libc.Xmalloc(b.tls, types.Size_t(blobChunk)); b.buf == 0 {
+		return errors.New("sqlite: out of memory")
+	}
+	return nil
+}
+
+func (b *blobHandle) open(path string, flags int32) error {
+	name, err := libc.CString(path)
+	if err != nil {
+		return err
+	}
+	defer libc.Xfree(b.tls, name)
+	pdb := b.tls.Alloc(ptrSize)
+	defer b.tls.Free(ptrSize)
+	rc := sqlite3.Xsqlite3_open_v2(b.tls, name, pdb, flags, 0)
+	b.db = loadPtr(pdb)
+	if rc != sqlite3.SQLITE_OK {
+		return b.error(rc)
+	}
+	sqlite3.Xsqlite3_busy_timeout(b.tls, b.db, 5000)
+	return nil
+}
+
+func (b *blobHandle) openBlob(table, column string, rowid int64, writable int32) error {
+	var names [3]uintptr
+	for i, s := range []string{"main", table, column} {
+		p, err := libc.CString(s)
+		if err != nil {
+			return err
+		}
+		defer libc.Xfree(b.tls, p)
+		names[i] = p
+	}
+	pblob := b.tls.Alloc(ptrSize)
+	defer b.tls.Free(ptrSize)
+	rc := sqlite3.Xsqlite3_blob_open(b.tls, b.db, names[0], names[1], names[2], rowid, writable, pblob)
+	if rc != sqlite3.SQLITE_OK {
+		return b.error(rc)
+	}
+	b.blob = loadPtr(pblob)
+	return nil
+}
+
+// Size is the length of the BLOB in bytes.
+func (b *blobHandle) Size() int64 { return b.size }
+
+func (b *BlobReader) Read(p []byte) (int, error) {
+	if b.off >= b.size {
+		return 0, io.EOF
+	}
+	n := int(min(int64(len(p)), int64(blobChunk), b.size-b.off))
+	if n == 0 {
+		return 0, nil
+	}
+	if rc := sqlite3.Xsqlite3_blob_read(b.tls, b.blob, b.buf, int32(n), int32(b.off)); rc != sqlite3.SQLITE_OK {
+		return 0, b.error(rc)
+	}
+	copy(p, libc.GoBytes(b.buf, n))
+	b.off += int64(n)
+	return n, nil
+}
+
+func (b *BlobReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += b.off
+	case io.SeekEnd:
+		offset += b.size
+	default:
+		return 0, fmt.Errorf("sqlite: invalid whence %d", whence)
+	}
+	if offset < 0 {
+		return 0, errors.New("sqlite: negative position")
+	}
+	b.off = offset
+	return offset, nil
+}
+
+// Close releases the BLOB handle and the connection.
+func (b *blobHandle) Close() error {
+	if b.tls == nil {
+		return nil
+	}
+	var err error
+	if b.blob != 0 {
+		sqlite3.Xsqlite3_blob_close(b.tls, b.blob)
+	}
+	if b.buf != 0 {
+		libc.Xfree(b.tls, b.buf)
+	}
+	if b.db != 0 {
+		if rc := sqlite3.Xsqlite3_close_v2(b.tls, b.db); rc != sqlite3.SQLITE_OK {
+			err = fmt.Errorf("sqlite: close blob connection: %s", libc.GoString(sqlite3.Xsqlite3_errstr(b.tls, rc)))
+		}
+	}
+	b.tls.Close()
+	*b = blobHandle{}
+	return err
+}
+
+func (b *blobHandle) error(rc int32) error {
+	msg := libc.GoString(sqlite3.Xsqlite3_errstr(b.tls, rc))
+	if b.db != 0 {
+		msg = libc.GoString(sqlite3.Xsqlite3_errmsg(b.tls, b.db))
+	}
+	return fmt.Errorf("sqlite: %s (%d)", msg, rc)
+}
+
+const ptrSize = int(unsafe.Sizeof(uintptr(0)))
+
+// loadPtr reads a pointer that C code stored at p. p is C memory, so
+// going through a byte view keeps the Go pointer rules out of it.
+func loadPtr(p uintptr) uintptr {
+	b := libc.GoBytes(p, ptrSize)
+	if ptrSize == 8 {
+		return uintptr(binary.NativeEndian.Uint64(b))
+	}
+	return uintptr(binary.NativeEndian.Uint32(b))
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package sqlite
+
+import (
+	"bytes"
+	"database/sql"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlobReaderStreamsValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blob.db")
+	db, err := sql.Open(DriverName, path)
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec("CREATE TABLE files (id INTEGER PRIMARY KEY, data BLOB)")
+	require.NoError(t, err)
+	want := bytes.Repeat([]byte("0123456789abcdef"), 3*blobChunk/16+5)
+	_, err = db.Exec("INSERT INTO files (id, data) VALUES (7, ?)", want)
+	require.NoError(t, err)
+
+	r, err := OpenBlob(path, "files", "data", 7)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(want)), r.Size())
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	pos, err := r.Seek(-10, io.SeekEnd)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(want)-10), pos)
+	tail, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, want[len(want)-10:], tail)
+	require.NoError(t, r.Close())
+	require.NoError(t, r.Close(), "closing twice is harmless")
+
+	_, err = OpenBlob(path, "files", "data", 8)
+	assert.ErrorContains(t, err, "no such rowid")
+	_, err = OpenBlob(filepath.Join(t.TempDir(), "missing.db"), "files", "data", 7)
+	assert.Error(t, err)
+}
+
+func TestWriteBlobFillsValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blob.db")
+	db, err := sql.Open(DriverName, path)
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec("CREATE TABLE files (id INTEGER PRIMARY KEY, data BLOB)")
+	require.NoError(t, err)
+	want := bytes.Repeat([]byte("fedcba9876543210"), 2*blobChunk/16+3)
+	_, err = db.Exec("INSERT INTO files (id, data) VALUES (3, zeroblob(?))", len(want))
+	require.NoError(t, err)
+
+	require.NoError(t, WriteBlob(path, "files", "data", 3, bytes.NewReader(want)))
+	var got []byte
+	require.NoError(t, db.QueryRow("SELECT data FROM files WHERE id = 3").Scan(&got))
+	assert.Equal(t, want, got)
+
+	assert.ErrorContains(t, WriteBlob(path, "files", "data", 3, bytes.NewReader(want[1:])), "the BLOB is")
+	assert.ErrorContains(t, WriteBlob(path, "files", "data", 3, bytes.NewReader(append(want, 'x'))), "longer than")
+	assert.ErrorContains(t, WriteBlob(path, "files", "data", 4, bytes.NewReader(want)), "no such rowid")
+}
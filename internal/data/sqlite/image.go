@@ -0,0 +1,75 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package sqlite
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+	"modernc.org/sqlite/vfs"
+)
+
+// Serialize returns the main database of a raw driver connection, as got
+// from sql.Conn.Raw, as the bytes of a SQLite file.
+func Serialize(driverConn any) ([]byte, error) {
+	c, ok := driverConn.(interface{ Serialize() ([]byte, error) })
+	if !ok {
+		return nil, fmt.Errorf("sqlite driver can't serialize a database")
+	}
+	return c.Serialize()
+}
+
+// Restore replaces the main database of a raw driver connection with the
+// SQLite file image. The image is read through a VFS rather than
+// sqlite3_deserialize, whose buffer the driver frees wrongly on close, so
+// it's never written anywhere but the connection's own database. An image
+// marked for WAL can't be read this way.
+func Restore(driverConn any, image []byte) error {
+	c, ok := driverConn.(interface {
+		NewRestore(string) (*sqlite.Backup, error)
+	})
+	if !ok {
+		return fmt.Errorf("sqlite driver can't restore a database")
+	}
+	name, fsys, err := vfs.New(imageFS{image})
+	if err != nil {
+		return fmt.Errorf("register image: %w", err)
+	}
+	defer fsys.Close()
+	b, err := c.NewRestore("file:image.db?vfs=" + name)
+	if err != nil {
+		return err
+	}
+	if _, err := b.Step(-1); err != nil {
+		_ = b.Finish()
+		return err
+	}
+	return b.Finish()
+}
+
+// imageFS holds one read-only database file. Journals never exist.
+type imageFS struct{ image []byte }
+
+func (f imageFS) Open(name string) (fs.File, error) {
+	for _, suffix := range []string{"-journal", "-wal", "-shm"} {
+		if strings.HasSuffix(name, suffix) {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		}
+	}
+	return imageFile{bytes.NewReader(f.image)}, nil
+}
+
+type imageFile struct{ *bytes.Reader }
+
+func (f imageFile) Stat() (fs.FileInfo, error) { return f, nil }
+func (imageFile) Close() error                 { return nil }
+func (imageFile) Name() string                 { return "image.db" }
+func (imageFile) Mode() fs.FileMode            { return 0o400 }
+func (imageFile) ModTime() time.Time           { return time.Time{} }
+func (imageFile) IsDir() bool                  { return false }
+func (imageFile) Sys() any                     { return nil }
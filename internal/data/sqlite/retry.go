@@ -0,0 +1,87 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	sqlite "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// IsTransient reports whether err is the kind of failure that clears up on
+// its own: an I/O error or a file that can't be opened, as when a network
+// filesystem blips or a snapshot briefly holds the file.
+func IsTransient(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+	var serr *sqlite.Error
+	if !errors.As(err, &serr) {
+		return false
+	}
+	switch serr.Code() & 0xff {
+	case sqlite3.SQLITE_IOERR, sqlite3.SQLITE_CANTOPEN, sqlite3.SQLITE_PROTOCOL:
+		return true
+	}
+	return false
+}
+
+// RetryPool runs statements again when they fail with a transient error.
+// Statements inside a transaction are not retried: gorm runs those on the
+// *sql.Tx from BeginTx, not on the pool.
+type RetryPool struct {
+	*sql.DB
+	// Delays are the waits before each retry.
+	Delays []time.Duration
+	// Observe, if set, sees the final outcome of every statement.
+	Observe func(error)
+}
+
+func (p *RetryPool) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	var res sql.Result
+	err := p.retry(ctx, func() (err error) {
+		res, err = p.DB.ExecContext(ctx, query, args...)
+		return err
+	})
+	return res, err
+}
+
+func (p *RetryPool) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	var rows *sql.Rows
+	err := p.retry(ctx, func() (err error) {
+		rows, err = p.DB.QueryContext(ctx, query, args...)
+		return err
+	})
+	return rows, err
+}
+
+// GetDBConn lets gorm's DB() find the underlying pool.
+func (p *RetryPool) GetDBConn() (*sql.DB, error) { return p.DB, nil }
+
+func (p *RetryPool) retry(ctx context.Context, fn func() error) error {
+	err := fn()
+	for _, d := range p.Delays {
+		if err == nil || !IsTransient(err) {
+			break
+		}
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+		case <-t.C:
+			err = fn()
+			continue
+		}
+		break
+	}
+	if p.Observe != nil {
+		p.Observe(err)
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsTransient(t *testing.T) {
+	// A directory can't be opened as a database.
+	db, err := sql.Open(DriverName, t.TempDir())
+	require.NoError(t, err)
+	defer db.Close()
+	err = db.Ping()
+	require.Error(t, err)
+	assert.True(t, IsTransient(err), err)
+
+	assert.True(t, IsTransient(driver.ErrBadConn))
+	assert.False(t, IsTransient(errors.New("no such table: widgets")))
+	assert.False(t, IsTransient(nil))
+}
+
+func TestRetryPoolRetriesTransientErrors(t *testing.T) {
+	var observed []error
+	p := &RetryPool{
+		Delays:  []time.Duration{time.Millisecond, time.Millisecond},
+		Observe: func(err error) { observed = append(observed, err) },
+	}
+	ctx := context.Background()
+
+	calls := 0
+	err := p.retry(ctx, func() error {
+		calls++
+		if calls < 3 {
+			return driver.ErrBadConn
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	err = p.retry(ctx, func() error { calls++; return driver.ErrBadConn })
+	require.ErrorIs(t, err, driver.ErrBadConn)
+	assert.Equal(t, 3, calls, "gives up after the last delay")
+
+	calls = 0
+	permanent := errors.New("constraint failed")
+	err = p.retry(ctx, func() error { calls++; return permanent })
+	require.ErrorIs(t, err, permanent)
+	assert.Equal(t, 1, calls, "other errors aren't retried")
+
+	assert.Equal(t, []error{nil, driver.ErrBadConn, permanent}, observed)
+}
+
+func TestRetryPoolStopsWhenCanceled(t *testing.T) {
+	p := &RetryPool{Delays: []time.Duration{time.Hour}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls := 0
+	err := p.retry(ctx, func() error { calls++; return driver.ErrBadConn })
+	require.ErrorIs(t, err, driver.ErrBadConn)
+	assert.Equal(t, 1, calls)
+}
@@ -35,6 +35,9 @@ type Dialector struct {
 	DSN        string
 	Conn       gorm.ConnPool
 	Pragmas    []string // PRAGMA statements to run on each new connection
+	// Retry, if set, wraps the connection pool it opens; its DB field is
+	// filled in. Ignored when Conn is given.
+	Retry *RetryPool
 }
 
 func Open(dsn string, pragmas ...string) gorm.Dialector {
@@ -109,17 +112,17 @@ func (dialector Dialector) Initialize(db *gorm.DB) (err error) {
 		if err := tmpDB.Close(); err != nil {
 			return err
 		}
-		db.ConnPool = sql.OpenDB(&pragmaConnector{
+		db.ConnPool = dialector.wrap(sql.OpenDB(&pragmaConnector{
 			dsn:     dialector.DSN,
 			driver:  drv,
 			pragmas: dialector.Pragmas,
-		})
+		}))
 	} else {
 		conn, err := sql.Open(dialector.DriverName, dialector.DSN)
 		if err != nil {
 			return err
 		}
-		db.ConnPool = conn
+		db.ConnPool = dialector.wrap(conn)
 	}
 
 	var version string
@@ -148,6 +151,14 @@ func (dialector Dialector) Initialize(db *gorm.DB) (err error) {
 	return
 }
 
+func (dialector Dialector) wrap(conn *sql.DB) gorm.ConnPool {
+	if dialector.Retry == nil {
+		return conn
+	}
+	dialector.Retry.DB = conn
+	return dialector.Retry
+}
+
 func (dialector Dialector) ClauseBuilders() map[string]clause.ClauseBuilder {
 	return map[string]clause.ClauseBuilder{
 		"INSERT": func(c clause.Clause, builder clause.Builder) {
@@ -0,0 +1,130 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// staleStatuses are the statuses of a project someone ought to be moving
+// along.
+var staleStatuses = []string{
+	ProjectStatusPlanned, ProjectStatusQuoted, ProjectStatusInProgress, ProjectStatusDelayed,
+}
+
+// StaleProject is an active project nothing has happened to for a while.
+// LastActivity is the latest of its own edits and those of its quotes,
+// bid requests, invoices, expenses, documents and tasks.
+type StaleProject struct {
+	Project      Project
+	LastActivity time.Time
+	IdleDays     int
+}
+
+// ListStaleProjects returns the current house's planned, quoted, underway
+// and delayed projects with no activity for at least idle before now, the
+// longest idle first.
+func (s *Store) ListStaleProjects(now time.Time, idle time.Duration) ([]StaleProject, error) {
+	var projects []Project
+	err := s.db.Where(ColStatus+" IN ?", staleStatuses).
+		Scopes(s.inHouse("projects")).
+		Preload("ProjectType").
+		Find(&projects).Error
+	if err != nil {
+		return nil, err
+	}
+	last := make(map[uint]time.Time, len(projects))
+	ids := make([]uint, len(projects))
+	for i, p := range projects {
+		ids[i] = p.ID
+		last[p.ID] = p.UpdatedAt
+	}
+	for _, q := range []*gorm.DB{
+		s.db.Model(&Quote{}).Select("project_id, updated_at AS at"),
+		s.db.Model(&BidRequest{}).Select("project_id, updated_at AS at"),
+		s.db.Model(&Invoice{}).Select("project_id, updated_at AS at"),
+		s.db.Model(&Expense{}).Select("project_id, updated_at AS at"),
+		s.db.Model(&ProjectTask{}).Select("project_id, updated_at AS at"),
+		s.db.Model(&Document{}).Select(ColEntityID+" AS project_id, updated_at AS at").
+			Where(ColEntityKind+" = ?", DocumentEntityProject),
+	} {
+		var touched []struct {
+			ProjectID uint
+			At        time.Time
+		}
+		if err := q.Where("project_id IN ?", ids).Scan(&touched).Error; err != nil {
+			return nil, err
+		}
+		for _, t := range touched {
+			if t.At.After(last[t.ProjectID]) {
+				last[t.ProjectID] = t.At
+			}
+		}
+	}
+
+	var out []StaleProject
+	for _, p := range projects {
+		at := last[p.ID]
+		if now.Sub(at) < idle {
+			continue
+		}
+		out = append(out, StaleProject{Project: p, LastActivity: at, IdleDays: int(now.Sub(at).Hours() / 24)})
+	}
+	slices.SortStableFunc(out, func(a, b StaleProject) int {
+		return cmp.Or(a.LastActivity.Compare(b.LastActivity), cmp.Compare(a.Project.ID, b.Project.ID))
+	})
+	return out, nil
+}
+
+// BumpProject marks a project as looked at now, so it stops counting as
+// stale, without changing anything about it.
+func (s *Store) BumpProject(id uint, now time.Time) error {
+	result := s.db.Model(&Project{}).Where(ColID+" = ?", id).UpdateColumn(ColUpdatedAt, now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetProjectStatus moves a project to status, for reason if it's delayed
+// or abandoned. A note, if given, is added to the end of the description
+// with the status and the day, as in "Abandoned 2026-03-01: sold the
+// house", and kept with the reason. Completing it with open tasks fails
+// with ErrOpenTasks.
+func (s *Store) SetProjectStatus(id uint, status, reason, note string, now time.Time) error {
+	if !slices.Contains(ProjectStatuses(), status) {
+		return fmt.Errorf("unknown project status %q", status)
+	}
+	project, err := s.GetProject(id)
+	if err != nil {
+		return err
+	}
+	before := project.Status
+	project.Status, project.StatusReason = status, reason
+	if err := checkStatusReason(&project); err != nil {
+		return err
+	}
+	if note = strings.TrimSpace(note); note != "" {
+		line := fmt.Sprintf("%s%s %s: %s", strings.ToUpper(status[:1]), status[1:], now.Format(time.DateOnly), note)
+		project.Description = strings.TrimSpace(project.Description + "\n\n" + line)
+	}
+	return s.InTransaction(func(tx *Store) error {
+		if err := tx.checkOpenTasks(before, project); err != nil {
+			return err
+		}
+		if err := tx.updateByID(&Project{}, id, project); err != nil {
+			return err
+		}
+		return tx.logStatusChange(before, project, note, now)
+	})
+}
@@ -0,0 +1,58 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestStaleProjects(t *testing.T) {
+	store := newTestStore(t)
+	now := time.Now()
+	idle := 30 * 24 * time.Hour
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+
+	create := func(title, status string, idleDays int) Project {
+		t.Helper()
+		p := Project{Title: title, ProjectTypeID: types[0].ID, Status: status, Description: "Plan it"}
+		require.NoError(t, store.CreateProject(&p))
+		require.NoError(t, store.db.Model(&p).UpdateColumn(ColUpdatedAt, now.AddDate(0, 0, -idleDays)).Error)
+		return p
+	}
+	roof := create("Roof", ProjectStatusPlanned, 60)
+	shed := create("Shed", ProjectStatusQuoted, 45)
+	deck := create("Deck", ProjectStatusInProgress, 60)
+	create("Fence", ProjectStatusCompleted, 90)
+	create("Porch", ProjectStatusInProgress, 5)
+
+	// A fresh quote counts as activity on the deck.
+	require.NoError(t, store.CreateQuote(&Quote{ProjectID: deck.ID, TotalCents: 100}, Vendor{Name: "Deck Co"}))
+
+	stale, err := store.ListStaleProjects(now, idle)
+	require.NoError(t, err)
+	require.Len(t, stale, 2)
+	assert.Equal(t, roof.ID, stale[0].Project.ID)
+	assert.Equal(t, 60, stale[0].IdleDays)
+	assert.Equal(t, shed.ID, stale[1].Project.ID)
+
+	require.NoError(t, store.BumpProject(roof.ID, now))
+	require.ErrorIs(t, store.BumpProject(999, now), gorm.ErrRecordNotFound)
+
+	require.ErrorContains(t, store.SetProjectStatus(shed.ID, "shelved", "", "", now), "unknown project status")
+	require.NoError(t, store.SetProjectStatus(shed.ID, ProjectStatusAbandoned, StatusReasonBudget, " Too pricey ", now))
+	got, err := store.GetProject(shed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ProjectStatusAbandoned, got.Status)
+	assert.Equal(t, "Plan it\n\nAbandoned "+now.Format(time.DateOnly)+": Too pricey", got.Description)
+
+	stale, err = store.ListStaleProjects(now, idle)
+	require.NoError(t, err)
+	assert.Empty(t, stale)
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrStatusReason means a project was given a reason that isn't one of
+// StatusReasons.
+var ErrStatusReason = errors.New("unknown status reason")
+
+// ProjectStatusChange records a project being delayed or abandoned, and
+// why, for reporting on what makes projects slip.
+type ProjectStatusChange struct {
+	ID        uint    `gorm:"primaryKey"`
+	ProjectID uint    `gorm:"index"`
+	Project   Project `gorm:"constraint:OnDelete:CASCADE;"`
+	Status    string
+	Reason    string
+	Note      string
+	ChangedAt time.Time
+}
+
+// stalled reports whether a project in status needs a reason.
+func stalled(status string) bool {
+	return status == ProjectStatusDelayed || status == ProjectStatusAbandoned
+}
+
+// checkStatusReason clears a project's reason unless it's delayed or
+// abandoned, and refuses one that isn't known.
+func checkStatusReason(p *Project) error {
+	if !stalled(p.Status) {
+		p.StatusReason = ""
+	}
+	if p.StatusReason != "" && !slices.Contains(StatusReasons(), p.StatusReason) {
+		return fmt.Errorf("%w %q", ErrStatusReason, p.StatusReason)
+	}
+	return nil
+}
+
+// logStatusChange records p being delayed or abandoned when it wasn't in
+// that status before.
+func (s *Store) logStatusChange(before string, p Project, note string, now time.Time) error {
+	if !stalled(p.Status) || p.Status == before {
+		return nil
+	}
+	return s.db.Create(&ProjectStatusChange{
+		ProjectID: p.ID, Status: p.Status, Reason: p.StatusReason, Note: note, ChangedAt: now,
+	}).Error
+}
+
+// projectStatus is what a project's status is now, for telling whether an
+// update changes it.
+func (s *Store) projectStatus(id uint) (string, error) {
+	var p Project
+	err := s.db.Select(ColStatus).First(&p, id).Error
+	return p.Status, err
+}
+
+// StatusReasonCount counts the times projects were delayed and abandoned
+// for a reason. An empty Reason counts those given none.
+type StatusReasonCount struct {
+	Reason    string
+	Delayed   int
+	Abandoned int
+}
+
+// SlipReport is why the current house's projects were delayed or
+// abandoned: the counts by reason, the most common first, and every
+// change, the latest first.
+type SlipReport struct {
+	Reasons []StatusReasonCount
+	Changes []ProjectStatusChange
+}
+
+// SlipReasons reports why the current house's projects were delayed or
+// abandoned. Deleted projects are left out.
+func (s *Store) SlipReasons() (SlipReport, error) {
+	var changes []ProjectStatusChange
+	err := s.db.Joins("JOIN projects ON projects."+ColID+" = project_status_changes.project_id").
+		Where("projects."+ColDeletedAt+" IS NULL").
+		Scopes(s.inHouse("projects")).
+		Preload("Project", func(q *gorm.DB) *gorm.DB { return q.Preload("ProjectType") }).
+		Order("project_status_changes.changed_at desc, project_status_changes." + ColID + " desc").
+		Find(&changes).Error
+	if err != nil {
+		return SlipReport{}, err
+	}
+
+	counts := map[string]*StatusReasonCount{}
+	for _, c := range changes {
+		n := counts[c.Reason]
+		if n == nil {
+			n = &StatusReasonCount{Reason: c.Reason}
+			counts[c.Reason] = n
+		}
+		if c.Status == ProjectStatusAbandoned {
+			n.Abandoned++
+		} else {
+			n.Delayed++
+		}
+	}
+	report := SlipReport{Changes: changes}
+	for _, reason := range append(StatusReasons(), "") {
+		if n := counts[reason]; n != nil {
+			report.Reasons = append(report.Reasons, *n)
+		}
+	}
+	slices.SortStableFunc(report.Reasons, func(a, b StatusReasonCount) int {
+		return cmp.Compare(b.Delayed+b.Abandoned, a.Delayed+a.Abandoned)
+	})
+	return report, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlipReasons(t *testing.T) {
+	store := newTestStore(t)
+	// Set a little ahead, so the changes made now come after those logged on
+	// the way.
+	now := time.Now().Add(time.Hour)
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+
+	deck := Project{Title: "Deck", ProjectTypeID: types[0].ID, Status: ProjectStatusInProgress}
+	require.NoError(t, store.CreateProject(&deck))
+	shed := Project{
+		Title: "Shed", ProjectTypeID: types[0].ID, Status: ProjectStatusDelayed, StatusReason: StatusReasonBudget,
+	}
+	require.NoError(t, store.CreateProject(&shed))
+	// A reason means nothing for a project that isn't stuck.
+	porch := Project{Title: "Porch", ProjectTypeID: types[0].ID, Status: ProjectStatusPlanned, StatusReason: StatusReasonWeather}
+	require.NoError(t, store.CreateProject(&porch))
+	assert.Empty(t, porch.StatusReason)
+
+	deck.Status, deck.StatusReason = ProjectStatusDelayed, "aliens"
+	require.ErrorIs(t, store.UpdateProject(deck), ErrStatusReason)
+	deck.StatusReason = StatusReasonWeather
+	require.NoError(t, store.UpdateProject(deck))
+	// Editing a delayed project without moving it logs nothing more.
+	deck, err = store.GetProject(deck.ID)
+	require.NoError(t, err)
+	deck.Title = "Back deck"
+	require.NoError(t, store.UpdateProject(deck))
+	require.NoError(t, store.SetProjectStatus(deck.ID, ProjectStatusAbandoned, StatusReasonBudget, "Spent it on the roof", now))
+	require.NoError(t, store.SetProjectStatus(porch.ID, ProjectStatusDelayed, "", "", now))
+
+	got, err := store.GetProject(deck.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusReasonBudget, got.StatusReason)
+	require.NoError(t, store.SetProjectStatus(deck.ID, ProjectStatusInProgress, StatusReasonBudget, "", now))
+	got, err = store.GetProject(deck.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.StatusReason, "an underway project has no reason")
+
+	report, err := store.SlipReasons()
+	require.NoError(t, err)
+	assert.Equal(t, []StatusReasonCount{
+		{Reason: StatusReasonBudget, Delayed: 1, Abandoned: 1},
+		{Reason: StatusReasonWeather, Delayed: 1},
+		{Reason: "", Delayed: 1},
+	}, report.Reasons)
+	require.Len(t, report.Changes, 4)
+	assert.Equal(t, "Spent it on the roof", report.Changes[1].Note)
+	assert.Equal(t, "Back deck", report.Changes[1].Project.Title)
+
+	require.NoError(t, store.DeleteProject(porch.ID))
+	report, err = store.SlipReasons()
+	require.NoError(t, err)
+	assert.Len(t, report.Changes, 3, "deleted projects are left out")
+}
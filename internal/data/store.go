@@ -4,11 +4,15 @@
 package data
 
 import (
+	"context"
 	"crypto/sha256"
 	"errors"
 	"fmt"
 	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 	"unicode"
 
@@ -24,19 +28,56 @@ import (
 type Store struct {
 	db              *gorm.DB
 	maxDocumentSize int64
+	health          *health
+	readOnly        bool
+	path            string
+	// sealed is set when the database is encrypted at rest; path is then
+	// ":memory:".
+	sealed *sealedFile
+	// undoDepth is how many steps the undo log keeps.
+	undoDepth *atomic.Int64
+	// filesDir is where document files kept as files are, and inFiles
+	// whether new ones are kept there (see SetDocumentStorage).
+	filesDir string
+	inFiles  bool
 }
 
 func Open(path string) (*Store, error) {
+	return open(path, false)
+}
+
+// OpenReadOnly opens a database without ever writing to it, e.g. one from a
+// newer version of webcasa (see SchemaTooNewError). Don't migrate or seed
+// it.
+func OpenReadOnly(path string) (*Store, error) {
+	return open(path, true)
+}
+
+func open(path string, readOnly bool) (*Store, error) {
 	if err := ValidateDBPath(path); err != nil {
 		return nil, err
 	}
+	if encrypted, err := IsEncrypted(path); err != nil {
+		return nil, fmt.Errorf("open db: %w", err)
+	} else if encrypted {
+		return nil, ErrEncrypted
+	}
+	pragmas := []string{
+		"PRAGMA foreign_keys = ON",
+		"PRAGMA journal_mode = WAL",
+		"PRAGMA synchronous = NORMAL",
+		"PRAGMA busy_timeout = 5000",
+	}
+	if readOnly {
+		pragmas = append(pragmas, "PRAGMA query_only = ON")
+	}
+	h := newHealth()
 	db, err := gorm.Open(
-		sqlite.Open(path,
-			"PRAGMA foreign_keys = ON",
-			"PRAGMA journal_mode = WAL",
-			"PRAGMA synchronous = NORMAL",
-			"PRAGMA busy_timeout = 5000",
-		),
+		&sqlite.Dialector{
+			DSN:     path,
+			Pragmas: pragmas,
+			Retry:   &sqlite.RetryPool{Delays: retryDelays, Observe: h.observe},
+		},
 		&gorm.Config{
 			Logger: logger.Default.LogMode(logger.Silent),
 		},
@@ -45,18 +86,42 @@ func Open(path string) (*Store, error) {
 		return nil, fmt.Errorf("open db: %w", err)
 	}
 
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("get underlying db: %w", err)
+	}
 	// In-memory SQLite gives each connection its own database. Limit the
 	// pool to one connection so AutoMigrate, seeding, and queries all
 	// share the same in-memory instance.
 	if path == ":memory:" {
-		sqlDB, err := db.DB()
-		if err != nil {
-			return nil, fmt.Errorf("get underlying db: %w", err)
-		}
 		sqlDB.SetMaxOpenConns(1)
 	}
+	// Probes go straight to the pool, past the retries. Reading the schema
+	// touches the file, unlike SELECT 1.
+	h.probe = func(ctx context.Context) error {
+		var n int
+		return sqlDB.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master").Scan(&n)
+	}
+
+	if err := registerSchedule(db); err != nil {
+		return nil, fmt.Errorf("register callbacks: %w", err)
+	}
+	if err := registerHouseFiling(db); err != nil {
+		return nil, fmt.Errorf("register callbacks: %w", err)
+	}
+	if err := registerAuditing(db); err != nil {
+		return nil, fmt.Errorf("register callbacks: %w", err)
+	}
+	undoDepth := new(atomic.Int64)
+	undoDepth.Store(DefaultUndoDepth)
+	if err := registerUndoTrim(db, undoDepth); err != nil {
+		return nil, fmt.Errorf("register callbacks: %w", err)
+	}
 
-	return &Store{db: db, maxDocumentSize: MaxDocumentSize}, nil
+	return &Store{
+		db: db, maxDocumentSize: MaxDocumentSize, health: h, readOnly: readOnly, path: path,
+		undoDepth: undoDepth,
+	}, nil
 }
 
 // MaxDocumentSize returns the configured maximum file size for document imports.
@@ -128,22 +193,79 @@ func isLetterOnly(s string) bool {
 	return true
 }
 
-// Close closes the underlying database connection.
+// Close closes the underlying database connection, first saving an
+// encrypted database.
 func (s *Store) Close() error {
 	sqlDB, err := s.db.DB()
 	if err != nil {
 		return fmt.Errorf("get underlying db: %w", err)
 	}
+	if s.sealed != nil {
+		if err := s.sealed.close(s); err != nil {
+			_ = sqlDB.Close()
+			return err
+		}
+	}
 	return sqlDB.Close()
 }
 
+// InTransaction runs fn with a store whose reads and writes all go through
+// one transaction, committed only if fn returns nil. Methods of the outer
+// store must not be called from fn.
+func (s *Store) InTransaction(fn func(tx *Store) error) error {
+	return s.db.Transaction(func(db *gorm.DB) error {
+		tx := *s
+		tx.db = db
+		return fn(&tx)
+	})
+}
+
+// AutoMigrate brings the tables up to date and records SchemaVersion. It
+// refuses, with a *SchemaTooNewError, a database last migrated by a newer
+// build.
 func (s *Store) AutoMigrate() error {
-	return s.db.AutoMigrate(
+	found, err := s.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if found > SchemaVersion {
+		return &SchemaTooNewError{Found: found, Supported: SchemaVersion}
+	}
+	if err := s.db.AutoMigrate(allModels()...); err != nil {
+		return err
+	}
+	if err := s.migrateDocuments(); err != nil {
+		return err
+	}
+	if err := syncNextDue(s.db); err != nil {
+		return err
+	}
+	if err := claimUnfiled(s.db); err != nil {
+		return err
+	}
+	if err := ensureSearchIndex(s.db); err != nil {
+		return err
+	}
+	if err := ensureDocTextIndex(s.db); err != nil {
+		return err
+	}
+	if found < SchemaVersion {
+		return s.PutSetting(settingSchemaVersion, strconv.Itoa(SchemaVersion))
+	}
+	return nil
+}
+
+// allModels lists every table the store manages, in migration order.
+func allModels() []any {
+	return []any{
 		&HouseProfile{},
 		&ProjectType{},
 		&Vendor{},
 		&Project{},
 		&Quote{},
+		&QuoteLine{},
+		&ChangeOrder{},
+		&Invoice{},
 		&MaintenanceCategory{},
 		&Appliance{},
 		&MaintenanceItem{},
@@ -151,9 +273,55 @@ func (s *Store) AutoMigrate() error {
 		&Incident{},
 		&Document{},
 		&DeletionRecord{},
+		&FieldChange{},
 		&Setting{},
 		&ChatInput{},
-	)
+		&SmartDevice{},
+		&LandscapeAsset{},
+		&PestTreatment{},
+		&WaterTest{},
+		&WaterFilterChange{},
+		&AirFilterSpec{},
+		&Room{},
+		&MaterialEstimate{},
+		&RoomFinish{},
+		&FloorPlan{},
+		&RoomHotspot{},
+		&Walkthrough{},
+		&WalkthroughItem{},
+		&HouseEvent{},
+		&HouseEventTask{},
+		&BidRequest{},
+		&Expense{},
+		&WarrantyClaim{},
+		&Warranty{},
+		&InventoryItem{},
+		&Rebate{},
+		&Contact{},
+		&UtilityBill{},
+		&ProjectStatusChange{},
+		&ProjectTask{},
+		&ClaimCorrespondence{},
+		&SitterStay{},
+		&SitterNote{},
+		&JobRun{},
+		&APIToken{},
+		&SecondFactor{},
+		&User{},
+		&UserSession{},
+		&ReminderState{},
+		&ReminderSnooze{},
+		&UsageCounter{},
+		&UndoEntry{},
+		&AuditEntry{},
+		&Embedding{},
+		&Tag{},
+		&TagLink{},
+		&CustomField{},
+		&CustomValue{},
+		&Thumbnail{},
+		&LLMUsage{},
+	}
 }
 
 func (s *Store) SeedDefaults() error {
@@ -295,6 +463,7 @@ func (s *Store) SeedDemoDataFrom(h *fake.HomeFaker) error {
 				TotalCents:     fq.TotalCents,
 				LaborCents:     fq.LaborCents,
 				MaterialsCents: fq.MaterialsCents,
+				RequestedDate:  fq.RequestedDate,
 				ReceivedDate:   fq.ReceivedDate,
 				Notes:          fq.Notes,
 			}
@@ -451,34 +620,53 @@ func (s *Store) SeedDemoDataFrom(h *fake.HomeFaker) error {
 	return nil
 }
 
+// HouseProfile returns the current house: the one last switched to, or
+// else the first active one. Archived houses are left out.
 func (s *Store) HouseProfile() (HouseProfile, error) {
-	var profile HouseProfile
-	err := s.db.First(&profile).Error
+	profile, err := currentHouse(s.db)
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return HouseProfile{}, gorm.ErrRecordNotFound
 	}
 	return profile, err
 }
 
+// CreateHouseProfile sets up the first house, filing everything recorded
+// so far under it. Further houses are added with AddHouse.
 func (s *Store) CreateHouseProfile(profile HouseProfile) error {
 	var count int64
-	if err := s.db.Model(&HouseProfile{}).Count(&count).Error; err != nil {
+	if err := s.db.Model(&HouseProfile{}).Scopes(activeHouse).Count(&count).Error; err != nil {
 		return fmt.Errorf("count house profiles: %w", err)
 	}
 	if count > 0 {
 		return fmt.Errorf("house profile already exists")
 	}
-	return s.db.Create(&profile).Error
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&profile).Error; err != nil {
+			return err
+		}
+		return claimUnfiled(tx)
+	})
+	if err != nil {
+		return err
+	}
+	return s.SwitchHouse(profile.ID)
 }
 
+// UpdateHouseProfile saves the current house's profile.
 func (s *Store) UpdateHouseProfile(profile HouseProfile) error {
-	var existing HouseProfile
-	if err := s.db.First(&existing).Error; err != nil {
+	existing, err := currentHouse(s.db)
+	if err != nil {
 		return err
 	}
 	profile.ID = existing.ID
 	profile.CreatedAt = existing.CreatedAt
-	return s.db.Model(&existing).Select("*").Updates(profile).Error
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return recordChanges(tx, &HouseProfile{}, existing.ID, func(tx *gorm.DB) error {
+			return versioned(tx, &HouseProfile{}, existing.ID, profile.Version, func(q *gorm.DB) *gorm.DB {
+				return q.Select("*").Omit(ColID, ColCreatedAt, ColVersion, ColArchivedAt).Updates(profile)
+			})
+		})
+	})
 }
 
 func (s *Store) ProjectTypes() ([]ProjectType, error) {
@@ -540,19 +728,22 @@ func (s *Store) CountQuotesByProject(projectIDs []uint) (map[uint]int, error) {
 	return s.countByFK(&Quote{}, ColProjectID, projectIDs)
 }
 
-// ListQuotesByVendor returns all quotes for a specific vendor.
+// ListQuotesByVendor returns a vendor's quotes for the current house's
+// projects. Vendors are shared, so their other quotes are another house's.
 func (s *Store) ListQuotesByVendor(
 	vendorID uint,
 	includeDeleted bool,
 ) ([]Quote, error) {
 	var quotes []Quote
 	db := s.db.Where(ColVendorID+" = ?", vendorID).
+		Where(ColProjectID+" IN (?)", s.houseProjectIDs()).
 		Preload("Vendor", func(q *gorm.DB) *gorm.DB {
 			return q.Unscoped()
 		}).
 		Preload("Project", func(q *gorm.DB) *gorm.DB {
 			return q.Unscoped()
 		}).
+		Preload("Lines", quoteLinesPreload).
 		Order(ColReceivedDate + " desc, " + ColID + " desc")
 	if includeDeleted {
 		db = db.Unscoped()
@@ -576,6 +767,7 @@ func (s *Store) ListQuotesByProject(
 		Preload("Project", func(q *gorm.DB) *gorm.DB {
 			return q.Unscoped()
 		}).
+		Preload("Lines", quoteLinesPreload).
 		Order(ColReceivedDate + " desc, " + ColID + " desc")
 	if includeDeleted {
 		db = db.Unscoped()
@@ -586,13 +778,15 @@ func (s *Store) ListQuotesByProject(
 	return quotes, nil
 }
 
-// ListServiceLogsByVendor returns all service log entries for a specific vendor.
+// ListServiceLogsByVendor returns a vendor's service log entries for the
+// current house's maintenance items.
 func (s *Store) ListServiceLogsByVendor(
 	vendorID uint,
 	includeDeleted bool,
 ) ([]ServiceLogEntry, error) {
 	var entries []ServiceLogEntry
 	db := s.db.Where(ColVendorID+" = ?", vendorID).
+		Where(ColMaintenanceItemID+" IN (?)", s.houseMaintenanceIDs()).
 		Preload("Vendor", func(q *gorm.DB) *gorm.DB {
 			return q.Unscoped()
 		}).
@@ -611,14 +805,18 @@ func (s *Store) ListServiceLogsByVendor(
 
 func (s *Store) ListProjects(includeDeleted bool) ([]Project, error) {
 	var projects []Project
-	db := s.db.Preload("ProjectType").Order(ColUpdatedAt + " desc, " + ColID + " desc")
+	db := s.db.Preload("ProjectType").Scopes(s.inHouse("projects")).
+		Order(ColUpdatedAt + " desc, " + ColID + " desc")
 	if includeDeleted {
 		db = db.Unscoped()
 	}
 	if err := db.Find(&projects).Error; err != nil {
 		return nil, err
 	}
-	return projects, nil
+	if err := s.fillProjectsTasks(projects); err != nil {
+		return nil, err
+	}
+	return projects, s.fillProjectsCustom(projects)
 }
 
 func (s *Store) ListQuotes(includeDeleted bool) ([]Quote, error) {
@@ -629,6 +827,8 @@ func (s *Store) ListQuotes(includeDeleted bool) ([]Quote, error) {
 	db = db.Preload("Project", func(q *gorm.DB) *gorm.DB {
 		return q.Unscoped().Preload("ProjectType")
 	})
+	db = db.Preload("Lines", quoteLinesPreload)
+	db = db.Where(ColProjectID+" IN (?)", s.houseProjectIDs())
 	db = db.Order(ColUpdatedAt + " desc, " + ColID + " desc")
 	if includeDeleted {
 		db = db.Unscoped()
@@ -645,7 +845,10 @@ func (s *Store) ListMaintenance(includeDeleted bool) ([]MaintenanceItem, error)
 	db = db.Preload("Appliance", func(q *gorm.DB) *gorm.DB {
 		return q.Unscoped()
 	})
-	db = db.Order(ColUpdatedAt + " desc, " + ColID + " desc")
+	db = db.Preload("LandscapeAsset", func(q *gorm.DB) *gorm.DB {
+		return q.Unscoped()
+	})
+	db = db.Scopes(s.inHouse(tableMaintenanceItems)).Order(ColUpdatedAt + " desc, " + ColID + " desc")
 	if includeDeleted {
 		db = db.Unscoped()
 	}
@@ -674,16 +877,56 @@ func (s *Store) ListMaintenanceByAppliance(
 
 func (s *Store) GetProject(id uint) (Project, error) {
 	var project Project
-	err := s.db.Preload("ProjectType").First(&project, id).Error
-	return project, err
+	if err := s.db.Preload("ProjectType").First(&project, id).Error; err != nil {
+		return Project{}, err
+	}
+	projects := []Project{project}
+	if err := s.fillProjectsTasks(projects); err != nil {
+		return Project{}, err
+	}
+	err := s.fillProjectsCustom(projects)
+	return projects[0], err
 }
 
+// CreateProject saves a project with its custom field values.
 func (s *Store) CreateProject(project *Project) error {
-	return s.db.Create(project).Error
+	if err := checkStatusReason(project); err != nil {
+		return err
+	}
+	return s.InTransaction(func(tx *Store) error {
+		if err := tx.db.Create(project).Error; err != nil {
+			return err
+		}
+		if err := tx.logStatusChange("", *project, "", time.Now()); err != nil {
+			return err
+		}
+		return tx.setCustomValues(DocumentEntityProject, project.ID, project.Custom)
+	})
 }
 
+// UpdateProject saves a project's fields and the custom field values it
+// gives. Delaying or abandoning it is logged with its reason. Completing
+// it with open tasks fails with ErrOpenTasks unless it allows them.
 func (s *Store) UpdateProject(project Project) error {
-	return s.updateByID(&Project{}, project.ID, project)
+	if err := checkStatusReason(&project); err != nil {
+		return err
+	}
+	return s.InTransaction(func(tx *Store) error {
+		before, err := tx.projectStatus(project.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.checkOpenTasks(before, project); err != nil {
+			return err
+		}
+		if err := tx.updateByID(&Project{}, project.ID, project); err != nil {
+			return err
+		}
+		if err := tx.logStatusChange(before, project, "", time.Now()); err != nil {
+			return err
+		}
+		return tx.setCustomValues(DocumentEntityProject, project.ID, project.Custom)
+	})
 }
 
 func (s *Store) GetQuote(id uint) (Quote, error) {
@@ -693,11 +936,17 @@ func (s *Store) GetQuote(id uint) (Quote, error) {
 	}).Preload("Project", func(q *gorm.DB) *gorm.DB {
 		return q.Unscoped().Preload("ProjectType")
 	}).
+		Preload("Lines", quoteLinesPreload).
 		First(&quote, id).Error
 	return quote, err
 }
 
+// CreateQuote saves a quote and its lines, if it has any. Lines have to add
+// up to the total (see ErrQuoteLines).
 func (s *Store) CreateQuote(quote *Quote, vendor Vendor) error {
+	if err := itemizeQuote(quote, quote.Lines); err != nil {
+		return err
+	}
 	return s.db.Transaction(func(tx *gorm.DB) error {
 		foundVendor, err := findOrCreateVendor(tx, vendor)
 		if err != nil {
@@ -708,6 +957,9 @@ func (s *Store) CreateQuote(quote *Quote, vendor Vendor) error {
 	})
 }
 
+// UpdateQuote saves a quote. Non-nil Lines replace the quote's lines, and
+// an empty slice removes them; with nil Lines the saved lines stay, and the
+// new total still has to match them.
 func (s *Store) UpdateQuote(quote Quote, vendor Vendor) error {
 	return s.db.Transaction(func(tx *gorm.DB) error {
 		foundVendor, err := findOrCreateVendor(tx, vendor)
@@ -715,6 +967,22 @@ func (s *Store) UpdateQuote(quote Quote, vendor Vendor) error {
 			return err
 		}
 		quote.VendorID = foundVendor.ID
+		lines := quote.Lines
+		if lines == nil {
+			if err := tx.Where(ColQuoteID+" = ?", quote.ID).
+				Order(ColSortOrder + ", " + ColID).Find(&lines).Error; err != nil {
+				return err
+			}
+		}
+		if err := itemizeQuote(&quote, lines); err != nil {
+			return err
+		}
+		if quote.Lines != nil {
+			if err := replaceQuoteLines(tx, quote.ID, lines); err != nil {
+				return err
+			}
+		}
+		quote.Lines = nil
 		return updateByIDWith(tx, &Quote{}, quote.ID, quote)
 	})
 }
@@ -725,11 +993,15 @@ func (s *Store) GetMaintenance(id uint) (MaintenanceItem, error) {
 		Preload("Appliance", func(q *gorm.DB) *gorm.DB {
 			return q.Unscoped()
 		}).
+		Preload("LandscapeAsset", func(q *gorm.DB) *gorm.DB {
+			return q.Unscoped()
+		}).
 		First(&item, id).Error
 	return item, err
 }
 
 func (s *Store) CreateMaintenance(item *MaintenanceItem) error {
+	item.NextDueAt = nextDueAt(item.LastServicedAt, item.IntervalMonths)
 	return s.db.Create(item).Error
 }
 
@@ -737,36 +1009,94 @@ func (s *Store) UpdateMaintenance(item MaintenanceItem) error {
 	return s.updateByID(&MaintenanceItem{}, item.ID, item)
 }
 
+// MarkMaintenanceDone records that a maintenance item was serviced at the
+// given time: a service log entry with the note, and the item's last
+// serviced date, which moves its next due date along.
+func (s *Store) MarkMaintenanceDone(id uint, at time.Time, note string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var item MaintenanceItem
+		if err := tx.First(&item, id).Error; err != nil {
+			return err
+		}
+		entry := ServiceLogEntry{MaintenanceItemID: id, ServicedAt: at, Notes: note}
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+		return tx.Model(&MaintenanceItem{}).
+			Where(ColID+" = ?", id).
+			Update(ColLastServicedAt, at).Error
+	})
+}
+
 func (s *Store) ListAppliances(includeDeleted bool) ([]Appliance, error) {
 	var items []Appliance
-	db := s.db.Order(ColUpdatedAt + " desc, " + ColID + " desc")
+	db := s.db.Scopes(s.inHouse("appliances")).Order(ColUpdatedAt + " desc, " + ColID + " desc")
 	if includeDeleted {
 		db = db.Unscoped()
 	}
 	if err := db.Find(&items).Error; err != nil {
 		return nil, err
 	}
-	return items, nil
+	return items, s.fillAppliancesCustom(items)
 }
 
 func (s *Store) GetAppliance(id uint) (Appliance, error) {
 	var item Appliance
-	err := s.db.First(&item, id).Error
-	return item, err
+	if err := s.db.First(&item, id).Error; err != nil {
+		return Appliance{}, err
+	}
+	items := []Appliance{item}
+	err := s.fillAppliancesCustom(items)
+	return items[0], err
 }
 
+// CreateAppliance saves an appliance with its custom field values.
 func (s *Store) CreateAppliance(item *Appliance) error {
-	return s.db.Create(item).Error
+	return s.InTransaction(func(tx *Store) error {
+		if err := tx.db.Create(item).Error; err != nil {
+			return err
+		}
+		return tx.setCustomValues(DocumentEntityAppliance, item.ID, item.Custom)
+	})
 }
 
+// UpdateAppliance saves an appliance's fields and the custom field values
+// it gives.
 func (s *Store) UpdateAppliance(item Appliance) error {
-	return s.updateByID(&Appliance{}, item.ID, item)
+	return s.InTransaction(func(tx *Store) error {
+		if err := tx.updateByID(&Appliance{}, item.ID, item); err != nil {
+			return err
+		}
+		return tx.setCustomValues(DocumentEntityAppliance, item.ID, item.Custom)
+	})
 }
 
 // ---------------------------------------------------------------------------
 // ServiceLogEntry CRUD
 // ---------------------------------------------------------------------------
 
+// ListServiceLogs returns every service log entry, most recent first, with
+// its maintenance item and vendor.
+func (s *Store) ListServiceLogs(includeDeleted bool) ([]ServiceLogEntry, error) {
+	var entries []ServiceLogEntry
+	db := s.db.
+		Preload("MaintenanceItem", func(q *gorm.DB) *gorm.DB {
+			return q.Unscoped()
+		}).
+		Preload("Vendor", func(q *gorm.DB) *gorm.DB {
+			return q.Unscoped()
+		}).
+		Where(ColMaintenanceItemID+" IN (?)", s.houseMaintenanceIDs()).
+		Order(ColServicedAt + " desc, " + ColID + " desc")
+	if includeDeleted {
+		db = db.Unscoped()
+	}
+	if err := db.Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
 func (s *Store) ListServiceLog(
 	maintenanceItemID uint,
 	includeDeleted bool,
@@ -795,6 +1125,9 @@ func (s *Store) GetServiceLog(id uint) (ServiceLogEntry, error) {
 }
 
 func (s *Store) CreateServiceLog(entry *ServiceLogEntry, vendor Vendor) error {
+	if err := validateServiceLogFunding(*entry); err != nil {
+		return err
+	}
 	return s.db.Transaction(func(tx *gorm.DB) error {
 		if strings.TrimSpace(vendor.Name) != "" {
 			found, err := findOrCreateVendor(tx, vendor)
@@ -808,6 +1141,9 @@ func (s *Store) CreateServiceLog(entry *ServiceLogEntry, vendor Vendor) error {
 }
 
 func (s *Store) UpdateServiceLog(entry ServiceLogEntry, vendor Vendor) error {
+	if err := validateServiceLogFunding(entry); err != nil {
+		return err
+	}
 	return s.db.Transaction(func(tx *gorm.DB) error {
 		if strings.TrimSpace(vendor.Name) != "" {
 			found, err := findOrCreateVendor(tx, vendor)
@@ -860,7 +1196,7 @@ func (s *Store) CountMaintenanceByAppliance(applianceIDs []uint) (map[uint]int,
 
 func (s *Store) ListIncidents(includeDeleted bool) ([]Incident, error) {
 	var items []Incident
-	db := s.db.
+	db := s.db.Scopes(s.inHouse("incidents")).
 		Preload("Appliance", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
 		Preload("Vendor", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
 		Order(ColUpdatedAt + " desc, " + ColID + " desc")
@@ -922,13 +1258,15 @@ func (s *Store) CountIncidentsByVendor(vendorIDs []uint) (map[uint]int, error) {
 // avoid loading the potentially large Data BLOB.
 var listDocumentColumns = []string{
 	ColID, ColTitle, ColFileName, ColEntityKind, ColEntityID,
-	ColMIMEType, ColSizeBytes, ColChecksum, ColNotes,
-	ColCreatedAt, ColUpdatedAt, ColDeletedAt,
+	ColMIMEType, ColSizeBytes, sizeHumanExpr, ColChecksum,
+	ColOriginalChecksum, ColOriginalSize, ColImageHash, ColSharpness, ColNotes,
+	ColLastOpenedAt, ColHouseID, ColCreatedAt, ColUpdatedAt, ColVersion, ColDeletedAt, ColContentOf,
 }
 
 func (s *Store) ListDocuments(includeDeleted bool) ([]Document, error) {
 	var docs []Document
-	db := s.db.Select(listDocumentColumns).Order(ColUpdatedAt + " desc, " + ColID + " desc")
+	db := s.db.Select(listDocumentColumns).Scopes(s.inHouse("documents")).
+		Order(ColUpdatedAt + " desc, " + ColID + " desc")
 	if includeDeleted {
 		db = db.Unscoped()
 	}
@@ -988,14 +1326,40 @@ func (s *Store) CountDocumentsByEntity(
 	return counts, nil
 }
 
+// GetDocumentMetadata returns a document without its BLOB data; stream
+// that with OpenDocument.
+func (s *Store) GetDocumentMetadata(id uint) (Document, error) {
+	var doc Document
+	if err := s.db.Select(listDocumentColumns).First(&doc, id).Error; err != nil {
+		return Document{}, err
+	}
+	return doc, nil
+}
+
+// GetDocument returns a document with its content, wherever it's stored.
 func (s *Store) GetDocument(id uint) (Document, error) {
 	var doc Document
 	if err := s.db.First(&doc, id).Error; err != nil {
 		return Document{}, err
 	}
+	holder := doc.ID
+	if doc.ContentOf != nil {
+		holder = *doc.ContentOf
+	} else if doc.StoredFile == "" {
+		return doc, nil
+	}
+	data, err := s.contentOf(s.db, holder)
+	if err != nil {
+		return Document{}, fmt.Errorf("load document content: %w", err)
+	}
+	doc.Data = data
 	return doc, nil
 }
 
+// CreateDocument stores a document. When an earlier document has the same
+// file, the new one shares it rather than storing it again, and comes back
+// with ContentOf set and no Data. When files are kept as files, it comes
+// back with StoredFile set instead.
 func (s *Store) CreateDocument(doc *Document) error {
 	if doc.SizeBytes > s.maxDocumentSize {
 		return fmt.Errorf(
@@ -1003,6 +1367,12 @@ func (s *Store) CreateDocument(doc *Document) error {
 			formatBytes(doc.SizeBytes), formatBytes(s.maxDocumentSize),
 		)
 	}
+	if err := s.shareContent(doc); err != nil {
+		return fmt.Errorf("look for the same file: %w", err)
+	}
+	if err := s.storeContent(doc); err != nil {
+		return err
+	}
 	return s.db.Create(doc).Error
 }
 
@@ -1019,18 +1389,50 @@ func formatBytes(n int64) string {
 // EntityKind) is always preserved -- callers must use a dedicated method to
 // re-link a document. When Data is empty the existing BLOB and file metadata
 // columns are also preserved, so metadata-only edits don't erase the file.
+// A new file is the document's own, and documents that shared its old one
+// keep it.
 func (s *Store) UpdateDocument(doc Document) error {
-	omit := []string{ColID, ColCreatedAt, ColDeletedAt, ColEntityID, ColEntityKind}
-	if len(doc.Data) == 0 {
+	omit := []string{ColID, ColCreatedAt, ColDeletedAt, ColVersion, ColEntityID, ColEntityKind, ColLastOpenedAt, ColContentOf}
+	replace := len(doc.Data) > 0
+	doc.StoredFile = ""
+	if !replace {
 		omit = append(omit,
 			ColFileName, ColMIMEType, ColSizeBytes,
-			ColChecksum, ColData,
+			ColChecksum, ColOriginalChecksum, ColOriginalSize,
+			ColImageHash, ColSharpness, ColData, ColStoredFile,
 		)
+	} else if err := s.storeContent(&doc); err != nil {
+		return err
 	}
-	return s.db.Model(&Document{}).Where(ColID+" = ?", doc.ID).
-		Select("*").
-		Omit(omit...).
-		Updates(doc).Error
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if replace {
+			if err := handOffContent(tx, doc.ID); err != nil {
+				return err
+			}
+			if err := tx.Model(&Document{}).Where(ColID+" = ?", doc.ID).
+				UpdateColumn(ColContentOf, nil).Error; err != nil {
+				return err
+			}
+		}
+		return recordChanges(tx, &Document{}, doc.ID, func(tx *gorm.DB) error {
+			return versioned(tx, &Document{}, doc.ID, doc.Version, func(q *gorm.DB) *gorm.DB {
+				return q.Select("*").Omit(omit...).Updates(doc)
+			})
+		})
+	})
+}
+
+// documentOpenResolution limits how often a document's last open is
+// written back.
+const documentOpenResolution = time.Hour
+
+// TouchDocument records that the document's file was opened at at. It
+// leaves UpdatedAt alone, as opening a file doesn't change it.
+func (s *Store) TouchDocument(id uint, at time.Time) error {
+	return s.db.Model(&Document{}).
+		Where(ColID+" = ? AND ("+ColLastOpenedAt+" IS NULL OR "+ColLastOpenedAt+" < ?)",
+			id, at.Add(-documentOpenResolution)).
+		UpdateColumn(ColLastOpenedAt, at).Error
 }
 
 func (s *Store) DeleteDocument(id uint) error {
@@ -1079,6 +1481,34 @@ func (s *Store) validateDocumentParent(doc Document) error {
 		if err := s.requireParentAlive(&Incident{}, doc.EntityID); err != nil {
 			return parentRestoreError("incident", err)
 		}
+	case DocumentEntitySmartDevice:
+		if err := s.requireParentAlive(&SmartDevice{}, doc.EntityID); err != nil {
+			return parentRestoreError("device", err)
+		}
+	case DocumentEntityLandscape:
+		if err := s.requireParentAlive(&LandscapeAsset{}, doc.EntityID); err != nil {
+			return parentRestoreError("landscape asset", err)
+		}
+	case DocumentEntityPestTreatment:
+		if err := s.requireParentAlive(&PestTreatment{}, doc.EntityID); err != nil {
+			return parentRestoreError("pest treatment", err)
+		}
+	case DocumentEntityWaterTest:
+		if err := s.requireParentAlive(&WaterTest{}, doc.EntityID); err != nil {
+			return parentRestoreError("water test", err)
+		}
+	case DocumentEntityWalkthrough:
+		if err := s.requireParentAlive(&Walkthrough{}, doc.EntityID); err != nil {
+			return parentRestoreError("walkthrough", err)
+		}
+	case DocumentEntitySitterStay:
+		if err := s.requireParentAlive(&SitterStay{}, doc.EntityID); err != nil {
+			return parentRestoreError("house-sitter stay", err)
+		}
+	case DocumentEntityInventory:
+		if err := s.requireParentAlive(&InventoryItem{}, doc.EntityID); err != nil {
+			return parentRestoreError("inventory item", err)
+		}
 	}
 	return nil
 }
@@ -1143,6 +1573,13 @@ func (s *Store) DeleteVendor(id uint) error {
 	if ni > 0 {
 		return fmt.Errorf("vendor has %d active incident(s) -- delete them first", ni)
 	}
+	np, err := s.countDependents(&PestTreatment{}, ColVendorID, id)
+	if err != nil {
+		return err
+	}
+	if np > 0 {
+		return fmt.Errorf("vendor has %d active pest treatment(s) -- delete them first", np)
+	}
 	return s.softDelete(&Vendor{}, DeletionEntityVendor, id)
 }
 
@@ -1158,6 +1595,13 @@ func (s *Store) DeleteProject(id uint) error {
 	if n > 0 {
 		return fmt.Errorf("project has %d active quote(s) -- delete them first", n)
 	}
+	ne, err := s.countDependents(&MaterialEstimate{}, ColProjectID, id)
+	if err != nil {
+		return err
+	}
+	if ne > 0 {
+		return fmt.Errorf("project has %d active material estimate(s) -- delete them first", ne)
+	}
 	return s.softDelete(&Project{}, DeletionEntityProject, id)
 }
 
@@ -1194,6 +1638,27 @@ func (s *Store) DeleteAppliance(id uint) error {
 	if ni > 0 {
 		return fmt.Errorf("appliance has %d active incident(s) -- delete them first", ni)
 	}
+	nf, err := s.countDependents(&WaterFilterChange{}, ColApplianceID, id)
+	if err != nil {
+		return err
+	}
+	if nf > 0 {
+		return fmt.Errorf("appliance has %d active filter change(s) -- delete them first", nf)
+	}
+	na, err := s.countDependents(&AirFilterSpec{}, ColApplianceID, id)
+	if err != nil {
+		return err
+	}
+	if na > 0 {
+		return fmt.Errorf("appliance has %d active air filter spec(s) -- delete them first", na)
+	}
+	nc, err := s.countDependents(&WarrantyClaim{}, ColApplianceID, id)
+	if err != nil {
+		return err
+	}
+	if nc > 0 {
+		return fmt.Errorf("appliance has %d active warranty claim(s) -- delete them first", nc)
+	}
 	return s.softDelete(&Appliance{}, DeletionEntityAppliance, id)
 }
 
@@ -1202,6 +1667,11 @@ func (s *Store) RestoreProject(id uint) error {
 	if err := s.db.Unscoped().First(&project, id).Error; err != nil {
 		return err
 	}
+	if project.RoomID != nil {
+		if err := s.requireParentAlive(&Room{}, *project.RoomID); err != nil {
+			return parentRestoreError("room", err)
+		}
+	}
 	return s.restoreEntity(&Project{}, DeletionEntityProject, id)
 }
 
@@ -1229,10 +1699,24 @@ func (s *Store) RestoreMaintenance(id uint) error {
 			return parentRestoreError("appliance", err)
 		}
 	}
+	if item.LandscapeAssetID != nil {
+		if err := s.requireParentAlive(&LandscapeAsset{}, *item.LandscapeAssetID); err != nil {
+			return parentRestoreError("landscape asset", err)
+		}
+	}
 	return s.restoreEntity(&MaintenanceItem{}, DeletionEntityMaintenance, id)
 }
 
 func (s *Store) RestoreAppliance(id uint) error {
+	var item Appliance
+	if err := s.db.Unscoped().First(&item, id).Error; err != nil {
+		return err
+	}
+	if item.RoomID != nil {
+		if err := s.requireParentAlive(&Room{}, *item.RoomID); err != nil {
+			return parentRestoreError("room", err)
+		}
+	}
 	return s.restoreEntity(&Appliance{}, DeletionEntityAppliance, id)
 }
 
@@ -1281,36 +1765,59 @@ func (s *Store) countDependents(model any, fkColumn string, id uint) (int64, err
 
 func (s *Store) softDelete(model any, entity string, id uint) error {
 	return s.db.Transaction(func(tx *gorm.DB) error {
-		result := tx.Delete(model, id)
-		if result.Error != nil {
-			return result.Error
-		}
-		if result.RowsAffected == 0 {
-			return gorm.ErrRecordNotFound
-		}
-		record := DeletionRecord{
-			Entity:    entity,
-			TargetID:  id,
-			DeletedAt: time.Now(),
-		}
-		return tx.Create(&record).Error
+		return softDeleteIn(tx, model, entity, id)
 	})
 }
 
+// softDeleteIn is softDelete within a transaction the caller manages.
+func softDeleteIn(tx *gorm.DB, model any, entity string, id uint) error {
+	result := tx.Delete(model, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	record := DeletionRecord{
+		Entity:    entity,
+		TargetID:  id,
+		DeletedAt: time.Now(),
+	}
+	if err := tx.Create(&record).Error; err != nil {
+		return err
+	}
+	table := result.Statement.Schema.Table
+	if err := audit(tx, AuditEntry{Action: AuditDelete, Entity: table, TargetID: id}); err != nil {
+		return err
+	}
+	return logUndo(tx, UndoEntry{Action: UndoDelete, Entity: table, TargetID: id})
+}
+
 func (s *Store) restoreEntity(model any, entity string, id uint) error {
 	return s.db.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Unscoped().Model(model).
+		result := tx.Unscoped().Model(model).
 			Where(ColID+" = ?", id).
-			Update(ColDeletedAt, nil).Error; err != nil {
-			return err
+			Update(ColDeletedAt, nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
 		}
 		restoredAt := time.Now()
-		return tx.Model(&DeletionRecord{}).
+		if err := tx.Model(&DeletionRecord{}).
 			Where(
 				ColEntity+" = ? AND "+ColTargetID+" = ? AND "+ColRestoredAt+" IS NULL",
 				entity, id,
 			).
-			Update(ColRestoredAt, restoredAt).Error
+			Update(ColRestoredAt, restoredAt).Error; err != nil {
+			return err
+		}
+		table := result.Statement.Schema.Table
+		if err := audit(tx, AuditEntry{Action: AuditRestore, Entity: table, TargetID: id}); err != nil {
+			return err
+		}
+		return logUndo(tx, UndoEntry{Action: UndoRestore, Entity: table, TargetID: id})
 	})
 }
 
@@ -1400,10 +1907,55 @@ func (s *Store) countByFK(model any, fkColumn string, ids []uint) (map[uint]int,
 // updateByIDWith updates a record by ID, preserving id, created_at, and
 // deleted_at. Works with both Store.db and transaction handles.
 func updateByIDWith(db *gorm.DB, model any, id uint, values any) error {
-	return db.Model(model).Where(ColID+" = ?", id).
-		Select("*").
-		Omit(ColID, ColCreatedAt, ColDeletedAt).
-		Updates(values).Error
+	return db.Transaction(func(tx *gorm.DB) error {
+		return recordChanges(tx, model, id, func(tx *gorm.DB) error {
+			return versioned(tx, model, id, versionOf(values), func(q *gorm.DB) *gorm.DB {
+				return q.Select("*").
+					Omit(ColID, ColCreatedAt, ColDeletedAt, ColVersion, ColHouseID).
+					Updates(values)
+			})
+		})
+	})
+}
+
+// ErrVersionConflict means the row was changed by someone else after the
+// caller read it.
+var ErrVersionConflict = errors.New("this record was changed by someone else in the meantime")
+
+// versioned runs update on the row model/id and bumps its version, as an
+// optimistic lock: when expected is non-zero the update only applies if
+// the row is still at that version. Callers that don't know the version
+// pass 0 and always win. update must leave the version column alone.
+func versioned(tx *gorm.DB, model any, id uint, expected int, update func(q *gorm.DB) *gorm.DB) error {
+	q := tx.Model(model).Where(ColID+" = ?", id)
+	if expected > 0 {
+		q = q.Where(ColVersion+" = ?", expected)
+	}
+	result := update(q)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		if expected > 0 {
+			return ErrVersionConflict
+		}
+		return gorm.ErrRecordNotFound
+	}
+	return tx.Model(model).Where(ColID+" = ?", id).
+		UpdateColumn(ColVersion, gorm.Expr(ColVersion+" + 1")).Error
+}
+
+// versionOf returns the Version field of a model value, or 0.
+func versionOf(values any) int {
+	v := reflect.Indirect(reflect.ValueOf(values))
+	if v.Kind() != reflect.Struct {
+		return 0
+	}
+	f := v.FieldByName("Version")
+	if !f.IsValid() || !f.CanInt() {
+		return 0
+	}
+	return int(f.Int())
 }
 
 func (s *Store) updateByID(model any, id uint, values any) error {
@@ -4,9 +4,12 @@
 package data
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
@@ -1157,6 +1160,56 @@ func TestDocumentBLOBStorageAndExtract(t *testing.T) {
 	assert.Equal(t, cachePath, cachePath2)
 }
 
+func TestOpenDocumentStreamsContent(t *testing.T) {
+	store := newTestStore(t)
+	content := bytes.Repeat([]byte("scan"), 100_000)
+	require.NoError(t, store.CreateDocument(&Document{
+		Title: "Scan", FileName: "scan.pdf", SizeBytes: int64(len(content)), Data: content,
+	}))
+	require.NoError(t, store.CreateDocument(&Document{Title: "Placeholder"}))
+	docs, err := store.ListDocuments(false)
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	scan, placeholder := docs[1], docs[0]
+
+	r, err := store.OpenDocument(scan.ID)
+	require.NoError(t, err)
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, content, got)
+
+	_, err = store.OpenDocument(placeholder.ID)
+	require.ErrorIs(t, err, ErrNoContent)
+
+	require.NoError(t, store.DeleteDocument(scan.ID))
+	_, err = store.OpenDocument(scan.ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestCreateDocumentFromStreamsContent(t *testing.T) {
+	store := newTestStore(t)
+	content := bytes.Repeat([]byte("manual"), 50_000)
+	doc := Document{Title: "Manual", FileName: "manual.pdf", SizeBytes: int64(len(content))}
+	require.NoError(t, store.CreateDocumentFrom(&doc, bytes.NewReader(content)))
+	assert.Nil(t, doc.Data)
+
+	r, err := store.OpenDocument(doc.ID)
+	require.NoError(t, err)
+	defer r.Close()
+	_, err = r.Seek(-6, io.SeekEnd)
+	require.NoError(t, err)
+	tail, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("manual"), tail)
+
+	short := Document{Title: "Short", SizeBytes: int64(len(content))}
+	require.Error(t, store.CreateDocumentFrom(&short, bytes.NewReader(content[:10])))
+	docs, err := store.ListDocuments(true)
+	require.NoError(t, err)
+	assert.Len(t, docs, 1, "a document whose content couldn't be stored isn't kept")
+}
+
 func TestUpdateDocumentMetadataPreservesFile(t *testing.T) {
 	store := newTestStore(t)
 
@@ -1638,6 +1691,39 @@ func TestListServiceLogsByVendor(t *testing.T) {
 		"preloaded MaintenanceItem should be available")
 }
 
+func TestListServiceLogs(t *testing.T) {
+	store := newTestStore(t)
+	cats, _ := store.MaintenanceCategories()
+	furnace := MaintenanceItem{Name: "Furnace", CategoryID: cats[0].ID}
+	gutters := MaintenanceItem{Name: "Gutters", CategoryID: cats[0].ID}
+	require.NoError(t, store.CreateMaintenance(&furnace))
+	require.NoError(t, store.CreateMaintenance(&gutters))
+	older := ServiceLogEntry{MaintenanceItemID: furnace.ID, ServicedAt: time.Now().AddDate(0, -1, 0)}
+	newer := ServiceLogEntry{MaintenanceItemID: gutters.ID, ServicedAt: time.Now()}
+	require.NoError(t, store.CreateServiceLog(&older, Vendor{Name: "Acme"}))
+	require.NoError(t, store.CreateServiceLog(&newer, Vendor{}))
+	require.NoError(t, store.DeleteServiceLog(older.ID))
+
+	entries, err := store.ListServiceLogs(false)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "Gutters", entries[0].MaintenanceItem.Name)
+
+	entries, err = store.ListServiceLogs(true)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, newer.ID, entries[0].ID, "most recent first")
+	assert.Equal(t, "Acme", entries[1].Vendor.Name)
+}
+
+func TestMissingRecordsAreNotFound(t *testing.T) {
+	store := newTestStore(t)
+	assert.ErrorIs(t, store.UpdateVendor(Vendor{ID: 999, Name: "Ghost"}), gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, store.UpdateServiceLog(ServiceLogEntry{ID: 999}, Vendor{}), gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, store.RestoreVendor(999), gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, store.RestoreAppliance(999), gorm.ErrRecordNotFound)
+}
+
 func TestDocumentCRUD(t *testing.T) {
 	store := newTestStore(t)
 
@@ -2353,3 +2439,67 @@ func TestRestoreDocumentBlockedByDeletedIncident(t *testing.T) {
 	require.NoError(t, store.RestoreIncident(incID))
 	require.NoError(t, store.RestoreDocument(docID))
 }
+
+func TestUpdateRejectsStaleVersion(t *testing.T) {
+	store := newTestStore(t)
+	require.NoError(t, store.CreateVendor(&Vendor{Name: "Acme"}))
+	vendors, err := store.ListVendors(false)
+	require.NoError(t, err)
+	mine, theirs := vendors[0], vendors[0]
+	require.Equal(t, 1, mine.Version)
+
+	theirs.Phone = "555-0100"
+	require.NoError(t, store.UpdateVendor(theirs))
+	mine.Email = "acme@example.com"
+	require.ErrorIs(t, store.UpdateVendor(mine), ErrVersionConflict)
+
+	got, err := store.GetVendor(mine.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, got.Version)
+	assert.Empty(t, got.Email)
+
+	// Without a version the update always applies.
+	mine.Version = 0
+	require.NoError(t, store.UpdateVendor(mine))
+	got, err = store.GetVendor(mine.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, got.Version)
+	assert.Equal(t, "acme@example.com", got.Email)
+}
+
+func TestInTransactionRollsBack(t *testing.T) {
+	store := newTestStore(t)
+	require.NoError(t, store.CreateVendor(&Vendor{Name: "Acme"}))
+
+	errBoom := errors.New("boom")
+	err := store.InTransaction(func(tx *Store) error {
+		require.NoError(t, tx.CreateVendor(&Vendor{Name: "Bolt"}))
+		vendors, err := tx.ListVendors(false)
+		require.NoError(t, err)
+		require.Len(t, vendors, 2)
+		require.NoError(t, tx.DeleteVendor(vendors[0].ID))
+		return errBoom
+	})
+	require.ErrorIs(t, err, errBoom)
+
+	vendors, err := store.ListVendors(false)
+	require.NoError(t, err)
+	require.Len(t, vendors, 1)
+	assert.Equal(t, "Acme", vendors[0].Name)
+
+	require.NoError(t, store.InTransaction(func(tx *Store) error {
+		return tx.CreateVendor(&Vendor{Name: "Bolt"})
+	}))
+	vendors, err = store.ListVendors(false)
+	require.NoError(t, err)
+	assert.Len(t, vendors, 2)
+}
+
+func TestListDocumentsIncludesVersion(t *testing.T) {
+	store := newTestStore(t)
+	require.NoError(t, store.CreateDocument(&Document{Title: "Manual", Data: []byte("pdf")}))
+	docs, err := store.ListDocuments(false)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, 1, docs[0].Version)
+}
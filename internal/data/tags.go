@@ -0,0 +1,242 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Tag is a label that projects, appliances, maintenance items, vendors and
+// documents can carry, such as "kitchen" or "rental unit", to group records
+// across kinds. Names are unique regardless of case.
+type Tag struct {
+	ID   uint   `gorm:"primaryKey"`
+	Name string `gorm:"uniqueIndex"`
+	// Uses is how many records carry the tag. Only ListTags fills it in.
+	Uses      int64 `gorm:"column:uses;->;-:migration"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TagLink puts a tag on a record. EntityKind is one of TaggableKinds. The
+// Tags of a record's model aren't stored: they're filled in from its links
+// when records are listed.
+type TagLink struct {
+	ID         uint   `gorm:"primaryKey"`
+	TagID      uint   `gorm:"uniqueIndex:idx_tag_link,priority:1"`
+	Tag        Tag    `gorm:"constraint:OnDelete:CASCADE;"`
+	EntityKind string `gorm:"uniqueIndex:idx_tag_link,priority:2;index:idx_tag_link_entity,priority:1"`
+	EntityID   uint   `gorm:"uniqueIndex:idx_tag_link,priority:3;index:idx_tag_link_entity,priority:2"`
+}
+
+// ErrTagName is returned for a tag name that's blank or has a comma, which
+// separates tags where they're typed as a list.
+var ErrTagName = errors.New("a tag needs a name, without commas")
+
+// ErrNotTaggable is returned for a kind of record that can't carry tags.
+var ErrNotTaggable = errors.New("only projects, appliances, maintenance, vendors and documents can be tagged")
+
+// taggable maps each kind of record that can carry tags to its table.
+var taggable = map[string]string{
+	DocumentEntityProject:     "projects",
+	DocumentEntityAppliance:   "appliances",
+	DocumentEntityMaintenance: tableMaintenanceItems,
+	DocumentEntityVendor:      "vendors",
+	DeletionEntityDocument:    "documents",
+}
+
+// TaggableKinds lists the kinds of record that can carry tags.
+func TaggableKinds() []string {
+	kinds := make([]string, 0, len(taggable))
+	for kind := range taggable {
+		kinds = append(kinds, kind)
+	}
+	slices.Sort(kinds)
+	return kinds
+}
+
+func tagName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" || strings.Contains(name, ",") {
+		return "", ErrTagName
+	}
+	return name, nil
+}
+
+// ListTags returns every tag by name, with how many records carry it.
+func (s *Store) ListTags() ([]Tag, error) {
+	var tags []Tag
+	err := s.db.Model(&Tag{}).
+		Select("tags.*, (SELECT COUNT(*) FROM tag_links WHERE tag_links.tag_id = tags.id) AS uses").
+		Order("tags.name COLLATE NOCASE").
+		Find(&tags).Error
+	return tags, err
+}
+
+// CreateTag adds a tag, or returns the one already named the same.
+func (s *Store) CreateTag(name string) (Tag, error) {
+	name, err := tagName(name)
+	if err != nil {
+		return Tag{}, err
+	}
+	var tag Tag
+	err = s.db.Where("name = ? COLLATE NOCASE", name).
+		Attrs(Tag{Name: name}).FirstOrCreate(&tag).Error
+	return tag, err
+}
+
+// RenameTag renames a tag, everywhere it's used.
+func (s *Store) RenameTag(id uint, name string) (Tag, error) {
+	name, err := tagName(name)
+	if err != nil {
+		return Tag{}, err
+	}
+	var tag Tag
+	err = s.InTransaction(func(tx *Store) error {
+		if err := tx.db.First(&tag, id).Error; err != nil {
+			return err
+		}
+		old := tag.Name
+		var taken int64
+		if err := tx.db.Model(&Tag{}).
+			Where("name = ? COLLATE NOCASE AND "+ColID+" <> ?", name, id).
+			Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return fmt.Errorf("%w: %q is already a tag", ErrTagName, name)
+		}
+		if err := tx.db.Model(&tag).Update(ColName, name).Error; err != nil {
+			return err
+		}
+		changes, err := json.Marshal(map[string][2]string{ColName: {old, name}})
+		if err != nil {
+			return err
+		}
+		return audit(tx.db, AuditEntry{Action: AuditUpdate, Entity: "tags", TargetID: id, Changes: string(changes)})
+	})
+	if err != nil {
+		return Tag{}, err
+	}
+	return tag, nil
+}
+
+// DeleteTag removes a tag and takes it off every record that carried it.
+func (s *Store) DeleteTag(id uint) error {
+	return s.InTransaction(func(tx *Store) error {
+		var tag Tag
+		if err := tx.db.First(&tag, id).Error; err != nil {
+			return err
+		}
+		if err := tx.db.Where("tag_id = ?", id).Delete(&TagLink{}).Error; err != nil {
+			return err
+		}
+		if err := tx.db.Delete(&tag).Error; err != nil {
+			return err
+		}
+		return audit(tx.db, AuditEntry{Action: AuditDelete, Entity: "tags", TargetID: id})
+	})
+}
+
+// SetTags replaces a record's tags with names, creating tags that don't
+// exist yet, and returns the tags it now carries.
+func (s *Store) SetTags(kind string, id uint, names []string) ([]string, error) {
+	table, ok := taggable[kind]
+	if !ok {
+		return nil, ErrNotTaggable
+	}
+	err := s.InTransaction(func(tx *Store) error {
+		var found int64
+		if err := tx.db.Table(table).Where(ColID+" = ?", id).Count(&found).Error; err != nil {
+			return err
+		}
+		if found == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		want := map[uint]bool{}
+		for _, name := range names {
+			tag, err := tx.CreateTag(name)
+			if err != nil {
+				return err
+			}
+			want[tag.ID] = true
+		}
+		var links []TagLink
+		if err := tx.db.Where(ColEntityKind+" = ? AND "+ColEntityID+" = ?", kind, id).
+			Find(&links).Error; err != nil {
+			return err
+		}
+		for _, link := range links {
+			if want[link.TagID] {
+				delete(want, link.TagID)
+				continue
+			}
+			if err := tx.db.Delete(&link).Error; err != nil {
+				return err
+			}
+			if err := audit(tx.db, AuditEntry{Action: AuditDelete, Entity: "tag_links", TargetID: link.ID}); err != nil {
+				return err
+			}
+		}
+		for tagID := range want {
+			if err := tx.db.Create(&TagLink{TagID: tagID, EntityKind: kind, EntityID: id}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	tags, err := s.TagsOf(kind, []uint{id})
+	return tags[id], err
+}
+
+// TagsOf returns the names of the tags each of the records of a kind
+// carries, in order, keyed by record. Records without tags are left out.
+func (s *Store) TagsOf(kind string, ids []uint) (map[uint][]string, error) {
+	if _, ok := taggable[kind]; !ok {
+		return nil, ErrNotTaggable
+	}
+	var rows []struct {
+		EntityID uint
+		Name     string
+	}
+	err := s.db.Model(&TagLink{}).
+		Select("tag_links.entity_id, tags.name").
+		Joins("JOIN tags ON tags.id = tag_links.tag_id").
+		Where("tag_links.entity_kind = ? AND tag_links.entity_id IN ?", kind, ids).
+		Order("tags.name COLLATE NOCASE").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[uint][]string)
+	for _, row := range rows {
+		tags[row.EntityID] = append(tags[row.EntityID], row.Name)
+	}
+	return tags, nil
+}
+
+// TaggedIDs returns the IDs of the records of a kind that carry the tag
+// named name, regardless of case.
+func (s *Store) TaggedIDs(kind, name string) ([]uint, error) {
+	if _, ok := taggable[kind]; !ok {
+		return nil, ErrNotTaggable
+	}
+	var ids []uint
+	err := s.db.Model(&TagLink{}).
+		Joins("JOIN tags ON tags.id = tag_links.tag_id").
+		Where("tag_links.entity_kind = ? AND tags.name = ? COLLATE NOCASE", kind, strings.TrimSpace(name)).
+		Order("tag_links.entity_id").
+		Pluck("tag_links.entity_id", &ids).Error
+	return ids, err
+}
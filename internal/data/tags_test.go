@@ -0,0 +1,88 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestTags(t *testing.T) {
+	store := newTestStore(t)
+	fridge := Appliance{Name: "Fridge"}
+	require.NoError(t, store.CreateAppliance(&fridge))
+	oven := Appliance{Name: "Oven"}
+	require.NoError(t, store.CreateAppliance(&oven))
+	acme := Vendor{Name: "Acme"}
+	require.NoError(t, store.CreateVendor(&acme))
+
+	tags, err := store.SetTags(DocumentEntityAppliance, fridge.ID, []string{"Kitchen", " rental  unit ", "kitchen"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Kitchen", "rental unit"}, tags)
+	_, err = store.SetTags(DocumentEntityAppliance, oven.ID, []string{"KITCHEN"})
+	require.NoError(t, err)
+	_, err = store.SetTags(DocumentEntityVendor, acme.ID, []string{"rental unit"})
+	require.NoError(t, err)
+
+	of, err := store.TagsOf(DocumentEntityAppliance, []uint{fridge.ID, oven.ID})
+	require.NoError(t, err)
+	assert.Equal(t, map[uint][]string{fridge.ID: {"Kitchen", "rental unit"}, oven.ID: {"Kitchen"}}, of)
+	ids, err := store.TaggedIDs(DocumentEntityAppliance, "kitchen")
+	require.NoError(t, err)
+	assert.Equal(t, []uint{fridge.ID, oven.ID}, ids)
+	ids, err = store.TaggedIDs(DocumentEntityAppliance, "rental unit")
+	require.NoError(t, err)
+	assert.Equal(t, []uint{fridge.ID}, ids)
+
+	all, err := store.ListTags()
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "Kitchen", all[0].Name)
+	assert.Equal(t, int64(2), all[0].Uses)
+	assert.Equal(t, int64(2), all[1].Uses)
+
+	// Setting tags replaces them.
+	tags, err = store.SetTags(DocumentEntityAppliance, fridge.ID, []string{"garage"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"garage"}, tags)
+	ids, err = store.TaggedIDs(DocumentEntityAppliance, "rental unit")
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+
+	renamed, err := store.RenameTag(all[0].ID, "Cooking")
+	require.NoError(t, err)
+	assert.Equal(t, "Cooking", renamed.Name)
+	_, err = store.RenameTag(all[0].ID, "GARAGE")
+	require.ErrorIs(t, err, ErrTagName)
+	of, err = store.TagsOf(DocumentEntityAppliance, []uint{oven.ID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Cooking"}, of[oven.ID])
+
+	require.NoError(t, store.DeleteTag(renamed.ID))
+	of, err = store.TagsOf(DocumentEntityAppliance, []uint{oven.ID})
+	require.NoError(t, err)
+	assert.Empty(t, of)
+}
+
+func TestSetTagsRejects(t *testing.T) {
+	store := newTestStore(t)
+	acme := Vendor{Name: "Acme"}
+	require.NoError(t, store.CreateVendor(&acme))
+
+	_, err := store.SetTags(DocumentEntityVendor, acme.ID, []string{"a, b"})
+	require.ErrorIs(t, err, ErrTagName)
+	_, err = store.SetTags(DocumentEntityVendor, acme.ID, []string{" "})
+	require.ErrorIs(t, err, ErrTagName)
+	_, err = store.SetTags(DocumentEntityVendor, acme.ID+1, []string{"x"})
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = store.SetTags(DocumentEntityQuote, 1, []string{"x"})
+	require.ErrorIs(t, err, ErrNotTaggable)
+
+	tags, err := store.ListTags()
+	require.NoError(t, err)
+	assert.Empty(t, tags, "nothing is created when tagging fails")
+}
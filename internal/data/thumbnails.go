@@ -0,0 +1,74 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cpcloud/webcasa/internal/photo"
+	"gorm.io/gorm/clause"
+)
+
+// thumbnailsTable caches the thumbnails of photo documents. Like the search
+// index it is derived data: it isn't exported or dumped, and a thumbnail
+// is made again when its photo's file changes.
+const thumbnailsTable = searchIndexTable + "_thumbnails"
+
+// Thumbnail is the cached thumbnail of a photo document.
+type Thumbnail struct {
+	DocumentID uint `gorm:"primaryKey;autoIncrement:false"`
+	// Checksum is the SHA-256 of the file the thumbnail was made from, so
+	// a replaced file gets a new one.
+	Checksum string
+	// Data is a JPEG.
+	Data []byte
+}
+
+// TableName keeps the cache with the search index it sits beside.
+func (Thumbnail) TableName() string { return thumbnailsTable }
+
+// ErrNoThumbnail is returned for a document that isn't a JPEG or PNG photo.
+var ErrNoThumbnail = errors.New("only JPEG and PNG photos have thumbnails")
+
+// DocumentThumbnail returns the thumbnail of a photo document as a JPEG,
+// making and caching it when it hasn't been made from the document's
+// current file.
+func (s *Store) DocumentThumbnail(id uint) ([]byte, error) {
+	doc, err := s.GetDocumentMetadata(id)
+	if err != nil {
+		return nil, err
+	}
+	if doc.MIMEType != "image/jpeg" && doc.MIMEType != "image/png" {
+		return nil, ErrNoThumbnail
+	}
+	var cached Thumbnail
+	found := s.db.Where("document_id = ? AND checksum = ?", id, doc.ChecksumSHA256).Limit(1).Find(&cached)
+	if found.Error != nil {
+		return nil, found.Error
+	}
+	if found.RowsAffected > 0 {
+		return cached.Data, nil
+	}
+	holder, err := s.contentID(id)
+	if err != nil {
+		return nil, err
+	}
+	content, err := s.contentOf(s.db, holder)
+	if err != nil {
+		return nil, err
+	}
+	thumb, err := photo.Thumbnail(content, photo.ThumbnailSize)
+	if errors.Is(err, photo.ErrUnsupported) {
+		return nil, ErrNoThumbnail
+	} else if err != nil {
+		return nil, fmt.Errorf("make thumbnail: %w", err)
+	}
+	err = s.db.Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(&Thumbnail{DocumentID: id, Checksum: doc.ChecksumSHA256, Data: thumb}).Error
+	if err != nil {
+		return nil, fmt.Errorf("save thumbnail: %w", err)
+	}
+	return thumb, nil
+}
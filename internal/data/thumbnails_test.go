@@ -0,0 +1,52 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentThumbnail(t *testing.T) {
+	store := newTestStore(t)
+	photoOf := func(w, h int) []byte {
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))))
+		return buf.Bytes()
+	}
+	doc := Document{Title: "Before", FileName: "before.png", MIMEType: "image/png", Data: photoOf(640, 480), ChecksumSHA256: "v1"}
+	require.NoError(t, store.CreateDocument(&doc))
+
+	thumb, err := store.DocumentThumbnail(doc.ID)
+	require.NoError(t, err)
+	img, format, err := image.Decode(bytes.NewReader(thumb))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, image.Rect(0, 0, 320, 240), img.Bounds())
+
+	var cached int64
+	require.NoError(t, store.db.Model(&Thumbnail{}).Count(&cached).Error)
+	assert.Equal(t, int64(1), cached)
+
+	// A replaced file gets a new thumbnail.
+	require.NoError(t, store.db.Model(&Document{}).Where(ColID+" = ?", doc.ID).
+		Updates(map[string]any{ColData: photoOf(100, 200), "sha256": "v2"}).Error)
+	thumb, err = store.DocumentThumbnail(doc.ID)
+	require.NoError(t, err)
+	img, _, err = image.Decode(bytes.NewReader(thumb))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 100, 200), img.Bounds())
+	require.NoError(t, store.db.Model(&Thumbnail{}).Count(&cached).Error)
+	assert.Equal(t, int64(1), cached)
+
+	manual := Document{Title: "Manual", FileName: "manual.pdf", MIMEType: "application/pdf", Data: []byte("%PDF-1.7")}
+	require.NoError(t, store.CreateDocument(&manual))
+	_, err = store.DocumentThumbnail(manual.ID)
+	assert.ErrorIs(t, err, ErrNoThumbnail)
+}
@@ -0,0 +1,137 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// API token scopes.
+const (
+	// ScopeRead allows only reads.
+	ScopeRead = "read"
+	// ScopeUpload allows only document uploads.
+	ScopeUpload = "upload"
+	// ScopeFull allows everything but the admin panel.
+	ScopeFull = "full"
+	// ScopeDisplay allows only the kiosk page, for a wall-mounted screen.
+	ScopeDisplay = "display"
+)
+
+// TokenScopes lists the valid API token scopes.
+func TokenScopes() []string { return []string{ScopeRead, ScopeUpload, ScopeFull, ScopeDisplay} }
+
+// TokenPrefix starts every API token, so they are easy to spot in scripts
+// and tell apart from other credentials.
+const TokenPrefix = "wct_"
+
+// DefaultTokenRateLimit is the requests per minute allowed to a token that
+// doesn't set its own limit.
+const DefaultTokenRateLimit = 60
+
+// tokenUseResolution limits how often a token's last use is written back.
+const tokenUseResolution = time.Minute
+
+// APIToken is a long-lived credential for scripts and automations. Only a
+// hash of the secret is stored.
+type APIToken struct {
+	ID   uint `gorm:"primaryKey"`
+	Name string
+	// Hint is the start of the secret, shown to tell tokens apart.
+	Hint      string
+	TokenHash string `gorm:"uniqueIndex" json:"-"`
+	Scope     string
+	// RateLimit is in requests per minute.
+	RateLimit  int
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+	RevokedAt  *time.Time
+}
+
+// Revoked reports whether the token has been revoked.
+func (t APIToken) Revoked() bool { return t.RevokedAt != nil }
+
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIToken issues a token and returns it with its secret, which is
+// not stored and can't be shown again. A rateLimit of zero means
+// DefaultTokenRateLimit.
+func (s *Store) CreateAPIToken(name, scope string, rateLimit int) (APIToken, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return APIToken{}, "", fmt.Errorf("token name is required")
+	}
+	if !slices.Contains(TokenScopes(), scope) {
+		return APIToken{}, "", fmt.Errorf(
+			"unknown token scope %q (want one of %s)", scope, strings.Join(TokenScopes(), ", "))
+	}
+	if rateLimit < 0 {
+		return APIToken{}, "", fmt.Errorf("token rate limit must not be negative")
+	}
+	if rateLimit == 0 {
+		rateLimit = DefaultTokenRateLimit
+	}
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return APIToken{}, "", fmt.Errorf("generate token: %w", err)
+	}
+	secret := TokenPrefix + hex.EncodeToString(raw)
+	tok := APIToken{
+		Name:      name,
+		Hint:      secret[:len(TokenPrefix)+6],
+		TokenHash: hashToken(secret),
+		Scope:     scope,
+		RateLimit: rateLimit,
+	}
+	if err := s.db.Create(&tok).Error; err != nil {
+		return APIToken{}, "", err
+	}
+	return tok, secret, nil
+}
+
+// ListAPITokens returns all tokens, revoked ones included, newest first.
+func (s *Store) ListAPITokens() ([]APIToken, error) {
+	var toks []APIToken
+	err := s.db.Order(ColCreatedAt + " desc, " + ColID + " desc").Find(&toks).Error
+	return toks, err
+}
+
+// RevokeAPIToken stops a token from working. Revoking twice is harmless.
+func (s *Store) RevokeAPIToken(id uint) error {
+	var tok APIToken
+	if err := s.db.First(&tok, id).Error; err != nil {
+		return err
+	}
+	if tok.Revoked() {
+		return nil
+	}
+	return s.db.Model(&tok).Update(ColRevokedAt, time.Now()).Error
+}
+
+// APITokenBySecret finds the live token with the given secret. It returns
+// gorm.ErrRecordNotFound for unknown and revoked tokens.
+func (s *Store) APITokenBySecret(secret string) (APIToken, error) {
+	var tok APIToken
+	err := s.db.Where(ColTokenHash+" = ? AND "+ColRevokedAt+" IS NULL", hashToken(secret)).
+		First(&tok).Error
+	return tok, err
+}
+
+// TouchAPIToken records that a token was used at the given time. Writes are
+// skipped while the recorded use is under a minute old.
+func (s *Store) TouchAPIToken(id uint, at time.Time) error {
+	return s.db.Model(&APIToken{}).
+		Where(ColID+" = ? AND ("+ColLastUsedAt+" IS NULL OR "+ColLastUsedAt+" < ?)",
+			id, at.Add(-tokenUseResolution)).
+		Update(ColLastUsedAt, at).Error
+}
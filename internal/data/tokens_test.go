@@ -0,0 +1,78 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestAPITokenLifecycle(t *testing.T) {
+	store := newTestStore(t)
+	tok, secret, err := store.CreateAPIToken("  scanner  ", ScopeUpload, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "scanner", tok.Name)
+	assert.Equal(t, DefaultTokenRateLimit, tok.RateLimit)
+	assert.True(t, strings.HasPrefix(secret, TokenPrefix))
+	assert.True(t, strings.HasPrefix(secret, tok.Hint))
+	assert.NotContains(t, tok.TokenHash, secret)
+
+	found, err := store.APITokenBySecret(secret)
+	require.NoError(t, err)
+	assert.Equal(t, tok.ID, found.ID)
+	assert.Equal(t, ScopeUpload, found.Scope)
+
+	_, err = store.APITokenBySecret(secret + "x")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	require.NoError(t, store.RevokeAPIToken(tok.ID))
+	require.NoError(t, store.RevokeAPIToken(tok.ID))
+	_, err = store.APITokenBySecret(secret)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	toks, err := store.ListAPITokens()
+	require.NoError(t, err)
+	require.Len(t, toks, 1)
+	assert.True(t, toks[0].Revoked())
+
+	assert.ErrorIs(t, store.RevokeAPIToken(999), gorm.ErrRecordNotFound)
+}
+
+func TestCreateAPITokenValidates(t *testing.T) {
+	store := newTestStore(t)
+	_, _, err := store.CreateAPIToken("", ScopeRead, 0)
+	assert.ErrorContains(t, err, "name")
+	_, _, err = store.CreateAPIToken("x", "admin", 0)
+	assert.ErrorContains(t, err, "unknown token scope")
+	_, _, err = store.CreateAPIToken("mudroom", ScopeDisplay, 0)
+	assert.NoError(t, err)
+	_, _, err = store.CreateAPIToken("x", ScopeRead, -1)
+	assert.Error(t, err)
+}
+
+func TestTouchAPIToken(t *testing.T) {
+	store := newTestStore(t)
+	tok, _, err := store.CreateAPIToken("ci", ScopeRead, 10)
+	require.NoError(t, err)
+
+	first := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	require.NoError(t, store.TouchAPIToken(tok.ID, first))
+	// Within a minute the recorded use stays put.
+	require.NoError(t, store.TouchAPIToken(tok.ID, first.Add(30*time.Second)))
+	toks, err := store.ListAPITokens()
+	require.NoError(t, err)
+	require.NotNil(t, toks[0].LastUsedAt)
+	assert.True(t, first.Equal(*toks[0].LastUsedAt))
+
+	later := first.Add(2 * time.Minute)
+	require.NoError(t, store.TouchAPIToken(tok.ID, later))
+	toks, err = store.ListAPITokens()
+	require.NoError(t, err)
+	assert.True(t, later.Equal(*toks[0].LastUsedAt))
+}
@@ -0,0 +1,298 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Undo log actions.
+const (
+	UndoEdit    = "edit"
+	UndoDelete  = "delete"
+	UndoRestore = "restore"
+)
+
+// DefaultUndoDepth is how many steps the undo log keeps unless told
+// otherwise.
+const DefaultUndoDepth = 200
+
+// tableUndoLog is UndoEntry's table.
+const tableUndoLog = "undo_log"
+
+// UndoEntry is one step of the undo log: an edit, deletion or restore of a
+// row, which Undo takes back and Redo puts back. The log lives in the
+// database, so it survives restarts.
+type UndoEntry struct {
+	ID       uint      `gorm:"primaryKey"`
+	At       time.Time `gorm:"index"`
+	Action   string
+	Entity   string
+	TargetID uint
+	// Fields names the fields an edit changed, comma-separated.
+	Fields string
+	// Changes holds the IDs of an edit's FieldChanges, as JSON.
+	Changes  string
+	UndoneAt *time.Time `gorm:"index"`
+}
+
+// TableName keeps the log's table name short.
+func (UndoEntry) TableName() string { return tableUndoLog }
+
+// ErrNothingToUndo and ErrNothingToRedo mean the log has no step to take
+// back or put back.
+var (
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrNothingToRedo = errors.New("nothing to redo")
+)
+
+// undoingKey marks the context of writes made by Undo and Redo, which
+// mustn't add steps of their own.
+type undoingKey struct{}
+
+// logUndo adds a step to the undo log. A new step drops the undone ones,
+// which can't be redone on top of it.
+func logUndo(tx *gorm.DB, entry UndoEntry) error {
+	if ctx := tx.Statement.Context; ctx != nil && ctx.Value(undoingKey{}) != nil {
+		return nil
+	}
+	if err := tx.Where(ColUndoneAt + " IS NOT NULL").Delete(&UndoEntry{}).Error; err != nil {
+		return fmt.Errorf("drop undone steps: %w", err)
+	}
+	entry.At = time.Now()
+	if err := tx.Create(&entry).Error; err != nil {
+		return fmt.Errorf("log undo step: %w", err)
+	}
+	return nil
+}
+
+// logEdit logs the field changes of one edit as a step.
+func logEdit(tx *gorm.DB, changes []FieldChange) error {
+	ids := make([]uint, len(changes))
+	fields := make([]string, len(changes))
+	for i, c := range changes {
+		ids[i], fields[i] = c.ID, c.Field
+	}
+	raw, err := json.Marshal(ids)
+	if err != nil {
+		return err
+	}
+	return logUndo(tx, UndoEntry{
+		Action: UndoEdit, Entity: changes[0].Entity, TargetID: changes[0].TargetID,
+		Fields: strings.Join(fields, ","), Changes: string(raw),
+	})
+}
+
+// registerUndoTrim trims the undo log to depth steps whenever one is
+// added.
+func registerUndoTrim(db *gorm.DB, depth *atomic.Int64) error {
+	return db.Callback().Create().After("gorm:create").Register("webcasa:undo_depth", func(tx *gorm.DB) {
+		if tx.Error != nil || tx.Statement.Schema == nil || tx.Statement.Schema.Table != tableUndoLog {
+			return
+		}
+		if err := tx.Session(&gorm.Session{NewDB: true}).Exec(
+			"DELETE FROM "+tableUndoLog+" WHERE "+ColID+" NOT IN (SELECT "+ColID+" FROM "+tableUndoLog+
+				" ORDER BY "+ColID+" DESC LIMIT ?)", depth.Load(),
+		).Error; err != nil {
+			_ = tx.AddError(fmt.Errorf("trim undo log: %w", err))
+		}
+	})
+}
+
+// SetUndoDepth sets how many steps the undo log keeps, dropping the oldest
+// beyond that.
+func (s *Store) SetUndoDepth(n int) error {
+	if n < 1 {
+		return fmt.Errorf("undo depth must be at least 1, got %d", n)
+	}
+	s.undoDepth.Store(int64(n))
+	return s.db.Exec(
+		"DELETE FROM "+tableUndoLog+" WHERE "+ColID+" NOT IN (SELECT "+ColID+" FROM "+tableUndoLog+
+			" ORDER BY "+ColID+" DESC LIMIT ?)", n,
+	).Error
+}
+
+// UndoLog returns the newest limit steps of the undo log, newest first,
+// undone ones included.
+func (s *Store) UndoLog(limit int) ([]UndoEntry, error) {
+	var entries []UndoEntry
+	err := s.db.Order(ColID + " desc").Limit(limit).Find(&entries).Error
+	return entries, err
+}
+
+// Undo takes back the newest step not yet undone, and returns it.
+func (s *Store) Undo() (UndoEntry, error) {
+	var entry UndoEntry
+	err := s.db.Where(ColUndoneAt + " IS NULL").Order(ColID + " desc").First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entry, ErrNothingToUndo
+	} else if err != nil {
+		return entry, err
+	}
+	if err := s.undoing().replay(entry, true); err != nil {
+		return entry, fmt.Errorf("undo %s of %s %d: %w", entry.Action, entry.Entity, entry.TargetID, err)
+	}
+	now := time.Now()
+	entry.UndoneAt = &now
+	return entry, s.db.Model(&entry).Update(ColUndoneAt, now).Error
+}
+
+// Redo puts back the step undone last, and returns it.
+func (s *Store) Redo() (UndoEntry, error) {
+	// Undone steps are always the newest, so the one undone last is the
+	// oldest of them.
+	var entry UndoEntry
+	err := s.db.Where(ColUndoneAt + " IS NOT NULL").Order(ColID).First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entry, ErrNothingToRedo
+	} else if err != nil {
+		return entry, err
+	}
+	if err := s.undoing().replay(entry, false); err != nil {
+		return entry, fmt.Errorf("redo %s of %s %d: %w", entry.Action, entry.Entity, entry.TargetID, err)
+	}
+	entry.UndoneAt = nil
+	return entry, s.db.Model(&entry).Update(ColUndoneAt, nil).Error
+}
+
+// undoing is the store with its writes kept out of the undo log.
+func (s *Store) undoing() *Store {
+	u := *s
+	u.db = s.db.WithContext(context.WithValue(s.db.Statement.Context, undoingKey{}, true))
+	return &u
+}
+
+// replay takes back a step, or puts it back when undo is false.
+func (s *Store) replay(entry UndoEntry, undo bool) error {
+	if entry.Action == UndoEdit {
+		return s.replayEdit(entry, undo)
+	}
+	model, err := s.modelOf(entry.Entity)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(s.deleters(), func(d deleter) bool {
+		return reflect.TypeOf(d.model) == reflect.TypeOf(model)
+	})
+	if i < 0 {
+		return fmt.Errorf("%s can't be deleted or restored", entry.Entity)
+	}
+	d := s.deleters()[i]
+	if (entry.Action == UndoDelete) == undo {
+		return d.restore(entry.TargetID)
+	}
+	return d.delete(entry.TargetID)
+}
+
+// replayEdit sets the fields an edit changed back to their old values,
+// or forward to their new ones.
+func (s *Store) replayEdit(entry UndoEntry, undo bool) error {
+	var ids []uint
+	if err := json.Unmarshal([]byte(entry.Changes), &ids); err != nil {
+		return fmt.Errorf("decode changes: %w", err)
+	}
+	var changes []FieldChange
+	if err := s.db.Where(ColID+" IN ?", ids).Order(ColID).Find(&changes).Error; err != nil {
+		return err
+	}
+	if len(changes) != len(ids) {
+		return fmt.Errorf("its field history is gone")
+	}
+	model, err := s.historyModel(entry.Entity)
+	if err != nil {
+		return err
+	}
+	if undo {
+		slices.Reverse(changes)
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for _, c := range changes {
+			value := c.NewValue
+			if undo {
+				value = c.OldValue
+			}
+			if err := setField(tx, model, c.TargetID, c.Field, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// setField sets one tracked field of a row to a JSON value from its
+// history, recording the change.
+func setField(tx *gorm.DB, model any, id uint, field, raw string) error {
+	sch, fields, err := trackedFields(tx, model)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(fields, func(f *schema.Field) bool { return f.DBName == field })
+	if i < 0 {
+		return fmt.Errorf("%s.%s can't be reverted", sch.Table, field)
+	}
+	value := reflect.New(fields[i].FieldType)
+	if err := json.Unmarshal([]byte(raw), value.Interface()); err != nil {
+		return fmt.Errorf("decode %s.%s: %w", sch.Table, field, err)
+	}
+	var n int64
+	if err := tx.Model(model).Where(ColID+" = ?", id).Count(&n).Error; err != nil {
+		return err
+	}
+	if n == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return recordChanges(tx, model, id, func(tx *gorm.DB) error {
+		return tx.Model(model).Where(ColID+" = ?", id).Update(field, value.Elem().Interface()).Error
+	})
+}
+
+// deleter deletes and restores the rows of a model the way the API does,
+// checking what depends on them and what they depend on.
+type deleter struct {
+	model           any
+	delete, restore func(id uint) error
+}
+
+func (s *Store) deleters() []deleter {
+	return []deleter{
+		{&Project{}, s.DeleteProject, s.RestoreProject},
+		{&Quote{}, s.DeleteQuote, s.RestoreQuote},
+		{&MaintenanceItem{}, s.DeleteMaintenance, s.RestoreMaintenance},
+		{&Appliance{}, s.DeleteAppliance, s.RestoreAppliance},
+		{&ServiceLogEntry{}, s.DeleteServiceLog, s.RestoreServiceLog},
+		{&Vendor{}, s.DeleteVendor, s.RestoreVendor},
+		{&Document{}, s.DeleteDocument, s.RestoreDocument},
+		{&Incident{}, s.DeleteIncident, s.RestoreIncident},
+		{&SmartDevice{}, s.DeleteSmartDevice, s.RestoreSmartDevice},
+		{&LandscapeAsset{}, s.DeleteLandscapeAsset, s.RestoreLandscapeAsset},
+		{&PestTreatment{}, s.DeletePestTreatment, s.RestorePestTreatment},
+		{&WaterTest{}, s.DeleteWaterTest, s.RestoreWaterTest},
+		{&WaterFilterChange{}, s.DeleteWaterFilterChange, s.RestoreWaterFilterChange},
+		{&AirFilterSpec{}, s.DeleteAirFilterSpec, s.RestoreAirFilterSpec},
+		{&Room{}, s.DeleteRoom, s.RestoreRoom},
+		{&MaterialEstimate{}, s.DeleteMaterialEstimate, s.RestoreMaterialEstimate},
+		{&RoomFinish{}, s.DeleteRoomFinish, s.RestoreRoomFinish},
+		{&FloorPlan{}, s.DeleteFloorPlan, s.RestoreFloorPlan},
+		{&Walkthrough{}, s.DeleteWalkthrough, s.RestoreWalkthrough},
+		{&HouseEvent{}, s.DeleteHouseEvent, s.RestoreHouseEvent},
+		{&Expense{}, s.DeleteExpense, s.RestoreExpense},
+		{&WarrantyClaim{}, s.DeleteWarrantyClaim, s.RestoreWarrantyClaim},
+		{&Warranty{}, s.DeleteWarranty, s.RestoreWarranty},
+		{&InventoryItem{}, s.DeleteInventoryItem, s.RestoreInventoryItem},
+		{&Rebate{}, s.DeleteRebate, s.RestoreRebate},
+		{&Contact{}, s.DeleteContact, s.RestoreContact},
+		{&UtilityBill{}, s.DeleteUtilityBill, s.RestoreUtilityBill},
+	}
+}
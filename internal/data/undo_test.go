@@ -0,0 +1,131 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestUndoRedoEdit(t *testing.T) {
+	store := newTestStore(t)
+	_, err := store.Undo()
+	require.ErrorIs(t, err, ErrNothingToUndo)
+
+	kettle := Appliance{Name: "Kettle", Brand: "Fellow"}
+	require.NoError(t, store.CreateAppliance(&kettle))
+	kettle.Name, kettle.Brand = "Electric kettle", "Breville"
+	require.NoError(t, store.UpdateAppliance(kettle))
+
+	entry, err := store.Undo()
+	require.NoError(t, err)
+	assert.Equal(t, UndoEdit, entry.Action)
+	assert.Equal(t, "appliances", entry.Entity)
+	assert.Equal(t, "name,brand", entry.Fields)
+	got, err := store.GetAppliance(kettle.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Kettle", got.Name)
+	assert.Equal(t, "Fellow", got.Brand)
+	_, err = store.Undo()
+	require.ErrorIs(t, err, ErrNothingToUndo)
+
+	_, err = store.Redo()
+	require.NoError(t, err)
+	got, err = store.GetAppliance(kettle.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Electric kettle", got.Name)
+	_, err = store.Redo()
+	require.ErrorIs(t, err, ErrNothingToRedo)
+
+	// Undoing and redoing is in the field history, but not the undo log.
+	changes, err := store.FieldHistory("appliances", kettle.ID)
+	require.NoError(t, err)
+	assert.Len(t, changes, 6)
+	log, err := store.UndoLog(10)
+	require.NoError(t, err)
+	assert.Len(t, log, 1)
+}
+
+func TestUndoRedoDelete(t *testing.T) {
+	store := newTestStore(t)
+	dryer := Appliance{Name: "Dryer"}
+	require.NoError(t, store.CreateAppliance(&dryer))
+	require.NoError(t, store.DeleteAppliance(dryer.ID))
+
+	entry, err := store.Undo()
+	require.NoError(t, err)
+	assert.Equal(t, UndoDelete, entry.Action)
+	_, err = store.GetAppliance(dryer.ID)
+	require.NoError(t, err)
+
+	_, err = store.Redo()
+	require.NoError(t, err)
+	_, err = store.GetAppliance(dryer.ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// Restoring by hand is a step of its own, and drops nothing.
+	require.NoError(t, store.RestoreAppliance(dryer.ID))
+	entry, err = store.Undo()
+	require.NoError(t, err)
+	assert.Equal(t, UndoRestore, entry.Action)
+	_, err = store.GetAppliance(dryer.ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestUndoNewStepDropsRedo(t *testing.T) {
+	store := newTestStore(t)
+	for _, name := range []string{"Washer", "Dryer"} {
+		a := Appliance{Name: name}
+		require.NoError(t, store.CreateAppliance(&a))
+		require.NoError(t, store.DeleteAppliance(a.ID))
+	}
+	_, err := store.Undo()
+	require.NoError(t, err)
+	washer := Appliance{Name: "Fridge"}
+	require.NoError(t, store.CreateAppliance(&washer))
+	require.NoError(t, store.DeleteAppliance(washer.ID))
+
+	_, err = store.Redo()
+	require.ErrorIs(t, err, ErrNothingToRedo)
+	log, err := store.UndoLog(10)
+	require.NoError(t, err)
+	require.Len(t, log, 2)
+	assert.Nil(t, log[0].UndoneAt)
+	assert.Nil(t, log[1].UndoneAt)
+}
+
+func TestUndoLogSurvivesRestartAndTrims(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "undo.db")
+	store, err := Open(path)
+	require.NoError(t, err)
+	require.NoError(t, store.AutoMigrate())
+	for _, name := range []string{"A", "B", "C"} {
+		a := Appliance{Name: name}
+		require.NoError(t, store.CreateAppliance(&a))
+		require.NoError(t, store.DeleteAppliance(a.ID))
+	}
+	require.NoError(t, store.SetUndoDepth(2))
+	a := Appliance{Name: "D"}
+	require.NoError(t, store.CreateAppliance(&a))
+	require.NoError(t, store.DeleteAppliance(a.ID))
+	require.ErrorContains(t, store.SetUndoDepth(0), "at least 1")
+	require.NoError(t, store.Close())
+
+	store, err = Open(path)
+	require.NoError(t, err)
+	defer store.Close()
+	log, err := store.UndoLog(10)
+	require.NoError(t, err)
+	require.Len(t, log, 2)
+	assert.Equal(t, []uint{4, 3}, []uint{log[0].TargetID, log[1].TargetID})
+
+	_, err = store.Undo()
+	require.NoError(t, err)
+	_, err = store.GetAppliance(4)
+	require.NoError(t, err)
+}
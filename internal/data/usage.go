@@ -0,0 +1,89 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Usage counter kinds.
+const (
+	// UsageFeature counts changes made through an API route, named by its
+	// pattern, e.g. "POST /api/projects".
+	UsageFeature = "feature"
+	// UsageTab counts visits to a page of the web app.
+	UsageTab = "tab"
+	// UsageForm counts forms saved on a page of the web app, and how long
+	// they were open.
+	UsageForm = "form"
+)
+
+// UsageKinds lists the counter kinds, in display order.
+func UsageKinds() []string { return []string{UsageFeature, UsageTab, UsageForm} }
+
+// usageName limits counter names to route patterns and page names, so a
+// counter can't end up holding anything typed into a record.
+var usageName = regexp.MustCompile(`^[A-Za-z0-9 /{}_.-]{1,100}$`)
+
+// UsageCounter counts how often something in webcasa was used. Counters
+// never leave the database; "webcasa stats" shows them.
+type UsageCounter struct {
+	ID    uint   `gorm:"primaryKey"`
+	Kind  string `gorm:"uniqueIndex:idx_usage_kind_name"`
+	Name  string `gorm:"uniqueIndex:idx_usage_kind_name"`
+	Count int64
+	// TotalMS adds up the durations recorded with each use, for forms.
+	TotalMS int64
+	FirstAt time.Time
+	LastAt  time.Time
+}
+
+// AverageDuration is the mean duration recorded per use.
+func (c UsageCounter) AverageDuration() time.Duration {
+	if c.Count == 0 {
+		return 0
+	}
+	return time.Duration(c.TotalMS/c.Count) * time.Millisecond
+}
+
+// RecordUsage counts one use of name, of the given kind, taking took.
+func (s *Store) RecordUsage(kind, name string, took time.Duration, at time.Time) error {
+	if !slices.Contains(UsageKinds(), kind) {
+		return fmt.Errorf("unknown usage kind %q", kind)
+	}
+	if !usageName.MatchString(name) {
+		return fmt.Errorf("invalid usage name %q", name)
+	}
+	ms := max(took.Milliseconds(), 0)
+	c := UsageCounter{Kind: kind, Name: name, Count: 1, TotalMS: ms, FirstAt: at, LastAt: at}
+	return s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "kind"}, {Name: ColName}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"count":    gorm.Expr("count + 1"),
+			"total_ms": gorm.Expr("total_ms + ?", ms),
+			"last_at":  at,
+		}),
+	}).Create(&c).Error
+}
+
+// UsageCounters returns every counter, by kind, then most used first.
+func (s *Store) UsageCounters() ([]UsageCounter, error) {
+	var counters []UsageCounter
+	err := s.db.Order("CASE kind WHEN '" + UsageFeature + "' THEN 0 WHEN '" + UsageTab + "' THEN 1 ELSE 2 END, " +
+		"count desc, " + ColName).
+		Find(&counters).Error
+	return counters, err
+}
+
+// WipeUsage deletes every counter and returns how many there were.
+func (s *Store) WipeUsage() (int64, error) {
+	result := s.db.Where("1 = 1").Delete(&UsageCounter{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,44 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageCounters(t *testing.T) {
+	store := newTestStore(t)
+	first := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	last := first.Add(time.Hour)
+	require.NoError(t, store.RecordUsage(UsageTab, "projects", 0, first))
+	require.NoError(t, store.RecordUsage(UsageForm, "projects", 30*time.Second, first))
+	require.NoError(t, store.RecordUsage(UsageForm, "projects", 10*time.Second, last))
+	require.NoError(t, store.RecordUsage(UsageFeature, "POST /api/projects", 0, first))
+
+	assert.Error(t, store.RecordUsage("clicks", "projects", 0, first))
+	assert.Error(t, store.RecordUsage(UsageTab, "Kitchen <remodel>", 0, first))
+	assert.Error(t, store.RecordUsage(UsageTab, "", 0, first))
+
+	counters, err := store.UsageCounters()
+	require.NoError(t, err)
+	require.Len(t, counters, 3)
+	assert.Equal(t, UsageFeature, counters[0].Kind)
+	assert.Equal(t, UsageTab, counters[1].Kind)
+	form := counters[2]
+	assert.Equal(t, int64(2), form.Count)
+	assert.Equal(t, 20*time.Second, form.AverageDuration())
+	assert.True(t, first.Equal(form.FirstAt))
+	assert.True(t, last.Equal(form.LastAt))
+
+	n, err := store.WipeUsage()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	counters, err = store.UsageCounters()
+	require.NoError(t, err)
+	assert.Empty(t, counters)
+}
@@ -0,0 +1,320 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MinPasswordLength is the shortest password an account may have.
+const MinPasswordLength = 10
+
+// SessionPrefix starts every sign-in session token.
+const SessionPrefix = "wcu_"
+
+// SessionTTL is how long a sign-in lasts.
+const SessionTTL = 30 * 24 * time.Hour
+
+// FreshFactorWindow is how long after a two-factor code a session counts
+// as freshly verified, for actions that ask for one, like restoring a
+// deleted record.
+const FreshFactorWindow = 5 * time.Minute
+
+// passwordIterations is the PBKDF2-SHA256 work factor for new hashes.
+const passwordIterations = 600_000
+
+// ErrBadCredentials means the username or password is wrong. Which one
+// isn't said.
+var ErrBadCredentials = errors.New("wrong username or password")
+
+// ErrSecondFactorRequired means the account has two-factor authentication
+// on and a code from it is needed to go ahead.
+var ErrSecondFactorRequired = errors.New("two-factor code required")
+
+// UserAccount names a user's two-factor enrollment, keeping it apart from
+// the admin panel's.
+func UserAccount(username string) string { return "user:" + username }
+
+// User is an account that can sign in to the web app and API. Only a
+// salted hash of the password is stored.
+type User struct {
+	ID           uint   `gorm:"primaryKey"`
+	Username     string `gorm:"uniqueIndex"`
+	PasswordHash string `json:"-"`
+	LastLoginAt  *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// UserSession is a signed-in browser or client. Only a hash of the token
+// is stored. VerifiedAt is when it last gave a two-factor code.
+type UserSession struct {
+	ID         uint   `gorm:"primaryKey"`
+	UserID     uint   `gorm:"index"`
+	User       User   `gorm:"constraint:OnDelete:CASCADE;"`
+	TokenHash  string `gorm:"uniqueIndex" json:"-"`
+	ExpiresAt  time.Time
+	LastUsedAt *time.Time
+	VerifiedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// hashPassword returns "pbkdf2-sha256$iterations$salt$key", base64 parts.
+func hashPassword(password string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, passwordIterations, 32)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("pbkdf2-sha256$%d$%s$%s", passwordIterations,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// checkPassword reports whether password matches a hash from
+// hashPassword.
+func checkPassword(hash, password string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" {
+		return false
+	}
+	iter, err := strconv.Atoi(parts[1])
+	if err != nil || iter <= 0 {
+		return false
+	}
+	salt, err1 := base64.RawStdEncoding.DecodeString(parts[2])
+	want, err2 := base64.RawStdEncoding.DecodeString(parts[3])
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	got, err := pbkdf2.Key(sha256.New, password, salt, iter, len(want))
+	return err == nil && subtle.ConstantTimeCompare(got, want) == 1
+}
+
+// dummyHash is checked against when the username is unknown, so a wrong
+// username takes as long as a wrong password.
+var dummyHash = sync.OnceValue(func() string {
+	h, _ := hashPassword("webcasa")
+	return h
+})
+
+func validatePassword(password string) error {
+	if len([]rune(password)) < MinPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+	}
+	return nil
+}
+
+// CreateUser adds an account.
+func (s *Store) CreateUser(username, password string) (User, error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return User{}, fmt.Errorf("username is required")
+	}
+	if err := validatePassword(password); err != nil {
+		return User{}, err
+	}
+	var n int64
+	if err := s.db.Model(&User{}).Where(ColUsername+" = ?", username).Count(&n).Error; err != nil {
+		return User{}, err
+	}
+	if n > 0 {
+		return User{}, fmt.Errorf("user %q already exists", username)
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return User{}, fmt.Errorf("hash password: %w", err)
+	}
+	u := User{Username: username, PasswordHash: hash}
+	if err := s.db.Create(&u).Error; err != nil {
+		return User{}, err
+	}
+	return u, nil
+}
+
+// ListUsers returns the accounts by name.
+func (s *Store) ListUsers() ([]User, error) {
+	var users []User
+	err := s.db.Order(ColUsername).Find(&users).Error
+	return users, err
+}
+
+// HasUsers reports whether any account exists. Until one does, the API
+// doesn't ask anyone to sign in.
+func (s *Store) HasUsers() (bool, error) {
+	var n int64
+	err := s.db.Model(&User{}).Limit(1).Count(&n).Error
+	return n > 0, err
+}
+
+// SetUserPassword changes an account's password and signs it out
+// everywhere.
+func (s *Store) SetUserPassword(username, password string) error {
+	if err := validatePassword(password); err != nil {
+		return err
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return fmt.Errorf("hash password: %w", err)
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var u User
+		if err := tx.Where(ColUsername+" = ?", username).First(&u).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&u).Update(ColPasswordHash, hash).Error; err != nil {
+			return err
+		}
+		return tx.Where(ColUserID+" = ?", u.ID).Delete(&UserSession{}).Error
+	})
+}
+
+// RemoveUser deletes an account, its sessions and its two-factor
+// enrollment.
+func (s *Store) RemoveUser(username string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var u User
+		if err := tx.Where(ColUsername+" = ?", username).First(&u).Error; err != nil {
+			return err
+		}
+		if err := tx.Where(ColUserID+" = ?", u.ID).Delete(&UserSession{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where(ColAccount+" = ?", UserAccount(u.Username)).Delete(&SecondFactor{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&u).Error
+	})
+}
+
+// SignIn checks a username and password, and a two-factor code when the
+// account has two-factor authentication on, and starts a session,
+// returning its token, which is not stored and can't be shown again. It
+// returns ErrSecondFactorRequired when the code is missing and
+// ErrBadSecondFactor when it is wrong.
+func (s *Store) SignIn(username, password, code string, now time.Time) (User, string, UserSession, error) {
+	var u User
+	err := s.db.Where(ColUsername+" = ?", strings.TrimSpace(username)).First(&u).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		checkPassword(dummyHash(), password)
+		return User{}, "", UserSession{}, ErrBadCredentials
+	}
+	if err != nil {
+		return User{}, "", UserSession{}, err
+	}
+	if !checkPassword(u.PasswordHash, password) {
+		return User{}, "", UserSession{}, ErrBadCredentials
+	}
+	f, err := s.SecondFactor(UserAccount(u.Username))
+	if err != nil {
+		return User{}, "", UserSession{}, err
+	}
+	var verified *time.Time
+	if f != nil && f.Confirmed() {
+		if strings.TrimSpace(code) == "" {
+			return User{}, "", UserSession{}, ErrSecondFactorRequired
+		}
+		if err := s.CheckSecondFactor(UserAccount(u.Username), code, now); err != nil {
+			return User{}, "", UserSession{}, err
+		}
+		verified = &now
+	}
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return User{}, "", UserSession{}, fmt.Errorf("generate session: %w", err)
+	}
+	token := SessionPrefix + hex.EncodeToString(raw)
+	sess := UserSession{UserID: u.ID, TokenHash: hashToken(token), ExpiresAt: now.Add(SessionTTL), VerifiedAt: verified}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Sweep expired sessions while here.
+		if err := tx.Where(ColExpiresAt+" <= ?", now).Delete(&UserSession{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Omit("User").Create(&sess).Error; err != nil {
+			return err
+		}
+		return tx.Model(&u).Update(ColLastLoginAt, now).Error
+	})
+	if err != nil {
+		return User{}, "", UserSession{}, err
+	}
+	u.LastLoginAt = &now
+	return u, token, sess, nil
+}
+
+// UserBySession returns the account signed in with the session token. It
+// returns gorm.ErrRecordNotFound for unknown and expired sessions.
+func (s *Store) UserBySession(token string, now time.Time) (User, error) {
+	sess, err := s.sessionByToken(token, now)
+	if err != nil {
+		return User{}, err
+	}
+	_ = s.db.Model(&UserSession{}).
+		Where(ColID+" = ? AND ("+ColLastUsedAt+" IS NULL OR "+ColLastUsedAt+" < ?)",
+			sess.ID, now.Add(-tokenUseResolution)).
+		Update(ColLastUsedAt, now).Error
+	return sess.User, nil
+}
+
+// sessionByToken returns the unexpired session with the token, with its
+// account.
+func (s *Store) sessionByToken(token string, now time.Time) (UserSession, error) {
+	var sess UserSession
+	err := s.db.Preload("User").
+		Where(ColTokenHash+" = ? AND "+ColExpiresAt+" > ?", hashToken(token), now).
+		First(&sess).Error
+	return sess, err
+}
+
+// VerifySession checks a two-factor code for the session's account and
+// marks the session freshly verified. It returns ErrBadSecondFactor for a
+// wrong code.
+func (s *Store) VerifySession(token, code string, now time.Time) error {
+	sess, err := s.sessionByToken(token, now)
+	if err != nil {
+		return err
+	}
+	if err := s.CheckSecondFactor(UserAccount(sess.User.Username), code, now); err != nil {
+		return err
+	}
+	return s.db.Model(&UserSession{}).Where(ColID+" = ?", sess.ID).Update(ColVerifiedAt, now).Error
+}
+
+// FreshlyVerified reports whether the session gave a two-factor code
+// within FreshFactorWindow, or its account doesn't use two-factor
+// authentication and so has none to give.
+func (s *Store) FreshlyVerified(token string, now time.Time) (bool, error) {
+	sess, err := s.sessionByToken(token, now)
+	if err != nil {
+		return false, err
+	}
+	f, err := s.SecondFactor(UserAccount(sess.User.Username))
+	if err != nil {
+		return false, err
+	}
+	if f == nil || !f.Confirmed() {
+		return true, nil
+	}
+	return sess.VerifiedAt != nil && now.Sub(*sess.VerifiedAt) < FreshFactorWindow, nil
+}
+
+// SignOut ends a session. Ending an unknown one is harmless.
+func (s *Store) SignOut(token string) error {
+	return s.db.Where(ColTokenHash+" = ?", hashToken(token)).Delete(&UserSession{}).Error
+}
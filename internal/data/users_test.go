@@ -0,0 +1,133 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestUsersAndSessions(t *testing.T) {
+	store := newTestStore(t)
+	has, err := store.HasUsers()
+	require.NoError(t, err)
+	assert.False(t, has)
+
+	_, err = store.CreateUser("pat", "short")
+	require.ErrorContains(t, err, "at least")
+	u, err := store.CreateUser(" pat ", "correct horse battery")
+	require.NoError(t, err)
+	assert.Equal(t, "pat", u.Username)
+	assert.True(t, strings.HasPrefix(u.PasswordHash, "pbkdf2-sha256$"))
+	assert.NotContains(t, u.PasswordHash, "correct horse")
+	_, err = store.CreateUser("pat", "another good password")
+	require.ErrorContains(t, err, "already exists")
+	has, err = store.HasUsers()
+	require.NoError(t, err)
+	assert.True(t, has)
+
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	_, _, _, err = store.SignIn("pat", "wrong password!", "", now)
+	require.ErrorIs(t, err, ErrBadCredentials)
+	_, _, _, err = store.SignIn("nobody", "correct horse battery", "", now)
+	require.ErrorIs(t, err, ErrBadCredentials)
+
+	signedIn, token, sess, err := store.SignIn("pat", "correct horse battery", "", now)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, SessionPrefix))
+	assert.Equal(t, now.Add(SessionTTL), sess.ExpiresAt)
+	require.NotNil(t, signedIn.LastLoginAt)
+
+	got, err := store.UserBySession(token, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, got.ID)
+	_, err = store.UserBySession(token, now.Add(SessionTTL))
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound, "expired")
+	_, err = store.UserBySession("wcu_nope", now)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// A new password signs the account out everywhere.
+	require.NoError(t, store.SetUserPassword("pat", "a brand new password"))
+	_, err = store.UserBySession(token, now.Add(time.Hour))
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, token, _, err = store.SignIn("pat", "a brand new password", "", now)
+	require.NoError(t, err)
+
+	require.NoError(t, store.SignOut(token))
+	_, err = store.UserBySession(token, now.Add(time.Hour))
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	require.NoError(t, store.RemoveUser("pat"))
+	require.ErrorIs(t, store.RemoveUser("pat"), gorm.ErrRecordNotFound)
+	has, err = store.HasUsers()
+	require.NoError(t, err)
+	assert.False(t, has)
+}
+
+func TestUserSecondFactor(t *testing.T) {
+	store := newTestStore(t)
+	_, err := store.CreateUser("pat", "correct horse battery")
+	require.NoError(t, err)
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// Without two-factor, a session is always fresh enough.
+	_, token, _, err := store.SignIn("pat", "correct horse battery", "", now)
+	require.NoError(t, err)
+	fresh, err := store.FreshlyVerified(token, now)
+	require.NoError(t, err)
+	assert.True(t, fresh)
+
+	pending, err := store.StartSecondFactor(UserAccount("pat"))
+	require.NoError(t, err)
+	code, err := totp.Code(pending.Secret, totp.Step(now))
+	require.NoError(t, err)
+	_, err = store.ConfirmSecondFactor(UserAccount("pat"), code, now)
+	require.NoError(t, err)
+	admin, err := store.SecondFactor("admin")
+	require.NoError(t, err)
+	assert.Nil(t, admin, "the admin panel's enrollment is apart")
+
+	// The session from before has given no code.
+	fresh, err = store.FreshlyVerified(token, now)
+	require.NoError(t, err)
+	assert.False(t, fresh)
+
+	_, _, _, err = store.SignIn("pat", "correct horse battery", "", now)
+	require.ErrorIs(t, err, ErrSecondFactorRequired)
+	_, _, _, err = store.SignIn("pat", "correct horse battery", "000000", now)
+	require.ErrorIs(t, err, ErrBadSecondFactor)
+	later := now.Add(time.Minute)
+	code, err = totp.Code(pending.Secret, totp.Step(later))
+	require.NoError(t, err)
+	_, token, sess, err := store.SignIn("pat", "correct horse battery", code, later)
+	require.NoError(t, err)
+	require.NotNil(t, sess.VerifiedAt)
+	fresh, err = store.FreshlyVerified(token, later.Add(FreshFactorWindow-time.Second))
+	require.NoError(t, err)
+	assert.True(t, fresh)
+	fresh, err = store.FreshlyVerified(token, later.Add(FreshFactorWindow))
+	require.NoError(t, err)
+	assert.False(t, fresh)
+
+	// A new code freshens the session again.
+	evenLater := later.Add(time.Hour)
+	require.ErrorIs(t, store.VerifySession(token, "000000", evenLater), ErrBadSecondFactor)
+	code, err = totp.Code(pending.Secret, totp.Step(evenLater))
+	require.NoError(t, err)
+	require.NoError(t, store.VerifySession(token, code, evenLater))
+	fresh, err = store.FreshlyVerified(token, evenLater)
+	require.NoError(t, err)
+	assert.True(t, fresh)
+
+	require.NoError(t, store.RemoveUser("pat"))
+	f, err := store.SecondFactor(UserAccount("pat"))
+	require.NoError(t, err)
+	assert.Nil(t, f)
+}
@@ -0,0 +1,206 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Utility types.
+const (
+	UtilityElectric = "electric"
+	UtilityGas      = "gas"
+	UtilityWater    = "water"
+	UtilityInternet = "internet"
+)
+
+// UtilityTypes lists the utilities a bill can be for.
+func UtilityTypes() []string {
+	return []string{UtilityElectric, UtilityGas, UtilityWater, UtilityInternet}
+}
+
+// utilityUnits are the units usage is measured in when a bill doesn't
+// say.
+var utilityUnits = map[string]string{
+	UtilityElectric: "kWh",
+	UtilityGas:      "therms",
+	UtilityWater:    "gallons",
+	UtilityInternet: "GB",
+}
+
+// UtilityBill is one bill from a utility: what the house used over the
+// billing period, in Unit, and what it cost. A bill counts toward the
+// month its period ends in.
+type UtilityBill struct {
+	ID          uint  `gorm:"primaryKey"`
+	HouseID     *uint `gorm:"index"`
+	Type        string
+	PeriodStart *time.Time
+	PeriodEnd   time.Time `gorm:"index"`
+	Usage       *float64
+	Unit        string
+	CostCents   int64
+	Notes       string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Version     int            `gorm:"not null;default:1"`
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+// ListUtilityBills returns the current house's utility bills, the latest
+// period first.
+func (s *Store) ListUtilityBills(includeDeleted bool) ([]UtilityBill, error) {
+	var items []UtilityBill
+	db := s.db.Scopes(s.inHouse("utility_bills")).
+		Order("period_end desc, " + ColID + " desc")
+	if includeDeleted {
+		db = db.Unscoped()
+	}
+	return items, db.Find(&items).Error
+}
+
+func (s *Store) GetUtilityBill(id uint) (UtilityBill, error) {
+	var item UtilityBill
+	err := s.db.First(&item, id).Error
+	return item, err
+}
+
+func (s *Store) CreateUtilityBill(item *UtilityBill) error {
+	if err := validateUtilityBill(item); err != nil {
+		return err
+	}
+	return s.db.Create(item).Error
+}
+
+func (s *Store) UpdateUtilityBill(item UtilityBill) error {
+	if err := validateUtilityBill(&item); err != nil {
+		return err
+	}
+	return s.updateByID(&UtilityBill{}, item.ID, item)
+}
+
+func (s *Store) DeleteUtilityBill(id uint) error {
+	return s.softDelete(&UtilityBill{}, DeletionEntityUtilityBill, id)
+}
+
+func (s *Store) RestoreUtilityBill(id uint) error {
+	return s.restoreEntity(&UtilityBill{}, DeletionEntityUtilityBill, id)
+}
+
+func validateUtilityBill(b *UtilityBill) error {
+	b.Unit = strings.TrimSpace(b.Unit)
+	if b.Unit == "" {
+		b.Unit = utilityUnits[b.Type]
+	}
+	switch {
+	case !slices.Contains(UtilityTypes(), b.Type):
+		return fmt.Errorf("unknown utility type %q", b.Type)
+	case b.PeriodEnd.IsZero():
+		return fmt.Errorf("a utility bill needs the end of its period")
+	case b.PeriodStart != nil && b.PeriodStart.After(b.PeriodEnd):
+		return fmt.Errorf("a utility bill's period can't end before it starts")
+	case b.CostCents < 0 || (b.Usage != nil && *b.Usage < 0):
+		return fmt.Errorf("a utility bill's usage and cost can't be negative")
+	}
+	return nil
+}
+
+// UtilityMonth is a month of one utility's bills next to the same month a
+// year before. Usage is nil in a month with no bill saying how much was
+// used. Change is how usage compares with the year before, 0.25 for a
+// quarter more, nil without both.
+type UtilityMonth struct {
+	Label         string
+	Usage         *float64
+	LastYear      *float64
+	Change        *float64
+	CostCents     int64
+	LastYearCents int64
+}
+
+// UtilityTrend is a utility's usage month by month, oldest first.
+type UtilityTrend struct {
+	Type   string
+	Unit   string
+	Months []UtilityMonth
+}
+
+// UtilityTrends totals the current house's bills for each utility billed
+// over the given number of calendar months up to and including now's, each
+// month next to the same month the year before, for spotting usage
+// creeping up. Utilities with no bills in the months or the year before
+// are left out.
+func (s *Store) UtilityTrends(now time.Time, months int) ([]UtilityTrend, error) {
+	first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, 1-months, 0)
+	var bills []UtilityBill
+	err := s.db.Scopes(s.inHouse("utility_bills")).
+		Where("period_end >= ?", first.AddDate(-1, 0, 0)).
+		Order("period_end, " + ColID).
+		Find(&bills).Error
+	if err != nil {
+		return nil, err
+	}
+
+	type total struct {
+		usage *float64
+		cents int64
+	}
+	totals := map[string]map[string]*total{}
+	units := map[string]string{}
+	for _, b := range bills {
+		if totals[b.Type] == nil {
+			totals[b.Type] = map[string]*total{}
+		}
+		label := b.PeriodEnd.Format("2006-01")
+		t := totals[b.Type][label]
+		if t == nil {
+			t = &total{}
+			totals[b.Type][label] = t
+		}
+		t.cents += b.CostCents
+		if b.Usage != nil {
+			t.usage = ptrSum(t.usage, *b.Usage)
+		}
+		units[b.Type] = b.Unit
+	}
+
+	var out []UtilityTrend
+	for _, typ := range UtilityTypes() {
+		byMonth, ok := totals[typ]
+		if !ok {
+			continue
+		}
+		trend := UtilityTrend{Type: typ, Unit: units[typ], Months: make([]UtilityMonth, months)}
+		for i := range trend.Months {
+			at := first.AddDate(0, i, 0)
+			m := UtilityMonth{Label: at.Format("2006-01")}
+			if t := byMonth[m.Label]; t != nil {
+				m.Usage, m.CostCents = t.usage, t.cents
+			}
+			if t := byMonth[at.AddDate(-1, 0, 0).Format("2006-01")]; t != nil {
+				m.LastYear, m.LastYearCents = t.usage, t.cents
+			}
+			if m.Usage != nil && m.LastYear != nil && *m.LastYear > 0 {
+				change := *m.Usage / *m.LastYear - 1
+				m.Change = &change
+			}
+			trend.Months[i] = m
+		}
+		out = append(out, trend)
+	}
+	return out, nil
+}
+
+// ptrSum adds x to what sum points at, treating nil as nothing yet.
+func ptrSum(sum *float64, x float64) *float64 {
+	if sum != nil {
+		x += *sum
+	}
+	return &x
+}
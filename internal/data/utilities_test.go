@@ -0,0 +1,67 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUtilityTrends(t *testing.T) {
+	store := newTestStore(t)
+	now := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+	end := func(y int, m time.Month) time.Time { return time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC) }
+	amount := func(x float64) *float64 { return &x }
+
+	require.ErrorContains(t, store.CreateUtilityBill(&UtilityBill{Type: "sewer", PeriodEnd: now}), "unknown utility type")
+	require.ErrorContains(t, store.CreateUtilityBill(&UtilityBill{Type: UtilityGas}), "end of its period")
+	require.ErrorContains(t, store.CreateUtilityBill(&UtilityBill{
+		Type: UtilityGas, PeriodStart: &now, PeriodEnd: now.AddDate(0, 0, -1),
+	}), "can't end before it starts")
+	require.ErrorContains(t, store.CreateUtilityBill(&UtilityBill{Type: UtilityGas, PeriodEnd: now, CostCents: -1}), "can't be negative")
+
+	// Gas use jumps this February; two bills closing in one month add up.
+	for _, b := range []UtilityBill{
+		{Type: UtilityGas, PeriodEnd: end(2025, 2), Usage: amount(80), CostCents: 9000},
+		{Type: UtilityGas, PeriodEnd: end(2025, 3), Usage: amount(60), CostCents: 7000},
+		{Type: UtilityGas, PeriodEnd: end(2026, 2), Usage: amount(120), CostCents: 14000},
+		{Type: UtilityGas, PeriodEnd: time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), Usage: amount(30), CostCents: 3500},
+		{Type: UtilityGas, PeriodEnd: end(2026, 3), Usage: amount(33), CostCents: 3700},
+		{Type: UtilityInternet, PeriodEnd: end(2026, 3), CostCents: 6000},
+		{Type: UtilityWater, PeriodEnd: end(2024, 1), Usage: amount(3000)},
+	} {
+		require.NoError(t, store.CreateUtilityBill(&b))
+	}
+
+	trends, err := store.UtilityTrends(now, 3)
+	require.NoError(t, err)
+	require.Len(t, trends, 2, "water has no bills in the last two years")
+	gas := trends[0]
+	assert.Equal(t, UtilityGas, gas.Type)
+	assert.Equal(t, "therms", gas.Unit)
+	require.Len(t, gas.Months, 3)
+	assert.Equal(t, []string{"2026-01", "2026-02", "2026-03"},
+		[]string{gas.Months[0].Label, gas.Months[1].Label, gas.Months[2].Label})
+	assert.Nil(t, gas.Months[0].Usage)
+
+	feb := gas.Months[1]
+	assert.InDelta(t, 120, *feb.Usage, 0.001)
+	assert.InDelta(t, 80, *feb.LastYear, 0.001)
+	require.NotNil(t, feb.Change)
+	assert.InDelta(t, 0.5, *feb.Change, 0.001)
+	assert.Equal(t, int64(9000), feb.LastYearCents)
+
+	mar := gas.Months[2]
+	assert.InDelta(t, 63, *mar.Usage, 0.001)
+	assert.Equal(t, int64(7200), mar.CostCents)
+	assert.InDelta(t, 0.05, *mar.Change, 0.001)
+
+	internet := trends[1]
+	assert.Nil(t, internet.Months[2].Usage)
+	assert.Nil(t, internet.Months[2].Change)
+	assert.Equal(t, int64(6000), internet.Months[2].CostCents)
+}
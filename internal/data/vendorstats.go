@@ -0,0 +1,230 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// VendorStats is how a vendor has done for the current house, for
+// comparing vendors side by side. A job is a service visit the vendor
+// made, or a project the vendor invoiced; its category is the maintenance
+// item's category or the project's type.
+type VendorStats struct {
+	VendorID   uint
+	Name       string
+	Jobs       int
+	SpendCents int64
+	// AvgJobCents averages the jobs with a cost.
+	AvgJobCents int64
+	// VsMedian is how the vendor's jobs compare with the median job in
+	// their category, all vendors' jobs counted, averaged: 0.15 is 15%
+	// dearer, -0.2 20% cheaper. Nil without a costed job.
+	VsMedian *float64
+	// QuoteDays is the average days from sending the vendor a bid request
+	// to receiving their quote, over the Quoted requests answered.
+	Quoted    int
+	QuoteDays *float64
+	// RepeatRate is how often the vendor got the next job in a category
+	// after doing the one before it: Rehires out of RehireChances. Nil
+	// when no job has followed one of theirs.
+	Rehires       int
+	RehireChances int
+	RepeatRate    *float64
+}
+
+// vendorJob is one job a vendor did, as VendorAnalytics reads it.
+// Project tells a project type from a maintenance category of the same
+// name.
+type vendorJob struct {
+	VendorID uint
+	Category string
+	Project  bool
+	Date     time.Time
+	Cents    *int64
+}
+
+type jobCategory struct {
+	project bool
+	name    string
+}
+
+func (j vendorJob) category() jobCategory { return jobCategory{j.Project, j.Category} }
+
+// VendorAnalytics ranks the vendors by what the current house has spent
+// with them, then by name. Vendors with no jobs and no quotes are left
+// out.
+func (s *Store) VendorAnalytics() ([]VendorStats, error) {
+	var jobs []vendorJob
+	err := s.db.Model(&ServiceLogEntry{}).
+		Select("service_log_entries.vendor_id, maintenance_categories.name AS category, " +
+			"service_log_entries.serviced_at AS date, service_log_entries.cost_cents AS cents").
+		Joins("JOIN " + tableMaintenanceItems + " ON " + tableMaintenanceItems + ".id = service_log_entries.maintenance_item_id").
+		Joins("JOIN maintenance_categories ON maintenance_categories.id = " + tableMaintenanceItems + ".category_id").
+		Where("service_log_entries.vendor_id IS NOT NULL AND " + tableMaintenanceItems + ".deleted_at IS NULL").
+		Scopes(s.inHouse(tableMaintenanceItems)).
+		Scan(&jobs).Error
+	if err != nil {
+		return nil, err
+	}
+	// A vendor's invoices for a project make one job, dated by the first.
+	var invoices []struct {
+		VendorID    uint
+		ProjectID   uint
+		Category    string
+		InvoicedOn  time.Time
+		AmountCents int64
+	}
+	err = s.db.Model(&Invoice{}).
+		Select("invoices.vendor_id, invoices.project_id, project_types.name AS category, " +
+			"invoices.invoiced_on, invoices.amount_cents").
+		Joins("JOIN projects ON projects.id = invoices.project_id").
+		Joins("JOIN project_types ON project_types.id = projects.project_type_id").
+		Where("invoices.vendor_id IS NOT NULL AND projects.deleted_at IS NULL").
+		Scopes(s.inHouse("projects")).
+		Order("invoices.invoiced_on").
+		Scan(&invoices).Error
+	if err != nil {
+		return nil, err
+	}
+	projectJobs := map[[2]uint]int{}
+	for _, inv := range invoices {
+		key := [2]uint{inv.VendorID, inv.ProjectID}
+		i, ok := projectJobs[key]
+		if !ok {
+			i = len(jobs)
+			projectJobs[key] = i
+			jobs = append(jobs, vendorJob{
+				VendorID: inv.VendorID, Category: inv.Category, Project: true, Date: inv.InvoicedOn, Cents: new(int64),
+			})
+		}
+		*jobs[i].Cents += inv.AmountCents
+	}
+
+	// A bid request is answered by the vendor's first quote for the
+	// project.
+	var answers []struct {
+		ID         uint
+		VendorID   uint
+		SentAt     time.Time
+		ReceivedAt time.Time
+	}
+	err = s.db.Model(&BidRequest{}).
+		Select("bid_requests.id, bid_requests.vendor_id, bid_requests.sent_at, quotes.received_date AS received_at").
+		Joins("JOIN quotes ON quotes.project_id = bid_requests.project_id AND quotes.vendor_id = bid_requests.vendor_id").
+		Joins("JOIN projects ON projects.id = bid_requests.project_id").
+		Where("quotes.deleted_at IS NULL AND quotes.received_date IS NOT NULL AND projects.deleted_at IS NULL").
+		Scopes(s.inHouse("projects")).
+		Order("quotes.received_date").
+		Scan(&answers).Error
+	if err != nil {
+		return nil, err
+	}
+
+	vendors, err := s.ListVendors(false)
+	if err != nil {
+		return nil, err
+	}
+	stats := make(map[uint]*VendorStats, len(vendors))
+	for _, v := range vendors {
+		stats[v.ID] = &VendorStats{VendorID: v.ID, Name: v.Name}
+	}
+
+	costs := map[jobCategory][]int64{}
+	for _, j := range jobs {
+		if j.Cents != nil {
+			costs[j.category()] = append(costs[j.category()], *j.Cents)
+		}
+	}
+	medians := make(map[jobCategory]int64, len(costs))
+	for category, c := range costs {
+		medians[category] = medianCents(c)
+	}
+
+	slices.SortStableFunc(jobs, func(a, b vendorJob) int { return a.Date.Compare(b.Date) })
+	last := map[jobCategory]uint{}
+	costed := map[uint]int{}
+	vsMedian := map[uint][]float64{}
+	for _, j := range jobs {
+		if prev, ok := last[j.category()]; ok {
+			if st := stats[prev]; st != nil {
+				st.RehireChances++
+				if prev == j.VendorID {
+					st.Rehires++
+				}
+			}
+		}
+		last[j.category()] = j.VendorID
+		st := stats[j.VendorID]
+		if st == nil {
+			continue
+		}
+		st.Jobs++
+		if j.Cents == nil {
+			continue
+		}
+		st.SpendCents += *j.Cents
+		costed[j.VendorID]++
+		if m := medians[j.category()]; m > 0 {
+			vsMedian[j.VendorID] = append(vsMedian[j.VendorID], float64(*j.Cents)/float64(m)-1)
+		}
+	}
+
+	days := map[uint]float64{}
+	answered := map[uint]bool{}
+	for _, a := range answers {
+		st := stats[a.VendorID]
+		if st == nil || answered[a.ID] || a.ReceivedAt.Before(a.SentAt) {
+			continue
+		}
+		answered[a.ID] = true
+		st.Quoted++
+		days[a.VendorID] += a.ReceivedAt.Sub(a.SentAt).Hours() / 24
+	}
+
+	out := make([]VendorStats, 0, len(stats))
+	for _, v := range vendors {
+		st := stats[v.ID]
+		if st.Jobs == 0 && st.Quoted == 0 {
+			continue
+		}
+		if n := costed[v.ID]; n > 0 {
+			st.AvgJobCents = st.SpendCents / int64(n)
+		}
+		if r := vsMedian[v.ID]; len(r) > 0 {
+			var sum float64
+			for _, x := range r {
+				sum += x
+			}
+			avg := sum / float64(len(r))
+			st.VsMedian = &avg
+		}
+		if st.Quoted > 0 {
+			avg := days[v.ID] / float64(st.Quoted)
+			st.QuoteDays = &avg
+		}
+		if st.RehireChances > 0 {
+			rate := float64(st.Rehires) / float64(st.RehireChances)
+			st.RepeatRate = &rate
+		}
+		out = append(out, *st)
+	}
+	slices.SortStableFunc(out, func(a, b VendorStats) int {
+		return cmp.Or(cmp.Compare(b.SpendCents, a.SpendCents), cmp.Compare(a.Name, b.Name))
+	})
+	return out, nil
+}
+
+// medianCents is the median of amounts, the mean of the middle two when
+// there's an even number of them.
+func medianCents(amounts []int64) int64 {
+	sorted := slices.Sorted(slices.Values(amounts))
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVendorAnalytics(t *testing.T) {
+	store := newTestStore(t)
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 9, 0, 0, 0, time.UTC) }
+	cents := func(c int64) *int64 { return &c }
+
+	categories, err := store.MaintenanceCategories()
+	require.NoError(t, err)
+	furnace := MaintenanceItem{Name: "Furnace service", CategoryID: categories[0].ID, IntervalMonths: 3}
+	require.NoError(t, store.CreateMaintenance(&furnace))
+	acme, bolt, cool := Vendor{Name: "Acme HVAC"}, Vendor{Name: "Bolt Heating"}, Vendor{Name: "Cool Air"}
+	for _, v := range []*Vendor{&acme, &bolt, &cool} {
+		require.NoError(t, store.CreateVendor(v))
+	}
+	require.NoError(t, store.CreateVendor(&Vendor{Name: "Idle Co"}))
+
+	// Acme services the furnace, loses it to Bolt once, and gets it back.
+	for _, visit := range []struct {
+		vendor Vendor
+		on     time.Time
+		cents  int64
+	}{
+		{acme, day(1, 1), 20000}, {acme, day(4, 1), 22000}, {bolt, day(7, 1), 30000}, {acme, day(10, 1), 20000},
+	} {
+		require.NoError(t, store.CreateServiceLog(&ServiceLogEntry{
+			MaintenanceItemID: furnace.ID, ServicedAt: visit.on, CostCents: cents(visit.cents),
+		}, visit.vendor))
+	}
+
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	project := Project{Title: "Heat pump", ProjectTypeID: types[0].ID, Status: ProjectStatusInProgress}
+	require.NoError(t, store.CreateProject(&project))
+	require.NoError(t, store.RecordBidRequests(project.ID, []uint{bolt.ID, cool.ID}, day(3, 1), 0))
+	received := day(3, 11)
+	require.NoError(t, store.CreateQuote(&Quote{ProjectID: project.ID, TotalCents: 150000, ReceivedDate: &received}, cool))
+	require.NoError(t, store.CreateInvoice(&Invoice{ProjectID: project.ID, VendorID: &cool.ID, InvoicedOn: day(5, 1), AmountCents: 100000}))
+	require.NoError(t, store.CreateInvoice(&Invoice{ProjectID: project.ID, VendorID: &cool.ID, InvoicedOn: day(6, 1), AmountCents: 50000}))
+
+	stats, err := store.VendorAnalytics()
+	require.NoError(t, err)
+	require.Len(t, stats, 3, "Idle Co has done nothing")
+	assert.Equal(t, []string{"Cool Air", "Acme HVAC", "Bolt Heating"},
+		[]string{stats[0].Name, stats[1].Name, stats[2].Name})
+
+	c := stats[0]
+	assert.Equal(t, 1, c.Jobs, "a project's invoices are one job")
+	assert.Equal(t, int64(150000), c.SpendCents)
+	require.NotNil(t, c.VsMedian)
+	assert.Zero(t, *c.VsMedian)
+	assert.Equal(t, 1, c.Quoted)
+	require.NotNil(t, c.QuoteDays)
+	assert.InDelta(t, 10, *c.QuoteDays, 0.001)
+	assert.Nil(t, c.RepeatRate)
+
+	// The furnace median is (200 + 220) / 2 = 210.
+	a := stats[1]
+	assert.Equal(t, 3, a.Jobs)
+	assert.Equal(t, int64(62000), a.SpendCents)
+	assert.Equal(t, int64(20666), a.AvgJobCents)
+	require.NotNil(t, a.VsMedian)
+	assert.InDelta(t, (20000.0/21000*2+22000.0/21000)/3-1, *a.VsMedian, 0.0001)
+	assert.Nil(t, a.QuoteDays)
+	assert.Equal(t, 1, a.Rehires)
+	assert.Equal(t, 2, a.RehireChances)
+	require.NotNil(t, a.RepeatRate)
+	assert.InDelta(t, 0.5, *a.RepeatRate, 0.0001)
+
+	b := stats[2]
+	assert.Equal(t, 0, b.Quoted, "Bolt never quoted")
+	assert.Equal(t, 0, b.Rehires)
+	assert.Equal(t, 1, b.RehireChances)
+}
@@ -0,0 +1,218 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Walkthrough is an annual photo/video snapshot of the house, kept so that
+// condition changes over the years are documented for insurance claims and
+// resale. There is at most one walkthrough per year.
+type Walkthrough struct {
+	ID        uint  `gorm:"primaryKey"`
+	HouseID   *uint `gorm:"index"`
+	Year      int   `gorm:"index"`
+	Title     string
+	TakenAt   *time.Time
+	Notes     string
+	Items     []WalkthroughItem `gorm:"constraint:OnDelete:CASCADE;"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Version   int            `gorm:"not null;default:1"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+// WalkthroughItem tags a photo or video document as part of a walkthrough.
+// Area (or the linked room) is what lines shots up across years.
+type WalkthroughItem struct {
+	ID            uint     `gorm:"primaryKey"`
+	WalkthroughID uint     `gorm:"index"`
+	DocumentID    uint     `gorm:"index"`
+	Document      Document `gorm:"constraint:OnDelete:RESTRICT;"`
+	RoomID        *uint    `gorm:"index"`
+	Room          Room     `gorm:"constraint:OnDelete:SET NULL;"`
+	Area          string
+	Caption       string
+	CreatedAt     time.Time
+}
+
+// AreaLabel is the name used to line this item up with other years: the
+// room name if linked, otherwise the free-text area.
+func (i WalkthroughItem) AreaLabel() string {
+	if i.RoomID != nil && i.Room.Name != "" {
+		return i.Room.Name
+	}
+	if a := strings.TrimSpace(i.Area); a != "" {
+		return a
+	}
+	return "General"
+}
+
+// WalkthroughArea is one row of a year-over-year comparison: the shots of
+// one area, grouped by year.
+type WalkthroughArea struct {
+	Area   string
+	ByYear map[int][]WalkthroughItem
+}
+
+// walkthroughItemsPreload loads items with their rooms and document
+// metadata (not the file bytes), skipping deleted documents.
+func walkthroughItemsPreload(q *gorm.DB) *gorm.DB {
+	return q.InnerJoins("Document", q.Session(&gorm.Session{NewDB: true}).
+		Select(listDocumentColumns)).
+		Preload("Room", func(q *gorm.DB) *gorm.DB { return q.Unscoped() }).
+		Order("walkthrough_items." + ColID)
+}
+
+func (s *Store) ListWalkthroughs(includeDeleted bool) ([]Walkthrough, error) {
+	var items []Walkthrough
+	db := s.db.Preload("Items", walkthroughItemsPreload).Scopes(s.inHouse("walkthroughs")).
+		Order(ColYear + " desc, " + ColID + " desc")
+	if includeDeleted {
+		db = db.Unscoped()
+	}
+	return items, db.Find(&items).Error
+}
+
+func (s *Store) GetWalkthrough(id uint) (Walkthrough, error) {
+	var item Walkthrough
+	err := s.db.Preload("Items", walkthroughItemsPreload).First(&item, id).Error
+	return item, err
+}
+
+func (s *Store) CreateWalkthrough(item *Walkthrough) error {
+	if err := s.validateWalkthrough(item); err != nil {
+		return err
+	}
+	item.Items = nil
+	return s.db.Create(item).Error
+}
+
+func (s *Store) UpdateWalkthrough(item Walkthrough) error {
+	if err := s.validateWalkthrough(&item); err != nil {
+		return err
+	}
+	item.Items = nil
+	return s.updateByID(&Walkthrough{}, item.ID, item)
+}
+
+func (s *Store) DeleteWalkthrough(id uint) error {
+	return s.softDelete(&Walkthrough{}, DeletionEntityWalkthrough, id)
+}
+
+func (s *Store) RestoreWalkthrough(id uint) error {
+	var item Walkthrough
+	if err := s.db.Unscoped().First(&item, id).Error; err != nil {
+		return err
+	}
+	if err := s.requireYearFree(item.Year, id); err != nil {
+		return err
+	}
+	return s.restoreEntity(&Walkthrough{}, DeletionEntityWalkthrough, id)
+}
+
+// AddWalkthroughItem tags a photo or video document as part of a
+// walkthrough.
+func (s *Store) AddWalkthroughItem(item *WalkthroughItem) error {
+	if err := s.requireParentAlive(&Walkthrough{}, item.WalkthroughID); err != nil {
+		return fmt.Errorf("walkthrough not found or deleted")
+	}
+	doc, err := s.GetDocument(item.DocumentID)
+	if err != nil {
+		return fmt.Errorf("document not found or deleted")
+	}
+	if !isWalkthroughMedia(doc.MIMEType) {
+		return fmt.Errorf("%q is not a photo or video", doc.Title)
+	}
+	if item.RoomID != nil {
+		if err := s.requireParentAlive(&Room{}, *item.RoomID); err != nil {
+			return fmt.Errorf("room not found or deleted")
+		}
+	}
+	item.Area = strings.TrimSpace(item.Area)
+	item.Document = Document{}
+	item.Room = Room{}
+	return s.db.Create(item).Error
+}
+
+// RemoveWalkthroughItem untags a document. The document itself is kept.
+func (s *Store) RemoveWalkthroughItem(id uint) error {
+	result := s.db.Delete(&WalkthroughItem{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CompareWalkthroughs lines up the walkthroughs for the given years by
+// area, so the same spot can be viewed side by side across years. Areas are
+// sorted by name.
+func (s *Store) CompareWalkthroughs(years []int) ([]WalkthroughArea, error) {
+	var walks []Walkthrough
+	if err := s.db.Preload("Items", walkthroughItemsPreload).Scopes(s.inHouse("walkthroughs")).
+		Where(ColYear+" IN ?", years).
+		Find(&walks).Error; err != nil {
+		return nil, err
+	}
+	byArea := make(map[string]*WalkthroughArea)
+	for _, w := range walks {
+		for _, it := range w.Items {
+			label := it.AreaLabel()
+			row, ok := byArea[strings.ToLower(label)]
+			if !ok {
+				row = &WalkthroughArea{Area: label, ByYear: make(map[int][]WalkthroughItem)}
+				byArea[strings.ToLower(label)] = row
+			}
+			row.ByYear[w.Year] = append(row.ByYear[w.Year], it)
+		}
+	}
+	out := make([]WalkthroughArea, 0, len(byArea))
+	for _, row := range byArea {
+		out = append(out, *row)
+	}
+	slices.SortFunc(out, func(a, b WalkthroughArea) int {
+		return strings.Compare(strings.ToLower(a.Area), strings.ToLower(b.Area))
+	})
+	return out, nil
+}
+
+func (s *Store) validateWalkthrough(item *Walkthrough) error {
+	if item.Year < 1800 || item.Year > 9999 {
+		return fmt.Errorf("walkthrough year %d is out of range", item.Year)
+	}
+	item.Title = strings.TrimSpace(item.Title)
+	if item.Title == "" {
+		item.Title = fmt.Sprintf("%d walkthrough", item.Year)
+	}
+	return s.requireYearFree(item.Year, item.ID)
+}
+
+// requireYearFree refuses a second walkthrough of the current house in a
+// year; each house gets its own.
+func (s *Store) requireYearFree(year int, selfID uint) error {
+	var other Walkthrough
+	err := s.db.Scopes(s.inHouse("walkthroughs")).
+		Where(ColYear+" = ? AND "+ColID+" <> ?", year, selfID).First(&other).Error
+	if err == nil {
+		return fmt.Errorf("there is already a walkthrough for %d", year)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+func isWalkthroughMedia(mime string) bool {
+	return strings.HasPrefix(mime, "image/") || strings.HasPrefix(mime, "video/")
+}
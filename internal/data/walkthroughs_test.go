@@ -0,0 +1,98 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPhoto(t *testing.T, store *Store, title, mime string) Document {
+	t.Helper()
+	doc := Document{Title: title, FileName: title + ".jpg", MIMEType: mime, Data: []byte("x"), SizeBytes: 1}
+	require.NoError(t, store.CreateDocument(&doc))
+	return doc
+}
+
+func TestWalkthroughOnePerYear(t *testing.T) {
+	store := newTestStore(t)
+	w := Walkthrough{Year: 2025}
+	require.NoError(t, store.CreateWalkthrough(&w))
+	assert.Equal(t, "2025 walkthrough", w.Title)
+	require.ErrorContains(t, store.CreateWalkthrough(&Walkthrough{Year: 2025}), "already")
+	assert.Error(t, store.CreateWalkthrough(&Walkthrough{Year: 25}))
+
+	require.NoError(t, store.DeleteWalkthrough(w.ID))
+	again := Walkthrough{Year: 2025}
+	require.NoError(t, store.CreateWalkthrough(&again))
+	require.ErrorContains(t, store.RestoreWalkthrough(w.ID), "already")
+}
+
+func TestWalkthroughItems(t *testing.T) {
+	store := newTestStore(t)
+	w := Walkthrough{Year: 2024}
+	require.NoError(t, store.CreateWalkthrough(&w))
+	photo := newPhoto(t, store, "roof", "image/jpeg")
+	pdf := newPhoto(t, store, "manual", "application/pdf")
+
+	require.Error(t, store.AddWalkthroughItem(&WalkthroughItem{WalkthroughID: w.ID, DocumentID: pdf.ID}))
+	item := WalkthroughItem{WalkthroughID: w.ID, DocumentID: photo.ID, Area: " Roof "}
+	require.NoError(t, store.AddWalkthroughItem(&item))
+
+	got, err := store.GetWalkthrough(w.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Items, 1)
+	assert.Equal(t, "Roof", got.Items[0].Area)
+	assert.Equal(t, "roof", got.Items[0].Document.Title)
+	assert.Empty(t, got.Items[0].Document.Data, "file bytes aren't loaded")
+
+	// A deleted document drops out of the walkthrough.
+	require.NoError(t, store.DeleteDocument(photo.ID))
+	got, err = store.GetWalkthrough(w.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.Items)
+
+	require.NoError(t, store.RemoveWalkthroughItem(item.ID))
+	assert.Error(t, store.RemoveWalkthroughItem(item.ID))
+}
+
+func TestCompareWalkthroughs(t *testing.T) {
+	store := newTestStore(t)
+	kitchen := Room{Name: "Kitchen"}
+	require.NoError(t, store.CreateRoom(&kitchen))
+
+	for _, year := range []int{2023, 2024, 2025} {
+		w := Walkthrough{Year: year}
+		require.NoError(t, store.CreateWalkthrough(&w))
+		require.NoError(t, store.AddWalkthroughItem(&WalkthroughItem{
+			WalkthroughID: w.ID,
+			DocumentID:    newPhoto(t, store, "kitchen", "image/jpeg").ID,
+			RoomID:        &kitchen.ID,
+		}))
+		require.NoError(t, store.AddWalkthroughItem(&WalkthroughItem{
+			WalkthroughID: w.ID,
+			DocumentID:    newPhoto(t, store, "deck", "video/mp4").ID,
+			Area:          "deck",
+		}))
+	}
+	// A differently-cased area still lines up.
+	var w2025 Walkthrough
+	require.NoError(t, store.db.Where(ColYear+" = ?", 2025).First(&w2025).Error)
+	require.NoError(t, store.AddWalkthroughItem(&WalkthroughItem{
+		WalkthroughID: w2025.ID,
+		DocumentID:    newPhoto(t, store, "deck2", "image/png").ID,
+		Area:          "Deck",
+	}))
+
+	rows, err := store.CompareWalkthroughs([]int{2023, 2025})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "deck", rows[0].Area)
+	assert.Len(t, rows[0].ByYear[2023], 1)
+	assert.Len(t, rows[0].ByYear[2025], 2)
+	assert.NotContains(t, rows[0].ByYear, 2024)
+	assert.Equal(t, "Kitchen", rows[1].Area)
+}
@@ -0,0 +1,167 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Warranty is a warranty on something in the house beyond the date an
+// appliance carries: an extended plan, a home warranty, a roofer's
+// workmanship guarantee. It covers an appliance or a project, or the
+// house as a whole when it names neither. DocumentID is the document
+// holding the policy.
+type Warranty struct {
+	ID      uint  `gorm:"primaryKey"`
+	HouseID *uint `gorm:"index"`
+	// Provider is who stands behind the warranty: the manufacturer, a
+	// warranty company, a contractor.
+	Provider     string
+	PolicyNumber string
+	// Coverage says what's covered, in the provider's words.
+	Coverage    string
+	StartsOn    *time.Time
+	ExpiresOn   time.Time `gorm:"index"`
+	ApplianceID *uint     `gorm:"index"`
+	Appliance   Appliance `gorm:"constraint:OnDelete:SET NULL;"`
+	ProjectID   *uint     `gorm:"index"`
+	Project     Project   `gorm:"constraint:OnDelete:SET NULL;"`
+	DocumentID  *uint
+	Notes       string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Version     int            `gorm:"not null;default:1"`
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+// Title names the warranty by what it covers, "Dishwasher warranty", or
+// by its provider when it covers the house as a whole.
+func (w Warranty) Title() string {
+	switch {
+	case w.ApplianceID != nil && w.Appliance.Name != "":
+		return w.Appliance.Name + " warranty"
+	case w.ProjectID != nil && w.Project.Title != "":
+		return w.Project.Title + " warranty"
+	case w.Provider != "":
+		return w.Provider + " warranty"
+	}
+	return "Home warranty"
+}
+
+func preloadWarranty(db *gorm.DB) *gorm.DB {
+	unscoped := func(q *gorm.DB) *gorm.DB { return q.Unscoped() }
+	return db.Preload("Appliance", unscoped).Preload("Project", unscoped)
+}
+
+// ListWarranties returns the current house's warranties, those ending
+// soonest first.
+func (s *Store) ListWarranties(includeDeleted bool) ([]Warranty, error) {
+	var items []Warranty
+	db := preloadWarranty(s.db).Scopes(s.inHouse("warranties")).
+		Order("expires_on asc, " + ColID + " asc")
+	if includeDeleted {
+		db = db.Unscoped()
+	}
+	return items, db.Find(&items).Error
+}
+
+func (s *Store) GetWarranty(id uint) (Warranty, error) {
+	var item Warranty
+	err := preloadWarranty(s.db).First(&item, id).Error
+	return item, err
+}
+
+func (s *Store) CreateWarranty(item *Warranty) error {
+	if err := validateWarranty(item); err != nil {
+		return err
+	}
+	return s.db.Create(item).Error
+}
+
+func (s *Store) UpdateWarranty(item Warranty) error {
+	if err := validateWarranty(&item); err != nil {
+		return err
+	}
+	return s.updateByID(&Warranty{}, item.ID, item)
+}
+
+func (s *Store) DeleteWarranty(id uint) error {
+	return s.softDelete(&Warranty{}, DeletionEntityWarranty, id)
+}
+
+func (s *Store) RestoreWarranty(id uint) error {
+	return s.restoreEntity(&Warranty{}, DeletionEntityWarranty, id)
+}
+
+// ListExpiringWarranties returns the current house's warranties ending
+// between (now - lookBack) and (now + within), soonest first. An
+// appliance's own warranty date counts too, as a Warranty with no ID
+// naming the appliance and its brand, unless a warranty on file for the
+// appliance ends the same day.
+func (s *Store) ListExpiringWarranties(
+	now time.Time,
+	lookBack, within time.Duration,
+) ([]Warranty, error) {
+	from := now.Add(-lookBack)
+	to := now.Add(within)
+	var out []Warranty
+	err := preloadWarranty(s.db).
+		Where("expires_on BETWEEN ? AND ?", from, to).
+		Scopes(s.inHouse("warranties")).
+		Find(&out).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var appliances []Appliance
+	err = s.db.
+		Where(ColWarrantyExpiry+" IS NOT NULL AND "+ColWarrantyExpiry+" BETWEEN ? AND ?", from, to).
+		Scopes(s.inHouse("appliances")).
+		Find(&appliances).Error
+	if err != nil {
+		return nil, err
+	}
+	onFile := func(a Appliance) bool {
+		return slices.ContainsFunc(out, func(w Warranty) bool {
+			return w.ApplianceID != nil && *w.ApplianceID == a.ID &&
+				w.ExpiresOn.Format(time.DateOnly) == a.WarrantyExpiry.Format(time.DateOnly)
+		})
+	}
+	for _, a := range appliances {
+		if onFile(a) {
+			continue
+		}
+		id := a.ID
+		out = append(out, Warranty{
+			HouseID: a.HouseID, Provider: a.Brand, ExpiresOn: *a.WarrantyExpiry,
+			ApplianceID: &id, Appliance: a,
+		})
+	}
+	slices.SortStableFunc(out, func(a, b Warranty) int {
+		return cmp.Or(a.ExpiresOn.Compare(b.ExpiresOn), cmp.Compare(a.Title(), b.Title()))
+	})
+	return out, nil
+}
+
+func validateWarranty(w *Warranty) error {
+	w.Provider = strings.TrimSpace(w.Provider)
+	w.PolicyNumber = strings.TrimSpace(w.PolicyNumber)
+	switch {
+	case w.Provider == "":
+		return fmt.Errorf("a warranty needs a provider")
+	case w.ExpiresOn.IsZero():
+		return fmt.Errorf("a warranty needs an expiry date")
+	case w.StartsOn != nil && w.StartsOn.After(w.ExpiresOn):
+		return fmt.Errorf("a warranty can't start after it expires")
+	case w.ApplianceID != nil && w.ProjectID != nil:
+		return fmt.Errorf("a warranty covers an appliance or a project, not both")
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarranties(t *testing.T) {
+	store := newTestStore(t)
+	now := time.Date(2026, 2, 8, 0, 0, 0, 0, time.UTC)
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 0, 0, 0, 0, time.UTC) }
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	dw := Appliance{Name: "Dishwasher", Brand: "Bosch", WarrantyExpiry: ptr(day(3, 1))}
+	require.NoError(t, store.CreateAppliance(&dw))
+	fridge := Appliance{Name: "Fridge", Brand: "LG", WarrantyExpiry: ptr(day(4, 1))}
+	require.NoError(t, store.CreateAppliance(&fridge))
+	roof := Project{Title: "New roof", ProjectTypeID: 1, Status: ProjectStatusCompleted}
+	require.NoError(t, store.CreateProject(&roof))
+
+	require.ErrorContains(t, store.CreateWarranty(&Warranty{ExpiresOn: day(5, 1)}), "needs a provider")
+	require.ErrorContains(t, store.CreateWarranty(&Warranty{Provider: "Acme"}), "needs an expiry date")
+	require.ErrorContains(t, store.CreateWarranty(&Warranty{
+		Provider: "Acme", StartsOn: ptr(day(6, 1)), ExpiresOn: day(5, 1),
+	}), "can't start after it expires")
+	require.ErrorContains(t, store.CreateWarranty(&Warranty{
+		Provider: "Acme", ExpiresOn: day(5, 1), ApplianceID: &dw.ID, ProjectID: &roof.ID,
+	}), "not both")
+
+	// The fridge's extended plan ends the day its own warranty does, so
+	// the appliance's date isn't listed twice.
+	plan := Warranty{Provider: "Asurion", PolicyNumber: "P-9", ExpiresOn: day(4, 1), ApplianceID: &fridge.ID}
+	require.NoError(t, store.CreateWarranty(&plan))
+	workmanship := Warranty{Provider: "Top Roofing", ExpiresOn: day(2, 20), ProjectID: &roof.ID}
+	require.NoError(t, store.CreateWarranty(&workmanship))
+	home := Warranty{Provider: "American Home Shield", StartsOn: ptr(day(1, 1)), ExpiresOn: day(12, 31)}
+	require.NoError(t, store.CreateWarranty(&home))
+
+	got, err := store.ListExpiringWarranties(now, 0, 60*24*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	assert.Equal(t, "New roof warranty", got[0].Title())
+	assert.Equal(t, "Dishwasher warranty", got[1].Title())
+	assert.Zero(t, got[1].ID, "an appliance's own warranty date")
+	assert.Equal(t, "Bosch", got[1].Provider)
+	assert.Equal(t, plan.ID, got[2].ID)
+	assert.Equal(t, "Fridge warranty", got[2].Title())
+
+	all, err := store.ListWarranties(false)
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, "American Home Shield warranty", all[2].Title())
+
+	require.NoError(t, store.DeleteWarranty(plan.ID))
+	got, err = store.ListExpiringWarranties(now, 0, 60*24*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	assert.Zero(t, got[2].ID, "the fridge's own date is back once the plan is gone")
+	require.NoError(t, store.RestoreWarranty(plan.ID))
+
+	plan.Coverage = "Parts and labor"
+	require.NoError(t, store.UpdateWarranty(plan))
+	reloaded, err := store.GetWarranty(plan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Parts and labor", reloaded.Coverage)
+}
@@ -0,0 +1,277 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Water quality metrics reported in threshold alerts.
+const (
+	WaterMetricHardness = "hardness"
+	WaterMetricLead     = "lead"
+	WaterMetricPH       = "ph"
+)
+
+// WaterTest is one water quality measurement, from a home test kit or a lab.
+// Measurements are optional so partial panels (e.g. a lead-only test) can be
+// recorded. ApplianceID links the test to the filter or softener it checks.
+type WaterTest struct {
+	ID          uint  `gorm:"primaryKey"`
+	HouseID     *uint `gorm:"index"`
+	TestedAt    time.Time
+	Source      string
+	ApplianceID *uint     `gorm:"index"`
+	Appliance   Appliance `gorm:"constraint:OnDelete:SET NULL;"`
+	HardnessGPG *float64
+	LeadPPB     *float64
+	PH          *float64
+	Lab         string
+	Notes       string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Version     int            `gorm:"not null;default:1"`
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+// WaterFilterChange records a filter cartridge or media replacement on a
+// water treatment appliance.
+type WaterFilterChange struct {
+	ID          uint      `gorm:"primaryKey"`
+	HouseID     *uint     `gorm:"index"`
+	ApplianceID uint      `gorm:"index"`
+	Appliance   Appliance `gorm:"constraint:OnDelete:CASCADE;"`
+	ChangedAt   time.Time
+	FilterModel string
+	CostCents   *int64
+	Notes       string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Version     int            `gorm:"not null;default:1"`
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+// WaterLimits are the thresholds a water test is checked against. A zero
+// limit disables that check.
+type WaterLimits struct {
+	MaxHardnessGPG float64
+	MaxLeadPPB     float64
+	MinPH          float64
+	MaxPH          float64
+}
+
+// DefaultWaterLimits returns commonly used thresholds: 7 gpg is the usual
+// "hard water" cutoff, 15 ppb is the EPA lead action level, and 6.5-8.5 is
+// the EPA secondary standard for pH.
+func DefaultWaterLimits() WaterLimits {
+	return WaterLimits{
+		MaxHardnessGPG: 7,
+		MaxLeadPPB:     15,
+		MinPH:          6.5,
+		MaxPH:          8.5,
+	}
+}
+
+// WaterExceedance describes a measurement outside its configured limit.
+type WaterExceedance struct {
+	Metric string
+	Value  float64
+	Limit  float64
+}
+
+// WaterAlert pairs a test with the limits it exceeded.
+type WaterAlert struct {
+	Test        WaterTest
+	Exceedances []WaterExceedance
+}
+
+// Check returns the measurements in t that fall outside the limits.
+func (l WaterLimits) Check(t WaterTest) []WaterExceedance {
+	var out []WaterExceedance
+	flag := func(metric string, value, limit float64) {
+		out = append(out, WaterExceedance{Metric: metric, Value: value, Limit: limit})
+	}
+	if t.HardnessGPG != nil && l.MaxHardnessGPG > 0 && *t.HardnessGPG > l.MaxHardnessGPG {
+		flag(WaterMetricHardness, *t.HardnessGPG, l.MaxHardnessGPG)
+	}
+	if t.LeadPPB != nil && l.MaxLeadPPB > 0 && *t.LeadPPB > l.MaxLeadPPB {
+		flag(WaterMetricLead, *t.LeadPPB, l.MaxLeadPPB)
+	}
+	if t.PH != nil {
+		if l.MinPH > 0 && *t.PH < l.MinPH {
+			flag(WaterMetricPH, *t.PH, l.MinPH)
+		}
+		if l.MaxPH > 0 && *t.PH > l.MaxPH {
+			flag(WaterMetricPH, *t.PH, l.MaxPH)
+		}
+	}
+	return out
+}
+
+// ListWaterTests returns tests oldest first, which is the order trend charts
+// plot them in.
+func (s *Store) ListWaterTests(includeDeleted bool) ([]WaterTest, error) {
+	var items []WaterTest
+	db := s.db.Scopes(s.inHouse("water_tests")).
+		Preload("Appliance", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Order(ColTestedAt + ", " + ColID)
+	if includeDeleted {
+		db = db.Unscoped()
+	}
+	return items, db.Find(&items).Error
+}
+
+func (s *Store) GetWaterTest(id uint) (WaterTest, error) {
+	var item WaterTest
+	err := s.db.Preload("Appliance").First(&item, id).Error
+	return item, err
+}
+
+func (s *Store) CreateWaterTest(item *WaterTest) error {
+	if err := validateWaterTest(item); err != nil {
+		return err
+	}
+	return s.db.Create(item).Error
+}
+
+func (s *Store) UpdateWaterTest(item WaterTest) error {
+	if err := validateWaterTest(&item); err != nil {
+		return err
+	}
+	return s.updateByID(&WaterTest{}, item.ID, item)
+}
+
+func (s *Store) DeleteWaterTest(id uint) error {
+	return s.softDelete(&WaterTest{}, DeletionEntityWaterTest, id)
+}
+
+func (s *Store) RestoreWaterTest(id uint) error {
+	var item WaterTest
+	if err := s.db.Unscoped().First(&item, id).Error; err != nil {
+		return err
+	}
+	if item.ApplianceID != nil {
+		if err := s.requireParentAlive(&Appliance{}, *item.ApplianceID); err != nil {
+			return parentRestoreError("appliance", err)
+		}
+	}
+	return s.restoreEntity(&WaterTest{}, DeletionEntityWaterTest, id)
+}
+
+// ListWaterAlerts checks the most recent test for each source against the
+// limits and returns those with at least one exceedance. Older tests are
+// ignored so a problem that has since been fixed stops alerting.
+func (s *Store) ListWaterAlerts(limits WaterLimits) ([]WaterAlert, error) {
+	var items []WaterTest
+	err := s.db.Scopes(s.inHouse("water_tests")).
+		Preload("Appliance", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Order(ColTestedAt + " desc, " + ColID + " desc").
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var alerts []WaterAlert
+	for _, item := range items {
+		key := strings.ToLower(item.Source)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if ex := limits.Check(item); len(ex) > 0 {
+			alerts = append(alerts, WaterAlert{Test: item, Exceedances: ex})
+		}
+	}
+	return alerts, nil
+}
+
+// ListWaterFilterChanges returns filter changes, newest first. A non-zero
+// applianceID restricts the list to that appliance.
+func (s *Store) ListWaterFilterChanges(
+	applianceID uint,
+	includeDeleted bool,
+) ([]WaterFilterChange, error) {
+	var items []WaterFilterChange
+	db := s.db.Scopes(s.inHouse("water_filter_changes")).
+		Preload("Appliance", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Order(ColChangedAt + " desc, " + ColID + " desc")
+	if applianceID != 0 {
+		db = db.Where(ColApplianceID+" = ?", applianceID)
+	}
+	if includeDeleted {
+		db = db.Unscoped()
+	}
+	return items, db.Find(&items).Error
+}
+
+func (s *Store) GetWaterFilterChange(id uint) (WaterFilterChange, error) {
+	var item WaterFilterChange
+	err := s.db.Preload("Appliance").First(&item, id).Error
+	return item, err
+}
+
+func (s *Store) CreateWaterFilterChange(item *WaterFilterChange) error {
+	if err := s.validateWaterFilterChange(item); err != nil {
+		return err
+	}
+	return s.db.Create(item).Error
+}
+
+func (s *Store) UpdateWaterFilterChange(item WaterFilterChange) error {
+	if err := s.validateWaterFilterChange(&item); err != nil {
+		return err
+	}
+	return s.updateByID(&WaterFilterChange{}, item.ID, item)
+}
+
+func (s *Store) DeleteWaterFilterChange(id uint) error {
+	return s.softDelete(&WaterFilterChange{}, DeletionEntityWaterFilter, id)
+}
+
+func (s *Store) RestoreWaterFilterChange(id uint) error {
+	var item WaterFilterChange
+	if err := s.db.Unscoped().First(&item, id).Error; err != nil {
+		return err
+	}
+	if err := s.requireParentAlive(&Appliance{}, item.ApplianceID); err != nil {
+		return parentRestoreError("appliance", err)
+	}
+	return s.restoreEntity(&WaterFilterChange{}, DeletionEntityWaterFilter, id)
+}
+
+func validateWaterTest(item *WaterTest) error {
+	if item.TestedAt.IsZero() {
+		return fmt.Errorf("test date is required")
+	}
+	if item.HardnessGPG == nil && item.LeadPPB == nil && item.PH == nil {
+		return fmt.Errorf("at least one measurement is required")
+	}
+	for _, v := range []*float64{item.HardnessGPG, item.LeadPPB} {
+		if v != nil && *v < 0 {
+			return fmt.Errorf("measurements must be non-negative, got %g", *v)
+		}
+	}
+	if item.PH != nil && (*item.PH < 0 || *item.PH > 14) {
+		return fmt.Errorf("pH must be between 0 and 14, got %g", *item.PH)
+	}
+	item.Source = strings.TrimSpace(item.Source)
+	return nil
+}
+
+func (s *Store) validateWaterFilterChange(item *WaterFilterChange) error {
+	if item.ApplianceID == 0 {
+		return fmt.Errorf("appliance is required")
+	}
+	if item.ChangedAt.IsZero() {
+		return fmt.Errorf("change date is required")
+	}
+	if err := s.requireParentAlive(&Appliance{}, item.ApplianceID); err != nil {
+		return fmt.Errorf("appliance not found or deleted")
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ptrFloat(v float64) *float64 { return &v }
+
+func TestWaterLimitsCheck(t *testing.T) {
+	limits := DefaultWaterLimits()
+	clean := WaterTest{HardnessGPG: ptrFloat(3), LeadPPB: ptrFloat(1), PH: ptrFloat(7.2)}
+	assert.Empty(t, limits.Check(clean))
+
+	bad := WaterTest{HardnessGPG: ptrFloat(12), LeadPPB: ptrFloat(20), PH: ptrFloat(6.1)}
+	ex := limits.Check(bad)
+	require.Len(t, ex, 3)
+	assert.Equal(t, WaterMetricHardness, ex[0].Metric)
+	assert.Equal(t, WaterMetricLead, ex[1].Metric)
+	assert.Equal(t, WaterMetricPH, ex[2].Metric)
+	assert.InDelta(t, 6.5, ex[2].Limit, 1e-9)
+
+	// A zero limit disables the check.
+	limits.MaxHardnessGPG = 0
+	assert.Len(t, limits.Check(bad), 2)
+}
+
+func TestWaterTestValidation(t *testing.T) {
+	store := newTestStore(t)
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	assert.Error(t, store.CreateWaterTest(&WaterTest{PH: ptrFloat(7)}))
+	assert.Error(t, store.CreateWaterTest(&WaterTest{TestedAt: now}))
+	assert.Error(t, store.CreateWaterTest(&WaterTest{TestedAt: now, PH: ptrFloat(15)}))
+	assert.Error(t, store.CreateWaterTest(&WaterTest{TestedAt: now, LeadPPB: ptrFloat(-1)}))
+	require.NoError(t, store.CreateWaterTest(&WaterTest{TestedAt: now, LeadPPB: ptrFloat(2)}))
+}
+
+func TestListWaterAlertsUsesLatestTestPerSource(t *testing.T) {
+	store := newTestStore(t)
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Kitchen was high in lead, but the newer test is clean.
+	require.NoError(t, store.CreateWaterTest(&WaterTest{
+		Source: "Kitchen", TestedAt: base, LeadPPB: ptrFloat(30),
+	}))
+	require.NoError(t, store.CreateWaterTest(&WaterTest{
+		Source: "kitchen", TestedAt: base.AddDate(0, 3, 0), LeadPPB: ptrFloat(2),
+	}))
+	well := WaterTest{Source: "Well", TestedAt: base, HardnessGPG: ptrFloat(14)}
+	require.NoError(t, store.CreateWaterTest(&well))
+
+	alerts, err := store.ListWaterAlerts(DefaultWaterLimits())
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, well.ID, alerts[0].Test.ID)
+	assert.Equal(t, WaterMetricHardness, alerts[0].Exceedances[0].Metric)
+
+	tests, err := store.ListWaterTests(false)
+	require.NoError(t, err)
+	require.Len(t, tests, 3)
+	assert.False(t, tests[0].TestedAt.After(tests[2].TestedAt), "oldest first for charting")
+}
+
+func TestWaterFilterChangeRequiresLiveAppliance(t *testing.T) {
+	store := newTestStore(t)
+	softener := Appliance{Name: "Water Softener"}
+	require.NoError(t, store.CreateAppliance(&softener))
+	changed := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Error(t, store.CreateWaterFilterChange(&WaterFilterChange{ChangedAt: changed}))
+	assert.Error(t, store.CreateWaterFilterChange(&WaterFilterChange{
+		ApplianceID: 9999, ChangedAt: changed,
+	}))
+
+	change := WaterFilterChange{
+		ApplianceID: softener.ID, ChangedAt: changed, FilterModel: "WS-10",
+	}
+	require.NoError(t, store.CreateWaterFilterChange(&change))
+
+	history, err := store.ListWaterFilterChanges(softener.ID, false)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "Water Softener", history[0].Appliance.Name)
+
+	require.ErrorContains(t, store.DeleteAppliance(softener.ID), "filter change")
+	require.NoError(t, store.DeleteWaterFilterChange(change.ID))
+	require.NoError(t, store.DeleteAppliance(softener.ID))
+	require.ErrorContains(t, store.RestoreWaterFilterChange(change.ID), "appliance is deleted")
+}
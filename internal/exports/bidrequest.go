@@ -0,0 +1,191 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package exports
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"gorm.io/gorm"
+)
+
+// Bid request formats.
+const (
+	BidFormatMarkdown = "markdown"
+	BidFormatText     = "text"
+	BidFormatHTML     = "html"
+)
+
+// BidFormats lists the formats BidRequest can write.
+func BidFormats() []string {
+	return []string{BidFormatMarkdown, BidFormatText, BidFormatHTML}
+}
+
+// bidAsks is what every vendor is asked to include, so the replies can be
+// compared line by line.
+var bidAsks = []string{
+	"Total price, with labor and materials broken out",
+	"When you could start, and how long the work will take",
+	"License and insurance details",
+	"Warranty on the work",
+}
+
+type bidRequest struct {
+	Title       string
+	Date        string
+	Fields      [][2]string
+	Description string
+	Attachments []bidAttachment
+	Asks        []string
+}
+
+type bidAttachment struct {
+	ID              uint
+	Title, FileName string
+	Photo           bool
+}
+
+// BidRequest writes a project's request for bids, the same for every
+// vendor it goes to: the job, where it is, the timeline, its photos and
+// attachments, and what a bid should cover. The budget is left out. format
+// is one of BidFormats; text suits an email body, and html is a page to
+// print or save as a PDF, with the photos shown from the API.
+func BidRequest(store *data.Store, projectID uint, format string, now time.Time) ([]byte, error) {
+	project, err := store.GetProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	house, err := store.HouseProfile()
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("load house profile: %w", err)
+	}
+	docs, err := store.ListDocumentsByEntity(data.DocumentEntityProject, projectID, false)
+	if err != nil {
+		return nil, fmt.Errorf("list documents: %w", err)
+	}
+
+	req := bidRequest{
+		Title:       project.Title,
+		Date:        now.Format("January 2, 2006"),
+		Description: strings.TrimSpace(project.Description),
+		Asks:        bidAsks,
+	}
+	field := func(label, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			req.Fields = append(req.Fields, [2]string{label, value})
+		}
+	}
+	field("Type of work", project.ProjectType.Name)
+	field("Address", strings.Join(nonBlank(
+		house.AddressLine1, house.AddressLine2, house.City, house.State, house.PostalCode,
+	), ", "))
+	if house.YearBuilt > 0 {
+		field("House built", fmt.Sprint(house.YearBuilt))
+	}
+	field("Hoping to start", dateOrBlank(project.StartDate))
+	field("Finished by", dateOrBlank(project.EndDate))
+	for _, d := range docs {
+		req.Attachments = append(req.Attachments, bidAttachment{
+			ID: d.ID, Title: d.Title, FileName: d.FileName, Photo: strings.HasPrefix(d.MIMEType, "image/"),
+		})
+	}
+
+	switch format {
+	case BidFormatMarkdown:
+		return req.markdown(), nil
+	case BidFormatText:
+		return req.text(), nil
+	case BidFormatHTML:
+		var buf bytes.Buffer
+		err := bidRequestTemplate.Execute(&buf, req)
+		return buf.Bytes(), err
+	}
+	return nil, fmt.Errorf("unknown format %q -- use one of %s", format, strings.Join(BidFormats(), ", "))
+}
+
+func (r bidRequest) markdown() []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Request for bid: %s\n\n_%s_\n\n", r.Title, r.Date)
+	for _, f := range r.Fields {
+		fmt.Fprintf(&b, "- **%s:** %s\n", f[0], f[1])
+	}
+	if r.Description != "" {
+		fmt.Fprintf(&b, "\n## The job\n\n%s\n", r.Description)
+	}
+	if len(r.Attachments) > 0 {
+		b.WriteString("\n## Photos and attachments\n\n")
+		for _, a := range r.Attachments {
+			fmt.Fprintf(&b, "- %s (%s)\n", a.Title, a.FileName)
+		}
+	}
+	b.WriteString("\n## Please include\n\n")
+	for i, ask := range r.Asks {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, ask)
+	}
+	return []byte(b.String())
+}
+
+func (r bidRequest) text() []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Hello,\n\nI'm collecting bids for the job below and would like one from you.\n\n")
+	fmt.Fprintf(&b, "%s\n\n", r.Title)
+	for _, f := range r.Fields {
+		fmt.Fprintf(&b, "%s: %s\n", f[0], f[1])
+	}
+	if r.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", r.Description)
+	}
+	if len(r.Attachments) > 0 {
+		b.WriteString("\nAttached:\n")
+		for _, a := range r.Attachments {
+			fmt.Fprintf(&b, "- %s\n", a.FileName)
+		}
+	}
+	b.WriteString("\nPlease include:\n")
+	for i, ask := range r.Asks {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, ask)
+	}
+	b.WriteString("\nThank you!\n")
+	return []byte(b.String())
+}
+
+// The page is served from /api/projects/{id}/bid-request, so photo links
+// are relative to that.
+var bidRequestTemplate = template.Must(template.New("bid").Parse(`<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Request for bid: {{.Title}}</title>
+<style>
+body { font: 15px/1.5 system-ui, sans-serif; max-width: 46rem; margin: 2rem auto; padding: 0 1rem; color: #222; }
+h1 { font-size: 1.6rem; margin-bottom: 0; }
+.date { color: #666; margin-top: 0.25rem; }
+dl { display: grid; grid-template-columns: max-content 1fr; gap: 0.25rem 1rem; }
+dt { font-weight: 600; }
+dd { margin: 0; }
+.photos { display: grid; grid-template-columns: repeat(auto-fill, minmax(14rem, 1fr)); gap: 0.75rem; }
+.photos img { width: 100%; border-radius: 4px; }
+figure { margin: 0; }
+figcaption { font-size: 0.85rem; color: #666; }
+</style>
+</head>
+<body>
+<h1>Request for bid: {{.Title}}</h1>
+<p class="date">{{.Date}}</p>
+{{with .Fields}}<dl>{{range .}}<dt>{{index . 0}}</dt><dd>{{index . 1}}</dd>{{end}}</dl>{{end}}
+{{with .Description}}<h2>The job</h2>
+<p style="white-space: pre-line">{{.}}</p>{{end}}
+{{with .Attachments}}<h2>Photos and attachments</h2>
+<div class="photos">{{range .}}{{if .Photo}}<figure><img src="../../documents/{{.ID}}/content" alt="{{.Title}}"><figcaption>{{.Title}}</figcaption></figure>{{end}}{{end}}</div>
+<ul>{{range .}}{{if not .Photo}}<li>{{.Title}} ({{.FileName}})</li>{{end}}{{end}}</ul>{{end}}
+<h2>Please include</h2>
+<ol>{{range .Asks}}<li>{{.}}</li>{{end}}</ol>
+</body>
+</html>
+`))
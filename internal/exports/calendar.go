@@ -0,0 +1,198 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package exports
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"gorm.io/gorm"
+)
+
+// calendarEvent is one all-day VEVENT. End is the last day, inclusive.
+type calendarEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	Category    string
+	Start, End  time.Time
+}
+
+// Calendar writes an iCalendar feed (RFC 5545) of the current house's
+// dates: maintenance coming due, project start and end dates, the
+// insurance renewal, warranties running out, appliances' own and those
+// on file, and rebates to apply for or chase. Events are all-day, and
+// their UIDs stay the same from one fetch to the next, so a subscribed
+// calendar moves an event rather than adding another.
+func Calendar(store *data.Store, now time.Time) ([]byte, error) {
+	house, err := store.HouseProfile()
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("load house profile: %w", err)
+	}
+	maintenance, err := store.ListMaintenance(false)
+	if err != nil {
+		return nil, fmt.Errorf("list maintenance: %w", err)
+	}
+	projects, err := store.ListProjects(false)
+	if err != nil {
+		return nil, fmt.Errorf("list projects: %w", err)
+	}
+	appliances, err := store.ListAppliances(false)
+	if err != nil {
+		return nil, fmt.Errorf("list appliances: %w", err)
+	}
+	warranties, err := store.ListWarranties(false)
+	if err != nil {
+		return nil, fmt.Errorf("list warranties: %w", err)
+	}
+	rebates, err := store.ListRebates(false)
+	if err != nil {
+		return nil, fmt.Errorf("list rebates: %w", err)
+	}
+
+	var events []calendarEvent
+	for _, m := range maintenance {
+		if m.NextDueAt == nil {
+			continue
+		}
+		desc := m.Notes
+		if m.Appliance.Name != "" {
+			desc = strings.TrimSpace("For the " + m.Appliance.Name + ".\n" + desc)
+		}
+		events = append(events, calendarEvent{
+			UID:         fmt.Sprintf("maintenance-%d@webcasa", m.ID),
+			Summary:     m.Name,
+			Description: desc,
+			Category:    "Maintenance",
+			Start:       *m.NextDueAt,
+		})
+	}
+	for _, p := range projects {
+		ev := calendarEvent{
+			UID:         fmt.Sprintf("project-%d@webcasa", p.ID),
+			Description: strings.TrimSpace("Status: " + p.Status + "\n" + p.Description),
+			Category:    "Project",
+		}
+		switch {
+		case p.StartDate != nil && p.EndDate != nil && !p.EndDate.Before(*p.StartDate):
+			ev.Summary, ev.Start, ev.End = p.Title, *p.StartDate, *p.EndDate
+		case p.StartDate != nil:
+			ev.Summary, ev.Start = p.Title+" starts", *p.StartDate
+		case p.EndDate != nil:
+			ev.Summary, ev.Start = p.Title+" finishes", *p.EndDate
+		default:
+			continue
+		}
+		events = append(events, ev)
+	}
+	if house.InsuranceRenewal != nil {
+		events = append(events, calendarEvent{
+			UID:         fmt.Sprintf("insurance-%d@webcasa", house.ID),
+			Summary:     "Homeowner's insurance renews",
+			Description: strings.TrimSpace(house.InsuranceCarrier + " " + house.InsurancePolicy),
+			Category:    "Insurance",
+			Start:       *house.InsuranceRenewal,
+		})
+	}
+	for _, a := range appliances {
+		if a.WarrantyExpiry == nil {
+			continue
+		}
+		events = append(events, calendarEvent{
+			UID:         fmt.Sprintf("warranty-%d@webcasa", a.ID),
+			Summary:     a.Name + " warranty ends",
+			Description: strings.TrimSpace(a.Brand + " " + a.ModelNumber),
+			Category:    "Warranty",
+			Start:       *a.WarrantyExpiry,
+		})
+	}
+	for _, w := range warranties {
+		events = append(events, calendarEvent{
+			UID:         fmt.Sprintf("warranty-policy-%d@webcasa", w.ID),
+			Summary:     w.Title() + " ends",
+			Description: strings.TrimSpace(w.Provider + " " + w.PolicyNumber),
+			Category:    "Warranty",
+			Start:       w.ExpiresOn,
+		})
+	}
+	for _, rb := range rebates {
+		due := rb.DueOn()
+		if due == nil {
+			continue
+		}
+		events = append(events, calendarEvent{
+			UID:         fmt.Sprintf("rebate-%d@webcasa", rb.ID),
+			Summary:     rb.NextStep(),
+			Description: strings.TrimSpace(rb.Provider + "\n" + rb.Notes),
+			Category:    "Rebate",
+			Start:       *due,
+		})
+	}
+
+	name := "webcasa"
+	if house.Nickname != "" {
+		name = house.Nickname
+	}
+	var b strings.Builder
+	line := func(s string) { b.WriteString(foldICal(s)) }
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//webcasa//webcasa//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:" + escapeICal(name))
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, ev := range events {
+		end := ev.End
+		if end.IsZero() {
+			end = ev.Start
+		}
+		line("BEGIN:VEVENT")
+		line("UID:" + ev.UID)
+		line("DTSTAMP:" + stamp)
+		line("DTSTART;VALUE=DATE:" + ev.Start.Format("20060102"))
+		// DTEND is exclusive.
+		line("DTEND;VALUE=DATE:" + end.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY:" + escapeICal(ev.Summary))
+		if ev.Description != "" {
+			line("DESCRIPTION:" + escapeICal(ev.Description))
+		}
+		line("CATEGORIES:" + escapeICal(ev.Category))
+		line("TRANSP:TRANSPARENT")
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return []byte(b.String()), nil
+}
+
+// escapeICal escapes a TEXT value.
+func escapeICal(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`,
+	).Replace(s)
+}
+
+// foldICal ends a content line with CRLF, folding it so that no line is
+// longer than 75 octets. Folds don't split a UTF-8 character.
+func foldICal(s string) string {
+	var b strings.Builder
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		// Continuation lines start with the space.
+		limit = 74
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+	return b.String()
+}
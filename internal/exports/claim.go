@@ -0,0 +1,199 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package exports
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"gorm.io/gorm"
+)
+
+// ClaimLetter writes a warranty claim's letter to the manufacturer, ready
+// to edit and send: the appliance's make, model and serial, when it was
+// bought, the problem, and what's enclosed. Blanks the house doesn't know
+// are left as [brackets] to fill in.
+func ClaimLetter(store *data.Store, claimID uint, now time.Time) ([]byte, error) {
+	claim, err := store.GetWarrantyClaim(claimID)
+	if err != nil {
+		return nil, err
+	}
+	enclosed, err := claimEnclosures(store, claim, now)
+	if err != nil {
+		return nil, err
+	}
+	return claimLetter(store, claim, enclosed, now)
+}
+
+func claimLetter(store *data.Store, claim data.WarrantyClaim, enclosed []claimEnclosure, now time.Time) ([]byte, error) {
+	house, err := store.HouseProfile()
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("load house profile: %w", err)
+	}
+	a := claim.Appliance
+	or := func(s, blank string) string {
+		if s = strings.TrimSpace(s); s != "" {
+			return s
+		}
+		return "[" + blank + "]"
+	}
+
+	var b strings.Builder
+	if addr := nonBlank(house.AddressLine1, house.AddressLine2,
+		strings.Join(nonBlank(house.City, house.State, house.PostalCode), " ")); len(addr) > 0 {
+		fmt.Fprintf(&b, "%s\n\n", strings.Join(addr, "\n"))
+	}
+	fmt.Fprintf(&b, "%s\n\n", now.Format("January 2, 2006"))
+	fmt.Fprintf(&b, "%s warranty claims\n\n", or(a.Brand, "Manufacturer"))
+	subject := "Warranty claim"
+	if claim.ClaimNumber != "" {
+		subject += ", reference " + claim.ClaimNumber
+	}
+	fmt.Fprintf(&b, "Re: %s -- %s\n\n", subject, a.Name)
+	b.WriteString("To whom it may concern,\n\n")
+	b.WriteString("I'm writing to make a claim under the warranty on the appliance below.\n\n")
+	fmt.Fprintf(&b, "Appliance:     %s\n", a.Name)
+	fmt.Fprintf(&b, "Brand:         %s\n", or(a.Brand, "brand"))
+	fmt.Fprintf(&b, "Model number:  %s\n", or(a.ModelNumber, "model number"))
+	fmt.Fprintf(&b, "Serial number: %s\n", or(a.SerialNumber, "serial number"))
+	fmt.Fprintf(&b, "Purchased:     %s\n", or(dateOrBlank(a.PurchaseDate), "purchase date"))
+	if a.WarrantyExpiry != nil {
+		fmt.Fprintf(&b, "Warranty ends: %s\n", a.WarrantyExpiry.Format(time.DateOnly))
+	}
+	fmt.Fprintf(&b, "\nThe problem:\n\n%s\n\n", claim.Problem)
+	b.WriteString("I'd be grateful if you would repair or replace it under the warranty, " +
+		"and let me know what you need from me to move the claim along.\n")
+	if len(enclosed) > 0 {
+		b.WriteString("\nEnclosed:\n")
+		for _, e := range enclosed {
+			if e.what != "" {
+				fmt.Fprintf(&b, "- %s\n", e.what)
+			}
+		}
+	}
+	b.WriteString("\nThank you,\n\n[Your name]\n[Phone or email]\n")
+	return []byte(b.String()), nil
+}
+
+// ClaimPacket writes a zip of everything a warranty claim goes out with:
+// the letter, the receipt and warranty documents, and the appliance's
+// service history as a PDF and a CSV.
+func ClaimPacket(store *data.Store, claimID uint, w io.Writer, now time.Time) error {
+	claim, err := store.GetWarrantyClaim(claimID)
+	if err != nil {
+		return err
+	}
+	enclosed, err := claimEnclosures(store, claim, now)
+	if err != nil {
+		return err
+	}
+	letter, err := claimLetter(store, claim, enclosed, now)
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(w)
+	if err := writeZipFile(zw, "claim-letter.txt", letter, now); err != nil {
+		return err
+	}
+	for _, e := range enclosed {
+		if err := writeZipFile(zw, e.name, e.body, now); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// ClaimPacketName is the file name for a claim's packet.
+func ClaimPacketName(claim data.WarrantyClaim, now time.Time) string {
+	return fmt.Sprintf("warranty-claim-%s-%s.zip", fileSlug(claim.Appliance.Name), now.Format(time.DateOnly))
+}
+
+// claimEnclosure is a file that goes with a claim, and how the letter
+// refers to it; files the letter doesn't mention have no what.
+type claimEnclosure struct {
+	name, what string
+	body       []byte
+}
+
+func claimEnclosures(store *data.Store, claim data.WarrantyClaim, now time.Time) ([]claimEnclosure, error) {
+	var out []claimEnclosure
+	for _, doc := range []struct {
+		id     *uint
+		prefix string
+		what   string
+	}{
+		{claim.PurchaseDocumentID, "purchase", "Proof of purchase"},
+		{claim.WarrantyDocumentID, "warranty", "Warranty"},
+	} {
+		if doc.id == nil {
+			continue
+		}
+		d, err := store.GetDocument(*doc.id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("load document %d: %w", *doc.id, err)
+		}
+		out = append(out, claimEnclosure{
+			name: doc.prefix + "-" + zipEntryName(d.FileName, d.Title),
+			what: fmt.Sprintf("%s (%s)", doc.what, d.Title),
+			body: d.Data,
+		})
+	}
+
+	h, err := ApplianceServiceHistory(store, claim.ApplianceID, now)
+	if err != nil {
+		return nil, fmt.Errorf("service history: %w", err)
+	}
+	if len(h.Visits) == 0 {
+		return out, nil
+	}
+	pdf, err := h.PDF()
+	if err != nil {
+		return nil, err
+	}
+	csv, err := h.CSV()
+	if err != nil {
+		return nil, err
+	}
+	return append(out,
+		claimEnclosure{name: "service-history.pdf", what: fmt.Sprintf("Service history (%d visit(s))", len(h.Visits)), body: pdf},
+		claimEnclosure{name: "service-history.csv", body: csv},
+	), nil
+}
+
+// unsafeZipName matches runs of characters kept out of zip entry names.
+var unsafeZipName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// zipEntryName makes a document's file name safe to put in a zip,
+// falling back to its title.
+func zipEntryName(name, fallback string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	if name == "." || name == "/" || name == "" {
+		name = fallback
+	}
+	if name = strings.Trim(unsafeZipName.ReplaceAllString(name, "_"), "_."); name == "" {
+		return "file"
+	}
+	return name
+}
+
+func writeZipFile(zw *zip.Writer, name string, body []byte, modified time.Time) error {
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return fmt.Errorf("add %s: %w", name, err)
+	}
+	if _, err := f.Write(body); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package exports
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimPacket(t *testing.T) {
+	store := newStore(t)
+	now := time.Date(2026, 4, 1, 10, 0, 0, 0, time.UTC)
+	bought := time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)
+	dw := data.Appliance{Name: "Dishwasher", Brand: "Bosch", ModelNumber: "SHX3", PurchaseDate: &bought}
+	require.NoError(t, store.CreateAppliance(&dw))
+	receipt := data.Document{
+		Title: "Receipt", FileName: "my receipt.pdf", EntityKind: data.DocumentEntityAppliance, EntityID: dw.ID,
+		MIMEType: "application/pdf", Data: []byte("%PDF receipt"), SizeBytes: 12,
+	}
+	require.NoError(t, store.CreateDocument(&receipt))
+	resolved := time.Date(2026, 2, 3, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, store.CreateIncident(&data.Incident{
+		Title: "Won't drain", Status: data.IncidentStatusInProgress, Severity: data.IncidentSeverityUrgent,
+		DateNoticed: resolved.AddDate(0, 0, -2), DateResolved: &resolved, ApplianceID: &dw.ID,
+	}))
+	claim := data.WarrantyClaim{ApplianceID: dw.ID, Problem: "Drain pump failed twice.", ClaimNumber: "B-17"}
+	require.NoError(t, store.CreateWarrantyClaim(&claim, now))
+
+	letter, err := ClaimLetter(store, claim.ID, now)
+	require.NoError(t, err)
+	for _, want := range []string{
+		"Bosch warranty claims",
+		"Re: Warranty claim, reference B-17 -- Dishwasher",
+		"Model number:  SHX3",
+		"Serial number: [serial number]",
+		"Purchased:     2025-06-10",
+		"Drain pump failed twice.",
+		"- Proof of purchase (Receipt)",
+		"- Service history (1 visit(s))",
+	} {
+		assert.Contains(t, string(letter), want)
+	}
+	assert.NotContains(t, string(letter), "Warranty (", "no warranty document on file")
+
+	var buf bytes.Buffer
+	require.NoError(t, ClaimPacket(store, claim.ID, &buf, now))
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	files := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		files[f.Name], err = io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+	}
+	assert.Equal(t, letter, files["claim-letter.txt"])
+	assert.Equal(t, receipt.Data, files["purchase-my_receipt.pdf"])
+	assert.Contains(t, files, "service-history.pdf")
+	assert.Contains(t, string(files["service-history.csv"]), "Won't drain")
+	got, err := store.GetWarrantyClaim(claim.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "warranty-claim-dishwasher-2026-04-01.zip", ClaimPacketName(got, now))
+}
@@ -0,0 +1,372 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package exports
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Destination receives finished exports.
+type Destination interface {
+	Deliver(ctx context.Context, a Artifact) error
+	String() string
+}
+
+// S3Settings holds the credentials for s3:// destinations. Endpoint is empty
+// for AWS itself or a base URL for an S3-compatible service.
+type S3Settings struct {
+	Endpoint        string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// SMTPSettings holds the mail server used by mailto: destinations.
+type SMTPSettings struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// ParseDestination turns a configured "to" value into a Destination: an
+// s3://bucket/prefix URL, a mailto:address, or else a local directory.
+func ParseDestination(to string, s3 S3Settings, mail SMTPSettings) (Destination, error) {
+	switch {
+	case strings.HasPrefix(to, "s3://"):
+		u, err := url.Parse(to)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid S3 destination %q -- expected s3://bucket/prefix", to)
+		}
+		return &S3Dest{Bucket: u.Host, Prefix: strings.Trim(u.Path, "/"), Settings: s3}, nil
+	case strings.HasPrefix(to, "mailto:"):
+		addr := strings.TrimPrefix(to, "mailto:")
+		if !strings.Contains(addr, "@") {
+			return nil, fmt.Errorf("invalid email destination %q", to)
+		}
+		if mail.Host == "" {
+			return nil, fmt.Errorf("email destination %q needs [smtp] host", to)
+		}
+		return &EmailDest{To: addr, Settings: mail}, nil
+	case strings.TrimSpace(to) == "":
+		return nil, fmt.Errorf("empty destination")
+	default:
+		return DirDest(to), nil
+	}
+}
+
+// DirDest writes exports into a local directory, creating it as needed.
+type DirDest string
+
+func (d DirDest) String() string { return string(d) }
+
+// Deliver writes the artifact through a temporary file so a reader never
+// sees a half-written export.
+func (d DirDest) Deliver(_ context.Context, a Artifact) error {
+	if err := os.MkdirAll(string(d), 0o700); err != nil {
+		return fmt.Errorf("create %s: %w", d, err)
+	}
+	final := filepath.Join(string(d), a.FileName)
+	tmp := final + ".part"
+	if err := os.WriteFile(tmp, a.Body, 0o600); err != nil {
+		return fmt.Errorf("write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, final); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("rename %s: %w", tmp, err)
+	}
+	return nil
+}
+
+// S3Dest uploads exports with a SigV4-signed PUT.
+type S3Dest struct {
+	Bucket   string
+	Prefix   string
+	Settings S3Settings
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+	// now is overridable in tests so signatures are reproducible.
+	now func() time.Time
+}
+
+func (d *S3Dest) String() string {
+	return "s3://" + strings.TrimSuffix(d.Bucket+"/"+d.Prefix, "/")
+}
+
+// Deliver uploads the artifact to <prefix>/<file name> in the bucket.
+func (d *S3Dest) Deliver(ctx context.Context, a Artifact) error {
+	resp, err := d.do(ctx, http.MethodPut, d.key(a.FileName), "", a.Body, a.ContentType)
+	if err != nil {
+		return fmt.Errorf("upload to %s: %w", d, err)
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+// key is the object key of a file under the prefix.
+func (d *S3Dest) key(name string) string {
+	if d.Prefix == "" {
+		return name
+	}
+	return d.Prefix + "/" + name
+}
+
+// do sends a signed request for key, which is empty for the bucket
+// itself, with query already in canonical order. It fails on any status
+// but a 2xx one, whose response the caller must close.
+func (d *S3Dest) do(ctx context.Context, method, key, query string, body []byte, contentType string) (*http.Response, error) {
+	if d.Settings.AccessKeyID == "" || d.Settings.SecretAccessKey == "" {
+		return nil, fmt.Errorf("S3 credentials are not configured")
+	}
+	region := d.Settings.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	// Path-style addressing works for AWS and every S3-compatible server.
+	endpoint := strings.TrimSuffix(d.Settings.Endpoint, "/")
+	if endpoint == "" {
+		endpoint = "https://s3." + region + ".amazonaws.com"
+	}
+	raw := endpoint + "/" + d.Bucket
+	if key != "" {
+		raw += "/" + escapePath(key)
+	}
+	if query != "" {
+		raw += "?" + query
+	}
+	target, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("build S3 URL: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	now := time.Now
+	if d.now != nil {
+		now = d.now
+	}
+	signS3(req, body, d.Settings.AccessKeyID, d.Settings.SecretAccessKey, region, now().UTC())
+
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		var msg bytes.Buffer
+		_, _ = msg.ReadFrom(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(msg.String()))
+	}
+	return resp, nil
+}
+
+// escapePath percent-encodes each segment of an object key the way SigV4
+// expects.
+func escapePath(key string) string {
+	parts := strings.Split(key, "/")
+	for i, p := range parts {
+		parts[i] = strings.ReplaceAll(url.PathEscape(p), "+", "%2B")
+	}
+	return strings.Join(parts, "/")
+}
+
+// signS3 adds AWS Signature Version 4 headers to an S3 request.
+func signS3(req *http.Request, body []byte, keyID, secret, region string, at time.Time) {
+	const service = "s3"
+	amzDate := at.Format("20060102T150405Z")
+	day := at.Format("20060102")
+	sum := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(sum[:])
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signed := []string{"content-type", "host", "x-amz-content-sha256", "x-amz-date"}
+	if req.Header.Get("Content-Type") == "" {
+		signed = signed[1:]
+	}
+	var canonHeaders strings.Builder
+	for _, h := range signed {
+		v := req.Header.Get(h)
+		if h == "host" {
+			v = req.URL.Host
+		}
+		canonHeaders.WriteString(h + ":" + strings.TrimSpace(v) + "\n")
+	}
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonHeaders.String(),
+		strings.Join(signed, ";"),
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + region + "/" + service + "/aws4_request"
+	canonSum := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonSum[:])
+
+	key := hmacSHA256([]byte("AWS4"+secret), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		keyID, scope, strings.Join(signed, ";"), signature))
+}
+
+func hmacSHA256(key []byte, msg string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(msg))
+	return h.Sum(nil)
+}
+
+// EmailDest mails exports as an attachment, or as the message itself when
+// they have a subject of their own.
+type EmailDest struct {
+	To       string
+	Settings SMTPSettings
+}
+
+func (d *EmailDest) String() string { return "mailto:" + d.To }
+
+// Deliver sends the artifact through the configured SMTP server, using
+// STARTTLS when the server offers it.
+func (d *EmailDest) Deliver(_ context.Context, a Artifact) error {
+	port := d.Settings.Port
+	if port == 0 {
+		port = 587
+	}
+	from := d.Settings.From
+	if from == "" {
+		from = d.Settings.Username
+	}
+	if from == "" {
+		return fmt.Errorf("set [smtp] from to send exports by email")
+	}
+	var msg []byte
+	var err error
+	if a.Subject != "" {
+		msg, err = BuildHTMLMessage(from, d.To, a.Subject, a.Text, a.Body, time.Now())
+	} else {
+		msg, err = BuildMessage(from, d.To, "webcasa export: "+a.FileName,
+			"Your scheduled webcasa export is attached.\n", a, time.Now())
+	}
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if d.Settings.Username != "" {
+		auth = smtp.PlainAuth("", d.Settings.Username, d.Settings.Password, d.Settings.Host)
+	}
+	addr := net.JoinHostPort(d.Settings.Host, strconv.Itoa(port))
+	if err := smtp.SendMail(addr, auth, from, []string{d.To}, msg); err != nil {
+		return fmt.Errorf("send to %s: %w", d.To, err)
+	}
+	return nil
+}
+
+// BuildHTMLMessage assembles a multipart/alternative email with a text
+// body and an HTML one.
+func BuildHTMLMessage(from, to, subject, text string, html []byte, at time.Time) ([]byte, error) {
+	var boundary [12]byte
+	if _, err := rand.Read(boundary[:]); err != nil {
+		return nil, err
+	}
+	b := "webcasa-" + hex.EncodeToString(boundary[:])
+
+	var m bytes.Buffer
+	fmt.Fprintf(&m, "From: %s\r\n", from)
+	fmt.Fprintf(&m, "To: %s\r\n", to)
+	fmt.Fprintf(&m, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&m, "Date: %s\r\n", at.Format(time.RFC1123Z))
+	m.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&m, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", b)
+
+	fmt.Fprintf(&m, "--%s\r\n", b)
+	m.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	m.WriteString(strings.ReplaceAll(text, "\n", "\r\n"))
+	m.WriteString("\r\n")
+
+	fmt.Fprintf(&m, "--%s\r\n", b)
+	m.WriteString("Content-Type: text/html; charset=utf-8\r\n")
+	m.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+	enc := base64.StdEncoding.EncodeToString(html)
+	for len(enc) > 76 {
+		m.WriteString(enc[:76] + "\r\n")
+		enc = enc[76:]
+	}
+	m.WriteString(enc + "\r\n")
+	fmt.Fprintf(&m, "--%s--\r\n", b)
+	return m.Bytes(), nil
+}
+
+// BuildMessage assembles a multipart/mixed email with a text body and the
+// artifact attached.
+func BuildMessage(from, to, subject, text string, a Artifact, at time.Time) ([]byte, error) {
+	var boundary [12]byte
+	if _, err := rand.Read(boundary[:]); err != nil {
+		return nil, err
+	}
+	b := "webcasa-" + hex.EncodeToString(boundary[:])
+
+	var m bytes.Buffer
+	fmt.Fprintf(&m, "From: %s\r\n", from)
+	fmt.Fprintf(&m, "To: %s\r\n", to)
+	fmt.Fprintf(&m, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&m, "Date: %s\r\n", at.Format(time.RFC1123Z))
+	m.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&m, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", b)
+
+	fmt.Fprintf(&m, "--%s\r\n", b)
+	m.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	m.WriteString(strings.ReplaceAll(text, "\n", "\r\n"))
+	m.WriteString("\r\n")
+
+	fmt.Fprintf(&m, "--%s\r\n", b)
+	fmt.Fprintf(&m, "Content-Type: %s\r\n", a.ContentType)
+	m.WriteString("Content-Transfer-Encoding: base64\r\n")
+	fmt.Fprintf(&m, "Content-Disposition: %s\r\n\r\n",
+		mime.FormatMediaType("attachment", map[string]string{"filename": a.FileName}))
+	enc := base64.StdEncoding.EncodeToString(a.Body)
+	for len(enc) > 76 {
+		m.WriteString(enc[:76] + "\r\n")
+		enc = enc[76:]
+	}
+	m.WriteString(enc + "\r\n")
+	fmt.Fprintf(&m, "--%s--\r\n", b)
+	return m.Bytes(), nil
+}
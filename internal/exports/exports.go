@@ -0,0 +1,144 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+// Package exports produces the periodic exports -- the house manual, the
+// emergency bundle, a spending CSV, the weekly summary, and database
+// snapshots -- and delivers them to a directory, an S3 bucket, or an email
+// inbox on a schedule. Snapshots can be fetched back from a directory or
+// bucket to restore.
+package exports
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/bundle"
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// Export kinds.
+const (
+	KindHouseManual     = "house_manual"
+	KindEmergencyBundle = "emergency_bundle"
+	KindSpendCSV        = "spend_csv"
+	KindWeeklySummary   = "weekly_summary"
+	KindBackup          = "backup"
+)
+
+// Kinds returns the supported export kinds.
+func Kinds() []string {
+	return []string{KindHouseManual, KindEmergencyBundle, KindSpendCSV, KindWeeklySummary, KindBackup}
+}
+
+// PassphraseEnv names the environment variable holding the passphrase for
+// scheduled emergency bundles.
+const PassphraseEnv = "WEBCASA_BUNDLE_PASSPHRASE"
+
+// Artifact is a rendered export ready for delivery.
+type Artifact struct {
+	FileName    string
+	ContentType string
+	Body        []byte
+
+	// Subject, when set, makes an HTML artifact a message of its own:
+	// email destinations send it as the body under this subject, with
+	// Text as the plain-text alternative, instead of attaching it.
+	Subject string
+	Text    string
+}
+
+// Build renders an export of the given kind. since is the start of the
+// period being reported on; only the spending CSV and the weekly summary
+// use it. The weekly summary is built without a budget; see BuildSummary.
+func Build(store *data.Store, kind string, since, now time.Time) (Artifact, error) {
+	stamp := now.Format(time.DateOnly)
+	switch kind {
+	case KindHouseManual:
+		var buf bytes.Buffer
+		if err := HouseManual(store, &buf, now); err != nil {
+			return Artifact{}, err
+		}
+		return Artifact{
+			FileName:    "house-manual-" + stamp + ".md",
+			ContentType: "text/markdown; charset=utf-8",
+			Body:        buf.Bytes(),
+		}, nil
+	case KindEmergencyBundle:
+		passphrase := os.Getenv(PassphraseEnv)
+		if passphrase == "" {
+			return Artifact{}, fmt.Errorf("set %s to encrypt scheduled emergency bundles", PassphraseEnv)
+		}
+		var buf bytes.Buffer
+		if _, err := bundle.Emergency(store, &buf, now); err != nil {
+			return Artifact{}, err
+		}
+		sealed, err := bundle.Seal(buf.Bytes(), passphrase)
+		if err != nil {
+			return Artifact{}, err
+		}
+		return Artifact{
+			FileName:    "webcasa-emergency-" + stamp + ".zip.enc",
+			ContentType: "application/octet-stream",
+			Body:        sealed,
+		}, nil
+	case KindSpendCSV:
+		body, err := SpendCSV(store, since, now)
+		if err != nil {
+			return Artifact{}, err
+		}
+		return Artifact{
+			FileName:    fmt.Sprintf("spend-%s-to-%s.csv", since.Format(time.DateOnly), stamp),
+			ContentType: "text/csv; charset=utf-8",
+			Body:        body,
+		}, nil
+	case KindWeeklySummary:
+		return BuildSummary(store, since, now, 0)
+	case KindBackup:
+		return Snapshot(store, now)
+	default:
+		return Artifact{}, fmt.Errorf("unknown export kind %q", kind)
+	}
+}
+
+// SpendCSV renders every cost recorded in [since, until) as CSV, gross
+// and out of pocket.
+func SpendCSV(store *data.Store, since, until time.Time) ([]byte, error) {
+	entries, err := store.ListSpending(since, until)
+	if err != nil {
+		return nil, fmt.Errorf("list spending: %w", err)
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"Date", "Category", "Description", "Vendor", "Amount",
+		"Insurance", "Warranty", "Rebate", "Out of pocket"})
+	var total data.SpendEntry
+	for _, e := range entries {
+		total.AmountCents += e.AmountCents
+		total.Funding = total.Funding.Add(e.Funding)
+		_ = w.Write(append([]string{
+			e.Date.Format(time.DateOnly), e.Category, e.Description, e.Vendor,
+		}, fundingAmounts(e)...))
+	}
+	_ = w.Write(append([]string{"", "total", "", ""}, fundingAmounts(total)...))
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// fundingAmounts is an entry's gross cost, what insurance, a warranty and
+// a rebate paid, and what's left, out of pocket.
+func fundingAmounts(e data.SpendEntry) []string {
+	return []string{
+		formatAmount(e.AmountCents), formatAmount(e.InsuranceCents), formatAmount(e.WarrantyCents),
+		formatAmount(e.RebateCents), formatAmount(e.OutOfPocketCents()),
+	}
+}
+
+// formatAmount renders cents as a plain decimal, which spreadsheets import
+// as a number.
+func formatAmount(cents int64) string {
+	return strconv.FormatFloat(float64(cents)/100, 'f', 2, 64)
+}
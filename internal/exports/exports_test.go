@@ -0,0 +1,322 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package exports
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/sched"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStore(t *testing.T) *data.Store {
+	t.Helper()
+	store, err := data.Open(filepath.Join(t.TempDir(), "exports.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	require.NoError(t, store.AutoMigrate())
+	require.NoError(t, store.SeedDefaults())
+	return store
+}
+
+func TestPeriodStart(t *testing.T) {
+	now := time.Date(2026, 3, 4, 9, 30, 0, 0, time.UTC)
+	weekly, err := sched.Parse("weekly")
+	require.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, -7), periodStart(weekly, now))
+
+	every, err := sched.Parse("36h")
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-36*time.Hour), periodStart(every, now))
+}
+
+func mustParse(t *testing.T, spec string) sched.Schedule {
+	t.Helper()
+	s, err := sched.Parse(spec)
+	require.NoError(t, err)
+	return s
+}
+
+func TestScheduledExportsDeliver(t *testing.T) {
+	store := newStore(t)
+	require.NoError(t, store.CreateHouseProfile(data.HouseProfile{Nickname: "Elm St", City: "Springfield"}))
+	cost := int64(89_900)
+	purchased := time.Date(2026, 2, 25, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, store.CreateAppliance(&data.Appliance{
+		Name: "Washer", Brand: "Acme", PurchaseDate: &purchased, CostCents: &cost,
+	}))
+
+	dir := t.TempDir()
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	s := sched.New(store)
+	s.Now = func() time.Time { return now }
+	for _, j := range []Job{
+		{Name: "spend", Kind: KindSpendCSV, Schedule: mustParse(t, "@every 168h"), To: DirDest(dir)},
+		{Name: "manual", Kind: KindHouseManual, Schedule: mustParse(t, "monthly"), To: DirDest(dir)},
+	} {
+		require.NoError(t, s.Add(j.SchedJob(store)))
+	}
+
+	require.NoError(t, s.RunNow(context.Background(), JobPrefix+"spend"))
+	spend, err := os.ReadFile(filepath.Join(dir, "spend-2026-02-22-to-2026-03-01.csv"))
+	require.NoError(t, err)
+	assert.Contains(t, string(spend), "2026-02-25,appliance,Washer,Acme,899.00")
+	assert.Contains(t, string(spend), ",total,,,899.00")
+
+	require.NoError(t, s.RunNow(context.Background(), JobPrefix+"manual"))
+	manual, err := os.ReadFile(filepath.Join(dir, "house-manual-2026-03-01.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(manual), "# Elm St -- House Manual")
+	assert.Contains(t, string(manual), "| Washer | Acme |")
+
+	// A month later both are due; the spend report picks up where the last
+	// one ended.
+	now = time.Date(2026, 4, 1, 8, 0, 0, 0, time.UTC)
+	s.RunDue(context.Background())
+	_, err = os.Stat(filepath.Join(dir, "house-manual-2026-04-01.md"))
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "spend-2026-03-01-to-2026-04-01.csv"))
+	require.NoError(t, err)
+}
+
+func TestEmergencyBundleNeedsPassphrase(t *testing.T) {
+	store := newStore(t)
+	t.Setenv(PassphraseEnv, "")
+	_, err := Build(store, KindEmergencyBundle, time.Time{}, time.Now())
+	assert.ErrorContains(t, err, PassphraseEnv)
+
+	t.Setenv(PassphraseEnv, "correct horse")
+	a, err := Build(store, KindEmergencyBundle, time.Time{}, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, "webcasa-emergency-2026-03-01.zip.enc", a.FileName)
+}
+
+func TestS3Upload(t *testing.T) {
+	var gotPath, gotAuth, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		gotPath = r.URL.EscapedPath()
+		gotAuth = r.Header.Get("Authorization")
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+	}))
+	defer srv.Close()
+
+	dest, err := ParseDestination("s3://backups/house/", S3Settings{
+		Endpoint: srv.URL, Region: "eu-west-1", AccessKeyID: "AKID", SecretAccessKey: "secret",
+	}, SMTPSettings{})
+	require.NoError(t, err)
+	assert.Equal(t, "s3://backups/house", dest.String())
+
+	s3 := dest.(*S3Dest)
+	s3.now = func() time.Time { return time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC) }
+	require.NoError(t, s3.Deliver(context.Background(), Artifact{
+		FileName: "spend 1.csv", ContentType: "text/csv", Body: []byte("a,b\n"),
+	}))
+	assert.Equal(t, "/backups/house/spend%201.csv", gotPath)
+	assert.Equal(t, "a,b\n", gotBody)
+	assert.True(t, strings.HasPrefix(gotAuth,
+		"AWS4-HMAC-SHA256 Credential=AKID/20260301/eu-west-1/s3/aws4_request, "+
+			"SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date, Signature="), gotAuth)
+
+	s3.Settings.SecretAccessKey = ""
+	assert.ErrorContains(t, s3.Deliver(context.Background(), Artifact{FileName: "x"}), "credentials")
+}
+
+func TestBuildMessage(t *testing.T) {
+	body := []byte(strings.Repeat("x", 100))
+	msg, err := BuildMessage("house@example.com", "me@example.com", "webcasa export", "See attached.\n",
+		Artifact{FileName: "spend.csv", ContentType: "text/csv", Body: body},
+		time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	s := string(msg)
+	assert.Contains(t, s, "To: me@example.com\r\n")
+	assert.Contains(t, s, "Content-Type: multipart/mixed; boundary=")
+	assert.Contains(t, s, "See attached.\r\n")
+	assert.Contains(t, s, "Content-Disposition: attachment; filename=spend.csv\r\n")
+	for _, line := range strings.Split(s, "\r\n") {
+		assert.LessOrEqual(t, len(line), 998)
+	}
+}
+
+func TestParseDestination(t *testing.T) {
+	d, err := ParseDestination("/srv/exports", S3Settings{}, SMTPSettings{})
+	require.NoError(t, err)
+	assert.IsType(t, DirDest(""), d)
+
+	_, err = ParseDestination("mailto:me@example.com", S3Settings{}, SMTPSettings{})
+	assert.ErrorContains(t, err, "[smtp] host")
+	_, err = ParseDestination("s3:///nobucket", S3Settings{}, SMTPSettings{})
+	assert.Error(t, err)
+	_, err = ParseDestination(" ", S3Settings{}, SMTPSettings{})
+	assert.Error(t, err)
+}
+
+func TestTableCSVAndJSON(t *testing.T) {
+	store := newStore(t)
+	cost := int64(89_900)
+	purchased := time.Date(2026, 2, 25, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, store.CreateAppliance(&data.Appliance{
+		Name: "Washer, front-load", PurchaseDate: &purchased, CostCents: &cost,
+	}))
+	require.NoError(t, store.CreateAppliance(&data.Appliance{Name: "Dryer"}))
+	table, err := store.DumpTable("appliances", data.DumpOptions{})
+	require.NoError(t, err)
+
+	out, err := TableCSV(table)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	require.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[0], "id,name,"))
+	assert.Contains(t, lines[1], `"Washer, front-load"`)
+	assert.Contains(t, lines[1], "2026-02-25 00:00:00")
+	assert.Contains(t, lines[1], "89900")
+
+	out, err = TablesJSON([]data.DumpedTable{table})
+	require.NoError(t, err)
+	var decoded map[string][]map[string]any
+	require.NoError(t, json.Unmarshal(out, &decoded))
+	require.Len(t, decoded["appliances"], 2)
+	assert.Equal(t, "Dryer", decoded["appliances"][1]["name"])
+	assert.Nil(t, decoded["appliances"][1]["purchase_date"])
+	assert.Less(t, strings.Index(string(out), `"id"`), strings.Index(string(out), `"name"`),
+		"columns keep their order")
+}
+
+func TestReadTablesBack(t *testing.T) {
+	store := newStore(t)
+	cost := int64(89_900)
+	purchased := time.Date(2026, 2, 25, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, store.CreateAppliance(&data.Appliance{
+		Name: "Washer, front-load", PurchaseDate: &purchased, CostCents: &cost,
+	}))
+	table, err := store.DumpTable("appliances", data.DumpOptions{})
+	require.NoError(t, err)
+
+	out, err := TableCSV(table)
+	require.NoError(t, err)
+	fromCSV, err := ReadTableCSV("appliances", bytes.NewReader(out))
+	require.NoError(t, err)
+	assert.Equal(t, table.Columns, fromCSV.Columns)
+	require.Len(t, fromCSV.Rows, 1)
+	col := slices.Index(fromCSV.Columns, "purchase_date")
+	assert.Equal(t, "2026-02-25 00:00:00", fromCSV.Rows[0][col])
+
+	out, err = TablesJSON([]data.DumpedTable{table})
+	require.NoError(t, err)
+	fromJSON, err := ReadTablesJSON(out)
+	require.NoError(t, err)
+	require.Len(t, fromJSON, 1)
+	assert.ElementsMatch(t, table.Columns, fromJSON[0].Columns)
+	col = slices.Index(fromJSON[0].Columns, "cost_cents")
+	assert.Equal(t, json.Number("89900"), fromJSON[0].Rows[0][col])
+
+	// Both load into another database as they were.
+	for _, tables := range [][]data.DumpedTable{{fromCSV}, fromJSON} {
+		dst := newStore(t)
+		_, err := dst.ImportTables(tables, data.ImportSkip)
+		require.NoError(t, err)
+		got, err := dst.GetAppliance(table.Rows[0][0].(uint))
+		require.NoError(t, err)
+		assert.Equal(t, "Washer, front-load", got.Name)
+		require.NotNil(t, got.CostCents)
+		assert.Equal(t, cost, *got.CostCents)
+		require.NotNil(t, got.PurchaseDate)
+		assert.True(t, purchased.Equal(*got.PurchaseDate))
+	}
+}
+
+func TestBidRequest(t *testing.T) {
+	store := newStore(t)
+	require.NoError(t, store.CreateHouseProfile(data.HouseProfile{AddressLine1: "12 Elm St", City: "Springfield"}))
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	budget := int64(500000)
+	project := data.Project{
+		Title: "Replace back deck", ProjectTypeID: types[0].ID, Status: data.ProjectStatusPlanned,
+		Description: "Tear out the rotted deck and build a 12x16 cedar one.", BudgetCents: &budget,
+	}
+	require.NoError(t, store.CreateProject(&project))
+	require.NoError(t, store.CreateDocument(&data.Document{
+		Title: "Rot by the stairs", FileName: "rot.jpg", MIMEType: "image/jpeg",
+		EntityKind: data.DocumentEntityProject, EntityID: project.ID, Data: []byte("jpeg"), SizeBytes: 4,
+	}))
+	now := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	md, err := BidRequest(store, project.ID, BidFormatMarkdown, now)
+	require.NoError(t, err)
+	assert.Contains(t, string(md), "# Request for bid: Replace back deck")
+	assert.Contains(t, string(md), "- **Address:** 12 Elm St, Springfield")
+	assert.Contains(t, string(md), "- Rot by the stairs (rot.jpg)")
+	assert.NotContains(t, string(md), "5,000", "the budget stays private")
+
+	text, err := BidRequest(store, project.ID, BidFormatText, now)
+	require.NoError(t, err)
+	assert.Contains(t, string(text), "Attached:\n- rot.jpg")
+
+	page, err := BidRequest(store, project.ID, BidFormatHTML, now)
+	require.NoError(t, err)
+	assert.Contains(t, string(page), `<img src="../../documents/`)
+
+	_, err = BidRequest(store, project.ID, "pdf", now)
+	assert.ErrorContains(t, err, "unknown format")
+}
+
+func TestCalendar(t *testing.T) {
+	store := newStore(t)
+	renewal := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, store.CreateHouseProfile(data.HouseProfile{
+		Nickname: "Elm St", InsuranceCarrier: "Acme Mutual", InsuranceRenewal: &renewal,
+	}))
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	start := time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 5, 8, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, store.CreateProject(&data.Project{
+		Title: "Paint, trim; and doors", ProjectTypeID: types[0].ID, Status: data.ProjectStatusPlanned,
+		StartDate: &start, EndDate: &end,
+	}))
+	categories, err := store.MaintenanceCategories()
+	require.NoError(t, err)
+	serviced := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	item := data.MaintenanceItem{
+		Name: "Replace furnace filter", CategoryID: categories[0].ID, LastServicedAt: &serviced, IntervalMonths: 3,
+		Notes: strings.Repeat("A long note that needs folding. ", 5),
+	}
+	require.NoError(t, store.CreateMaintenance(&item))
+	items, err := store.ListMaintenance(false)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.NotNil(t, items[0].NextDueAt)
+
+	ics, err := Calendar(store, time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	text := string(ics)
+	assert.True(t, strings.HasPrefix(text, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(text, "END:VCALENDAR\r\n"))
+	assert.Contains(t, text, "X-WR-CALNAME:Elm St\r\n")
+	assert.Contains(t, text, "DTSTAMP:20260401T120000Z\r\n")
+	assert.Contains(t, text, `SUMMARY:Paint\, trim\; and doors`+"\r\nDESCRIPTION:Status: planned\r\n")
+	assert.Contains(t, text, "DTSTART;VALUE=DATE:20260504\r\nDTEND;VALUE=DATE:20260509\r\n")
+	assert.Contains(t, text, "DTSTART;VALUE=DATE:"+items[0].NextDueAt.Format("20060102")+"\r\n")
+	assert.Contains(t, text, "SUMMARY:Homeowner's insurance renews\r\n")
+	assert.Contains(t, text, "DTSTART;VALUE=DATE:20260901\r\n")
+	assert.Equal(t, 3, strings.Count(text, "BEGIN:VEVENT"))
+	for _, line := range strings.Split(text, "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, "line %q isn't folded", line)
+	}
+}
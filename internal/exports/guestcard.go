@@ -0,0 +1,222 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package exports
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/llm"
+)
+
+// Guest card formats.
+const (
+	GuestCardMarkdown = "markdown"
+	GuestCardHTML     = "html"
+)
+
+// guestManualLimit is how much of the appliance's text documents a drafting
+// model is given, so a long manual doesn't crowd out the notes.
+const guestManualLimit = 12_000
+
+type guestCard struct {
+	Title  string
+	Where  string
+	Model  string
+	Steps  []string
+	Photos []bidAttachment
+}
+
+// GuestCard writes a one-page "how to use" card for an appliance from its
+// guest instructions: where it is, what it is, the steps, and its photos.
+// format is markdown or html; html is a page to print or save as a PDF,
+// with the photos shown from the API.
+func GuestCard(store *data.Store, applianceID uint, format string) ([]byte, error) {
+	a, err := store.GetAppliance(applianceID)
+	if err != nil {
+		return nil, err
+	}
+	card := guestCard{
+		Title: "How to use the " + a.Name,
+		Where: a.Location,
+		Model: strings.Join(nonBlank(a.Brand, a.ModelNumber), " "),
+		Steps: GuestSteps(a.GuestInstructions),
+	}
+	if a.RoomID != nil {
+		if room, err := store.GetRoom(*a.RoomID); err == nil {
+			card.Where = room.Name
+		}
+	}
+
+	switch format {
+	case GuestCardMarkdown:
+		return card.markdown(), nil
+	case GuestCardHTML:
+		docs, err := store.ListDocumentsByEntity(data.DocumentEntityAppliance, applianceID, false)
+		if err != nil {
+			return nil, fmt.Errorf("list documents: %w", err)
+		}
+		for _, d := range docs {
+			if strings.HasPrefix(d.MIMEType, "image/") && len(card.Photos) < 2 {
+				card.Photos = append(card.Photos, bidAttachment{ID: d.ID, Title: d.Title, FileName: d.FileName, Photo: true})
+			}
+		}
+		var buf bytes.Buffer
+		err = guestCardTemplate.Execute(&buf, card)
+		return buf.Bytes(), err
+	}
+	return nil, fmt.Errorf("unknown format %q -- use markdown or html", format)
+}
+
+// stepMarker is a list marker at the start of a line: "-", "*", "•",
+// "1." or "1)".
+var stepMarker = regexp.MustCompile(`^\s*(?:[-*•]|\d+[.)])\s+`)
+
+// GuestSteps splits guest instructions into steps, one per non-blank
+// line, without any list markers.
+func GuestSteps(instructions string) []string {
+	var steps []string
+	for _, line := range strings.Split(instructions, "\n") {
+		if line = strings.TrimSpace(stepMarker.ReplaceAllString(line, "")); line != "" {
+			steps = append(steps, line)
+		}
+	}
+	return steps
+}
+
+func (c guestCard) markdown() []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", c.Title)
+	if c.Where != "" || c.Model != "" {
+		fmt.Fprintf(&b, "_%s_\n\n", strings.Join(nonBlank(c.Where, c.Model), " · "))
+	}
+	if len(c.Steps) == 0 {
+		b.WriteString("No instructions yet.\n")
+	}
+	for i, step := range c.Steps {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, step)
+	}
+	return []byte(b.String())
+}
+
+// The page is served from /api/appliances/{id}/guest-card, so photo links
+// are relative to that.
+var guestCardTemplate = template.Must(template.New("guest").Parse(`<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font: 20px/1.5 system-ui, sans-serif; max-width: 40rem; margin: 2rem auto; padding: 0 1rem; color: #222; }
+h1 { font-size: 2rem; line-height: 1.2; margin-bottom: 0; }
+.where { color: #666; margin-top: 0.25rem; }
+ol { padding-left: 1.5rem; }
+li { margin: 0.6rem 0; }
+.empty { color: #666; font-style: italic; }
+.photos { display: flex; gap: 0.75rem; margin-top: 1.5rem; }
+.photos img { max-width: 100%; max-height: 14rem; border-radius: 4px; }
+@media print { body { margin: 0 auto; } }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{if or .Where .Model}}<p class="where">{{.Where}}{{if and .Where .Model}} · {{end}}{{.Model}}</p>{{end}}
+{{with .Steps}}<ol>{{range .}}<li>{{.}}</li>{{end}}</ol>{{else}}<p class="empty">No instructions yet.</p>{{end}}
+{{with .Photos}}<div class="photos">{{range .}}<img src="../../documents/{{.ID}}/content" alt="{{.Title}}">{{end}}</div>{{end}}
+</body>
+</html>
+`))
+
+// Where a guest-instructions draft came from.
+const (
+	GuestDraftNotes = "notes"
+	GuestDraftModel = "model"
+)
+
+// GuestDraft is a suggested set of guest instructions, to be reviewed
+// before it's saved.
+type GuestDraft struct {
+	Instructions string `json:"instructions"`
+	// Source is GuestDraftNotes or GuestDraftModel.
+	Source string `json:"source"`
+}
+
+// guestDraftPrompt asks a model for guest instructions.
+const guestDraftPrompt = `You write the short "how to use" card left out for house guests next to an appliance.
+Write 3 to 8 steps a visitor who has never seen this appliance can follow, one per line, in plain words.
+Cover the everyday use only: turning it on, the usual setting, turning it off, and the one mistake people make with it.
+Write only the steps: no title, no numbering, no markdown, and nothing about maintenance or repairs.
+Use only what the notes and manual say; leave out anything they don't cover.`
+
+// DraftGuestInstructions suggests guest instructions for an appliance from
+// its notes and the text of any plain-text documents attached to it, such
+// as a transcribed manual. With a model, the model writes them; without
+// one, the notes' lines become the steps, or a skeleton to fill in when
+// there are no notes.
+func DraftGuestInstructions(ctx context.Context, store *data.Store, applianceID uint, model *llm.Client) (GuestDraft, error) {
+	a, err := store.GetAppliance(applianceID)
+	if err != nil {
+		return GuestDraft{}, err
+	}
+	if model == nil {
+		return GuestDraft{Instructions: notesDraft(a.Notes), Source: GuestDraftNotes}, nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Appliance: %s\n", strings.Join(nonBlank(a.Name, a.Brand, a.ModelNumber), " "))
+	if notes := strings.TrimSpace(a.Notes); notes != "" {
+		fmt.Fprintf(&b, "\nOwner's notes:\n%s\n", notes)
+	}
+	docs, err := store.ListDocumentsByEntity(data.DocumentEntityAppliance, applianceID, false)
+	if err != nil {
+		return GuestDraft{}, fmt.Errorf("list documents: %w", err)
+	}
+	budget := guestManualLimit
+	for _, d := range docs {
+		if budget <= 0 || !strings.HasPrefix(d.MIMEType, "text/") {
+			continue
+		}
+		doc, err := store.GetDocument(d.ID)
+		if err != nil {
+			return GuestDraft{}, fmt.Errorf("load %s: %w", d.FileName, err)
+		}
+		text := strings.TrimSpace(string(doc.Data))
+		if !utf8.ValidString(text) {
+			continue
+		}
+		if len(text) > budget {
+			text = strings.ToValidUTF8(text[:budget], "")
+		}
+		budget -= len(text)
+		fmt.Fprintf(&b, "\nFrom %q:\n%s\n", d.Title, text)
+	}
+
+	answer, err := model.Complete(ctx, guestDraftPrompt, b.String())
+	if err != nil {
+		return GuestDraft{}, fmt.Errorf("draft guest instructions: %w", err)
+	}
+	return GuestDraft{Instructions: strings.Join(GuestSteps(answer), "\n"), Source: GuestDraftModel}, nil
+}
+
+// sentenceEnd splits a paragraph into sentences.
+var sentenceEnd = regexp.MustCompile(`([.!?])\s+`)
+
+// notesDraft turns notes into steps: one per line, or one per sentence
+// when the notes are a single paragraph.
+func notesDraft(notes string) string {
+	steps := GuestSteps(notes)
+	if len(steps) == 1 {
+		steps = GuestSteps(sentenceEnd.ReplaceAllString(steps[0], "$1\n"))
+	}
+	if len(steps) == 0 {
+		return "To turn it on:\nThe usual setting:\nTo turn it off:\nPlease don't:"
+	}
+	return strings.Join(steps, "\n")
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package exports
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuestCard(t *testing.T) {
+	store := newStore(t)
+	washer := data.Appliance{
+		Name: "Washer", Brand: "Speed Queen", ModelNumber: "TC5", Location: "Basement",
+		GuestInstructions: "1. Turn the dial to Normal.\n\n- Press Start <once>.\n",
+	}
+	require.NoError(t, store.CreateAppliance(&washer))
+
+	md, err := GuestCard(store, washer.ID, GuestCardMarkdown)
+	require.NoError(t, err)
+	assert.Equal(t, "# How to use the Washer\n\n_Basement · Speed Queen TC5_\n\n"+
+		"1. Turn the dial to Normal.\n2. Press Start <once>.\n", string(md))
+
+	page, err := GuestCard(store, washer.ID, GuestCardHTML)
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "<li>Press Start &lt;once&gt;.</li>")
+
+	_, err = GuestCard(store, washer.ID, "pdf")
+	require.ErrorContains(t, err, "unknown format")
+}
+
+func TestDraftGuestInstructions(t *testing.T) {
+	store := newStore(t)
+	thermostat := data.Appliance{Name: "Thermostat", Notes: "Press the leaf for eco mode. Hold the arrow for 3s to override. Don't touch the schedule."}
+	require.NoError(t, store.CreateAppliance(&thermostat))
+	manual := data.Document{
+		Title: "Quick start", FileName: "quick.txt", MIMEType: "text/plain",
+		EntityKind: data.DocumentEntityAppliance, EntityID: thermostat.ID, Data: []byte("Tap the ring to wake the display."),
+	}
+	manual.SizeBytes = int64(len(manual.Data))
+	require.NoError(t, store.CreateDocument(&manual))
+	blank := data.Appliance{Name: "Dryer"}
+	require.NoError(t, store.CreateAppliance(&blank))
+
+	draft, err := DraftGuestInstructions(context.Background(), store, thermostat.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, GuestDraft{
+		Instructions: "Press the leaf for eco mode.\nHold the arrow for 3s to override.\nDon't touch the schedule.",
+		Source:       GuestDraftNotes,
+	}, draft)
+	draft, err = DraftGuestInstructions(context.Background(), store, blank.ID, nil)
+	require.NoError(t, err)
+	assert.Contains(t, draft.Instructions, "To turn it on:")
+
+	var prompt string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct{ Messages []llm.Message }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		prompt = req.Messages[len(req.Messages)-1].Content
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"1. Tap the ring.\n2. Press the leaf."}}]}`))
+	}))
+	defer srv.Close()
+	draft, err = DraftGuestInstructions(context.Background(), store, thermostat.ID, &llm.Client{BaseURL: srv.URL})
+	require.NoError(t, err)
+	assert.Equal(t, GuestDraft{Instructions: "Tap the ring.\nPress the leaf.", Source: GuestDraftModel}, draft)
+	assert.True(t, strings.Contains(prompt, "Press the leaf for eco mode") && strings.Contains(prompt, "Tap the ring to wake"), prompt)
+}
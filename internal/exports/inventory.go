@@ -0,0 +1,170 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package exports
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"gorm.io/gorm"
+)
+
+// Home inventory formats.
+const (
+	InventoryCSV = "csv"
+	InventoryPDF = "pdf"
+)
+
+// HomeInventory is the house's valuables room by room, with what replacing
+// them all would cost, for an insurer.
+type HomeInventory struct {
+	House data.HouseProfile
+	Rooms []data.InventoryRoom
+	// ReplacementCents adds up the rooms' values and PurchaseCents what
+	// the items cost.
+	ReplacementCents int64
+	PurchaseCents    int64
+	Items            int
+	Now              time.Time
+}
+
+// Inventory gathers the current house's inventory by room.
+func Inventory(store *data.Store, now time.Time) (HomeInventory, error) {
+	inv := HomeInventory{Now: now}
+	var err error
+	inv.House, err = store.HouseProfile()
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return HomeInventory{}, fmt.Errorf("load house profile: %w", err)
+	}
+	if inv.Rooms, err = store.InventoryByRoom(); err != nil {
+		return HomeInventory{}, fmt.Errorf("list inventory: %w", err)
+	}
+	for _, r := range inv.Rooms {
+		inv.ReplacementCents += r.ReplacementCents
+		for _, it := range r.Items {
+			if it.PurchaseCents != nil {
+				inv.PurchaseCents += *it.PurchaseCents
+			}
+			inv.Items++
+		}
+	}
+	return inv, nil
+}
+
+// Artifact renders the inventory in format, InventoryCSV or InventoryPDF,
+// named for the day.
+func (inv HomeInventory) Artifact(format string) (Artifact, error) {
+	name := fmt.Sprintf("home-inventory-%s.%s", inv.Now.Format(time.DateOnly), format)
+	switch format {
+	case InventoryCSV:
+		body, err := inv.CSV()
+		return Artifact{FileName: name, ContentType: "text/csv; charset=utf-8", Body: body}, err
+	case InventoryPDF:
+		body, err := inv.PDF()
+		return Artifact{FileName: name, ContentType: "application/pdf", Body: body}, err
+	}
+	return Artifact{}, fmt.Errorf("unknown format %q -- use csv or pdf", format)
+}
+
+// CSV renders the inventory as a row per item, amounts as plain decimals.
+func (inv HomeInventory) CSV() ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{
+		"Room", "Item", "Category", "Brand", "Model", "Serial", "Purchased", "Purchase price", "Replacement value", "Notes",
+	})
+	amount := func(c *int64) string {
+		if c == nil {
+			return ""
+		}
+		return formatAmount(*c)
+	}
+	for _, r := range inv.Rooms {
+		for _, it := range r.Items {
+			_ = w.Write([]string{
+				r.Name, it.Name, it.Category, it.Brand, it.ModelNumber, it.SerialNumber,
+				dateOrBlank(it.PurchaseDate), amount(it.PurchaseCents), amount(it.ReplacementCents), it.Notes,
+			})
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// Columns of the PDF's table.
+const (
+	inventorySerialX   = pdfMargin + 200
+	inventoryPaidX     = pdfPageWidth - pdfMargin - 90
+	inventoryReplaceX  = pdfPageWidth - pdfMargin
+	inventoryNameWidth = inventorySerialX - pdfMargin - 8
+)
+
+// PDF renders the inventory for an insurer: a table of items under each
+// room, with the room's total, and the replacement value of everything at
+// the end.
+func (inv HomeInventory) PDF() ([]byte, error) {
+	title := "Home inventory"
+	if inv.House.Nickname != "" {
+		title += ": " + inv.House.Nickname
+	}
+	p := &pdfWriter{footer: title}
+	p.line(18, 18, pdfCell{X: pdfMargin, Text: title, Bold: true})
+	p.gap(4)
+	address := strings.Join(nonBlank(inv.House.AddressLine1, inv.House.AddressLine2,
+		strings.Join(nonBlank(inv.House.City, inv.House.State, inv.House.PostalCode), " ")), ", ")
+	insurer := inv.House.InsuranceCarrier
+	if inv.House.InsurancePolicy != "" {
+		insurer = strings.TrimSpace(insurer + " policy " + inv.House.InsurancePolicy)
+	}
+	for _, d := range [][2]string{
+		{"Address", address},
+		{"Insurer", insurer},
+		{"Generated", inv.Now.Format("January 2, 2006")},
+	} {
+		if d[1] != "" {
+			p.line(10, 14, pdfCell{X: pdfMargin, Text: d[0], Bold: true}, pdfCell{X: pdfMargin + 78, Text: d[1]})
+		}
+	}
+	p.gap(14)
+
+	if inv.Items == 0 {
+		p.line(10, 14, pdfCell{X: pdfMargin, Text: "No items recorded."})
+		return p.bytes()
+	}
+	for _, r := range inv.Rooms {
+		name := r.Name
+		if r.RoomID == nil {
+			name = "Not in a room"
+		}
+		p.line(12, 18, pdfCell{X: pdfMargin, Text: name, Bold: true})
+		p.line(9, 12,
+			pdfCell{X: pdfMargin, Text: "Item", Bold: true},
+			pdfCell{X: inventorySerialX, Text: "Serial", Bold: true},
+			pdfCell{X: inventoryPaidX, Text: "Paid", Bold: true, Right: true},
+			pdfCell{X: inventoryReplaceX, Text: "Replacement", Bold: true, Right: true})
+		p.rule()
+		for _, it := range r.Items {
+			item := strings.Join(nonBlank(it.Name, strings.Join(nonBlank(it.Brand, it.ModelNumber), " ")), " -- ")
+			p.line(10, 15,
+				pdfCell{X: pdfMargin, Text: item, Width: inventoryNameWidth},
+				pdfCell{X: inventorySerialX, Text: it.SerialNumber, Width: inventoryPaidX - inventorySerialX - 70},
+				pdfCell{X: inventoryPaidX, Text: centsOrBlank(it.PurchaseCents), Right: true},
+				pdfCell{X: inventoryReplaceX, Text: centsOrBlank(it.ReplacementCents), Right: true})
+		}
+		p.line(10, 15,
+			pdfCell{X: pdfMargin, Text: fmt.Sprintf("%d item(s)", len(r.Items))},
+			pdfCell{X: inventoryReplaceX, Text: data.FormatCents(r.ReplacementCents), Bold: true, Right: true})
+		p.gap(8)
+	}
+	p.rule()
+	p.line(11, 18,
+		pdfCell{X: pdfMargin, Text: fmt.Sprintf("Total replacement value, %d item(s)", inv.Items), Bold: true},
+		pdfCell{X: inventoryReplaceX, Text: data.FormatCents(inv.ReplacementCents), Bold: true, Right: true})
+	return p.bytes()
+}
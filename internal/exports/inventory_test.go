@@ -0,0 +1,62 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package exports
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/pdftext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInventory(t *testing.T) {
+	store := newStore(t)
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	cents := func(c int64) *int64 { return &c }
+	require.NoError(t, store.CreateHouseProfile(data.HouseProfile{
+		Nickname: "Elm Street", InsuranceCarrier: "Acme Mutual", InsurancePolicy: "HO-7",
+	}))
+	den := data.Room{Name: "Den"}
+	require.NoError(t, store.CreateRoom(&den))
+	bought := time.Date(2024, 11, 29, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, store.CreateInventoryItem(&data.InventoryItem{
+		Name: "TV", Category: "Electronics", Brand: "Sony", ModelNumber: "X90", SerialNumber: "S-1",
+		RoomID: &den.ID, PurchaseDate: &bought, PurchaseCents: cents(120_000), ReplacementCents: cents(90_000),
+	}))
+	require.NoError(t, store.CreateInventoryItem(&data.InventoryItem{Name: "Painting", ReplacementCents: cents(300_000)}))
+
+	inv, err := Inventory(store, now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, inv.Items)
+	assert.Equal(t, int64(390_000), inv.ReplacementCents)
+	assert.Equal(t, int64(120_000), inv.PurchaseCents)
+
+	csvOut, err := inv.Artifact(InventoryCSV)
+	require.NoError(t, err)
+	assert.Equal(t, "home-inventory-2026-03-01.csv", csvOut.FileName)
+	assert.Equal(t, "Room,Item,Category,Brand,Model,Serial,Purchased,Purchase price,Replacement value,Notes\n"+
+		"Den,TV,Electronics,Sony,X90,S-1,2024-11-29,1200.00,900.00,\n"+
+		",Painting,,,,,,,3000.00,\n", string(csvOut.Body))
+
+	pdfOut, err := inv.Artifact(InventoryPDF)
+	require.NoError(t, err)
+	pages, err := pdftext.Pages(pdfOut.Body)
+	require.NoError(t, err)
+	require.Len(t, pages, 1)
+	for _, want := range []string{
+		"Home inventory: Elm Street",
+		"Insurer Acme Mutual policy HO-7",
+		"TV -- Sony X90 S-1 $1,200.00 $900.00",
+		"Not in a room",
+		"Total replacement value, 2 item(s) $3,900.00",
+	} {
+		assert.Contains(t, pages[0], want)
+	}
+
+	_, err = inv.Artifact("docx")
+	assert.Error(t, err)
+}
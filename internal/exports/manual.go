@@ -0,0 +1,138 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package exports
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"gorm.io/gorm"
+)
+
+// HouseManual writes a Markdown "owner's manual" for the house: the
+// profile, rooms, appliances, the maintenance schedule, and who to call.
+func HouseManual(store *data.Store, w io.Writer, now time.Time) error {
+	profile, err := store.HouseProfile()
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("load house profile: %w", err)
+	}
+	rooms, err := store.ListRooms(false)
+	if err != nil {
+		return fmt.Errorf("list rooms: %w", err)
+	}
+	appliances, err := store.ListAppliances(false)
+	if err != nil {
+		return fmt.Errorf("list appliances: %w", err)
+	}
+	maintenance, err := store.ListMaintenance(false)
+	if err != nil {
+		return fmt.Errorf("list maintenance: %w", err)
+	}
+	vendors, err := store.ListVendors(false)
+	if err != nil {
+		return fmt.Errorf("list vendors: %w", err)
+	}
+
+	var b strings.Builder
+	title := "House Manual"
+	if profile.Nickname != "" {
+		title = profile.Nickname + " -- House Manual"
+	}
+	fmt.Fprintf(&b, "# %s\n\n_Generated %s_\n\n", title, now.Format("January 2, 2006"))
+
+	b.WriteString("## The house\n\n")
+	field := func(label, value string) {
+		if strings.TrimSpace(value) != "" {
+			fmt.Fprintf(&b, "- **%s:** %s\n", label, value)
+		}
+	}
+	field("Address", strings.TrimSpace(strings.Join([]string{
+		profile.AddressLine1, profile.AddressLine2, profile.City, profile.State, profile.PostalCode,
+	}, " ")))
+	if profile.YearBuilt > 0 {
+		field("Built", fmt.Sprint(profile.YearBuilt))
+	}
+	field("Foundation", profile.FoundationType)
+	field("Roof", profile.RoofType)
+	field("Exterior", profile.ExteriorType)
+	field("Wiring", profile.WiringType)
+	field("Heating", profile.HeatingType)
+	field("Cooling", profile.CoolingType)
+	field("Water", profile.WaterSource)
+	field("Sewer", profile.SewerType)
+	field("Insurance", strings.TrimSpace(profile.InsuranceCarrier+" "+profile.InsurancePolicy))
+	b.WriteString("\n")
+
+	if len(rooms) > 0 {
+		b.WriteString("## Rooms\n\n| Room | Level | Size |\n|---|---|---|\n")
+		for _, r := range rooms {
+			fmt.Fprintf(&b, "| %s | %s | %g' x %g' |\n", cell(r.Name), cell(r.Level), r.LengthFt, r.WidthFt)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(appliances) > 0 {
+		b.WriteString("## Appliances\n\n| Appliance | Brand / Model | Serial | Warranty |\n|---|---|---|---|\n")
+		for _, a := range appliances {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n",
+				cell(a.Name), cell(strings.TrimSpace(a.Brand+" "+a.ModelNumber)),
+				cell(a.SerialNumber), cell(dateOrBlank(a.WarrantyExpiry)))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(maintenance) > 0 {
+		b.WriteString("## Maintenance schedule\n\n| Task | Every | Last done | Next due |\n|---|---|---|---|\n")
+		for _, m := range maintenance {
+			every := "as needed"
+			if m.IntervalMonths > 0 {
+				every = fmt.Sprintf("%d mo", m.IntervalMonths)
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n",
+				cell(m.Name), every, cell(dateOrBlank(m.LastServicedAt)), dateOrBlank(m.NextDueAt))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(vendors) > 0 {
+		b.WriteString("## Who to call\n\n")
+		for _, v := range vendors {
+			contact := strings.Join(nonBlank(v.ContactName, v.Phone, v.Email), ", ")
+			if contact == "" {
+				fmt.Fprintf(&b, "- **%s**\n", v.Name)
+				continue
+			}
+			fmt.Fprintf(&b, "- **%s** -- %s\n", v.Name, contact)
+		}
+	}
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// cell escapes a value for a Markdown table cell.
+func cell(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", `\|`), "\n", " ")
+}
+
+func dateOrBlank(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.DateOnly)
+}
+
+func nonBlank(parts ...string) []string {
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
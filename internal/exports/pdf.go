@@ -0,0 +1,231 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package exports
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+)
+
+// US Letter, in points, with three-quarter-inch margins.
+const (
+	pdfPageWidth  = 612
+	pdfPageHeight = 792
+	pdfMargin     = 54
+)
+
+// pdfWriter lays plain text out in Helvetica on US Letter pages, which is
+// all a printable record needs, so there's no PDF library to carry. Text
+// is encoded as Windows-1252, the encoding the standard fonts use;
+// characters outside it print as "?".
+type pdfWriter struct {
+	pages []*bytes.Buffer
+	// y is the baseline of the last line written on the current page.
+	y float64
+	// footer, when set, is printed at the foot of every page with its
+	// number.
+	footer string
+}
+
+// pdfCell is text placed on a line: from X, or ending at X when Right.
+type pdfCell struct {
+	X     float64
+	Text  string
+	Bold  bool
+	Right bool
+	// Width, when set, cuts the text to fit.
+	Width float64
+}
+
+// line moves down by lead, starting a new page when the line wouldn't
+// fit, and writes cells there in size-point type.
+func (p *pdfWriter) line(size, lead float64, cells ...pdfCell) {
+	if len(p.pages) == 0 || p.y-lead < pdfMargin+24 {
+		p.pages = append(p.pages, &bytes.Buffer{})
+		p.y = pdfPageHeight - pdfMargin
+	}
+	p.y -= lead
+	for _, c := range cells {
+		text := c.Text
+		if c.Width > 0 {
+			text = fitWidth(text, c.Width, size, c.Bold)
+		}
+		x := c.X
+		if c.Right {
+			x -= textWidth(text, size, c.Bold)
+		}
+		p.show(x, p.y, size, c.Bold, text)
+	}
+}
+
+// wrapped writes s from x to the right margin, breaking it between words
+// onto as many lines as it takes.
+func (p *pdfWriter) wrapped(x, size, lead float64, s string) {
+	width := pdfPageWidth - pdfMargin - x
+	for _, para := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			next := strings.TrimSpace(line + " " + word)
+			if line != "" && textWidth(next, size, false) > width {
+				p.line(size, lead, pdfCell{X: x, Text: line, Width: width})
+				next = word
+			}
+			line = next
+		}
+		if line != "" {
+			p.line(size, lead, pdfCell{X: x, Text: line, Width: width})
+		}
+	}
+}
+
+// gap leaves space before the next line.
+func (p *pdfWriter) gap(h float64) { p.y -= h }
+
+// rule draws a thin line across the page just below the last line.
+func (p *pdfWriter) rule() {
+	if len(p.pages) == 0 {
+		return
+	}
+	p.y -= 5
+	fmt.Fprintf(p.pages[len(p.pages)-1], "0.5 w %d %.2f m %d %.2f l S\n",
+		pdfMargin, p.y, pdfPageWidth-pdfMargin, p.y)
+}
+
+func (p *pdfWriter) show(x, y, size float64, bold bool, text string) {
+	if text == "" {
+		return
+	}
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(p.pages[len(p.pages)-1], "BT /%s %g Tf 1 0 0 1 %.2f %.2f Tm (%s) Tj ET\n",
+		font, size, x, y, pdfString(text))
+}
+
+// bytes returns the finished document.
+func (p *pdfWriter) bytes() ([]byte, error) {
+	if len(p.pages) == 0 {
+		p.line(10, 0)
+	}
+	var b bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, b.Len())
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+	b.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1 to 4 are the catalog, the page tree and the two fonts;
+	// each page is then followed by its content.
+	kids := make([]string, len(p.pages))
+	for i := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d /MediaBox [0 0 %d %d] "+
+		"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> >>",
+		strings.Join(kids, " "), len(p.pages), pdfPageWidth, pdfPageHeight))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, page := range p.pages {
+		if p.footer != "" {
+			footer := fmt.Sprintf("%s -- page %d of %d", p.footer, i+1, len(p.pages))
+			fmt.Fprintf(page, "BT /F1 8 Tf 1 0 0 1 %d %d Tm (%s) Tj ET\n",
+				pdfMargin, pdfMargin-18, pdfString(fitWidth(footer, pdfPageWidth-2*pdfMargin, 8, false)))
+		}
+		var content bytes.Buffer
+		zw := zlib.NewWriter(&content)
+		if _, err := zw.Write(page.Bytes()); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /Contents %d 0 R >>", 6+2*i))
+		object(fmt.Sprintf("<< /Filter /FlateDecode /Length %d >>\nstream\n%s\nendstream",
+			content.Len(), content.Bytes()))
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return b.Bytes(), nil
+}
+
+// cp1252 maps the characters Windows-1252 puts in 0x80-0x9f to their
+// codes; 0xa0-0xff match Latin-1.
+var cp1252 = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b, 'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+// pdfString encodes s as the inside of a PDF literal string.
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\t':
+			b.WriteByte(' ')
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		case cp1252[r] != 0:
+			fmt.Fprintf(&b, "\\%03o", cp1252[r])
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// textWidth estimates how wide s is in Helvetica, in points, from a few
+// classes of character: close enough to line up a table and wrap text
+// without the fonts' metrics.
+func textWidth(s string, size float64, bold bool) float64 {
+	var em float64
+	for _, r := range s {
+		switch {
+		case strings.ContainsRune("ijlI.,:;'|!", r):
+			em += 0.25
+		case r == ' ' || strings.ContainsRune("ft()[]/-", r):
+			em += 0.33
+		case strings.ContainsRune("mwMW@%", r):
+			em += 0.87
+		case r >= '0' && r <= '9' || r == '$':
+			em += 0.556
+		case r >= 'A' && r <= 'Z':
+			em += 0.68
+		default:
+			em += 0.54
+		}
+	}
+	if bold {
+		em *= 1.06
+	}
+	return em * size
+}
+
+// fitWidth cuts s short, with an ellipsis, so it fits within width.
+func fitWidth(s string, width, size float64, bold bool) string {
+	if textWidth(s, size, bold) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && textWidth(string(runes)+"…", size, bold) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimSpace(string(runes)) + "…"
+}
@@ -0,0 +1,294 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package exports
+
+import (
+	"bytes"
+	"cmp"
+	_ "embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"gorm.io/gorm"
+)
+
+// Report is a summary of the house over a period, for an insurance claim
+// or a sale disclosure: the profile, projects with their budgets and
+// actual costs, the service history, the appliances, and what was spent.
+type Report struct {
+	House   data.HouseProfile
+	Address string
+	// Since and Until bound the period, [Since, Until).
+	Since time.Time
+	Until time.Time
+	Now   time.Time
+
+	Projects     []ReportProject
+	Services     []data.ServiceLogEntry
+	ServiceCents int64
+	Appliances   []data.Appliance
+	// Spending is what was spent in the period by category, the largest
+	// first, adding up to SpentCents gross and OutOfPocketCents once
+	// Covered, what insurance, warranties and rebates paid, is taken off.
+	Spending         []data.SpendTotal
+	SpentCents       int64
+	Covered          data.Funding
+	OutOfPocketCents int64
+}
+
+// ReportProject is a project in the report: one underway or delayed now,
+// or finished during the period.
+type ReportProject struct {
+	Title       string
+	Type        string
+	Status      string
+	BudgetCents *int64
+	ActualCents *int64
+}
+
+// variance is how far the actual cost came in over the budget, or under
+// it when negative. ok is false when either isn't known.
+func (p ReportProject) variance() (cents int64, ok bool) {
+	if p.BudgetCents == nil || p.ActualCents == nil {
+		return 0, false
+	}
+	return *p.ActualCents - *p.BudgetCents, true
+}
+
+// Over reports whether the project cost more than its budget.
+func (p ReportProject) Over() bool {
+	v, ok := p.variance()
+	return ok && v > 0
+}
+
+// Variance says how the actual cost compares with the budget, or "" when
+// either isn't known.
+func (p ReportProject) Variance() string {
+	v, ok := p.variance()
+	switch {
+	case !ok:
+		return ""
+	case v > 0:
+		return data.FormatCents(v) + " over"
+	case v < 0:
+		return data.FormatCents(-v) + " under"
+	}
+	return "on budget"
+}
+
+// Through is the last day of the period.
+func (r Report) Through() time.Time {
+	return r.Until.AddDate(0, 0, -1)
+}
+
+// Title is the report's heading.
+func (r Report) Title() string {
+	if r.House.Nickname == "" {
+		return "Home Report"
+	}
+	return r.House.Nickname + " -- Home Report"
+}
+
+// HomeReport gathers the current house's report for [since, until).
+func HomeReport(store *data.Store, since, until, now time.Time) (Report, error) {
+	r := Report{Since: since, Until: until, Now: now}
+	var err error
+	r.House, err = store.HouseProfile()
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return Report{}, fmt.Errorf("load house profile: %w", err)
+	}
+	r.Address = strings.Join(nonBlank(
+		r.House.AddressLine1, r.House.AddressLine2, r.House.City, r.House.State+" "+r.House.PostalCode,
+	), ", ")
+	in := func(t *time.Time) bool { return t != nil && !t.Before(since) && t.Before(until) }
+
+	projects, err := store.ListProjects(false)
+	if err != nil {
+		return Report{}, fmt.Errorf("list projects: %w", err)
+	}
+	for _, p := range projects {
+		active := p.Status == data.ProjectStatusInProgress || p.Status == data.ProjectStatusDelayed
+		if !active && (p.Status != data.ProjectStatusCompleted || !in(p.EndDate)) {
+			continue
+		}
+		r.Projects = append(r.Projects, ReportProject{
+			Title: p.Title, Type: p.ProjectType.Name, Status: p.Status,
+			BudgetCents: p.BudgetCents, ActualCents: p.ActualCents,
+		})
+	}
+
+	logs, err := store.ListServiceLogs(false)
+	if err != nil {
+		return Report{}, fmt.Errorf("list service logs: %w", err)
+	}
+	for _, l := range logs {
+		if !in(&l.ServicedAt) {
+			continue
+		}
+		r.Services = append(r.Services, l)
+		if l.CostCents != nil {
+			r.ServiceCents += *l.CostCents
+		}
+	}
+
+	if r.Appliances, err = store.ListAppliances(false); err != nil {
+		return Report{}, fmt.Errorf("list appliances: %w", err)
+	}
+	slices.SortStableFunc(r.Appliances, func(a, b data.Appliance) int { return cmp.Compare(a.Name, b.Name) })
+
+	entries, err := store.ListSpending(since, until)
+	if err != nil {
+		return Report{}, fmt.Errorf("list spending: %w", err)
+	}
+	byCategory := map[string]*data.SpendTotal{}
+	for _, e := range entries {
+		t := byCategory[e.Category]
+		if t == nil {
+			t = &data.SpendTotal{Category: e.Category}
+			byCategory[e.Category] = t
+		}
+		t.AmountCents += e.AmountCents
+		t.OutOfPocketCents += e.OutOfPocketCents()
+		r.SpentCents += e.AmountCents
+		r.OutOfPocketCents += e.OutOfPocketCents()
+		r.Covered = r.Covered.Add(e.Funding)
+	}
+	for _, t := range byCategory {
+		r.Spending = append(r.Spending, *t)
+	}
+	slices.SortFunc(r.Spending, func(a, b data.SpendTotal) int {
+		return cmp.Or(cmp.Compare(b.AmountCents, a.AmountCents), cmp.Compare(a.Category, b.Category))
+	})
+	return r, nil
+}
+
+// CoveredLine says what others paid toward the period's costs, or is
+// empty when the house paid for everything itself.
+func (r Report) CoveredLine() string {
+	var parts []string
+	for _, p := range []struct {
+		what  string
+		cents int64
+	}{
+		{"insurance", r.Covered.InsuranceCents},
+		{"warranties", r.Covered.WarrantyCents},
+		{"rebates", r.Covered.RebateCents},
+	} {
+		if p.cents != 0 {
+			parts = append(parts, fmt.Sprintf("%s from %s", data.FormatCents(p.cents), p.what))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "Paid by others: " + strings.Join(parts, ", ") + "."
+}
+
+// Markdown renders the report as Markdown.
+func (r Report) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n_%s to %s, generated %s_\n\n", r.Title(),
+		r.Since.Format("January 2, 2006"), r.Through().Format("January 2, 2006"), r.Now.Format("January 2, 2006"))
+
+	b.WriteString("## The house\n\n")
+	field := func(label, value string) {
+		if strings.TrimSpace(value) != "" {
+			fmt.Fprintf(&b, "- **%s:** %s\n", label, value)
+		}
+	}
+	field("Address", r.Address)
+	if r.House.YearBuilt > 0 {
+		field("Built", fmt.Sprint(r.House.YearBuilt))
+	}
+	if r.House.SquareFeet > 0 {
+		field("Size", fmt.Sprintf("%d sq ft", r.House.SquareFeet))
+	}
+	field("Insurance", strings.TrimSpace(r.House.InsuranceCarrier+" "+r.House.InsurancePolicy))
+	b.WriteString("\n")
+
+	b.WriteString("## Projects\n\n")
+	if len(r.Projects) == 0 {
+		b.WriteString("None underway or finished in this period.\n\n")
+	} else {
+		b.WriteString("| Project | Type | Status | Budget | Actual | Variance |\n|---|---|---|---:|---:|---|\n")
+		for _, p := range r.Projects {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n", cell(p.Title), cell(p.Type), p.Status,
+				centsOrBlank(p.BudgetCents), centsOrBlank(p.ActualCents), p.Variance())
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Maintenance history\n\n")
+	if len(r.Services) == 0 {
+		b.WriteString("No service logged in this period.\n\n")
+	} else {
+		b.WriteString("| Date | Task | Vendor | Cost |\n|---|---|---|---:|\n")
+		for _, l := range r.Services {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", l.ServicedAt.Format(time.DateOnly),
+				cell(l.MaintenanceItem.Name), cell(l.Vendor.Name), centsOrBlank(l.CostCents))
+		}
+		fmt.Fprintf(&b, "\n%d visit(s), %s in all.\n\n", len(r.Services), data.FormatCents(r.ServiceCents))
+	}
+
+	b.WriteString("## Appliances\n\n")
+	if len(r.Appliances) == 0 {
+		b.WriteString("None recorded.\n\n")
+	} else {
+		b.WriteString("| Appliance | Brand / Model | Serial | Purchased | Warranty | Cost |\n|---|---|---|---|---|---:|\n")
+		for _, a := range r.Appliances {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n",
+				cell(a.Name), cell(strings.TrimSpace(a.Brand+" "+a.ModelNumber)), cell(a.SerialNumber),
+				dateOrBlank(a.PurchaseDate), dateOrBlank(a.WarrantyExpiry), centsOrBlank(a.CostCents))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Spending\n\n")
+	if len(r.Spending) == 0 {
+		b.WriteString("Nothing spent in this period.\n")
+	} else {
+		b.WriteString("| Category | Cost | Out of pocket |\n|---|---:|---:|\n")
+		for _, s := range r.Spending {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", cell(s.Category),
+				data.FormatCents(s.AmountCents), data.FormatCents(s.OutOfPocketCents))
+		}
+		fmt.Fprintf(&b, "| **Total** | **%s** | **%s** |\n",
+			data.FormatCents(r.SpentCents), data.FormatCents(r.OutOfPocketCents))
+		if covered := r.CoveredLine(); covered != "" {
+			fmt.Fprintf(&b, "\n%s\n", covered)
+		}
+	}
+	return b.String()
+}
+
+//go:embed report.html
+var reportHTML string
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"money":       data.FormatCents,
+	"maybeMoney":  centsOrBlank,
+	"maybeDate":   dateOrBlank,
+	"trimmedJoin": func(a, b string) string { return strings.TrimSpace(a + " " + b) },
+}).Parse(reportHTML))
+
+// HTML renders the report as a page to print or save as a PDF.
+func (r Report) HTML() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := reportTemplate.Execute(&buf, r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func centsOrBlank(c *int64) string {
+	if c == nil {
+		return ""
+	}
+	return data.FormatCents(*c)
+}
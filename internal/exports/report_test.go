@@ -0,0 +1,86 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package exports
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHomeReport(t *testing.T) {
+	store := newStore(t)
+	require.NoError(t, store.CreateHouseProfile(data.HouseProfile{
+		Nickname: "Elm St", AddressLine1: "12 Elm St", City: "Springfield", State: "IL", PostalCode: "62701",
+		InsuranceCarrier: "Acme Mutual",
+	}))
+	day := func(y int, m time.Month, d int) *time.Time {
+		t := time.Date(y, m, d, 12, 0, 0, 0, time.UTC)
+		return &t
+	}
+	cents := func(c int64) *int64 { return &c }
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	for _, p := range []data.Project{
+		{Title: "Deck", Status: data.ProjectStatusInProgress, BudgetCents: cents(1_000_000), ActualCents: cents(1_250_000)},
+		{Title: "Paint", Status: data.ProjectStatusCompleted, EndDate: day(2025, 6, 1), BudgetCents: cents(300_000), ActualCents: cents(250_000)},
+		{Title: "Old roof", Status: data.ProjectStatusCompleted, EndDate: day(2024, 6, 1)},
+		{Title: "Someday pool", Status: data.ProjectStatusIdeating},
+	} {
+		p.ProjectTypeID = types[0].ID
+		require.NoError(t, store.CreateProject(&p))
+	}
+	cats, err := store.MaintenanceCategories()
+	require.NoError(t, err)
+	gutters := data.MaintenanceItem{Name: "Clean gutters", CategoryID: cats[0].ID, IntervalMonths: 6}
+	require.NoError(t, store.CreateMaintenance(&gutters))
+	require.NoError(t, store.CreateServiceLog(&data.ServiceLogEntry{
+		MaintenanceItemID: gutters.ID, ServicedAt: *day(2025, 4, 10), CostCents: cents(12_000), RebateCents: 2_000,
+	}, data.Vendor{Name: "Gutter Guys"}))
+	require.NoError(t, store.CreateServiceLog(&data.ServiceLogEntry{
+		MaintenanceItemID: gutters.ID, ServicedAt: *day(2024, 10, 10), CostCents: cents(11_000),
+	}, data.Vendor{Name: "Gutter Guys"}))
+	require.NoError(t, store.CreateAppliance(&data.Appliance{
+		Name: "Dishwasher", Brand: "Bosch", SerialNumber: "SN-1", PurchaseDate: day(2025, 2, 1), CostCents: cents(90_000),
+	}))
+
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	r, err := HomeReport(store, since, since.AddDate(1, 0, 0), time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, "12 Elm St, Springfield, IL 62701", r.Address)
+	require.Len(t, r.Projects, 2, "the active one and the one finished in 2025")
+	variance := map[string]string{}
+	for _, p := range r.Projects {
+		variance[p.Title] = p.Variance()
+	}
+	assert.Equal(t, map[string]string{"Deck": "$2,500.00 over", "Paint": "$500.00 under"}, variance)
+	require.Len(t, r.Services, 1)
+	assert.Equal(t, int64(12_000), r.ServiceCents)
+	// The finished project's cost counts; the one still underway has none
+	// dated yet.
+	assert.Equal(t, []data.SpendTotal{
+		{Category: data.SpendProject, AmountCents: 250_000, OutOfPocketCents: 250_000},
+		{Category: data.SpendAppliance, AmountCents: 90_000, OutOfPocketCents: 90_000},
+		{Category: data.SpendMaintenance, AmountCents: 12_000, OutOfPocketCents: 10_000},
+	}, r.Spending)
+	assert.Equal(t, int64(352_000), r.SpentCents)
+	assert.Equal(t, int64(350_000), r.OutOfPocketCents)
+
+	md := r.Markdown()
+	assert.Contains(t, md, "# Elm St -- Home Report\n\n_January 1, 2025 to December 31, 2025, generated January 5, 2026_")
+	assert.Contains(t, md, "| Deck | ")
+	assert.Contains(t, md, "| 2025-04-10 | Clean gutters | Gutter Guys | $120.00 |")
+	assert.Contains(t, md, "| Dishwasher | Bosch | SN-1 | 2025-02-01 |  | $900.00 |")
+	assert.Contains(t, md, "| **Total** | **$3,520.00** | **$3,500.00** |")
+	assert.Contains(t, md, "Paid by others: $20.00 from rebates.")
+	assert.NotContains(t, md, "Old roof")
+
+	html, err := r.HTML()
+	require.NoError(t, err)
+	assert.Contains(t, string(html), `<td class="over">$2,500.00 over</td>`)
+	assert.Contains(t, string(html), "Acme Mutual")
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package exports
+
+import (
+	"context"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/sched"
+)
+
+// JobPrefix namespaces exports among the scheduler's jobs.
+const JobPrefix = "export:"
+
+// Job is one configured export.
+type Job struct {
+	Name     string
+	Kind     string
+	Schedule sched.Schedule
+	Jitter   time.Duration
+	To       Destination
+	// BudgetCents is the monthly budget a weekly summary compares spending
+	// against; zero for none.
+	BudgetCents int64
+}
+
+// SchedJob adapts the export to the background scheduler. Each run covers
+// the time since the previous successful one; the first covers one
+// scheduling period.
+func (j Job) SchedJob(store *data.Store) sched.Job {
+	return sched.Job{
+		Name:     JobPrefix + j.Name,
+		Schedule: j.Schedule,
+		Jitter:   j.Jitter,
+		Run: func(ctx context.Context, last, now time.Time) error {
+			since := last
+			if since.IsZero() {
+				since = periodStart(j.Schedule, now)
+			}
+			a, err := j.build(store, since, now)
+			if err != nil {
+				return err
+			}
+			return j.To.Deliver(ctx, a)
+		},
+	}
+}
+
+func (j Job) build(store *data.Store, since, now time.Time) (Artifact, error) {
+	if j.Kind == KindWeeklySummary {
+		return BuildSummary(store, since, now, j.BudgetCents)
+	}
+	return Build(store, j.Kind, since, now)
+}
+
+// periodStart estimates the start of the scheduling period ending at now
+// from the gap between the next two scheduled runs.
+func periodStart(s sched.Schedule, now time.Time) time.Time {
+	next := s.Next(now)
+	after := s.Next(next)
+	if next.IsZero() || after.IsZero() {
+		return now.AddDate(0, -1, 0)
+	}
+	return now.Add(-after.Sub(next))
+}
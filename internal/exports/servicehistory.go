@@ -0,0 +1,211 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package exports
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/csv"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// Service history formats.
+const (
+	ServiceHistoryCSV = "csv"
+	ServiceHistoryPDF = "pdf"
+)
+
+// Kinds of service visit.
+const (
+	VisitMaintenance = "maintenance"
+	VisitRepair      = "repair"
+)
+
+// ServiceHistory is an appliance's record of service, oldest first, for a
+// warranty claim or a buyer: the maintenance logged against its items and
+// the incidents that involved it.
+type ServiceHistory struct {
+	Appliance data.Appliance
+	Visits    []ServiceVisit
+	// TotalCents adds up the visits' costs.
+	TotalCents int64
+	Now        time.Time
+}
+
+// ServiceVisit is one entry in a service history. Work is the maintenance
+// item for maintenance and the incident's title for a repair, dated when
+// it was resolved, or noticed while it's still open.
+type ServiceVisit struct {
+	Date      time.Time
+	Kind      string
+	Work      string
+	Vendor    string
+	CostCents *int64
+	Notes     string
+}
+
+// ApplianceServiceHistory gathers an appliance's service history. Service
+// logged against maintenance items since deleted still counts; entries
+// and incidents in the trash don't.
+func ApplianceServiceHistory(store *data.Store, applianceID uint, now time.Time) (ServiceHistory, error) {
+	a, err := store.GetAppliance(applianceID)
+	if err != nil {
+		return ServiceHistory{}, err
+	}
+	h := ServiceHistory{Appliance: a, Now: now}
+
+	items, err := store.ListMaintenanceByAppliance(applianceID, true)
+	if err != nil {
+		return ServiceHistory{}, fmt.Errorf("list maintenance: %w", err)
+	}
+	for _, item := range items {
+		logs, err := store.ListServiceLog(item.ID, false)
+		if err != nil {
+			return ServiceHistory{}, fmt.Errorf("list service log: %w", err)
+		}
+		for _, l := range logs {
+			h.Visits = append(h.Visits, ServiceVisit{
+				Date: l.ServicedAt, Kind: VisitMaintenance, Work: item.Name,
+				Vendor: l.Vendor.Name, CostCents: l.CostCents, Notes: l.Notes,
+			})
+		}
+	}
+
+	incidents, err := store.ListIncidents(false)
+	if err != nil {
+		return ServiceHistory{}, fmt.Errorf("list incidents: %w", err)
+	}
+	for _, inc := range incidents {
+		if inc.ApplianceID == nil || *inc.ApplianceID != applianceID {
+			continue
+		}
+		v := ServiceVisit{
+			Date: inc.DateNoticed, Kind: VisitRepair, Work: inc.Title,
+			Vendor: inc.Vendor.Name, CostCents: inc.CostCents,
+			Notes: strings.Join(nonBlank(inc.Description, inc.Notes), "\n"),
+		}
+		if inc.DateResolved != nil {
+			v.Date = *inc.DateResolved
+		} else {
+			v.Work += " (open)"
+		}
+		h.Visits = append(h.Visits, v)
+	}
+
+	slices.SortStableFunc(h.Visits, func(a, b ServiceVisit) int {
+		return cmp.Or(a.Date.Compare(b.Date), cmp.Compare(a.Work, b.Work))
+	})
+	for _, v := range h.Visits {
+		if v.CostCents != nil {
+			h.TotalCents += *v.CostCents
+		}
+	}
+	return h, nil
+}
+
+// Artifact renders the history in format, ServiceHistoryCSV or
+// ServiceHistoryPDF, named for the appliance and the day.
+func (h ServiceHistory) Artifact(format string) (Artifact, error) {
+	name := fmt.Sprintf("service-history-%s-%s.%s", fileSlug(h.Appliance.Name), h.Now.Format(time.DateOnly), format)
+	switch format {
+	case ServiceHistoryCSV:
+		body, err := h.CSV()
+		return Artifact{FileName: name, ContentType: "text/csv; charset=utf-8", Body: body}, err
+	case ServiceHistoryPDF:
+		body, err := h.PDF()
+		return Artifact{FileName: name, ContentType: "application/pdf", Body: body}, err
+	}
+	return Artifact{}, fmt.Errorf("unknown format %q -- use csv or pdf", format)
+}
+
+// CSV renders the history as a row per visit, amounts as plain decimals.
+func (h ServiceHistory) CSV() ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"Date", "Kind", "Work", "Vendor", "Cost", "Notes"})
+	for _, v := range h.Visits {
+		cost := ""
+		if v.CostCents != nil {
+			cost = formatAmount(*v.CostCents)
+		}
+		_ = w.Write([]string{v.Date.Format(time.DateOnly), v.Kind, v.Work, v.Vendor, cost, v.Notes})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// Columns of the PDF's table.
+const (
+	historyWorkX   = pdfMargin + 78
+	historyVendorX = pdfMargin + 300
+	historyCostX   = pdfPageWidth - pdfMargin
+)
+
+// PDF renders the history as a printable record: what the appliance is,
+// then a table of visits with their notes beneath.
+func (h ServiceHistory) PDF() ([]byte, error) {
+	a := h.Appliance
+	p := &pdfWriter{footer: "Service history: " + a.Name}
+	p.line(18, 18, pdfCell{X: pdfMargin, Text: "Service history: " + a.Name, Bold: true})
+	p.gap(4)
+	detail := func(label, value string) {
+		if strings.TrimSpace(value) != "" {
+			p.line(10, 14, pdfCell{X: pdfMargin, Text: label, Bold: true}, pdfCell{X: historyWorkX, Text: value})
+		}
+	}
+	detail("Make", strings.Join(nonBlank(a.Brand, a.ModelNumber), " "))
+	detail("Serial", a.SerialNumber)
+	detail("Purchased", dateOrBlank(a.PurchaseDate))
+	detail("Warranty", dateOrBlank(a.WarrantyExpiry))
+	detail("Location", a.Location)
+	detail("Generated", h.Now.Format("January 2, 2006"))
+	p.gap(14)
+
+	if len(h.Visits) == 0 {
+		p.line(10, 14, pdfCell{X: pdfMargin, Text: "No service recorded."})
+		return p.bytes()
+	}
+	p.line(10, 14,
+		pdfCell{X: pdfMargin, Text: "Date", Bold: true},
+		pdfCell{X: historyWorkX, Text: "Work", Bold: true},
+		pdfCell{X: historyVendorX, Text: "Vendor", Bold: true},
+		pdfCell{X: historyCostX, Text: "Cost", Bold: true, Right: true})
+	p.rule()
+	for _, v := range h.Visits {
+		work := v.Work
+		if v.Kind == VisitRepair {
+			work = "Repair: " + work
+		}
+		p.line(10, 16,
+			pdfCell{X: pdfMargin, Text: v.Date.Format(time.DateOnly)},
+			pdfCell{X: historyWorkX, Text: work, Width: historyVendorX - historyWorkX - 8},
+			pdfCell{X: historyVendorX, Text: v.Vendor, Width: historyCostX - historyVendorX - 70},
+			pdfCell{X: historyCostX, Text: centsOrBlank(v.CostCents), Right: true})
+		if v.Notes != "" {
+			p.wrapped(historyWorkX, 9, 12, v.Notes)
+		}
+	}
+	p.rule()
+	p.line(10, 16,
+		pdfCell{X: pdfMargin, Text: fmt.Sprintf("%d visit(s)", len(h.Visits)), Bold: true},
+		pdfCell{X: historyCostX, Text: data.FormatCents(h.TotalCents), Bold: true, Right: true})
+	return p.bytes()
+}
+
+// fileSlug makes a name safe to put in a file name: "Fridge (garage)" is
+// fridge-garage.
+func fileSlug(name string) string {
+	slug := strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	}), "-")
+	if slug == "" {
+		return "appliance"
+	}
+	return slug
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package exports
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/pdftext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplianceServiceHistory(t *testing.T) {
+	store := newStore(t)
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 12, 0, 0, 0, time.UTC) }
+	cents := func(c int64) *int64 { return &c }
+	bought := day(2024, 3, 1)
+	washer := data.Appliance{Name: "Washer (basement)", Brand: "LG", ModelNumber: "WM4000", SerialNumber: "SN-42", PurchaseDate: &bought}
+	require.NoError(t, store.CreateAppliance(&washer))
+	dryer := data.Appliance{Name: "Dryer"}
+	require.NoError(t, store.CreateAppliance(&dryer))
+
+	cats, err := store.MaintenanceCategories()
+	require.NoError(t, err)
+	hoses := data.MaintenanceItem{Name: "Replace hoses", CategoryID: cats[0].ID, ApplianceID: &washer.ID}
+	require.NoError(t, store.CreateMaintenance(&hoses))
+	lint := data.MaintenanceItem{Name: "Clean vent", CategoryID: cats[0].ID, ApplianceID: &dryer.ID}
+	require.NoError(t, store.CreateMaintenance(&lint))
+	require.NoError(t, store.CreateServiceLog(&data.ServiceLogEntry{
+		MaintenanceItemID: hoses.ID, ServicedAt: day(2025, 5, 2), CostCents: cents(4_500),
+		Notes: "Braided steel (½ inch), both hoses",
+	}, data.Vendor{Name: "Appliance Pros"}))
+	require.NoError(t, store.CreateServiceLog(&data.ServiceLogEntry{
+		MaintenanceItemID: lint.ID, ServicedAt: day(2025, 6, 1),
+	}, data.Vendor{}))
+	gone := data.ServiceLogEntry{MaintenanceItemID: hoses.ID, ServicedAt: day(2024, 9, 9)}
+	require.NoError(t, store.CreateServiceLog(&gone, data.Vendor{}))
+	require.NoError(t, store.DeleteServiceLog(gone.ID))
+
+	resolved := day(2024, 11, 20)
+	require.NoError(t, store.CreateIncident(&data.Incident{
+		Title: "Leak under drum", Status: data.IncidentStatusInProgress, Severity: data.IncidentSeverityUrgent,
+		DateNoticed: day(2024, 11, 2), DateResolved: &resolved, ApplianceID: &washer.ID, CostCents: cents(18_000),
+		Description: "Water on the floor after each cycle.",
+	}))
+	require.NoError(t, store.CreateIncident(&data.Incident{
+		Title: "Noisy spin", Status: data.IncidentStatusOpen, Severity: data.IncidentSeverityUrgent,
+		DateNoticed: day(2025, 8, 14), ApplianceID: &washer.ID,
+	}))
+
+	now := day(2026, 1, 5)
+	h, err := ApplianceServiceHistory(store, washer.ID, now)
+	require.NoError(t, err)
+	work := make([]string, len(h.Visits))
+	for i, v := range h.Visits {
+		work[i] = v.Date.Format(time.DateOnly) + " " + v.Kind + " " + v.Work
+	}
+	assert.Equal(t, []string{
+		"2024-11-20 repair Leak under drum",
+		"2025-05-02 maintenance Replace hoses",
+		"2025-08-14 repair Noisy spin (open)",
+	}, work, "oldest first, without the dryer's or the trash's")
+	assert.Equal(t, int64(22_500), h.TotalCents)
+
+	csvOut, err := h.Artifact(ServiceHistoryCSV)
+	require.NoError(t, err)
+	assert.Equal(t, "service-history-washer-basement-2026-01-05.csv", csvOut.FileName)
+	assert.Equal(t, "Date,Kind,Work,Vendor,Cost,Notes\n"+
+		"2024-11-20,repair,Leak under drum,,180.00,Water on the floor after each cycle.\n"+
+		"2025-05-02,maintenance,Replace hoses,Appliance Pros,45.00,\"Braided steel (½ inch), both hoses\"\n"+
+		"2025-08-14,repair,Noisy spin (open),,,\n", string(csvOut.Body))
+
+	pdfOut, err := h.Artifact(ServiceHistoryPDF)
+	require.NoError(t, err)
+	assert.Equal(t, "application/pdf", pdfOut.ContentType)
+	pages, err := pdftext.Pages(pdfOut.Body)
+	require.NoError(t, err)
+	require.Len(t, pages, 1)
+	for _, want := range []string{
+		"Service history: Washer (basement)",
+		"Make LG WM4000",
+		"Serial SN-42",
+		"Purchased 2024-03-01",
+		"2025-05-02 Replace hoses Appliance Pros $45.00",
+		"Braided steel (½ inch), both hoses",
+		"2024-11-20 Repair: Leak under drum $180.00",
+		"3 visit(s) $225.00",
+		"page 1 of 1",
+	} {
+		assert.Contains(t, pages[0], want)
+	}
+
+	_, err = h.Artifact("docx")
+	assert.Error(t, err)
+}
+
+func TestServiceHistoryPDFPages(t *testing.T) {
+	h := ServiceHistory{Appliance: data.Appliance{Name: "Furnace"}, Now: time.Now()}
+	for i := range 80 {
+		h.Visits = append(h.Visits, ServiceVisit{
+			Date: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, i), Kind: VisitMaintenance,
+			Work: "Change filter", Notes: strings.Repeat("A long note that wraps. ", 6),
+		})
+	}
+	body, err := h.PDF()
+	require.NoError(t, err)
+	pages, err := pdftext.Pages(body)
+	require.NoError(t, err)
+	require.Greater(t, len(pages), 1, "80 visits with notes don't fit one page")
+	assert.Contains(t, pages[len(pages)-1], "80 visit(s)")
+	assert.Contains(t, pages[0], "page 1 of ")
+}
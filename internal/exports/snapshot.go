@@ -0,0 +1,217 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package exports
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// Snapshot file names: webcasa-YYYYMMDD-HHMMSS.db.gz, which sort by age.
+const (
+	snapshotPrefix = "webcasa-"
+	snapshotSuffix = ".db.gz"
+	snapshotStamp  = "20060102-150405"
+	// checksumPrefix starts the gzip comment holding the checksum of the
+	// database inside.
+	checksumPrefix = "sha256:"
+)
+
+// ErrBadSnapshot is returned for a snapshot that is damaged or isn't one.
+var ErrBadSnapshot = errors.New("not an intact webcasa snapshot")
+
+// Snapshot is a gzip-compressed backup of the whole database, carrying
+// the checksum of the database so a restore can tell it arrived intact.
+// A snapshot of an encrypted database stays encrypted.
+func Snapshot(store *data.Store, now time.Time) (Artifact, error) {
+	dir, err := os.MkdirTemp("", "webcasa-snapshot-")
+	if err != nil {
+		return Artifact{}, err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	path := filepath.Join(dir, "webcasa.db")
+	if err := store.Backup(path); err != nil {
+		return Artifact{}, err
+	}
+	db, err := os.ReadFile(path)
+	if err != nil {
+		return Artifact{}, err
+	}
+	sum := sha256.Sum256(db)
+
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return Artifact{}, err
+	}
+	zw.Name = "webcasa.db"
+	zw.ModTime = now
+	zw.Comment = checksumPrefix + hex.EncodeToString(sum[:])
+	if _, err := zw.Write(db); err != nil {
+		return Artifact{}, err
+	}
+	if err := zw.Close(); err != nil {
+		return Artifact{}, err
+	}
+	return Artifact{
+		FileName:    snapshotPrefix + now.Format(snapshotStamp) + snapshotSuffix,
+		ContentType: "application/gzip",
+		Body:        buf.Bytes(),
+	}, nil
+}
+
+// OpenSnapshot decompresses a snapshot and checks the database inside
+// against its checksum.
+func OpenSnapshot(body []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBadSnapshot, err)
+	}
+	want, ok := strings.CutPrefix(zr.Comment, checksumPrefix)
+	if !ok {
+		return nil, fmt.Errorf("%w: it has no checksum", ErrBadSnapshot)
+	}
+	db, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBadSnapshot, err)
+	}
+	if sum := sha256.Sum256(db); hex.EncodeToString(sum[:]) != want {
+		return nil, fmt.Errorf("%w: the checksum doesn't match", ErrBadSnapshot)
+	}
+	return db, nil
+}
+
+// SplitSnapshot splits a backup target into its destination and snapshot
+// file name. A target naming a .db.gz file, as in s3://bucket/house.db.gz,
+// gives that name; anything else is a destination taking the default one,
+// so name is empty.
+func SplitSnapshot(target string) (dest, name string) {
+	if !strings.HasSuffix(target, snapshotSuffix) {
+		return target, ""
+	}
+	switch i := strings.LastIndex(target, "/"); i {
+	case -1:
+		return ".", target
+	case 0:
+		return "/", target[1:]
+	default:
+		return target[:i], target[i+1:]
+	}
+}
+
+// Source is a destination that backups can be restored from.
+type Source interface {
+	Destination
+	// Fetch reads the named file back.
+	Fetch(ctx context.Context, name string) ([]byte, error)
+	// List names the files there, in no particular order.
+	List(ctx context.Context) ([]string, error)
+}
+
+// LatestSnapshot names the newest snapshot at src.
+func LatestSnapshot(ctx context.Context, src Source) (string, error) {
+	names, err := src.List(ctx)
+	if err != nil {
+		return "", err
+	}
+	names = slices.DeleteFunc(names, func(name string) bool {
+		return !strings.HasPrefix(name, snapshotPrefix) || !strings.HasSuffix(name, snapshotSuffix)
+	})
+	if len(names) == 0 {
+		return "", fmt.Errorf("no snapshots in %s", src)
+	}
+	return slices.Max(names), nil
+}
+
+// Fetch reads a file from the directory.
+func (d DirDest) Fetch(_ context.Context, name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(string(d), name))
+}
+
+// List names the files in the directory.
+func (d DirDest) List(_ context.Context) ([]string, error) {
+	entries, err := os.ReadDir(string(d))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+// Fetch downloads <prefix>/<name> from the bucket.
+func (d *S3Dest) Fetch(ctx context.Context, name string) ([]byte, error) {
+	resp, err := d.do(ctx, http.MethodGet, d.key(name), "", nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("download %s from %s: %w", name, d, err)
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// List names the objects directly under the prefix, a page of a thousand
+// at a time.
+func (d *S3Dest) List(ctx context.Context) ([]string, error) {
+	prefix := d.key("")
+	var names []string
+	var token string
+	for {
+		// Query parameters are signed in order, so they're written sorted.
+		var query []string
+		if token != "" {
+			query = append(query, "continuation-token="+escapeQuery(token))
+		}
+		query = append(query, "list-type=2", "prefix="+escapeQuery(prefix))
+		resp, err := d.do(ctx, http.MethodGet, "", strings.Join(query, "&"), nil, "")
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %w", d, err)
+		}
+		var page struct {
+			Contents []struct {
+				Key string
+			}
+			IsTruncated           bool
+			NextContinuationToken string
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %w", d, err)
+		}
+		for _, c := range page.Contents {
+			if name := strings.TrimPrefix(c.Key, prefix); name != "" && !strings.Contains(name, "/") {
+				names = append(names, name)
+			}
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return names, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// escapeQuery percent-encodes a query value the way SigV4 expects.
+func escapeQuery(v string) string {
+	return strings.ReplaceAll(url.QueryEscape(v), "+", "%20")
+}
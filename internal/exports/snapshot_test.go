@@ -0,0 +1,152 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package exports
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	store := newStore(t)
+	require.NoError(t, store.CreateAppliance(&data.Appliance{Name: "Boiler"}))
+	now := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+	a, err := Build(store, KindBackup, time.Time{}, now)
+	require.NoError(t, err)
+	assert.Equal(t, "webcasa-20260301-020000.db.gz", a.FileName)
+
+	db, err := OpenSnapshot(a.Body)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "restored.db")
+	require.NoError(t, os.WriteFile(path, db, 0o600))
+	restored, err := data.Open(path)
+	require.NoError(t, err)
+	defer restored.Close()
+	require.NoError(t, restored.CheckIntegrity())
+	appliances, err := restored.ListAppliances(false)
+	require.NoError(t, err)
+	require.Len(t, appliances, 1)
+	assert.Equal(t, "Boiler", appliances[0].Name)
+
+	damaged := append([]byte(nil), a.Body...)
+	damaged[len(damaged)/2] ^= 0xff
+	_, err = OpenSnapshot(damaged)
+	require.ErrorIs(t, err, ErrBadSnapshot)
+	_, err = OpenSnapshot([]byte("SQLite format 3\x00"))
+	require.ErrorIs(t, err, ErrBadSnapshot)
+}
+
+func TestSplitSnapshot(t *testing.T) {
+	for target, want := range map[string][2]string{
+		"s3://bucket/webcasa":            {"s3://bucket/webcasa", ""},
+		"s3://bucket/webcasa/h.db.gz":    {"s3://bucket/webcasa", "h.db.gz"},
+		"s3://bucket/h.db.gz":            {"s3://bucket", "h.db.gz"},
+		"/srv/backups":                   {"/srv/backups", ""},
+		"/srv/backups/house.db.gz":       {"/srv/backups", "house.db.gz"},
+		"/house.db.gz":                   {"/", "house.db.gz"},
+		"house.db.gz":                    {".", "house.db.gz"},
+		"s3://bucket/webcasa/export.zip": {"s3://bucket/webcasa/export.zip", ""},
+	} {
+		dest, name := SplitSnapshot(target)
+		assert.Equal(t, want, [2]string{dest, name}, target)
+	}
+}
+
+func TestLatestSnapshotInDirectory(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"webcasa-20260101-020000.db.gz", "webcasa-20260301-020000.db.gz", "notes.txt", "webcasa-20260201-020000.db.gz"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(name), 0o600))
+	}
+	name, err := LatestSnapshot(context.Background(), DirDest(dir))
+	require.NoError(t, err)
+	assert.Equal(t, "webcasa-20260301-020000.db.gz", name)
+
+	_, err = LatestSnapshot(context.Background(), DirDest(t.TempDir()))
+	assert.ErrorContains(t, err, "no snapshots")
+}
+
+// fakeBucket is an S3 server holding objects in memory that lists them a
+// page of two at a time.
+func fakeBucket(t *testing.T) (*httptest.Server, map[string]string) {
+	t.Helper()
+	var mu sync.Mutex
+	objects := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Contains(t, r.Header.Get("Authorization"), "Credential=AKID/")
+		key := strings.TrimPrefix(r.URL.Path, "/backups/")
+		switch {
+		case r.Method == http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			objects[key] = string(body)
+		case r.URL.Path == "/backups":
+			q := r.URL.Query()
+			assert.Equal(t, "2", q.Get("list-type"))
+			var keys []string
+			for k := range objects {
+				if strings.HasPrefix(k, q.Get("prefix")) && k > q.Get("continuation-token") {
+					keys = append(keys, k)
+				}
+			}
+			slices.Sort(keys)
+			truncated := len(keys) > 2
+			if truncated {
+				keys = keys[:2]
+			}
+			_, _ = io.WriteString(w, "<ListBucketResult>")
+			for _, k := range keys {
+				_, _ = io.WriteString(w, "<Contents><Key>"+k+"</Key></Contents>")
+			}
+			if truncated {
+				_, _ = io.WriteString(w, "<IsTruncated>true</IsTruncated><NextContinuationToken>"+keys[1]+"</NextContinuationToken>")
+			}
+			_, _ = io.WriteString(w, "</ListBucketResult>")
+		case objects[key] != "":
+			_, _ = io.WriteString(w, objects[key])
+		default:
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, objects
+}
+
+func TestS3FetchAndList(t *testing.T) {
+	srv, objects := fakeBucket(t)
+	dest, err := ParseDestination("s3://backups/house", S3Settings{
+		Endpoint: srv.URL, AccessKeyID: "AKID", SecretAccessKey: "secret",
+	}, SMTPSettings{})
+	require.NoError(t, err)
+	src := dest.(Source)
+
+	for _, name := range []string{"webcasa-20260101-020000.db.gz", "webcasa-20260301-020000.db.gz", "webcasa-20260201-020000.db.gz"} {
+		require.NoError(t, src.Deliver(context.Background(), Artifact{FileName: name, ContentType: "application/gzip", Body: []byte(name)}))
+	}
+	objects["house/old/webcasa-20270101-020000.db.gz"] = "nested"
+	objects["cabin/webcasa-20270101-020000.db.gz"] = "elsewhere"
+
+	name, err := LatestSnapshot(context.Background(), src)
+	require.NoError(t, err)
+	assert.Equal(t, "webcasa-20260301-020000.db.gz", name)
+	body, err := src.Fetch(context.Background(), name)
+	require.NoError(t, err)
+	assert.Equal(t, name, string(body))
+
+	_, err = src.Fetch(context.Background(), "missing.db.gz")
+	assert.ErrorContains(t, err, "404 Not Found: NoSuchKey")
+}
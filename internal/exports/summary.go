@@ -0,0 +1,272 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package exports
+
+import (
+	"bytes"
+	"cmp"
+	_ "embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"gorm.io/gorm"
+)
+
+// summaryHorizon is how far ahead the weekly summary looks for what's
+// due, and summaryExpiryHorizon for what's expiring.
+const (
+	summaryHorizon       = 7 * 24 * time.Hour
+	summaryExpiryHorizon = 30 * 24 * time.Hour
+)
+
+// Summary is what the weekly summary reports: what got done since the
+// last one, what's due in the coming week, this month's spending against
+// the budget, and what's about to expire.
+type Summary struct {
+	House     string
+	Since     time.Time
+	Now       time.Time
+	Completed []SummaryItem
+	Due       []SummaryItem
+	Expiring  []SummaryItem
+
+	// MonthSpentCents is what was spent from the start of Now's month,
+	// by Categories. BudgetCents is the monthly budget; zero for none.
+	MonthSpentCents int64
+	BudgetCents     int64
+	Categories      []data.SpendTotal
+}
+
+// SummaryItem is one line of the summary. Alert marks one that is past
+// due.
+type SummaryItem struct {
+	Title  string
+	Detail string
+	Alert  bool
+}
+
+// OverBudget reports whether this month's spending passed the budget.
+func (s Summary) OverBudget() bool {
+	return s.BudgetCents > 0 && s.MonthSpentCents > s.BudgetCents
+}
+
+// OverBudgetCents is how far this month's spending passed the budget.
+func (s Summary) OverBudgetCents() int64 {
+	if !s.OverBudget() {
+		return 0
+	}
+	return s.MonthSpentCents - s.BudgetCents
+}
+
+// BudgetPercent is this month's spending as a share of the budget.
+func (s Summary) BudgetPercent() int {
+	if s.BudgetCents <= 0 {
+		return 0
+	}
+	return int(s.MonthSpentCents * 100 / s.BudgetCents)
+}
+
+// WeeklySummary gathers the current house's summary for [since, now).
+// budgetCents is the monthly spending budget, or zero for none.
+func WeeklySummary(store *data.Store, since, now time.Time, budgetCents int64) (Summary, error) {
+	sum := Summary{Since: since, Now: now, BudgetCents: budgetCents}
+	profile, err := store.HouseProfile()
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return Summary{}, fmt.Errorf("load house profile: %w", err)
+	}
+	sum.House = profile.Nickname
+	in := func(t *time.Time) bool { return t != nil && !t.Before(since) && t.Before(now) }
+
+	logs, err := store.ListServiceLogs(false)
+	if err != nil {
+		return Summary{}, fmt.Errorf("list service logs: %w", err)
+	}
+	for _, l := range logs {
+		if !in(&l.ServicedAt) {
+			continue
+		}
+		detail := l.ServicedAt.Format("Mon Jan 2")
+		if l.Vendor.Name != "" {
+			detail += " · " + l.Vendor.Name
+		}
+		sum.Completed = append(sum.Completed, SummaryItem{Title: l.MaintenanceItem.Name, Detail: detail})
+	}
+	projects, err := store.ListProjects(false)
+	if err != nil {
+		return Summary{}, fmt.Errorf("list projects: %w", err)
+	}
+	for _, p := range projects {
+		if p.Status == data.ProjectStatusCompleted && in(p.EndDate) {
+			sum.Completed = append(sum.Completed, SummaryItem{
+				Title: p.Title, Detail: "project finished " + p.EndDate.Format("Mon Jan 2"),
+			})
+		}
+	}
+	incidents, err := store.ListIncidents(false)
+	if err != nil {
+		return Summary{}, fmt.Errorf("list incidents: %w", err)
+	}
+	for _, i := range incidents {
+		if in(i.DateResolved) {
+			sum.Completed = append(sum.Completed, SummaryItem{
+				Title: i.Title, Detail: "resolved " + i.DateResolved.Format("Mon Jan 2"),
+			})
+		}
+	}
+
+	maintenance, err := store.ListMaintenanceWithSchedule()
+	if err != nil {
+		return Summary{}, fmt.Errorf("list maintenance: %w", err)
+	}
+	slices.SortStableFunc(maintenance, func(a, b data.MaintenanceItem) int {
+		return dueTime(a).Compare(dueTime(b))
+	})
+	for _, m := range maintenance {
+		if m.NextDueAt == nil || m.NextDueAt.After(now.Add(summaryHorizon)) {
+			continue
+		}
+		sum.Due = append(sum.Due, SummaryItem{
+			Title:  m.Name,
+			Detail: dueDetail(*m.NextDueAt, now),
+			Alert:  m.NextDueAt.Before(now),
+		})
+	}
+
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	entries, err := store.ListSpending(monthStart, now)
+	if err != nil {
+		return Summary{}, fmt.Errorf("list spending: %w", err)
+	}
+	for _, e := range entries {
+		sum.MonthSpentCents += e.AmountCents
+	}
+	sum.Categories = data.RollUpSpending(entries, true)
+	slices.SortStableFunc(sum.Categories, func(a, b data.SpendTotal) int {
+		return cmp.Compare(b.AmountCents, a.AmountCents)
+	})
+
+	warranties, err := store.ListExpiringWarranties(now, 0, summaryExpiryHorizon)
+	if err != nil {
+		return Summary{}, fmt.Errorf("list warranties: %w", err)
+	}
+	for _, w := range warranties {
+		sum.Expiring = append(sum.Expiring, SummaryItem{
+			Title: w.Title(), Detail: "ends " + w.ExpiresOn.Format("Mon Jan 2"),
+		})
+	}
+	if r := profile.InsuranceRenewal; r != nil && !r.Before(now) && r.Before(now.Add(summaryExpiryHorizon)) {
+		title := "Insurance policy"
+		if profile.InsuranceCarrier != "" {
+			title = profile.InsuranceCarrier + " policy"
+		}
+		sum.Expiring = append(sum.Expiring, SummaryItem{Title: title, Detail: "renews " + r.Format("Mon Jan 2")})
+	}
+	return sum, nil
+}
+
+func dueTime(m data.MaintenanceItem) time.Time {
+	if m.NextDueAt == nil {
+		return time.Time{}
+	}
+	return *m.NextDueAt
+}
+
+// dueDetail says when something is due, or how long it has been overdue.
+func dueDetail(due, now time.Time) string {
+	if due.Before(now) {
+		days := int(now.Sub(due).Hours() / 24)
+		switch days {
+		case 0:
+			return "due today"
+		case 1:
+			return "overdue by a day"
+		}
+		return fmt.Sprintf("overdue by %d days", days)
+	}
+	return "due " + due.Format("Mon Jan 2")
+}
+
+// Subject is the summary email's subject line.
+func (s Summary) Subject() string {
+	house := s.House
+	if house == "" {
+		house = "your house"
+	}
+	return fmt.Sprintf("This week at %s: %d done, %d due", house, len(s.Completed), len(s.Due))
+}
+
+//go:embed summary.html
+var summaryHTML string
+
+var summaryTemplate = template.Must(template.New("summary").Funcs(template.FuncMap{
+	"money":    data.FormatCents,
+	"barWidth": func(percent int) int { return min(percent, 100) },
+}).Parse(summaryHTML))
+
+// Text renders the summary as plain text, for mail readers that don't
+// show HTML.
+func (s Summary) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n%s – %s\n", s.Subject(), s.Since.Format("Jan 2"), s.Now.Format("Jan 2, 2006"))
+	list := func(title, empty string, items []SummaryItem) {
+		fmt.Fprintf(&b, "\n%s\n", title)
+		if len(items) == 0 {
+			fmt.Fprintf(&b, "  %s\n", empty)
+		}
+		for _, it := range items {
+			mark := "-"
+			if it.Alert {
+				mark = "!"
+			}
+			fmt.Fprintf(&b, "  %s %s (%s)\n", mark, it.Title, it.Detail)
+		}
+	}
+	list("Done this week", "Nothing logged.", s.Completed)
+	list("Due next week", "Nothing due.", s.Due)
+	fmt.Fprintf(&b, "\nSpent this month: %s", data.FormatCents(s.MonthSpentCents))
+	if s.BudgetCents > 0 {
+		fmt.Fprintf(&b, " of %s budgeted (%d%%)", data.FormatCents(s.BudgetCents), s.BudgetPercent())
+	}
+	b.WriteString("\n")
+	for _, c := range s.Categories {
+		fmt.Fprintf(&b, "  - %s: %s\n", c.Category, data.FormatCents(c.AmountCents))
+	}
+	list("Expiring soon", "Nothing in the next 30 days.", s.Expiring)
+	return b.String()
+}
+
+// HTML renders the summary as an HTML email.
+func (s Summary) HTML() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := summaryTemplate.Execute(&buf, s); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// BuildSummary renders the weekly summary for [since, now) as an artifact
+// to be sent as a message of its own. budgetCents is the monthly budget,
+// or zero for none.
+func BuildSummary(store *data.Store, since, now time.Time, budgetCents int64) (Artifact, error) {
+	sum, err := WeeklySummary(store, since, now, budgetCents)
+	if err != nil {
+		return Artifact{}, err
+	}
+	body, err := sum.HTML()
+	if err != nil {
+		return Artifact{}, err
+	}
+	return Artifact{
+		FileName:    "weekly-summary-" + now.Format(time.DateOnly) + ".html",
+		ContentType: "text/html; charset=utf-8",
+		Body:        body,
+		Subject:     sum.Subject(),
+		Text:        sum.Text(),
+	}, nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package exports
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeeklySummary(t *testing.T) {
+	store := newStore(t)
+	now := time.Date(2026, 3, 15, 8, 0, 0, 0, time.UTC)
+	since := now.AddDate(0, 0, -7)
+	renewal := now.AddDate(0, 0, 20)
+	require.NoError(t, store.CreateHouseProfile(data.HouseProfile{
+		Nickname: "Elm St", InsuranceCarrier: "Acme Mutual", InsuranceRenewal: &renewal,
+	}))
+	cats, err := store.MaintenanceCategories()
+	require.NoError(t, err)
+	day := func(d int) *time.Time { t := time.Date(2026, 3, d, 12, 0, 0, 0, time.UTC); return &t }
+	gutters := data.MaintenanceItem{Name: "Clean gutters", CategoryID: cats[0].ID, IntervalMonths: 6}
+	require.NoError(t, store.CreateMaintenance(&gutters))
+	changed := time.Date(2025, 12, 18, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, store.CreateMaintenance(&data.MaintenanceItem{
+		Name: "Furnace filter", CategoryID: cats[0].ID, IntervalMonths: 3, LastServicedAt: &changed,
+	}))
+	cost := int64(12_000)
+	require.NoError(t, store.CreateServiceLog(&data.ServiceLogEntry{
+		MaintenanceItemID: gutters.ID, ServicedAt: *day(10), CostCents: &cost,
+	}, data.Vendor{Name: "Gutter Guys"}))
+	require.NoError(t, store.CreateExpense(&data.Expense{
+		SpentOn: *day(3), AmountCents: 200_000, Category: "Repairs", Description: "Roof patch",
+	}))
+	warranty := now.AddDate(0, 0, 10)
+	require.NoError(t, store.CreateAppliance(&data.Appliance{Name: "Dishwasher", WarrantyExpiry: &warranty}))
+
+	a, err := BuildSummary(store, since, now, 150_000)
+	require.NoError(t, err)
+	assert.Equal(t, "weekly-summary-2026-03-15.html", a.FileName)
+	assert.Equal(t, "This week at Elm St: 1 done, 1 due", a.Subject)
+	html := string(a.Body)
+	assert.Contains(t, html, "Clean gutters")
+	assert.Contains(t, html, "Gutter Guys")
+	assert.Contains(t, html, "Furnace filter")
+	assert.Contains(t, html, "141% of the $1,500.00 budget, over by $620.00")
+	assert.Contains(t, html, "Dishwasher warranty")
+	assert.Contains(t, html, "Acme Mutual policy")
+	assert.Contains(t, a.Text, "Spent this month: $2,120.00 of $1,500.00 budgeted (141%)")
+	assert.Contains(t, a.Text, "  - Repairs: $2,000.00\n")
+
+	// Without a budget there's nothing to compare against.
+	a, err = Build(store, KindWeeklySummary, since, now)
+	require.NoError(t, err)
+	assert.NotContains(t, string(a.Body), "budget")
+}
+
+func TestBuildHTMLMessage(t *testing.T) {
+	msg, err := BuildHTMLMessage("house@example.com", "me@example.com", "This week at Elm St",
+		"Done this week\n", []byte("<p>"+strings.Repeat("x", 200)+"</p>"),
+		time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	s := string(msg)
+	assert.Contains(t, s, "Content-Type: multipart/alternative; boundary=")
+	assert.Contains(t, s, "Done this week\r\n")
+	assert.Contains(t, s, "Content-Type: text/html; charset=utf-8\r\n")
+	assert.NotContains(t, s, "attachment")
+	for _, line := range strings.Split(s, "\r\n") {
+		assert.LessOrEqual(t, len(line), 998)
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package exports
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// TableCSV renders a dumped table as CSV with a header row of column
+// names. Times are written as "2006-01-02 15:04:05" in UTC, which
+// spreadsheets read as dates; amounts stay in cents, as the column names
+// say.
+func TableCSV(t data.DumpedTable) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write(t.Columns)
+	record := make([]string, len(t.Columns))
+	for _, row := range t.Rows {
+		for i, v := range row {
+			record[i] = csvValue(v)
+		}
+		_ = w.Write(record)
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func csvValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.UTC().Format(time.DateTime)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// TablesJSON renders dumped tables as one JSON object with an array of
+// rows per table. Each row is an object of its columns, in table order.
+func TablesJSON(tables []data.DumpedTable) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, t := range tables {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		if err := writeJSONTable(&buf, t); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteString("}\n")
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// TableJSON renders one dumped table as a JSON array of row objects.
+func TableJSON(t data.DumpedTable) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeJSONRows(&buf, t); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteString("\n")
+	return out.Bytes(), nil
+}
+
+func writeJSONTable(buf *bytes.Buffer, t data.DumpedTable) error {
+	name, _ := json.Marshal(t.Name)
+	buf.Write(name)
+	buf.WriteString(":")
+	return writeJSONRows(buf, t)
+}
+
+// writeJSONRows writes rows as objects by hand, since a map would lose the
+// column order.
+func writeJSONRows(buf *bytes.Buffer, t data.DumpedTable) error {
+	buf.WriteString("[")
+	for r, row := range t.Rows {
+		if r > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("{")
+		for i, v := range row {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			key, _ := json.Marshal(t.Columns[i])
+			val, err := json.Marshal(v)
+			if err != nil {
+				return fmt.Errorf("encode %s.%s: %w", t.Name, t.Columns[i], err)
+			}
+			buf.Write(key)
+			buf.WriteString(":")
+			buf.Write(val)
+		}
+		buf.WriteString("}")
+	}
+	buf.WriteString("]")
+	return nil
+}
+
+// ReadTableCSV reads a table written by TableCSV back. Every cell is a
+// string, with "" for empty.
+func ReadTableCSV(name string, r io.Reader) (data.DumpedTable, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return data.DumpedTable{}, fmt.Errorf("read %s: %w", name, err)
+	}
+	if len(records) == 0 {
+		return data.DumpedTable{}, fmt.Errorf("read %s: no header row", name)
+	}
+	t := data.DumpedTable{Name: name, Columns: records[0]}
+	for _, rec := range records[1:] {
+		row := make([]any, len(rec))
+		for i, v := range rec {
+			row[i] = v
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t, nil
+}
+
+// ReadTablesJSON reads tables written by TablesJSON back, in name order.
+// Numbers are json.Numbers.
+func ReadTablesJSON(b []byte) ([]data.DumpedTable, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	tables := make([]data.DumpedTable, 0, len(names))
+	for _, name := range names {
+		t, err := ReadTableJSON(name, raw[name])
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+	return tables, nil
+}
+
+// ReadTableJSON reads a table written by TableJSON back. A column missing
+// from some rows is nil in them.
+func ReadTableJSON(name string, b []byte) (data.DumpedTable, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var objects []map[string]any
+	if err := dec.Decode(&objects); err != nil {
+		return data.DumpedTable{}, fmt.Errorf("read %s: %w", name, err)
+	}
+	t := data.DumpedTable{Name: name}
+	for _, obj := range objects {
+		for col := range obj {
+			if !slices.Contains(t.Columns, col) {
+				t.Columns = append(t.Columns, col)
+			}
+		}
+	}
+	slices.Sort(t.Columns)
+	for _, obj := range objects {
+		row := make([]any, len(t.Columns))
+		for i, col := range t.Columns {
+			row[i] = obj[col]
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t, nil
+}
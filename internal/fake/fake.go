@@ -182,6 +182,7 @@ type Quote struct {
 	TotalCents     int64
 	LaborCents     *int64
 	MaterialsCents *int64
+	RequestedDate  *time.Time
 	ReceivedDate   *time.Time
 	Notes          string
 }
@@ -397,13 +398,16 @@ func (h *HomeFaker) Quote() Quote {
 		time.Now().AddDate(-1, 0, 0),
 		time.Now(),
 	)
+	notes := h.f.Sentence(h.f.IntRange(5, 15))
+	requested := received.AddDate(0, 0, -h.f.IntRange(2, 21))
 
 	return Quote{
 		TotalCents:     totalCents,
 		LaborCents:     &laborCents,
 		MaterialsCents: &materialsCents,
+		RequestedDate:  &requested,
 		ReceivedDate:   &received,
-		Notes:          h.f.Sentence(h.f.IntRange(5, 15)),
+		Notes:          notes,
 	}
 }
 
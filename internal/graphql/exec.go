@@ -0,0 +1,415 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// Schema is the set of types a query can select from, starting at Query.
+type Schema struct {
+	Query *Object
+}
+
+// Object is a GraphQL object type.
+type Object struct {
+	Name   string
+	Fields map[string]*Field
+}
+
+// Field is a field of an object type. Type is nil for scalars. Args names
+// the arguments the field takes.
+type Field struct {
+	Type    *Object
+	List    bool
+	Args    []string
+	Resolve Resolver
+}
+
+// Resolver resolves a field for every parent at once, so one query can
+// serve a whole level of the response: the quotes of fifty projects are
+// one lookup, not fifty. It returns one value per parent, in order; for
+// list fields each value is a []any. Top-level fields get a single nil
+// parent.
+type Resolver func(ctx context.Context, parents []any, args map[string]any) ([]any, error)
+
+// Request is a GraphQL request as sent over HTTP.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Response is the result of running a request.
+type Response struct {
+	Data   any     `json:"data"`
+	Errors []Error `json:"errors,omitempty"`
+}
+
+// Error is one GraphQL error, with the response path it happened at.
+type Error struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// Execute parses and runs a request. Failures are reported in the
+// response's Errors, with Data left null.
+func Execute(ctx context.Context, schema *Schema, req Request) *Response {
+	doc, err := Parse(req.Query)
+	if err != nil {
+		return fail(err)
+	}
+	op, err := pickOperation(doc, req.OperationName)
+	if err != nil {
+		return fail(err)
+	}
+	if op.Kind != "query" {
+		return fail(fmt.Errorf("only queries are supported, not %ss", op.Kind))
+	}
+	vars := make(map[string]any, len(op.Variables))
+	for _, def := range op.Variables {
+		if v, ok := req.Variables[def.Name]; ok {
+			vars[def.Name] = v
+		} else if def.Default != nil {
+			v, err := def.Default.Resolve(nil)
+			if err != nil {
+				return fail(err)
+			}
+			vars[def.Name] = v
+		}
+	}
+	ex := &executor{ctx: ctx, doc: doc, vars: vars}
+	out, err := ex.selectObject(schema.Query, []any{nil}, op.Selections, nil)
+	if err != nil {
+		return fail(err)
+	}
+	return &Response{Data: out[0]}
+}
+
+func fail(err error) *Response {
+	var e *Error
+	if errors.As(err, &e) {
+		return &Response{Errors: []Error{*e}}
+	}
+	return &Response{Errors: []Error{{Message: err.Error()}}}
+}
+
+func pickOperation(doc *Document, name string) (*Operation, error) {
+	if name == "" {
+		if len(doc.Operations) > 1 {
+			return nil, fmt.Errorf("the document has several operations; name one with operationName")
+		}
+		return doc.Operations[0], nil
+	}
+	for _, op := range doc.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("no operation named %q", name)
+}
+
+type executor struct {
+	ctx  context.Context
+	doc  *Document
+	vars map[string]any
+}
+
+// selectObject resolves sels on every parent, which are all of type obj.
+func (ex *executor) selectObject(obj *Object, parents []any, sels []Selection, path []any) ([]*object, error) {
+	fields, err := ex.collect(obj, sels, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*object, len(parents))
+	for i := range out {
+		out[i] = &object{}
+	}
+	for _, f := range fields {
+		key := f.ResponseKey()
+		fieldPath := append(slices.Clip(path), key)
+		if f.Name == "__typename" {
+			for _, o := range out {
+				o.set(key, obj.Name)
+			}
+			continue
+		}
+		def, ok := obj.Fields[f.Name]
+		if !ok {
+			return nil, &Error{Message: fmt.Sprintf("%s has no field %q", obj.Name, f.Name), Path: fieldPath}
+		}
+		args, err := ex.arguments(def, f)
+		if err != nil {
+			return nil, &Error{Message: err.Error(), Path: fieldPath}
+		}
+		if len(parents) == 0 {
+			continue
+		}
+		vals, err := def.Resolve(ex.ctx, parents, args)
+		if err != nil {
+			return nil, &Error{Message: err.Error(), Path: fieldPath}
+		}
+		if len(vals) != len(parents) {
+			return nil, &Error{Message: "resolver returned the wrong number of values", Path: fieldPath}
+		}
+		if def.Type == nil {
+			if len(f.Selections) > 0 {
+				return nil, &Error{Message: fmt.Sprintf("%s.%s has no subfields", obj.Name, f.Name), Path: fieldPath}
+			}
+			for i, v := range vals {
+				out[i].set(key, v)
+			}
+			continue
+		}
+		if len(f.Selections) == 0 {
+			return nil, &Error{Message: fmt.Sprintf("%s.%s needs a selection of subfields", obj.Name, f.Name), Path: fieldPath}
+		}
+		if err := ex.selectChildren(def, f, vals, out, fieldPath); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// selectChildren resolves an object field's subselection for all parents'
+// values together, then hands the results back to each parent.
+func (ex *executor) selectChildren(def *Field, f Selection, vals []any, out []*object, path []any) error {
+	key := f.ResponseKey()
+	var kids []any
+	counts := make([]int, len(vals))
+	for i, v := range vals {
+		switch {
+		case isNull(v):
+			counts[i] = -1
+		case def.List:
+			list, ok := v.([]any)
+			if !ok {
+				return &Error{Message: "resolver returned a non-list for a list field", Path: path}
+			}
+			counts[i] = len(list)
+			kids = append(kids, list...)
+		default:
+			counts[i] = 1
+			kids = append(kids, v)
+		}
+	}
+	sub, err := ex.selectObject(def.Type, kids, f.Selections, path)
+	if err != nil {
+		return err
+	}
+	for i, n := range counts {
+		switch {
+		case n < 0:
+			out[i].set(key, nil)
+		case def.List:
+			list := make([]*object, n)
+			copy(list, sub[:n])
+			out[i].set(key, list)
+		default:
+			out[i].set(key, sub[0])
+		}
+		if n > 0 {
+			sub = sub[n:]
+		}
+	}
+	return nil
+}
+
+// collect flattens fragments and drops skipped selections, leaving the
+// fields to resolve on obj. Fields sharing a response key are merged.
+func (ex *executor) collect(obj *Object, sels []Selection, visiting []string, depth int) ([]Selection, error) {
+	if depth > maxNesting {
+		return nil, fmt.Errorf("fragments nest too deeply")
+	}
+	var fields []Selection
+	add := func(f Selection) {
+		for i := range fields {
+			if fields[i].ResponseKey() == f.ResponseKey() {
+				fields[i].Selections = append(slices.Clip(fields[i].Selections), f.Selections...)
+				return
+			}
+		}
+		fields = append(fields, f)
+	}
+	for _, sel := range sels {
+		include, err := ex.included(sel.Directives)
+		if err != nil {
+			return nil, err
+		}
+		if !include {
+			continue
+		}
+		var inner []Selection
+		nested := visiting
+		switch sel.Kind {
+		case FieldSelection:
+			add(sel)
+			continue
+		case FragmentSpread:
+			frag, ok := ex.doc.Fragments[sel.Name]
+			if !ok {
+				return nil, fmt.Errorf("unknown fragment %q", sel.Name)
+			}
+			if slices.Contains(visiting, sel.Name) {
+				return nil, fmt.Errorf("fragment %q spreads itself", sel.Name)
+			}
+			if frag.TypeCondition != obj.Name {
+				continue
+			}
+			nested = append(slices.Clip(visiting), sel.Name)
+			inner = frag.Selections
+		case InlineFragment:
+			if sel.TypeCondition != "" && sel.TypeCondition != obj.Name {
+				continue
+			}
+			inner = sel.Selections
+		}
+		more, err := ex.collect(obj, inner, nested, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range more {
+			add(f)
+		}
+	}
+	return fields, nil
+}
+
+// included applies @include(if:) and @skip(if:).
+func (ex *executor) included(dirs []Directive) (bool, error) {
+	for _, d := range dirs {
+		if d.Name != "include" && d.Name != "skip" {
+			return false, fmt.Errorf("unknown directive @%s", d.Name)
+		}
+		if len(d.Arguments) != 1 || d.Arguments[0].Name != "if" {
+			return false, fmt.Errorf("@%s takes one argument, if", d.Name)
+		}
+		v, err := d.Arguments[0].Value.Resolve(ex.vars)
+		if err != nil {
+			return false, err
+		}
+		cond, ok := v.(bool)
+		if !ok {
+			return false, fmt.Errorf("@%s(if:) must be a boolean", d.Name)
+		}
+		if cond == (d.Name == "skip") {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (ex *executor) arguments(def *Field, f Selection) (map[string]any, error) {
+	args := make(map[string]any, len(f.Arguments))
+	for _, a := range f.Arguments {
+		if !slices.Contains(def.Args, a.Name) {
+			return nil, fmt.Errorf("%s takes no argument %q", f.Name, a.Name)
+		}
+		v, err := a.Value.Resolve(ex.vars)
+		if err != nil {
+			return nil, err
+		}
+		args[a.Name] = v
+	}
+	return args, nil
+}
+
+// IntArg reads an integer argument, given as a literal, a JSON number in
+// the variables or, as IDs often are, a string of digits.
+func IntArg(args map[string]any, name string) (n int64, ok bool, err error) {
+	v, ok := args[name]
+	if !ok || v == nil {
+		return 0, false, nil
+	}
+	switch v := v.(type) {
+	case int64:
+		return v, true, nil
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v), true, nil
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n, true, nil
+		}
+	case string:
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n, true, nil
+		}
+	}
+	return 0, false, fmt.Errorf("argument %q must be an integer", name)
+}
+
+// BoolArg reads a boolean argument.
+func BoolArg(args map[string]any, name string) (b bool, ok bool, err error) {
+	v, ok := args[name]
+	if !ok || v == nil {
+		return false, false, nil
+	}
+	b, ok = v.(bool)
+	if !ok {
+		return false, false, fmt.Errorf("argument %q must be a boolean", name)
+	}
+	return b, true, nil
+}
+
+func isNull(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// object is a response object, which keeps its fields in query order.
+type object struct {
+	keys []string
+	vals []any
+}
+
+func (o *object) set(key string, v any) {
+	if i := slices.Index(o.keys, key); i >= 0 {
+		o.vals[i] = v
+		return
+	}
+	o.keys = append(o.keys, key)
+	o.vals = append(o.vals, v)
+}
+
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		kb, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		vb, err := json.Marshal(o.vals[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(kb)
+		buf.WriteByte(':')
+		buf.Write(vb)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
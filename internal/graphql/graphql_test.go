@@ -0,0 +1,265 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	doc, err := Parse(`
+		# a comment
+		query Projects($id: ID!, $deleted: Boolean = false) {
+			all: projects(includeDeleted: $deleted) { id ...Names }
+			project(id: $id) @include(if: true) { ... on Project { title } }
+		}
+		fragment Names on Project { title, tags: labels(in: ["a", "b"], where: {x: 1.5, y: null}) }
+	`)
+	require.NoError(t, err)
+	require.Len(t, doc.Operations, 1)
+	op := doc.Operations[0]
+	assert.Equal(t, "query", op.Kind)
+	assert.Equal(t, "Projects", op.Name)
+	require.Len(t, op.Variables, 2)
+	assert.Nil(t, op.Variables[0].Default)
+	require.NotNil(t, op.Variables[1].Default)
+	assert.Equal(t, BooleanValue, op.Variables[1].Default.Kind)
+
+	require.Len(t, op.Selections, 2)
+	all := op.Selections[0]
+	assert.Equal(t, "all", all.ResponseKey())
+	assert.Equal(t, "projects", all.Name)
+	assert.Equal(t, VariableValue, all.Arguments[0].Value.Kind)
+	assert.Equal(t, FragmentSpread, all.Selections[1].Kind)
+	assert.Equal(t, "Names", all.Selections[1].Name)
+	assert.Equal(t, "include", op.Selections[1].Directives[0].Name)
+	assert.Equal(t, InlineFragment, op.Selections[1].Selections[0].Kind)
+	assert.Equal(t, "Project", op.Selections[1].Selections[0].TypeCondition)
+
+	frag := doc.Fragments["Names"]
+	require.NotNil(t, frag)
+	labels := frag.Selections[1]
+	v, err := labels.Arguments[1].Value.Resolve(nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"x": 1.5, "y": nil}, v)
+	v, err = labels.Arguments[0].Value.Resolve(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"a", "b"}, v)
+}
+
+func TestParseShorthandAndStrings(t *testing.T) {
+	doc, err := Parse(`{ search(q: "say \"hi\"\n", n: -3) }`)
+	require.NoError(t, err)
+	args := doc.Operations[0].Selections[0].Arguments
+	assert.Equal(t, "say \"hi\"\n", args[0].Value.Raw)
+	n, err := args[1].Value.Resolve(nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-3), n)
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, src := range []string{
+		``,
+		`{`,
+		`{ }`,
+		`{ a(b:) }`,
+		`{ a(b: "unterminated) }`,
+		`{ a(b: """block""") }`,
+		`query { a } fragment F on X { a } fragment F on X { b }`,
+		`{ a } }`,
+		`{ a: }`,
+		`{ 1 }`,
+	} {
+		_, err := Parse(src)
+		assert.Error(t, err, src)
+	}
+}
+
+func TestParseNestingLimit(t *testing.T) {
+	src := ""
+	for range maxNesting + 2 {
+		src += "{ a "
+	}
+	for range maxNesting + 2 {
+		src += "} "
+	}
+	_, err := Parse(src)
+	assert.ErrorContains(t, err, "too deeply")
+}
+
+type testProject struct {
+	ID    int64
+	Title string
+}
+
+type testQuote struct {
+	ID        int64
+	ProjectID int64
+	Total     int
+}
+
+// testSchema serves projects and their quotes, counting resolver calls.
+func testSchema(calls map[string]int) *Schema {
+	projects := []testProject{{1, "Roof"}, {2, "Deck"}, {3, "Fence"}}
+	quotes := []testQuote{{10, 1, 500}, {11, 1, 700}, {12, 3, 200}}
+
+	quote := &Object{Name: "Quote", Fields: map[string]*Field{}}
+	project := &Object{Name: "Project", Fields: map[string]*Field{}}
+	scalar := func(get func(any) any) *Field {
+		return &Field{Resolve: func(_ context.Context, parents []any, _ map[string]any) ([]any, error) {
+			out := make([]any, len(parents))
+			for i, p := range parents {
+				out[i] = get(p)
+			}
+			return out, nil
+		}}
+	}
+	quote.Fields["id"] = scalar(func(p any) any { return p.(testQuote).ID })
+	quote.Fields["total"] = scalar(func(p any) any { return p.(testQuote).Total })
+	project.Fields["id"] = scalar(func(p any) any { return p.(testProject).ID })
+	project.Fields["title"] = scalar(func(p any) any { return p.(testProject).Title })
+	project.Fields["quotes"] = &Field{Type: quote, List: true,
+		Resolve: func(_ context.Context, parents []any, _ map[string]any) ([]any, error) {
+			calls["quotes"]++
+			out := make([]any, len(parents))
+			for i, p := range parents {
+				list := []any{}
+				for _, q := range quotes {
+					if q.ProjectID == p.(testProject).ID {
+						list = append(list, q)
+					}
+				}
+				out[i] = list
+			}
+			return out, nil
+		}}
+	quote.Fields["project"] = &Field{Type: project,
+		Resolve: func(_ context.Context, parents []any, _ map[string]any) ([]any, error) {
+			calls["project"]++
+			out := make([]any, len(parents))
+			for i, p := range parents {
+				out[i] = projects[p.(testQuote).ProjectID-1]
+			}
+			return out, nil
+		}}
+
+	query := &Object{Name: "Query", Fields: map[string]*Field{
+		"projects": {Type: project, List: true,
+			Resolve: func(context.Context, []any, map[string]any) ([]any, error) {
+				list := make([]any, len(projects))
+				for i, p := range projects {
+					list[i] = p
+				}
+				return []any{list}, nil
+			}},
+		"project": {Type: project, Args: []string{"id"},
+			Resolve: func(_ context.Context, _ []any, args map[string]any) ([]any, error) {
+				id, _, err := IntArg(args, "id")
+				if err != nil {
+					return nil, err
+				}
+				for _, p := range projects {
+					if p.ID == id {
+						return []any{p}, nil
+					}
+				}
+				return []any{nil}, nil
+			}},
+	}}
+	return &Schema{Query: query}
+}
+
+func run(t *testing.T, schema *Schema, req Request) string {
+	t.Helper()
+	out, err := json.Marshal(Execute(context.Background(), schema, req))
+	require.NoError(t, err)
+	return string(out)
+}
+
+func TestExecuteBatchesNestedFields(t *testing.T) {
+	calls := map[string]int{}
+	got := run(t, testSchema(calls), Request{Query: `{
+		projects { title quotes { total project { id } } }
+	}`})
+	assert.JSONEq(t, `{"data":{"projects":[
+		{"title":"Roof","quotes":[{"total":500,"project":{"id":1}},{"total":700,"project":{"id":1}}]},
+		{"title":"Deck","quotes":[]},
+		{"title":"Fence","quotes":[{"total":200,"project":{"id":3}}]}
+	]}}`, got)
+	assert.Equal(t, map[string]int{"quotes": 1, "project": 1}, calls)
+}
+
+func TestExecuteKeepsQueryOrder(t *testing.T) {
+	got := run(t, testSchema(map[string]int{}), Request{Query: `{ project(id: 2) { title id __typename } }`})
+	assert.Equal(t, `{"data":{"project":{"title":"Deck","id":2,"__typename":"Project"}}}`, got)
+}
+
+func TestExecuteVariablesFragmentsDirectives(t *testing.T) {
+	schema := testSchema(map[string]int{})
+	got := run(t, schema, Request{
+		Query: `query One($id: ID!, $withQuotes: Boolean = true) {
+			p: project(id: $id) { ...Basics quotes @include(if: $withQuotes) { id } }
+			missing: project(id: 99) { id }
+		}
+		fragment Basics on Project { id ... on Project { title } title }`,
+		Variables: map[string]any{"id": "3"},
+	})
+	assert.JSONEq(t, `{"data":{
+		"p":{"id":3,"title":"Fence","quotes":[{"id":12}]},
+		"missing":null
+	}}`, got)
+
+	got = run(t, schema, Request{
+		Query:     `query One($id: ID!, $skip: Boolean!) { project(id: $id) { id quotes @skip(if: $skip) { id } } }`,
+		Variables: map[string]any{"id": float64(1), "skip": true},
+	})
+	assert.JSONEq(t, `{"data":{"project":{"id":1}}}`, got)
+}
+
+func TestExecuteErrors(t *testing.T) {
+	schema := testSchema(map[string]int{})
+	tests := []struct {
+		query, want string
+	}{
+		{`{ nope }`, `Query has no field "nope"`},
+		{`{ projects }`, `Query.projects needs a selection of subfields`},
+		{`{ projects { title { x } } }`, `Project.title has no subfields`},
+		{`{ project(id: 1, extra: 2) { id } }`, `project takes no argument "extra"`},
+		{`{ project(id: "x") { id } }`, `argument "id" must be an integer`},
+		{`{ project(id: $id) { id } }`, `variable $id is not defined`},
+		{`mutation { project(id: 1) { id } }`, `only queries are supported, not mutations`},
+		{`{ projects { ...F } } fragment F on Project { ...F }`, `fragment "F" spreads itself`},
+		{`{ projects { ...G } }`, `unknown fragment "G"`},
+		{`{ a } { b }`, `several operations`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			resp := Execute(context.Background(), schema, Request{Query: tt.query})
+			assert.Nil(t, resp.Data)
+			require.Len(t, resp.Errors, 1)
+			assert.Contains(t, resp.Errors[0].Message, tt.want)
+		})
+	}
+}
+
+func TestExecuteErrorPath(t *testing.T) {
+	resp := Execute(context.Background(), testSchema(map[string]int{}),
+		Request{Query: `{ projects { quotes { cost } } }`})
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, []any{"projects", "quotes", "cost"}, resp.Errors[0].Path)
+}
+
+func TestExecuteOperationName(t *testing.T) {
+	schema := testSchema(map[string]int{})
+	got := run(t, schema, Request{
+		Query:         `query A { project(id: 1) { id } } query B { project(id: 2) { id } }`,
+		OperationName: "B",
+	})
+	assert.JSONEq(t, `{"data":{"project":{"id":2}}}`, got)
+}
@@ -0,0 +1,682 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+// Package graphql runs read-only GraphQL queries against a schema of
+// batched resolvers. It covers what front ends use day to day: fields,
+// arguments, aliases, variables, fragments and @include/@skip. Mutations,
+// subscriptions and introspection are not supported.
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Document is a parsed GraphQL request document.
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+// Operation is one query in a document.
+type Operation struct {
+	Kind       string // "query", "mutation" or "subscription"
+	Name       string
+	Variables  []VariableDefinition
+	Selections []Selection
+}
+
+// VariableDefinition declares a $variable and its default.
+type VariableDefinition struct {
+	Name    string
+	Default *Value
+}
+
+// Fragment is a named fragment definition.
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	Selections    []Selection
+}
+
+// SelectionKind tells the three kinds of selection apart.
+type SelectionKind int
+
+const (
+	FieldSelection SelectionKind = iota
+	FragmentSpread
+	InlineFragment
+)
+
+// Selection is a field, a fragment spread (Name is the fragment) or an
+// inline fragment.
+type Selection struct {
+	Kind          SelectionKind
+	Alias         string
+	Name          string
+	Arguments     []Argument
+	Directives    []Directive
+	TypeCondition string
+	Selections    []Selection
+}
+
+// ResponseKey is the name the field's value has in the response.
+func (s Selection) ResponseKey() string {
+	if s.Alias != "" {
+		return s.Alias
+	}
+	return s.Name
+}
+
+// Argument is a name: value pair on a field or directive.
+type Argument struct {
+	Name  string
+	Value Value
+}
+
+// Directive is an @name(args) annotation.
+type Directive struct {
+	Name      string
+	Arguments []Argument
+}
+
+// ValueKind tells literal values apart.
+type ValueKind int
+
+const (
+	VariableValue ValueKind = iota
+	IntValue
+	FloatValue
+	StringValue
+	BooleanValue
+	NullValue
+	EnumValue
+	ListValue
+	ObjectValue
+)
+
+// Value is a literal or variable in a query.
+type Value struct {
+	Kind   ValueKind
+	Raw    string // name, number, decoded string or "true"/"false"
+	List   []Value
+	Fields []Argument
+}
+
+// Resolve turns v into a Go value: int64, float64, string, bool, nil,
+// []any or map[string]any. Variables come from vars.
+func (v Value) Resolve(vars map[string]any) (any, error) {
+	switch v.Kind {
+	case VariableValue:
+		val, ok := vars[v.Raw]
+		if !ok {
+			return nil, fmt.Errorf("variable $%s is not defined", v.Raw)
+		}
+		return val, nil
+	case IntValue:
+		return strconv.ParseInt(v.Raw, 10, 64)
+	case FloatValue:
+		return strconv.ParseFloat(v.Raw, 64)
+	case StringValue, EnumValue:
+		return v.Raw, nil
+	case BooleanValue:
+		return v.Raw == "true", nil
+	case NullValue:
+		return nil, nil
+	case ListValue:
+		out := make([]any, 0, len(v.List))
+		for _, item := range v.List {
+			val, err := item.Resolve(vars)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, val)
+		}
+		return out, nil
+	case ObjectValue:
+		out := make(map[string]any, len(v.Fields))
+		for _, f := range v.Fields {
+			val, err := f.Value.Resolve(vars)
+			if err != nil {
+				return nil, err
+			}
+			out[f.Name] = val
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("unknown value kind %d", v.Kind)
+}
+
+// maxNesting bounds how deeply selections and values may nest, so a
+// hostile query can't exhaust the stack.
+const maxNesting = 32
+
+// Parse parses a GraphQL request document.
+func Parse(source string) (*Document, error) {
+	p := &parser{lex: lexer{src: source}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	doc := &Document{Fragments: make(map[string]*Fragment)}
+	for p.tok.kind != tokEOF {
+		switch {
+		case p.tok.is(tokPunct, "{"):
+			sels, err := p.selectionSet(0)
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, &Operation{Kind: "query", Selections: sels})
+		case p.tok.is(tokName, "query"), p.tok.is(tokName, "mutation"), p.tok.is(tokName, "subscription"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, op)
+		case p.tok.is(tokName, "fragment"):
+			frag, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, dup := doc.Fragments[frag.Name]; dup {
+				return nil, fmt.Errorf("fragment %q is defined twice", frag.Name)
+			}
+			doc.Fragments[frag.Name] = frag
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	if len(doc.Operations) == 0 {
+		return nil, fmt.Errorf("the document has no operations")
+	}
+	return doc, nil
+}
+
+type parser struct {
+	lex lexer
+	tok token
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	if p.tok.kind == tokEOF {
+		return fmt.Errorf("unexpected end of document")
+	}
+	return fmt.Errorf("unexpected %q at offset %d", p.tok.text, p.tok.pos)
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.tok.is(tokPunct, punct) {
+		return fmt.Errorf("expected %q: %w", punct, p.unexpected())
+	}
+	return p.advance()
+}
+
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokName {
+		return "", fmt.Errorf("expected a name: %w", p.unexpected())
+	}
+	name := p.tok.text
+	return name, p.advance()
+}
+
+func (p *parser) operation() (*Operation, error) {
+	op := &Operation{Kind: p.tok.text}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokName {
+		op.Name = p.tok.text
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if p.tok.is(tokPunct, "(") {
+		vars, err := p.variableDefinitions()
+		if err != nil {
+			return nil, err
+		}
+		op.Variables = vars
+	}
+	if _, err := p.directives(0); err != nil {
+		return nil, err
+	}
+	sels, err := p.selectionSet(0)
+	if err != nil {
+		return nil, err
+	}
+	op.Selections = sels
+	return op, nil
+}
+
+func (p *parser) variableDefinitions() ([]VariableDefinition, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	var defs []VariableDefinition
+	for !p.tok.is(tokPunct, ")") {
+		if err := p.expect("$"); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if err := p.typeRef(0); err != nil {
+			return nil, err
+		}
+		def := VariableDefinition{Name: name}
+		if p.tok.is(tokPunct, "=") {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			v, err := p.value(0)
+			if err != nil {
+				return nil, err
+			}
+			def.Default = &v
+		}
+		defs = append(defs, def)
+	}
+	return defs, p.advance()
+}
+
+// typeRef skips over a type like [ID!]!. Types aren't checked; resolvers
+// check the arguments they get.
+func (p *parser) typeRef(depth int) error {
+	if depth > maxNesting {
+		return fmt.Errorf("type nests too deeply")
+	}
+	if p.tok.is(tokPunct, "[") {
+		if err := p.advance(); err != nil {
+			return err
+		}
+		if err := p.typeRef(depth + 1); err != nil {
+			return err
+		}
+		if err := p.expect("]"); err != nil {
+			return err
+		}
+	} else if _, err := p.name(); err != nil {
+		return err
+	}
+	if p.tok.is(tokPunct, "!") {
+		return p.advance()
+	}
+	return nil
+}
+
+func (p *parser) fragment() (*Fragment, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if name == "on" {
+		return nil, fmt.Errorf("a fragment can't be named \"on\"")
+	}
+	if !p.tok.is(tokName, "on") {
+		return nil, fmt.Errorf("expected \"on\": %w", p.unexpected())
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	typ, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.directives(0); err != nil {
+		return nil, err
+	}
+	sels, err := p.selectionSet(0)
+	if err != nil {
+		return nil, err
+	}
+	return &Fragment{Name: name, TypeCondition: typ, Selections: sels}, nil
+}
+
+func (p *parser) selectionSet(depth int) ([]Selection, error) {
+	if depth > maxNesting {
+		return nil, fmt.Errorf("selections nest too deeply")
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var sels []Selection
+	for !p.tok.is(tokPunct, "}") {
+		sel, err := p.selection(depth)
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+	}
+	if len(sels) == 0 {
+		return nil, fmt.Errorf("empty selection set")
+	}
+	return sels, p.advance()
+}
+
+func (p *parser) selection(depth int) (Selection, error) {
+	if p.tok.is(tokPunct, "...") {
+		return p.fragmentSelection(depth)
+	}
+	name, err := p.name()
+	if err != nil {
+		return Selection{}, err
+	}
+	sel := Selection{Kind: FieldSelection, Name: name}
+	if p.tok.is(tokPunct, ":") {
+		if err := p.advance(); err != nil {
+			return Selection{}, err
+		}
+		sel.Alias = name
+		if sel.Name, err = p.name(); err != nil {
+			return Selection{}, err
+		}
+	}
+	if p.tok.is(tokPunct, "(") {
+		if sel.Arguments, err = p.arguments(depth); err != nil {
+			return Selection{}, err
+		}
+	}
+	if sel.Directives, err = p.directives(depth); err != nil {
+		return Selection{}, err
+	}
+	if p.tok.is(tokPunct, "{") {
+		if sel.Selections, err = p.selectionSet(depth + 1); err != nil {
+			return Selection{}, err
+		}
+	}
+	return sel, nil
+}
+
+func (p *parser) fragmentSelection(depth int) (Selection, error) {
+	if err := p.advance(); err != nil {
+		return Selection{}, err
+	}
+	var err error
+	if p.tok.kind == tokName && p.tok.text != "on" {
+		sel := Selection{Kind: FragmentSpread, Name: p.tok.text}
+		if err := p.advance(); err != nil {
+			return Selection{}, err
+		}
+		sel.Directives, err = p.directives(depth)
+		return sel, err
+	}
+	sel := Selection{Kind: InlineFragment}
+	if p.tok.is(tokName, "on") {
+		if err := p.advance(); err != nil {
+			return Selection{}, err
+		}
+		if sel.TypeCondition, err = p.name(); err != nil {
+			return Selection{}, err
+		}
+	}
+	if sel.Directives, err = p.directives(depth); err != nil {
+		return Selection{}, err
+	}
+	sel.Selections, err = p.selectionSet(depth + 1)
+	return sel, err
+}
+
+func (p *parser) arguments(depth int) ([]Argument, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	var args []Argument
+	for !p.tok.is(tokPunct, ")") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		v, err := p.value(depth)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, Argument{Name: name, Value: v})
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty argument list")
+	}
+	return args, p.advance()
+}
+
+func (p *parser) directives(depth int) ([]Directive, error) {
+	var dirs []Directive
+	for p.tok.is(tokPunct, "@") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		d := Directive{Name: name}
+		if p.tok.is(tokPunct, "(") {
+			if d.Arguments, err = p.arguments(depth); err != nil {
+				return nil, err
+			}
+		}
+		dirs = append(dirs, d)
+	}
+	return dirs, nil
+}
+
+func (p *parser) value(depth int) (Value, error) {
+	if depth > maxNesting {
+		return Value{}, fmt.Errorf("value nests too deeply")
+	}
+	tok := p.tok
+	switch {
+	case tok.is(tokPunct, "$"):
+		if err := p.advance(); err != nil {
+			return Value{}, err
+		}
+		name, err := p.name()
+		return Value{Kind: VariableValue, Raw: name}, err
+	case tok.kind == tokInt:
+		return Value{Kind: IntValue, Raw: tok.text}, p.advance()
+	case tok.kind == tokFloat:
+		return Value{Kind: FloatValue, Raw: tok.text}, p.advance()
+	case tok.kind == tokString:
+		return Value{Kind: StringValue, Raw: tok.text}, p.advance()
+	case tok.is(tokName, "true"), tok.is(tokName, "false"):
+		return Value{Kind: BooleanValue, Raw: tok.text}, p.advance()
+	case tok.is(tokName, "null"):
+		return Value{Kind: NullValue}, p.advance()
+	case tok.kind == tokName:
+		return Value{Kind: EnumValue, Raw: tok.text}, p.advance()
+	case tok.is(tokPunct, "["):
+		if err := p.advance(); err != nil {
+			return Value{}, err
+		}
+		v := Value{Kind: ListValue}
+		for !p.tok.is(tokPunct, "]") {
+			item, err := p.value(depth + 1)
+			if err != nil {
+				return Value{}, err
+			}
+			v.List = append(v.List, item)
+		}
+		return v, p.advance()
+	case tok.is(tokPunct, "{"):
+		if err := p.advance(); err != nil {
+			return Value{}, err
+		}
+		v := Value{Kind: ObjectValue}
+		for !p.tok.is(tokPunct, "}") {
+			name, err := p.name()
+			if err != nil {
+				return Value{}, err
+			}
+			if err := p.expect(":"); err != nil {
+				return Value{}, err
+			}
+			field, err := p.value(depth + 1)
+			if err != nil {
+				return Value{}, err
+			}
+			v.Fields = append(v.Fields, Argument{Name: name, Value: field})
+		}
+		return v, p.advance()
+	}
+	return Value{}, fmt.Errorf("expected a value: %w", p.unexpected())
+}
+
+// ── Lexer ──────────────────────────────────────────
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokPunct
+	tokName
+	tokInt
+	tokFloat
+	tokString
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) is(kind tokenKind, text string) bool {
+	return t.kind == kind && t.text == text
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, pos: l.pos}, nil
+	}
+	start := l.pos
+	c := l.src[l.pos]
+	switch {
+	case strings.HasPrefix(l.src[l.pos:], "..."):
+		l.pos += 3
+		return token{kind: tokPunct, text: "...", pos: start}, nil
+	case strings.IndexByte("!$():=@[]{}|&", c) >= 0:
+		l.pos++
+		return token{kind: tokPunct, text: string(c), pos: start}, nil
+	case c == '_' || isLetter(c):
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || isLetter(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokName, text: l.src[start:l.pos], pos: start}, nil
+	case c == '-' || isDigit(c):
+		return l.number()
+	case c == '"':
+		return l.string()
+	}
+	return token{}, fmt.Errorf("unexpected character %q at offset %d", c, start)
+}
+
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			l.pos++
+		case c == '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		case strings.HasPrefix(l.src[l.pos:], "\ufeff"):
+			l.pos += len("\ufeff")
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) number() (token, error) {
+	start := l.pos
+	if l.src[l.pos] == '-' {
+		l.pos++
+	}
+	digits := func() int {
+		n := 0
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.pos++
+			n++
+		}
+		return n
+	}
+	if digits() == 0 {
+		return token{}, fmt.Errorf("malformed number at offset %d", start)
+	}
+	kind := tokInt
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		l.pos++
+		kind = tokFloat
+		if digits() == 0 {
+			return token{}, fmt.Errorf("malformed number at offset %d", start)
+		}
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		l.pos++
+		kind = tokFloat
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.pos++
+		}
+		if digits() == 0 {
+			return token{}, fmt.Errorf("malformed number at offset %d", start)
+		}
+	}
+	return token{kind: kind, text: l.src[start:l.pos], pos: start}, nil
+}
+
+// string reads a quoted string, whose escapes are the same as JSON's.
+// Block strings aren't supported.
+func (l *lexer) string() (token, error) {
+	start := l.pos
+	if strings.HasPrefix(l.src[l.pos:], `"""`) {
+		return token{}, fmt.Errorf("block strings aren't supported (offset %d)", start)
+	}
+	l.pos++
+	for l.pos < len(l.src) {
+		switch l.src[l.pos] {
+		case '\\':
+			l.pos += 2
+			continue
+		case '\n', '\r':
+			return token{}, fmt.Errorf("unterminated string at offset %d", start)
+		case '"':
+			l.pos++
+			var s string
+			if err := json.Unmarshal([]byte(l.src[start:l.pos]), &s); err != nil {
+				return token{}, fmt.Errorf("malformed string at offset %d", start)
+			}
+			return token{kind: tokString, text: s, pos: start}, nil
+		}
+		l.pos++
+	}
+	return token{}, fmt.Errorf("unterminated string at offset %d", start)
+}
+
+func isLetter(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
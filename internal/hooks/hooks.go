@@ -0,0 +1,272 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+// Package hooks runs user-supplied executables when records change, so
+// custom automations -- tagging, extra validation, unusual notifications --
+// can be added without forking webcasa.
+//
+// Each hook receives one JSON Event on stdin. Notify hooks run in the
+// background after a change is saved. Validate hooks run before the change
+// and can reject it by exiting non-zero; whatever they print becomes the
+// error message.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Hook modes.
+const (
+	ModeNotify   = "notify"
+	ModeValidate = "validate"
+)
+
+// Event phases.
+const (
+	PhaseBefore = "before"
+	PhaseAfter  = "after"
+)
+
+// Default timeouts, used when a hook doesn't set its own.
+const (
+	DefaultNotifyTimeout   = 30 * time.Second
+	DefaultValidateTimeout = 5 * time.Second
+)
+
+// queueSize bounds the backlog of notifications per hook; beyond it new
+// events are dropped (and logged) rather than stalling requests.
+const queueSize = 64
+
+// maxMessage caps how much of a rejecting hook's output is shown.
+const maxMessage = 1024
+
+// Event is the JSON document written to a hook's stdin.
+type Event struct {
+	// Name is "<entity>.<action>", e.g. "project.created".
+	Name   string `json:"event"`
+	Phase  string `json:"phase"`
+	Entity string `json:"entity"`
+	Action string `json:"action"`
+	// ID is the changed record, when known. For creates it is only known
+	// after the fact.
+	ID uint `json:"id,omitempty"`
+	// ParentID is set for records created under another, e.g. a service
+	// log entry under its maintenance item.
+	ParentID uint            `json:"parent_id,omitempty"`
+	At       time.Time       `json:"at"`
+	Request  json.RawMessage `json:"request,omitempty"`
+	Record   json.RawMessage `json:"record,omitempty"`
+}
+
+// Hook is one configured executable.
+type Hook struct {
+	Name string
+	// Command is the program and its arguments. It is run directly, not
+	// through a shell.
+	Command []string
+	// Events are glob patterns matched against event names, such as
+	// "project.*", "*.deleted", or "*".
+	Events  []string
+	Mode    string
+	Timeout time.Duration
+}
+
+// Matches reports whether the hook subscribes to the named event.
+func (h Hook) Matches(event string) bool {
+	for _, pattern := range h.Events {
+		if ok, _ := path.Match(pattern, event); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (h Hook) timeout() time.Duration {
+	if h.Timeout > 0 {
+		return h.Timeout
+	}
+	if h.Mode == ModeValidate {
+		return DefaultValidateTimeout
+	}
+	return DefaultNotifyTimeout
+}
+
+// RejectedError is returned when a validate hook turns a change down.
+type RejectedError struct {
+	Hook    string
+	Message string
+}
+
+func (e *RejectedError) Error() string {
+	if e.Message == "" {
+		return "rejected by hook " + e.Hook
+	}
+	return e.Message
+}
+
+// Dispatcher delivers events to hooks. Notify hooks each get a worker, so
+// one hook sees its events in order and a slow hook doesn't hold up others.
+type Dispatcher struct {
+	hooks  []Hook
+	queues map[string]chan Event
+	log    io.Writer
+	wg     sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewDispatcher starts workers for the notify hooks. log receives failures;
+// nil discards them.
+func NewDispatcher(hooks []Hook, log io.Writer) *Dispatcher {
+	if log == nil {
+		log = io.Discard
+	}
+	d := &Dispatcher{hooks: hooks, queues: make(map[string]chan Event), log: log}
+	for _, h := range hooks {
+		if h.Mode == ModeValidate {
+			continue
+		}
+		q := make(chan Event, queueSize)
+		d.queues[h.Name] = q
+		d.wg.Add(1)
+		go func(h Hook) {
+			defer d.wg.Done()
+			for ev := range q {
+				err := run(context.Background(), h, ev)
+				var rej *RejectedError
+				switch {
+				case errors.As(err, &rej):
+					fmt.Fprintf(d.log, "webcasa: hook %s (%s) exited non-zero: %s\n", h.Name, ev.Name, rej.Message)
+				case err != nil:
+					fmt.Fprintf(d.log, "webcasa: hook %s (%s): %v\n", h.Name, ev.Name, err)
+				}
+			}
+		}(h)
+	}
+	return d
+}
+
+// Wants reports whether any hook subscribes to the event in the given mode.
+func (d *Dispatcher) Wants(event, mode string) bool {
+	if d == nil {
+		return false
+	}
+	for _, h := range d.hooks {
+		if h.Mode == mode && h.Matches(event) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate runs the matching validate hooks in order and returns the first
+// rejection.
+func (d *Dispatcher) Validate(ctx context.Context, ev Event) error {
+	ev.Phase = PhaseBefore
+	for _, h := range d.hooks {
+		if h.Mode != ModeValidate || !h.Matches(ev.Name) {
+			continue
+		}
+		if err := run(ctx, h, ev); err != nil {
+			var rej *RejectedError
+			if errors.As(err, &rej) {
+				return rej
+			}
+			// A hook that can't run can't vouch for the change.
+			fmt.Fprintf(d.log, "webcasa: hook %s (%s): %v\n", h.Name, ev.Name, err)
+			return &RejectedError{Hook: h.Name, Message: fmt.Sprintf("validation hook %s failed", h.Name)}
+		}
+	}
+	return nil
+}
+
+// Notify queues the event for every matching notify hook.
+func (d *Dispatcher) Notify(ev Event) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return
+	}
+	ev.Phase = PhaseAfter
+	for _, h := range d.hooks {
+		if h.Mode == ModeValidate || !h.Matches(ev.Name) {
+			continue
+		}
+		select {
+		case d.queues[h.Name] <- ev:
+		default:
+			fmt.Fprintf(d.log, "webcasa: hook %s: queue full, dropped %s\n", h.Name, ev.Name)
+		}
+	}
+}
+
+// Close stops accepting events and waits up to timeout for queued ones to
+// finish.
+func (d *Dispatcher) Close(timeout time.Duration) {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		for _, q := range d.queues {
+			close(q)
+		}
+	}
+	d.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+// run executes a hook with the event on stdin. A non-zero exit becomes a
+// RejectedError carrying the hook's output.
+func run(ctx context.Context, h Hook, ev Event) error {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, h.timeout())
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, h.Command[0], h.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(),
+		"WEBCASA_EVENT="+ev.Name,
+		"WEBCASA_EVENT_PHASE="+ev.Phase,
+	)
+	// Don't wait on grandchildren still holding the output pipe after the
+	// hook itself is killed.
+	cmd.WaitDelay = time.Second
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", h.timeout())
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		msg := strings.TrimSpace(out.String())
+		if len(msg) > maxMessage {
+			msg = msg[:maxMessage] + "..."
+		}
+		return &RejectedError{Hook: h.Name, Message: msg}
+	}
+	return err
+}
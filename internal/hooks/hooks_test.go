@@ -0,0 +1,99 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// script writes an executable shell script and returns its path.
+func script(t *testing.T, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hook tests use shell scripts")
+	}
+	path := filepath.Join(t.TempDir(), "hook.sh")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0o700))
+	return path
+}
+
+func TestMatches(t *testing.T) {
+	h := Hook{Events: []string{"project.*", "*.deleted"}}
+	assert.True(t, h.Matches("project.created"))
+	assert.True(t, h.Matches("vendor.deleted"))
+	assert.False(t, h.Matches("vendor.created"))
+	assert.True(t, Hook{Events: []string{"*"}}.Matches("room_finish.restored"))
+}
+
+func TestValidateRejects(t *testing.T) {
+	reject := script(t, `grep -q '"Title":""' && { echo "title must not be blank"; exit 1; }; exit 0`)
+	d := NewDispatcher([]Hook{{
+		Name: "titles", Command: []string{reject}, Events: []string{"project.*"}, Mode: ModeValidate,
+	}}, nil)
+	defer d.Close(time.Second)
+
+	assert.True(t, d.Wants("project.created", ModeValidate))
+	assert.False(t, d.Wants("project.created", ModeNotify))
+	assert.False(t, d.Wants("vendor.created", ModeValidate))
+
+	ev := Event{Name: "project.created", Entity: "project", Action: "created"}
+	ev.Request = json.RawMessage(`{"Title":""}`)
+	err := d.Validate(context.Background(), ev)
+	var rej *RejectedError
+	require.ErrorAs(t, err, &rej)
+	assert.Equal(t, "title must not be blank", rej.Error())
+
+	ev.Request = json.RawMessage(`{"Title":"Deck"}`)
+	assert.NoError(t, d.Validate(context.Background(), ev))
+}
+
+func TestValidateFailsClosed(t *testing.T) {
+	slow := script(t, "sleep 5\n")
+	var log bytes.Buffer
+	d := NewDispatcher([]Hook{
+		{Name: "missing", Command: []string{"/nonexistent/hook"}, Events: []string{"a.*"}, Mode: ModeValidate},
+		{Name: "slow", Command: []string{slow}, Events: []string{"b.*"}, Mode: ModeValidate, Timeout: 100 * time.Millisecond},
+	}, &log)
+	defer d.Close(time.Second)
+
+	assert.ErrorContains(t, d.Validate(context.Background(), Event{Name: "a.created"}), "validation hook missing failed")
+	assert.ErrorContains(t, d.Validate(context.Background(), Event{Name: "b.created"}), "validation hook slow failed")
+	assert.Contains(t, log.String(), "timed out")
+}
+
+func TestNotifyDeliversInOrder(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "events.log")
+	record := script(t, `cat >> "$1"; echo >> "$1"; echo "$WEBCASA_EVENT $WEBCASA_EVENT_PHASE" >> "$1"`)
+	d := NewDispatcher([]Hook{{
+		Name: "log", Command: []string{record, out}, Events: []string{"*"},
+	}}, nil)
+
+	d.Notify(Event{Name: "vendor.created", Entity: "vendor", Action: "created", ID: 7})
+	d.Notify(Event{Name: "vendor.deleted", Entity: "vendor", Action: "deleted", ID: 7})
+	d.Close(5 * time.Second)
+	d.Notify(Event{Name: "vendor.restored"}) // ignored after Close
+
+	b, err := os.ReadFile(out)
+	require.NoError(t, err)
+	lines := bytes.Split(bytes.TrimSpace(b), []byte("\n"))
+	require.Len(t, lines, 4)
+
+	var first Event
+	require.NoError(t, json.Unmarshal(lines[0], &first))
+	assert.Equal(t, "vendor.created", first.Name)
+	assert.Equal(t, PhaseAfter, first.Phase)
+	assert.Equal(t, uint(7), first.ID)
+	assert.Equal(t, "vendor.created after", string(lines[1]))
+	assert.Equal(t, "vendor.deleted after", string(lines[3]))
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package hooks
+
+import (
+	"strings"
+)
+
+// routeEvents names the events of routes that don't follow the usual
+// create/update/delete/restore shape.
+var routeEvents = map[string][2]string{
+	"POST /api/devices/{id}/battery":       {"device", "battery_changed"},
+	"POST /api/air-filters/{id}/change":    {"air_filter", "changed"},
+	"PUT /api/floor-plans/{id}/hotspots":   {"floor_plan", "hotspots_updated"},
+	"POST /api/rooms/{id}/finishes":        {"room_finish", "created"},
+	"POST /api/walkthroughs/{id}/items":    {"walkthrough_item", "created"},
+	"POST /api/landscape/{id}/maintenance": {"maintenance", "created"},
+	"POST /api/documents/{id}/keep":        {"document", "burst_resolved"},
+	"POST /api/history/{id}/revert":        {"field_change", "reverted"},
+	"POST /api/undo":                       {"undo_entry", "undone"},
+	"POST /api/redo":                       {"undo_entry", "redone"},
+	"POST /api/estimates/preview":          {},
+	"POST /api/presence":                   {},
+	"POST /api/batch":                      {},
+	"POST /api/graphql":                    {},
+	"POST /api/chat":                       {},
+}
+
+// EventForRoute maps a mutating API route pattern, such as
+// "PUT /api/projects/{id}", to the entity and action its requests produce
+// ("project", "updated"). nested is set when the {id} in the path is the
+// parent of a record being created. ok is false for routes that change
+// nothing, including everything under /api/admin/.
+func EventForRoute(pattern string) (entity, action string, nested, ok bool) {
+	if ev, found := routeEvents[pattern]; found {
+		if ev[0] == "" {
+			return "", "", false, false
+		}
+		return ev[0], ev[1], ev[1] == "created", true
+	}
+	method, route, _ := strings.Cut(pattern, " ")
+	rest, found := strings.CutPrefix(route, "/api/")
+	if !found || strings.HasPrefix(rest, "admin/") {
+		return "", "", false, false
+	}
+	parts := strings.Split(rest, "/")
+	switch {
+	case method == "POST" && len(parts) == 1:
+		return singular(parts[0]), "created", false, true
+	case method == "PUT" && len(parts) == 1:
+		return singular(parts[0]), "updated", false, true
+	case method == "PUT" && len(parts) == 2:
+		return singular(parts[0]), "updated", false, true
+	case method == "DELETE" && len(parts) == 2:
+		return singular(parts[0]), "deleted", false, true
+	case method == "POST" && len(parts) == 3 && parts[2] == "restore":
+		return singular(parts[0]), "restored", false, true
+	case method == "POST" && len(parts) == 3:
+		return singular(parts[2]), "created", true, true
+	}
+	return "", "", false, false
+}
+
+// singular turns a route segment like "service-logs" into an entity name
+// like "service_log".
+func singular(segment string) string {
+	s := strings.ReplaceAll(segment, "-", "_")
+	switch {
+	case strings.HasSuffix(s, "ies"):
+		return strings.TrimSuffix(s, "ies") + "y"
+	case strings.HasSuffix(s, "shes"), strings.HasSuffix(s, "ches"),
+		strings.HasSuffix(s, "sses"), strings.HasSuffix(s, "xes"):
+		return strings.TrimSuffix(s, "es")
+	case strings.HasSuffix(s, "s"):
+		return strings.TrimSuffix(s, "s")
+	}
+	return s
+}
@@ -0,0 +1,46 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package hooks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventForRoute(t *testing.T) {
+	tests := []struct {
+		pattern, entity, action string
+		nested, ok              bool
+	}{
+		{"POST /api/projects", "project", "created", false, true},
+		{"PUT /api/projects/{id}", "project", "updated", false, true},
+		{"DELETE /api/service-logs/{id}", "service_log", "deleted", false, true},
+		{"POST /api/room-finishes/{id}/restore", "room_finish", "restored", false, true},
+		{"POST /api/maintenance/{id}/service-logs", "service_log", "created", true, true},
+		{"POST /api/rooms/{id}/finishes", "room_finish", "created", true, true},
+		{"POST /api/devices/{id}/battery", "device", "battery_changed", false, true},
+		{"POST /api/documents/{id}/keep", "document", "burst_resolved", false, true},
+		{"POST /api/history/{id}/revert", "field_change", "reverted", false, true},
+		{"POST /api/undo", "undo_entry", "undone", false, true},
+		{"PUT /api/house", "house", "updated", false, true},
+		{"POST /api/pest-treatments", "pest_treatment", "created", false, true},
+		{"POST /api/estimates/preview", "", "", false, false},
+		{"POST /api/presence", "", "", false, false},
+		{"POST /api/batch", "", "", false, false},
+		{"POST /api/graphql", "", "", false, false},
+		{"POST /api/admin/tokens", "", "", false, false},
+		{"DELETE /api/admin/tokens/{id}", "", "", false, false},
+		{"GET /api/projects", "", "", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			entity, action, nested, ok := EventForRoute(tt.pattern)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.entity, entity)
+			assert.Equal(t, tt.action, action)
+			assert.Equal(t, tt.nested, nested)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+// Package imagedup finds bursts of near-identical photos -- several shots
+// of the same thing taken moments apart -- using a perceptual hash that
+// survives rescaling and recompression, and scores sharpness so the best
+// shot of a burst can be suggested.
+package imagedup
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math/bits"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Defaults for Bursts.
+const (
+	// DefaultWindow is the longest gap between two shots of one burst.
+	DefaultWindow = 5 * time.Minute
+	// DefaultMaxDistance is how many of the 64 hash bits may differ for two
+	// photos to count as the same shot.
+	DefaultMaxDistance = 10
+)
+
+// Hash is a 64-bit difference hash (dHash): each bit says whether a cell of
+// a 9×8 grayscale thumbnail is brighter than its right-hand neighbour.
+type Hash uint64
+
+// String formats the hash as 16 hex digits.
+func (h Hash) String() string { return fmt.Sprintf("%016x", uint64(h)) }
+
+// ParseHash reads a hash formatted by String.
+func ParseHash(s string) (Hash, error) {
+	n, err := strconv.ParseUint(s, 16, 64)
+	if err != nil || len(s) != 16 {
+		return 0, fmt.Errorf("invalid image hash %q", s)
+	}
+	return Hash(n), nil
+}
+
+// Distance counts the bits in which two hashes differ.
+func Distance(a, b Hash) int { return bits.OnesCount64(uint64(a ^ b)) }
+
+// samples is how many points per side of a thumbnail cell are averaged;
+// enough to be stable without touching every pixel of a 12 MP photo.
+const samples = 8
+
+// HashImage computes the difference hash of img.
+func HashImage(img image.Image) Hash {
+	var cells [8][9]float64
+	b := img.Bounds()
+	for cy := range 8 {
+		for cx := range 9 {
+			var sum float64
+			for sy := range samples {
+				y := b.Min.Y + ((cy*samples+sy)*2+1)*b.Dy()/(8*samples*2)
+				for sx := range samples {
+					x := b.Min.X + ((cx*samples+sx)*2+1)*b.Dx()/(9*samples*2)
+					sum += luma(img, x, y)
+				}
+			}
+			cells[cy][cx] = sum
+		}
+	}
+	var h Hash
+	for cy := range 8 {
+		for cx := range 8 {
+			h <<= 1
+			if cells[cy][cx] > cells[cy][cx+1] {
+				h |= 1
+			}
+		}
+	}
+	return h
+}
+
+// sharpGrid is the side of the grayscale grid Sharpness works on.
+const sharpGrid = 256
+
+// Sharpness scores how much fine detail img has, as the variance of the
+// Laplacian of a grayscale sample. Blurred or shaken shots score lower than
+// crisp ones of the same scene; scores aren't comparable across scenes.
+func Sharpness(img image.Image) float64 {
+	b := img.Bounds()
+	w, h := min(b.Dx(), sharpGrid), min(b.Dy(), sharpGrid)
+	if w < 3 || h < 3 {
+		return 0
+	}
+	g := make([]float64, w*h)
+	for y := range h {
+		sy := b.Min.Y + y*b.Dy()/h
+		for x := range w {
+			g[y*w+x] = luma(img, b.Min.X+x*b.Dx()/w, sy)
+		}
+	}
+	var sum, sumSq float64
+	n := float64((w - 2) * (h - 2))
+	for y := 1; y < h-1; y++ {
+		for x := 1; x < w-1; x++ {
+			i := y*w + x
+			l := g[i-1] + g[i+1] + g[i-w] + g[i+w] - 4*g[i]
+			sum += l
+			sumSq += l * l
+		}
+	}
+	mean := sum / n
+	return sumSq/n - mean*mean
+}
+
+func luma(img image.Image, x, y int) float64 {
+	switch m := img.(type) {
+	case *image.YCbCr:
+		return float64(m.Y[m.YOffset(x, y)])
+	case *image.Gray:
+		return float64(m.Pix[m.PixOffset(x, y)])
+	}
+	return float64(color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y)
+}
+
+// Photo is what Bursts needs to know about one picture.
+type Photo struct {
+	ID        uint
+	Hash      Hash
+	Taken     time.Time
+	Sharpness float64
+}
+
+// Burst is a run of near-identical photos. Best is the ID of the sharpest.
+type Burst struct {
+	Photos []Photo
+	Best   uint
+}
+
+// Bursts groups photos taken within window of each other whose hashes are
+// within maxDistance, and returns the groups of two or more, oldest first.
+// A photo joins a burst if it matches any member, so a slowly panning
+// series stays together.
+func Bursts(photos []Photo, window time.Duration, maxDistance int) []Burst {
+	sorted := slices.Clone(photos)
+	slices.SortStableFunc(sorted, func(a, b Photo) int { return a.Taken.Compare(b.Taken) })
+
+	var groups [][]Photo
+	for _, p := range sorted {
+		joined := false
+		for i := len(groups) - 1; i >= 0 && !joined; i-- {
+			g := groups[i]
+			if p.Taken.Sub(g[len(g)-1].Taken) > window {
+				continue
+			}
+			for _, q := range g {
+				if Distance(p.Hash, q.Hash) <= maxDistance {
+					groups[i] = append(g, p)
+					joined = true
+					break
+				}
+			}
+		}
+		if !joined {
+			groups = append(groups, []Photo{p})
+		}
+	}
+
+	var bursts []Burst
+	for _, g := range groups {
+		if len(g) < 2 {
+			continue
+		}
+		best := g[0]
+		for _, p := range g[1:] {
+			if p.Sharpness > best.Sharpness {
+				best = p
+			}
+		}
+		bursts = append(bursts, Burst{Photos: g, Best: best.ID})
+	}
+	return bursts
+}
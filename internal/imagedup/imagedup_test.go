@@ -0,0 +1,108 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package imagedup
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scene draws a test picture: a checkerboard whose squares are size pixels
+// across, offset by shift and brightened by light.
+func scene(w, h, size, shift int, light uint8) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			v := uint8(40 + x*120/w)
+			if ((x+shift)/size+y/size)%2 == 0 {
+				v += 80
+			}
+			img.Set(x, y, color.RGBA{v + light, v + light, v, 255})
+		}
+	}
+	return img
+}
+
+// blur averages each pixel with its neighbours within r.
+func blur(src *image.RGBA, r int) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			var sum, n int
+			for dy := -r; dy <= r; dy++ {
+				for dx := -r; dx <= r; dx++ {
+					if p := image.Pt(x+dx, y+dy); p.In(b) {
+						sum += int(src.RGBAAt(p.X, p.Y).R)
+						n++
+					}
+				}
+			}
+			v := uint8(sum / n)
+			dst.SetRGBA(x, y, color.RGBA{v, v, v, 255})
+		}
+	}
+	return dst
+}
+
+func reencode(t *testing.T, img image.Image) image.Image {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 60}))
+	out, err := jpeg.Decode(&buf)
+	require.NoError(t, err)
+	return out
+}
+
+func TestHashSurvivesSmallChanges(t *testing.T) {
+	base := HashImage(scene(640, 480, 80, 0, 0))
+	same := HashImage(reencode(t, scene(640, 480, 80, 3, 10)))
+	smaller := HashImage(scene(320, 240, 40, 0, 0))
+	other := HashImage(scene(640, 480, 30, 0, 0))
+
+	assert.LessOrEqual(t, Distance(base, same), DefaultMaxDistance)
+	assert.LessOrEqual(t, Distance(base, smaller), DefaultMaxDistance)
+	assert.Greater(t, Distance(base, other), DefaultMaxDistance)
+
+	parsed, err := ParseHash(base.String())
+	require.NoError(t, err)
+	assert.Equal(t, base, parsed)
+	_, err = ParseHash("xyz")
+	assert.Error(t, err)
+}
+
+func TestSharpnessPrefersCrispShots(t *testing.T) {
+	crisp := scene(300, 200, 20, 0, 0)
+	assert.Greater(t, Sharpness(crisp), Sharpness(blur(crisp, 3)))
+	assert.Zero(t, Sharpness(image.NewGray(image.Rect(0, 0, 2, 2))))
+}
+
+func TestBursts(t *testing.T) {
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	a := HashImage(scene(640, 480, 80, 0, 0))
+	b := HashImage(scene(640, 480, 30, 0, 0))
+	photos := []Photo{
+		{ID: 1, Hash: a, Taken: at, Sharpness: 10},
+		{ID: 2, Hash: b, Taken: at.Add(10 * time.Second), Sharpness: 50},
+		{ID: 3, Hash: a, Taken: at.Add(30 * time.Second), Sharpness: 40},
+		{ID: 4, Hash: a, Taken: at.Add(4 * time.Minute), Sharpness: 20},
+		// Same subject, but long after the burst ended.
+		{ID: 5, Hash: a, Taken: at.Add(2 * time.Hour), Sharpness: 90},
+	}
+	bursts := Bursts(photos, DefaultWindow, DefaultMaxDistance)
+	require.Len(t, bursts, 1)
+	var ids []uint
+	for _, p := range bursts[0].Photos {
+		ids = append(ids, p.ID)
+	}
+	assert.Equal(t, []uint{1, 3, 4}, ids)
+	assert.Equal(t, uint(3), bursts[0].Best)
+}
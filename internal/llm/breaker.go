@@ -0,0 +1,66 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrUnavailable is wrapped, along with ErrUnreachable, by the errors for
+// requests a Breaker turned away.
+var ErrUnavailable = errors.New("the model failed repeatedly and is being left alone")
+
+// Breaker stops sending requests to a server that keeps failing, so each
+// question fails at once instead of waiting out its timeouts and retries.
+// After Failures requests in a row never got an answer it opens for
+// Cooldown; the first request after that is let through, and opens it
+// again if it fails too. A Breaker can be shared by the clients of one
+// server.
+type Breaker struct {
+	// Failures is how many requests in a row must fail to open it.
+	Failures int
+	// Cooldown is how long it stays open.
+	Cooldown time.Duration
+
+	mu        sync.Mutex
+	failed    int
+	openUntil time.Time
+}
+
+// OpenUntil returns when the breaker lets requests through again, or the
+// zero time when it does now.
+func (b *Breaker) OpenUntil() time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if time.Now().Before(b.openUntil) {
+		return b.openUntil
+	}
+	return time.Time{}
+}
+
+// allow returns an error when the breaker is open.
+func (b *Breaker) allow() error {
+	if until := b.OpenUntil(); !until.IsZero() {
+		return fmt.Errorf("%w: %w until %s", ErrUnreachable, ErrUnavailable, until.Format(time.Kitchen))
+	}
+	return nil
+}
+
+// record counts a request that never got an answer, and forgets the count
+// when one did.
+func (b *Breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !transient(err) {
+		b.failed, b.openUntil = 0, time.Time{}
+		return
+	}
+	b.failed++
+	if b.Failures > 0 && b.failed >= b.Failures {
+		b.openUntil = time.Now().Add(b.Cooldown)
+	}
+}
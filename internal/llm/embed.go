@@ -0,0 +1,53 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNoEmbeddings is returned by Embed when no EmbeddingModel is set.
+var ErrNoEmbeddings = errors.New("no embedding model is set")
+
+type embedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embedResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Usage *usageReport `json:"usage"`
+}
+
+// Embed returns the embedding of each of texts by EmbeddingModel, from the
+// API's /embeddings endpoint, in order.
+func (c *Client) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if c.EmbeddingModel == "" {
+		return nil, ErrNoEmbeddings
+	}
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	var out embedResponse
+	if err := c.post(ctx, "/embeddings", embedRequest{Model: c.EmbeddingModel, Input: texts}, &out); err != nil {
+		return nil, err
+	}
+	c.meter(c.EmbeddingModel, out.Usage, texts, "")
+	if len(out.Data) != len(texts) {
+		return nil, fmt.Errorf("asked for %d embeddings, got %d", len(texts), len(out.Data))
+	}
+	vectors := make([][]float32, len(texts))
+	for _, d := range out.Data {
+		if d.Index < 0 || d.Index >= len(texts) || len(d.Embedding) == 0 {
+			return nil, fmt.Errorf("bad embedding at index %d", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	return vectors, nil
+}
@@ -0,0 +1,248 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+// Package llm talks to an OpenAI-compatible chat completions API, such as
+// the one Ollama serves locally.
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Client sends chat requests to one model.
+type Client struct {
+	// BaseURL is the root of the API; /chat/completions is appended.
+	BaseURL string
+	Model   string
+	// ExtraContext is appended to every system prompt.
+	ExtraContext string
+	// HTTP defaults to http.DefaultClient. Bound how long a request may
+	// take with the context passed to Complete.
+	HTTP *http.Client
+	// Window is the model's context window in tokens. Zero asks the
+	// server; see ContextWindow.
+	Window int
+	// ProbeTimeout bounds asking the server about the model. Default: 5s.
+	ProbeTimeout time.Duration
+	// EmbeddingModel embeds text for Embed. Empty turns embeddings off.
+	EmbeddingModel string
+	// RequestTimeout bounds each request to the model, each retry getting
+	// its own. Zero leaves it to the context.
+	RequestTimeout time.Duration
+	// Retries is how many more times a request that never got an answer,
+	// or got one saying the server is overloaded, is tried, waiting longer
+	// before each.
+	Retries int
+	// Breaker, when set, turns requests away while the server keeps
+	// failing.
+	Breaker *Breaker
+	// Meter, when set, is told the tokens each request takes, and can
+	// turn requests away.
+	Meter Meter
+}
+
+// Message is one turn of a conversation.
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ErrEmpty is returned when the model answers with nothing.
+var ErrEmpty = errors.New("the model gave an empty answer")
+
+// ErrUnreachable is wrapped by the errors for requests that never got an
+// answer, because the server is down or didn't answer in time.
+var ErrUnreachable = errors.New("can't reach the model")
+
+type chatRequest struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+	Stream   bool      `json:"stream"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message Message `json:"message"`
+	} `json:"choices"`
+	Usage *usageReport `json:"usage"`
+}
+
+// errorResponse is how the API says what went wrong.
+type errorResponse struct {
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// thinking matches the reasoning some models, like qwen3, put before their
+// answer.
+var thinking = regexp.MustCompile(`(?s)<think>.*?</think>`)
+
+// Complete asks the model to answer prompt, following the system
+// instructions, and returns its answer without any reasoning.
+func (c *Client) Complete(ctx context.Context, system, prompt string) (string, error) {
+	return c.Converse(ctx, system, []Message{{Role: "user", Content: prompt}})
+}
+
+// Converse asks the model for the next turn of a conversation, following
+// the system instructions, and returns it without any reasoning.
+func (c *Client) Converse(ctx context.Context, system string, turns []Message) (string, error) {
+	if extra := strings.TrimSpace(c.ExtraContext); extra != "" {
+		system = strings.TrimSpace(system + "\n\n" + extra)
+	}
+	var messages []Message
+	if system != "" {
+		messages = append(messages, Message{Role: "system", Content: system})
+	}
+	return c.Chat(ctx, append(messages, turns...))
+}
+
+// Chat sends the conversation and returns the model's reply without any
+// reasoning.
+func (c *Client) Chat(ctx context.Context, messages []Message) (string, error) {
+	var out chatResponse
+	if err := c.post(ctx, "/chat/completions", chatRequest{Model: c.Model, Messages: messages}, &out); err != nil {
+		return "", err
+	}
+	if len(out.Choices) == 0 {
+		c.meter(c.Model, out.Usage, contents(messages), "")
+		return "", ErrEmpty
+	}
+	c.meter(c.Model, out.Usage, contents(messages), out.Choices[0].Message.Content)
+	answer := strings.TrimSpace(thinking.ReplaceAllString(out.Choices[0].Message.Content, ""))
+	if answer == "" {
+		return "", ErrEmpty
+	}
+	return answer, nil
+}
+
+func contents(messages []Message) []string {
+	texts := make([]string, len(messages))
+	for i, m := range messages {
+		texts[i] = m.Content
+	}
+	return texts
+}
+
+// retryDelay is how long the first retry waits; each after waits twice as
+// long as the one before.
+var retryDelay = time.Second
+
+// statusError is an answer from the server saying the request failed.
+type statusError struct {
+	code int
+	msg  string
+}
+
+func (e *statusError) Error() string { return e.msg }
+
+// transient reports whether err is a failure that trying again, or later,
+// may get past: no answer at all, or the server saying it's overloaded or
+// can't reach the model behind it.
+func transient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrUnreachable) {
+		return true
+	}
+	var status *statusError
+	if errors.As(err, &status) {
+		switch status.code {
+		case http.StatusTooManyRequests, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+	}
+	return false
+}
+
+// post sends in as JSON to path under BaseURL and decodes the answer into
+// out, retrying transient failures up to Retries times.
+func (c *Client) post(ctx context.Context, path string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	if c.Meter != nil {
+		if err := c.Meter.Allow(); err != nil {
+			return err
+		}
+	}
+	if c.Breaker != nil {
+		if err := c.Breaker.allow(); err != nil {
+			return err
+		}
+	}
+	for attempt := 0; ; attempt++ {
+		err = c.send(ctx, path, body, out)
+		if !transient(err) || attempt >= c.Retries || !sleep(ctx, retryDelay<<attempt) {
+			break
+		}
+	}
+	// A caller that gave up says nothing about the server.
+	if c.Breaker != nil && ctx.Err() == nil {
+		c.Breaker.record(err)
+	}
+	return err
+}
+
+// sleep waits for d, reporting false if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// send makes one try at a request, bounded by RequestTimeout.
+func (c *Client) send(ctx context.Context, path string, body []byte, out any) error {
+	if c.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.RequestTimeout)
+		defer cancel()
+	}
+	url := strings.TrimRight(c.BaseURL, "/") + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w at %s: %w", ErrUnreachable, c.BaseURL, err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		var failure errorResponse
+		if json.Unmarshal(raw, &failure) == nil && failure.Error != nil && failure.Error.Message != "" {
+			return &statusError{resp.StatusCode, fmt.Sprintf("%s: %s", resp.Status, failure.Error.Message)}
+		}
+		return &statusError{resp.StatusCode, fmt.Sprintf("%s: %s", resp.Status, strings.TrimSpace(string(raw[:min(len(raw), 4096)])))}
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("decode model answer: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComplete(t *testing.T) {
+	var got chatRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"<think>hmm</think>\n Press the big button. "}}]}`))
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL + "/v1/", Model: "qwen3", ExtraContext: "Answer in English."}
+	answer, err := c.Complete(context.Background(), "Be brief.", "How do I start it?")
+	require.NoError(t, err)
+	assert.Equal(t, "Press the big button.", answer)
+	assert.Equal(t, "qwen3", got.Model)
+	assert.False(t, got.Stream)
+	assert.Equal(t, []Message{
+		{Role: "system", Content: "Be brief.\n\nAnswer in English."},
+		{Role: "user", Content: "How do I start it?"},
+	}, got.Messages)
+}
+
+func TestCompleteErrors(t *testing.T) {
+	status, body := http.StatusNotFound, `{"error":{"message":"model \"nope\" not found"}}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+	c := &Client{BaseURL: srv.URL, Model: "nope"}
+
+	_, err := c.Complete(context.Background(), "", "hi")
+	require.ErrorContains(t, err, `model "nope" not found`)
+
+	status, body = http.StatusOK, `{"choices":[{"message":{"content":"<think>only thinking</think>"}}]}`
+	_, err = c.Complete(context.Background(), "", "hi")
+	require.ErrorIs(t, err, ErrEmpty)
+
+	srv.Close()
+	_, err = c.Complete(context.Background(), "", "hi")
+	require.ErrorIs(t, err, ErrUnreachable)
+	require.ErrorContains(t, err, "can't reach the model at "+srv.URL)
+}
+
+func TestCompleteRetries(t *testing.T) {
+	retryDelay = time.Millisecond
+	t.Cleanup(func() { retryDelay = time.Second })
+	var calls, failures int
+	slow := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		switch {
+		case slow:
+			time.Sleep(100 * time.Millisecond)
+		case calls <= failures:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"Done."}}]}`))
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	c := &Client{BaseURL: srv.URL, Model: "qwen3", Retries: 2}
+	failures = 2
+	answer, err := c.Complete(ctx, "", "hi")
+	require.NoError(t, err)
+	assert.Equal(t, "Done.", answer)
+	assert.Equal(t, 3, calls)
+
+	calls, failures = 0, 3
+	_, err = c.Complete(ctx, "", "hi")
+	require.ErrorContains(t, err, "503")
+	assert.Equal(t, 3, calls)
+
+	calls, failures, slow = 0, 0, true
+	c.RequestTimeout, c.Retries = 20*time.Millisecond, 1
+	_, err = c.Complete(ctx, "", "hi")
+	require.ErrorIs(t, err, ErrUnreachable)
+	assert.Equal(t, 2, calls)
+}
+
+func TestBreaker(t *testing.T) {
+	status := http.StatusOK
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"Done."}}]}`))
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	b := &Breaker{Failures: 2, Cooldown: time.Hour}
+	c := &Client{BaseURL: srv.URL, Model: "qwen3", Breaker: b}
+
+	// A model error isn't the server failing.
+	status = http.StatusBadGateway
+	_, err := c.Complete(ctx, "", "hi")
+	require.Error(t, err)
+	status = http.StatusNotFound
+	_, err = c.Complete(ctx, "", "hi")
+	require.Error(t, err)
+	status = http.StatusBadGateway
+	_, err = c.Complete(ctx, "", "hi")
+	require.Error(t, err)
+	assert.True(t, b.OpenUntil().IsZero())
+
+	_, err = c.Complete(ctx, "", "hi")
+	require.Error(t, err)
+	assert.False(t, b.OpenUntil().IsZero())
+	calls = 0
+	_, err = c.Complete(ctx, "", "hi")
+	require.ErrorIs(t, err, ErrUnavailable)
+	require.ErrorIs(t, err, ErrUnreachable)
+	assert.Zero(t, calls, "an open breaker sends nothing")
+
+	// Once the cooldown is over, a request that gets through closes it.
+	b.openUntil = time.Now()
+	status = http.StatusOK
+	_, err = c.Complete(ctx, "", "hi")
+	require.NoError(t, err)
+	assert.True(t, b.OpenUntil().IsZero())
+}
+
+func TestContextWindow(t *testing.T) {
+	serve := func(routes map[string]string) string {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, ok := routes[r.Method+" "+r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write([]byte(body))
+		}))
+		t.Cleanup(srv.Close)
+		return srv.URL
+	}
+	ctx := context.Background()
+
+	for name, tt := range map[string]struct {
+		routes map[string]string
+		want   int
+	}{
+		"ollama default": {map[string]string{
+			"POST /api/show": `{"parameters":"stop \"<|im_end|>\"","model_info":{"qwen3.context_length":40960}}`,
+		}, 4096},
+		"ollama num_ctx": {map[string]string{
+			"POST /api/show": `{"parameters":"num_ctx 16384\nstop \"<|im_end|>\"","model_info":{"qwen3.context_length":40960}}`,
+		}, 16384},
+		"llama.cpp": {map[string]string{
+			"GET /props":     `{"default_generation_settings":{"n_ctx":8192}}`,
+			"GET /v1/models": `{"data":[{"id":"any","meta":{"n_ctx_train":131072}}]}`,
+		}, 8192},
+		"vllm": {map[string]string{
+			"GET /v1/models": `{"data":[{"id":"other","max_model_len":2048},{"id":"qwen3","max_model_len":32768}]}`,
+		}, 32768},
+		"lm studio": {map[string]string{
+			"GET /v1/models/qwen3": `{"id":"qwen3","max_context_length":65536}`,
+		}, 65536},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := &Client{BaseURL: serve(tt.routes) + "/v1", Model: "qwen3"}
+			n, ok := c.ContextWindow(ctx)
+			assert.True(t, ok)
+			assert.Equal(t, tt.want, n)
+		})
+	}
+
+	c := &Client{BaseURL: serve(nil) + "/v1", Model: "qwen3"}
+	n, ok := c.ContextWindow(ctx)
+	assert.False(t, ok)
+	assert.Equal(t, DefaultContextWindow, n)
+	chars, window, _ := c.PromptBudget(ctx)
+	assert.Equal(t, DefaultContextWindow, window)
+	assert.Equal(t, (DefaultContextWindow-1024)*4, chars)
+
+	c.Window = 1000
+	n, ok = c.ContextWindow(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, 1000, n)
+	chars, _, _ = c.PromptBudget(ctx)
+	assert.Equal(t, 750*4, chars)
+}
+
+func TestEmbed(t *testing.T) {
+	var got embedRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/embeddings", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		// Out of order, as the API allows.
+		_, _ = w.Write([]byte(`{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0.5]}]}`))
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL + "/v1", Model: "qwen3"}
+	_, err := c.Embed(context.Background(), []string{"a"})
+	require.ErrorIs(t, err, ErrNoEmbeddings)
+
+	c.EmbeddingModel = "nomic-embed-text"
+	vectors, err := c.Embed(context.Background(), []string{"descale the kettle", "clean the filter"})
+	require.NoError(t, err)
+	assert.Equal(t, embedRequest{Model: "nomic-embed-text", Input: []string{"descale the kettle", "clean the filter"}}, got)
+	assert.Equal(t, [][]float32{{1, 0.5}, {0, 1}}, vectors)
+
+	_, err = c.Embed(context.Background(), []string{"one", "two", "three"})
+	require.ErrorContains(t, err, "asked for 3 embeddings, got 2")
+}
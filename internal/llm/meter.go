@@ -0,0 +1,45 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package llm
+
+// Usage is how many tokens a request took.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+	// Estimated is set when the server didn't say, as local servers often
+	// don't, and the tokens were guessed from the text.
+	Estimated bool
+}
+
+// Meter keeps count of the tokens a client's requests take, and can turn
+// requests away, as when a budget is spent.
+type Meter interface {
+	// Allow is asked before each request; an error turns it away.
+	Allow() error
+	// Record is told what each answered request took, and by which model.
+	Record(model string, u Usage)
+}
+
+// usageReport is how the API says what a request took.
+type usageReport struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// meter tells the Meter, if any, what a request took: what the server
+// reported, else a guess from the text sent and received.
+func (c *Client) meter(model string, reported *usageReport, sent []string, received string) {
+	if c.Meter == nil {
+		return
+	}
+	if reported != nil && reported.PromptTokens+reported.CompletionTokens > 0 {
+		c.Meter.Record(model, Usage{PromptTokens: reported.PromptTokens, CompletionTokens: reported.CompletionTokens})
+		return
+	}
+	u := Usage{CompletionTokens: EstimateTokens(received), Estimated: true}
+	for _, text := range sent {
+		u.PromptTokens += EstimateTokens(text)
+	}
+	c.Meter.Record(model, u)
+}
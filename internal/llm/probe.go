@@ -0,0 +1,209 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Context windows, in tokens.
+const (
+	// DefaultContextWindow is assumed when the server won't say how much
+	// the model reads at once. It's small, so a prompt sized for it fits
+	// nearly any model.
+	DefaultContextWindow = 4096
+	// ollamaDefaultContext is what Ollama gives a model unless its
+	// Modelfile sets num_ctx, however much more the model could read.
+	ollamaDefaultContext = 4096
+	// answerReserve is left over in the window for the model's answer.
+	answerReserve = 1024
+	// charsPerToken is about how many characters of English and SQL go
+	// into a token.
+	charsPerToken = 4
+)
+
+// defaultProbeTimeout bounds probing when ProbeTimeout isn't set.
+const defaultProbeTimeout = 5 * time.Second
+
+// probed caches the windows found, by server and model, so each is only
+// probed once.
+var probed sync.Map
+
+// ContextWindow returns how many tokens the model reads at once, prompt
+// and answer together: Window if it's set, else what the server says, from
+// Ollama's model details, llama.cpp's settings, or the model listing of
+// servers such as vLLM and LM Studio. ok is false when the server wouldn't
+// say and DefaultContextWindow is a guess.
+func (c *Client) ContextWindow(ctx context.Context) (tokens int, ok bool) {
+	if c.Window > 0 {
+		return c.Window, true
+	}
+	key := c.BaseURL + "\x00" + c.Model
+	if n, found := probed.Load(key); found {
+		return n.(int), true
+	}
+	timeout := c.ProbeTimeout
+	if timeout <= 0 {
+		timeout = defaultProbeTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for _, probe := range []func(context.Context) int{c.probeOllama, c.probeLlamaCpp, c.probeModels} {
+		if n := probe(ctx); n > 0 {
+			probed.Store(key, n)
+			return n, true
+		}
+	}
+	return DefaultContextWindow, false
+}
+
+// PromptBudget returns how many characters of prompt the model can be
+// sent, leaving room in its context window for the answer, along with
+// the window and whether the server said what it is.
+func (c *Client) PromptBudget(ctx context.Context) (chars, window int, ok bool) {
+	window, ok = c.ContextWindow(ctx)
+	reserve := min(answerReserve, window/4)
+	return (window - reserve) * charsPerToken, window, ok
+}
+
+// EstimateTokens guesses how many tokens text takes.
+func EstimateTokens(text string) int {
+	return (len(text) + charsPerToken - 1) / charsPerToken
+}
+
+// root is the server's address without the /v1 of its OpenAI-compatible
+// API, where Ollama and llama.cpp serve their own endpoints.
+func (c *Client) root() string {
+	return strings.TrimSuffix(strings.TrimRight(c.BaseURL, "/"), "/v1")
+}
+
+// fetchJSON decodes the JSON answer to a request into out, reporting
+// whether there was one.
+func (c *Client) fetchJSON(ctx context.Context, method, target string, body, out any) bool {
+	var r io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return false
+		}
+		r = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, r)
+	if err != nil {
+		return false
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return false
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 4<<20)).Decode(out) == nil
+}
+
+var numCtx = regexp.MustCompile(`(?m)^num_ctx\s+(\d+)`)
+
+// probeOllama reads the model's details from Ollama. The window is the
+// num_ctx its Modelfile sets, else Ollama's default, but no more than the
+// model was trained for.
+func (c *Client) probeOllama(ctx context.Context) int {
+	var show struct {
+		Parameters string         `json:"parameters"`
+		ModelInfo  map[string]any `json:"model_info"`
+	}
+	if !c.fetchJSON(ctx, http.MethodPost, c.root()+"/api/show", map[string]string{"model": c.Model}, &show) {
+		return 0
+	}
+	trained := 0
+	for k, v := range show.ModelInfo {
+		if n, ok := v.(float64); ok && strings.HasSuffix(k, ".context_length") {
+			trained = int(n)
+		}
+	}
+	if trained == 0 && show.Parameters == "" {
+		return 0
+	}
+	window := ollamaDefaultContext
+	if m := numCtx.FindStringSubmatch(show.Parameters); m != nil {
+		window, _ = strconv.Atoi(m[1])
+	}
+	if trained > 0 {
+		window = min(window, trained)
+	}
+	return window
+}
+
+// windowKeys are the fields servers give a model's context window in.
+var windowKeys = []string{"max_model_len", "context_length", "max_context_length", "context_window"}
+
+// modelWindow finds the context window in a model's listing.
+func modelWindow(m map[string]any) int {
+	for _, k := range windowKeys {
+		if n, ok := m[k].(float64); ok && n > 0 {
+			return int(n)
+		}
+	}
+	if meta, ok := m["meta"].(map[string]any); ok {
+		if n, ok := meta["n_ctx_train"].(float64); ok && n > 0 {
+			return int(n)
+		}
+	}
+	return 0
+}
+
+// probeModels looks the model up in the OpenAI-compatible model listing,
+// which vLLM, LM Studio and others extend with its context window.
+func (c *Client) probeModels(ctx context.Context) int {
+	base := strings.TrimRight(c.BaseURL, "/")
+	var one map[string]any
+	if c.fetchJSON(ctx, http.MethodGet, base+"/models/"+url.PathEscape(c.Model), nil, &one) {
+		if n := modelWindow(one); n > 0 {
+			return n
+		}
+	}
+	var list struct {
+		Data []map[string]any `json:"data"`
+	}
+	if !c.fetchJSON(ctx, http.MethodGet, base+"/models", nil, &list) {
+		return 0
+	}
+	for _, m := range list.Data {
+		if m["id"] == c.Model || len(list.Data) == 1 {
+			return modelWindow(m)
+		}
+	}
+	return 0
+}
+
+// probeLlamaCpp reads the window llama.cpp's server was started with.
+func (c *Client) probeLlamaCpp(ctx context.Context) int {
+	var props struct {
+		Settings struct {
+			NCtx int `json:"n_ctx"`
+		} `json:"default_generation_settings"`
+	}
+	if !c.fetchJSON(ctx, http.MethodGet, c.root()+"/props", nil, &props) {
+		return 0
+	}
+	return props.Settings.NCtx
+}
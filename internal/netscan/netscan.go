@@ -0,0 +1,101 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+// Package netscan lists hosts the operating system has recently seen on the
+// local network. It reads the kernel's neighbor (ARP) table rather than
+// actively probing, so it is fast, needs no privileges, and sends no traffic.
+package netscan
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+)
+
+// arpTablePath is the Linux procfs view of the IPv4 neighbor table.
+const arpTablePath = "/proc/net/arp"
+
+// ErrUnsupported is returned on platforms without a readable neighbor table.
+var ErrUnsupported = errors.New("network discovery is not supported on this platform")
+
+// Neighbor is a host seen on the local network.
+type Neighbor struct {
+	IPAddress  string
+	MACAddress string
+	Interface  string
+}
+
+// Neighbors returns the hosts in the system neighbor table, sorted by IP.
+func Neighbors() ([]Neighbor, error) {
+	f, err := os.Open(arpTablePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open neighbor table: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	return ParseARPTable(f)
+}
+
+// ParseARPTable parses the /proc/net/arp format:
+//
+//	IP address       HW type     Flags       HW address            Mask     Device
+//	192.168.1.10     0x1         0x2         aa:bb:cc:dd:ee:ff     *        eth0
+//
+// Incomplete entries (flags 0x0 or an all-zero hardware address) are skipped.
+func ParseARPTable(r io.Reader) ([]Neighbor, error) {
+	var out []Neighbor
+	sc := bufio.NewScanner(r)
+	first := true
+	for sc.Scan() {
+		if first {
+			first = false
+			continue // header
+		}
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 6 {
+			continue
+		}
+		if fields[2] == "0x0" {
+			continue
+		}
+		ip, err := netip.ParseAddr(fields[0])
+		if err != nil {
+			continue
+		}
+		hw, err := net.ParseMAC(fields[3])
+		if err != nil || isZeroMAC(hw) {
+			continue
+		}
+		out = append(out, Neighbor{
+			IPAddress:  ip.String(),
+			MACAddress: hw.String(),
+			Interface:  fields[5],
+		})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read neighbor table: %w", err)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, _ := netip.ParseAddr(out[i].IPAddress)
+		b, _ := netip.ParseAddr(out[j].IPAddress)
+		return a.Less(b)
+	})
+	return out, nil
+}
+
+func isZeroMAC(hw net.HardwareAddr) bool {
+	for _, b := range hw {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
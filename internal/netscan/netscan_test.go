@@ -0,0 +1,35 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package netscan
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleARP = `IP address       HW type     Flags       HW address            Mask     Device
+192.168.1.20     0x1         0x2         AA:BB:CC:DD:EE:02     *        eth0
+192.168.1.3      0x1         0x2         aa:bb:cc:dd:ee:01     *        eth0
+192.168.1.99     0x1         0x0         00:00:00:00:00:00     *        eth0
+192.168.1.50     0x1         0x2         00:00:00:00:00:00     *        wlan0
+garbage line
+`
+
+func TestParseARPTable(t *testing.T) {
+	got, err := ParseARPTable(strings.NewReader(sampleARP))
+	require.NoError(t, err)
+	assert.Equal(t, []Neighbor{
+		{IPAddress: "192.168.1.3", MACAddress: "aa:bb:cc:dd:ee:01", Interface: "eth0"},
+		{IPAddress: "192.168.1.20", MACAddress: "aa:bb:cc:dd:ee:02", Interface: "eth0"},
+	}, got)
+}
+
+func TestParseARPTableEmpty(t *testing.T) {
+	got, err := ParseARPTable(strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
@@ -0,0 +1,193 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package pdftext
+
+import (
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// maxRange caps how many codes one bfrange of a ToUnicode map may cover.
+const maxRange = 1 << 16
+
+// font maps the codes of shown strings to text.
+type font struct {
+	toUnicode *cmap
+	// composite fonts (Type0) use multi-byte codes, which mean nothing
+	// without a ToUnicode map.
+	composite bool
+}
+
+// cmap is a ToUnicode map: codes of the lengths in codeLens, to text.
+type cmap struct {
+	codeLens []int
+	m        map[uint32]string
+}
+
+func (d *document) font(v any) *font {
+	fd, _ := d.resolve(v).(dict)
+	if fd == nil {
+		return nil
+	}
+	f := &font{composite: d.resolve(fd["Subtype"]) == name("Type0")}
+	if s, ok := d.resolve(fd["ToUnicode"]).(*stream); ok {
+		if b, err := d.decode(s); err == nil {
+			f.toUnicode = parseCMap(b)
+		}
+	}
+	return f
+}
+
+// text decodes a shown string.
+func (f *font) text(s []byte) string {
+	switch {
+	case f != nil && f.toUnicode != nil:
+		return f.toUnicode.decode(s)
+	case f != nil && f.composite:
+		return ""
+	}
+	return winAnsi(s)
+}
+
+func (c *cmap) decode(s []byte) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		n := c.codeLens[0]
+		for _, l := range c.codeLens {
+			if i+l <= len(s) {
+				if t, ok := c.m[code(s[i:i+l])]; ok {
+					b.WriteString(t)
+					n = l
+					break
+				}
+			}
+		}
+		i += n
+	}
+	return b.String()
+}
+
+func code(b []byte) uint32 {
+	var c uint32
+	for _, x := range b {
+		c = c<<8 | uint32(x)
+	}
+	return c
+}
+
+// parseCMap reads the codespace ranges and bfchar and bfrange mappings of
+// a ToUnicode CMap, or returns nil if it maps nothing.
+func parseCMap(b []byte) *cmap {
+	c := &cmap{m: map[uint32]string{}}
+	lens := map[int]bool{}
+	l := &lexer{b: b}
+	var operands []any
+	for {
+		t, err := l.token()
+		if err == io.EOF {
+			break
+		}
+		kw, ok := t.(keyword)
+		if !ok {
+			operands = append(operands, t)
+			continue
+		}
+		switch kw {
+		case "[":
+			arr, _ := l.array()
+			operands = append(operands, arr)
+			continue
+		case "endcodespacerange":
+			for i := 0; i+1 < len(operands); i += 2 {
+				if lo, ok := operands[i].([]byte); ok && len(lo) > 0 && len(lo) <= 4 {
+					lens[len(lo)] = true
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].([]byte)
+				dst, ok2 := operands[i+1].([]byte)
+				if ok1 && ok2 && len(src) > 0 && len(src) <= 4 {
+					c.m[code(src)] = utf16BE(dst)
+					lens[len(src)] = true
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].([]byte)
+				hi, ok2 := operands[i+1].([]byte)
+				if !ok1 || !ok2 || len(lo) == 0 || len(lo) > 4 {
+					continue
+				}
+				lens[len(lo)] = true
+				first, last := code(lo), code(hi)
+				if last < first || last-first >= maxRange {
+					continue
+				}
+				switch dst := operands[i+2].(type) {
+				case []byte:
+					for n := uint32(0); n <= last-first; n++ {
+						c.m[first+n] = utf16BE(offsetLast(dst, n))
+					}
+				case []any:
+					for n, v := range dst {
+						if s, ok := v.([]byte); ok && uint32(n) <= last-first {
+							c.m[first+uint32(n)] = utf16BE(s)
+						}
+					}
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+	if len(c.m) == 0 {
+		return nil
+	}
+	for n := 1; n <= 4; n++ {
+		if lens[n] {
+			c.codeLens = append(c.codeLens, n)
+		}
+	}
+	return c
+}
+
+// offsetLast adds n to the last byte of b, as a bfrange does to its
+// destination for each code past the first.
+func offsetLast(b []byte, n uint32) []byte {
+	if len(b) == 0 {
+		return b
+	}
+	out := append([]byte(nil), b...)
+	out[len(out)-1] += byte(n)
+	return out
+}
+
+func utf16BE(b []byte) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// cp1252 holds the WinAnsiEncoding characters that differ from Latin-1.
+var cp1252 = map[byte]rune{
+	0x80: '€', 0x85: '…', 0x91: '‘', 0x92: '’', 0x93: '“', 0x94: '”',
+	0x95: '•', 0x96: '–', 0x97: '—', 0x99: '™',
+}
+
+// winAnsi decodes a string in a simple font without a ToUnicode map. Most
+// such fonts use WinAnsiEncoding or one close to it.
+func winAnsi(s []byte) string {
+	var b strings.Builder
+	for _, c := range s {
+		if r, ok := cp1252[c]; ok {
+			b.WriteRune(r)
+		} else if c >= 0x20 && c != 0x7f {
+			b.WriteRune(rune(c))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,344 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package pdftext
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+)
+
+// The values a PDF object can be: float64 for numbers, bool, nil for
+// null, []byte for strings, name, []any for arrays, dict, ref, and
+// *stream. keyword is anything else, such as a content stream operator.
+type (
+	name    string
+	keyword string
+	dict    map[name]any
+	ref     struct{ num, gen int }
+	stream  struct {
+		hdr dict
+		raw []byte
+	}
+)
+
+// maxNesting bounds how deeply arrays and dictionaries may nest.
+const maxNesting = 64
+
+// lexer reads tokens and objects from PDF syntax.
+type lexer struct {
+	b   []byte
+	pos int
+	// refs has "1 0 R" read as a reference. Content streams have none, and
+	// looking ahead for them there would only cost time.
+	refs  bool
+	depth int
+}
+
+func isSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', '\f', 0:
+		return true
+	}
+	return false
+}
+
+func isDelim(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.b) {
+		switch c := l.b[l.pos]; {
+		case isSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.b) && l.b[l.pos] != '\n' && l.b[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// token reads the next token: a number, name, string, or keyword, with
+// "[", "]", "<<", ">>", "{", and "}" as keywords.
+func (l *lexer) token() (any, error) {
+	l.skipSpace()
+	if l.pos >= len(l.b) {
+		return nil, io.EOF
+	}
+	switch c := l.b[l.pos]; c {
+	case '(':
+		l.pos++
+		return l.literal(), nil
+	case '<':
+		if l.pos+1 < len(l.b) && l.b[l.pos+1] == '<' {
+			l.pos += 2
+			return keyword("<<"), nil
+		}
+		l.pos++
+		return l.hex(), nil
+	case '>':
+		l.pos++
+		if l.pos < len(l.b) && l.b[l.pos] == '>' {
+			l.pos++
+			return keyword(">>"), nil
+		}
+		return keyword(">"), nil
+	case '[', ']', '{', '}', ')':
+		l.pos++
+		return keyword(c), nil
+	case '/':
+		l.pos++
+		return l.name(), nil
+	}
+	start := l.pos
+	for l.pos < len(l.b) && !isSpace(l.b[l.pos]) && !isDelim(l.b[l.pos]) {
+		l.pos++
+	}
+	word := l.b[start:l.pos]
+	if c := word[0]; c == '+' || c == '-' || c == '.' || c >= '0' && c <= '9' {
+		if f, err := strconv.ParseFloat(string(word), 64); err == nil {
+			return f, nil
+		}
+	}
+	return keyword(word), nil
+}
+
+// literal reads a (string) whose opening parenthesis was just read.
+func (l *lexer) literal() []byte {
+	var out []byte
+	for depth := 1; l.pos < len(l.b); {
+		c := l.b[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return out
+			}
+		case '\\':
+			if l.pos >= len(l.b) {
+				return out
+			}
+			c = l.b[l.pos]
+			l.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.pos < len(l.b) && l.b[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					n := int(c - '0')
+					for i := 0; i < 2 && l.pos < len(l.b) && l.b[l.pos] >= '0' && l.b[l.pos] <= '7'; i++ {
+						n = n*8 + int(l.b[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(n)
+				}
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// hex reads a <string> whose opening bracket was just read.
+func (l *lexer) hex() []byte {
+	var out []byte
+	var digits []byte
+	for l.pos < len(l.b) {
+		c := l.b[l.pos]
+		l.pos++
+		if c == '>' {
+			break
+		}
+		if v, ok := unhex(c); ok {
+			digits = append(digits, v)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, 0)
+	}
+	for i := 0; i < len(digits); i += 2 {
+		out = append(out, digits[i]<<4|digits[i+1])
+	}
+	return out
+}
+
+func unhex(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// name reads a /Name whose slash was just read.
+func (l *lexer) name() name {
+	var out []byte
+	for l.pos < len(l.b) && !isSpace(l.b[l.pos]) && !isDelim(l.b[l.pos]) {
+		c := l.b[l.pos]
+		l.pos++
+		if c == '#' && l.pos+1 < len(l.b) {
+			hi, ok1 := unhex(l.b[l.pos])
+			lo, ok2 := unhex(l.b[l.pos+1])
+			if ok1 && ok2 {
+				c = hi<<4 | lo
+				l.pos += 2
+			}
+		}
+		out = append(out, c)
+	}
+	return name(out)
+}
+
+// object reads the next object.
+func (l *lexer) object() (any, error) {
+	t, err := l.token()
+	if err != nil {
+		return nil, err
+	}
+	return l.objectFrom(t)
+}
+
+// objectFrom reads the object that starts with token t.
+func (l *lexer) objectFrom(t any) (any, error) {
+	switch t := t.(type) {
+	case keyword:
+		switch t {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		case "[":
+			return l.array()
+		case "<<":
+			return l.dict()
+		}
+		return t, nil
+	case float64:
+		if l.refs && t >= 0 && t == float64(int(t)) {
+			if r, ok := l.ref(int(t)); ok {
+				return r, nil
+			}
+		}
+	}
+	return t, nil
+}
+
+// ref reads the rest of "num gen R", or leaves the position alone if
+// that isn't what follows.
+func (l *lexer) ref(num int) (ref, bool) {
+	save := l.pos
+	gen, err := l.token()
+	if g, ok := gen.(float64); ok && err == nil {
+		if r, err := l.token(); err == nil && r == keyword("R") {
+			return ref{num, int(g)}, true
+		}
+	}
+	l.pos = save
+	return ref{}, false
+}
+
+func (l *lexer) array() ([]any, error) {
+	if l.depth++; l.depth > maxNesting {
+		return nil, errMalformed
+	}
+	defer func() { l.depth-- }()
+	var out []any
+	for {
+		t, err := l.token()
+		if err != nil {
+			return out, err
+		}
+		if t == keyword("]") {
+			return out, nil
+		}
+		v, err := l.objectFrom(t)
+		if err != nil {
+			return out, err
+		}
+		out = append(out, v)
+	}
+}
+
+func (l *lexer) dict() (dict, error) {
+	if l.depth++; l.depth > maxNesting {
+		return nil, errMalformed
+	}
+	defer func() { l.depth-- }()
+	out := dict{}
+	for {
+		t, err := l.token()
+		if err != nil {
+			return out, err
+		}
+		if t == keyword(">>") {
+			return out, nil
+		}
+		key, ok := t.(name)
+		if !ok {
+			continue
+		}
+		v, err := l.object()
+		if err != nil {
+			return out, err
+		}
+		out[key] = v
+	}
+}
+
+// skipInlineImage skips the data of an inline image, from after its BI
+// operator to after its EI.
+func (l *lexer) skipInlineImage() {
+	for {
+		t, err := l.token()
+		if err != nil {
+			return
+		}
+		if t == keyword("ID") {
+			break
+		}
+	}
+	for l.pos < len(l.b) {
+		i := bytes.Index(l.b[l.pos:], []byte("EI"))
+		if i < 0 {
+			l.pos = len(l.b)
+			return
+		}
+		at := l.pos + i
+		l.pos = at + 2
+		if at > 0 && isSpace(l.b[at-1]) && (l.pos == len(l.b) || isSpace(l.b[l.pos])) {
+			return
+		}
+	}
+}
@@ -0,0 +1,524 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+// Package pdftext pulls the text out of PDF files, page by page, well
+// enough to search a manual. It reads the text-showing operators of each
+// page and maps their codes to Unicode through the fonts' ToUnicode maps.
+// It doesn't lay text out, so columns and tables come out in the order
+// they were drawn, and scanned pages, being pictures, come out blank.
+package pdftext
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrNotPDF is returned for data that isn't a PDF file.
+	ErrNotPDF = errors.New("not a PDF file")
+	// ErrEncrypted is returned for a password-protected PDF.
+	ErrEncrypted = errors.New("the PDF is encrypted")
+
+	errMalformed = errors.New("malformed PDF")
+)
+
+// maxDecoded caps the size of one decompressed stream.
+const maxDecoded = 64 << 20
+
+// maxFormDepth bounds how deeply form XObjects may draw one another.
+const maxFormDepth = 8
+
+var (
+	objHeader = regexp.MustCompile(`(\d+)[ \t\r\n\f]+(\d+)[ \t\r\n\f]+obj\b`)
+	rootRef   = regexp.MustCompile(`/Root[ \t\r\n\f]*(\d+)[ \t\r\n\f]+(\d+)[ \t\r\n\f]+R`)
+	encrypt   = regexp.MustCompile(`/Encrypt[ \t\r\n\f]*(\d+[ \t\r\n\f]+\d+[ \t\r\n\f]+R|<<)`)
+)
+
+// Pages returns the text of each page of the PDF in data, in page order.
+func Pages(data []byte) (pages []string, err error) {
+	if !bytes.Contains(data[:min(len(data), 1024)], []byte("%PDF-")) {
+		return nil, ErrNotPDF
+	}
+	if encrypt.Match(data) {
+		return nil, ErrEncrypted
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			pages, err = nil, fmt.Errorf("%w: %v", errMalformed, r)
+		}
+	}()
+	d := load(data)
+	for _, p := range d.pageList() {
+		pages = append(pages, d.pageText(p))
+	}
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("%w: no pages", errMalformed)
+	}
+	return pages, nil
+}
+
+// document finds objects by scanning for their headers rather than
+// trusting the cross-reference table, so a file whose offsets are off, as
+// those edited by hand or half-repaired often are, still reads.
+type document struct {
+	data    []byte
+	offsets map[int]int
+	packed  map[int]any
+	cache   map[int]any
+}
+
+func load(data []byte) *document {
+	d := &document{data: data, offsets: map[int]int{}, packed: map[int]any{}, cache: map[int]any{}}
+	for _, m := range objHeader.FindAllSubmatchIndex(data, -1) {
+		if m[0] > 0 && data[m[0]-1] >= '0' && data[m[0]-1] <= '9' {
+			continue
+		}
+		num, err := strconv.Atoi(string(data[m[2]:m[3]]))
+		if err == nil {
+			d.offsets[num] = m[0] // a later definition replaces an earlier one
+		}
+	}
+	for num, off := range d.offsets {
+		if bytes.Contains(data[off:min(len(data), off+512)], []byte("/ObjStm")) {
+			d.unpack(d.object(num))
+		}
+	}
+	return d
+}
+
+// unpack reads the objects compressed into an object stream.
+func (d *document) unpack(v any) {
+	s, ok := v.(*stream)
+	if !ok || s.hdr["Type"] != name("ObjStm") {
+		return
+	}
+	b, err := d.decode(s)
+	if err != nil {
+		return
+	}
+	n, _ := d.resolve(s.hdr["N"]).(float64)
+	first, _ := d.resolve(s.hdr["First"]).(float64)
+	l := &lexer{b: b, refs: true}
+	type entry struct{ num, off int }
+	var entries []entry
+	for i := 0; i < int(n); i++ {
+		num, err1 := l.token()
+		off, err2 := l.token()
+		nf, ok1 := num.(float64)
+		of, ok2 := off.(float64)
+		if err1 != nil || err2 != nil || !ok1 || !ok2 {
+			break
+		}
+		entries = append(entries, entry{int(nf), int(of)})
+	}
+	for _, e := range entries {
+		if _, direct := d.offsets[e.num]; direct {
+			continue
+		}
+		at := int(first) + e.off
+		if at < 0 || at >= len(b) {
+			continue
+		}
+		l.pos = at
+		if obj, err := l.object(); err == nil {
+			d.packed[e.num] = obj
+		}
+	}
+}
+
+func (d *document) object(num int) any {
+	if v, ok := d.cache[num]; ok {
+		return v
+	}
+	d.cache[num] = nil // a reference back to this object while reading it is null
+	var v any
+	if off, ok := d.offsets[num]; ok {
+		v = d.parseAt(off)
+	} else {
+		v = d.packed[num]
+	}
+	d.cache[num] = v
+	return v
+}
+
+// parseAt reads the object whose "num gen obj" header is at off.
+func (d *document) parseAt(off int) any {
+	l := &lexer{b: d.data, pos: off, refs: true}
+	for range 3 {
+		if _, err := l.token(); err != nil {
+			return nil
+		}
+	}
+	v, err := l.object()
+	if err != nil {
+		return nil
+	}
+	hdr, ok := v.(dict)
+	if !ok {
+		return v
+	}
+	save := l.pos
+	if t, err := l.token(); err != nil || t != keyword("stream") {
+		l.pos = save
+		return hdr
+	}
+	start := l.pos
+	if start < len(d.data) && d.data[start] == '\r' {
+		start++
+	}
+	if start < len(d.data) && d.data[start] == '\n' {
+		start++
+	}
+	if n, ok := d.resolve(hdr["Length"]).(float64); ok && n >= 0 {
+		end := start + int(n)
+		if end <= len(d.data) {
+			rest := bytes.TrimLeft(d.data[end:min(len(d.data), end+32)], " \t\r\n\f\x00")
+			if bytes.HasPrefix(rest, []byte("endstream")) {
+				return &stream{hdr: hdr, raw: d.data[start:end]}
+			}
+		}
+	}
+	// The length is missing or wrong: the data runs to endstream.
+	end := bytes.Index(d.data[start:], []byte("endstream"))
+	if end < 0 {
+		return &stream{hdr: hdr, raw: d.data[start:]}
+	}
+	raw := d.data[start : start+end]
+	raw = bytes.TrimSuffix(raw, []byte("\n"))
+	raw = bytes.TrimSuffix(raw, []byte("\r"))
+	return &stream{hdr: hdr, raw: raw}
+}
+
+// resolve follows references to the object they name.
+func (d *document) resolve(v any) any {
+	for range 32 {
+		r, ok := v.(ref)
+		if !ok {
+			return v
+		}
+		v = d.object(r.num)
+	}
+	return nil
+}
+
+func (d *document) dict(v any) dict {
+	switch v := d.resolve(v).(type) {
+	case dict:
+		return v
+	case *stream:
+		return v.hdr
+	}
+	return nil
+}
+
+// decode undoes a stream's filters.
+func (d *document) decode(s *stream) ([]byte, error) {
+	var filters []any
+	switch f := d.resolve(s.hdr["Filter"]).(type) {
+	case name:
+		filters = []any{f}
+	case []any:
+		filters = f
+	}
+	b := s.raw
+	for i, f := range filters {
+		var params dict
+		switch p := d.resolve(s.hdr["DecodeParms"]).(type) {
+		case dict:
+			params = p
+		case []any:
+			if i < len(p) {
+				params = d.dict(p[i])
+			}
+		}
+		if pred, _ := d.resolve(params["Predictor"]).(float64); pred > 1 {
+			return nil, fmt.Errorf("unsupported predictor %v", pred)
+		}
+		var err error
+		switch d.resolve(f) {
+		case name("FlateDecode"), name("Fl"):
+			b, err = inflate(b)
+		case name("ASCIIHexDecode"), name("AHx"):
+			b, err = asciiHex(b)
+		case name("ASCII85Decode"), name("A85"):
+			b, err = ascii85Decode(b)
+		default:
+			return nil, fmt.Errorf("unsupported filter %v", f)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+func inflate(b []byte) ([]byte, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	out, err := io.ReadAll(io.LimitReader(zr, maxDecoded))
+	if err != nil && len(out) == 0 {
+		return nil, err
+	}
+	// Keep what a truncated stream did hold.
+	return out, nil
+}
+
+func asciiHex(b []byte) ([]byte, error) {
+	var digits []byte
+	for _, c := range b {
+		if c == '>' {
+			break
+		}
+		if _, ok := unhex(c); ok {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	return hex.DecodeString(string(digits))
+}
+
+func ascii85Decode(b []byte) ([]byte, error) {
+	b = bytes.TrimSpace(b)
+	b = bytes.TrimPrefix(b, []byte("<~"))
+	if i := bytes.Index(b, []byte("~>")); i >= 0 {
+		b = b[:i]
+	}
+	out := make([]byte, 4*len(b)/5+4)
+	n, _, err := ascii85.Decode(out, b, true)
+	return out[:n], err
+}
+
+// page is a leaf of the page tree, with the resources it inherits.
+type page struct {
+	node      dict
+	resources dict
+}
+
+// pageList returns the pages in order, from the document catalog's page
+// tree, or, if there's no catalog to be found, every page object in
+// object number order.
+func (d *document) pageList() []page {
+	var pages []page
+	if ms := rootRef.FindAllSubmatch(d.data, -1); len(ms) > 0 {
+		num, _ := strconv.Atoi(string(ms[len(ms)-1][1]))
+		if root := d.dict(ref{num: num}); root != nil {
+			d.walk(root["Pages"], nil, map[any]bool{}, &pages)
+		}
+	}
+	if len(pages) > 0 {
+		return pages
+	}
+	nums := make([]int, 0, len(d.offsets)+len(d.packed))
+	for num := range d.offsets {
+		nums = append(nums, num)
+	}
+	for num := range d.packed {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	for _, num := range nums {
+		if node := d.dict(ref{num: num}); node["Type"] == name("Page") {
+			pages = append(pages, page{node: node, resources: d.dict(node["Resources"])})
+		}
+	}
+	return pages
+}
+
+func (d *document) walk(v any, resources dict, seen map[any]bool, pages *[]page) {
+	if r, ok := v.(ref); ok {
+		if seen[r] {
+			return
+		}
+		seen[r] = true
+	}
+	node := d.dict(v)
+	if node == nil {
+		return
+	}
+	if res := d.dict(node["Resources"]); res != nil {
+		resources = res
+	}
+	kids, ok := d.resolve(node["Kids"]).([]any)
+	if !ok || node["Type"] == name("Page") {
+		*pages = append(*pages, page{node: node, resources: resources})
+		return
+	}
+	for _, kid := range kids {
+		d.walk(kid, resources, seen, pages)
+	}
+}
+
+// pageText returns the text of a page, one line per line of text, or ""
+// if it has none that can be read.
+func (d *document) pageText(p page) string {
+	var content [][]byte
+	contents := d.resolve(p.node["Contents"])
+	parts, ok := contents.([]any)
+	if !ok {
+		parts = []any{contents}
+	}
+	for _, part := range parts {
+		if s, ok := d.resolve(part).(*stream); ok {
+			if b, err := d.decode(s); err == nil {
+				content = append(content, b)
+			}
+		}
+	}
+	w := &textWriter{}
+	d.run(bytes.Join(content, []byte("\n")), p.resources, w, 0)
+	var lines []string
+	for _, line := range strings.Split(w.b.String(), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// textWriter collects shown text, starting a new line when the text moves
+// to another line and a space when it moves along the same one.
+type textWriter struct {
+	b        strings.Builder
+	y        float64
+	shownY   float64
+	shown    bool
+	moved    bool
+	nextLine bool
+}
+
+func (w *textWriter) show(s string) {
+	if s == "" {
+		return
+	}
+	switch {
+	case w.nextLine || w.shown && math.Abs(w.y-w.shownY) > 1:
+		w.write("\n")
+	case w.moved:
+		w.write(" ")
+	}
+	w.b.WriteString(s)
+	w.shown, w.shownY, w.moved, w.nextLine = true, w.y, false, false
+}
+
+// write adds a separator unless the text already ends in one.
+func (w *textWriter) write(sep string) {
+	s := w.b.String()
+	if s == "" || strings.HasSuffix(s, "\n") || strings.HasSuffix(s, sep) {
+		return
+	}
+	w.b.WriteString(sep)
+}
+
+// run interprets a content stream's text operators.
+func (d *document) run(content []byte, resources dict, w *textWriter, depth int) {
+	fonts := d.dict(resources["Font"])
+	xobjects := d.dict(resources["XObject"])
+	var f *font
+	loaded := map[name]*font{}
+	l := &lexer{b: content}
+	var operands []any
+	for {
+		t, err := l.token()
+		if err != nil {
+			return
+		}
+		op, ok := t.(keyword)
+		if !ok {
+			operands = append(operands, t)
+			continue
+		}
+		switch op {
+		case "[", "<<", "true", "false", "null":
+			v, _ := l.objectFrom(op)
+			operands = append(operands, v)
+			continue
+		case "BI":
+			l.skipInlineImage()
+		case "BT":
+			w.y, w.moved = 0, true
+		case "Tf":
+			if len(operands) >= 1 {
+				if n, ok := operands[0].(name); ok {
+					if _, ok := loaded[n]; !ok {
+						loaded[n] = d.font(fonts[n])
+					}
+					f = loaded[n]
+				}
+			}
+		case "Td", "TD":
+			if ty, ok := number(operands, 1); ok {
+				w.y += ty
+			}
+			w.moved = true
+		case "Tm":
+			if y, ok := number(operands, 5); ok {
+				w.y = y
+			}
+			w.moved = true
+		case "T*":
+			w.nextLine = true
+		case "Tj", "'", `"`:
+			if op != "Tj" {
+				w.nextLine = true
+			}
+			if len(operands) > 0 {
+				if s, ok := operands[len(operands)-1].([]byte); ok {
+					w.show(f.text(s))
+				}
+			}
+		case "TJ":
+			if len(operands) > 0 {
+				arr, _ := operands[len(operands)-1].([]any)
+				for _, v := range arr {
+					switch v := v.(type) {
+					case []byte:
+						w.show(f.text(v))
+					case float64:
+						// A large enough gap, in thousandths of the font
+						// size, is a space between words.
+						if v < -250 {
+							w.moved = true
+						}
+					}
+				}
+			}
+		case "Do":
+			if len(operands) > 0 && depth < maxFormDepth {
+				n, _ := operands[0].(name)
+				if s, ok := d.resolve(xobjects[n]).(*stream); ok && s.hdr["Subtype"] == name("Form") {
+					if b, err := d.decode(s); err == nil {
+						res := d.dict(s.hdr["Resources"])
+						if res == nil {
+							res = resources
+						}
+						w.moved = true
+						d.run(b, res, w, depth+1)
+					}
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+}
+
+func number(operands []any, i int) (float64, bool) {
+	if i >= len(operands) {
+		return 0, false
+	}
+	f, ok := operands[i].(float64)
+	return f, ok
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package pdftext
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildPDF writes a PDF with the given objects, numbered from 1, and a
+// trailer naming object 1 as the root.
+func buildPDF(objects ...string) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.Bytes()
+}
+
+func streamObj(hdr string, data []byte) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", hdr, len(data), data)
+}
+
+func deflate(s string) []byte {
+	var b bytes.Buffer
+	zw := zlib.NewWriter(&b)
+	_, _ = zw.Write([]byte(s))
+	_ = zw.Close()
+	return b.Bytes()
+}
+
+func TestPages(t *testing.T) {
+	page1 := "BT /F1 12 Tf 72 720 Td (Quick) Tj ( start) Tj 0 -14 Td " +
+		"[(Press)-300(the)-20( )(p)10(ower button) ] TJ T* (Hold \\(3 s\\) to reset.) Tj ET\n" +
+		"BI /W 1 /H 1 /BPC 8 /CS /G ID \x00EI\x01 EI\n" +
+		"BT 1 0 0 1 72 600 Tm (Caf\\351) Tj ET"
+	cmap := `/CIDInit /ProcSet findresource begin
+12 dict begin begincmap
+1 begincodespacerange <0000> <FFFF> endcodespacerange
+2 beginbfchar <0001> <0046> <0002> <00650074> endbfchar
+1 beginbfrange <0010> <0012> <0061> endbfrange
+1 beginbfrange <0020> <0021> [<00DF> <2019>] endbfrange
+endcmap end end`
+	pdf := buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 "+
+			"/Resources << /Font << /F1 6 0 R /F2 7 0 R >> /XObject << /Fm1 9 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 5 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /Contents [10 0 R] >>",
+		streamObj("/Filter /FlateDecode", deflate(page1)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /Type /Font /Subtype /Type0 /BaseFont /Noto /Encoding /Identity-H /ToUnicode 8 0 R >>",
+		streamObj("", []byte(cmap)),
+		streamObj("/Type /XObject /Subtype /Form /BBox [0 0 100 100]", []byte("BT /F1 9 Tf (Page 2) Tj ET")),
+		streamObj("/Filter /ASCIIHexDecode", []byte(fmt.Sprintf("%X>",
+			"BT /F2 10 Tf 72 700 Td <000100100011001200020020> Tj 0 -12 Td [<0021>] TJ ET /Fm1 Do"))),
+	)
+
+	pages, err := Pages(pdf)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"Quick start\nPress the power button\nHold (3 s) to reset.\nCafé",
+		"Fabcetß\n’\nPage 2",
+	}, pages)
+}
+
+func TestPagesInObjectStream(t *testing.T) {
+	objs := []string{
+		"<< /Type /Catalog /Pages 3 0 R >>",
+		"<< /Type /Pages /Kids [4 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 3 0 R /Contents 5 0 R >>",
+	}
+	var index, body string
+	for i, obj := range objs {
+		index += fmt.Sprintf("%d %d ", i+2, len(body))
+		body += obj + "\n"
+	}
+	pdf := buildPDF(
+		streamObj(fmt.Sprintf("/Type /ObjStm /N 3 /First %d /Filter /FlateDecode", len(index)), deflate(index+body)),
+		"null", "null", "null",
+		streamObj("", []byte("BT 72 700 Td (Filter: replace yearly) Tj ET")),
+	)
+	// Object 1 is the object stream; the catalog it holds is object 2.
+	pdf = bytes.Replace(pdf, []byte("/Root 1 0 R"), []byte("/Root 2 0 R"), 1)
+	for _, n := range []string{"2", "3", "4"} {
+		pdf = bytes.Replace(pdf, []byte("\n"+n+" 0 obj\nnull\nendobj"), nil, 1)
+	}
+
+	pages, err := Pages(pdf)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Filter: replace yearly"}, pages)
+}
+
+func TestPagesErrors(t *testing.T) {
+	_, err := Pages([]byte("hello"))
+	require.ErrorIs(t, err, ErrNotPDF)
+
+	_, err = Pages(buildPDF("<< /Type /Catalog >>", "<< /Filter /Standard >>"))
+	require.ErrorContains(t, err, "no pages")
+
+	locked := bytes.Replace(buildPDF("<< /Type /Catalog >>"), []byte("/Root 1 0 R"), []byte("/Root 1 0 R /Encrypt 2 0 R"), 1)
+	_, err = Pages(locked)
+	require.ErrorIs(t, err, ErrEncrypted)
+}
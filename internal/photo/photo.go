@@ -0,0 +1,225 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+// Package photo shrinks photos as they are uploaded: scaled down to a
+// maximum size and re-encoded, so a 12 MP phone picture takes a fraction of
+// the space. The re-encoded file carries no metadata, so the EXIF
+// orientation is applied to the pixels first. It also makes the small
+// thumbnails photos are shown by in lists.
+package photo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+)
+
+// Defaults used when recompression is enabled without further settings.
+const (
+	DefaultMaxDimension = 2048
+	DefaultQuality      = 82
+)
+
+// Options controls recompression.
+type Options struct {
+	// MaxDimension caps the longer side, in pixels. 0 keeps the size.
+	MaxDimension int
+	// Quality is the JPEG quality, 1 to 100.
+	Quality int
+}
+
+// ErrUnsupported means the data is not a JPEG or PNG image.
+var ErrUnsupported = errors.New("photo: not a JPEG or PNG image")
+
+// Shrink scales and re-encodes a JPEG or PNG in its own format. ok is false
+// when the result wouldn't be smaller, in which case data should be kept
+// as it is. PNGs are only ever scaled, never made lossy.
+func Shrink(data []byte, opts Options) (out []byte, ok bool, err error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if errors.Is(err, image.ErrFormat) {
+		return nil, false, ErrUnsupported
+	} else if err != nil {
+		return nil, false, fmt.Errorf("photo: decode: %w", err)
+	}
+	if format != "jpeg" && format != "png" {
+		return nil, false, ErrUnsupported
+	}
+	orientation := 1
+	if format == "jpeg" {
+		orientation = exifOrientation(data)
+	}
+
+	b := img.Bounds()
+	w, h := fit(b.Dx(), b.Dy(), opts.MaxDimension)
+	scaled := w != b.Dx() || h != b.Dy()
+	if format == "png" && !scaled {
+		return nil, false, nil
+	}
+	if scaled || orientation != 1 {
+		rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+		if scaled {
+			rgba = downscale(rgba, w, h)
+		}
+		img = orient(rgba, orientation)
+	}
+
+	var buf bytes.Buffer
+	if format == "png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: max(1, min(opts.Quality, 100))})
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("photo: encode: %w", err)
+	}
+	if buf.Len() >= len(data) {
+		return nil, false, nil
+	}
+	return buf.Bytes(), true, nil
+}
+
+// fit scales w×h down so neither side exceeds limit, keeping the aspect
+// ratio.
+func fit(w, h, limit int) (int, int) {
+	if limit <= 0 || (w <= limit && h <= limit) {
+		return w, h
+	}
+	if w >= h {
+		return limit, max(1, h*limit/w)
+	}
+	return max(1, w*limit/h), limit
+}
+
+// downscale averages each block of source pixels into one destination
+// pixel, which keeps fine detail from aliasing the way point sampling does.
+func downscale(src *image.RGBA, dw, dh int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := range dh {
+		y0 := y * sh / dh
+		y1 := max((y+1)*sh/dh, y0+1)
+		for x := range dw {
+			x0 := x * sw / dw
+			x1 := max((x+1)*sw/dw, x0+1)
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4 : sx*4+4]
+					r += uint64(p[0])
+					g += uint64(p[1])
+					b += uint64(p[2])
+					a += uint64(p[3])
+					n++
+				}
+			}
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// orient turns an image stored with the given EXIF orientation (1-8)
+// upright.
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := range dh {
+		for x := range dw {
+			var sx, sy int
+			switch orientation {
+			case 2: // flip horizontally
+				sx, sy = w-1-x, y
+			case 3: // rotate 180°
+				sx, sy = w-1-x, h-1-y
+			case 4: // flip vertically
+				sx, sy = x, h-1-y
+			case 5: // transpose
+				sx, sy = y, x
+			case 6: // rotate 90° clockwise
+				sx, sy = y, h-1-x
+			case 7: // transverse
+				sx, sy = w-1-y, h-1-x
+			case 8: // rotate 90° counter-clockwise
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):][:4], src.Pix[src.PixOffset(sx, sy):][:4])
+		}
+	}
+	return dst
+}
+
+// exifOrientation returns the orientation tag from a JPEG's EXIF block, or
+// 1 (upright) when there is none.
+func exifOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if marker == 0xDA || size < 2 || i+2+size > len(data) {
+			return 1 // image data starts; metadata comes before it
+		}
+		seg := data[i+4 : i+2+size]
+		if marker == 0xE1 && bytes.HasPrefix(seg, []byte("Exif\x00\x00")) {
+			return tiffOrientation(seg[6:])
+		}
+		i += 2 + size
+	}
+	return 1
+}
+
+// tiffOrientation finds tag 0x0112 in the first IFD of a TIFF structure.
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	n := int(order.Uint16(tiff[ifd:]))
+	for e := range n {
+		entry := ifd + 2 + 12*e
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if v := int(order.Uint16(tiff[entry+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			return 1
+		}
+	}
+	return 1
+}
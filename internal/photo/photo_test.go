@@ -0,0 +1,145 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package photo
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testImage is w×h noise with a solid red block in the top-left corner,
+// so orientation is visible and JPEG can't compress it to nothing.
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	r := rand.New(rand.NewPCG(1, 2))
+	for y := range h {
+		for x := range w {
+			c := color.RGBA{uint8(r.IntN(256)), uint8(r.IntN(256)), uint8(r.IntN(256)), 255}
+			if x < w/4 && y < h/4 {
+				c = color.RGBA{255, 0, 0, 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}))
+	return buf.Bytes()
+}
+
+// withOrientation inserts an EXIF block carrying the orientation tag right
+// after the JPEG's start marker.
+func withOrientation(jpg []byte, orientation uint16) []byte {
+	tiff := []byte{'M', 'M', 0, 42, 0, 0, 0, 8, 0, 1,
+		0x01, 0x12, 0, 3, 0, 0, 0, 1, byte(orientation >> 8), byte(orientation), 0, 0,
+		0, 0, 0, 0}
+	seg := append([]byte("Exif\x00\x00"), tiff...)
+	n := len(seg) + 2
+	app1 := append([]byte{0xFF, 0xE1, byte(n >> 8), byte(n)}, seg...)
+	return append(append(append([]byte{}, jpg[:2]...), app1...), jpg[2:]...)
+}
+
+func decode(t *testing.T, data []byte) image.Image {
+	t.Helper()
+	img, _, err := image.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	return img
+}
+
+func isRed(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r > 0xC000 && g < 0x4000 && b < 0x4000
+}
+
+func TestShrinkScalesJPEG(t *testing.T) {
+	src := encodeJPEG(t, testImage(400, 200))
+	out, ok, err := Shrink(src, Options{MaxDimension: 100, Quality: 80})
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Less(t, len(out), len(src))
+	img := decode(t, out)
+	assert.Equal(t, image.Rect(0, 0, 100, 50), img.Bounds())
+	assert.True(t, isRed(img.At(5, 5)))
+}
+
+func TestShrinkAppliesOrientation(t *testing.T) {
+	src := withOrientation(encodeJPEG(t, testImage(400, 200)), 6)
+	assert.Equal(t, 6, exifOrientation(src))
+	out, ok, err := Shrink(src, Options{MaxDimension: 100, Quality: 80})
+	require.NoError(t, err)
+	require.True(t, ok)
+	img := decode(t, out)
+	// Turned clockwise: portrait, with the red corner now top-right.
+	assert.Equal(t, image.Rect(0, 0, 50, 100), img.Bounds())
+	assert.True(t, isRed(img.At(45, 5)))
+	assert.False(t, isRed(img.At(5, 5)))
+}
+
+func TestShrinkPNG(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, testImage(64, 64)))
+
+	_, ok, err := Shrink(buf.Bytes(), Options{MaxDimension: 100, Quality: 80})
+	require.NoError(t, err)
+	assert.False(t, ok, "PNGs within the limit are left alone")
+
+	out, ok, err := Shrink(buf.Bytes(), Options{MaxDimension: 32, Quality: 80})
+	require.NoError(t, err)
+	require.True(t, ok)
+	img, format, err := image.Decode(bytes.NewReader(out))
+	require.NoError(t, err)
+	assert.Equal(t, "png", format)
+	assert.Equal(t, image.Rect(0, 0, 32, 32), img.Bounds())
+}
+
+func TestShrinkRejectsOtherData(t *testing.T) {
+	_, _, err := Shrink([]byte("%PDF-1.7"), Options{MaxDimension: 100, Quality: 80})
+	assert.ErrorIs(t, err, ErrUnsupported)
+	assert.Equal(t, 1, exifOrientation([]byte("not a jpeg")))
+}
+
+func TestFit(t *testing.T) {
+	w, h := fit(4032, 3024, 2048)
+	assert.Equal(t, [2]int{2048, 1536}, [2]int{w, h})
+	w, h = fit(3024, 4032, 2048)
+	assert.Equal(t, [2]int{1536, 2048}, [2]int{w, h})
+	w, h = fit(800, 600, 2048)
+	assert.Equal(t, [2]int{800, 600}, [2]int{w, h})
+	w, h = fit(800, 600, 0)
+	assert.Equal(t, [2]int{800, 600}, [2]int{w, h})
+}
+
+func TestThumbnail(t *testing.T) {
+	src := withOrientation(encodeJPEG(t, testImage(400, 200)), 6)
+	out, err := Thumbnail(src, 40)
+	require.NoError(t, err)
+	img, format, err := image.Decode(bytes.NewReader(out))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, image.Rect(0, 0, 20, 40), img.Bounds())
+	assert.True(t, isRed(img.At(17, 2)))
+
+	clear := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, clear))
+	out, err = Thumbnail(buf.Bytes(), 40)
+	require.NoError(t, err)
+	r, g, b, _ := decode(t, out).At(5, 5).RGBA()
+	assert.Greater(t, min(r, g, b), uint32(0xF000), "transparency comes out white")
+
+	_, err = Thumbnail([]byte("%PDF-1.7"), 40)
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
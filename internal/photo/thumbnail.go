@@ -0,0 +1,53 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package photo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+)
+
+// Thumbnail defaults: the longer side in pixels, and the JPEG quality.
+const (
+	ThumbnailSize    = 320
+	thumbnailQuality = 75
+)
+
+// Thumbnail makes a small upright JPEG of a JPEG or PNG, its longer side
+// at most size pixels, for showing photos in lists. Transparent parts of a
+// PNG come out white.
+func Thumbnail(data []byte, size int) ([]byte, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if errors.Is(err, image.ErrFormat) {
+		return nil, ErrUnsupported
+	} else if err != nil {
+		return nil, fmt.Errorf("photo: decode: %w", err)
+	}
+	if format != "jpeg" && format != "png" {
+		return nil, ErrUnsupported
+	}
+	orientation := 1
+	if format == "jpeg" {
+		orientation = exifOrientation(data)
+	}
+
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Over)
+	if w, h := fit(b.Dx(), b.Dy(), size); w != b.Dx() || h != b.Dy() {
+		rgba = downscale(rgba, w, h)
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, orient(rgba, orientation), &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, fmt.Errorf("photo: encode: %w", err)
+	}
+	return buf.Bytes(), nil
+}
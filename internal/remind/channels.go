@@ -0,0 +1,329 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package remind
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/exports"
+)
+
+// Notice is what a channel delivers.
+type Notice struct {
+	Subject   string     `json:"subject"`
+	Body      string     `json:"body"`
+	Reminders []Reminder `json:"reminders"`
+
+	// render, when set, renders Body with chat markup for links.
+	render func(markup) string
+}
+
+// body is the notice's body in the markup; Slack's is escaped.
+func (n Notice) body(m markup) string {
+	if n.render != nil {
+		return n.render(m)
+	}
+	return m.escape(n.Body)
+}
+
+// Channel delivers reminders somewhere.
+type Channel interface {
+	Notify(ctx context.Context, n Notice) error
+	String() string
+}
+
+// ParseChannel turns a configured channel into a Channel: "stdout",
+// "desktop", mailto:address, ntfy:https://server/topic,
+// slack:<incoming webhook URL>, discord:<webhook URL>, or an http(s) URL
+// to POST JSON to. Stdout writes to out.
+func ParseChannel(to string, mail exports.SMTPSettings, out io.Writer) (Channel, error) {
+	switch {
+	case to == "stdout":
+		return WriterChannel{W: out}, nil
+	case to == "desktop":
+		return DesktopChannel{}, nil
+	case strings.HasPrefix(to, "mailto:"):
+		addr := strings.TrimPrefix(to, "mailto:")
+		if !strings.Contains(addr, "@") {
+			return nil, fmt.Errorf("invalid email channel %q", to)
+		}
+		if mail.Host == "" {
+			return nil, fmt.Errorf("email channel %q needs [smtp] host", to)
+		}
+		return &EmailChannel{To: addr, Settings: mail}, nil
+	case strings.HasPrefix(to, "ntfy:"):
+		u, err := url.Parse(strings.TrimPrefix(to, "ntfy:"))
+		if err != nil || u.Host == "" || strings.Trim(u.Path, "/") == "" {
+			return nil, fmt.Errorf("invalid ntfy channel %q -- expected ntfy:https://ntfy.sh/topic", to)
+		}
+		return &HTTPChannel{URL: u.String(), Ntfy: true}, nil
+	case strings.HasPrefix(to, "slack:"), strings.HasPrefix(to, "discord:"):
+		kind, hook, _ := strings.Cut(to, ":")
+		if u, err := url.Parse(hook); err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+			return nil, fmt.Errorf("invalid %s channel %q -- expected %s:https://<webhook URL>", kind, to, kind)
+		}
+		return &ChatChannel{URL: hook, Discord: kind == "discord"}, nil
+	case strings.HasPrefix(to, "http://"), strings.HasPrefix(to, "https://"):
+		if u, err := url.Parse(to); err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid webhook channel %q", to)
+		}
+		return &HTTPChannel{URL: to}, nil
+	}
+	return nil, fmt.Errorf(
+		"unknown channel %q -- use stdout, desktop, mailto:address, ntfy:URL, slack:URL, discord:URL, or an http(s) URL", to)
+}
+
+// WriterChannel prints reminders.
+type WriterChannel struct{ W io.Writer }
+
+func (WriterChannel) String() string { return "stdout" }
+
+func (c WriterChannel) Notify(_ context.Context, n Notice) error {
+	_, err := fmt.Fprintf(c.W, "%s\n\n%s", n.Subject, n.Body)
+	return err
+}
+
+// DesktopChannel pops up a notification with notify-send, or osascript on
+// macOS.
+type DesktopChannel struct{}
+
+func (DesktopChannel) String() string { return "desktop" }
+
+func (DesktopChannel) Notify(ctx context.Context, n Notice) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		script := fmt.Sprintf("display notification %s with title %s",
+			strconv.Quote(n.Body), strconv.Quote(n.Subject))
+		cmd = exec.CommandContext(ctx, "osascript", "-e", script)
+	} else {
+		cmd = exec.CommandContext(ctx, "notify-send", "--app-name=webcasa", n.Subject, n.Body)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// HTTPChannel POSTs reminders: as JSON to a webhook, or as plain text with
+// a Title header to an ntfy topic.
+type HTTPChannel struct {
+	URL  string
+	Ntfy bool
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+func (c *HTTPChannel) String() string {
+	if c.Ntfy {
+		return "ntfy:" + c.URL
+	}
+	return c.URL
+}
+
+func (c *HTTPChannel) Notify(ctx context.Context, n Notice) error {
+	body, contentType := []byte(n.Body), "text/plain; charset=utf-8"
+	if !c.Ntfy {
+		var err error
+		if body, err = json.Marshal(n); err != nil {
+			return err
+		}
+		contentType = "application/json"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if c.Ntfy {
+		req.Header.Set("Title", n.Subject)
+		req.Header.Set("Tags", "house")
+	}
+	return post(c.Client, req)
+}
+
+// post sends req, turning a non-2xx answer into an error with the start
+// of its body. client defaults to http.DefaultClient.
+func post(client *http.Client, req *http.Request) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		var msg bytes.Buffer
+		_, _ = msg.ReadFrom(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(msg.String()))
+	}
+	return nil
+}
+
+// discordLimit is the most characters Discord takes in a message.
+const discordLimit = 2000
+
+// ChatChannel posts reminders to a Slack incoming webhook or a Discord
+// channel webhook as one message: the subject in bold over the list.
+type ChatChannel struct {
+	URL     string
+	Discord bool
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// String names the channel without the webhook's path, which is its
+// secret.
+func (c *ChatChannel) String() string {
+	kind := "slack"
+	if c.Discord {
+		kind = "discord"
+	}
+	if u, err := url.Parse(c.URL); err == nil {
+		return kind + ":" + u.Scheme + "://" + u.Host + "/..."
+	}
+	return kind
+}
+
+func (c *ChatChannel) Notify(ctx context.Context, n Notice) error {
+	var payload any
+	if c.Discord {
+		text := "**" + n.Subject + "**\n" + n.body(discordMarkup)
+		if runes := []rune(text); len(runes) > discordLimit {
+			text = string(runes[:discordLimit-1]) + "…"
+		}
+		payload = map[string]any{
+			"username": "webcasa",
+			"content":  text,
+			// Titles are the house's own text; don't let them ping anyone.
+			"allowed_mentions": map[string]any{"parse": []string{}},
+		}
+	} else {
+		payload = map[string]string{"text": "*" + slackEscape(n.Subject) + "*\n" + n.body(slackMarkup)}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return post(c.Client, req)
+}
+
+// slackEscape escapes the characters Slack treats as markup.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// Filter picks which reminders a channel gets. The zero Filter keeps
+// everything.
+type Filter struct {
+	// OverdueOnly keeps only reminders whose date has passed.
+	OverdueOnly bool
+	// Kinds keeps only reminders of these kinds, when set.
+	Kinds []string
+	// MinAmountCents keeps only reminders with an amount, such as
+	// approvals and rebates, of at least this much, when set.
+	MinAmountCents int64
+}
+
+// Apply returns the reminders the filter keeps.
+func (f Filter) Apply(reminders []Reminder, now time.Time) []Reminder {
+	var kept []Reminder
+	for _, r := range reminders {
+		switch {
+		case f.OverdueOnly && !r.Overdue(now),
+			len(f.Kinds) > 0 && !slices.Contains(f.Kinds, r.Kind),
+			f.MinAmountCents > 0 && (r.AmountCents == nil || abs(*r.AmountCents) < f.MinAmountCents):
+			continue
+		}
+		kept = append(kept, r)
+	}
+	return kept
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Filtered is a channel with settings of its own: it gets only the
+// reminders its filter keeps, optionally as a digest, and may have its
+// own quiet hours.
+type Filtered struct {
+	Channel
+	Filter Filter
+	// Digest is DigestDaily or DigestWeekly to send the reminders grouped
+	// under headings, at most once per period when scheduled.
+	Digest string
+	// ID identifies the channel from one run to the next, such as its
+	// configured address, so a digest isn't sent twice in a period.
+	// Default: the channel's String.
+	ID string
+	// QuietHours, when set, replace the rules' quiet hours for this
+	// channel.
+	QuietHours QuietHours
+}
+
+// EmailChannel mails reminders as plain text.
+type EmailChannel struct {
+	To       string
+	Settings exports.SMTPSettings
+}
+
+func (c *EmailChannel) String() string { return "mailto:" + c.To }
+
+// Notify sends through the configured SMTP server, using STARTTLS when the
+// server offers it.
+func (c *EmailChannel) Notify(_ context.Context, n Notice) error {
+	port := c.Settings.Port
+	if port == 0 {
+		port = 587
+	}
+	from := c.Settings.From
+	if from == "" {
+		from = c.Settings.Username
+	}
+	if from == "" {
+		return fmt.Errorf("set [smtp] from to send reminders by email")
+	}
+	var auth smtp.Auth
+	if c.Settings.Username != "" {
+		auth = smtp.PlainAuth("", c.Settings.Username, c.Settings.Password, c.Settings.Host)
+	}
+	addr := net.JoinHostPort(c.Settings.Host, strconv.Itoa(port))
+	return smtp.SendMail(addr, auth, from, []string{c.To}, message(from, c.To, n, time.Now()))
+}
+
+func message(from, to string, n Notice, at time.Time) []byte {
+	var m bytes.Buffer
+	fmt.Fprintf(&m, "From: %s\r\n", from)
+	fmt.Fprintf(&m, "To: %s\r\n", to)
+	fmt.Fprintf(&m, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", n.Subject))
+	fmt.Fprintf(&m, "Date: %s\r\n", at.Format(time.RFC1123Z))
+	m.WriteString("MIME-Version: 1.0\r\n")
+	m.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	m.WriteString(strings.ReplaceAll(n.Body, "\n", "\r\n"))
+	return m.Bytes()
+}
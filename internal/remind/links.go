@@ -0,0 +1,182 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package remind
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// Link actions: mark a reminder's record done, or snooze its reminders.
+const (
+	ActionDone   = "done"
+	ActionSnooze = "snooze"
+)
+
+// LinkTTL is how long an action link in a reminder keeps working.
+const LinkTTL = 14 * 24 * time.Hour
+
+// SnoozeFor is how long a snooze link holds a reminder off.
+const SnoozeFor = 7 * 24 * time.Hour
+
+// settingLinkKey holds the key action links are signed with.
+const settingLinkKey = "remind.link_key"
+
+// ErrBadLink means an action link was altered, is for something else, or
+// has expired.
+var ErrBadLink = errors.New("this link is invalid or has expired")
+
+// Links are the one-tap links sent with a reminder. Done and Snooze are
+// signed, so they work without signing in; Open goes to the record in the
+// web app.
+type Links struct {
+	Done   string `json:"done,omitempty"`
+	Snooze string `json:"snooze,omitempty"`
+	Open   string `json:"open,omitempty"`
+}
+
+// CanComplete reports whether a reminder of the kind can be marked done:
+// maintenance is logged as serviced, a change order approved and a task
+// ticked off. The rest can only be snoozed.
+func CanComplete(kind string) bool {
+	switch kind {
+	case KindMaintenance, KindApproval, KindProjectTask, KindChecklist:
+		return true
+	}
+	return false
+}
+
+// LinkKey returns the key action links are signed with, making one the
+// first time.
+func LinkKey(store *data.Store) ([]byte, error) {
+	stored, err := store.GetSetting(settingLinkKey)
+	if err != nil {
+		return nil, err
+	}
+	if key, err := hex.DecodeString(stored); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := store.PutSetting(settingLinkKey, hex.EncodeToString(key)); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func signature(key []byte, action, kind string, id uint, expires int64) string {
+	mac := hmac.New(sha256.New, key)
+	fmt.Fprintf(mac, "%s\n%s\n%d\n%d", action, kind, id, expires)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// LinkPath is the path, below the server's root, of an action link for
+// the reminder of the kind about record id.
+func LinkPath(action, kind string, id uint) string {
+	return "/api/reminders/" + url.PathEscape(kind) + "/" + strconv.FormatUint(uint64(id), 10) + "/" + action
+}
+
+// SignLink returns the query that makes an action link work until
+// expires.
+func SignLink(key []byte, action, kind string, id uint, expires time.Time) url.Values {
+	exp := expires.Unix()
+	return url.Values{
+		"exp": {strconv.FormatInt(exp, 10)},
+		"sig": {signature(key, action, kind, id, exp)},
+	}
+}
+
+// VerifyLink checks an action link's query at now.
+func VerifyLink(key []byte, action, kind string, id uint, query url.Values, now time.Time) error {
+	exp, err := strconv.ParseInt(query.Get("exp"), 10, 64)
+	if err != nil || now.Unix() > exp {
+		return ErrBadLink
+	}
+	want := signature(key, action, kind, id, exp)
+	if !hmac.Equal([]byte(want), []byte(query.Get("sig"))) {
+		return ErrBadLink
+	}
+	return nil
+}
+
+// addLinks gives each reminder its links under base, the address webcasa
+// is reached at.
+func addLinks(store *data.Store, reminders []Reminder, base string, now time.Time) error {
+	key, err := LinkKey(store)
+	if err != nil {
+		return fmt.Errorf("load link key: %w", err)
+	}
+	expires := now.Add(LinkTTL)
+	link := func(action string, r Reminder) string {
+		return base + LinkPath(action, r.Kind, r.ID) + "?" + SignLink(key, action, r.Kind, r.ID, expires).Encode()
+	}
+	for i, r := range reminders {
+		l := &Links{Snooze: link(ActionSnooze, r)}
+		if CanComplete(r.Kind) {
+			l.Done = link(ActionDone, r)
+		}
+		if r.open != "" {
+			l.Open = base + "/#" + r.open
+		}
+		reminders[i].Links = l
+	}
+	return nil
+}
+
+// Act carries out an action link's action at now and says what was done.
+// It returns ErrBadLink for an action the kind doesn't have.
+func Act(store *data.Store, action, kind string, id uint, now time.Time) (string, error) {
+	switch {
+	case action == ActionDone && kind == KindMaintenance:
+		item, err := store.GetMaintenance(id)
+		if err != nil {
+			return "", err
+		}
+		if err := store.MarkMaintenanceDone(id, now, "Marked done from a reminder"); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Logged %s as done today.", item.Name), nil
+	case action == ActionDone && kind == KindApproval:
+		co, err := store.ApproveChangeOrder(id, now)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Approved the %s change order: %s.", dollars(co.AmountCents), co.Reason), nil
+	case action == ActionDone && kind == KindProjectTask:
+		task, err := store.GetProjectTask(id)
+		if err != nil {
+			return "", err
+		}
+		task.Status = data.TaskStatusDone
+		if err := store.UpdateProjectTask(task, now); err != nil {
+			return "", err
+		}
+		return "Ticked off " + task.Title + ".", nil
+	case action == ActionDone && kind == KindChecklist:
+		task, err := store.SetHouseEventTaskDone(id, true, now)
+		if err != nil {
+			return "", err
+		}
+		return "Ticked off " + task.Title + ".", nil
+	case action == ActionSnooze:
+		until := now.Add(SnoozeFor)
+		if err := store.SnoozeReminder(kind, id, until); err != nil {
+			return "", err
+		}
+		return "No more reminders about this until " + until.Format("Mon Jan 2") + ".", nil
+	}
+	return "", ErrBadLink
+}
@@ -0,0 +1,550 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+// Package remind gathers what is coming due -- maintenance, warranty ends,
+// the insurance renewal, change orders awaiting approval, projects over
+// budget -- and sends it to
+// stdout, the desktop, an email inbox, an ntfy topic, Slack, Discord or a
+// webhook, either once from the command line or on a schedule in the
+// server. Week lists it with the week's tasks and appointments, for
+// triage.
+package remind
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/sched"
+	"gorm.io/gorm"
+)
+
+// DefaultDays is how far ahead reminders look by default.
+const DefaultDays = 14
+
+// JobName is the reminder job's name among the scheduler's jobs.
+const JobName = "remind"
+
+// Reminder kinds. KindWarranty is the warranty date an appliance carries;
+// KindPolicy is a warranty on file. KindRebate is a rebate to apply for
+// or to chase.
+const (
+	KindMaintenance = "maintenance"
+	KindWarranty    = "warranty"
+	KindPolicy      = "warranty_policy"
+	KindInsurance   = "insurance"
+	KindApproval    = "approval"
+	KindBudget      = "budget"
+	KindRebate      = "rebate"
+)
+
+// Kinds returns the reminder kinds, for validating a channel's filter.
+func Kinds() []string {
+	return []string{KindMaintenance, KindWarranty, KindPolicy, KindInsurance, KindApproval, KindBudget, KindRebate}
+}
+
+// Digest periods. A digest channel gets one message grouping everything,
+// at most once a day or once a week however often the reminders run.
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// settingDigestPrefix keys when each digest channel was last sent.
+const settingDigestPrefix = "remind.digest."
+
+// Reminder is one thing coming due. An approval is due from the day it was
+// asked for, and carries the amount at stake; a budget alert is due when
+// it is collected, and carries the amount over; a rebate carries what it
+// pays. ID is the record it is about: the maintenance item, appliance,
+// warranty, house, change order, project or rebate.
+type Reminder struct {
+	Kind        string    `json:"kind"`
+	ID          uint      `json:"id"`
+	Title       string    `json:"title"`
+	Due         time.Time `json:"due"`
+	AmountCents *int64    `json:"amount_cents,omitempty"`
+	// Links are set when reminders know the address webcasa is reached
+	// at.
+	Links *Links `json:"links,omitempty"`
+
+	// open is where the record is shown in the web app, e.g. "projects/3".
+	open string
+}
+
+// Overdue reports whether the reminder's date has passed. Approvals wait
+// on someone's say-so and budget alerts on a decision, not a date, so they
+// are never overdue.
+func (r Reminder) Overdue(now time.Time) bool {
+	return r.Kind != KindApproval && r.Kind != KindBudget && r.Due.Before(now)
+}
+
+// Collect returns what falls due within days of now, overdue maintenance
+// included, soonest first. Snoozed reminders are left out.
+func Collect(store *data.Store, now time.Time, days int) ([]Reminder, error) {
+	within := time.Duration(days) * 24 * time.Hour
+	var out []Reminder
+	page := func(name string, id uint) string { return name + "/" + strconv.FormatUint(uint64(id), 10) }
+
+	items, err := store.ListMaintenanceDue(now, within)
+	if err != nil {
+		return nil, fmt.Errorf("list maintenance due: %w", err)
+	}
+	for _, m := range items {
+		out = append(out, Reminder{
+			Kind: KindMaintenance, ID: m.ID, Title: m.Name, Due: *m.NextDueAt, open: page("maintenance", m.ID),
+		})
+	}
+
+	warranties, err := store.ListExpiringWarranties(now, 0, within)
+	if err != nil {
+		return nil, fmt.Errorf("list expiring warranties: %w", err)
+	}
+	for _, w := range warranties {
+		r := Reminder{Kind: KindPolicy, ID: w.ID, Title: w.Title() + " ends", Due: w.ExpiresOn, open: page("warranties", w.ID)}
+		if w.ID == 0 {
+			r.Kind, r.ID, r.open = KindWarranty, *w.ApplianceID, page("appliances", *w.ApplianceID)
+		}
+		out = append(out, r)
+	}
+
+	rebates, err := store.ListRebatesDue(now, within)
+	if err != nil {
+		return nil, fmt.Errorf("list rebates due: %w", err)
+	}
+	for _, rb := range rebates {
+		out = append(out, Reminder{
+			Kind: KindRebate, ID: rb.ID, Title: rb.NextStep(), Due: *rb.DueOn(), AmountCents: &rb.AmountCents,
+			open: page("rebates", rb.ID),
+		})
+	}
+
+	house, err := store.HouseProfile()
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("load house profile: %w", err)
+	}
+	if r := house.InsuranceRenewal; r != nil && !r.Before(now) && !r.After(now.Add(within)) {
+		title := "Homeowner's insurance renews"
+		if house.InsuranceCarrier != "" {
+			title += " (" + house.InsuranceCarrier + ")"
+		}
+		out = append(out, Reminder{Kind: KindInsurance, ID: house.ID, Title: title, Due: *r, open: "house"})
+	}
+
+	pending, err := store.ListPendingChangeOrders()
+	if err != nil {
+		return nil, fmt.Errorf("list pending change orders: %w", err)
+	}
+	for _, co := range pending {
+		out = append(out, Reminder{
+			Kind: KindApproval,
+			ID:   co.ID,
+			Title: fmt.Sprintf("Approve %s change order on %s: %s",
+				dollars(co.AmountCents), co.Project.Title, co.Reason),
+			Due:         co.CreatedAt,
+			AmountCents: &co.AmountCents,
+			open:        page("projects", co.ProjectID),
+		})
+	}
+
+	projects, err := store.ListActiveProjects()
+	if err != nil {
+		return nil, fmt.Errorf("list active projects: %w", err)
+	}
+	for _, p := range projects {
+		f, err := store.ProjectFinancials(p.ID)
+		if err != nil {
+			return nil, fmt.Errorf("project %d financials: %w", p.ID, err)
+		}
+		if f.EffectiveBudgetCents == nil {
+			continue
+		}
+		spent := f.InvoicedCents
+		if p.ActualCents != nil && *p.ActualCents > spent {
+			spent = *p.ActualCents
+		}
+		if over := spent - *f.EffectiveBudgetCents; over > 0 {
+			out = append(out, Reminder{
+				Kind: KindBudget,
+				ID:   p.ID,
+				Title: fmt.Sprintf("%s is %s over its %s budget",
+					p.Title, dollars(over), dollars(*f.EffectiveBudgetCents)),
+				Due:         now,
+				AmountCents: &over,
+				open:        page("projects", p.ID),
+			})
+		}
+	}
+
+	out, err = dropSnoozed(store, out, now)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Due.Before(out[j].Due) })
+	return out, nil
+}
+
+// dropSnoozed leaves out the reminders snoozed at now.
+func dropSnoozed(store *data.Store, reminders []Reminder, now time.Time) ([]Reminder, error) {
+	snoozes, err := store.ReminderSnoozes(now)
+	if err != nil {
+		return nil, fmt.Errorf("list snoozed reminders: %w", err)
+	}
+	if len(snoozes) == 0 {
+		return reminders, nil
+	}
+	snoozed := make(map[stateKey]bool, len(snoozes))
+	for _, sn := range snoozes {
+		snoozed[stateKey{sn.Kind, sn.TargetID}] = true
+	}
+	return slices.DeleteFunc(reminders, func(r Reminder) bool { return snoozed[keyOf(r)] }), nil
+}
+
+// Subject is a one-line summary of the reminders, for a notification title
+// or an email subject.
+func Subject(reminders []Reminder, now time.Time) string {
+	return "webcasa: " + counts(reminders, now)
+}
+
+// DigestSubject is Subject for a daily or weekly digest.
+func DigestSubject(period string, reminders []Reminder, now time.Time) string {
+	return "webcasa " + period + " digest: " + counts(reminders, now)
+}
+
+func counts(reminders []Reminder, now time.Time) string {
+	overdue := 0
+	for _, r := range reminders {
+		if r.Overdue(now) {
+			overdue++
+		}
+	}
+	s := fmt.Sprintf("%d coming up", len(reminders))
+	if overdue > 0 {
+		s += fmt.Sprintf(", %d overdue", overdue)
+	}
+	return s
+}
+
+// Text lists the reminders one per line, each followed by its links.
+func Text(reminders []Reminder, now time.Time) string {
+	return text(reminders, now, plain)
+}
+
+func text(reminders []Reminder, now time.Time, m markup) string {
+	var b strings.Builder
+	for _, r := range reminders {
+		fmt.Fprintf(&b, "%s  %s\n", when(r, now), m.escape(r.Title))
+		b.WriteString(m.links(r.Links, "  "))
+	}
+	return b.String()
+}
+
+// markup is how a channel shows a reminder's links.
+type markup int
+
+const (
+	plain markup = iota
+	slackMarkup
+	discordMarkup
+)
+
+func (m markup) escape(s string) string {
+	if m == slackMarkup {
+		return slackEscape(s)
+	}
+	return s
+}
+
+// links renders a reminder's links on their own lines, indented, or ""
+// when it has none.
+func (m markup) links(l *Links, indent string) string {
+	if l == nil {
+		return ""
+	}
+	var parts []string
+	for _, link := range []struct{ label, url string }{
+		{"Mark done", l.Done}, {"Snooze a week", l.Snooze}, {"Open in webcasa", l.Open},
+	} {
+		switch {
+		case link.url == "":
+		case m == slackMarkup:
+			parts = append(parts, "<"+link.url+"|"+link.label+">")
+		case m == discordMarkup:
+			parts = append(parts, "["+link.label+"](<"+link.url+">)")
+		default:
+			parts = append(parts, indent+"  "+link.label+": "+link.url+"\n")
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	if m == plain {
+		return strings.Join(parts, "")
+	}
+	return indent + "  " + strings.Join(parts, " · ") + "\n"
+}
+
+func when(r Reminder, now time.Time) string {
+	s := r.Due.Local().Format("Mon Jan 2")
+	switch {
+	case r.Kind == KindApproval:
+		s += " (waiting)"
+	case r.Kind == KindBudget:
+		s += " (over budget)"
+	case r.Overdue(now):
+		s += " (overdue)"
+	}
+	return s
+}
+
+// digestGroups are the headings of a digest, in order, and which
+// reminders go under each.
+var digestGroups = []struct {
+	heading string
+	keep    func(r Reminder, now time.Time) bool
+}{
+	{"Overdue", func(r Reminder, now time.Time) bool { return r.Overdue(now) }},
+	{"Coming up", func(r Reminder, now time.Time) bool {
+		return (r.Kind == KindMaintenance || r.Kind == KindRebate) && !r.Overdue(now)
+	}},
+	{"Expiring", func(r Reminder, now time.Time) bool {
+		return (r.Kind == KindWarranty || r.Kind == KindPolicy || r.Kind == KindInsurance) && !r.Overdue(now)
+	}},
+	{"Over budget", func(r Reminder, _ time.Time) bool { return r.Kind == KindBudget }},
+	{"Awaiting approval", func(r Reminder, _ time.Time) bool { return r.Kind == KindApproval }},
+}
+
+// DigestText lists the reminders under headings -- overdue, maintenance
+// and rebates coming up, warranties and insurance expiring, projects over budget and
+// change orders awaiting approval -- leaving out empty ones.
+func DigestText(reminders []Reminder, now time.Time) string {
+	return digestText(reminders, now, plain)
+}
+
+func digestText(reminders []Reminder, now time.Time, m markup) string {
+	var b strings.Builder
+	for _, g := range digestGroups {
+		var kept []Reminder
+		for _, r := range reminders {
+			if g.keep(r, now) {
+				kept = append(kept, r)
+			}
+		}
+		if len(kept) == 0 {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s (%d)\n", g.heading, len(kept))
+		for _, r := range kept {
+			fmt.Fprintf(&b, "  %s  %s\n", r.Due.Local().Format("Mon Jan 2"), m.escape(r.Title))
+			b.WriteString(m.links(r.Links, "  "))
+		}
+	}
+	return b.String()
+}
+
+// digestDue reports whether a digest last sent at last is due again at
+// now: on a new day, or in a new week starting Monday.
+func digestDue(period string, last, now time.Time) bool {
+	last, now = last.Local(), now.Local()
+	if period == DigestWeekly {
+		ly, lw := last.ISOWeek()
+		ny, nw := now.ISOWeek()
+		return ly != ny || lw != nw
+	}
+	return last.Format(time.DateOnly) != now.Format(time.DateOnly)
+}
+
+// channelID identifies a channel from one run to the next, by its ID if
+// it has one or else its name, hashed since it may hold a webhook's
+// secret.
+func channelID(c Channel) string {
+	id := c.String()
+	if f, ok := c.(*Filtered); ok && f.ID != "" {
+		id = f.ID
+	}
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:8])
+}
+
+// dollars formats cents as whole dollars, e.g. "$2,500" or "-$300".
+func dollars(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	s := strconv.FormatInt((cents+50)/100, 10)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return sign + "$" + s
+}
+
+// Send collects the reminders and sends them to every channel, each
+// getting only what its filter keeps, and digest channels getting them
+// grouped. Nothing is sent to a channel with nothing coming up. Every
+// channel is tried; the errors are joined.
+func Send(ctx context.Context, store *data.Store, channels []Channel, now time.Time, days int) error {
+	return Job{Days: days, Channels: channels}.Send(ctx, store, now)
+}
+
+// Send sends the job's reminders once, as Send does, with links when the
+// job has a URL.
+func (j Job) Send(ctx context.Context, store *data.Store, now time.Time) error {
+	return j.send(ctx, store, now, nil)
+}
+
+// send is Send. A scheduled run passes its rules: then channels in their
+// quiet hours are skipped, reminders are held back and escalated by the
+// rules, and a digest channel is skipped if it already got its digest
+// this day or week. Digests always cover everything.
+func (j Job) send(ctx context.Context, store *data.Store, now time.Time, rules *Rules) error {
+	channels := j.Channels
+	reminders, err := Collect(store, now, j.Days)
+	if err != nil {
+		return err
+	}
+	if j.URL != "" && len(reminders) > 0 {
+		if err := addLinks(store, reminders, j.URL, now); err != nil {
+			return err
+		}
+	}
+	states := map[string]map[stateKey]data.ReminderState{}
+	if rules != nil {
+		saved, err := store.ReminderStates()
+		if err != nil {
+			return fmt.Errorf("load reminder states: %w", err)
+		}
+		for _, st := range saved {
+			if states[st.Channel] == nil {
+				states[st.Channel] = map[stateKey]data.ReminderState{}
+			}
+			states[st.Channel][stateKey{st.Kind, st.TargetID}] = st
+		}
+	}
+
+	var errs []error
+	// deliver sends what plan picks from list to c, returning what it
+	// escalated.
+	deliver := func(c Channel, list []Reminder, plan Rules, escalated bool) []Reminder {
+		if rules != nil && rules.quietHours(c).Contains(now) {
+			return nil
+		}
+		id := channelID(c)
+		f, _ := c.(*Filtered)
+		digest := f != nil && f.Digest != ""
+		var up []Reminder
+		if rules != nil && !digest {
+			list, up = plan.plan(list, states[id], now)
+		}
+		if f != nil {
+			list = f.Filter.Apply(list, now)
+		}
+		if len(list) == 0 {
+			return up
+		}
+		n := Notice{Subject: Subject(list, now), Body: Text(list, now), Reminders: list}
+		n.render = func(m markup) string { return text(list, now, m) }
+		if escalated {
+			n.Subject = fmt.Sprintf("webcasa: %d still overdue", len(list))
+		}
+		if digest {
+			n.Subject, n.Body = DigestSubject(f.Digest, list, now), DigestText(list, now)
+			n.render = func(m markup) string { return digestText(list, now, m) }
+			if rules != nil {
+				last, err := store.GetSetting(settingDigestPrefix + id)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", c, err))
+					return nil
+				}
+				if t, err := time.Parse(time.RFC3339, last); err == nil && !digestDue(f.Digest, t, now) {
+					return nil
+				}
+			}
+		}
+		if err := c.Notify(ctx, n); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c, err))
+			return up
+		}
+		switch {
+		case rules == nil:
+		case digest:
+			if err := store.PutSetting(settingDigestPrefix+id, now.Format(time.RFC3339)); err != nil {
+				errs = append(errs, fmt.Errorf("%s: record digest: %w", c, err))
+			}
+		default:
+			for _, r := range list {
+				st := data.ReminderState{
+					Channel: id, Kind: r.Kind, TargetID: r.ID,
+					Due: r.Due, Overdue: r.Overdue(now), Sent: 1, LastSentAt: now,
+				}
+				if prev, ok := states[id][keyOf(r)]; ok && prev.Due.Equal(r.Due) && prev.Overdue == st.Overdue {
+					st.Sent = prev.Sent + 1
+				}
+				if err := store.SaveReminderState(st); err != nil {
+					errs = append(errs, fmt.Errorf("%s: record reminder: %w", c, err))
+				}
+			}
+		}
+		return up
+	}
+
+	var escalate []Reminder
+	seen := map[stateKey]bool{}
+	for _, c := range channels {
+		plan := Rules{}
+		if rules != nil {
+			plan = *rules
+		}
+		for _, r := range deliver(c, reminders, plan, false) {
+			if !seen[keyOf(r)] {
+				seen[keyOf(r)] = true
+				escalate = append(escalate, r)
+			}
+		}
+	}
+	if rules != nil && len(escalate) > 0 {
+		for _, c := range rules.EscalateTo {
+			deliver(c, escalate, Rules{RenotifyDays: rules.RenotifyDays}, true)
+		}
+	}
+	if rules != nil {
+		if err := store.PruneReminderStates(now.Add(-forgetAfter)); err != nil {
+			errs = append(errs, fmt.Errorf("prune reminder states: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Job is the configured reminder run.
+type Job struct {
+	Schedule sched.Schedule
+	Days     int
+	Channels []Channel
+	Rules    Rules
+	// URL is the address webcasa is reached at, e.g.
+	// "https://casa.example.com", for links to mark done, snooze or open
+	// what a reminder is about. Without it, reminders carry no links.
+	URL string
+}
+
+// SchedJob adapts the reminders to the background scheduler.
+func (j Job) SchedJob(store *data.Store) sched.Job {
+	return sched.Job{
+		Name:     JobName,
+		Schedule: j.Schedule,
+		Run: func(ctx context.Context, _, now time.Time) error {
+			return j.send(ctx, store, now, &j.Rules)
+		},
+	}
+}
@@ -0,0 +1,437 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package remind
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/cpcloud/webcasa/internal/exports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStore(t *testing.T) *data.Store {
+	t.Helper()
+	store, err := data.Open(filepath.Join(t.TempDir(), "remind.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	require.NoError(t, store.AutoMigrate())
+	require.NoError(t, store.SeedDefaults())
+	return store
+}
+
+func TestCollect(t *testing.T) {
+	store := newStore(t)
+	now := time.Now()
+	renewal := now.AddDate(0, 0, 10)
+	require.NoError(t, store.CreateHouseProfile(data.HouseProfile{
+		Nickname: "Elm Street", InsuranceCarrier: "Acme Mutual", InsuranceRenewal: &renewal,
+	}))
+	categories, err := store.MaintenanceCategories()
+	require.NoError(t, err)
+	serviced := now.AddDate(0, -3, -2)
+	require.NoError(t, store.CreateMaintenance(&data.MaintenanceItem{
+		Name: "Replace furnace filter", CategoryID: categories[0].ID,
+		LastServicedAt: &serviced, IntervalMonths: 3,
+	}))
+	expiry := now.AddDate(0, 0, 5)
+	require.NoError(t, store.CreateAppliance(&data.Appliance{Name: "Dishwasher", WarrantyExpiry: &expiry}))
+	later := now.AddDate(1, 0, 0)
+	require.NoError(t, store.CreateAppliance(&data.Appliance{Name: "Fridge", WarrantyExpiry: &later}))
+	plan := data.Warranty{Provider: "Home Shield", ExpiresOn: now.AddDate(0, 0, 12)}
+	require.NoError(t, store.CreateWarranty(&plan))
+	applyBy := now.AddDate(0, 0, 13)
+	rebate := data.Rebate{Program: "Heat pump water heater", AmountCents: 15000, ApplyBy: &applyBy}
+	require.NoError(t, store.CreateRebate(&rebate, now))
+
+	reminders, err := Collect(store, now, 14)
+	require.NoError(t, err)
+	require.Len(t, reminders, 5)
+	assert.Equal(t, KindMaintenance, reminders[0].Kind)
+	assert.True(t, reminders[0].Overdue(now))
+	assert.Equal(t, "Dishwasher warranty ends", reminders[1].Title)
+	assert.Equal(t, "Homeowner's insurance renews (Acme Mutual)", reminders[2].Title)
+	assert.Equal(t, KindPolicy, reminders[3].Kind)
+	assert.Equal(t, plan.ID, reminders[3].ID)
+	assert.Equal(t, "Home Shield warranty ends", reminders[3].Title)
+	assert.Equal(t, "warranties/"+strconv.FormatUint(uint64(plan.ID), 10), reminders[3].open)
+	assert.Equal(t, KindRebate, reminders[4].Kind)
+	assert.Equal(t, "Apply for the Heat pump water heater rebate", reminders[4].Title)
+	assert.Equal(t, int64(15000), *reminders[4].AmountCents)
+	assert.Equal(t, "webcasa: 5 coming up, 1 overdue", Subject(reminders, now))
+
+	reminders, err = Collect(store, now, 7)
+	require.NoError(t, err)
+	assert.Len(t, reminders, 2, "the renewal, the plan and the rebate are further out")
+}
+
+func TestSendToChannels(t *testing.T) {
+	store := newStore(t)
+	var out bytes.Buffer
+	stdout, err := ParseChannel("stdout", exports.SMTPSettings{}, &out)
+	require.NoError(t, err)
+	require.NoError(t, Send(context.Background(), store, []Channel{stdout}, time.Now(), 14))
+	assert.Empty(t, out.String(), "nothing is sent when nothing is due")
+
+	expiry := time.Now().AddDate(0, 0, 3)
+	require.NoError(t, store.CreateAppliance(&data.Appliance{Name: "Dishwasher", WarrantyExpiry: &expiry}))
+
+	var got []*http.Request
+	var bodies [][]byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got, bodies = append(got, r), append(bodies, body)
+	}))
+	defer srv.Close()
+	ntfy, err := ParseChannel("ntfy:"+srv.URL+"/elm-street", exports.SMTPSettings{}, nil)
+	require.NoError(t, err)
+	hook, err := ParseChannel(srv.URL+"/hook", exports.SMTPSettings{}, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, Send(context.Background(), store, []Channel{stdout, ntfy, hook}, time.Now(), 14))
+	assert.Contains(t, out.String(), "Dishwasher warranty ends")
+	require.Len(t, got, 2)
+	assert.Equal(t, "webcasa: 1 coming up", got[0].Header.Get("Title"))
+	assert.Contains(t, string(bodies[0]), "Dishwasher warranty ends")
+	var notice Notice
+	require.NoError(t, json.Unmarshal(bodies[1], &notice))
+	require.Len(t, notice.Reminders, 1)
+	assert.Equal(t, KindWarranty, notice.Reminders[0].Kind)
+}
+
+func TestChatChannelsAndFilters(t *testing.T) {
+	store := newStore(t)
+	now := time.Now()
+	expiry := now.AddDate(0, 0, 3)
+	require.NoError(t, store.CreateAppliance(&data.Appliance{Name: "Dishwasher", WarrantyExpiry: &expiry}))
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	project := data.Project{Title: "Deck", ProjectTypeID: types[0].ID, Status: data.ProjectStatusInProgress}
+	require.NoError(t, store.CreateProject(&project))
+	for _, co := range []data.ChangeOrder{
+		{ProjectID: project.ID, AmountCents: 250_000, Reason: "Composite boards"},
+		{ProjectID: project.ID, AmountCents: 4_000, Reason: "Extra screws"},
+	} {
+		require.NoError(t, store.CreateChangeOrder(&co))
+	}
+
+	reminders, err := Collect(store, now, 14)
+	require.NoError(t, err)
+	require.Len(t, reminders, 3)
+	assert.Equal(t, KindApproval, reminders[0].Kind)
+	assert.Equal(t, "Approve $2,500 change order on Deck: Composite boards", reminders[0].Title)
+	assert.False(t, reminders[0].Overdue(now.AddDate(0, 0, 1)), "approvals aren't overdue")
+
+	bodies := map[string][]byte{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bodies[r.URL.Path], _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+	slack, err := ParseChannel("slack:"+srv.URL+"/services/T0/B0/secret", exports.SMTPSettings{}, nil)
+	require.NoError(t, err)
+	assert.NotContains(t, slack.String(), "secret")
+	discord, err := ParseChannel("discord:"+srv.URL+"/api/webhooks/1/secret", exports.SMTPSettings{}, nil)
+	require.NoError(t, err)
+	var overdue bytes.Buffer
+	stdout, err := ParseChannel("stdout", exports.SMTPSettings{}, &overdue)
+	require.NoError(t, err)
+
+	channels := []Channel{
+		&Filtered{Channel: slack, Filter: Filter{Kinds: []string{KindApproval}, MinAmountCents: 100_000}},
+		discord,
+		&Filtered{Channel: stdout, Filter: Filter{OverdueOnly: true}},
+	}
+	require.NoError(t, Send(context.Background(), store, channels, now, 14))
+
+	var sl struct{ Text string }
+	require.NoError(t, json.Unmarshal(bodies["/services/T0/B0/secret"], &sl))
+	assert.Contains(t, sl.Text, "*webcasa: 1 coming up*")
+	assert.Contains(t, sl.Text, "Composite boards")
+	assert.NotContains(t, sl.Text, "Extra screws", "under the minimum")
+	assert.NotContains(t, sl.Text, "Dishwasher")
+
+	var dc struct{ Content string }
+	require.NoError(t, json.Unmarshal(bodies["/api/webhooks/1/secret"], &dc))
+	assert.Contains(t, dc.Content, "**webcasa: 3 coming up**")
+	assert.Contains(t, dc.Content, "Dishwasher warranty ends")
+
+	assert.Empty(t, overdue.String(), "nothing is overdue")
+}
+
+func TestParseChannelRejects(t *testing.T) {
+	for _, to := range []string{
+		"pager", "mailto:nobody", "ntfy:https://ntfy.sh/", "https://", "slack:hooks", "discord:ftp://x",
+	} {
+		_, err := ParseChannel(to, exports.SMTPSettings{Host: "smtp.example.com"}, nil)
+		assert.Error(t, err, to)
+	}
+	_, err := ParseChannel("mailto:me@example.com", exports.SMTPSettings{}, nil)
+	assert.ErrorContains(t, err, "[smtp] host")
+}
+
+func TestDigest(t *testing.T) {
+	store := newStore(t)
+	now := time.Date(2026, 6, 3, 9, 0, 0, 0, time.Local) // a Wednesday
+	categories, err := store.MaintenanceCategories()
+	require.NoError(t, err)
+	serviced := now.AddDate(0, -3, -2)
+	require.NoError(t, store.CreateMaintenance(&data.MaintenanceItem{
+		Name: "Replace furnace filter", CategoryID: categories[0].ID,
+		LastServicedAt: &serviced, IntervalMonths: 3,
+	}))
+	expiry := now.AddDate(0, 0, 5)
+	require.NoError(t, store.CreateAppliance(&data.Appliance{Name: "Dishwasher", WarrantyExpiry: &expiry}))
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	budget, actual := int64(1_000_000), int64(1_120_000)
+	require.NoError(t, store.CreateProject(&data.Project{
+		Title: "Deck", ProjectTypeID: types[0].ID, Status: data.ProjectStatusInProgress,
+		BudgetCents: &budget, ActualCents: &actual,
+	}))
+	require.NoError(t, store.CreateProject(&data.Project{
+		Title: "Shed", ProjectTypeID: types[0].ID, Status: data.ProjectStatusInProgress, BudgetCents: &budget,
+	}))
+
+	reminders, err := Collect(store, now, 14)
+	require.NoError(t, err)
+	require.Len(t, reminders, 3)
+	budgetAlert := reminders[1]
+	assert.Equal(t, KindBudget, budgetAlert.Kind)
+	assert.Equal(t, "Deck is $1,200 over its $10,000 budget", budgetAlert.Title)
+	assert.False(t, budgetAlert.Overdue(now.AddDate(0, 0, 1)))
+
+	var out bytes.Buffer
+	stdout, err := ParseChannel("stdout", exports.SMTPSettings{}, &out)
+	require.NoError(t, err)
+	job := Job{Days: 14, Channels: []Channel{&Filtered{Channel: stdout, Digest: DigestWeekly, ID: "stdout"}}}
+	run := job.SchedJob(store).Run
+	require.NoError(t, run(context.Background(), time.Time{}, now))
+	text := out.String()
+	assert.Contains(t, text, "webcasa weekly digest: 3 coming up, 1 overdue")
+	assert.Contains(t, text, "Overdue (1)\n  ")
+	assert.Contains(t, text, "Replace furnace filter")
+	assert.Contains(t, text, "Expiring (1)\n  ")
+	assert.Contains(t, text, "Over budget (1)\n  ")
+	assert.NotContains(t, text, "Coming up", "empty headings are left out")
+	assert.Less(t, strings.Index(text, "Overdue"), strings.Index(text, "Over budget"))
+
+	// The digest goes out once a week however often the job runs.
+	out.Reset()
+	require.NoError(t, run(context.Background(), time.Time{}, now.AddDate(0, 0, 4)))
+	assert.Empty(t, out.String(), "same week")
+	require.NoError(t, run(context.Background(), time.Time{}, now.AddDate(0, 0, 5)))
+	assert.Contains(t, out.String(), "weekly digest", "next Monday")
+
+	// On demand, it's sent regardless.
+	out.Reset()
+	require.NoError(t, Send(context.Background(), store, job.Channels, now.AddDate(0, 0, 5), 14))
+	assert.Contains(t, out.String(), "weekly digest")
+}
+
+func TestQuietHours(t *testing.T) {
+	q, err := ParseQuietHours("22:00-07:00")
+	require.NoError(t, err)
+	assert.Equal(t, "22:00-07:00", q.String())
+	day := time.Date(2026, 6, 3, 0, 0, 0, 0, time.Local)
+	assert.True(t, q.Contains(day.Add(23*time.Hour)))
+	assert.True(t, q.Contains(day.Add(6*time.Hour+59*time.Minute)))
+	assert.False(t, q.Contains(day.Add(7*time.Hour)))
+	q, err = ParseQuietHours("12:30-13:30")
+	require.NoError(t, err)
+	assert.True(t, q.Contains(day.Add(13*time.Hour)))
+	assert.False(t, q.Contains(day.Add(23*time.Hour)))
+	assert.False(t, QuietHours{}.Contains(day))
+	for _, bad := range []string{"22:00", "25:00-07:00", "07:00-07:00"} {
+		_, err := ParseQuietHours(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestRules(t *testing.T) {
+	store := newStore(t)
+	morning := time.Date(2026, 6, 3, 8, 0, 0, 0, time.Local)
+	categories, err := store.MaintenanceCategories()
+	require.NoError(t, err)
+	serviced := morning.AddDate(0, -3, -2)
+	require.NoError(t, store.CreateMaintenance(&data.MaintenanceItem{
+		Name: "Replace furnace filter", CategoryID: categories[0].ID,
+		LastServicedAt: &serviced, IntervalMonths: 3,
+	}))
+	serviced = morning.AddDate(0, -6, 1)
+	require.NoError(t, store.CreateMaintenance(&data.MaintenanceItem{
+		Name: "Clean gutters", CategoryID: categories[0].ID,
+		LastServicedAt: &serviced, IntervalMonths: 6,
+	}))
+
+	var usual, urgent, night bytes.Buffer
+	stdout := func(w io.Writer) Channel {
+		ch, err := ParseChannel("stdout", exports.SMTPSettings{}, w)
+		require.NoError(t, err)
+		return ch
+	}
+	quiet, err := ParseQuietHours("22:00-07:00")
+	require.NoError(t, err)
+	job := Job{
+		Days: 14,
+		Channels: []Channel{
+			stdout(&usual),
+			&Filtered{Channel: stdout(&night), ID: "night", QuietHours: QuietHours{Start: time.Hour, End: 2 * time.Hour}},
+		},
+		Rules: Rules{QuietHours: quiet, RenotifyDays: 2, EscalateAfter: 2, EscalateTo: []Channel{stdout(&urgent)}},
+	}
+	run := func(at time.Time) {
+		t.Helper()
+		usual.Reset()
+		urgent.Reset()
+		night.Reset()
+		require.NoError(t, job.SchedJob(store).Run(context.Background(), time.Time{}, at))
+	}
+
+	run(morning.Add(-10 * time.Hour))
+	assert.Empty(t, usual.String(), "quiet hours")
+	assert.Contains(t, night.String(), "Replace furnace filter", "its own quiet hours")
+
+	run(morning)
+	assert.Contains(t, usual.String(), "Replace furnace filter")
+	assert.Contains(t, usual.String(), "Clean gutters")
+	run(morning.Add(time.Hour))
+	assert.Empty(t, usual.String(), "sent within renotify_days")
+
+	// Going overdue is news; staying overdue waits out renotify_days.
+	run(morning.AddDate(0, 0, 1).Add(time.Hour))
+	assert.Contains(t, usual.String(), "Clean gutters")
+	assert.NotContains(t, usual.String(), "Replace furnace filter")
+	run(morning.AddDate(0, 0, 2))
+	assert.Contains(t, usual.String(), "Replace furnace filter")
+	assert.Empty(t, urgent.String())
+
+	// Sent twice and still overdue, the filter moves to escalate_to.
+	run(morning.AddDate(0, 0, 4))
+	assert.NotContains(t, usual.String(), "Replace furnace filter")
+	assert.Contains(t, usual.String(), "Clean gutters")
+	assert.Contains(t, urgent.String(), "webcasa: 1 still overdue")
+	assert.Contains(t, urgent.String(), "Replace furnace filter")
+	run(morning.AddDate(0, 0, 5))
+	assert.Empty(t, urgent.String(), "escalations wait out renotify_days too")
+
+	// On demand, the rules don't apply.
+	usual.Reset()
+	require.NoError(t, Send(context.Background(), store, job.Channels[:1], morning.AddDate(0, 0, 2), 14))
+	assert.Contains(t, usual.String(), "Replace furnace filter")
+}
+
+func TestLinks(t *testing.T) {
+	store := newStore(t)
+	now := time.Now()
+	categories, err := store.MaintenanceCategories()
+	require.NoError(t, err)
+	serviced := now.AddDate(0, -3, -2)
+	filter := data.MaintenanceItem{
+		Name: "Replace furnace filter", CategoryID: categories[0].ID,
+		LastServicedAt: &serviced, IntervalMonths: 3,
+	}
+	require.NoError(t, store.CreateMaintenance(&filter))
+	expiry := now.AddDate(0, 0, 10)
+	dishwasher := data.Appliance{Name: "Dishwasher", WarrantyExpiry: &expiry}
+	require.NoError(t, store.CreateAppliance(&dishwasher))
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	project := data.Project{Title: "Deck", ProjectTypeID: types[0].ID, Status: data.ProjectStatusInProgress}
+	require.NoError(t, store.CreateProject(&project))
+	co := data.ChangeOrder{ProjectID: project.ID, AmountCents: 250_000, Reason: "Composite boards"}
+	require.NoError(t, store.CreateChangeOrder(&co))
+
+	// Links are signed for one action on one record, until they expire.
+	key, err := LinkKey(store)
+	require.NoError(t, err)
+	again, err := LinkKey(store)
+	require.NoError(t, err)
+	assert.Equal(t, key, again, "the key is kept")
+	query := SignLink(key, ActionDone, KindMaintenance, filter.ID, now.Add(LinkTTL))
+	require.NoError(t, VerifyLink(key, ActionDone, KindMaintenance, filter.ID, query, now))
+	assert.ErrorIs(t, VerifyLink(key, ActionSnooze, KindMaintenance, filter.ID, query, now), ErrBadLink)
+	assert.ErrorIs(t, VerifyLink(key, ActionDone, KindMaintenance, filter.ID+1, query, now), ErrBadLink)
+	assert.ErrorIs(t, VerifyLink(key, ActionDone, KindMaintenance, filter.ID, query, now.Add(LinkTTL+time.Second)), ErrBadLink)
+	tampered := SignLink(key, ActionDone, KindMaintenance, filter.ID, now.Add(LinkTTL))
+	tampered.Set("exp", strconv.FormatInt(now.Add(2*LinkTTL).Unix(), 10))
+	assert.ErrorIs(t, VerifyLink(key, ActionDone, KindMaintenance, filter.ID, tampered, now), ErrBadLink)
+
+	// Every channel gets the links, in its own markup.
+	var out bytes.Buffer
+	stdout, err := ParseChannel("stdout", exports.SMTPSettings{}, &out)
+	require.NoError(t, err)
+	bodies := map[string][]byte{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bodies[r.URL.Path], _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+	slack, err := ParseChannel("slack:"+srv.URL+"/slack", exports.SMTPSettings{}, nil)
+	require.NoError(t, err)
+	discord, err := ParseChannel("discord:"+srv.URL+"/discord", exports.SMTPSettings{}, nil)
+	require.NoError(t, err)
+	job := Job{Days: 14, Channels: []Channel{stdout, slack, discord}, URL: "https://casa.example.com"}
+	require.NoError(t, job.Send(context.Background(), store, now))
+
+	done := "https://casa.example.com" + LinkPath(ActionDone, KindMaintenance, filter.ID) + "?exp="
+	assert.Contains(t, out.String(), "Mark done: "+done)
+	assert.Contains(t, out.String(), "Open in webcasa: https://casa.example.com/#maintenance/"+strconv.Itoa(int(filter.ID)))
+	assert.Contains(t, out.String(), "Open in webcasa: https://casa.example.com/#projects/"+strconv.Itoa(int(project.ID)))
+	var sl struct{ Text string }
+	require.NoError(t, json.Unmarshal(bodies["/slack"], &sl))
+	assert.Contains(t, sl.Text, "<"+done)
+	assert.Contains(t, sl.Text, "|Snooze a week>")
+	var dc struct{ Content string }
+	require.NoError(t, json.Unmarshal(bodies["/discord"], &dc))
+	assert.Contains(t, dc.Content, "[Mark done](<"+done)
+
+	reminders, err := Collect(store, now, 14)
+	require.NoError(t, err)
+	require.NoError(t, addLinks(store, reminders, "https://casa.example.com", now))
+	for _, r := range reminders {
+		require.NotNil(t, r.Links)
+		assert.NotEmpty(t, r.Links.Snooze)
+		assert.Equal(t, r.Kind != KindWarranty, r.Links.Done != "", "a warranty can only be snoozed")
+	}
+	out.Reset()
+	require.NoError(t, Send(context.Background(), store, []Channel{stdout}, now, 14))
+	assert.NotContains(t, out.String(), "Mark done", "no links without the address")
+
+	// Following the links: the filter is serviced, the change order
+	// approved and the warranty snoozed for a week.
+	msg, err := Act(store, ActionDone, KindMaintenance, filter.ID, now)
+	require.NoError(t, err)
+	assert.Equal(t, "Logged Replace furnace filter as done today.", msg)
+	_, err = Act(store, ActionDone, KindWarranty, dishwasher.ID, now)
+	require.ErrorIs(t, err, ErrBadLink)
+	_, err = Act(store, ActionDone, KindApproval, co.ID, now)
+	require.NoError(t, err)
+	approved, err := store.GetChangeOrder(co.ID)
+	require.NoError(t, err)
+	require.NotNil(t, approved.ApprovedOn)
+	_, err = Act(store, ActionSnooze, KindWarranty, dishwasher.ID, now)
+	require.NoError(t, err)
+
+	reminders, err = Collect(store, now, 14)
+	require.NoError(t, err)
+	assert.Empty(t, reminders)
+	log, err := store.ListServiceLog(filter.ID, false)
+	require.NoError(t, err)
+	require.Len(t, log, 1)
+	assert.Equal(t, "Marked done from a reminder", log[0].Notes)
+	reminders, err = Collect(store, now.Add(SnoozeFor+time.Hour), 14)
+	require.NoError(t, err)
+	require.Len(t, reminders, 1, "the snooze is over")
+	assert.Equal(t, KindWarranty, reminders[0].Kind)
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package remind
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// QuietHours is a stretch of the day, in local time, when scheduled
+// reminders hold off. It may run past midnight. The zero QuietHours is
+// never quiet.
+type QuietHours struct {
+	// Start and End are times of day as durations since midnight.
+	Start, End time.Duration
+}
+
+// ParseQuietHours reads quiet hours written "22:00-07:00". "" is none.
+func ParseQuietHours(s string) (QuietHours, error) {
+	if s == "" {
+		return QuietHours{}, nil
+	}
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return QuietHours{}, fmt.Errorf("invalid quiet hours %q -- expected HH:MM-HH:MM, e.g. 22:00-07:00", s)
+	}
+	var q QuietHours
+	for _, part := range []struct {
+		text string
+		into *time.Duration
+	}{{from, &q.Start}, {to, &q.End}} {
+		t, err := time.Parse("15:04", strings.TrimSpace(part.text))
+		if err != nil {
+			return QuietHours{}, fmt.Errorf("invalid quiet hours %q -- expected HH:MM-HH:MM, e.g. 22:00-07:00", s)
+		}
+		*part.into = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	if q.Start == q.End {
+		return QuietHours{}, fmt.Errorf("quiet hours %q start and end at the same time", s)
+	}
+	return q, nil
+}
+
+// Contains reports whether t falls in the quiet hours.
+func (q QuietHours) Contains(t time.Time) bool {
+	if q.Start == q.End {
+		return false
+	}
+	t = t.Local()
+	at := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if q.Start < q.End {
+		return at >= q.Start && at < q.End
+	}
+	return at >= q.Start || at < q.End
+}
+
+func (q QuietHours) String() string {
+	if q.Start == q.End {
+		return ""
+	}
+	clock := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return clock(q.Start) + "-" + clock(q.End)
+}
+
+// Rules say how the scheduled reminders nag. The zero Rules sends
+// everything on every run.
+type Rules struct {
+	// QuietHours holds off every channel without quiet hours of its own.
+	// What would have been sent goes out on the first run after.
+	QuietHours QuietHours
+	// RenotifyDays holds back a reminder already sent until this many
+	// days have passed, unless it has gone overdue since. 0 sends it on
+	// every run.
+	RenotifyDays int
+	// EscalateAfter sends an overdue reminder to EscalateTo instead of a
+	// channel once that channel has been sent it this many times since it
+	// went overdue. 0 never escalates.
+	EscalateAfter int
+	EscalateTo    []Channel
+}
+
+// forgetAfter is how long the reminder states of things no longer coming
+// up are kept.
+const forgetAfter = 365 * 24 * time.Hour
+
+type stateKey struct {
+	kind string
+	id   uint
+}
+
+func keyOf(r Reminder) stateKey { return stateKey{r.Kind, r.ID} }
+
+// plan splits the reminders by the rules into those for a channel and
+// those to escalate, leaving out ones held back, given what was sent to
+// the channel before.
+func (rules Rules) plan(reminders []Reminder, states map[stateKey]data.ReminderState, now time.Time) (usual, escalate []Reminder) {
+	for _, r := range reminders {
+		st, sent := states[keyOf(r)]
+		if sent && !st.Due.Equal(r.Due) {
+			sent = false
+		}
+		overdue := r.Overdue(now)
+		if sent && rules.RenotifyDays > 0 && (st.Overdue || !overdue) &&
+			now.Before(st.LastSentAt.AddDate(0, 0, rules.RenotifyDays)) {
+			continue
+		}
+		if sent && overdue && rules.EscalateAfter > 0 && len(rules.EscalateTo) > 0 &&
+			st.Sent >= rules.EscalateAfter {
+			escalate = append(escalate, r)
+			continue
+		}
+		usual = append(usual, r)
+	}
+	return usual, escalate
+}
+
+// quietHours returns the quiet hours that apply to c.
+func (rules Rules) quietHours(c Channel) QuietHours {
+	if f, ok := c.(*Filtered); ok && f.QuietHours != (QuietHours{}) {
+		return f.QuietHours
+	}
+	return rules.QuietHours
+}
@@ -0,0 +1,186 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package remind
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// WeekDays is how far ahead the week's list looks.
+const WeekDays = 7
+
+// Kinds only on the week's list. KindProjectTask is a task on a project's
+// checklist and KindChecklist one on a house event's. KindProjectStart and
+// KindProjectEnd are the days a project is set to start and wrap up, and
+// KindPest a pest treatment due again; with maintenance, they're the
+// appointments.
+const (
+	KindProjectTask  = "project_task"
+	KindChecklist    = "checklist"
+	KindProjectStart = "project_start"
+	KindProjectEnd   = "project_end"
+	KindPest         = "pest"
+)
+
+// ErrCantReschedule means a thing on the week's list has a date that
+// follows from something else, like a warranty's end or the last time the
+// furnace was serviced, so it can only be done or snoozed.
+var ErrCantReschedule = errors.New("this can't be rescheduled -- mark it done or snooze it")
+
+// WeekItem is one thing on the week's list: a reminder, with a line of
+// context, where the web app shows it, and which quick actions it has.
+type WeekItem struct {
+	Reminder
+	Detail        string `json:"detail,omitempty"`
+	Open          string `json:"open,omitempty"`
+	Overdue       bool   `json:"overdue"`
+	CanComplete   bool   `json:"can_complete"`
+	CanReschedule bool   `json:"can_reschedule"`
+}
+
+// CanReschedule reports whether a thing of the kind has a date of its own
+// that can be moved: a task's due date or a project's start or wrap-up.
+func CanReschedule(kind string) bool {
+	switch kind {
+	case KindProjectTask, KindChecklist, KindProjectStart, KindProjectEnd:
+		return true
+	}
+	return false
+}
+
+// Week returns everything needing attention in the WeekDays from now, in
+// one list ordered by date: the reminders, project and house event tasks
+// with due dates, overdue ones included, and the appointments -- projects
+// starting or wrapping up and pest treatments coming due. Snoozed things
+// are left out.
+func Week(store *data.Store, now time.Time) ([]WeekItem, error) {
+	horizon := time.Duration(WeekDays) * 24 * time.Hour
+	reminders, err := Collect(store, now, WeekDays)
+	if err != nil {
+		return nil, err
+	}
+	details := map[stateKey]string{}
+	add := func(r Reminder, detail string) {
+		reminders = append(reminders, r)
+		details[keyOf(r)] = detail
+	}
+	page := func(name string, id uint) string { return name + "/" + strconv.FormatUint(uint64(id), 10) }
+
+	tasks, err := store.ListProjectTasksDue(now, horizon)
+	if err != nil {
+		return nil, fmt.Errorf("list project tasks due: %w", err)
+	}
+	for _, t := range tasks {
+		detail := t.Project.Title
+		if t.Assignee != "" {
+			detail += " · " + t.Assignee
+		}
+		add(Reminder{Kind: KindProjectTask, ID: t.ID, Title: t.Title, Due: *t.DueDate, open: page("projects", t.ProjectID)}, detail)
+	}
+
+	checklist, err := store.ListHouseEventTasksDue(now, horizon)
+	if err != nil {
+		return nil, fmt.Errorf("list checklist tasks due: %w", err)
+	}
+	for _, t := range checklist {
+		add(Reminder{Kind: KindChecklist, ID: t.ID, Title: t.Title, Due: *t.DueAt, open: "house"}, "house checklist")
+	}
+
+	// Appointments are what's coming, from the start of today; one that
+	// has passed isn't overdue, it happened or it didn't.
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	ahead := func(t *time.Time) bool { return t != nil && !t.Before(today) && !t.After(now.Add(horizon)) }
+	projects, err := store.ListProjects(false)
+	if err != nil {
+		return nil, fmt.Errorf("list projects: %w", err)
+	}
+	for _, p := range projects {
+		if p.Status == data.ProjectStatusCompleted || p.Status == data.ProjectStatusAbandoned {
+			continue
+		}
+		if ahead(p.StartDate) {
+			add(Reminder{Kind: KindProjectStart, ID: p.ID, Title: p.Title, Due: *p.StartDate, open: page("projects", p.ID)}, "project starts")
+		}
+		if ahead(p.EndDate) {
+			add(Reminder{Kind: KindProjectEnd, ID: p.ID, Title: p.Title, Due: *p.EndDate, open: page("projects", p.ID)}, "project wraps up")
+		}
+	}
+
+	pests, err := store.ListPestRetreatmentsDue(now, horizon)
+	if err != nil {
+		return nil, fmt.Errorf("list pest treatments due: %w", err)
+	}
+	for _, p := range pests {
+		detail := "pest treatment"
+		if p.Vendor.Name != "" {
+			detail += " · " + p.Vendor.Name
+		}
+		add(Reminder{Kind: KindPest, ID: p.ID, Title: "Treat for " + p.TargetPest, Due: *p.NextTreatmentDue(), open: page("pests", p.ID)}, detail)
+	}
+
+	reminders, err = dropSnoozed(store, reminders, now)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(reminders, func(i, j int) bool { return reminders[i].Due.Before(reminders[j].Due) })
+	out := make([]WeekItem, len(reminders))
+	for i, r := range reminders {
+		out[i] = WeekItem{
+			Reminder:      r,
+			Detail:        details[keyOf(r)],
+			Open:          r.open,
+			Overdue:       r.Overdue(now),
+			CanComplete:   CanComplete(r.Kind),
+			CanReschedule: CanReschedule(r.Kind),
+		}
+	}
+	return out, nil
+}
+
+// Reschedule moves the thing of the kind about record id to to and says
+// what was done. It returns ErrCantReschedule for a kind whose date can't
+// be moved.
+func Reschedule(store *data.Store, kind string, id uint, to, now time.Time) (string, error) {
+	on := " to " + to.Format("Mon Jan 2") + "."
+	switch kind {
+	case KindProjectTask:
+		task, err := store.GetProjectTask(id)
+		if err != nil {
+			return "", err
+		}
+		task.DueDate = &to
+		if err := store.UpdateProjectTask(task, now); err != nil {
+			return "", err
+		}
+		return "Moved " + task.Title + on, nil
+	case KindChecklist:
+		task, err := store.SetHouseEventTaskDue(id, to)
+		if err != nil {
+			return "", err
+		}
+		return "Moved " + task.Title + on, nil
+	case KindProjectStart, KindProjectEnd:
+		p, err := store.GetProject(id)
+		if err != nil {
+			return "", err
+		}
+		what := "start"
+		if kind == KindProjectStart {
+			p.StartDate = &to
+		} else {
+			p.EndDate, what = &to, "wrap-up"
+		}
+		if err := store.UpdateProject(p); err != nil {
+			return "", err
+		}
+		return "Moved " + p.Title + "'s " + what + on, nil
+	}
+	return "", ErrCantReschedule
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package remind
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeek(t *testing.T) {
+	store := newStore(t)
+	now := time.Now()
+	day := func(n int) *time.Time { d := now.AddDate(0, 0, n); return &d }
+	require.NoError(t, store.CreateHouseProfile(data.HouseProfile{Nickname: "Elm Street"}))
+	categories, err := store.MaintenanceCategories()
+	require.NoError(t, err)
+	require.NoError(t, store.CreateMaintenance(&data.MaintenanceItem{
+		Name: "Replace furnace filter", CategoryID: categories[0].ID,
+		LastServicedAt: day(-94), IntervalMonths: 3,
+	}))
+	types, err := store.ProjectTypes()
+	require.NoError(t, err)
+	deck := data.Project{
+		Title: "Deck", ProjectTypeID: types[0].ID, Status: data.ProjectStatusInProgress,
+		StartDate: day(2), EndDate: day(30),
+	}
+	require.NoError(t, store.CreateProject(&deck))
+	require.NoError(t, store.CreateProject(&data.Project{
+		Title: "Fence", ProjectTypeID: types[0].ID, Status: data.ProjectStatusCompleted, StartDate: day(3),
+	}))
+	footings := data.ProjectTask{ProjectID: deck.ID, Title: "Set footings", DueDate: day(1), Assignee: "Sam"}
+	require.NoError(t, store.CreateProjectTask(&footings, now))
+	require.NoError(t, store.CreateProjectTask(&data.ProjectTask{ProjectID: deck.ID, Title: "Stain", DueDate: day(20)}, now))
+	require.NoError(t, store.CreateProjectTask(&data.ProjectTask{
+		ProjectID: deck.ID, Title: "Pull permit", DueDate: day(-1), Status: data.TaskStatusDone,
+	}, now))
+	event := data.HouseEvent{Kind: data.HouseEventSold, OccurredOn: *day(365)}
+	require.NoError(t, store.CreateHouseEvent(&event))
+	gutters := data.HouseEventTask{HouseEventID: event.ID, Title: "Clean the gutters", DueAt: day(4)}
+	require.NoError(t, store.AddHouseEventTask(&gutters))
+	ants := data.PestTreatment{TargetPest: "ants", TreatedAt: now.AddDate(0, -1, 5), RetreatIntervalMonths: 1}
+	require.NoError(t, store.CreatePestTreatment(&ants))
+
+	week, err := Week(store, now)
+	require.NoError(t, err)
+	kinds := func(items []WeekItem) []string {
+		var out []string
+		for _, it := range items {
+			out = append(out, it.Kind)
+		}
+		return out
+	}
+	require.Equal(t, []string{KindMaintenance, KindProjectTask, KindProjectStart, KindChecklist, KindPest}, kinds(week))
+	assert.True(t, week[0].Overdue)
+	assert.Equal(t, "Deck · Sam", week[1].Detail)
+	assert.Equal(t, "projects/"+strconv.FormatUint(uint64(deck.ID), 10), week[1].Open)
+	assert.True(t, week[1].CanComplete)
+	assert.True(t, week[1].CanReschedule)
+	assert.Equal(t, "project starts", week[2].Detail)
+	assert.False(t, week[2].CanComplete)
+	assert.Equal(t, "Treat for ants", week[4].Title)
+	assert.False(t, week[4].CanReschedule)
+
+	// Monday triage: tick off the footings, push the gutters out, move the
+	// deck's start and put off the ants.
+	msg, err := Act(store, ActionDone, KindProjectTask, footings.ID, now)
+	require.NoError(t, err)
+	assert.Equal(t, "Ticked off Set footings.", msg)
+	_, err = Reschedule(store, KindChecklist, gutters.ID, *day(10), now)
+	require.NoError(t, err)
+	_, err = Reschedule(store, KindProjectStart, deck.ID, *day(5), now)
+	require.NoError(t, err)
+	_, err = Reschedule(store, KindMaintenance, week[0].ID, *day(5), now)
+	require.ErrorIs(t, err, ErrCantReschedule)
+	_, err = Act(store, ActionSnooze, KindPest, ants.ID, now)
+	require.NoError(t, err)
+
+	week, err = Week(store, now)
+	require.NoError(t, err)
+	require.Equal(t, []string{KindMaintenance, KindProjectStart}, kinds(week))
+	assert.WithinDuration(t, *day(5), week[1].Due, time.Second)
+}
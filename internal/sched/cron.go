@@ -0,0 +1,210 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package sched
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule yields the next run time strictly after a given time.
+type Schedule interface {
+	Next(after time.Time) time.Time
+	String() string
+}
+
+// aliases maps shorthands, with or without the leading @, to cron
+// expressions.
+var aliases = map[string]string{
+	"hourly":    "0 * * * *",
+	"daily":     "0 0 * * *",
+	"midnight":  "0 0 * * *",
+	"weekly":    "0 0 * * 0",
+	"monthly":   "0 0 1 * *",
+	"quarterly": "0 0 1 1,4,7,10 *",
+	"yearly":    "0 0 1 1 *",
+	"annually":  "0 0 1 1 *",
+}
+
+// Parse reads a schedule spec: a five-field cron expression
+// ("minute hour day-of-month month day-of-week"), a shorthand such as
+// "daily", "@weekly", or "quarterly", or a fixed interval written as
+// "@every 6h" or just "6h".
+func Parse(spec string) (Schedule, error) {
+	s := strings.TrimSpace(spec)
+	lower := strings.ToLower(s)
+	if rest, ok := strings.CutPrefix(lower, "@every "); ok {
+		return parseEvery(spec, strings.TrimSpace(rest))
+	}
+	if expr, ok := aliases[strings.TrimPrefix(lower, "@")]; ok {
+		c, err := parseCron(expr)
+		if err != nil {
+			return nil, err
+		}
+		c.spec = s
+		return c, nil
+	}
+	if len(strings.Fields(s)) == 5 {
+		return parseCron(s)
+	}
+	if _, err := time.ParseDuration(s); err == nil {
+		return parseEvery(spec, s)
+	}
+	return nil, fmt.Errorf(
+		"invalid schedule %q -- use a cron expression like \"0 3 * * *\", "+
+			"a shorthand like daily/weekly/monthly/quarterly, or an interval like \"6h\"", spec)
+}
+
+// Every is a fixed-interval schedule.
+type Every time.Duration
+
+func parseEvery(spec, d string) (Schedule, error) {
+	dur, err := time.ParseDuration(d)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", spec, err)
+	}
+	if dur < time.Minute {
+		return nil, fmt.Errorf("invalid schedule %q: interval must be at least 1m", spec)
+	}
+	return Every(dur), nil
+}
+
+// Next returns after plus the interval.
+func (e Every) Next(after time.Time) time.Time { return after.Add(time.Duration(e)) }
+
+func (e Every) String() string { return "@every " + time.Duration(e).String() }
+
+// Cron is a parsed five-field cron expression, evaluated in the location of
+// the time passed to Next.
+type Cron struct {
+	spec                   string
+	minute, hour, dom, dow uint64
+	month                  uint64
+	anyDOM, anyDOW         bool
+}
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var cronFields = [5]field{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+func parseCron(spec string) (*Cron, error) {
+	parts := strings.Fields(spec)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: want 5 fields, got %d", spec, len(parts))
+	}
+	var bits [5]uint64
+	for i, p := range parts {
+		b, err := parseField(p, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", spec, err)
+		}
+		bits[i] = b
+	}
+	// Sunday is both 0 and 7.
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+	return &Cron{
+		spec:   spec,
+		minute: bits[0], hour: bits[1], dom: bits[2], month: bits[3], dow: bits[4],
+		anyDOM: strings.HasPrefix(parts[2], "*"), anyDOW: strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+// parseField turns one comma-separated cron field into a bit set.
+func parseField(s string, f field) (uint64, error) {
+	var bits uint64
+	for _, term := range strings.Split(s, ",") {
+		rng, stepStr, hasStep := strings.Cut(term, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%s: bad step %q", f.name, stepStr)
+			}
+			step = n
+		}
+		lo, hi := f.min, f.max
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(a); err != nil {
+				return 0, fmt.Errorf("%s: bad value %q", f.name, a)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(b); err != nil {
+					return 0, fmt.Errorf("%s: bad value %q", f.name, b)
+				}
+			} else if hasStep {
+				hi = f.max
+			}
+		}
+		if lo < f.min || hi > f.max || lo > hi {
+			return 0, fmt.Errorf("%s: %q out of range %d-%d", f.name, term, f.min, f.max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func (c *Cron) String() string { return c.spec }
+
+// Next returns the first matching minute strictly after after, or the zero
+// time if none falls within the next five years (e.g. "0 0 31 2 *").
+func (c *Cron) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if c.month&(1<<int(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if c.hour&(1<<t.Hour()) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if c.minute&(1<<t.Minute()) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches applies cron's rule that when both day fields are restricted,
+// either may match.
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<t.Day()) != 0
+	dow := c.dow&(1<<int(t.Weekday())) != 0
+	switch {
+	case c.anyDOM && c.anyDOW:
+		return true
+	case c.anyDOM:
+		return dow
+	case c.anyDOW:
+		return dom
+	default:
+		return dom || dow
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package sched
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronNext(t *testing.T) {
+	base := time.Date(2026, 3, 4, 9, 30, 15, 0, time.UTC) // a Wednesday
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2026, 3, 4, 9, 45, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2026, 3, 5, 3, 0, 0, 0, time.UTC)},
+		{"30 9 * * *", time.Date(2026, 3, 5, 9, 30, 0, 0, time.UTC)},
+		{"0 7 * * 1", time.Date(2026, 3, 9, 7, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)},
+		{"0 9-17/4 * * 1-5", time.Date(2026, 3, 4, 13, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either matches.
+		{"0 0 15 * 5", time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)},
+		{"daily", time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"quarterly", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"yearly", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := Parse(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, s.Next(base))
+		})
+	}
+}
+
+func TestCronNextIsStrictlyAfter(t *testing.T) {
+	s, err := Parse("0 3 * * *")
+	require.NoError(t, err)
+	at := time.Date(2026, 3, 4, 3, 0, 0, 0, time.UTC)
+	assert.Equal(t, at.AddDate(0, 0, 1), s.Next(at))
+}
+
+func TestParseEvery(t *testing.T) {
+	base := time.Date(2026, 3, 4, 9, 30, 15, 0, time.UTC)
+	for _, spec := range []string{"@every 6h", "6h"} {
+		s, err := Parse(spec)
+		require.NoError(t, err)
+		assert.Equal(t, base.Add(6*time.Hour), s.Next(base))
+		assert.Equal(t, "@every 6h0m0s", s.String())
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	for _, spec := range []string{
+		"", "fortnightly", "10s", "@every -1h",
+		"* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8",
+		"*/0 * * * *", "5-1 * * * *", "a * * * *",
+	} {
+		_, err := Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+// Package sched runs named background jobs on cron-like schedules. Last-run
+// times are kept in the database, so a restart neither repeats a job that
+// already ran nor forgets one that came due while the server was down.
+package sched
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+)
+
+// DefaultRetryDelay is how long a failed job waits before it is tried again.
+const DefaultRetryDelay = 15 * time.Minute
+
+// RunFunc does a job's work. last is the time of the previous successful
+// run, or zero if there has been none.
+type RunFunc func(ctx context.Context, last, now time.Time) error
+
+// Job is a named unit of background work.
+type Job struct {
+	Name     string
+	Schedule Schedule
+	// Jitter spreads jobs that share a schedule. Each job is delayed by a
+	// fixed fraction of Jitter derived from its name.
+	Jitter time.Duration
+	Run    RunFunc
+}
+
+// Status describes a job for listings.
+type Status struct {
+	Name      string
+	Schedule  string
+	LastRun   *data.JobRun
+	NextRunAt time.Time
+}
+
+// Scheduler owns a set of jobs and runs them when they come due.
+type Scheduler struct {
+	store *data.Store
+	jobs  []Job
+
+	// Log receives one line per run; nil discards.
+	Log io.Writer
+	// Now defaults to time.Now.
+	Now func() time.Time
+	// RetryDelay defaults to DefaultRetryDelay.
+	RetryDelay time.Duration
+
+	// mu serializes job runs.
+	mu sync.Mutex
+
+	startMu sync.Mutex
+	started time.Time
+}
+
+// New returns a Scheduler with no jobs.
+func New(store *data.Store) *Scheduler {
+	return &Scheduler{store: store}
+}
+
+// Add registers a job. Names must be unique.
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" {
+		return fmt.Errorf("job name is required")
+	}
+	if job.Schedule == nil || job.Run == nil {
+		return fmt.Errorf("job %q needs a schedule and a run function", job.Name)
+	}
+	if s.job(job.Name) != nil {
+		return fmt.Errorf("duplicate job %q", job.Name)
+	}
+	s.jobs = append(s.jobs, job)
+	return nil
+}
+
+// Jobs returns the registered jobs in name order.
+func (s *Scheduler) Jobs() []Job {
+	jobs := slices.Clone(s.jobs)
+	slices.SortFunc(jobs, func(a, b Job) int { return strings.Compare(a.Name, b.Name) })
+	return jobs
+}
+
+func (s *Scheduler) job(name string) *Job {
+	for i := range s.jobs {
+		if s.jobs[i].Name == name {
+			return &s.jobs[i]
+		}
+	}
+	return nil
+}
+
+// Status reports the last and next run of every job.
+func (s *Scheduler) Status() ([]Status, error) {
+	now := s.now()
+	jobs := s.Jobs()
+	out := make([]Status, 0, len(jobs))
+	for _, j := range jobs {
+		last, err := s.store.LastJobRun(j.Name)
+		if err != nil {
+			return nil, fmt.Errorf("load last run of %s: %w", j.Name, err)
+		}
+		out = append(out, Status{
+			Name:      j.Name,
+			Schedule:  j.Schedule.String(),
+			LastRun:   last,
+			NextRunAt: s.nextRun(j, last, now),
+		})
+	}
+	return out, nil
+}
+
+// nextRun works out when a job is next due. A job that has never run is
+// first due at its next scheduled time after the scheduler started; one
+// that missed its slot while the server was down is due immediately.
+func (s *Scheduler) nextRun(j Job, last *data.JobRun, now time.Time) time.Time {
+	if last == nil {
+		s.startMu.Lock()
+		base := s.started
+		s.startMu.Unlock()
+		if base.IsZero() {
+			base = now
+		}
+		return s.jittered(j, j.Schedule.Next(base))
+	}
+	if last.LastError != "" {
+		retry := s.RetryDelay
+		if retry <= 0 {
+			retry = DefaultRetryDelay
+		}
+		return last.LastRunAt.Add(retry)
+	}
+	return s.jittered(j, j.Schedule.Next(last.LastRunAt))
+}
+
+// jittered delays t by the job's stable share of its jitter window.
+func (s *Scheduler) jittered(j Job, t time.Time) time.Time {
+	if j.Jitter <= 0 || t.IsZero() {
+		return t
+	}
+	h := fnv.New64a()
+	_, _ = io.WriteString(h, j.Name)
+	return t.Add(time.Duration(h.Sum64() % uint64(j.Jitter)))
+}
+
+// Start runs due jobs once a minute until ctx is done.
+func (s *Scheduler) Start(ctx context.Context) {
+	t := time.NewTicker(time.Minute)
+	defer t.Stop()
+	for {
+		s.RunDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// RunDue runs every job whose next run time has passed. Jobs run one at a
+// time, in name order. The first call marks the start time that jobs which
+// have never run are scheduled from.
+func (s *Scheduler) RunDue(ctx context.Context) {
+	s.startMu.Lock()
+	if s.started.IsZero() {
+		s.started = s.now()
+	}
+	s.startMu.Unlock()
+
+	for _, j := range s.Jobs() {
+		if ctx.Err() != nil {
+			return
+		}
+		last, err := s.store.LastJobRun(j.Name)
+		if err != nil {
+			s.logf("%s: load last run: %v", j.Name, err)
+			continue
+		}
+		now := s.now()
+		next := s.nextRun(j, last, now)
+		if next.IsZero() || now.Before(next) {
+			continue
+		}
+		_ = s.run(ctx, j, last, now)
+	}
+}
+
+// RunNow runs the named job immediately, regardless of its schedule.
+func (s *Scheduler) RunNow(ctx context.Context, name string) error {
+	j := s.job(name)
+	if j == nil {
+		return fmt.Errorf("no job named %q", name)
+	}
+	last, err := s.store.LastJobRun(name)
+	if err != nil {
+		return fmt.Errorf("load last run: %w", err)
+	}
+	return s.run(ctx, *j, last, s.now())
+}
+
+func (s *Scheduler) run(ctx context.Context, j Job, last *data.JobRun, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var since time.Time
+	if last != nil && last.LastSuccessAt != nil {
+		since = *last.LastSuccessAt
+	}
+
+	start := time.Now()
+	runErr := j.Run(ctx, since, now)
+	if err := s.store.RecordJobRun(j.Name, now, time.Since(start), runErr); err != nil {
+		s.logf("%s: record run: %v", j.Name, err)
+	}
+	if runErr != nil {
+		s.logf("%s: %v", j.Name, runErr)
+		return runErr
+	}
+	s.logf("%s: done in %s", j.Name, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+func (s *Scheduler) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+func (s *Scheduler) logf(format string, args ...any) {
+	if s.Log != nil {
+		fmt.Fprintf(s.Log, "webcasa: job "+format+"\n", args...)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package sched
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cpcloud/webcasa/internal/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStore(t *testing.T) *data.Store {
+	t.Helper()
+	store, err := data.Open(filepath.Join(t.TempDir(), "sched.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	require.NoError(t, store.AutoMigrate())
+	return store
+}
+
+type recorder struct {
+	calls []time.Time // the "last" argument of each call
+	err   error
+}
+
+func (r *recorder) run(_ context.Context, last, _ time.Time) error {
+	r.calls = append(r.calls, last)
+	return r.err
+}
+
+func TestSchedulerRunsWhenDue(t *testing.T) {
+	store := newStore(t)
+	now := time.Date(2026, 3, 4, 2, 0, 0, 0, time.UTC)
+	s := New(store)
+	s.Now = func() time.Time { return now }
+	daily, err := Parse("0 3 * * *")
+	require.NoError(t, err)
+	var r recorder
+	require.NoError(t, s.Add(Job{Name: "backup", Schedule: daily, Run: r.run}))
+	require.Error(t, s.Add(Job{Name: "backup", Schedule: daily, Run: r.run}))
+
+	s.RunDue(context.Background())
+	assert.Empty(t, r.calls, "not due before 03:00")
+
+	now = time.Date(2026, 3, 4, 3, 0, 30, 0, time.UTC)
+	s.RunDue(context.Background())
+	require.Len(t, r.calls, 1)
+	assert.True(t, r.calls[0].IsZero(), "first run has no previous run")
+
+	s.RunDue(context.Background())
+	assert.Len(t, r.calls, 1, "ran already today")
+
+	// A fresh scheduler (a restart) sees the stored run.
+	s = New(store)
+	s.Now = func() time.Time { return now }
+	require.NoError(t, s.Add(Job{Name: "backup", Schedule: daily, Run: r.run}))
+	s.RunDue(context.Background())
+	assert.Len(t, r.calls, 1)
+
+	// Down all day on the 5th: the missed run happens as soon as we're back.
+	now = time.Date(2026, 3, 6, 1, 0, 0, 0, time.UTC)
+	s.RunDue(context.Background())
+	require.Len(t, r.calls, 2)
+	assert.Equal(t, time.Date(2026, 3, 4, 3, 0, 30, 0, time.UTC), r.calls[1].UTC())
+
+	st, err := s.Status()
+	require.NoError(t, err)
+	require.Len(t, st, 1)
+	assert.Equal(t, "0 3 * * *", st[0].Schedule)
+	assert.Equal(t, time.Date(2026, 3, 6, 3, 0, 0, 0, time.UTC), st[0].NextRunAt.UTC())
+}
+
+func TestSchedulerRetriesFailures(t *testing.T) {
+	store := newStore(t)
+	now := time.Date(2026, 3, 4, 3, 0, 0, 0, time.UTC)
+	s := New(store)
+	s.Now = func() time.Time { return now }
+	s.RetryDelay = 10 * time.Minute
+	r := recorder{err: errors.New("bucket unreachable")}
+	require.NoError(t, s.Add(Job{Name: "export:bundle", Schedule: Every(24 * time.Hour), Run: r.run}))
+
+	require.ErrorContains(t, s.RunNow(context.Background(), "export:bundle"), "unreachable")
+	run, err := store.LastJobRun("export:bundle")
+	require.NoError(t, err)
+	assert.Equal(t, "bucket unreachable", run.LastError)
+
+	now = now.Add(5 * time.Minute)
+	s.RunDue(context.Background())
+	assert.Len(t, r.calls, 1, "waits out the retry delay")
+
+	now = now.Add(5 * time.Minute)
+	r.err = nil
+	s.RunDue(context.Background())
+	require.Len(t, r.calls, 2)
+	assert.True(t, r.calls[1].IsZero(), "no successful run yet")
+
+	assert.ErrorContains(t, s.RunNow(context.Background(), "nope"), "no job")
+}
+
+func TestJitterIsStablePerJob(t *testing.T) {
+	s := New(nil)
+	at := time.Date(2026, 3, 4, 3, 0, 0, 0, time.UTC)
+	a := Job{Name: "export:a", Jitter: time.Hour}
+	b := Job{Name: "export:b", Jitter: time.Hour}
+	ja, jb := s.jittered(a, at), s.jittered(b, at)
+	assert.Equal(t, ja, s.jittered(a, at))
+	assert.NotEqual(t, ja, jb)
+	for _, j := range []time.Time{ja, jb} {
+		assert.False(t, j.Before(at))
+		assert.True(t, j.Before(at.Add(time.Hour)))
+	}
+	assert.Equal(t, at, s.jittered(Job{Name: "x"}, at))
+}
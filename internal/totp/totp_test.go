@@ -0,0 +1,84 @@
+// Copyright 2026 Phillip Cloud
+// Licensed under the Apache License, Version 2.0
+
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the SHA-1 key from the RFC 6238 test vectors.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).
+	EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeMatchesRFC6238(t *testing.T) {
+	// The RFC lists 8-digit codes; ours are their last 6 digits.
+	for unix, want := range map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	} {
+		got, err := Code(rfcSecret, Step(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, want, got, unix)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	code, err := Code(rfcSecret, Step(now))
+	require.NoError(t, err)
+
+	step, ok := Verify(rfcSecret, code, now, 0)
+	require.True(t, ok)
+	assert.Equal(t, Step(now), step)
+
+	// A little clock drift is fine.
+	_, ok = Verify(rfcSecret, code, now.Add(Period), 0)
+	assert.True(t, ok)
+	_, ok = Verify(rfcSecret, code, now.Add(3*Period), 0)
+	assert.False(t, ok)
+
+	// A used code can't be replayed.
+	_, ok = Verify(rfcSecret, code, now, step)
+	assert.False(t, ok)
+
+	_, ok = Verify(rfcSecret, "12345", now, 0)
+	assert.False(t, ok)
+}
+
+func TestSecretRoundTrip(t *testing.T) {
+	secret, err := NewSecret()
+	require.NoError(t, err)
+	assert.Len(t, secret, 32)
+	now := time.Now()
+	code, err := Code(secret, Step(now))
+	require.NoError(t, err)
+	_, ok := Verify(secret, code[:3]+" "+code[3:], now, 0)
+	assert.True(t, ok)
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("webcasa", "admin", "JBSWY3DPEHPK3PXP")
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/webcasa:admin?"), uri)
+	assert.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+	assert.Contains(t, uri, "issuer=webcasa")
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := RecoveryCodes(10)
+	require.NoError(t, err)
+	require.Len(t, codes, 10)
+	assert.Regexp(t, `^[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}$`, codes[0])
+	assert.NotEqual(t, codes[0], codes[1])
+	assert.Equal(t, HashRecoveryCode(codes[0]), HashRecoveryCode(" "+strings.ToUpper(codes[0])))
+}